@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"github.com/rsgcata/go-migrations/_examples/mongo/migrations"
 	"github.com/rsgcata/go-migrations/cli"
-	"github.com/rsgcata/go-migrations/execution/repository"
+	mongorepo "github.com/rsgcata/go-migrations/execution/repository/mongo"
 	"github.com/rsgcata/go-migrations/migration"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -20,7 +20,7 @@ func main() {
 	cli.Bootstrap(
 		os.Args[1:],
 		buildRegistry(dirPath, ctx, dbDsn, getDbName()),
-		createMongoRepository(dbDsn, ctx),
+		createMongoRepository(getStateDbDsn(dbDsn), ctx),
 		dirPath,
 		nil,
 	)
@@ -47,10 +47,10 @@ func createMigrationsDirPath() migration.MigrationsDirPath {
 func createMongoRepository(
 	dbDsn string,
 	ctx context.Context,
-) *repository.MongoHandler {
-	repo, err := repository.NewMongoHandler(
+) *mongorepo.Handler {
+	repo, err := mongorepo.NewHandler(
 		dbDsn,
-		getDbName(),
+		getStateDbName(),
 		getCollectionName(),
 		ctx,
 		nil,
@@ -73,6 +73,16 @@ func getDbName() string {
 	return dbName
 }
 
+// getStateDbName The database which stores the migration executions. Defaults to the
+// migrations database
+func getStateDbName() string {
+	if dbName := os.Getenv("MONGO_STATE_DATABASE"); dbName != "" {
+		return dbName
+	}
+
+	return getDbName()
+}
+
 func getCollectionName() string {
 	collectionName := os.Getenv("MONGO_MIGRATIONS_COLLECTION")
 
@@ -96,6 +106,17 @@ func getDbDsn() string {
 	return dsn
 }
 
+// getStateDbDsn Prepare the DSN of the cluster which stores the migration executions. It
+// defaults to the migrations DSN, but the executions can be stored in a different cluster, with
+// different credentials
+func getStateDbDsn(dbDsn string) string {
+	if dsn := os.Getenv("MONGO_STATE_DSN"); dsn != "" {
+		return dsn
+	}
+
+	return dbDsn
+}
+
 // buildRegistry This will create a new registry and register all migrations
 func buildRegistry(
 	dirPath migration.MigrationsDirPath,
@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"github.com/rsgcata/go-migrations/_examples/mysql/migrations"
 	"github.com/rsgcata/go-migrations/cli"
-	"github.com/rsgcata/go-migrations/execution/repository"
+	"github.com/rsgcata/go-migrations/execution/repository/mysql"
+	"github.com/rsgcata/go-migrations/handler"
 	"github.com/rsgcata/go-migrations/migration"
 	"os"
 	"path/filepath"
@@ -16,12 +17,18 @@ func main() {
 	ctx := context.Background()
 	dirPath := createMigrationsDirPath()
 	dbDsn := getDbDsn()
-	cli.Bootstrap(
+	registry, db := buildRegistry(dirPath, dbDsn)
+	cli.BootstrapWithSettings(
 		os.Args[1:],
-		buildRegistry(dirPath, ctx, dbDsn),
-		createMysqlRepository(dbDsn, ctx),
-		dirPath,
-		nil,
+		cli.BootstrapSettings{
+			Registry:   registry,
+			Repository: createMysqlRepository(getStateDbDsn(dbDsn), ctx),
+			DirPath:    dirPath,
+			// Shared with the context-aware migrations (see migration.WithResources)
+			HandlerOptions: []handler.Option{
+				handler.WithContext(ctx), handler.WithResources(migration.DB.Provide(db)),
+			},
+		},
 	)
 }
 
@@ -46,8 +53,8 @@ func createMigrationsDirPath() migration.MigrationsDirPath {
 func createMysqlRepository(
 	dbDsn string,
 	ctx context.Context,
-) *repository.MysqlHandler {
-	repo, err := repository.NewMysqlHandler(dbDsn, "migration_executions", ctx, nil)
+) *mysql.Handler {
+	repo, err := mysql.NewHandler(dbDsn, "migration_executions", ctx, nil)
 
 	if err != nil {
 		panic(fmt.Errorf("failed to build executions repository: %w", err))
@@ -74,12 +81,23 @@ func getDbDsn() string {
 	return dsn
 }
 
-// buildRegistry This will create a new registry and register all migrations
+// getStateDbDsn Prepare the DSN of the database which stores the migration executions. It
+// defaults to the migrations DSN, but the executions can be stored in a different database or
+// cluster, with different credentials
+func getStateDbDsn(dbDsn string) string {
+	if dsn := os.Getenv("MYSQL_STATE_DSN"); dsn != "" {
+		return dsn
+	}
+
+	return dbDsn
+}
+
+// buildRegistry This will create a new registry and register all migrations. Returns also the
+// migrations db handle
 func buildRegistry(
 	dirPath migration.MigrationsDirPath,
-	ctx context.Context,
 	dbDsn string,
-) *migration.DirMigrationsRegistry {
+) (*migration.DirMigrationsRegistry, *sql.DB) {
 	// New db needed to not conflict with executions repository connection session
 	db, err := sql.Open("mysql", dbDsn)
 
@@ -92,8 +110,8 @@ func buildRegistry(
 	allMigrations := []migration.Migration{
 		&migrations.Migration1712953077{Db: db},
 		&migrations.Migration1712953080{Db: db},
-		&migrations.Migration1712953083{Db: db, Ctx: ctx},
+		&migrations.Migration1712953083{},
 	}
 
-	return migration.NewDirMigrationsRegistry(dirPath, allMigrations)
+	return migration.NewDirMigrationsRegistry(dirPath, allMigrations), db
 }
@@ -2,28 +2,39 @@ package migrations
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+
+	"github.com/rsgcata/go-migrations/migration"
 )
 
+// Migration1712953083 Retrieves the shared db handle from the context set by the handler (see
+// migration.ContextAware and migration.WithResources), instead of holding it in a field
 type Migration1712953083 struct {
-	Db  *sql.DB
-	Ctx context.Context
+	ctx context.Context
+}
+
+func (m *Migration1712953083) SetContext(ctx context.Context) {
+	m.ctx = ctx
 }
 
-func (migration *Migration1712953083) Version() uint64 {
+func (m *Migration1712953083) Version() uint64 {
 	return 1712953083
 }
 
-func (migration *Migration1712953083) Up() error {
-	tx, err := migration.Db.BeginTx(migration.Ctx, nil)
+func (m *Migration1712953083) Up() error {
+	db, err := migration.DB.Get(m.ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(m.ctx, nil)
 
 	if err != nil {
 		return err
 	}
 
 	_, err = tx.ExecContext(
-		migration.Ctx,
+		m.ctx,
 		"insert into `users` (`name`, `phone_num`) values ('Alex', '1234'), ('Jada', '4567'), ('Tia', '7890')",
 	)
 
@@ -39,15 +50,20 @@ func (migration *Migration1712953083) Up() error {
 	return tx.Commit()
 }
 
-func (migration *Migration1712953083) Down() error {
-	tx, err := migration.Db.BeginTx(migration.Ctx, nil)
+func (m *Migration1712953083) Down() error {
+	db, err := migration.DB.Get(m.ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(m.ctx, nil)
 
 	if err != nil {
 		return err
 	}
 
 	_, err = tx.ExecContext(
-		migration.Ctx,
+		m.ctx,
 		"delete from `users` where `name` in ('Alex', 'Jada', 'Tia')",
 	)
 
@@ -3,16 +3,30 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/impact"
+	"io"
 	"os"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
+	"github.com/rsgcata/go-migrations/clock"
 	"github.com/rsgcata/go-migrations/execution"
 	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/schema"
+	"github.com/rsgcata/go-migrations/tenant"
 )
 
 // Command The specification for all the commands the tool should expose as entrypoint, features
@@ -22,6 +36,125 @@ type Command interface {
 	Exec() error
 }
 
+// NewHandlerFunc Builds the migrations handler used by the CLI commands
+type NewHandlerFunc func(
+	registry migration.MigrationsRegistry,
+	repository execution.Repository,
+	newExecutionPlan handler.ExecutionPlanBuilder,
+	options ...handler.Option,
+) (*handler.MigrationsHandler, error)
+
+// errNoMigrationsDir is returned (wrapped) by the commands which need the migrations directory,
+// when the CLI runs without one
+var errNoMigrationsDir = errors.New(
+	"no migrations directory configured (BootstrapSettings.DirPath is empty)",
+)
+
+// BootstrapSettings Groups everything needed to bootstrap the CLI. Registry and Repository are
+// required, the rest are optional.
+type BootstrapSettings struct {
+	Registry migration.MigrationsRegistry
+	// Repository Stores the migration executions. It does not need to use the connection (or
+	// the credentials) of the migrations, so it can live in a different database or cluster
+	// (see repository.FromDSN)
+	Repository execution.Repository
+	// DirPath The migrations directory. Can be left empty when the registry is built only in
+	// code (for example, from an embed.FS), in which case the commands which need the
+	// directory (blank, prune) are disabled and the baseline and skip list files are not read.
+	DirPath migration.MigrationsDirPath
+
+	// NewHandler Used to build the migrations handler. Defaults to handler.NewHandler
+	NewHandler NewHandlerFunc
+
+	// HandlerOptions Optional behaviour for the migrations handler (see handler.Option)
+	HandlerOptions []handler.Option
+
+	// StateImporters The importers available for the "state:adopt" command
+	StateImporters []execution.StateImporter
+
+	// TenantRunner Enables the --tenant=id and --all-tenants flags, which run the command
+	// for the selected tenants, each with its own registry and repository. When only tenant
+	// commands are used, Registry and Repository can be left empty.
+	TenantRunner *tenant.Runner
+
+	// CanaryVerifiers Used by the --canary=id flag of the tenant "up" command, which migrates
+	// the canary tenant first, runs the verifiers for it and migrates the remaining selected
+	// tenants (all of them, if none are selected) only if the canary passes (see
+	// tenant.Runner.RunWithCanary). Without verifiers, the canary only has to migrate
+	// successfully.
+	CanaryVerifiers []tenant.Verifier
+
+	// DriftDetector Used by the "validate" command to detect schema changes made outside
+	// migrations. Schema drift is not checked if nil
+	DriftDetector schema.DriftDetector
+
+	// SnapshotStore and SnapshotRestorer Enable the "fresh --from-snapshot" command, which
+	// restores the latest schema snapshot in a fresh database
+	SnapshotStore    schema.SnapshotStore
+	SnapshotRestorer schema.Restorer
+
+	// Clock Used for execution timestamps and blank migration versions. Defaults to
+	// clock.System
+	Clock clock.Clock
+
+	// BlankLayout Where the "blank" command generates migration files. Defaults to
+	// migration.FlatLayout. For migration.YearMonthLayout, the registry should be built with
+	// migration.NewNestedDirMigrationsRegistry.
+	BlankLayout migration.BlankLayout
+
+	// BlankFileExtension If set (for example, ".sql"), the "blank" command generates a pair of
+	// up and down files with this extension instead of a Go file (see migration.FileMigration)
+	BlankFileExtension string
+
+	// Environment The name of the environment the CLI runs against (for example, read from an
+	// environment variable). If it is one of the Guardrails protected environments, down,
+	// force:up, force:down, fresh and destructive migrations require the --allow-destructive
+	// flag or, if Guardrails.DisableDestructive is set, are disabled.
+	Environment string
+	Guardrails  handler.Guardrails
+
+	// ImpactAnalyzer Enables the "up --impact" command, which estimates the impact (locks,
+	// rows touched) of the pending migrations' statements (see impact.MysqlAnalyzer)
+	ImpactAnalyzer impact.Analyzer
+
+	// Throttle Operational limits for the "up" command (max run duration, pause between
+	// migrations, allowed time window). When a limit is reached, the run stops before the next
+	// migration and the remaining migrations are printed.
+	Throttle handler.Throttle
+
+	// ReadOnly Runs the CLI with read-only access to the executions repository (see
+	// handler.WithReadOnly), for example, with database credentials which can only read. Can
+	// also be enabled per call with the --read-only flag. Informational commands (stats,
+	// plan, validate) work as usual, while the commands which change the executions fail.
+	// The informational commands (see ReadOnlyCommands) always run read-only.
+	ReadOnly bool
+
+	// AuditLog If set, an entry (command, arguments, user, host, result, duration) is appended
+	// for each invocation, for compliance requirements. For example,
+	// execution.NewFileAuditLog(path) for a local file or the bundled repositories, which store
+	// the entries next to the executions.
+	AuditLog execution.AuditLog
+
+	// WorkDirRoot The directory in which the work directories of the migrations are created
+	// (see handler.WithWorkDirRoot), for example, a volume with enough space for data exports.
+	// Can also be set per call with the --work-dir=<dir> flag. Defaults to os.TempDir()
+	WorkDirRoot string
+
+	// Locker If set, the runs hold this migrations lock (see handler.WithLocker), instead of the
+	// repository's. For example, local.Locker (the execution/lock/local package) locks a file on
+	// Unix-like systems and a named mutex on Windows, so agents sharing a host exclude each other
+	// the same way on all platforms.
+	Locker execution.Locker
+
+	// Catalog Translations of the CLI messages (see Catalog), so operators get the flag errors,
+	// summaries and prompts in their language. Messages are printed in English if nil.
+	Catalog Catalog
+
+	// Close If set, it is called once the command is done, to release the resources opened for
+	// the migrations (for example, their database connections)
+	Close func() error
+}
+
 // Bootstrap Will bootstrap everything needed for the user CLI input, request. Will process the
 // user input and run the requested migration command
 func Bootstrap(
@@ -35,21 +168,144 @@ func Bootstrap(
 		newExecutionPlan handler.ExecutionPlanBuilder,
 	) (*handler.MigrationsHandler, error),
 ) {
-	if newHandler == nil {
-		newHandler = handler.NewHandler
+	settings := BootstrapSettings{Registry: registry, Repository: repository, DirPath: dirPath}
+
+	if newHandler != nil {
+		settings.NewHandler = func(
+			registry migration.MigrationsRegistry,
+			repository execution.Repository,
+			newExecutionPlan handler.ExecutionPlanBuilder,
+			_ ...handler.Option,
+		) (*handler.MigrationsHandler, error) {
+			return newHandler(registry, repository, newExecutionPlan)
+		}
 	}
 
-	migrationsHandler, err := newHandler(registry, repository, nil)
+	BootstrapWithSettings(args, settings)
+}
+
+// BootstrapWithSettings Same as Bootstrap, but allows configuring the optional CLI features
+// via BootstrapSettings
+func BootstrapWithSettings(args []string, settings BootstrapSettings) {
+	catalog := settings.Catalog
+	if settings.Close != nil {
+		defer func() {
+			if err := settings.Close(); err != nil {
+				catalog.printf("Failed to release the migrations resources with error: %s\n", err)
+			}
+		}()
+	}
 
-	if err != nil {
-		panic(
-			fmt.Errorf(
-				"coult not bootstrap cli, %s: %w",
-				"failed to create new migrations migrationsHandler with error", err,
-			),
+	if err := settings.Catalog.validate(); err != nil {
+		panic(fmt.Errorf("could not bootstrap cli, invalid catalog: %w", err))
+	}
+
+	if settings.NewHandler == nil {
+		settings.NewHandler = handler.NewHandler
+	}
+
+	if settings.Clock == nil {
+		settings.Clock = clock.System{}
+	}
+
+	defaultOptions := []handler.Option{handler.WithClock(settings.Clock)}
+
+	if settings.DirPath != "" {
+		baseline, found, err := migration.ReadBaseline(settings.DirPath)
+		if err != nil {
+			panic(fmt.Errorf("could not bootstrap cli, failed to read baseline: %w", err))
+		}
+
+		if found {
+			defaultOptions = append(defaultOptions, handler.WithBaseline(baseline))
+		}
+
+		skipList, err := migration.ReadSkipList(settings.DirPath)
+		if err != nil {
+			panic(fmt.Errorf("could not bootstrap cli, failed to read skip list: %w", err))
+		}
+
+		if len(skipList) > 0 {
+			defaultOptions = append(defaultOptions, handler.WithSkipList(skipList))
+		}
+
+		releases, err := migration.ReadReleases(settings.DirPath)
+		if err != nil {
+			panic(fmt.Errorf("could not bootstrap cli, failed to read releases: %w", err))
+		}
+
+		if len(releases) > 0 {
+			defaultOptions = append(defaultOptions, handler.WithReleases(releases))
+		}
+
+		lock, found, err := migration.ReadLockFile(settings.DirPath)
+		if err != nil {
+			panic(fmt.Errorf("could not bootstrap cli, failed to read lock file: %w", err))
+		}
+
+		if found {
+			defaultOptions = append(defaultOptions, handler.WithLockFile(lock))
+		}
+	}
+
+	settings.HandlerOptions = append(defaultOptions, settings.HandlerOptions...)
+	invocation := execution.AuditEntry{Time: settings.Clock.Now(), Args: slices.Clone(args)}
+
+	args, runMetadata := extractRunMetadataFlags(args)
+	invocation.Operator = runMetadata.Operator
+	if !runMetadata.IsZero() {
+		settings.HandlerOptions = append(
+			settings.HandlerOptions, handler.WithRunMetadata(runMetadata),
+		)
+	}
+
+	if settings.Environment != "" {
+		settings.HandlerOptions = append(
+			settings.HandlerOptions,
+			handler.WithGuardrails(settings.Environment, settings.Guardrails),
+		)
+	}
+
+	if settings.Locker != nil {
+		settings.HandlerOptions = append(
+			settings.HandlerOptions, handler.WithLocker(settings.Locker),
+		)
+	}
+
+	args, workDirRoot, hasWorkDirRoot := extractValueFlag(args, "--work-dir")
+	if hasWorkDirRoot {
+		settings.WorkDirRoot = workDirRoot
+	}
+	if settings.WorkDirRoot != "" {
+		settings.HandlerOptions = append(
+			settings.HandlerOptions, handler.WithWorkDirRoot(settings.WorkDirRoot),
+		)
+	}
+
+	workDirs := &workDirLog{}
+	settings.HandlerOptions = append(
+		settings.HandlerOptions, handler.WithWorkDirHooks(workDirs.record),
+	)
+
+	if settings.Throttle != (handler.Throttle{}) {
+		settings.HandlerOptions = append(
+			settings.HandlerOptions, handler.WithThrottle(settings.Throttle),
 		)
 	}
 
+	args, destructiveApproved := extractBoolFlag(args, "--allow-destructive")
+	if destructiveApproved {
+		settings.HandlerOptions = append(settings.HandlerOptions, handler.WithDestructiveApproval())
+	}
+
+	args, locked := extractBoolFlag(args, "--locked")
+	if locked {
+		settings.HandlerOptions = append(settings.HandlerOptions, handler.WithLockedRuns())
+	}
+
+	args, readOnly := extractBoolFlag(args, "--read-only")
+	args, tenantIds, allTenants := extractTenantFlags(args)
+	args, canaryID, _ := extractValueFlag(args, "--canary")
 	inputCmd := "help"
 
 	if len(args) >= 1 {
@@ -60,35 +316,294 @@ func Bootstrap(
 		inputCmd = args[0]
 	}
 
-	up := &MigrateUpCommand{handler: migrationsHandler, args: args}
-	down := &MigrateDownCommand{handler: migrationsHandler, args: args}
-	forceUp := &MigrateForceUpCommand{handler: migrationsHandler, args: args}
-	forceDown := &MigrateForceDownCommand{handler: migrationsHandler, args: args}
-	stats := &MigrateStatsCommand{registry: registry, repository: repository}
-	blank := &GenerateBlankMigrationCommand{dirPath}
+	if readOnly || settings.ReadOnly || slices.Contains(ReadOnlyCommands, inputCmd) {
+		settings.HandlerOptions = append(settings.HandlerOptions, handler.WithReadOnly())
+	}
+
+	if len(tenantIds) > 0 || allTenants || canaryID != "" {
+		if allTenants {
+			tenantIds = nil
+		}
+
+		err := runForTenants(inputCmd, args, tenantIds, canaryID, settings, workDirs)
+		if err != nil {
+			catalog.printf("Failed to execute \"%s\" with error: %s\n", inputCmd, err)
+			printFailure(catalog, err, func(version uint64) string {
+				return migration.DisplayName(nil, version)
+			})
+		}
+		invocation.WorkDirs = workDirs.paths()
+		auditInvocation(settings, invocation, inputCmd, err)
+		return
+	}
+
+	migrationsHandler, err := settings.NewHandler(
+		settings.Registry, settings.Repository, nil, settings.HandlerOptions...,
+	)
 
-	availableCommands := []Command{
-		up, down, forceUp, forceDown, blank, stats,
+	if err != nil {
+		panic(
+			catalog.errorf(
+				"coult not bootstrap cli, %s: %w",
+				"failed to create new migrations migrationsHandler with error", err,
+			),
+		)
 	}
 
-	help := &HelpCommand{availableCommands: availableCommands}
+	availableCommands := newCommands(migrationsHandler, settings, args, workDirs)
+	help := &HelpCommand{availableCommands: availableCommands, catalog: catalog}
 
 	for _, cmd := range availableCommands {
 		if inputCmd == cmd.Name() {
-			if cmdErr := cmd.Exec(); cmdErr != nil {
-				fmt.Println("Failed to execute \"" + cmd.Name() + "\" with error: " + cmdErr.Error())
+			cmdErr := cmd.Exec()
+			if cmdErr != nil {
+				catalog.printf("Failed to execute \"%s\" with error: %s\n", cmd.Name(), cmdErr)
+				printFailure(catalog, cmdErr, migrationsHandler.DisplayName)
 			}
+			invocation.WorkDirs = workDirs.paths()
+			auditInvocation(settings, invocation, cmd.Name(), cmdErr)
 			return
 		}
 	}
 
-	if cmdErr := help.Exec(); cmdErr != nil {
-		fmt.Println("Failed to execute \"" + help.Name() + "\" with error: " + cmdErr.Error())
+	cmdErr := help.Exec()
+	if cmdErr != nil {
+		catalog.printf("Failed to execute \"%s\" with error: %s\n", help.Name(), cmdErr)
+	}
+	auditInvocation(settings, invocation, help.Name(), cmdErr)
+}
+
+// auditInvocation Appends the audit entry of the command invocation to the audit log, if set.
+// Audit failures are printed, they do not change the command result.
+func auditInvocation(
+	settings BootstrapSettings,
+	entry execution.AuditEntry,
+	command string,
+	cmdErr error,
+) {
+	if settings.AuditLog == nil {
+		return
+	}
+
+	entry.Command = command
+	entry.User = currentUser()
+	entry.Host, _ = os.Hostname()
+	entry.DurationMs = settings.Clock.Now().Sub(entry.Time).Milliseconds()
+	entry.Result = execution.AuditResultSuccess
+	if cmdErr != nil {
+		entry.Result = execution.AuditResultFailure
+		entry.Error = cmdErr.Error()
+	}
+
+	if err := settings.AuditLog.AppendAudit(entry); err != nil {
+		settings.Catalog.printf("Failed to write the audit log entry with error: %s\n", err)
+	}
+}
+
+// currentUser The name of the operating system user running the CLI
+func currentUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+func newCommands(
+	migrationsHandler *handler.MigrationsHandler,
+	settings BootstrapSettings,
+	args []string,
+	workDirs *workDirLog,
+) []Command {
+	catalog := settings.Catalog
+	up := &MigrateUpCommand{
+		handler:  migrationsHandler,
+		args:     args,
+		dirPath:  settings.DirPath,
+		input:    os.Stdin,
+		analyzer: settings.ImpactAnalyzer,
+		catalog:  catalog,
+	}
+	down := &MigrateDownCommand{handler: migrationsHandler, args: args, catalog: catalog}
+	forceUp := &MigrateForceUpCommand{
+		handler: migrationsHandler, args: args, workDirs: workDirs, catalog: catalog,
+	}
+	forceDown := &MigrateForceDownCommand{
+		handler: migrationsHandler, args: args, workDirs: workDirs, catalog: catalog,
+	}
+	stats := &MigrateStatsCommand{handler: migrationsHandler, args: args, catalog: catalog}
+	blank := &GenerateBlankMigrationCommand{
+		migrationsDir: settings.DirPath,
+		options: migration.BlankOptions{
+			Clock:         settings.Clock,
+			Layout:        settings.BlankLayout,
+			FileExtension: settings.BlankFileExtension,
+		},
+		args:    args,
+		catalog: catalog,
+	}
+	script := &GenerateSQLScriptCommand{handler: migrationsHandler, args: args, catalog: catalog}
+	adopt := &AdoptStateCommand{
+		handler:   migrationsHandler,
+		importers: settings.StateImporters,
+		args:      args,
+		catalog:   catalog,
+	}
+	export := &ExportGolangMigrateCommand{handler: migrationsHandler, args: args, catalog: catalog}
+	exportState := &ExportStateCommand{handler: migrationsHandler, args: args, catalog: catalog}
+	prune := &PruneCommand{
+		handler: migrationsHandler, dirPath: settings.DirPath, args: args, catalog: catalog,
+	}
+	writeLock := &WriteLockFileCommand{
+		handler: migrationsHandler, dirPath: settings.DirPath, catalog: catalog,
+	}
+	runSchedule := &RunScheduleCommand{
+		handler: migrationsHandler, dirPath: settings.DirPath, catalog: catalog,
+	}
+	note := &NoteCommand{handler: migrationsHandler, args: args, catalog: catalog}
+	history := &HistoryCommand{handler: migrationsHandler, catalog: catalog}
+	diffState := &DiffStateCommand{handler: migrationsHandler, args: args, catalog: catalog}
+	validate := &ValidateCommand{
+		handler: migrationsHandler, driftDetector: settings.DriftDetector, catalog: catalog,
+	}
+	verifyReversible := &VerifyReversibleCommand{
+		handler: migrationsHandler, args: args, catalog: catalog,
+	}
+	tui := &TUICommand{
+		handler: migrationsHandler, dirPath: settings.DirPath, input: os.Stdin, catalog: catalog,
+	}
+
+	fresh := &FreshCommand{
+		handler:  migrationsHandler,
+		store:    settings.SnapshotStore,
+		restorer: settings.SnapshotRestorer,
+		args:     args,
+		catalog:  catalog,
+	}
+
+	return []Command{
+		up, down, forceUp, forceDown, blank, stats, validate, fresh, script, adopt, export,
+		exportState, prune, diffState, verifyReversible, tui, writeLock, runSchedule, note,
+		history,
+	}
+}
+
+// ReadOnlyCommands The informational commands, which only read the executions. They always run
+// with a read-only handler (see handler.NewReadOnlyHandler), which never initializes the
+// repository nor takes locks, so they work with read-only credentials and during active runs.
+var ReadOnlyCommands = []string{
+	"stats", "validate", "script", "export:golang-migrate", "state:export", "state:diff",
+	"history",
+}
+
+// tenantCommands The commands which can be executed for one or multiple tenants
+var tenantCommands = []string{"up", "down", "force:up", "force:down", "stats", "validate"}
+
+// extractTenantFlags Removes the tenant selection flags (--tenant=id, repeatable or comma
+// separated, and --all-tenants) from args
+func extractTenantFlags(args []string) (remaining []string, tenantIds []string, all bool) {
+	for _, arg := range args {
+		if arg == "--all-tenants" {
+			all = true
+		} else if ids, found := strings.CutPrefix(arg, "--tenant="); found {
+			for _, id := range strings.Split(ids, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					tenantIds = append(tenantIds, id)
+				}
+			}
+		} else {
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return remaining, tenantIds, all
+}
+
+// extractRunMetadataFlags Removes the run metadata flags (--deploy-id=id, --git-sha=sha and
+// --operator=name) from args. The metadata is stored with each execution created by the run.
+func extractRunMetadataFlags(args []string) ([]string, execution.RunMetadata) {
+	var metadata execution.RunMetadata
+	args, metadata.DeployID, _ = extractValueFlag(args, "--deploy-id")
+	args, metadata.GitSHA, _ = extractValueFlag(args, "--git-sha")
+	args, metadata.Operator, _ = extractValueFlag(args, "--operator")
+	return args, metadata
+}
+
+// runForTenants Runs the command for the selected tenants (all of them if no ids are provided).
+// If a canary tenant id is provided, the canary is migrated and verified first (see
+// BootstrapSettings.CanaryVerifiers).
+func runForTenants(
+	inputCmd string,
+	args []string,
+	tenantIds []string,
+	canaryID string,
+	settings BootstrapSettings,
+	workDirs *workDirLog,
+) error {
+	if settings.TenantRunner == nil {
+		return settings.Catalog.newError(
+			"tenant flags were provided but no tenant runner was configured",
+		)
+	}
+
+	if !slices.Contains(tenantCommands, inputCmd) {
+		return settings.Catalog.newError(
+			"command can not be executed per tenant. Allowed commands: " +
+				strings.Join(tenantCommands, ", "),
+		)
+	}
+
+	if canaryID != "" && inputCmd != "up" {
+		return settings.Catalog.newError("the canary flag can only be used with the up command")
+	}
+
+	tenants, err := settings.TenantRunner.Select(tenantIds...)
+	if err != nil {
+		return err
+	}
+
+	runCommand := func(
+		t tenant.Tenant,
+		registry migration.MigrationsRegistry,
+		repository execution.Repository,
+	) error {
+		migrationsHandler, err := settings.NewHandler(
+			registry, repository, nil, settings.HandlerOptions...,
+		)
+		if err != nil {
+			return settings.Catalog.errorf(
+				"failed to create migrations handler with error: %w", err,
+			)
+		}
+
+		fmt.Println("")
+		settings.Catalog.printf("Tenant: %s\n", t.ID)
+
+		commands := newCommands(migrationsHandler, settings, args, workDirs)
+		for _, cmd := range commands {
+			if cmd.Name() == inputCmd {
+				return cmd.Exec()
+			}
+		}
+
+		return nil
+	}
+
+	if canaryID != "" {
+		return settings.TenantRunner.RunWithCanary(
+			canaryID, tenants, runCommand, settings.CanaryVerifiers...,
+		)
 	}
+
+	return settings.TenantRunner.Run(tenants, runCommand)
 }
 
 type HelpCommand struct {
 	availableCommands []Command
+	catalog           Catalog
 }
 
 func (c *HelpCommand) Name() string {
@@ -102,13 +617,15 @@ func (c *HelpCommand) Description() string {
 
 func (c *HelpCommand) Exec() error {
 	fmt.Println("")
-	fmt.Println(c.Description())
+	c.catalog.printLine(c.Description())
 	fmt.Println("")
-	fmt.Println("Available commands:")
+	c.catalog.printLine("Available commands:")
 	fmt.Println("")
 
 	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	_, _ = fmt.Fprintln(writer, c.Name()+"\tDisplays helpful information about this tool")
+	_, _ = fmt.Fprintln(
+		writer, c.Name()+"\t"+c.catalog.tr("Displays helpful information about this tool"),
+	)
 
 	chunkDescription := func(description string, size int) []string {
 		if len(description) == 0 {
@@ -134,7 +651,7 @@ func (c *HelpCommand) Exec() error {
 
 	for _, command := range c.availableCommands {
 		_, _ = fmt.Fprintln(writer, "_________\t")
-		descChunks := chunkDescription(command.Description(), 80)
+		descChunks := chunkDescription(c.catalog.tr(command.Description()), 80)
 		_, _ = fmt.Fprintln(writer, command.Name()+"\t"+descChunks[0])
 		if len(descChunks) > 1 {
 			for _, descChunk := range descChunks[1:] {
@@ -148,8 +665,12 @@ func (c *HelpCommand) Exec() error {
 }
 
 type MigrateUpCommand struct {
-	handler *handler.MigrationsHandler
-	args    []string
+	handler  *handler.MigrationsHandler
+	args     []string
+	dirPath  migration.MigrationsDirPath
+	input    io.Reader
+	analyzer impact.Analyzer
+	catalog  Catalog
 }
 
 func (c *MigrateUpCommand) Name() string {
@@ -159,207 +680,2027 @@ func (c *MigrateUpCommand) Name() string {
 func (c *MigrateUpCommand) Description() string {
 	return "Executes Up() for the specified number of registered and not yet executed migrations." +
 		" If the number of migrations to execute is not specified, defaults to 1. Allowed" +
-		" values for the number of migrations to run Up(): \"all\", alias for 99999 and a valid" +
-		" integer greater than 0\n" +
-		"Examples: migrate up, migrate up all, migrate up 3"
+		" values for the number of migrations to run Up(), provided as an argument or via" +
+		" --steps=<value>: \"all\" and a valid integer greater than 0. With --dry-run, the" +
+		" SQL statements of database/sql based migrations (see SQLDryRunner) are printed" +
+		" instead of executed. With --impact, the statements are not executed either, but" +
+		" their estimated impact (locks, rows touched) is printed. With --interactive, each" +
+		" migration is displayed and must be approved, skipped (recorded as executed, without" +
+		" running it) or the run aborted. With --json, the run report is printed as JSON." +
+		" Instead of a number of migrations, --version=<version> executes all migrations up to" +
+		" and including the version and --range=<from>..<to> executes exactly the migrations" +
+		" in the range, which must start with the next migration to execute. With --locked," +
+		" the run fails if it would execute migrations which are not pinned in the lock file" +
+		" (see lock:write). With --release=<release>, all migrations up to the last migration" +
+		" of the release are executed. With --at=<time> (RFC 3339, for example" +
+		" 2024-07-01T02:00Z), the process waits until the time before running and, with" +
+		" --until=<time>, does not start the run after the end of the approved window. With" +
+		" --defer, the run is stored in the " + handler.ScheduleFileName + " file, from the" +
+		" migrations directory, instead of waiting, and executed by schedule:run (or by" +
+		" handler.RunStoredSchedule, on startup). With --prepare, only Prepare() is executed for" +
+		" the two-phase migrations (see migration.TwoPhase), so heavy work is staged ahead of" +
+		" the release, and with --commit, the staged changes are switched over at deploy" +
+		" time, executing Commit() instead of Up(). With --abort, the staged changes of the" +
+		" prepared migrations are discarded\n" +
+		"Examples: migrate up, migrate up all, migrate up 3, migrate up --steps=3," +
+		" migrate up --version=20240101000000, migrate up --range=2..5," +
+		" migrate up all --dry-run, migrate up all --impact, migrate up all --interactive," +
+		" migrate up all --json, migrate up all --locked, migrate up --release=2024.06," +
+		" migrate up all --at=2024-07-01T02:00Z --until=2024-07-01T04:00Z," +
+		" migrate up all --at=2024-07-01T02:00Z --defer, migrate up all --prepare," +
+		" migrate up all --commit, migrate up all --abort"
 }
 
 func (c *MigrateUpCommand) Exec() error {
-	var numOfRuns handler.NumOfRuns
-	var argErr error
-
-	if len(c.args) < 2 {
-		numOfRuns, argErr = handler.NewNumOfRuns("1")
-	} else {
-		numOfRuns, argErr = handler.NewNumOfRuns(c.args[1])
+	args, dryRun := extractBoolFlag(c.args, "--dry-run")
+	args, interactive := extractBoolFlag(args, "--interactive")
+	args, estimateImpact := extractBoolFlag(args, "--impact")
+	args, asJSON := extractBoolFlag(args, "--json")
+	args, prepare := extractBoolFlag(args, "--prepare")
+	args, commit := extractBoolFlag(args, "--commit")
+	args, abortPrepared := extractBoolFlag(args, "--abort")
+	args, schedule, deferred, argErr := extractSchedule(c.catalog, args)
+	if deferred && (prepare || commit || abortPrepared) {
+		argErr = c.catalog.newError(
+			"--defer can not be combined with --prepare, --commit or --abort",
+		)
+	}
+	if argErr == nil && deferred {
+		return c.deferRun(args, schedule)
 	}
 
+	target, runsErr := extractRuns(c.catalog, args, handler.StageUp)
+	argErr = errors.Join(argErr, runsErr)
+
 	if argErr != nil {
-		fmt.Printf("Failed to execute Up(). %s\n", argErr)
+		c.catalog.printf("Failed to execute Up(). %s\n", argErr)
 		return argErr
 	}
 
-	execs, err := c.handler.MigrateUp(numOfRuns)
-	fmt.Printf("Executed Up() for %d migrations\n", len(execs))
-
-	for _, execMig := range execs {
-		if execMig.Execution != nil {
-			fmt.Printf("Executed Up() for %d migration\n", execMig.Execution.Version)
+	if !schedule.At.IsZero() {
+		if err := c.waitFor(schedule); err != nil {
+			return err
 		}
 	}
 
-	return err
-}
-
-type MigrateDownCommand struct {
-	handler *handler.MigrationsHandler
-	args    []string
-}
-
-func (c *MigrateDownCommand) Name() string {
-	return "down"
-}
-
-func (c *MigrateDownCommand) Description() string {
-	return "Executes Down() for the specified number of executed migrations." +
-		" If the number of executions is not specified, defaults to 1. Allowed" +
-		" values for the number of migrations to run Down(): \"all\", alias for 99999 and a valid" +
-		" integer greater than 0\n" +
-		"Examples: migrate down, migrate down all, migrate down 3"
-}
+	if estimateImpact || dryRun {
+		return c.execReadOnly(target, estimateImpact)
+	}
 
-func (c *MigrateDownCommand) Exec() error {
-	var numOfRuns handler.NumOfRuns
-	var argErr error
+	if interactive {
+		return c.execInteractive(target)
+	}
 
-	if len(c.args) < 2 {
-		numOfRuns, argErr = handler.NewNumOfRuns("1")
-	} else {
-		numOfRuns, argErr = handler.NewNumOfRuns(c.args[1])
+	if prepare {
+		versions, err := c.handler.PrepareUpTo(target)
+		c.printTwoPhases(c.catalog.tr("Prepared"), versions)
+		return err
 	}
 
-	if argErr != nil {
-		fmt.Printf("Failed to execute Down(). %s\n", argErr)
-		return argErr
+	if abortPrepared {
+		versions, err := c.handler.AbortPreparedTo(target)
+		c.printTwoPhases(c.catalog.tr("Aborted"), versions)
+		return err
 	}
 
-	execs, err := c.handler.MigrateDown(numOfRuns)
+	run := c.handler.MigrateUpTo
+	if commit {
+		run = c.handler.CommitUpTo
+	}
 
-	fmt.Printf("Executed Down() for %d migrations\n", len(execs))
+	report, err := run(target)
+	if asJSON {
+		return errors.Join(err, printJSON(report))
+	}
+	printReport(c.catalog, report, "Up")
 
-	for _, mig := range execs {
-		if mig.Execution != nil {
-			fmt.Printf("Executed Down() for %d migration\n", mig.Execution.Version)
+	var throttled *handler.RunThrottledError
+	if errors.As(err, &throttled) {
+		c.catalog.printf("Run stopped, %s\n", throttled.Reason)
+		for _, version := range throttled.Remaining {
+			c.catalog.printf("Remaining: %s\n", c.handler.DisplayName(version))
 		}
-
 	}
 
 	return err
 }
 
-type MigrateStatsCommand struct {
-	registry   migration.MigrationsRegistry
-	repository execution.Repository
-}
-
-func (c *MigrateStatsCommand) Name() string {
-	return "stats"
-}
-
-func (c *MigrateStatsCommand) Description() string {
-	return "Displays statistics about registered migrations and executions\n" +
-		"Examples: migrate stats"
-}
-
-func (c *MigrateStatsCommand) Exec() error {
-	plan, err := handler.NewPlan(c.registry, c.repository)
-
-	if plan != nil {
-		nextMigFile := "N/A"
-		lastMigFile := "N/A"
-		next := plan.NextToExecute()
-		prev := plan.LastExecuted().Migration
+// execReadOnly Estimates the impact of the migrations of the target or dry-runs them. Since the
+// executions are not changed, the target is resolved against the current executions.
+func (c *MigrateUpCommand) execReadOnly(target handler.Target, estimateImpact bool) error {
+	numOfRuns, err := c.handler.ResolveTarget(target)
+	if err != nil {
+		c.catalog.printf("Failed to execute Up(). %s\n", err)
+		return err
+	}
 
-		if next != nil {
-			nextMigFile = migration.FileNamePrefix + migration.FileNameSeparator +
-				strconv.Itoa(int(next.Version())) + ".go"
-		}
-		if prev != nil {
-			lastMigFile = migration.FileNamePrefix + migration.FileNameSeparator +
-				strconv.Itoa(int(prev.Version())) + ".go"
+	if estimateImpact {
+		if c.analyzer == nil {
+			return c.catalog.newError("no impact analyzer was configured")
 		}
 
-		fmt.Println("")
-		fmt.Printf("Registered migrations count: %d\n", plan.RegisteredMigrationsCount())
-		fmt.Printf("Executions count: %d\n", plan.FinishedExecutionsCount())
-		fmt.Printf("Next to execute migration file: %s\n", nextMigFile)
-		fmt.Printf("Last executed migration file: %s\n", lastMigFile)
+		impacts, err := c.handler.EstimateImpact(numOfRuns, c.analyzer)
+		printImpacts(c.catalog, impacts)
+		return err
 	}
 
+	dryRuns, err := c.handler.DryRunUp(numOfRuns)
+	printDryRuns(c.catalog, dryRuns)
 	return err
 }
 
-type GenerateBlankMigrationCommand struct {
-	migrationsDir migration.MigrationsDirPath
+// printTwoPhases Prints the two-phase migrations handled by up --prepare or up --abort
+func (c *MigrateUpCommand) printTwoPhases(action string, versions []uint64) {
+	c.catalog.printf("%s %d two-phase migrations\n", action, len(versions))
+	for _, version := range versions {
+		c.catalog.printf("%s: %s\n", action, c.handler.DisplayName(version))
+	}
 }
 
-func (c *GenerateBlankMigrationCommand) Name() string {
-	return "blank"
-}
+// waitFor Waits, until interrupted, for the schedule of the run (see
+// handler.MigrationsHandler.WaitForSchedule)
+func (c *MigrateUpCommand) waitFor(schedule handler.Schedule) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-func (c *GenerateBlankMigrationCommand) Description() string {
-	return "Generates a new, blank migrations file in the configured migrations directory\n" +
-		"Examples: migrate blank"
+	c.catalog.printf("Waiting until %s to execute Up()\n", schedule.At.Format(time.RFC3339))
+	return c.handler.WaitForSchedule(ctx, schedule)
 }
 
-func (c *GenerateBlankMigrationCommand) Exec() error {
-	fileName, err := migration.GenerateBlankMigration(c.migrationsDir)
+// deferRun Stores the schedule of the run in the migrations directory (see
+// handler.WriteScheduleFile). Only a number of migrations can be scheduled, the schedule file has
+// no room for targets (for example, --version).
+func (c *MigrateUpCommand) deferRun(args []string, schedule handler.Schedule) error {
+	if c.dirPath == "" {
+		return c.catalog.errorf("%w, up --defer needs it to store the schedule", errNoMigrationsDir)
+	}
+
+	for _, flag := range []string{"--version", "--range", "--release"} {
+		if _, _, found := extractValueFlag(args, flag); found {
+			return c.catalog.errorf(
+				"%w, up --defer accepts only the number of migrations to run, not %s",
+				handler.ErrInvalidTarget, flag,
+			)
+		}
+	}
 
+	steps, err := extractStepsValue(c.catalog, args, "1")
 	if err != nil {
 		return err
 	}
+	schedule.Steps = steps
 
-	fmt.Println("")
-	fmt.Println("New blank migration file generated: " + fileName)
-	fmt.Println("")
+	if err := handler.WriteScheduleFile(c.dirPath, schedule); err != nil {
+		return err
+	}
 
+	c.catalog.printf(
+		"Scheduled Up() for %s migrations at %s in %s\n",
+		formatSteps(schedule.Steps), schedule.At.Format(time.RFC3339), handler.ScheduleFileName,
+	)
 	return nil
 }
 
-func getVersionFrom(args []string) (uint64, error) {
-	if len(args) < 2 {
-		return 0, errors.New(
-			"migration version is expected to be the second argument. None provided",
-		)
-	}
+func (c *MigrateUpCommand) execInteractive(target handler.Target) error {
+	reader := bufio.NewReader(c.input)
+	skipped := map[uint64]bool{}
+
+	execs, err := c.handler.MigrateUpInteractiveTo(
+		target, func(mig migration.Migration) handler.Decision {
+			checksum := c.catalog.tr("N/A")
+			if c.dirPath != "" {
+				if sum, sumErr := migration.FileChecksum(c.dirPath, mig.Version()); sumErr == nil {
+					checksum = sum
+				}
+			}
 
-	migVersion, err := strconv.Atoi(args[1])
+			fmt.Println("")
+			c.catalog.printf("Migration: %s\n", c.handler.DisplayName(mig.Version()))
+			c.catalog.printf("Description: %s\n", describe(mig))
+			c.catalog.printf("Checksum: %s\n", checksum)
+
+			for {
+				c.catalog.printf(
+					"[a]pprove, [s]kip (record as executed without running it), a[b]ort: ",
+				)
+				answer, readErr := reader.ReadString('\n')
+
+				switch strings.ToLower(strings.TrimSpace(answer)) {
+				case "a", "approve":
+					return handler.DecisionApprove
+				case "s", "skip":
+					skipped[mig.Version()] = true
+					return handler.DecisionSkip
+				case "b", "abort":
+					return handler.DecisionAbort
+				}
+
+				if readErr != nil {
+					return handler.DecisionAbort
+				}
+			}
+		},
+	)
 
-	if err != nil {
-		return 0, fmt.Errorf(
-			"migration version must be a valid numeric value. Failed with error: %w", err,
-		)
+	for _, execMig := range execs {
+		if execMig.Execution == nil {
+			continue
+		}
+
+		if skipped[execMig.Execution.Version] {
+			c.catalog.printf("Skipped %d migration\n", execMig.Execution.Version)
+		} else {
+			c.catalog.printf("Executed Up() for %d migration\n", execMig.Execution.Version)
+		}
 	}
 
-	return uint64(migVersion), nil
+	return err
 }
 
-type MigrateForceUpCommand struct {
+type MigrateDownCommand struct {
 	handler *handler.MigrationsHandler
 	args    []string
+	catalog Catalog
 }
 
-func (c *MigrateForceUpCommand) Name() string {
-	return "force:up"
+func (c *MigrateDownCommand) Name() string {
+	return "down"
 }
 
-func (c *MigrateForceUpCommand) Description() string {
-	return "Executes Up() forcefully for the provided migration version" +
-		" (even if it was executed before)\n" +
-		"Examples: migrate force:up 1712953077"
+func (c *MigrateDownCommand) Description() string {
+	return "Executes Down() for the specified number of executed migrations." +
+		" If the number of executions is not specified, defaults to 1. Allowed" +
+		" values for the number of migrations to run Down(), provided as an argument or via" +
+		" --steps=<value>: \"all\" and a valid integer greater than 0. With --before=<time>," +
+		" all migrations executed at or after the provided time (date, YYYY-MM-DD, in local" +
+		" time, or RFC 3339 timestamp) are rolled back. With --json, the run report is printed" +
+		" as JSON. Instead of a number of migrations, --version=<version> rolls back the version" +
+		" and all executed migrations after it and --range=<from>..<to> rolls back exactly the" +
+		" migrations in the range, which must end with the last executed migration. With" +
+		" --release=<release>, the migrations of the release and all executed migrations" +
+		" after them are rolled back\n" +
+		"Examples: migrate down, migrate down all, migrate down 3, migrate down --steps=all," +
+		" migrate down --version=20240101000000, migrate down --range=2..5," +
+		" migrate down --before=2024-06-01, migrate down all --json," +
+		" migrate down --release=2024.06"
 }
 
-func (c *MigrateForceUpCommand) Exec() error {
-	migVersion, err := getVersionFrom(c.args)
+func (c *MigrateDownCommand) Exec() error {
+	args, asJSON := extractBoolFlag(c.args, "--json")
+	args, before, hasBefore := extractValueFlag(args, "--before")
+
+	var target handler.Target
+	if hasBefore {
+		since, parseErr := parseTime(c.catalog, before)
+		if parseErr != nil {
+			c.catalog.printf("Failed to execute Down(). %s\n", parseErr)
+			return parseErr
+		}
+		target = handler.DownSince(since)
+	} else {
+		var argErr error
+		if target, argErr = extractRuns(c.catalog, args, handler.StageDown); argErr != nil {
+			c.catalog.printf("Failed to execute Down(). %s\n", argErr)
+			return argErr
+		}
+	}
 
-	if err != nil {
-		return err
+	report, err := c.handler.MigrateDownTo(target)
+	if asJSON {
+		return errors.Join(err, printJSON(report))
 	}
+	printReport(c.catalog, report, "Down")
+	return err
+}
 
-	exec, err := c.handler.ForceUp(migVersion)
+// printReport Prints the migrations handled by a run, with their outcomes and durations, and
+// the run totals
+func printReport(catalog Catalog, report *handler.RunReport, direction string) {
+	catalog.printf("Executed %s() for %d migrations\n", direction, len(report.Migrations))
+
+	for _, migrationReport := range report.Migrations {
+		version := migrationReport.Migration.Version()
+		duration := migrationReport.Duration.Round(time.Millisecond)
+
+		switch migrationReport.Outcome {
+		case handler.OutcomeSkipped:
+			catalog.printf("Skipped %d migration\n", version)
+		case handler.OutcomeFailed:
+			catalog.printf("Failed %s() for %d migration after %s\n", direction, version, duration)
+		default:
+			catalog.printf("Executed %s() for %d migration in %s\n", direction, version, duration)
+		}
+
+		printLogs(migrationReport.Logs)
+		if migrationReport.WorkDir != "" {
+			printWorkDir(catalog, migrationReport.WorkDir, migrationReport.WorkDirRemoveErr)
+		}
+	}
+
+	catalog.printf(
+		"Batch %s: %d executed, %d skipped, %d failed in %s\n",
+		report.BatchID,
+		report.Count(handler.OutcomeExecuted),
+		report.Count(handler.OutcomeSkipped),
+		report.Count(handler.OutcomeFailed),
+		report.Duration().Round(time.Millisecond),
+	)
+
+	for _, warning := range report.Warnings {
+		catalog.printf("Warning: %s\n", warning)
+	}
+}
+
+// workDirLog Records the work directories of the migrations executed by the invocation (see
+// handler.WithWorkDirHooks), for the audit log and for the commands which do not print run
+// reports
+type workDirLog struct {
+	workDirs []handler.WorkDir
+}
+
+func (log *workDirLog) record(workDir handler.WorkDir) {
+	log.workDirs = append(log.workDirs, workDir)
+}
+
+func (log *workDirLog) count() int {
+	return len(log.workDirs)
+}
+
+func (log *workDirLog) paths() []string {
+	var paths []string
+	for _, workDir := range log.workDirs {
+		paths = append(paths, workDir.Path)
+	}
+	return paths
+}
+
+// printSince Prints the work directories recorded after the first recorded ones
+func (log *workDirLog) printSince(catalog Catalog, recorded int) {
+	for _, workDir := range log.workDirs[recorded:] {
+		printWorkDir(catalog, workDir.Path, workDir.RemoveErr)
+	}
+}
+
+// printWorkDir Prints the work directory of a migration and whether it was removed
+func printWorkDir(catalog Catalog, path string, removeErr error) {
+	if removeErr != nil {
+		catalog.printf("  Work directory: %s (not removed: %s)\n", path, removeErr)
+	} else {
+		catalog.printf("  Work directory: %s (removed)\n", path)
+	}
+}
+
+// failureHints Remediation hints for migration failures, by stage. %[1]d is the version
+var failureHints = map[handler.MigrationStage]string{
+	handler.StageValidate: "Nothing was executed. Fix migration %[1]d, then run the command again.",
+	handler.StageUp: "Up() may have applied only part of its changes and the execution was" +
+		" saved as unfinished. Revert the applied changes manually, fix the migration, then run" +
+		" \"force:up %[1]d\".",
+	handler.StageDown: "Down() may have reverted only part of the changes and the execution is" +
+		" still saved. Finish reverting the changes manually, then run \"force:down %[1]d\"" +
+		" to remove the execution, after fixing the migration.",
+	handler.StageSave: "The changes of Up() are applied, but the execution was not saved. Fix the" +
+		" executions repository, then add %[1]d to the skip list, so the next run records it as" +
+		" executed without running it, or revert the changes manually and run \"up\" again.",
+	handler.StageRemove: "The changes were reverted by Down(), but the execution was not" +
+		" removed. Fix the executions repository, reapply the changes manually, then run" +
+		" \"force:down %[1]d\" again.",
+}
+
+// printFailure Prints the details of the migration failure wrapped by err, if any, together with
+// a remediation hint. The migration is named by displayName. For inconsistent executions, points
+// to the validate command, which suggests how to fix them.
+func printFailure(catalog Catalog, err error, displayName func(version uint64) string) {
+	if errors.Is(err, handler.ErrPlanInconsistent) {
+		catalog.printLine("Hint: run \"validate\" to find the version gaps and how to fix them.")
+	}
+
+	var failed *handler.MigrationFailedError
+	if !errors.As(err, &failed) {
+		return
+	}
+
+	fmt.Println("")
+	catalog.printLine("Migration failure")
+	catalog.printf("  Migration: %s\n", displayName(failed.Version))
+	catalog.printf("  Direction: %s\n", failed.Direction)
+	catalog.printf("  Stage:     %s\n", failed.Stage)
+	catalog.printf("  Elapsed:   %s\n", failed.Elapsed.Round(time.Millisecond))
+	catalog.printf("  Error:     %s\n", failed.Err)
+
+	if hint, found := failureHints[failed.Stage]; found {
+		catalog.printf("Hint: %s\n", fmt.Sprintf(catalog.tr(hint), failed.Version))
+	}
+}
+
+// printDurationStats Prints the time spent running migrations and the slowest migrations, named
+// by displayName
+func printDurationStats(
+	catalog Catalog,
+	stats handler.DurationStats,
+	displayName func(version uint64) string,
+) {
+	if stats.Count == 0 {
+		return
+	}
+
+	catalog.printf("Total time spent migrating: %s\n", stats.Total)
+	catalog.printf("Average migration duration: %s\n", stats.Average().Round(time.Millisecond))
+
+	for _, slow := range stats.Slowest {
+		catalog.printf("Slow migration: %s (%s)\n", displayName(slow.Version), slow.Duration)
+	}
+}
+
+// printRunLockStatus Prints who holds the migrations run lock, if exclusive locking is enabled
+func printRunLockStatus(catalog Catalog, lock handler.RunLockStatus) {
+	if !lock.Enabled {
+		return
+	}
+
+	switch {
+	case !lock.Inspectable:
+		catalog.printLine("Run lock: unknown, the repository does not report the lock holder")
+	case lock.Holder == nil:
+		catalog.printLine("Run lock: free")
+	default:
+		catalog.printf(
+			"Run lock: held by %s since %s\n",
+			lock.Holder.Owner, execution.FormatTimestampMs(lock.Holder.AcquiredAtMs),
+		)
+	}
+}
+
+// printReleaseSummaries Prints the executed and pending migrations of each release, if the
+// migrations are grouped by release
+func printReleaseSummaries(catalog Catalog, releases []handler.ReleaseSummary) {
+	if !slices.ContainsFunc(releases, func(summary handler.ReleaseSummary) bool {
+		return summary.Release != ""
+	}) {
+		return
+	}
+
+	for _, summary := range releases {
+		release := summary.Release
+		if release == "" {
+			release = catalog.tr("(no release)")
+		}
+		catalog.printf(
+			"Release %s: %d executed, %d pending\n", release, summary.Executed, summary.Pending,
+		)
+	}
+}
+
+// printLogs Prints the messages logged by a migration, indented, one per line
+func printLogs(logs []handler.LogEntry) {
+	for _, entry := range logs {
+		line := "  " + entry.Level.String() + " " + entry.Message
+		for _, attr := range entry.Attrs {
+			line += " " + attr.String()
+		}
+		fmt.Println(line)
+	}
+}
+
+// printJSON Prints the value, encoded as indented JSON
+func printJSON(value any) error {
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(encoded))
+	return nil
+}
+
+type MigrateStatsCommand struct {
+	handler *handler.MigrationsHandler
+	args    []string
+	catalog Catalog
+}
+
+func (c *MigrateStatsCommand) Name() string {
+	return "stats"
+}
+
+func (c *MigrateStatsCommand) Description() string {
+	return "Displays statistics about registered migrations and executions, the time spent" +
+		" running migrations, the slowest migrations (5, unless --top=<number> is provided)," +
+		" the migrations which were skipped (see the migrations.skip file), the executed and" +
+		" pending migrations of each release (see the migrations.releases file) and, if exclusive" +
+		" locking is enabled, who holds the migrations run lock and since when. With" +
+		" --format=prometheus, the current version, pending count and last run metrics are" +
+		" printed in the Prometheus text format, or written to the --output=<file> file" +
+		" (atomically, for the node_exporter textfile collector), and inconsistent executions" +
+		" are reported with the go_migrations_inconsistent gauge\n" +
+		"Examples: migrate stats, migrate stats --top=10, migrate stats --format=prometheus" +
+		" --output=/var/lib/node_exporter/migrations.prom"
+}
+
+func (c *MigrateStatsCommand) Exec() error {
+	args, format, _ := extractValueFlag(c.args, "--format")
+	args, output, _ := extractValueFlag(args, "--output")
+	_, topValue, hasTop := extractValueFlag(args, "--top")
+
+	top := 5
+	if hasTop {
+		var convErr error
+		if top, convErr = strconv.Atoi(topValue); convErr != nil || top < 0 {
+			return c.catalog.errorf("invalid --top value %q, expected a number", topValue)
+		}
+	}
+
+	switch format {
+	case "", "text":
+		return c.execText(top)
+	case "prometheus":
+		return c.execPrometheus(output)
+	default:
+		return c.catalog.errorf("unknown format %q, expected text or prometheus", format)
+	}
+}
+
+func (c *MigrateStatsCommand) execText(top int) error {
+	stats, err := c.handler.Stats(top)
+	summary := stats.Summary
+
+	if err == nil {
+		nextMig := c.catalog.tr("N/A")
+		lastMig := c.catalog.tr("N/A")
+		next := summary.NextToExecute
+		prev := summary.LastExecuted.Migration
+
+		if next != nil {
+			nextMig = c.handler.DisplayName(next.Version())
+		}
+		if prev != nil {
+			lastMig = c.handler.DisplayName(prev.Version())
+		}
+
+		fmt.Println("")
+		c.catalog.printf("Registered migrations count: %d\n", summary.RegisteredCount)
+		c.catalog.printf("Executions count: %d\n", summary.FinishedCount)
+		c.catalog.printf("Next to execute migration: %s\n", nextMig)
+		c.catalog.printf("Last executed migration: %s\n", lastMig)
+	}
+
+	if err == nil {
+		printReleaseSummaries(c.catalog, stats.Releases)
+	}
+
+	if err == nil {
+		var lock handler.RunLockStatus
+		lock, err = c.handler.RunLockStatus()
+		printRunLockStatus(c.catalog, lock)
+	}
+
+	if err == nil {
+		printDurationStats(c.catalog, stats.Durations, c.handler.DisplayName)
+
+		for _, executed := range stats.Skipped {
+			c.catalog.printf(
+				"Skipped migration: %s (%s)\n",
+				c.handler.DisplayName(executed.Migration.Version()), executed.Execution.SkipReason,
+			)
+		}
+	}
+
+	return err
+}
+
+// execPrometheus Prints the metrics, or writes them to the output file, if provided. The file
+// is written to a temporary file first, which is then renamed, so the textfile collector never
+// reads a partially written file. Inconsistent executions (see handler.ErrPlanInconsistent) are
+// reported with the inconsistent gauge, instead of failing, so they can be alerted on.
+func (c *MigrateStatsCommand) execPrometheus(output string) error {
+	stats, err := c.handler.Stats(0)
+	inconsistent := errors.Is(err, handler.ErrPlanInconsistent)
+	if err != nil && !inconsistent {
+		return err
+	}
+
+	var summary *handler.Summary
+	if !inconsistent {
+		summary = &stats.Summary
+	}
+	durations := stats.Durations
+
+	lock, err := c.handler.RunLockStatus()
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		return writePrometheusMetrics(os.Stdout, summary, durations, lock)
+	}
+
+	err = replaceFile(output, func(w io.Writer) error {
+		return writePrometheusMetrics(w, summary, durations, lock)
+	})
+	if err != nil {
+		return c.catalog.errorf("failed to write metrics file with error: %w", err)
+	}
+
+	c.catalog.printf("Exported metrics to %s\n", output)
+	return nil
+}
+
+// replaceFile Writes the file through a temporary file, from the same directory, which replaces
+// it once completely written, so readers never see partial contents and failed writes leave no
+// partial file behind. The file is readable by all users (for example, by the node exporter,
+// which usually runs as another user), unlike the temporary files created by os.CreateTemp.
+func replaceFile(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	err = errors.Join(write(tmp), tmp.Close())
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// prometheusGauge A gauge written in the Prometheus text format
+type prometheusGauge struct {
+	name  string
+	help  string
+	value float64
+}
+
+// writePrometheusMetrics Writes the migrations metrics in the Prometheus text format. The
+// summary metrics are written only if the executions are consistent (summary is not nil) and the
+// run lock metrics only if the lock is enabled and inspectable.
+func writePrometheusMetrics(
+	w io.Writer,
+	summary *handler.Summary,
+	durations handler.DurationStats,
+	lock handler.RunLockStatus,
+) error {
+	var metrics []prometheusGauge
+	inconsistent := 1.0
+	if summary != nil {
+		metrics = summaryMetrics(*summary)
+		inconsistent = 0
+	}
+
+	metrics = append(
+		metrics,
+		prometheusGauge{
+			"go_migrations_inconsistent",
+			"1 if the executions are inconsistent with the registered migrations.",
+			inconsistent,
+		},
+		prometheusGauge{
+			"go_migrations_duration_seconds",
+			"Total time spent running the executed migrations.",
+			durations.Total.Seconds(),
+		},
+	)
+
+	if lock.Inspectable {
+		var held, acquiredAt float64
+		if lock.Holder != nil {
+			held = 1
+			acquiredAt = float64(lock.Holder.AcquiredAtMs) / 1000
+		}
+
+		metrics = append(
+			metrics,
+			prometheusGauge{
+				"go_migrations_run_lock_held", "1 if the migrations run lock is held.", held,
+			},
+			prometheusGauge{
+				"go_migrations_run_lock_acquired_timestamp_seconds",
+				"Unix time when the held run lock was acquired, 0 if the lock is free.",
+				acquiredAt,
+			},
+		)
+	}
+
+	for _, metric := range metrics {
+		_, err := fmt.Fprintf(
+			w, "# HELP %[1]s %[2]s\n# TYPE %[1]s gauge\n%[1]s %[3]s\n",
+			metric.name, metric.help, strconv.FormatFloat(metric.value, 'f', -1, 64),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// summaryMetrics The version, count and last run metrics of the summary
+func summaryMetrics(summary handler.Summary) []prometheusGauge {
+	var currentVersion, dirty, lastRunTimestamp, lastRunDuration float64
+	if last := summary.LastExecuted.Execution; last != nil {
+		currentVersion = float64(last.Version)
+		lastRunTimestamp = float64(max(last.FinishedAtMs, last.ExecutedAtMs)) / 1000
+		lastRunDuration = last.Duration().Seconds()
+		if !last.Finished() {
+			dirty = 1
+		}
+	}
+
+	return []prometheusGauge{
+		{
+			"go_migrations_current_version",
+			"Version of the last executed migration, 0 if none was executed.",
+			currentVersion,
+		},
+		{
+			"go_migrations_dirty",
+			"1 if the last executed migration did not finish (it failed or is running).",
+			dirty,
+		},
+		{
+			"go_migrations_registered",
+			"Number of registered migrations.",
+			float64(summary.RegisteredCount),
+		},
+		{
+			"go_migrations_executed",
+			"Number of finished migration executions.",
+			float64(summary.FinishedCount),
+		},
+		{
+			"go_migrations_pending",
+			"Number of registered migrations which are not executed yet.",
+			float64(summary.PendingCount()),
+		},
+		{
+			"go_migrations_last_run_timestamp_seconds",
+			"Unix time when the last executed migration finished (or started, if unfinished).",
+			lastRunTimestamp,
+		},
+		{
+			"go_migrations_last_run_duration_seconds",
+			"How long the last executed migration ran.",
+			lastRunDuration,
+		},
+	}
+}
+
+type ValidateCommand struct {
+	handler       *handler.MigrationsHandler
+	driftDetector schema.DriftDetector
+	catalog       Catalog
+}
+
+func (c *ValidateCommand) Name() string {
+	return "validate"
+}
+
+func (c *ValidateCommand) Description() string {
+	return "Checks, without changing anything, that executions are consistent with the" +
+		" registered migrations (pending migrations ordered before executed ones, for example" +
+		" merged late from an old branch, are reported with suggested fixes), that all" +
+		" migration files are registered and, if a drift detector is configured, that the" +
+		" database schema was not changed outside migrations\n" +
+		"Examples: migrate validate"
+}
+
+func (c *ValidateCommand) Exec() error {
+	problems, err := c.handler.Validate(c.driftDetector)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		c.catalog.printLine("No problems found")
+		return nil
+	}
+
+	c.catalog.printf("Found %d problems:\n", len(problems))
+	for _, problem := range problems {
+		c.catalog.printf("- %s\n", problem)
+	}
+
+	return c.catalog.newError("validation failed")
+}
+
+type VerifyReversibleCommand struct {
+	handler *handler.MigrationsHandler
+	args    []string
+	catalog Catalog
+}
+
+func (c *VerifyReversibleCommand) Name() string {
+	return "verify:reversible"
+}
+
+func (c *VerifyReversibleCommand) Description() string {
+	return "Runs Up(), Down() and Up() again for each pending migration and reports the" +
+		" migrations which fail the round-trip, catching broken rollbacks before release." +
+		" Must be run against a disposable database (for example, in CI). Verifies all" +
+		" pending migrations, unless a number is provided (as an argument or via" +
+		" --steps=<value>)\n" +
+		"Examples: migrate verify:reversible OR migrate verify:reversible 3"
+}
+
+func (c *VerifyReversibleCommand) Exec() error {
+	numOfRuns, argErr := extractSteps(c.catalog, c.args, "all")
+	if argErr != nil {
+		return argErr
+	}
+
+	results, err := c.handler.VerifyReversible(numOfRuns)
+
+	for _, result := range results {
+		name := c.handler.DisplayName(result.Migration.Version())
+		if result.Reversible() {
+			c.catalog.printf("Reversible: %s\n", name)
+		} else {
+			c.catalog.printf("Not reversible: %s (%s)\n", name, result.Err)
+		}
+	}
+
+	if err == nil {
+		c.catalog.printf("Verified %d migrations, all are reversible\n", len(results))
+	}
+
+	return err
+}
+
+type TUICommand struct {
+	handler *handler.MigrationsHandler
+	dirPath migration.MigrationsDirPath
+	input   io.Reader
+	catalog Catalog
+}
+
+// tuiEntry A migration listed by the tui command, with its execution, if any
+type tuiEntry struct {
+	migration migration.Migration
+	execution *execution.MigrationExecution
+}
+
+// state Returns the execution state of the entry
+func (entry tuiEntry) state() string {
+	switch {
+	case entry.execution == nil:
+		return "pending"
+	case !entry.execution.Finished():
+		return "unfinished"
+	case entry.execution.Skipped():
+		return "skipped"
+	default:
+		return "executed"
+	}
+}
+
+func (c *TUICommand) Name() string {
+	return "tui"
+}
+
+func (c *TUICommand) Description() string {
+	return "Opens an interactive terminal UI which lists all migrations with their state," +
+		" description and duration. Entries can be inspected, applied (with all pending" +
+		" migrations before them) or rolled back (with all executed migrations after them)," +
+		" after confirmation\n" +
+		"Example: migrate tui"
+}
+
+func (c *TUICommand) Exec() error {
+	reader := bufio.NewReader(c.input)
+	clearScreen := isTerminal(os.Stdout)
+
+	for {
+		entries, err := c.entries()
+		if err != nil {
+			return err
+		}
+
+		if clearScreen {
+			fmt.Print("\033[H\033[2J")
+		}
+		c.printEntries(entries)
+
+		fmt.Println("")
+		c.catalog.printf("[number] inspect, [a]pply <number>, [r]ollback <number>, [q]uit: ")
+		answer, readErr := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		action, numStr, _ := strings.Cut(answer, " ")
+
+		if action == "q" || action == "quit" || (readErr != nil && answer == "") {
+			fmt.Println("")
+			return nil
+		}
+
+		if numStr == "" {
+			action, numStr = "", action
+		}
+
+		num, numErr := strconv.Atoi(strings.TrimSpace(numStr))
+		if numErr != nil || num < 1 || num > len(entries) {
+			c.catalog.printLine("Invalid entry number")
+			continue
+		}
+
+		switch action {
+		case "":
+			c.printDetails(entries[num-1])
+		case "a", "apply":
+			err = c.apply(entries, num-1, reader)
+		case "r", "rollback":
+			err = c.rollback(entries, num-1, reader)
+		default:
+			c.catalog.printf("Unknown action %s\n", action)
+		}
+
+		if err != nil {
+			c.catalog.printf("Failed with error: %s\n", err)
+		}
+
+		c.catalog.printf("Press enter to continue")
+		if _, readErr = reader.ReadString('\n'); readErr != nil {
+			fmt.Println("")
+			return nil
+		}
+	}
+}
+
+// entries Returns all migrations, in execution order, with their executions
+func (c *TUICommand) entries() ([]tuiEntry, error) {
+	plan, err := c.handler.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []tuiEntry
+	executed := map[uint64]bool{}
+	for _, execMig := range plan.AllExecuted() {
+		executed[execMig.Migration.Version()] = true
+		entries = append(entries, tuiEntry{execMig.Migration, execMig.Execution})
+	}
+
+	for _, mig := range plan.AllToBeExecuted() {
+		if !executed[mig.Version()] {
+			entries = append(entries, tuiEntry{migration: mig})
+		}
+	}
+
+	return entries, nil
+}
+
+func (c *TUICommand) printEntries(entries []tuiEntry) {
+	c.catalog.printLine("Migrations")
+	fmt.Println("")
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, c.catalog.tr("#\tName\tState\tDuration\tDescription"))
+	for i, entry := range entries {
+		duration := "-"
+		if entry.state() == "executed" {
+			duration = entry.execution.Duration().String()
+		}
+
+		_, _ = fmt.Fprintf(
+			writer, "%d\t%s\t%s\t%s\t%s\n",
+			i+1, c.handler.DisplayName(entry.migration.Version()), c.catalog.tr(entry.state()),
+			duration, describe(entry.migration),
+		)
+	}
+	_ = writer.Flush()
+}
+
+func (c *TUICommand) printDetails(entry tuiEntry) {
+	checksum := c.catalog.tr("N/A")
+	if c.dirPath != "" {
+		if sum, err := migration.FileChecksum(c.dirPath, entry.migration.Version()); err == nil {
+			checksum = sum
+		}
+	}
+
+	fmt.Println("")
+	c.catalog.printf("Migration: %s\n", c.handler.DisplayName(entry.migration.Version()))
+	c.catalog.printf("Version: %d\n", entry.migration.Version())
+	c.catalog.printf("Description: %s\n", describe(entry.migration))
+	c.catalog.printf("Checksum: %s\n", checksum)
+	c.catalog.printf("State: %s\n", c.catalog.tr(entry.state()))
+
+	if entry.execution == nil {
+		return
+	}
+
+	c.catalog.printf("Executed at: %s\n", execution.FormatTimestampMs(entry.execution.ExecutedAtMs))
+	if entry.execution.Finished() {
+		c.catalog.printf(
+			"Finished at: %s\n", execution.FormatTimestampMs(entry.execution.FinishedAtMs),
+		)
+		c.catalog.printf("Duration: %s\n", entry.execution.Duration())
+	}
+	if entry.execution.Skipped() {
+		c.catalog.printf("Skip reason: %s\n", entry.execution.SkipReason)
+	}
+	if !entry.execution.Run.IsZero() {
+		c.catalog.printf(
+			"Run: deploy %s, git sha %s, operator %s\n", entry.execution.Run.DeployID,
+			entry.execution.Run.GitSHA, entry.execution.Run.Operator,
+		)
+	}
+}
+
+// apply Executes Up() for all pending migrations up to and including the selected one, after
+// confirmation
+func (c *TUICommand) apply(entries []tuiEntry, selected int, reader *bufio.Reader) error {
+	if entries[selected].execution != nil && entries[selected].execution.Finished() {
+		return c.catalog.newError("the migration is already executed")
+	}
+
+	var toApply []tuiEntry
+	for _, entry := range entries[:selected+1] {
+		if entry.execution == nil || !entry.execution.Finished() {
+			toApply = append(toApply, entry)
+		}
+	}
+
+	if !c.confirm(c.catalog.tr("Apply"), toApply, reader) {
+		return nil
+	}
+
+	executed, err := c.handler.MigrateUp(handler.NumOfRuns(len(toApply)))
+	for _, execMig := range executed {
+		c.catalog.printf(
+			"Executed Up() for %s\n", c.handler.DisplayName(execMig.Migration.Version()),
+		)
+	}
+	return err
+}
+
+// rollback Executes Down() for all executed migrations from the selected one onwards, after
+// confirmation
+func (c *TUICommand) rollback(entries []tuiEntry, selected int, reader *bufio.Reader) error {
+	if entries[selected].execution == nil {
+		return c.catalog.newError("the migration is not executed")
+	}
+
+	var toRollBack []tuiEntry
+	for i := len(entries) - 1; i >= selected; i-- {
+		if entries[i].execution != nil {
+			toRollBack = append(toRollBack, entries[i])
+		}
+	}
+
+	if !c.confirm(c.catalog.tr("Roll back"), toRollBack, reader) {
+		return nil
+	}
+
+	rolledBack, err := c.handler.MigrateDown(handler.NumOfRuns(len(toRollBack)))
+	for _, execMig := range rolledBack {
+		c.catalog.printf(
+			"Executed Down() for %s\n", c.handler.DisplayName(execMig.Migration.Version()),
+		)
+	}
+	return err
+}
+
+// confirm Asks the operator to confirm the action for the listed migrations
+func (c *TUICommand) confirm(action string, entries []tuiEntry, reader *bufio.Reader) bool {
+	fmt.Println("")
+	for _, entry := range entries {
+		c.catalog.printf("%s: %s\n", action, c.handler.DisplayName(entry.migration.Version()))
+	}
+	c.catalog.printf("%s %d migrations? [y/N]: ", action, len(entries))
+
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		c.catalog.printLine("Cancelled")
+		return false
+	}
+	return true
+}
+
+// describe Returns the migration description, N/A if it does not have one
+func describe(mig migration.Migration) string {
+	if describer, ok := mig.(migration.Describer); ok {
+		return describer.Description()
+	}
+	return "N/A"
+}
+
+// isTerminal Checks if the file is a terminal (for example, not a pipe)
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type FreshCommand struct {
+	handler  *handler.MigrationsHandler
+	store    schema.SnapshotStore
+	restorer schema.Restorer
+	args     []string
+	catalog  Catalog
+}
+
+func (c *FreshCommand) Name() string {
+	return "fresh"
+}
+
+func (c *FreshCommand) Description() string {
+	return "Prepares a fresh database (no executions). Only the --from-snapshot mode is" +
+		" supported: restores the latest stored schema snapshot and marks all migrations" +
+		" covered by it as executed, without running them. Requires a snapshot store and" +
+		" restorer configured at bootstrap\n" +
+		"Examples: migrate fresh --from-snapshot"
+}
+
+func (c *FreshCommand) Exec() error {
+	if _, fromSnapshot := extractBoolFlag(c.args, "--from-snapshot"); !fromSnapshot {
+		return c.catalog.newError("only the --from-snapshot mode is supported")
+	}
+
+	if c.store == nil || c.restorer == nil {
+		return c.catalog.newError("no snapshot store or snapshot restorer configured")
+	}
+
+	execs, err := c.handler.FastForwardFromSnapshot(c.store, c.restorer)
+	c.catalog.printf("Marked %d migrations as executed from snapshot\n", len(execs))
+
+	return err
+}
+
+type GenerateBlankMigrationCommand struct {
+	migrationsDir migration.MigrationsDirPath
+	options       migration.BlankOptions
+	args          []string
+	catalog       Catalog
+}
+
+func (c *GenerateBlankMigrationCommand) Name() string {
+	return "blank"
+}
+
+func (c *GenerateBlankMigrationCommand) Description() string {
+	return "Generates a new, blank migrations file in the configured migrations directory, or" +
+		" in the --dir=<path> directory. With --dry-run, the file names and contents are" +
+		" printed instead of written. With --stdout, only the contents are printed (for" +
+		" example, to pipe them into other tools)\n" +
+		"Examples: migrate blank, migrate blank --dry-run, migrate blank --stdout," +
+		" migrate blank --dir=./migrations/reports"
+}
+
+func (c *GenerateBlankMigrationCommand) Exec() error {
+	args, dryRun := extractBoolFlag(c.args, "--dry-run")
+	args, toStdout := extractBoolFlag(args, "--stdout")
+	_, dir, hasDir := extractValueFlag(args, "--dir")
+
+	migrationsDir := c.migrationsDir
+	if hasDir {
+		migrationsDir = migration.MigrationsDirPath(dir)
+	}
+
+	if migrationsDir == "" {
+		return c.catalog.errorf(
+			"%w, the blank command is disabled. Add the new migration to the code which builds"+
+				" the registry instead, or provide the --dir=<path> flag",
+			errNoMigrationsDir,
+		)
+	}
+
+	if dryRun || toStdout {
+		files, err := migration.RenderBlankMigration(migrationsDir, c.options)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			if toStdout {
+				fmt.Print(file.Contents)
+				continue
+			}
+
+			c.catalog.printf("Would generate %s\n", filepath.Join(string(migrationsDir), file.Name))
+			fmt.Print(file.Contents)
+		}
+		return nil
+	}
+
+	if hasDir {
+		var err error
+		if migrationsDir, err = migration.NewMigrationsDirPath(dir); err != nil {
+			return err
+		}
+	}
+
+	fileName, err := migration.GenerateBlankMigrationWithOptions(migrationsDir, c.options)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("")
+	c.catalog.printf("New blank migration file generated: %s\n", fileName)
+	fmt.Println("")
+
+	return nil
+}
+
+type GenerateSQLScriptCommand struct {
+	handler *handler.MigrationsHandler
+	args    []string
+	catalog Catalog
+}
+
+func (c *GenerateSQLScriptCommand) Name() string {
+	return "script"
+}
+
+func (c *GenerateSQLScriptCommand) Description() string {
+	return "Generates a single, ordered SQL script with the changes of all pending migrations," +
+		" without executing them. All pending migrations must implement the SQLRecorder" +
+		" interface. If a file path is provided, the script is written to that file, otherwise" +
+		" it is printed\n" +
+		"Examples: migrate script, migrate script pending.sql"
+}
+
+func (c *GenerateSQLScriptCommand) Exec() (err error) {
+	writer := io.Writer(os.Stdout)
+
+	if len(c.args) >= 2 {
+		file, createErr := os.OpenFile(c.args[1], os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if createErr != nil {
+			return c.catalog.errorf("failed to create script file with error: %w", createErr)
+		}
+
+		defer func(file *os.File) {
+			if closeErr := file.Close(); closeErr != nil {
+				err = errors.Join(err, closeErr)
+			}
+		}(file)
+
+		writer = file
+	}
+
+	scripted, err := c.handler.ScriptUp(writer)
+
+	if len(c.args) >= 2 {
+		c.catalog.printf("Generated SQL script for %d migrations: %s\n", len(scripted), c.args[1])
+	}
+
+	return err
+}
+
+type AdoptStateCommand struct {
+	handler   *handler.MigrationsHandler
+	importers []execution.StateImporter
+	args      []string
+	catalog   Catalog
+}
+
+func (c *AdoptStateCommand) Name() string {
+	return "state:adopt"
+}
+
+func (c *AdoptStateCommand) Description() string {
+	return "Imports the migrations state persisted by another migrations tool (for example" +
+		" golang-migrate, goose, flyway) into the executions repository. The executions" +
+		" repository must be empty. Only the importers configured at bootstrap are available\n" +
+		"Examples: migrate state:adopt goose"
+}
+
+func (c *AdoptStateCommand) Exec() error {
+	var names []string
+	for _, importer := range c.importers {
+		names = append(names, importer.Name())
+	}
+
+	if len(c.args) < 2 {
+		return c.catalog.newError(
+			"importer name is expected to be the second argument. Available importers: " +
+				strings.Join(names, ", "),
+		)
+	}
+
+	for _, importer := range c.importers {
+		if importer.Name() == c.args[1] {
+			adopted, err := c.handler.AdoptState(importer)
+			c.catalog.printf("Adopted %d executions from %s\n", len(adopted), importer.Name())
+			return err
+		}
+	}
+
+	return c.catalog.newError(
+		"unknown importer " + c.args[1] + ". Available importers: " + strings.Join(names, ", "),
+	)
+}
+
+type ExportGolangMigrateCommand struct {
+	handler *handler.MigrationsHandler
+	args    []string
+	catalog Catalog
+}
+
+func (c *ExportGolangMigrateCommand) Name() string {
+	return "export:golang-migrate"
+}
+
+func (c *ExportGolangMigrateCommand) Description() string {
+	return "Exports all registered migrations as SQL files, using golang-migrate's file name" +
+		" conventions, in the provided directory. All migrations must implement the SQLRecorder" +
+		" interface. If there are executions, a schema_migrations.sql file is also generated" +
+		" which sets golang-migrate's state to the last execution\n" +
+		"Examples: migrate export:golang-migrate ./golang-migrate"
+}
+
+func (c *ExportGolangMigrateCommand) Exec() error {
+	if len(c.args) < 2 {
+		return c.catalog.newError(
+			"export directory path is expected to be the second argument. None provided",
+		)
+	}
+
+	fileNames, err := c.handler.ExportGolangMigrate(c.args[1])
+	c.catalog.printf("Exported %d files\n", len(fileNames))
+
+	for _, fileName := range fileNames {
+		fmt.Println(fileName)
+	}
+
+	return err
+}
+
+type ExportStateCommand struct {
+	handler *handler.MigrationsHandler
+	args    []string
+	catalog Catalog
+}
+
+func (c *ExportStateCommand) Name() string {
+	return "state:export"
+}
+
+func (c *ExportStateCommand) Description() string {
+	return "Exports all executions, as JSON, to the provided file or, if no file is provided," +
+		" to the standard output. Exports of each environment can be used by the \"prune\"" +
+		" and \"state:diff\" commands\n" +
+		"Examples: migrate state:export production.json"
+}
+
+func (c *ExportStateCommand) Exec() error {
+	if len(c.args) < 2 {
+		return c.handler.ExportState(os.Stdout)
+	}
+
+	// The state file is only replaced once the export succeeded
+	if err := replaceFile(c.args[1], c.handler.ExportState); err != nil {
+		return c.catalog.errorf("failed to write state file with error: %w", err)
+	}
+
+	c.catalog.printf("Exported state to %s\n", c.args[1])
+	return nil
+}
+
+type PruneCommand struct {
+	handler *handler.MigrationsHandler
+	dirPath migration.MigrationsDirPath
+	args    []string
+	catalog Catalog
+}
+
+func (c *PruneCommand) Name() string {
+	return "prune"
+}
+
+func (c *PruneCommand) Description() string {
+	return "Checks that all environments, via their state exports (see state:export), executed" +
+		" all migrations up to (and including) the provided version, then moves their files" +
+		" into the archive directory and records the version as baseline. Archived migrations" +
+		" must then be removed from the registry\n" +
+		"Examples: migrate prune 1712953083 ../archive staging.json production.json"
+}
+
+func (c *PruneCommand) Exec() error {
+	if c.dirPath == "" {
+		return c.catalog.errorf(
+			"%w, prune needs it to archive the migration files", errNoMigrationsDir,
+		)
+	}
+
+	if len(c.args) < 4 {
+		return c.catalog.newError(
+			"version, archive directory and at least one state file are expected as arguments",
+		)
+	}
+
+	version, err := strconv.ParseUint(c.args[1], 10, 64)
+	if err != nil {
+		return c.catalog.errorf("invalid version %q", c.args[1])
+	}
+
+	var environments []handler.EnvironmentState
+	for _, stateFile := range c.args[3:] {
+		state, readErr := readStateFile(c.catalog, stateFile)
+		if readErr != nil {
+			return readErr
+		}
+		environments = append(environments, state)
+	}
+
+	archived, err := c.handler.Prune(c.dirPath, c.args[2], version, environments)
+	c.catalog.printf("Archived %d migration files\n", len(archived))
+
+	for _, fileName := range archived {
+		fmt.Println(fileName)
+	}
+
+	return err
+}
+
+type WriteLockFileCommand struct {
+	handler *handler.MigrationsHandler
+	dirPath migration.MigrationsDirPath
+	catalog Catalog
+}
+
+func (c *WriteLockFileCommand) Name() string {
+	return "lock:write"
+}
+
+func (c *WriteLockFileCommand) Description() string {
+	return "Pins all registered migrations in the " + migration.LockFileName + " file, from the" +
+		" migrations directory, as the versions expected to be applied for the release. With" +
+		" up --locked, migrations which are not pinned are not executed and the validate" +
+		" command reports the discrepancies between the lock file and the migrations\n" +
+		"Examples: migrate lock:write, migrate up all --locked"
+}
+
+func (c *WriteLockFileCommand) Exec() error {
+	if c.dirPath == "" {
+		return c.catalog.errorf(
+			"%w, lock:write needs it to write the lock file", errNoMigrationsDir,
+		)
+	}
+
+	versions, err := c.handler.WriteLockFile(c.dirPath)
+	if err == nil {
+		c.catalog.printf("Pinned %d migrations in %s\n", len(versions), migration.LockFileName)
+	}
+	return err
+}
+
+type RunScheduleCommand struct {
+	handler *handler.MigrationsHandler
+	dirPath migration.MigrationsDirPath
+	catalog Catalog
+}
+
+func (c *RunScheduleCommand) Name() string {
+	return "schedule:run"
+}
+
+func (c *RunScheduleCommand) Description() string {
+	return "Executes the run stored by up --defer, in the " + handler.ScheduleFileName + " file," +
+		" from the migrations directory. Waits until the scheduled time, so it can be started" +
+		" ahead of the approved window (for example, by a job runner), then executes Up() and" +
+		" removes the schedule. A run whose window was missed is not executed, but its" +
+		" schedule is removed\n" +
+		"Examples: migrate schedule:run"
+}
+
+func (c *RunScheduleCommand) Exec() error {
+	if c.dirPath == "" {
+		return c.catalog.errorf(
+			"%w, schedule:run needs it to read the schedule", errNoMigrationsDir,
+		)
+	}
+
+	schedule, found, err := handler.ReadScheduleFile(c.dirPath)
+	if err != nil {
+		return err
+	} else if !found {
+		c.catalog.printLine("No scheduled run")
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c.catalog.printf(
+		"Waiting until %s to execute Up() for %s migrations\n",
+		schedule.At.Format(time.RFC3339), formatSteps(schedule.Steps),
+	)
+	report, err := c.handler.RunStoredSchedule(ctx, c.dirPath)
+	if report != nil {
+		printReport(c.catalog, report, "Up")
+	}
+	return err
+}
+
+type NoteCommand struct {
+	handler *handler.MigrationsHandler
+	args    []string
+	catalog Catalog
+}
+
+func (c *NoteCommand) Name() string {
+	return "note"
+}
+
+func (c *NoteCommand) Description() string {
+	return "Attaches a free-text note to the execution of the migration with the provided" +
+		" version, capturing operational context (for example, a manual verification). The" +
+		" notes are stored in the repository, with the operator (see --operator) as author, and" +
+		" are shown by the history command\n" +
+		"Examples: migrate note --version=20240101000000 \"backfill verified\" --operator=jane"
+}
+
+func (c *NoteCommand) Exec() error {
+	args, value, found := extractValueFlag(c.args, "--version")
+	if !found {
+		return c.catalog.newError(
+			"the migration version is required, provide it via --version=<version>",
+		)
+	}
+
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return c.catalog.errorf("invalid version %q", value)
+	}
+
+	var text string
+	if len(args) >= 2 {
+		text = strings.Join(args[1:], " ")
+	}
+
+	if _, err = c.handler.AddNote(version, text); err == nil {
+		c.catalog.printf("Added note to %s\n", c.handler.DisplayName(version))
+	}
+	return err
+}
+
+type HistoryCommand struct {
+	handler *handler.MigrationsHandler
+	catalog Catalog
+}
+
+func (c *HistoryCommand) Name() string {
+	return "history"
+}
+
+func (c *HistoryCommand) Description() string {
+	return "Lists the executions, in the order they were executed, with their run metadata" +
+		" and the notes attached to them (see the note command)\n" +
+		"Examples: migrate history"
+}
+
+func (c *HistoryCommand) Exec() error {
+	history, err := c.handler.History()
+	if err != nil {
+		return err
+	}
+
+	notes, err := c.handler.Notes()
+	if err != nil {
+		return err
+	}
+
+	for _, exec := range history {
+		state := c.catalog.tr("unfinished")
+		if exec.Skipped() {
+			state = fmt.Sprintf(c.catalog.tr("skipped (%s)"), exec.SkipReason)
+		} else if exec.Finished() {
+			state = fmt.Sprintf(c.catalog.tr("finished in %s"), exec.Duration())
+		}
+
+		c.catalog.printf(
+			"%s: executed at %s, %s\n",
+			c.handler.DisplayName(exec.Version), execution.FormatTimestampMs(exec.ExecutedAtMs),
+			state,
+		)
+		if !exec.Run.IsZero() {
+			c.catalog.printf(
+				"  Run: deploy %q, git sha %q, operator %q\n",
+				exec.Run.DeployID, exec.Run.GitSHA, exec.Run.Operator,
+			)
+		}
+
+		for _, note := range notes[exec.Version] {
+			author := ""
+			if note.Author != "" {
+				author = fmt.Sprintf(c.catalog.tr(" by %s"), note.Author)
+			}
+			c.catalog.printf(
+				"  Note%s at %s: %s\n",
+				author, execution.FormatTimestampMs(note.CreatedAtMs), note.Text,
+			)
+		}
+	}
+
+	if len(history) == 0 {
+		c.catalog.printLine("No executions")
+	}
+	return nil
+}
+
+type DiffStateCommand struct {
+	handler *handler.MigrationsHandler
+	args    []string
+	catalog Catalog
+}
+
+func (c *DiffStateCommand) Name() string {
+	return "state:diff"
+}
+
+func (c *DiffStateCommand) Description() string {
+	return "Compares the executed migrations of two environments, via their state exports" +
+		" (see state:export), and shows which versions were executed in only one of them." +
+		" If a single state file is provided, it is compared with the current environment\n" +
+		"Examples: migrate state:diff staging.json production.json"
+}
+
+func (c *DiffStateCommand) Exec() error {
+	if len(c.args) < 2 {
+		return c.catalog.newError("at least one state file is expected as argument")
+	}
+
+	var states []handler.EnvironmentState
+	if len(c.args) == 2 {
+		current, err := c.handler.State("current")
+		if err != nil {
+			return err
+		}
+		states = append(states, current)
+	}
+
+	for _, stateFile := range c.args[1:min(len(c.args), 3)] {
+		state, err := readStateFile(c.catalog, stateFile)
+		if err != nil {
+			return err
+		}
+		states = append(states, state)
+	}
+
+	diff := handler.DiffEnvironments(states[0], states[1])
+	if diff.InSync() {
+		c.catalog.printf("%s and %s are in sync\n", diff.Left, diff.Right)
+		return nil
+	}
+
+	printOnlyExecuted(c.catalog, diff.Left, diff.OnlyLeft, c.handler.DisplayName)
+	printOnlyExecuted(c.catalog, diff.Right, diff.OnlyRight, c.handler.DisplayName)
+
+	return handler.ErrEnvironmentsOutOfSync
+}
+
+func printOnlyExecuted(
+	catalog Catalog,
+	environment string,
+	versions []uint64,
+	displayName func(version uint64) string,
+) {
+	catalog.printf("Executed only in %s: %d\n", environment, len(versions))
+	for _, version := range versions {
+		fmt.Println(displayName(version))
+	}
+}
+
+// readStateFile Reads a state export (see state:export). The environment is named after the
+// file, without extension
+func readStateFile(catalog Catalog, path string) (handler.EnvironmentState, error) {
+	state := handler.EnvironmentState{
+		Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+	}
+
+	file, err := os.Open(path)
+	if err == nil {
+		state.Executions, err = execution.DecodeExecutions(file)
+		err = errors.Join(err, file.Close())
+	}
+
+	if err != nil {
+		return state, catalog.errorf("failed to read state file %s with error: %w", path, err)
+	}
+	return state, nil
+}
+
+// extractBoolFlag Removes the flag from args and reports if it was present
+func extractBoolFlag(args []string, flag string) ([]string, bool) {
+	var remaining []string
+	found := false
+
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+		} else {
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return remaining, found
+}
+
+// extractValueFlag Removes the flag (--flag=value) from args and returns its value
+func extractValueFlag(args []string, flag string) ([]string, string, bool) {
+	var remaining []string
+	var value string
+	found := false
+
+	for _, arg := range args {
+		if flagValue, isFlag := strings.CutPrefix(arg, flag+"="); isFlag {
+			value = flagValue
+			found = true
+		} else {
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return remaining, value, found
+}
+
+// extractSteps Parses the number of migrations to run (see handler.ParseSteps) from the
+// --steps=<value> flag or, if the flag is not provided, from the command argument. Defaults to
+// defaultSteps.
+func extractSteps(
+	catalog Catalog,
+	args []string,
+	defaultSteps string,
+) (handler.NumOfRuns, error) {
+	steps, err := extractStepsValue(catalog, args, defaultSteps)
+	if err != nil {
+		return 0, err
+	}
+	return steps.NumOfRuns(), nil
+}
+
+// extractStepsValue Parses the number of migrations to run, as requested (see extractSteps)
+func extractStepsValue(catalog Catalog, args []string, defaultSteps string) (handler.Steps, error) {
+	args, value, found := extractValueFlag(args, "--steps")
+	if found && len(args) >= 2 {
+		return handler.Steps{}, catalog.errorf(
+			"%w, provide it via --steps or as an argument, not both", handler.ErrInvalidSteps,
+		)
+	} else if len(args) >= 2 {
+		value, found = args[1], true
+	}
+
+	if !found {
+		value = defaultSteps
+	}
+
+	return handler.ParseSteps(value)
+}
+
+// extractSchedule Removes the scheduling flags (--at=<time>, --until=<time> and --defer) from
+// args and builds the schedule of the run. The schedule time is zero if the run is not
+// scheduled.
+func extractSchedule(catalog Catalog, args []string) ([]string, handler.Schedule, bool, error) {
+	args, at, hasAt := extractValueFlag(args, "--at")
+	args, until, hasUntil := extractValueFlag(args, "--until")
+	args, deferred := extractBoolFlag(args, "--defer")
+
+	var schedule handler.Schedule
+	if !hasAt {
+		if hasUntil || deferred {
+			return args, schedule, false, catalog.newError(
+				"--until and --defer require --at=<time>",
+			)
+		}
+		return args, schedule, false, nil
+	}
+
+	var err error
+	if schedule.At, err = handler.ParseScheduleTime(at); err != nil {
+		return args, schedule, false, err
+	}
+	if hasUntil {
+		if schedule.Until, err = handler.ParseScheduleTime(until); err != nil {
+			return args, schedule, false, err
+		}
+		if !schedule.Until.After(schedule.At) {
+			return args, schedule, false, catalog.newError("--until must be after --at")
+		}
+	}
+
+	return args, schedule, deferred, nil
+}
+
+// formatSteps Formats the number of migrations to run, as requested
+func formatSteps(steps handler.Steps) string {
+	if steps.All {
+		return "all"
+	}
+	return strconv.Itoa(steps.N)
+}
+
+// extractRuns Extracts the target of the run from the --version=<version>, --range=<from>..<to>
+// or --release=<release> flag, or, if none is provided, from the number of migrations to run
+// (see extractSteps). Targets are resolved by the run, while holding the run lock.
+func extractRuns(
+	catalog Catalog,
+	args []string,
+	stage handler.MigrationStage,
+) (handler.Target, error) {
+	args, version, hasVersion := extractValueFlag(args, "--version")
+	args, versionRange, hasRange := extractValueFlag(args, "--range")
+	args, release, hasRelease := extractValueFlag(args, "--release")
+	_, _, hasSteps := extractValueFlag(args, "--steps")
+
+	targets := 0
+	for _, hasTarget := range []bool{hasVersion, hasRange, hasRelease, hasSteps || len(args) >= 2} {
+		if hasTarget {
+			targets++
+		}
+	}
+
+	if targets > 1 {
+		return nil, catalog.errorf(
+			"%w, provide only one of --version, --range, --release or the number of migrations"+
+				" to run",
+			handler.ErrInvalidTarget,
+		)
+	}
+
+	if hasRelease {
+		if stage == handler.StageDown {
+			return handler.DownToRelease(release), nil
+		}
+		return handler.UpToRelease(release), nil
+	}
+
+	if hasVersion {
+		target, err := strconv.ParseUint(version, 10, 64)
+		if err != nil {
+			return nil, catalog.errorf("%w, invalid version %q", handler.ErrInvalidTarget, version)
+		}
+		if stage == handler.StageDown {
+			return handler.DownToVersion(target), nil
+		}
+		return handler.UpToVersion(target), nil
+	}
+
+	if hasRange {
+		versions, err := handler.ParseVersionRange(versionRange)
+		if err != nil {
+			return nil, err
+		}
+		if stage == handler.StageDown {
+			return handler.DownRange(versions), nil
+		}
+		return handler.UpRange(versions), nil
+	}
+
+	numOfRuns, err := extractSteps(catalog, args, "1")
+	if err != nil {
+		return nil, err
+	}
+	return handler.Runs(numOfRuns), nil
+}
+
+// parseTime Parses a date (YYYY-MM-DD, local time) or a RFC 3339 timestamp
+func parseTime(catalog Catalog, value string) (time.Time, error) {
+	if parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return parsed, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, catalog.errorf(
+			"invalid time %q, expected a date (YYYY-MM-DD) or a RFC 3339 timestamp", value,
+		)
+	}
+
+	return parsed, nil
+}
+
+func printDryRuns(catalog Catalog, dryRuns []handler.DryRunMigration) {
+	catalog.printf("Dry-run Up() for %d migrations\n", len(dryRuns))
+
+	for _, dryRun := range dryRuns {
+		fmt.Println("")
+		catalog.printf("-- Migration version %d\n", dryRun.Migration.Version())
+
+		if !dryRun.Captured {
+			catalog.printLine(
+				"-- Statements can not be captured, the migration is not an SQLDryRunner",
+			)
+			continue
+		}
+
+		for _, statement := range dryRun.Statements {
+			if len(statement.Args) > 0 {
+				catalog.printf("%s; -- args: %v\n", statement.Query, statement.Args)
+			} else {
+				catalog.printf("%s;\n", statement.Query)
+			}
+		}
+	}
+}
+
+func printImpacts(catalog Catalog, impacts []handler.MigrationImpact) {
+	catalog.printf("Estimated impact of Up() for %d migrations\n", len(impacts))
+
+	for _, migImpact := range impacts {
+		fmt.Println("")
+		catalog.printf("-- Migration version %d\n", migImpact.Migration.Version())
+
+		if !migImpact.Captured {
+			catalog.printLine(
+				"-- Impact can not be estimated, the migration is not an SQLDryRunner",
+			)
+			continue
+		}
+
+		for _, estimate := range migImpact.Estimates {
+			rows := catalog.tr("unknown")
+			if estimate.EstimatedRows != impact.UnknownRows {
+				rows = strconv.FormatInt(estimate.EstimatedRows, 10)
+			}
+
+			table := estimate.Table
+			if table == "" {
+				table = catalog.tr("unknown")
+			}
+
+			catalog.printf(
+				"%s; -- table: %s, lock: %s, estimated rows: %s\n",
+				estimate.Statement.Query, table, estimate.Lock, rows,
+			)
+		}
+	}
+}
+
+func getVersionFrom(catalog Catalog, args []string) (uint64, error) {
+	if len(args) < 2 {
+		return 0, catalog.newError(
+			"migration version is expected to be the second argument. None provided",
+		)
+	}
+
+	migVersion, err := strconv.Atoi(args[1])
+
+	if err != nil {
+		return 0, catalog.errorf(
+			"migration version must be a valid numeric value. Failed with error: %w", err,
+		)
+	}
+
+	return uint64(migVersion), nil
+}
+
+type MigrateForceUpCommand struct {
+	handler  *handler.MigrationsHandler
+	args     []string
+	workDirs *workDirLog
+	catalog  Catalog
+}
+
+func (c *MigrateForceUpCommand) Name() string {
+	return "force:up"
+}
+
+func (c *MigrateForceUpCommand) Description() string {
+	return "Executes Up() forcefully for the provided migration version" +
+		" (even if it was executed before). With --dry-run, the SQL statements of a database/sql" +
+		" based migration (see SQLDryRunner) are printed instead of executed\n" +
+		"Examples: migrate force:up 1712953077, migrate force:up 1712953077 --dry-run"
+}
+
+func (c *MigrateForceUpCommand) Exec() error {
+	args, dryRun := extractBoolFlag(c.args, "--dry-run")
+	migVersion, err := getVersionFrom(c.catalog, args)
+
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		dryRunMig, err := c.handler.DryRunForceUp(migVersion)
+		if dryRunMig.Migration != nil {
+			printDryRuns(c.catalog, []handler.DryRunMigration{dryRunMig})
+		}
+		return err
+	}
+
+	recorded := c.workDirs.count()
+	exec, err := c.handler.ForceUp(migVersion)
 
 	if exec.Execution != nil {
-		fmt.Printf("Executed Up() forcefully for %d migration\n", exec.Execution.Version)
+		c.catalog.printf("Executed Up() forcefully for %d migration\n", exec.Execution.Version)
 	} else {
-		fmt.Print("No forced Up() migration executed\n")
+		c.catalog.printf("No forced Up() migration executed\n")
 	}
+	c.workDirs.printSince(c.catalog, recorded)
 
 	return err
 }
 
 type MigrateForceDownCommand struct {
-	handler *handler.MigrationsHandler
-	args    []string
+	handler  *handler.MigrationsHandler
+	args     []string
+	workDirs *workDirLog
+	catalog  Catalog
 }
 
 func (c *MigrateForceDownCommand) Name() string {
@@ -373,19 +2714,21 @@ func (c *MigrateForceDownCommand) Description() string {
 }
 
 func (c *MigrateForceDownCommand) Exec() error {
-	migVersion, err := getVersionFrom(c.args)
+	migVersion, err := getVersionFrom(c.catalog, c.args)
 
 	if err != nil {
 		return err
 	}
 
+	recorded := c.workDirs.count()
 	exec, err := c.handler.ForceDown(migVersion)
 
 	if exec.Execution != nil {
-		fmt.Printf("Executed Down() forcefully for %d migration\n", exec.Execution.Version)
+		c.catalog.printf("Executed Down() forcefully for %d migration\n", exec.Execution.Version)
 	} else {
-		fmt.Print("No forced Down() migration executed\n")
+		c.catalog.printf("No forced Down() migration executed\n")
 	}
+	c.workDirs.printSince(c.catalog, recorded)
 
 	return err
 }
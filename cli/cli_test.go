@@ -1,13 +1,24 @@
 package cli
 
 import (
+	"database/sql"
 	"errors"
+	"github.com/rsgcata/go-migrations/clock"
 	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/impact"
 	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/rsgcata/go-migrations/tenant"
 	"github.com/stretchr/testify/suite"
 	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type CliTestSuite struct {
@@ -46,10 +57,48 @@ func (suite *CliTestSuite) TestItCanRunTheGivenCommand() {
 		"help explicit with go run": {[]string{"--", "help"}, helpCmdOutput},
 		"up explicit":               {[]string{"up"}, "Executed Up() for 0 migrations"},
 		"down explicit":             {[]string{"down"}, "Executed Down() for 0 migrations"},
+		"down before": {
+			[]string{"down", "--before=2024-06-01"},
+			"Executed Down() for 0 migrations",
+		},
+		"down before invalid time": {
+			[]string{"down", "--before=yesterday"},
+			"invalid time \"yesterday\"",
+		},
 		"force up up explicit": {
 			[]string{"force:up", "123"},
 			"No forced Up() migration executed",
 		},
+		"state adopt without importer": {
+			[]string{"state:adopt"},
+			"importer name is expected to be the second argument",
+		},
+		"export without directory": {
+			[]string{"export:golang-migrate"},
+			"export directory path is expected to be the second argument",
+		},
+		"up dry run":        {[]string{"up", "all", "--dry-run"}, "Dry-run Up() for 0 migrations"},
+		"validate explicit": {[]string{"validate"}, "No problems found"},
+		"verify reversible": {
+			[]string{"verify:reversible"},
+			"Verified 0 migrations, all are reversible",
+		},
+		"fresh without snapshot flag": {
+			[]string{"fresh"},
+			"only the --from-snapshot mode is supported",
+		},
+		"prune without arguments": {
+			[]string{"prune", "123"},
+			"version, archive directory and at least one state file are expected",
+		},
+		"stats invalid top": {
+			[]string{"stats", "--top=many"},
+			"invalid --top value \"many\"",
+		},
+		"stats unknown format": {
+			[]string{"stats", "--format=xml"},
+			"unknown format \"xml\", expected text or prometheus",
+		},
 		"force down explicit": {
 			[]string{"force:down", "123"},
 			"No forced Down() migration executed",
@@ -79,3 +128,1399 @@ func (suite *CliTestSuite) TestItCanRunTheGivenCommand() {
 		)
 	}
 }
+
+func (suite *CliTestSuite) TestItCanRunWithoutMigrationsDirectory() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings([]string{"up"}, settings)
+	BootstrapWithSettings([]string{"blank"}, settings)
+	BootstrapWithSettings([]string{"prune", "1", "archive", "state.json"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Failed to execute \"blank\" with error: no migrations directory configured"+
+			" (BootstrapSettings.DirPath is empty), the blank command is disabled.",
+	)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Failed to execute \"prune\" with error: no migrations directory configured",
+	)
+}
+
+func (suite *CliTestSuite) TestItCanGenerateSQLScriptFile() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	scriptPath := filepath.Join(suite.T().TempDir(), "pending.sql")
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	Bootstrap(
+		[]string{"script", scriptPath},
+		migration.NewEmptyDirMigrationsRegistry(migPath),
+		&execution.InMemoryRepository{},
+		migPath,
+		nil,
+	)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "Generated SQL script for 0 migrations")
+	suite.Assert().FileExists(scriptPath)
+}
+
+func (suite *CliTestSuite) TestItCanRunCommandsForTenants() {
+	runner := tenant.NewRunner(
+		tenant.StaticProvider{{ID: "acme"}, {ID: "globex"}},
+		func(t tenant.Tenant) (migration.MigrationsRegistry, execution.Repository, error) {
+			return migration.NewGenericRegistry(), &execution.InMemoryRepository{}, nil
+		},
+	)
+
+	scenarios := map[string]struct {
+		inputArgs        []string
+		expectedOutput   []string
+		unexpectedOutput []string
+	}{
+		"all tenants": {
+			[]string{"up", "--all-tenants"},
+			[]string{"Tenant: acme", "Tenant: globex", "Executed Up() for 0 migrations"},
+			[]string{},
+		},
+		"one tenant": {
+			[]string{"down", "--tenant=globex"},
+			[]string{"Tenant: globex", "Executed Down() for 0 migrations"},
+			[]string{"Tenant: acme"},
+		},
+		"unknown tenant": {
+			[]string{"up", "--tenant=acme,unknown"},
+			[]string{tenant.ErrUnknownTenant.Error()},
+			[]string{"Tenant: acme"},
+		},
+		"not allowed command": {
+			[]string{"blank", "--all-tenants"},
+			[]string{"command can not be executed per tenant"},
+			[]string{"Tenant: acme"},
+		},
+		"canary": {
+			[]string{"up", "--canary=globex"},
+			[]string{"Tenant: globex\nExecuted Up() for 0 migrations", "Tenant: acme"},
+			[]string{},
+		},
+		"unknown canary": {
+			[]string{"up", "--tenant=acme", "--canary=globex"},
+			[]string{tenant.ErrUnknownTenant.Error()},
+			[]string{"Tenant: acme"},
+		},
+		"canary not allowed command": {
+			[]string{"down", "--canary=globex"},
+			[]string{"the canary flag can only be used with the up command"},
+			[]string{"Tenant: acme"},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		rescueStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		BootstrapWithSettings(scenario.inputArgs, BootstrapSettings{TenantRunner: runner})
+
+		_ = w.Close()
+		actualOutput, _ := io.ReadAll(r)
+		os.Stdout = rescueStdout
+
+		for _, expected := range scenario.expectedOutput {
+			suite.Assert().Contains(string(actualOutput), expected, "failed scenario %s", name)
+		}
+		for _, unexpected := range scenario.unexpectedOutput {
+			suite.Assert().NotContains(string(actualOutput), unexpected, "failed scenario %s", name)
+		}
+	}
+}
+
+func (suite *CliTestSuite) TestItCanMigrateUpInteractively() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{}
+	migrationsHandler, _ := handler.NewHandler(registry, repo, nil)
+	cmd := &MigrateUpCommand{
+		handler: migrationsHandler,
+		args:    []string{"up", "all", "--interactive"},
+		input:   strings.NewReader("x\na\ns\nb\n"),
+	}
+
+	err := cmd.Exec()
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().ErrorIs(err, handler.ErrRunAborted)
+	suite.Assert().Contains(string(actualOutput), "Migration: migration 1")
+	suite.Assert().Contains(string(actualOutput), "Checksum: N/A")
+	suite.Assert().Contains(string(actualOutput), "Executed Up() for 1 migration")
+	suite.Assert().Contains(string(actualOutput), "Skipped 2 migration")
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+}
+
+func (suite *CliTestSuite) TestItCanBrowseAndRunMigrationsInTheTUI() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{}
+	migrationsHandler, _ := handler.NewHandler(registry, repo, nil)
+	cmd := &TUICommand{
+		handler: migrationsHandler,
+		input: strings.NewReader(
+			"2\n\n" + "a 2\ny\n\n" + "r 1\nn\n\n" + "r 2\nyes\n\n" + "a 1\n\n" + "x 1\n\n" +
+				"9\n" + "q\n",
+		),
+	}
+
+	err := cmd.Exec()
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().NoError(err)
+	for _, expected := range []string{
+		"#  Name         State     Duration  Description",
+		"3  migration 3  pending   -",
+		"Version: 2",
+		"Apply: migration 1\nApply: migration 2\nApply 2 migrations? [y/N]: ",
+		"Executed Up() for migration 2",
+		"Roll back 2 migrations? [y/N]: Cancelled",
+		"Roll back: migration 2\nRoll back 1 migrations? [y/N]: ",
+		"Executed Down() for migration 2",
+		"the migration is already executed",
+		"Unknown action x",
+		"Invalid entry number",
+	} {
+		suite.Assert().Contains(string(actualOutput), expected)
+	}
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+}
+
+func (suite *CliTestSuite) TestItCanGenerateBlankMigrationWithConfiguredClock() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	BootstrapWithSettings(
+		[]string{"blank"},
+		BootstrapSettings{
+			Registry:   migration.NewGenericRegistry(),
+			Repository: &execution.InMemoryRepository{},
+			DirPath:    migPath,
+			Clock:      clock.NewFixed(time.Unix(1712953083, 0)),
+		},
+	)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "version_1712953083.go")
+	suite.Assert().FileExists(filepath.Join(string(migPath), "version_1712953083.go"))
+}
+
+func (suite *CliTestSuite) TestItCanPreviewBlankMigrationsAndGenerateThemInOtherDirs() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	otherDir := suite.T().TempDir()
+	settings := BootstrapSettings{
+		Registry:   migration.NewGenericRegistry(),
+		Repository: &execution.InMemoryRepository{},
+		DirPath:    migPath,
+		Clock:      clock.NewFixed(time.Unix(1712953083, 0)),
+	}
+
+	BootstrapWithSettings([]string{"blank", "--dry-run"}, settings)
+	BootstrapWithSettings([]string{"blank", "--stdout"}, settings)
+	BootstrapWithSettings([]string{"blank", "--dir=" + otherDir}, settings)
+
+	settings.DirPath = ""
+	BootstrapWithSettings([]string{"blank", "--dir=" + otherDir + "/missing"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	entries, _ := os.ReadDir(string(migPath))
+	suite.Assert().Empty(entries)
+	suite.Assert().FileExists(filepath.Join(otherDir, "version_1712953083.go"))
+
+	output := string(actualOutput)
+	suite.Assert().Contains(
+		output,
+		"Would generate "+filepath.Join(string(migPath), "version_1712953083.go")+"\n"+
+			"package "+filepath.Base(string(migPath)),
+	)
+	suite.Assert().Equal(2, strings.Count(output, "func(migration *Migration1712953083) Up()"))
+	suite.Assert().Contains(output, "Failed to execute \"blank\"")
+	suite.Assert().Contains(output, "could not create new migrations directory path")
+}
+
+func (suite *CliTestSuite) TestItCanExportStateAndPruneMigrations() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	_ = os.WriteFile(filepath.Join(string(migPath), migration.FileName(1)), []byte("go"), 0600)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	settings := BootstrapSettings{Registry: registry, Repository: repo, DirPath: migPath}
+	stateFile := filepath.Join(suite.T().TempDir(), "production.json")
+	archiveDir := suite.T().TempDir()
+
+	BootstrapWithSettings([]string{"state:export", stateFile}, settings)
+	BootstrapWithSettings([]string{"prune", "1", archiveDir, stateFile}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "Exported state to "+stateFile)
+	suite.Assert().Contains(string(actualOutput), "Archived 1 migration files")
+	suite.Assert().FileExists(filepath.Join(archiveDir, migration.FileName(1)))
+	suite.Assert().FileExists(filepath.Join(string(migPath), migration.BaselineFileName))
+}
+
+func (suite *CliTestSuite) TestItKeepsTheStateFileWhenTheExportFails() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{
+		Registry: migration.NewGenericRegistry(), Repository: repo, DirPath: migPath,
+	}
+	stateDir := suite.T().TempDir()
+	stateFile := filepath.Join(stateDir, "production.json")
+	_ = os.WriteFile(stateFile, []byte("previous state"), 0600)
+
+	repo.LoadErr = errors.New("load failed")
+	BootstrapWithSettings([]string{"state:export", stateFile}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "failed to write state file")
+	contents, _ := os.ReadFile(stateFile)
+	suite.Assert().Equal("previous state", string(contents))
+	entries, _ := os.ReadDir(stateDir)
+	suite.Assert().Len(entries, 1)
+}
+
+func (suite *CliTestSuite) TestItSkipsMigrationsFromSkipListFile() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	_ = os.WriteFile(
+		filepath.Join(string(migPath), migration.SkipListFileName),
+		[]byte("2 superseded by 3\n"),
+		0600,
+	)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo, DirPath: migPath}
+
+	BootstrapWithSettings([]string{"up", "all"}, settings)
+	BootstrapWithSettings([]string{"stats"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+	suite.Assert().Equal("superseded by 3", repo.PersistedExecutions[1].SkipReason)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Skipped migration: migration 2 (superseded by 3)",
+	)
+}
+
+func (suite *CliTestSuite) TestItPinsMigrationsInTheLockFile() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo, DirPath: migPath}
+
+	BootstrapWithSettings([]string{"lock:write"}, settings)
+	_ = registry.Register(migration.NewDummyMigration(3))
+	BootstrapWithSettings([]string{"validate"}, settings)
+	BootstrapWithSettings([]string{"up", "all", "--locked"}, settings)
+	suite.Assert().Empty(repo.PersistedExecutions)
+	BootstrapWithSettings([]string{"up", "2", "--locked"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().Contains(string(actualOutput), "Pinned 2 migrations in migrations.lock")
+	suite.Assert().Contains(
+		string(actualOutput), "migration 3 is pending, but not pinned by the lock file",
+	)
+	suite.Assert().Contains(string(actualOutput), handler.ErrNotInLockFile.Error())
+}
+
+func (suite *CliTestSuite) TestItRunsScheduledAndDeferredMigrations() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 3; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo, DirPath: migPath}
+
+	BootstrapWithSettings([]string{"up", "--at=2020-01-01T00:00Z"}, settings)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+
+	BootstrapWithSettings(
+		[]string{"up", "--at=2020-01-01T00:00Z", "--until=2020-01-01T01:00Z"}, settings,
+	)
+	BootstrapWithSettings([]string{"up", "--version=3", "--at=2020-01-01T00:00Z", "--defer"}, settings)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+
+	BootstrapWithSettings([]string{"up", "all", "--at=2020-01-01T00:00Z", "--defer"}, settings)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	schedule, found, _ := handler.ReadScheduleFile(migPath)
+	suite.Assert().True(found)
+	suite.Assert().True(schedule.Steps.All)
+
+	BootstrapWithSettings([]string{"schedule:run"}, settings)
+	BootstrapWithSettings([]string{"schedule:run"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+	suite.Assert().Contains(string(actualOutput), handler.ErrScheduleMissed.Error())
+	suite.Assert().Contains(string(actualOutput), "up --defer accepts only the number")
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Scheduled Up() for all migrations at 2020-01-01T00:00:00Z in migrations.schedule.json",
+	)
+	suite.Assert().Contains(string(actualOutput), "Executed Up() for 2 migrations")
+	suite.Assert().Contains(string(actualOutput), "No scheduled run")
+}
+
+type notesRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryNoteStore
+}
+
+func (suite *CliTestSuite) TestItAddsNotesAndShowsThemInTheHistory() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &notesRepository{}
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{
+			Version: 1, ExecutedAtMs: 1717236000000, FinishedAtMs: 1717236001500,
+			Run: execution.RunMetadata{DeployID: "d-7"},
+		},
+	}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings(
+		[]string{"note", "--version=1", "backfill", "verified", "--operator=jane"}, settings,
+	)
+	BootstrapWithSettings([]string{"note", "--version=2", "verified"}, settings)
+	BootstrapWithSettings([]string{"history"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Len(repo.Notes, 1)
+	suite.Assert().Contains(string(actualOutput), "Added note to migration 1")
+	suite.Assert().Contains(string(actualOutput), "the migration was not executed")
+	suite.Assert().Contains(
+		string(actualOutput),
+		"migration 1: executed at 2024-06-01T10:00:00.000Z, finished in 1.5s\n"+
+			"  Run: deploy \"d-7\", git sha \"\", operator \"\"\n"+
+			"  Note by jane at ",
+	)
+	suite.Assert().Contains(string(actualOutput), ": backfill verified\n")
+}
+
+type twoPhaseRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryProgressStore
+}
+
+type twoPhaseMigration struct {
+	migration.DummyMigration
+	committed bool
+}
+
+func (mig *twoPhaseMigration) Prepare() error { return nil }
+func (mig *twoPhaseMigration) Abort() error   { return nil }
+func (mig *twoPhaseMigration) Commit() error {
+	mig.committed = true
+	return nil
+}
+
+func (suite *CliTestSuite) TestItPreparesAndCommitsTwoPhaseMigrations() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	mig := &twoPhaseMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	_ = registry.Register(mig)
+	repo := &twoPhaseRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings([]string{"up", "--commit"}, settings)
+	BootstrapWithSettings([]string{"up", "--prepare"}, settings)
+	suite.Assert().Empty(repo.PersistedExecutions)
+	BootstrapWithSettings([]string{"up", "--commit"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().True(mig.committed)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().Contains(string(actualOutput), handler.ErrNotPrepared.Error())
+	suite.Assert().Contains(string(actualOutput), "Prepared 1 two-phase migrations")
+	suite.Assert().Contains(string(actualOutput), "Executed Up() for 1 migrations")
+}
+
+func (suite *CliTestSuite) TestItRunsAndReportsMigrationsGroupedByRelease() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	_ = os.WriteFile(
+		filepath.Join(string(migPath), migration.ReleasesFileName),
+		[]byte("1 2024.05\n2 2024.06\n3 2024.06\n"),
+		0644,
+	)
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 4; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo, DirPath: migPath}
+
+	BootstrapWithSettings([]string{"up", "--release=2024.06"}, settings)
+	BootstrapWithSettings([]string{"stats"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+	suite.Assert().Contains(string(actualOutput), "Release 2024.05: 1 executed, 0 pending")
+	suite.Assert().Contains(string(actualOutput), "Release 2024.06: 2 executed, 0 pending")
+	suite.Assert().Contains(string(actualOutput), "Release (no release): 0 executed, 1 pending")
+}
+
+func (suite *CliTestSuite) TestItPrintsRunReports() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings([]string{"up"}, settings)
+	BootstrapWithSettings([]string{"up", "--json"}, settings)
+	BootstrapWithSettings([]string{"down", "--before=2000-01-01", "--json"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Len(repo.PersistedExecutions, 0)
+	suite.Assert().Contains(string(actualOutput), "Executed Up() for 1 migration in 0s")
+	suite.Assert().Regexp(
+		"Batch [0-9a-f]{16}: 1 executed, 0 skipped, 0 failed", string(actualOutput),
+	)
+	suite.Assert().Contains(string(actualOutput), `"stage": "up"`)
+	suite.Assert().Contains(string(actualOutput), `"outcome": "executed"`)
+	suite.Assert().Contains(string(actualOutput), `"stage": "down"`)
+}
+
+type loggingMigration struct {
+	migration.DummyMigration
+	logger *slog.Logger
+}
+
+func (l *loggingMigration) SetLogger(logger *slog.Logger) {
+	l.logger = logger
+}
+
+func (l *loggingMigration) Up() error {
+	l.logger.Info("copied rows", "rows", 10)
+	return nil
+}
+
+func (suite *CliTestSuite) TestItPrintsTheMigrationLogsWithTheRunReport() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&loggingMigration{DummyMigration: *migration.NewDummyMigration(1)})
+	settings := BootstrapSettings{Registry: registry, Repository: &execution.InMemoryRepository{}}
+
+	BootstrapWithSettings([]string{"up"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Executed Up() for 1 migration in 0s\n  INFO copied rows rows=10\n",
+	)
+}
+
+type workDirMigration struct {
+	migration.DummyMigration
+	workDir string
+}
+
+func (m *workDirMigration) SetWorkDir(dir string) {
+	m.workDir = dir
+}
+
+func (suite *CliTestSuite) TestItPrintsAndAuditsTheWorkDirectories() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	root := suite.T().TempDir()
+	mig := &workDirMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	auditLog := &execution.InMemoryAuditLog{}
+	settings := BootstrapSettings{
+		Registry:    registry,
+		Repository:  &execution.InMemoryRepository{},
+		AuditLog:    auditLog,
+		WorkDirRoot: root,
+	}
+
+	BootstrapWithSettings([]string{"up"}, settings)
+	upWorkDir := mig.workDir
+	BootstrapWithSettings([]string{"force:down", "1", "--work-dir=" + root}, settings)
+	settings.WorkDirRoot = filepath.Join(root, "missing")
+	BootstrapWithSettings([]string{"force:up", "1", "--work-dir=" + root}, settings)
+	printWorkDir(nil, "/tmp/go-migrations-1-up-1", errors.New("permission denied"))
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Equal(root, filepath.Dir(upWorkDir))
+	suite.Assert().Contains(
+		string(actualOutput), "  Work directory: "+upWorkDir+" (removed)\n",
+	)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Executed Down() forcefully for 1 migration\n  Work directory: "+root,
+	)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Executed Up() forcefully for 1 migration\n  Work directory: "+mig.workDir+" (removed)",
+	)
+
+	suite.Require().Len(auditLog.Entries, 3)
+	suite.Assert().Equal([]string{upWorkDir}, auditLog.Entries[0].WorkDirs)
+	suite.Require().Len(auditLog.Entries[1].WorkDirs, 1)
+	suite.Assert().True(
+		strings.HasPrefix(filepath.Base(auditLog.Entries[1].WorkDirs[0]), "go-migrations-1-down-"),
+	)
+	suite.Assert().Equal([]string{mig.workDir}, auditLog.Entries[2].WorkDirs)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"  Work directory: /tmp/go-migrations-1-up-1 (not removed: permission denied)\n",
+	)
+}
+
+func (suite *CliTestSuite) TestItPrintsMigrationDurationStats() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1000, FinishedAtMs: 3000},
+			{Version: 2, ExecutedAtMs: 3000, FinishedAtMs: 3500},
+			{Version: 3, ExecutedAtMs: 4000, FinishedAtMs: 10000},
+		},
+	}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings([]string{"stats", "--top=2"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Total time spent migrating: 8.5s\n"+
+			"Average migration duration: 2.833s\n"+
+			"Slow migration: migration 3 (6s)\n"+
+			"Slow migration: migration 1 (2s)\n",
+	)
+	suite.Assert().NotContains(string(actualOutput), "migration 2 (")
+}
+
+func (suite *CliTestSuite) TestItExportsPrometheusMetrics() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1712953083))
+	_ = registry.Register(migration.NewDummyMigration(1712953084))
+	_ = registry.Register(migration.NewDummyMigration(1712953085))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1712953083, ExecutedAtMs: 1717236000000, FinishedAtMs: 1717236001500},
+			{Version: 1712953084, ExecutedAtMs: 1717236002000, FinishedAtMs: 1717236002250},
+		},
+	}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+	output := filepath.Join(suite.T().TempDir(), "migrations.prom")
+
+	BootstrapWithSettings([]string{"stats", "--format=prometheus"}, settings)
+	BootstrapWithSettings(
+		[]string{"stats", "--format=prometheus", "--output=" + output}, settings,
+	)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	expected := "# HELP go_migrations_current_version Version of the last executed migration," +
+		" 0 if none was executed.\n" +
+		"# TYPE go_migrations_current_version gauge\n" +
+		"go_migrations_current_version 1712953084\n"
+	suite.Assert().Contains(string(actualOutput), expected)
+	suite.Assert().Contains(string(actualOutput), "go_migrations_dirty 0\n")
+	suite.Assert().Contains(string(actualOutput), "go_migrations_registered 3\n")
+	suite.Assert().Contains(string(actualOutput), "go_migrations_executed 2\n")
+	suite.Assert().Contains(string(actualOutput), "go_migrations_pending 1\n")
+	suite.Assert().Contains(
+		string(actualOutput), "go_migrations_last_run_timestamp_seconds 1717236002.25\n",
+	)
+	suite.Assert().Contains(
+		string(actualOutput), "go_migrations_last_run_duration_seconds 0.25\n",
+	)
+	suite.Assert().Contains(string(actualOutput), "go_migrations_duration_seconds 1.75\n")
+	suite.Assert().NotContains(string(actualOutput), "Registered migrations count")
+	suite.Assert().Contains(string(actualOutput), "Exported metrics to "+output)
+
+	written, err := os.ReadFile(output)
+	suite.Assert().NoError(err)
+	suite.Assert().True(strings.HasPrefix(string(written), expected))
+	info, err := os.Stat(output)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(os.FileMode(0644), info.Mode().Perm())
+	entries, _ := os.ReadDir(filepath.Dir(output))
+	suite.Assert().Len(entries, 1)
+	suite.Assert().NotContains(string(actualOutput), "go_migrations_run_lock_held")
+	suite.Assert().Contains(string(actualOutput), "go_migrations_inconsistent 0\n")
+}
+
+func (suite *CliTestSuite) TestItExportsTheInconsistentGaugeForInconsistentExecutions() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1712953083))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1712953084, ExecutedAtMs: 1717236000000, FinishedAtMs: 1717236001500},
+		},
+	}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings([]string{"stats", "--format=prometheus"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "go_migrations_inconsistent 1\n")
+	suite.Assert().Contains(string(actualOutput), "go_migrations_duration_seconds 1.5\n")
+	suite.Assert().NotContains(string(actualOutput), "go_migrations_current_version")
+	suite.Assert().NotContains(string(actualOutput), handler.ErrPlanInconsistent.Error())
+}
+
+type lockingRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryLocker
+}
+
+func (suite *CliTestSuite) TestItReportsTheRunLockHolderInStats() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &lockingRepository{}
+	settings := BootstrapSettings{
+		Registry:       registry,
+		Repository:     repo,
+		HandlerOptions: []handler.Option{handler.WithExclusiveLock()},
+	}
+
+	BootstrapWithSettings([]string{"stats"}, settings)
+	repo.Held, repo.Owner, repo.AcquiredAtMs = true, "ci-1:42", 1717236000000
+	BootstrapWithSettings([]string{"stats"}, settings)
+	BootstrapWithSettings([]string{"stats", "--format=prometheus"}, settings)
+
+	settings.Repository = &execution.InMemoryRepository{}
+	BootstrapWithSettings([]string{"stats"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "Run lock: free\n")
+	suite.Assert().Contains(
+		string(actualOutput), "Run lock: held by ci-1:42 since 2024-06-01T10:00:00.000Z\n",
+	)
+	suite.Assert().Contains(string(actualOutput), "go_migrations_run_lock_held 1\n")
+	suite.Assert().Contains(
+		string(actualOutput), "go_migrations_run_lock_acquired_timestamp_seconds 1717236000\n",
+	)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Run lock: unknown, the repository does not report the lock holder\n",
+	)
+}
+
+func (suite *CliTestSuite) TestItHoldsTheConfiguredLockerDuringRuns() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+	locker := &execution.InMemoryLocker{Held: true}
+	settings := BootstrapSettings{Registry: registry, Repository: repo, Locker: locker}
+
+	BootstrapWithSettings([]string{"up"}, settings)
+	locker.Held = false
+	BootstrapWithSettings([]string{"up"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "failed to acquire the migrations lock")
+	suite.Assert().Contains(string(actualOutput), "Executed Up() for 1 migrations\n")
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().False(locker.Held)
+}
+
+func (suite *CliTestSuite) TestItCanDiffEnvironmentStates() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	stateDir := suite.T().TempDir()
+	writeState := func(name string, versions ...uint64) string {
+		var executions []execution.MigrationExecution
+		for _, version := range versions {
+			executions = append(
+				executions,
+				execution.MigrationExecution{Version: version, ExecutedAtMs: 1, FinishedAtMs: 1},
+			)
+		}
+
+		stateFile := filepath.Join(stateDir, name+".json")
+		file, _ := os.Create(stateFile)
+		_ = execution.EncodeExecutions(file, executions)
+		_ = file.Close()
+		return stateFile
+	}
+	staging := writeState("staging", 1, 2)
+	production := writeState("production", 1)
+
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	settings := BootstrapSettings{Registry: migration.NewGenericRegistry(), Repository: repo}
+
+	BootstrapWithSettings([]string{"state:diff", staging, production}, settings)
+	BootstrapWithSettings([]string{"state:diff", production}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Executed only in staging: 1\nmigration 2\nExecuted only in production: 0\n",
+	)
+	suite.Assert().Contains(string(actualOutput), handler.ErrEnvironmentsOutOfSync.Error())
+	suite.Assert().Contains(string(actualOutput), "current and production are in sync")
+}
+
+func (suite *CliTestSuite) TestItStoresRunMetadataFromFlags() {
+	rescueStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings(
+		[]string{"up", "--deploy-id=deploy-12", "--git-sha=a1b2c3", "--operator=jane"}, settings,
+	)
+
+	_ = w.Close()
+	os.Stdout = rescueStdout
+
+	executions, _ := repo.LoadExecutions()
+	suite.Assert().Len(executions, 1)
+	suite.Assert().Equal(
+		execution.RunMetadata{DeployID: "deploy-12", GitSHA: "a1b2c3", Operator: "jane"},
+		executions[0].Run,
+	)
+}
+
+func (suite *CliTestSuite) TestItAppendsAnAuditEntryForEachInvocation() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	auditLog := &execution.InMemoryAuditLog{}
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	settings := BootstrapSettings{
+		Registry:   registry,
+		Repository: &execution.InMemoryRepository{},
+		Clock:      clock.NewFixed(now),
+		AuditLog:   auditLog,
+	}
+
+	BootstrapWithSettings([]string{"up", "1", "--operator=jane"}, settings)
+	failing := settings
+	failing.Repository = &execution.InMemoryRepository{SaveErr: errors.New("connection lost")}
+	BootstrapWithSettings([]string{"up"}, failing)
+	BootstrapWithSettings([]string{"unknown"}, settings)
+
+	auditLog.AppendErr = errors.New("disk full")
+	BootstrapWithSettings([]string{"stats"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Require().Len(auditLog.Entries, 3)
+	host, _ := os.Hostname()
+	suite.Assert().Equal(
+		execution.AuditEntry{
+			Time:     now,
+			Command:  "up",
+			Args:     []string{"up", "1", "--operator=jane"},
+			User:     currentUser(),
+			Host:     host,
+			Operator: "jane",
+			Result:   execution.AuditResultSuccess,
+		},
+		auditLog.Entries[0],
+	)
+	suite.Assert().Equal(execution.AuditResultFailure, auditLog.Entries[1].Result)
+	suite.Assert().Contains(auditLog.Entries[1].Error, "connection lost")
+	suite.Assert().Equal("help", auditLog.Entries[2].Command)
+	suite.Assert().Equal([]string{"unknown"}, auditLog.Entries[2].Args)
+	suite.Assert().Contains(
+		string(actualOutput), "Failed to write the audit log entry with error: disk full",
+	)
+}
+
+func (suite *CliTestSuite) TestItEnforcesGuardrailsInProtectedEnvironments() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	settings := BootstrapSettings{
+		Registry:    registry,
+		Repository:  repo,
+		Environment: "production",
+		Guardrails:  handler.Guardrails{Protected: []string{"production"}},
+	}
+
+	BootstrapWithSettings([]string{"down"}, settings)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+
+	BootstrapWithSettings([]string{"down", "--allow-destructive"}, settings)
+	suite.Assert().Len(repo.PersistedExecutions, 0)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "down in production requires explicit approval")
+	suite.Assert().Contains(string(actualOutput), "Executed Down() for 1 migrations")
+}
+
+func (suite *CliTestSuite) TestItRejectsChangesInReadOnlyMode() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings([]string{"up", "--read-only"}, settings)
+	suite.Assert().Len(repo.PersistedExecutions, 0)
+
+	settings.ReadOnly = true
+	BootstrapWithSettings([]string{"stats"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "repository is read-only: up is not allowed")
+	suite.Assert().NotContains(string(actualOutput), "Failed to execute \"stats\"")
+}
+
+func (suite *CliTestSuite) TestItExtractsTheNumberOfMigrationsToRun() {
+	scenarios := map[string]struct {
+		args        []string
+		expected    handler.NumOfRuns
+		expectedErr bool
+	}{
+		"default":             {[]string{"up"}, 1, false},
+		"argument":            {[]string{"up", "3"}, 3, false},
+		"all argument":        {[]string{"up", "all"}, handler.AllRuns, false},
+		"steps flag":          {[]string{"up", "--steps=4"}, 4, false},
+		"all steps flag":      {[]string{"down", "--steps=all"}, handler.AllRuns, false},
+		"zero":                {[]string{"up", "0"}, 0, true},
+		"negative steps flag": {[]string{"up", "--steps=-1"}, 0, true},
+		"not a number":        {[]string{"down", "--steps=few"}, 0, true},
+		"flag and argument":   {[]string{"up", "2", "--steps=3"}, 0, true},
+	}
+
+	for name, scenario := range scenarios {
+		numOfRuns, err := extractSteps(nil, scenario.args, "1")
+		suite.Assert().Equal(scenario.expected, numOfRuns, "failed scenario: %s", name)
+		if scenario.expectedErr {
+			suite.Assert().ErrorIs(err, handler.ErrInvalidSteps, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
+	}
+
+	numOfRuns, err := extractSteps(nil, []string{"verify:reversible"}, "all")
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(handler.AllRuns, numOfRuns)
+}
+
+func (suite *CliTestSuite) TestItResolvesTheMigrationsToRunFromVersionsAndRanges() {
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 4; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+			{Version: 2, ExecutedAtMs: 2, FinishedAtMs: 2},
+		},
+	}
+	migrationsHandler, _ := handler.NewHandler(
+		registry, repo, nil,
+		handler.WithReleases(migration.Releases{1: "2024.05", 2: "2024.05", 3: "2024.06"}),
+	)
+
+	up, down := handler.StageUp, handler.StageDown
+	scenarios := map[string]struct {
+		args        []string
+		stage       handler.MigrationStage
+		expected    handler.NumOfRuns
+		expectedErr bool
+	}{
+		"up to version":     {[]string{"up", "--version=4"}, up, 2, false},
+		"down to version":   {[]string{"down", "--version=1"}, down, 2, false},
+		"up range":          {[]string{"up", "--range=3..3"}, up, 1, false},
+		"down range":        {[]string{"down", "--range=2..2"}, down, 1, false},
+		"steps":             {[]string{"up", "all"}, up, handler.AllRuns, false},
+		"invalid version":   {[]string{"up", "--version=x"}, up, 0, true},
+		"invalid range":     {[]string{"up", "--range=4..3"}, up, 0, true},
+		"version and range": {[]string{"up", "--version=4", "--range=3..4"}, up, 0, true},
+		"version and steps": {[]string{"up", "2", "--version=4"}, up, 0, true},
+		"range and steps":   {[]string{"down", "--steps=1", "--range=2..2"}, down, 0, true},
+		"up to release":     {[]string{"up", "--release=2024.06"}, up, 1, false},
+		"down to release":   {[]string{"down", "--release=2024.05"}, down, 2, false},
+		"unknown release":   {[]string{"up", "--release=2025.01"}, up, 0, true},
+		"release and range": {[]string{"up", "--release=2024.06", "--range=3..3"}, up, 0, true},
+	}
+
+	for name, scenario := range scenarios {
+		var numOfRuns handler.NumOfRuns
+		target, err := extractRuns(nil, scenario.args, scenario.stage)
+		if err == nil {
+			numOfRuns, err = migrationsHandler.ResolveTarget(target)
+		}
+		suite.Assert().Equal(scenario.expected, numOfRuns, "failed scenario: %s", name)
+		if scenario.expectedErr {
+			suite.Assert().ErrorIs(err, handler.ErrInvalidTarget, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
+	}
+}
+
+func (suite *CliTestSuite) TestItRunsInformationalCommandsWithoutInitializingTheRepository() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{InitErr: errors.New("CREATE command denied")}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	suite.Assert().NotPanics(func() {
+		BootstrapWithSettings([]string{"stats"}, settings)
+		BootstrapWithSettings([]string{"validate"}, settings)
+	})
+	suite.Assert().Panics(func() {
+		BootstrapWithSettings([]string{"up"}, settings)
+	})
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().NotContains(string(actualOutput), "Failed to execute")
+}
+
+func (suite *CliTestSuite) TestItPrintsMigrationFailuresWithRemediationHints() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{SaveErr: errors.New("connection lost")}
+	settings := BootstrapSettings{
+		Registry: registry, Repository: repo, Clock: clock.NewFixed(time.Now()),
+	}
+
+	BootstrapWithSettings([]string{"up"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Migration failure\n"+
+			"  Migration: migration 1\n"+
+			"  Direction: up\n"+
+			"  Stage:     save\n"+
+			"  Elapsed:   0s\n"+
+			"  Error:     connection lost\n"+
+			"Hint: The changes of Up() are applied, but the execution was not saved.",
+	)
+	suite.Assert().Contains(string(actualOutput), "then add 1 to the skip list")
+}
+
+func (suite *CliTestSuite) TestItSuggestsFixesForVersionGaps() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+
+	BootstrapWithSettings([]string{"up"}, settings)
+	BootstrapWithSettings([]string{"validate"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "Hint: run \"validate\"")
+	suite.Assert().Contains(
+		string(actualOutput),
+		"- migration 1 is pending, but migration 2, ordered after it, was executed."+
+			" Suggested fixes: ",
+	)
+}
+
+func (suite *CliTestSuite) TestItPrintsRemainingMigrationsWhenRunIsThrottled() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	window, _ := handler.ParseWindow("09:00-10:00", nil)
+	settings := BootstrapSettings{
+		Registry:   registry,
+		Repository: repo,
+		Clock:      clock.NewFixed(time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)),
+		Throttle:   handler.Throttle{Window: &window},
+	}
+
+	BootstrapWithSettings([]string{"up", "all"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Empty(repo.PersistedExecutions)
+	suite.Assert().Contains(
+		string(actualOutput), "Run stopped, outside the allowed time window 09:00-10:00",
+	)
+	suite.Assert().Contains(string(actualOutput), "Remaining: migration 1")
+	suite.Assert().Contains(string(actualOutput), "Remaining: migration 2")
+}
+
+type alterMigration struct {
+	migration.DummyMigration
+	db *sql.DB
+}
+
+func (m *alterMigration) Up() error {
+	_, err := m.db.Exec("ALTER TABLE users ADD COLUMN age INT")
+	return err
+}
+
+func (m *alterMigration) WithDB(db *sql.DB) migration.Migration {
+	return &alterMigration{m.DummyMigration, db}
+}
+
+func (suite *CliTestSuite) TestItCanEstimateImpactOfPendingMigrations() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&alterMigration{DummyMigration: *migration.NewDummyMigration(1)})
+	repo := &execution.InMemoryRepository{}
+	analyzerDb, _ := sqlcapture.NewDB()
+	defer func() { _ = analyzerDb.Close() }()
+
+	settings := BootstrapSettings{Registry: registry, Repository: repo}
+	BootstrapWithSettings([]string{"up", "all", "--impact"}, settings)
+
+	settings.ImpactAnalyzer = &impact.MysqlAnalyzer{DB: analyzerDb}
+	BootstrapWithSettings([]string{"up", "all", "--impact"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Contains(string(actualOutput), "no impact analyzer was configured")
+	suite.Assert().Contains(
+		string(actualOutput),
+		"ALTER TABLE users ADD COLUMN age INT;"+
+			" -- table: users, lock: table, estimated rows: unknown",
+	)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *CliTestSuite) TestItPrintsMessagesFromTheCatalog() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	settings := BootstrapSettings{
+		Registry:   registry,
+		Repository: &execution.InMemoryRepository{},
+		Clock:      clock.NewFixed(time.Now()),
+		Catalog: Catalog{
+			"Executed %s() for %d migrations\n":         "%[2]d migrations exécutées, %[1]s()\n",
+			"Failed to execute \"%s\" with error: %s\n": "Échec de \"%s\" : %s\n",
+			"invalid --top value %q, expected a number": "valeur --top %q invalide",
+		},
+	}
+
+	run := func(args ...string) string {
+		rescueStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		BootstrapWithSettings(args, settings)
+
+		_ = w.Close()
+		actualOutput, _ := io.ReadAll(r)
+		os.Stdout = rescueStdout
+		return string(actualOutput)
+	}
+
+	output := run("up", "all")
+	suite.Assert().Contains(output, "2 migrations exécutées, Up()\n")
+	// Messages which are not translated are printed in English
+	suite.Assert().Contains(output, "Executed Up() for 1 migration in 0s\n")
+
+	suite.Assert().Contains(
+		run("stats", "--top=many"), "Échec de \"stats\" : valeur --top \"many\" invalide\n",
+	)
+
+	settings.Catalog = Catalog{
+		"failed to write metrics file with error: %w": "échec du fichier de métriques : %v",
+	}
+	suite.Assert().PanicsWithError(
+		"could not bootstrap cli, invalid catalog: the translation "+
+			`"échec du fichier de métriques : %v" of `+
+			`"failed to write metrics file with error: %w" does not use the same verbs`,
+		func() { BootstrapWithSettings([]string{"stats"}, settings) },
+	)
+
+	settings.Catalog = nil
+	suite.Assert().Contains(
+		run("stats", "--top=many"),
+		"Failed to execute \"stats\" with error: invalid --top value \"many\", expected a number",
+	)
+}
+
+func (suite *CliTestSuite) TestItKeepsTheCatalogPerInvocation() {
+	french := &MigrateStatsCommand{
+		args: []string{"stats", "--top=many"}, catalog: Catalog{
+			"invalid --top value %q, expected a number": "valeur --top %q invalide",
+		},
+	}
+	english := &MigrateStatsCommand{args: []string{"stats", "--top=many"}}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, cmd := range []*MigrateStatsCommand{french, english} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = cmd.Exec()
+		}()
+	}
+	wg.Wait()
+
+	suite.Assert().EqualError(errs[0], `valeur --top "many" invalide`)
+	suite.Assert().EqualError(errs[1], `invalid --top value "many", expected a number`)
+}
+
+func (suite *CliTestSuite) TestItReleasesTheMigrationsResourcesWhenDone() {
+	rescueStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	closed := 0
+	settings := BootstrapSettings{
+		Registry:   migration.NewGenericRegistry(),
+		Repository: &execution.InMemoryRepository{},
+		Close: func() error {
+			closed++
+			return errors.New("connection reset")
+		},
+	}
+	BootstrapWithSettings([]string{"stats"}, settings)
+
+	_ = w.Close()
+	actualOutput, _ := io.ReadAll(r)
+	os.Stdout = rescueStdout
+
+	suite.Assert().Equal(1, closed)
+	suite.Assert().Contains(
+		string(actualOutput),
+		"Failed to release the migrations resources with error: connection reset",
+	)
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+)
+
+// Catalog Translations of the CLI user-facing messages (flag and argument errors, summaries,
+// prompts, command descriptions), keyed by the English message. For formatted messages, the key
+// is the English format string (for example, "Executed %s() for %d migrations\n") and the
+// translation must use the same verbs, in any order, with explicit argument indexes (for
+// example, "%[2]d migrations, %[1]s() executed\n"). Messages without a translation are printed
+// in English. Errors returned by the handler and the repositories are not translated, they are
+// printed as they are, after the translated context. Bootstrapping the CLI fails if a translation
+// does not use the same verbs as its message, so wrapped errors (%w) are never lost.
+type Catalog map[string]string
+
+// validate Checks that the translations use the same verbs, for the same arguments, as their
+// messages
+func (catalog Catalog) validate() error {
+	for message, translated := range catalog {
+		if translated != "" && !maps.Equal(formatVerbs(message), formatVerbs(translated)) {
+			return fmt.Errorf(
+				"the translation %q of %q does not use the same verbs", translated, message,
+			)
+		}
+	}
+	return nil
+}
+
+// formatVerbs Returns the verbs of the format string, keyed by the index of their argument
+func formatVerbs(format string) map[int]rune {
+	verbs := map[int]rune{}
+	argIndex := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		for i++; i < len(format); i++ {
+			char := rune(format[i])
+			switch {
+			case char == '[':
+				end := i + 1
+				for end < len(format) && format[end] != ']' {
+					end++
+				}
+				if index, err := strconv.Atoi(format[i+1 : end]); err == nil {
+					argIndex = index - 1
+				}
+				i = end
+				continue
+			case char == '*':
+				verbs[argIndex] = char
+				argIndex++
+				continue
+			case char == '%' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z'):
+			default:
+				continue
+			}
+
+			if char != '%' {
+				verbs[argIndex] = char
+				argIndex++
+			}
+			break
+		}
+	}
+	return verbs
+}
+
+// tr Returns the translation of the message, or the message itself if it is not translated
+func (catalog Catalog) tr(message string) string {
+	if translated, ok := catalog[message]; ok && translated != "" {
+		return translated
+	}
+	return message
+}
+
+func (catalog Catalog) printf(format string, args ...any) {
+	fmt.Printf(catalog.tr(format), args...)
+}
+
+func (catalog Catalog) printLine(message string) {
+	fmt.Println(catalog.tr(message))
+}
+
+func (catalog Catalog) errorf(format string, args ...any) error {
+	return fmt.Errorf(catalog.tr(format), args...)
+}
+
+func (catalog Catalog) newError(message string) error {
+	return errors.New(catalog.tr(message))
+}
@@ -0,0 +1,82 @@
+// Package clock includes the time source abstraction used for execution timestamps and blank
+// migration versions, so time can be controlled in tests.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock Must return the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System Clock implementation which returns the system time
+type System struct{}
+
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Fixed Clock implementation which returns the same time until it is changed via Set or
+// Advance. Safe for concurrent use.
+type Fixed struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFixed Creates a new Fixed clock, frozen at the provided time
+func NewFixed(now time.Time) *Fixed {
+	return &Fixed{now: now}
+}
+
+func (f *Fixed) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set Freezes the clock at the provided time
+func (f *Fixed) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance Moves the clock forward with the provided duration
+func (f *Fixed) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Offset Clock implementation which shifts the time of a base clock with a fixed offset, for
+// example, to follow the clock of the database server (see SyncWith)
+type Offset struct {
+	base   Clock
+	offset time.Duration
+}
+
+// SyncWith Builds an Offset clock which follows the time returned by the source (for example,
+// the database server time). The source is read once and the offset from the base clock is
+// computed assuming the time was read half way through the source round trip.
+func SyncWith(base Clock, source func() (time.Time, error)) (*Offset, error) {
+	before := base.Now()
+	sourceNow, err := source()
+	if err != nil {
+		return nil, err
+	}
+	after := base.Now()
+
+	return &Offset{base: base, offset: sourceNow.Sub(before.Add(after.Sub(before) / 2))}, nil
+}
+
+func (o *Offset) Now() time.Time {
+	return o.base.Now().Add(o.offset)
+}
+
+// Skew The duration the source clock was ahead of the base clock when synced (negative if behind)
+func (o *Offset) Skew() time.Duration {
+	return o.offset
+}
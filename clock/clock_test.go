@@ -0,0 +1,62 @@
+package clock
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ClockTestSuite struct {
+	suite.Suite
+}
+
+func TestClockTestSuite(t *testing.T) {
+	suite.Run(t, new(ClockTestSuite))
+}
+
+func (suite *ClockTestSuite) TestSystemClockReturnsCurrentTime() {
+	before := time.Now()
+	now := System{}.Now()
+	suite.Assert().False(now.Before(before))
+	suite.Assert().False(now.After(time.Now()))
+}
+
+func (suite *ClockTestSuite) TestFixedClockCanBeControlled() {
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	fixed := NewFixed(start)
+
+	suite.Assert().Equal(start, fixed.Now())
+	suite.Assert().Equal(start, fixed.Now())
+
+	fixed.Advance(time.Second)
+	suite.Assert().Equal(start.Add(time.Second), fixed.Now())
+
+	fixed.Set(start)
+	suite.Assert().Equal(start, fixed.Now())
+}
+
+func (suite *ClockTestSuite) TestOffsetClockFollowsTheSourceClock() {
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	base := NewFixed(start)
+
+	synced, err := SyncWith(base, func() (time.Time, error) {
+		return start.Add(-3 * time.Second), nil
+	})
+
+	suite.Require().NoError(err)
+	suite.Assert().Equal(-3*time.Second, synced.Skew())
+	suite.Assert().Equal(start.Add(-3*time.Second), synced.Now())
+	base.Advance(time.Minute)
+	suite.Assert().Equal(start.Add(57*time.Second), synced.Now())
+}
+
+func (suite *ClockTestSuite) TestItFailsToSyncWhenTheSourceTimeCanNotBeRead() {
+	synced, err := SyncWith(System{}, func() (time.Time, error) {
+		return time.Time{}, errors.New("connection lost")
+	})
+
+	suite.Assert().Nil(synced)
+	suite.Assert().ErrorContains(err, "connection lost")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/scaffold"
+)
+
+// initCommand The command which scaffolds a migrations setup. It does not need a DSN, so it is
+// handled before the settings are built.
+const initCommand = "init"
+
+// initUsage The usage of the init command
+const initUsage = "Usage: migrate init --db=<mysql|mongo> [--main] [--module=<path>]" +
+	" [<project dir>]"
+
+// runInit Scaffolds a migrations setup (see the scaffold package) in the project directory,
+// which defaults to the current directory, for the --db database. The setup runs file migrations
+// with this binary, unless --main is provided, in which case Go migrations and a main.go, which
+// imports the migrations package from the --module module (defaults to the go.mod module), are
+// generated. Existing files are never overwritten.
+func runInit(args []string, out io.Writer) error {
+	opts := scaffold.Options{Standalone: true}
+	projectDir := "."
+	var positional []string
+
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch name {
+		case "--db":
+			opts.Database = value
+		case "--module":
+			opts.ModulePath = value
+		case "--main":
+			opts.Standalone = false
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("unknown init flag %s", name)
+			}
+			positional = append(positional, arg)
+		}
+	}
+
+	if opts.Database == "" {
+		return errors.New("no database provided, it must be set via --db")
+	}
+
+	if len(positional) > 1 {
+		return errors.New("only one project directory can be provided")
+	} else if len(positional) == 1 {
+		projectDir = positional[0]
+	}
+
+	files, err := scaffold.Generate(projectDir, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		_, _ = fmt.Fprintln(out, "Created "+file.Path)
+	}
+
+	_, _ = fmt.Fprintln(out, "\nNext steps:")
+	if opts.Standalone {
+		_, _ = fmt.Fprintln(out, "  docker compose up -d --wait")
+		_, _ = fmt.Fprintln(out, "  . ./migrate.env && migrate up")
+		_, _ = fmt.Fprintln(
+			out, "  scripts/test-migrations.sh (checks that all migrations are reversible)",
+		)
+	} else {
+		_, _ = fmt.Fprintln(out, "  go get github.com/rsgcata/go-migrations && go mod tidy")
+		_, _ = fmt.Fprintln(out, "  docker compose up -d --wait")
+		_, _ = fmt.Fprintln(out, "  go run ./cmd/migrations up")
+		_, _ = fmt.Fprintln(
+			out, "  MIGRATIONS_TEST=1 go test ./cmd/migrations (checks that all migrations are"+
+				" reversible)",
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InitTestSuite struct {
+	suite.Suite
+}
+
+func TestInitTestSuite(t *testing.T) {
+	suite.Run(t, new(InitTestSuite))
+}
+
+func (suite *InitTestSuite) TestItScaffoldsASetup() {
+	projectDir := suite.T().TempDir()
+	var out bytes.Buffer
+
+	suite.Require().NoError(runInit([]string{"--db=mongo", projectDir}, &out))
+	suite.Assert().Contains(out.String(), "Created migrate.env")
+	suite.Assert().Contains(out.String(), "migrate up")
+	_, err := os.Stat(filepath.Join(projectDir, "scripts", "test-migrations.sh"))
+	suite.Assert().NoError(err)
+
+	out.Reset()
+	suite.Require().NoError(
+		runInit(
+			[]string{"--db=mysql", "--main", "--module=example.com/app", projectDir + "/app"},
+			&out,
+		),
+	)
+	suite.Assert().Contains(out.String(), "Created cmd/migrations/main.go")
+	suite.Assert().Contains(out.String(), "go run ./cmd/migrations up")
+}
+
+func (suite *InitTestSuite) TestItFailsForInvalidArgs() {
+	scenarios := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"no database":  {[]string{}, "no database provided"},
+		"unknown flag": {[]string{"--db=mysql", "--dsn=mysql://"}, "unknown init flag --dsn"},
+		"many dirs":    {[]string{"--db=mysql", "a", "b"}, "only one project directory"},
+		"invalid db":   {[]string{"--db=oracle", suite.T().TempDir()}, "unsupported database"},
+		"no module": {
+			[]string{"--db=mysql", "--main", suite.T().TempDir()}, "no module path provided",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Assert().ErrorContains(
+			runInit(scenario.args, &bytes.Buffer{}), scenario.expected, name,
+		)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	_ "github.com/rsgcata/go-migrations/execution/repository/mongo"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/mongohelpers"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func init() {
+	fileRunners["mongodb"] = newMongoRunner
+	fileRunners["mongodb+srv"] = newMongoRunner
+}
+
+// newMongoRunner Runs the .json migration files (arrays of database commands) against the
+// database from the DSN path, through a client which is separate from the one used by the
+// repository
+func newMongoRunner(cfg config) (string, migration.FileRunner, func() error, error) {
+	if !cfg.session.IsZero() {
+		return "", nil, nil, errors.New("session settings are only supported for SQL databases")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.dsn))
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	db := client.Database(strings.TrimPrefix(cfg.url.Path, "/"))
+	run := func(contents string) error {
+		return mongohelpers.RunCommands(ctx, db, contents)
+	}
+	return ".json", run, func() error { return client.Disconnect(ctx) }, nil
+}
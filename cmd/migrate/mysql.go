@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/rsgcata/go-migrations/execution/repository/mysql"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/online"
+	"github.com/rsgcata/go-migrations/sqlhelpers"
+)
+
+func init() {
+	fileRunners["mysql"] = newMysqlRunner
+	fileRunners["mariadb"] = newMysqlRunner
+	fileRunners["tidb"] = newMysqlRunner
+}
+
+// newMysqlRunner Runs the .sql migration files through a db handle which is separate from the
+// one used by the repository. If session settings are configured, the files run on a dedicated
+// connection, with the settings applied, which is kept open until the runner is closed.
+func newMysqlRunner(cfg config) (string, migration.FileRunner, func() error, error) {
+	query := cfg.url.Query()
+	query.Del("galera")
+	withoutGalera := *cfg.url
+	withoutGalera.RawQuery = query.Encode()
+
+	dsn, err := mysql.DSNFromURL(&withoutGalera)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	session := cfg.session
+	if session.IsZero() {
+		return ".sql", func(contents string) error {
+			return sqlhelpers.ExecScript(context.Background(), db, contents)
+		}, db.Close, nil
+	}
+
+	if cfg.scheme == "mariadb" && session.StatementTimeout > 0 {
+		// MariaDB does not have max_execution_time, it limits all statements via
+		// max_statement_time, in seconds
+		seconds := strconv.FormatFloat(session.StatementTimeout.Seconds(), 'f', -1, 64)
+		session.Extra = map[string]string{"max_statement_time": seconds}
+		session.StatementTimeout = 0
+	}
+
+	// The session settings only apply to the connection they are set on, so all migrations run
+	// on a dedicated connection
+	var conn *sql.Conn
+	run := func(contents string) error {
+		if conn == nil {
+			opened, openErr := sqlhelpers.OpenSession(
+				context.Background(), db, online.Mysql, session,
+			)
+			if openErr != nil {
+				return openErr
+			}
+			conn = opened
+		}
+		return sqlhelpers.ExecScript(context.Background(), conn, contents)
+	}
+
+	closeRunner := func() error {
+		var connErr error
+		if conn != nil {
+			connErr = conn.Close()
+			conn = nil
+		}
+		return errors.Join(connErr, db.Close())
+	}
+	return ".sql", run, closeRunner, nil
+}
@@ -0,0 +1,101 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditResultSuccess and AuditResultFailure The possible results of an audited invocation
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditEntry The record of a command invocation (for example, a CLI command), stored for
+// compliance requirements (who ran which schema changes, where and when)
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	// Args The arguments and flags of the invocation, as provided
+	Args []string `json:"args"`
+	// User The operating system user which ran the command
+	User string `json:"user"`
+	Host string `json:"host"`
+	// Operator The operator from the run metadata (see RunMetadata), if provided
+	Operator   string `json:"operator,omitempty"`
+	Result     string `json:"result"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	// WorkDirs The work directories of the migrations executed by the command (see
+	// migration.WorkDirAware), so their intermediate files can be traced
+	WorkDirs []string `json:"workDirs,omitempty"`
+}
+
+// AuditLog Stores audit entries. Entries are only appended, never changed. The bundled
+// repositories (mysql, mongo) implement it, storing the entries next to the executions.
+type AuditLog interface {
+	AppendAudit(entry AuditEntry) error
+}
+
+// FileAuditLog AuditLog implementation which appends the entries, one JSON object per line, to a
+// local file
+type FileAuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileAuditLog Builds a new FileAuditLog which appends the entries to the file from path. The
+// file is created on the first append, if it does not exist.
+func NewFileAuditLog(path string) *FileAuditLog {
+	return &FileAuditLog{path: path}
+}
+
+func (log *FileAuditLog) AppendAudit(entry AuditEntry) (err error) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode the audit entry with error: %w", err)
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	file, err := os.OpenFile(log.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open the audit file with error: %w", err)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close the audit file with error: %w", closeErr)
+		}
+	}()
+
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write the audit entry with error: %w", err)
+	}
+
+	return nil
+}
+
+// InMemoryAuditLog Implementation of AuditLog. Can be used in unit tests. AppendErr can be used
+// to force AppendAudit to return an error.
+type InMemoryAuditLog struct {
+	mu        sync.Mutex
+	Entries   []AuditEntry
+	AppendErr error
+}
+
+func (log *InMemoryAuditLog) AppendAudit(entry AuditEntry) error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if log.AppendErr != nil {
+		return log.AppendErr
+	}
+
+	log.Entries = append(log.Entries, entry)
+	return nil
+}
@@ -0,0 +1,86 @@
+package execution
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditLogTestSuite struct {
+	suite.Suite
+}
+
+func TestAuditLogTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditLogTestSuite))
+}
+
+func (suite *AuditLogTestSuite) TestItAppendsJSONLinesToTheAuditFile() {
+	path := filepath.Join(suite.T().TempDir(), "audit.log")
+	log := NewFileAuditLog(path)
+
+	suite.Require().NoError(
+		log.AppendAudit(
+			AuditEntry{
+				Time:       time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+				Command:    "up",
+				Args:       []string{"up", "all"},
+				User:       "deployer",
+				Host:       "ci-1",
+				Result:     AuditResultSuccess,
+				DurationMs: 1500,
+			},
+		),
+	)
+	suite.Require().NoError(
+		log.AppendAudit(
+			AuditEntry{
+				Time:     time.Date(2024, 6, 1, 10, 5, 0, 0, time.UTC),
+				Command:  "down",
+				Args:     []string{"down"},
+				User:     "deployer",
+				Host:     "ci-1",
+				Operator: "jane",
+				Result:   AuditResultFailure,
+				Error:    "mig err",
+			},
+		),
+	)
+
+	content, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	suite.Require().Len(lines, 2)
+	suite.Assert().JSONEq(
+		`{"time":"2024-06-01T10:00:00Z","command":"up","args":["up","all"],"user":"deployer",`+
+			`"host":"ci-1","result":"success","durationMs":1500}`,
+		lines[0],
+	)
+	suite.Assert().JSONEq(
+		`{"time":"2024-06-01T10:05:00Z","command":"down","args":["down"],"user":"deployer",`+
+			`"host":"ci-1","operator":"jane","result":"failure","error":"mig err",`+
+			`"durationMs":0}`,
+		lines[1],
+	)
+}
+
+func (suite *AuditLogTestSuite) TestItFailsToAppendToAnUnwritableFile() {
+	log := NewFileAuditLog(filepath.Join(suite.T().TempDir(), "missing", "audit.log"))
+	suite.Assert().ErrorContains(
+		log.AppendAudit(AuditEntry{Command: "up"}), "failed to open the audit file",
+	)
+}
+
+func (suite *AuditLogTestSuite) TestItCanAppendToTheInMemoryAuditLog() {
+	log := &InMemoryAuditLog{}
+	suite.Assert().NoError(log.AppendAudit(AuditEntry{Command: "up"}))
+	suite.Assert().Equal([]AuditEntry{{Command: "up"}}, log.Entries)
+
+	log.AppendErr = errors.New("append err")
+	suite.Assert().ErrorIs(log.AppendAudit(AuditEntry{Command: "down"}), log.AppendErr)
+	suite.Assert().Len(log.Entries, 1)
+}
@@ -0,0 +1,467 @@
+package execution
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Codec Serializes the executions of repositories which store them as a single blob (see
+// FileRepository). JSONCodec is the default, ProtobufCodec and MsgpackCodec are more compact,
+// for very large histories.
+type Codec interface {
+	Encode(w io.Writer, executions []MigrationExecution) error
+	Decode(r io.Reader) ([]MigrationExecution, error)
+}
+
+var (
+	// JSONCodec Encodes the executions as an indented JSON array (see EncodeExecutions)
+	JSONCodec Codec = jsonCodec{}
+
+	// ProtobufCodec Encodes the executions in the protobuf wire format, matching the schema:
+	//
+	//	message Executions {
+	//	  repeated Execution executions = 1;
+	//	}
+	//
+	//	message Execution {
+	//	  uint64 version = 1;
+	//	  uint64 executed_at_ms = 2;
+	//	  uint64 finished_at_ms = 3;
+	//	  string deploy_id = 4;
+	//	  string git_sha = 5;
+	//	  string operator = 6;
+	//	  string skip_reason = 7;
+	//	  uint64 sequence = 8;
+	//	}
+	ProtobufCodec Codec = protobufCodec{}
+
+	// MsgpackCodec Encodes the executions as a MessagePack array of maps, keyed by the JSON
+	// field names of the unix milliseconds timestamps, run metadata, skip reason and sequence
+	// (for example, "executedAtMs", "deployId"). Empty fields are omitted.
+	MsgpackCodec Codec = msgpackCodec{}
+)
+
+// CodecByName Returns the codec with the provided name: json, protobuf or msgpack
+func CodecByName(name string) (Codec, error) {
+	switch name {
+	case "json":
+		return JSONCodec, nil
+	case "protobuf":
+		return ProtobufCodec, nil
+	case "msgpack":
+		return MsgpackCodec, nil
+	}
+	return nil, fmt.Errorf("unknown executions codec %q, use json, protobuf or msgpack", name)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(w io.Writer, executions []MigrationExecution) error {
+	return EncodeExecutions(w, executions)
+}
+
+func (jsonCodec) Decode(r io.Reader) ([]MigrationExecution, error) {
+	return DecodeExecutions(r)
+}
+
+const (
+	protoVarint = 0
+	protoI64    = 1
+	protoLen    = 2
+	protoI32    = 5
+)
+
+type protobufCodec struct{}
+
+func (protobufCodec) Encode(w io.Writer, executions []MigrationExecution) error {
+	var encoded []byte
+	for _, execution := range executions {
+		var message []byte
+		message = appendProtoUint(message, 1, execution.Version)
+		message = appendProtoUint(message, 2, execution.ExecutedAtMs)
+		message = appendProtoUint(message, 3, execution.FinishedAtMs)
+		message = appendProtoString(message, 4, execution.Run.DeployID)
+		message = appendProtoString(message, 5, execution.Run.GitSHA)
+		message = appendProtoString(message, 6, execution.Run.Operator)
+		message = appendProtoString(message, 7, execution.SkipReason)
+		message = appendProtoUint(message, 8, execution.Sequence)
+
+		encoded = binary.AppendUvarint(encoded, 1<<3|protoLen)
+		encoded = binary.AppendUvarint(encoded, uint64(len(message)))
+		encoded = append(encoded, message...)
+	}
+
+	_, err := w.Write(encoded)
+	return err
+}
+
+func (protobufCodec) Decode(r io.Reader) ([]MigrationExecution, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var executions []MigrationExecution
+	err = readProtoFields(data, func(field uint64, value uint64, bytes []byte) error {
+		if field != 1 || bytes == nil {
+			return nil
+		}
+
+		var execution MigrationExecution
+		err := readProtoFields(bytes, func(field uint64, value uint64, bytes []byte) error {
+			decodeProtoField(&execution, field, value, string(bytes))
+			return nil
+		})
+		executions = append(executions, execution)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf executions: %w", err)
+	}
+
+	return executions, nil
+}
+
+// decodeProtoField Sets the execution field with the protobuf field number. value is set for
+// varint fields, text for length delimited ones
+func decodeProtoField(execution *MigrationExecution, field uint64, value uint64, text string) {
+	switch field {
+	case 1:
+		execution.Version = value
+	case 2:
+		execution.ExecutedAtMs = value
+	case 3:
+		execution.FinishedAtMs = value
+	case 4:
+		execution.Run.DeployID = text
+	case 5:
+		execution.Run.GitSHA = text
+	case 6:
+		execution.Run.Operator = text
+	case 7:
+		execution.SkipReason = text
+	case 8:
+		execution.Sequence = value
+	}
+}
+
+func appendProtoUint(data []byte, field uint64, value uint64) []byte {
+	if value == 0 {
+		return data
+	}
+	data = binary.AppendUvarint(data, field<<3|protoVarint)
+	return binary.AppendUvarint(data, value)
+}
+
+func appendProtoString(data []byte, field uint64, value string) []byte {
+	if value == "" {
+		return data
+	}
+	data = binary.AppendUvarint(data, field<<3|protoLen)
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+// readProtoFields Calls read for each field of the protobuf message, with the value of varint
+// fields or the bytes of length delimited fields (non nil). Fixed size fields are skipped.
+func readProtoFields(
+	data []byte,
+	read func(field uint64, value uint64, bytes []byte) error,
+) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("truncated field tag")
+		}
+		data = data[n:]
+
+		var value uint64
+		var bytes []byte
+
+		switch tag & 7 {
+		case protoVarint:
+			value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errors.New("truncated varint")
+			}
+			data = data[n:]
+		case protoLen:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errors.New("truncated length delimited field")
+			}
+			bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case protoI64, protoI32:
+			size := 8
+			if tag&7 == protoI32 {
+				size = 4
+			}
+			if len(data) < size {
+				return errors.New("truncated fixed size field")
+			}
+			data = data[size:]
+			continue
+		default:
+			return fmt.Errorf("unsupported wire type %d", tag&7)
+		}
+
+		if err := read(tag>>3, value, bytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Encode(w io.Writer, executions []MigrationExecution) error {
+	encoded := appendMsgpackHeader(nil, len(executions), 0x90, 0xdc)
+	for _, execution := range executions {
+		var fields []byte
+		count := 0
+		appendUint := func(key string, value uint64, always bool) {
+			if value != 0 || always {
+				fields = appendMsgpackUint(appendMsgpackString(fields, key), value)
+				count++
+			}
+		}
+		appendString := func(key string, value string) {
+			if value != "" {
+				fields = appendMsgpackString(appendMsgpackString(fields, key), value)
+				count++
+			}
+		}
+
+		appendUint("version", execution.Version, true)
+		appendUint("executedAtMs", execution.ExecutedAtMs, true)
+		appendUint("finishedAtMs", execution.FinishedAtMs, true)
+		appendString("deployId", execution.Run.DeployID)
+		appendString("gitSha", execution.Run.GitSHA)
+		appendString("operator", execution.Run.Operator)
+		appendString("skipReason", execution.SkipReason)
+		appendUint("sequence", execution.Sequence, false)
+
+		encoded = append(appendMsgpackHeader(encoded, count, 0x80, 0xde), fields...)
+	}
+
+	_, err := w.Write(encoded)
+	return err
+}
+
+func (msgpackCodec) Decode(r io.Reader) ([]MigrationExecution, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &msgpackReader{data: data}
+	executions, err := reader.readExecutions()
+	if err == nil && len(reader.data) > 0 {
+		err = errors.New("unexpected data after the executions")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgpack executions: %w", err)
+	}
+
+	return executions, nil
+}
+
+// appendMsgpackHeader Appends an array or map header: the fix format (fixPrefix) for up to 15
+// elements, otherwise the 16 bit (prefix16) or 32 bit (prefix16 + 1) format
+func appendMsgpackHeader(data []byte, count int, fixPrefix byte, prefix16 byte) []byte {
+	switch {
+	case count < 16:
+		return append(data, fixPrefix|byte(count))
+	case count <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(data, prefix16), uint16(count))
+	default:
+		return binary.BigEndian.AppendUint32(append(data, prefix16+1), uint32(count))
+	}
+}
+
+func appendMsgpackString(data []byte, value string) []byte {
+	switch length := len(value); {
+	case length < 32:
+		data = append(data, 0xa0|byte(length))
+	case length <= math.MaxUint8:
+		data = append(data, 0xd9, byte(length))
+	case length <= math.MaxUint16:
+		data = binary.BigEndian.AppendUint16(append(data, 0xda), uint16(length))
+	default:
+		data = binary.BigEndian.AppendUint32(append(data, 0xdb), uint32(length))
+	}
+	return append(data, value...)
+}
+
+func appendMsgpackUint(data []byte, value uint64) []byte {
+	switch {
+	case value < 128:
+		return append(data, byte(value))
+	case value <= math.MaxUint8:
+		return append(data, 0xcc, byte(value))
+	case value <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(data, 0xcd), uint16(value))
+	case value <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(data, 0xce), uint32(value))
+	default:
+		return binary.BigEndian.AppendUint64(append(data, 0xcf), value)
+	}
+}
+
+// msgpackReader Reads the MessagePack values needed by the executions encoding
+type msgpackReader struct {
+	data []byte
+}
+
+func (r *msgpackReader) readExecutions() ([]MigrationExecution, error) {
+	count, err := r.readHeader(0x90, 0xdc)
+	if err != nil {
+		return nil, err
+	}
+
+	executions := make([]MigrationExecution, 0, min(count, len(r.data)))
+	for range count {
+		fieldsCount, err := r.readHeader(0x80, 0xde)
+		if err != nil {
+			return nil, err
+		}
+
+		var execution MigrationExecution
+		for range fieldsCount {
+			if err = r.readField(&execution); err != nil {
+				return nil, err
+			}
+		}
+		executions = append(executions, execution)
+	}
+
+	return executions, nil
+}
+
+// readField Reads a key and its value into the matching execution field
+func (r *msgpackReader) readField(execution *MigrationExecution) error {
+	key, err := r.readString()
+	if err != nil {
+		return err
+	}
+
+	switch key {
+	case "version":
+		execution.Version, err = r.readUint()
+	case "executedAtMs":
+		execution.ExecutedAtMs, err = r.readUint()
+	case "finishedAtMs":
+		execution.FinishedAtMs, err = r.readUint()
+	case "deployId":
+		execution.Run.DeployID, err = r.readString()
+	case "gitSha":
+		execution.Run.GitSHA, err = r.readString()
+	case "operator":
+		execution.Run.Operator, err = r.readString()
+	case "skipReason":
+		execution.SkipReason, err = r.readString()
+	case "sequence":
+		execution.Sequence, err = r.readUint()
+	default:
+		err = fmt.Errorf("unknown field %q", key)
+	}
+
+	return err
+}
+
+func (r *msgpackReader) take(size int) ([]byte, error) {
+	if len(r.data) < size {
+		return nil, errors.New("truncated value")
+	}
+	taken := r.data[:size]
+	r.data = r.data[size:]
+	return taken, nil
+}
+
+// readLength Reads a big endian length of 1, 2 or 4 bytes
+func (r *msgpackReader) readLength(size int) (int, error) {
+	bytes, err := r.take(size)
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return int(bytes[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(bytes)), nil
+	default:
+		return int(binary.BigEndian.Uint32(bytes)), nil
+	}
+}
+
+// readHeader Reads an array or map header (see appendMsgpackHeader)
+func (r *msgpackReader) readHeader(fixPrefix byte, prefix16 byte) (int, error) {
+	prefix, err := r.take(1)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case prefix[0]&0xf0 == fixPrefix:
+		return int(prefix[0] & 0x0f), nil
+	case prefix[0] == prefix16:
+		return r.readLength(2)
+	case prefix[0] == prefix16+1:
+		return r.readLength(4)
+	}
+	return 0, fmt.Errorf("unexpected type 0x%x, expected an array or map", prefix[0])
+}
+
+func (r *msgpackReader) readString() (string, error) {
+	prefix, err := r.take(1)
+	if err != nil {
+		return "", err
+	}
+
+	var length int
+	switch {
+	case prefix[0]&0xe0 == 0xa0:
+		length = int(prefix[0] & 0x1f)
+	case prefix[0] >= 0xd9 && prefix[0] <= 0xdb:
+		length, err = r.readLength(1 << (prefix[0] - 0xd9))
+	default:
+		err = fmt.Errorf("unexpected type 0x%x, expected a string", prefix[0])
+	}
+	if err != nil {
+		return "", err
+	}
+
+	value, err := r.take(length)
+	return string(value), err
+}
+
+func (r *msgpackReader) readUint() (uint64, error) {
+	prefix, err := r.take(1)
+	if err != nil {
+		return 0, err
+	}
+
+	if prefix[0] < 0x80 {
+		return uint64(prefix[0]), nil
+	}
+	if prefix[0] < 0xcc || prefix[0] > 0xcf {
+		return 0, fmt.Errorf("unexpected type 0x%x, expected an unsigned integer", prefix[0])
+	}
+
+	bytes, err := r.take(1 << (prefix[0] - 0xcc))
+	if err != nil {
+		return 0, err
+	}
+
+	var value uint64
+	for _, b := range bytes {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
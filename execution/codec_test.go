@@ -0,0 +1,95 @@
+package execution
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CodecTestSuite struct {
+	suite.Suite
+}
+
+func TestCodecTestSuite(t *testing.T) {
+	suite.Run(t, new(CodecTestSuite))
+}
+
+func (suite *CodecTestSuite) TestItCanEncodeAndDecodeExecutions() {
+	executions := []MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1712953083000, FinishedAtMs: 1712953084000},
+		{
+			Version: 1712953083, ExecutedAtMs: 5, FinishedAtMs: 5, SkipReason: "superseded by 7",
+			Run:      RunMetadata{DeployID: "deploy-12", GitSHA: "a1b2c3", Operator: "jane"},
+			Sequence: 300,
+		},
+		{Version: 3, ExecutedAtMs: 200, Run: RunMetadata{Operator: strings.Repeat("o", 300)}},
+	}
+
+	for _, name := range []string{"json", "protobuf", "msgpack"} {
+		codec, err := CodecByName(name)
+		suite.Require().NoError(err, name)
+
+		var encoded bytes.Buffer
+		suite.Require().NoError(codec.Encode(&encoded, executions), name)
+		decoded, err := codec.Decode(&encoded)
+
+		suite.Assert().NoError(err, name)
+		suite.Assert().Equal(executions, decoded, name)
+	}
+}
+
+func (suite *CodecTestSuite) TestItEncodesExecutionsInTheStandardWireFormats() {
+	executions := []MigrationExecution{{Version: 1, ExecutedAtMs: 300}}
+
+	var protobuf bytes.Buffer
+	suite.Require().NoError(ProtobufCodec.Encode(&protobuf, executions))
+	suite.Assert().Equal([]byte{0x0a, 0x05, 0x08, 0x01, 0x10, 0xac, 0x02}, protobuf.Bytes())
+
+	var msgpack bytes.Buffer
+	suite.Require().NoError(MsgpackCodec.Encode(&msgpack, executions))
+	expected := []byte{0x91, 0x83, 0xa7}
+	expected = append(append(expected, "version"...), 0x01, 0xac)
+	expected = append(append(expected, "executedAtMs"...), 0xcd, 0x01, 0x2c, 0xac)
+	expected = append(append(expected, "finishedAtMs"...), 0x00)
+	suite.Assert().Equal(expected, msgpack.Bytes())
+}
+
+func (suite *CodecTestSuite) TestItSkipsUnknownProtobufFields() {
+	// version = 2, an unknown fixed64 field 9 and an unknown string field 10
+	encoded := []byte{0x0a, 0x0f, 0x08, 0x02, 0x49, 1, 2, 3, 4, 5, 6, 7, 8, 0x52, 0x02, 'o', 'k'}
+
+	decoded, err := ProtobufCodec.Decode(bytes.NewReader(encoded))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{{Version: 2}}, decoded)
+}
+
+func (suite *CodecTestSuite) TestItFailsToDecodeInvalidData() {
+	scenarios := map[string]struct {
+		codec   Codec
+		encoded []byte
+	}{
+		"truncated protobuf":    {ProtobufCodec, []byte{0x0a, 0x05, 0x08}},
+		"invalid protobuf type": {ProtobufCodec, []byte{0x0b}},
+		"truncated msgpack":     {MsgpackCodec, []byte{0x91, 0x81, 0xa7, 'v'}},
+		"msgpack not an array":  {MsgpackCodec, []byte{0x81}},
+		"msgpack unknown field": {MsgpackCodec, []byte{0x91, 0x81, 0xa1, 'x', 0x01}},
+		"msgpack wrong type":    {MsgpackCodec, []byte("\x91\x81\xa7version\xa0")},
+		"msgpack trailing data": {MsgpackCodec, []byte{0x90, 0x01}},
+		"json invalid":          {JSONCodec, []byte("{")},
+	}
+
+	for name, scenario := range scenarios {
+		_, err := scenario.codec.Decode(bytes.NewReader(scenario.encoded))
+		suite.Assert().Error(err, name)
+	}
+}
+
+func (suite *CodecTestSuite) TestItFailsForUnknownCodecs() {
+	codec, err := CodecByName("xml")
+
+	suite.Assert().Nil(codec)
+	suite.Assert().ErrorContains(err, "unknown executions codec \"xml\"")
+}
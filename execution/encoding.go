@@ -0,0 +1,101 @@
+package execution
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// TimestampFormat The layout of the human-readable timestamps used in the JSON encoding
+// (RFC 3339, UTC, millisecond precision)
+const TimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// FormatTimestampMs Formats a unix milliseconds timestamp using TimestampFormat. Returns an empty
+// string for 0 (not set).
+func FormatTimestampMs(ms uint64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(int64(ms)).UTC().Format(TimestampFormat)
+}
+
+type jsonExecution struct {
+	Version      uint64       `json:"version"`
+	ExecutedAt   string       `json:"executedAt"`
+	FinishedAt   *string      `json:"finishedAt"`
+	Finished     bool         `json:"finished"`
+	ExecutedAtMs uint64       `json:"executedAtMs"`
+	FinishedAtMs uint64       `json:"finishedAtMs"`
+	Run          *RunMetadata `json:"run,omitempty"`
+	SkipReason   string       `json:"skipReason,omitempty"`
+	Sequence     uint64       `json:"sequence,omitempty"`
+}
+
+// MarshalJSON Encodes the execution with both human-readable and unix milliseconds timestamps.
+// finishedAt is null for unfinished executions, run is omitted if no run metadata is set and
+// skipReason is omitted for executions which were not skipped and sequence is omitted if the
+// repository did not assign one.
+func (execution MigrationExecution) MarshalJSON() ([]byte, error) {
+	encoded := jsonExecution{
+		Version:      execution.Version,
+		ExecutedAt:   FormatTimestampMs(execution.ExecutedAtMs),
+		Finished:     execution.Finished(),
+		ExecutedAtMs: execution.ExecutedAtMs,
+		FinishedAtMs: execution.FinishedAtMs,
+		SkipReason:   execution.SkipReason,
+		Sequence:     execution.Sequence,
+	}
+
+	if execution.Finished() {
+		finishedAt := FormatTimestampMs(execution.FinishedAtMs)
+		encoded.FinishedAt = &finishedAt
+	}
+
+	if !execution.Run.IsZero() {
+		encoded.Run = &execution.Run
+	}
+
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON Decodes an execution encoded by MarshalJSON. The unix milliseconds timestamps
+// are the source of truth, the human-readable ones are ignored.
+func (execution *MigrationExecution) UnmarshalJSON(data []byte) error {
+	var decoded jsonExecution
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	execution.Version = decoded.Version
+	execution.ExecutedAtMs = decoded.ExecutedAtMs
+	execution.FinishedAtMs = decoded.FinishedAtMs
+	execution.Run = RunMetadata{}
+	execution.SkipReason = decoded.SkipReason
+	execution.Sequence = decoded.Sequence
+
+	if decoded.Run != nil {
+		execution.Run = *decoded.Run
+	}
+	return nil
+}
+
+// EncodeExecutions Writes the executions, as a JSON array, to w. Can be used to export the
+// executions state of an environment.
+func EncodeExecutions(w io.Writer, executions []MigrationExecution) error {
+	if executions == nil {
+		executions = []MigrationExecution{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(executions)
+}
+
+// DecodeExecutions Reads executions encoded by EncodeExecutions
+func DecodeExecutions(r io.Reader) ([]MigrationExecution, error) {
+	var executions []MigrationExecution
+	if err := json.NewDecoder(r).Decode(&executions); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
@@ -0,0 +1,89 @@
+package execution
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EncodingTestSuite struct {
+	suite.Suite
+}
+
+func TestEncodingTestSuite(t *testing.T) {
+	suite.Run(t, new(EncodingTestSuite))
+}
+
+func (suite *EncodingTestSuite) TestItCanEncodeAndDecodeExecutionsAsJson() {
+	scenarios := map[string]struct {
+		execution    MigrationExecution
+		expectedJson string
+	}{
+		"finished": {
+			MigrationExecution{
+				Version: 1, ExecutedAtMs: 1712953083000, FinishedAtMs: 1712953083456,
+			},
+			`{"version":1,"executedAt":"2024-04-12T20:18:03.000Z",` +
+				`"finishedAt":"2024-04-12T20:18:03.456Z","finished":true,` +
+				`"executedAtMs":1712953083000,"finishedAtMs":1712953083456}`,
+		},
+		"unfinished": {
+			MigrationExecution{Version: 2, ExecutedAtMs: 1712953083000},
+			`{"version":2,"executedAt":"2024-04-12T20:18:03.000Z","finishedAt":null,` +
+				`"finished":false,"executedAtMs":1712953083000,"finishedAtMs":0}`,
+		},
+		"with run metadata": {
+			MigrationExecution{
+				Version: 3, ExecutedAtMs: 1712953083000,
+				Run: RunMetadata{DeployID: "deploy-12", GitSHA: "a1b2c3"},
+			},
+			`{"version":3,"executedAt":"2024-04-12T20:18:03.000Z","finishedAt":null,` +
+				`"finished":false,"executedAtMs":1712953083000,"finishedAtMs":0,` +
+				`"run":{"deployId":"deploy-12","gitSha":"a1b2c3"}}`,
+		},
+		"with sequence": {
+			MigrationExecution{Version: 4, ExecutedAtMs: 1712953083000, Sequence: 7},
+			`{"version":4,"executedAt":"2024-04-12T20:18:03.000Z","finishedAt":null,` +
+				`"finished":false,"executedAtMs":1712953083000,"finishedAtMs":0,"sequence":7}`,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		encoded, err := json.Marshal(scenario.execution)
+		suite.Assert().NoError(err, "failed scenario %s", name)
+		suite.Assert().JSONEq(scenario.expectedJson, string(encoded), "failed scenario %s", name)
+
+		var decoded MigrationExecution
+		err = json.Unmarshal(encoded, &decoded)
+		suite.Assert().NoError(err, "failed scenario %s", name)
+		suite.Assert().Equal(scenario.execution, decoded, "failed scenario %s", name)
+	}
+}
+
+func (suite *EncodingTestSuite) TestItFormatsTimestamps() {
+	suite.Assert().Equal("", FormatTimestampMs(0))
+	suite.Assert().Equal("1970-01-01T00:00:01.500Z", FormatTimestampMs(1500))
+}
+
+func (suite *EncodingTestSuite) TestItCanEncodeAndDecodeExecutionsState() {
+	executions := []MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1000, FinishedAtMs: 2000},
+		{Version: 2, ExecutedAtMs: 3000},
+	}
+	var buffer bytes.Buffer
+
+	suite.Assert().NoError(EncodeExecutions(&buffer, executions))
+	decoded, err := DecodeExecutions(&buffer)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(executions, decoded)
+
+	buffer.Reset()
+	suite.Assert().NoError(EncodeExecutions(&buffer, nil))
+	suite.Assert().Equal("[]\n", buffer.String())
+
+	_, err = DecodeExecutions(bytes.NewBufferString("{"))
+	suite.Assert().Error(err)
+}
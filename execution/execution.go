@@ -3,6 +3,9 @@
 package execution
 
 import (
+	"cmp"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/rsgcata/go-migrations/migration"
@@ -15,17 +18,51 @@ type MigrationExecution struct {
 	Version      uint64
 	ExecutedAtMs uint64
 	FinishedAtMs uint64
+	Run          RunMetadata
+
+	// SkipReason Set for executions recorded without running the migration, because it was
+	// in the skip list (see migration.ReadSkipList)
+	SkipReason string
+
+	// Sequence A monotonic number assigned by the repository when the execution is first
+	// saved, for repositories which support logical clocks (0 otherwise). Unlike the
+	// timestamps, it orders the executions history reliably even when the clocks of the hosts
+	// running migrations are skewed (see SortHistory). Repositories ignore it on Save.
+	Sequence uint64
+}
+
+// RunMetadata Information about the run which created an execution (for example, the
+// deployment), tying schema changes to specific deployments. All fields are optional.
+type RunMetadata struct {
+	DeployID string `bson:"deployId,omitempty" json:"deployId,omitempty"`
+	GitSHA   string `bson:"gitSha,omitempty" json:"gitSha,omitempty"`
+	Operator string `bson:"operator,omitempty" json:"operator,omitempty"`
+}
+
+// IsZero Checks if no run metadata is set
+func (metadata RunMetadata) IsZero() bool {
+	return metadata == RunMetadata{}
 }
 
 // StartExecution Creates a new MigrationExecution and marks it as unfinished.
 func StartExecution(migration migration.Migration) *MigrationExecution {
-	return &MigrationExecution{migration.Version(), uint64(time.Now().UnixMilli()), 0}
+	return StartExecutionAt(migration, time.Now())
+}
+
+// StartExecutionAt Same as StartExecution, but uses the provided time as execution time
+func StartExecutionAt(migration migration.Migration, now time.Time) *MigrationExecution {
+	return &MigrationExecution{Version: migration.Version(), ExecutedAtMs: uint64(now.UnixMilli())}
 }
 
 // FinishExecution Marks the MigrationExecution as finished
 func (execution *MigrationExecution) FinishExecution() {
+	execution.FinishExecutionAt(time.Now())
+}
+
+// FinishExecutionAt Same as FinishExecution, but uses the provided time as finish time
+func (execution *MigrationExecution) FinishExecutionAt(now time.Time) {
 	if !execution.Finished() {
-		execution.FinishedAtMs = uint64(time.Now().UnixMilli())
+		execution.FinishedAtMs = uint64(now.UnixMilli())
 	}
 }
 
@@ -34,6 +71,35 @@ func (execution *MigrationExecution) Finished() bool {
 	return execution.FinishedAtMs > 0
 }
 
+// Duration Returns how long the migration ran, 0 if the execution is not finished
+func (execution *MigrationExecution) Duration() time.Duration {
+	if !execution.Finished() || execution.FinishedAtMs < execution.ExecutedAtMs {
+		return 0
+	}
+	return time.Duration(execution.FinishedAtMs-execution.ExecutedAtMs) * time.Millisecond
+}
+
+// SortHistory Orders the executions in the order they were executed: by Sequence, if assigned
+// by the repository, otherwise by ExecutedAtMs, then by version. Executions without a sequence
+// (saved before the repository assigned sequences) are ordered before the ones with a sequence.
+func SortHistory(executions []MigrationExecution) {
+	slices.SortStableFunc(executions, func(a MigrationExecution, b MigrationExecution) int {
+		if a.Sequence != 0 || b.Sequence != 0 {
+			return cmp.Compare(a.Sequence, b.Sequence)
+		}
+
+		if a.ExecutedAtMs != b.ExecutedAtMs {
+			return cmp.Compare(a.ExecutedAtMs, b.ExecutedAtMs)
+		}
+		return cmp.Compare(a.Version, b.Version)
+	})
+}
+
+// Skipped Checks if the execution was recorded without running the migration
+func (execution *MigrationExecution) Skipped() bool {
+	return execution.SkipReason != ""
+}
+
 // Repository Must be implemented by any storage mechanism and must handle everything related
 // to migration executions persistence
 type Repository interface {
@@ -54,6 +120,75 @@ type Repository interface {
 	FindOne(version uint64) (*MigrationExecution, error)
 }
 
+// ErrExecutionConflict is returned by ConditionalSaver implementations when the persisted
+// execution is not in the expected state (it was changed by another process)
+var ErrExecutionConflict = errors.New("execution was changed by another process")
+
+// ErrLockHeld is returned (wrapped) by repositories and lock implementations when the
+// migrations lock is held by another process
+var ErrLockHeld = errors.New("migrations lock is held by another process")
+
+// ErrQueryTimeout is returned (wrapped) by repositories when a query did not finish within the
+// configured query timeout
+var ErrQueryTimeout = errors.New("repository query timed out")
+
+// ConditionalSaver Optional Repository capability which allows compare-and-set saves, so
+// processes racing to execute the same migration without locks can not both record it
+type ConditionalSaver interface {
+	// SaveIf Must persist the execution only if the currently persisted execution with the
+	// same version is equal to expected (nil meaning no execution is persisted for that
+	// version). Must return ErrExecutionConflict if that's not the case.
+	SaveIf(execution MigrationExecution, expected *MigrationExecution) error
+}
+
+// SummaryReader Optional Repository capability which allows reading the executions summary
+// without loading all executions. Useful for databases with very long executions history.
+type SummaryReader interface {
+	// LatestExecution Must return the execution with the greatest version, or nil if there
+	// are no executions
+	LatestExecution() (*MigrationExecution, error)
+
+	// CountFinished Must return the number of finished executions
+	CountFinished() (int, error)
+}
+
+// AccessChecker Optional Repository capability which allows checking, without changing any
+// executions, that the repository storage can be read and written (for example, that the
+// executions table exists and the database user has the needed privileges)
+type AccessChecker interface {
+	CheckRead() error
+	CheckWrite() error
+}
+
+// AsyncChangesWaiter Optional Repository capability for databases which apply schema changes
+// asynchronously (for example, TiDB DDL jobs). The handler waits for the changes made by a
+// migration's Up() or Down() to be applied before recording the execution state.
+type AsyncChangesWaiter interface {
+	// WaitForChanges Must block until no schema change is pending, or fail if the changes are
+	// not applied in a reasonable time
+	WaitForChanges() error
+}
+
+// ServerClock Optional Repository capability for databases which can report the current time
+// of the database server. Used by the handler to source the execution timestamps from the
+// server clock (see handler.WithServerClock), instead of the clock of the host running the
+// migrations, which may be skewed.
+type ServerClock interface {
+	// ServerTime Must return the current time of the database server
+	ServerTime() (time.Time, error)
+}
+
+// ReplicaReader Optional Repository capability for databases with read replicas. Only read-only
+// handlers (see handler.WithReadOnly) read the executions through the replica repository, runs
+// always read them from the primary, so plans built while holding the run lock are never stale.
+type ReplicaReader interface {
+	// ReplicaRepository Must return a repository which serves the executions reads from a read
+	// replica (when fresh enough). Read-only handlers use its read capabilities (for example,
+	// SummaryReader, LockInspector and NoteStore) instead of the primary repository ones, so it
+	// should implement the same capabilities.
+	ReplicaRepository() Repository
+}
+
 // InMemoryRepository Implementation of Repository. Can be used in unit tests.
 // All {method}Err properties can be used to force the specific method to return an error
 type InMemoryRepository struct {
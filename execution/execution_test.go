@@ -28,6 +28,7 @@ func (suite *ExecutionTestSuite) TestItCanStartExecution() {
 	)
 	suite.Assert().Equal(uint64(0), execution.FinishedAtMs)
 	suite.Assert().False(execution.Finished())
+	suite.Assert().False(execution.Skipped())
 }
 
 func (suite *ExecutionTestSuite) TestItCanFinishExecution() {
@@ -42,3 +43,34 @@ func (suite *ExecutionTestSuite) TestItCanFinishExecution() {
 	)
 	suite.Assert().True(execution.Finished())
 }
+
+func (suite *ExecutionTestSuite) TestItCanComputeTheExecutionDuration() {
+	execution := MigrationExecution{Version: 1, ExecutedAtMs: 1000}
+	suite.Assert().Equal(time.Duration(0), execution.Duration())
+
+	execution.FinishedAtMs = 3500
+	suite.Assert().Equal(2500*time.Millisecond, execution.Duration())
+
+	execution.FinishedAtMs = 500
+	suite.Assert().Equal(time.Duration(0), execution.Duration())
+}
+
+func (suite *ExecutionTestSuite) TestItSortsTheExecutionsHistory() {
+	executions := []MigrationExecution{
+		// Executed on a host with the clock behind, but after version 3
+		{Version: 4, ExecutedAtMs: 100, Sequence: 3},
+		{Version: 3, ExecutedAtMs: 500, Sequence: 2},
+		{Version: 2, ExecutedAtMs: 300, Sequence: 1},
+		// Saved before the repository assigned sequences
+		{Version: 5, ExecutedAtMs: 50},
+		{Version: 1, ExecutedAtMs: 50},
+	}
+
+	SortHistory(executions)
+
+	var versions []uint64
+	for _, execution := range executions {
+		versions = append(versions, execution.Version)
+	}
+	suite.Assert().Equal([]uint64{1, 5, 2, 3, 4}, versions)
+}
@@ -0,0 +1,210 @@
+package execution
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// pendingChange A change which could not be written in the primary repository of a
+// FailoverRepository, to be replayed when the primary returns
+type pendingChange struct {
+	execution MigrationExecution
+	removed   bool
+}
+
+// FailoverRepository Repository which keeps the executions in a primary repository (for example,
+// the database) and mirrors them in a fallback repository (for example, a local
+// FileRepository). When the primary fails, reads are served by the fallback and writes are
+// recorded in the fallback and kept as pending changes, with a warning. The pending changes are
+// replayed in the primary (reconciled), in order, before the next operation which reaches it.
+// Pending changes are kept in memory, so changes which were not reconciled before the process
+// exits must be reconciled manually (see PendingChanges).
+type FailoverRepository struct {
+	primary  Repository
+	fallback Repository
+	warn     func(warning error)
+
+	mu      sync.Mutex
+	pending []pendingChange
+}
+
+// NewFailoverRepository Builds a new FailoverRepository. The warn function is called each time
+// the fallback is used instead of the primary. If nil, the warnings are printed to stderr.
+func NewFailoverRepository(
+	primary Repository,
+	fallback Repository,
+	warn func(warning error),
+) *FailoverRepository {
+	if warn == nil {
+		warn = func(warning error) {
+			_, _ = fmt.Fprintln(os.Stderr, "Warning: "+warning.Error())
+		}
+	}
+
+	return &FailoverRepository{primary: primary, fallback: fallback, warn: warn}
+}
+
+func (repo *FailoverRepository) Init() error {
+	if err := repo.fallback.Init(); err != nil {
+		return fmt.Errorf("failed to initialize the fallback repository with error: %w", err)
+	}
+
+	if err := repo.primary.Init(); err != nil {
+		repo.warn(fmt.Errorf("primary repository init failed, using the fallback: %w", err))
+	}
+
+	return nil
+}
+
+func (repo *FailoverRepository) LoadExecutions() ([]MigrationExecution, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := repo.reconcile(); err != nil {
+		repo.warn(fmt.Errorf("primary repository unavailable, reading the fallback: %w", err))
+		return repo.fallback.LoadExecutions()
+	}
+
+	executions, err := repo.primary.LoadExecutions()
+	if err != nil {
+		repo.warn(fmt.Errorf("primary repository read failed, reading the fallback: %w", err))
+		return repo.fallback.LoadExecutions()
+	}
+
+	if err = repo.refreshFallback(executions); err != nil {
+		repo.warn(fmt.Errorf("failed to refresh the fallback repository: %w", err))
+	}
+
+	return executions, nil
+}
+
+// refreshFallback Makes the fallback mirror the primary executions, including the changes made
+// by other processes, so it is up-to-date when the primary fails
+func (repo *FailoverRepository) refreshFallback(executions []MigrationExecution) error {
+	cached, err := repo.fallback.LoadExecutions()
+	if err != nil {
+		return err
+	}
+
+	current := make(map[uint64]MigrationExecution, len(executions))
+	for _, execution := range executions {
+		current[execution.Version] = execution
+	}
+
+	for _, execution := range cached {
+		if primaryExecution, found := current[execution.Version]; !found {
+			err = repo.fallback.Remove(execution)
+		} else if primaryExecution == execution {
+			delete(current, execution.Version)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, execution := range executions {
+		if _, changed := current[execution.Version]; changed {
+			if err = repo.fallback.Save(execution); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (repo *FailoverRepository) FindOne(version uint64) (*MigrationExecution, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := repo.reconcile(); err != nil {
+		repo.warn(fmt.Errorf("primary repository unavailable, reading the fallback: %w", err))
+		return repo.fallback.FindOne(version)
+	}
+
+	execution, err := repo.primary.FindOne(version)
+	if err != nil {
+		repo.warn(fmt.Errorf("primary repository read failed, reading the fallback: %w", err))
+		return repo.fallback.FindOne(version)
+	}
+
+	return execution, nil
+}
+
+func (repo *FailoverRepository) Save(execution MigrationExecution) error {
+	return repo.write(pendingChange{execution: execution})
+}
+
+func (repo *FailoverRepository) Remove(execution MigrationExecution) error {
+	return repo.write(pendingChange{execution: execution, removed: true})
+}
+
+// PendingChanges The executions saved (or removed, if the bool is true) in the fallback, which
+// were not reconciled in the primary yet
+func (repo *FailoverRepository) PendingChanges() map[uint64]bool {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	changes := make(map[uint64]bool, len(repo.pending))
+	for _, change := range repo.pending {
+		changes[change.execution.Version] = change.removed
+	}
+	return changes
+}
+
+// Reconcile Replays the pending changes in the primary. It is also done before each operation,
+// so it needs to be called only to reconcile the changes before the process exits.
+func (repo *FailoverRepository) Reconcile() error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return repo.reconcile()
+}
+
+// write Applies the change in the fallback, then in the primary. If the primary fails, the
+// change is kept as pending
+func (repo *FailoverRepository) write(change pendingChange) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := apply(repo.fallback, change); err != nil {
+		return fmt.Errorf("failed to write the fallback repository with error: %w", err)
+	}
+
+	err := repo.reconcile()
+	if err == nil {
+		err = apply(repo.primary, change)
+	}
+
+	if err != nil {
+		repo.pending = append(repo.pending, change)
+		repo.warn(
+			fmt.Errorf(
+				"primary repository write failed, execution %d kept in the fallback: %w",
+				change.execution.Version, err,
+			),
+		)
+	}
+
+	return nil
+}
+
+// reconcile Replays the pending changes in the primary, stopping at the first failure
+func (repo *FailoverRepository) reconcile() error {
+	for len(repo.pending) > 0 {
+		if err := apply(repo.primary, repo.pending[0]); err != nil {
+			return fmt.Errorf("%d changes are not reconciled, %w", len(repo.pending), err)
+		}
+		repo.pending = repo.pending[1:]
+	}
+	return nil
+}
+
+// apply Writes the change in the repository
+func apply(repository Repository, change pendingChange) error {
+	if change.removed {
+		return repository.Remove(change.execution)
+	}
+	return repository.Save(change.execution)
+}
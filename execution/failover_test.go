@@ -0,0 +1,147 @@
+package execution
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FailoverTestSuite struct {
+	suite.Suite
+}
+
+func TestFailoverTestSuite(t *testing.T) {
+	suite.Run(t, new(FailoverTestSuite))
+}
+
+// flakyRepository Fails all operations while down
+type flakyRepository struct {
+	Repository
+	down bool
+}
+
+var errRepositoryDown = errors.New("connection refused")
+
+func (repo *flakyRepository) Init() error {
+	if repo.down {
+		return errRepositoryDown
+	}
+	return repo.Repository.Init()
+}
+
+func (repo *flakyRepository) LoadExecutions() ([]MigrationExecution, error) {
+	if repo.down {
+		return nil, errRepositoryDown
+	}
+	return repo.Repository.LoadExecutions()
+}
+
+func (repo *flakyRepository) Save(execution MigrationExecution) error {
+	if repo.down {
+		return errRepositoryDown
+	}
+	return repo.Repository.Save(execution)
+}
+
+func (repo *flakyRepository) Remove(execution MigrationExecution) error {
+	if repo.down {
+		return errRepositoryDown
+	}
+	return repo.Repository.Remove(execution)
+}
+
+func (repo *flakyRepository) FindOne(version uint64) (*MigrationExecution, error) {
+	if repo.down {
+		return nil, errRepositoryDown
+	}
+	return repo.Repository.FindOne(version)
+}
+
+func (suite *FailoverTestSuite) newRepositories() (
+	*flakyRepository, *FileRepository, *FailoverRepository, *[]error,
+) {
+	dir := suite.T().TempDir()
+	primary := &flakyRepository{Repository: NewFileRepository(filepath.Join(dir, "primary"))}
+	fallback := NewFileRepository(filepath.Join(dir, "fallback"))
+	var warnings []error
+	repo := NewFailoverRepository(primary, fallback, func(warning error) {
+		warnings = append(warnings, warning)
+	})
+	return primary, fallback, repo, &warnings
+}
+
+func (suite *FailoverTestSuite) TestItMirrorsTheExecutionsInTheFallback() {
+	primary, fallback, repo, warnings := suite.newRepositories()
+	suite.Require().NoError(repo.Init())
+
+	exec1 := MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	exec4 := MigrationExecution{Version: 4, ExecutedAtMs: 5, FinishedAtMs: 6}
+	suite.Assert().NoError(repo.Save(exec1))
+	suite.Assert().NoError(repo.Save(exec4))
+	suite.Assert().NoError(repo.Remove(exec1))
+
+	// Changed by another process
+	exec7 := MigrationExecution{Version: 7, ExecutedAtMs: 8, FinishedAtMs: 9}
+	_ = primary.Save(exec7)
+
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{exec4, exec7}, executions)
+
+	cached, _ := fallback.LoadExecutions()
+	suite.Assert().Equal([]MigrationExecution{exec4, exec7}, cached)
+	suite.Assert().Empty(*warnings)
+}
+
+func (suite *FailoverTestSuite) TestItFailsOverAndReconcilesWhenThePrimaryReturns() {
+	primary, _, repo, warnings := suite.newRepositories()
+	suite.Require().NoError(repo.Init())
+
+	exec1 := MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	exec4 := MigrationExecution{Version: 4, ExecutedAtMs: 5, FinishedAtMs: 6}
+	suite.Assert().NoError(repo.Save(exec1))
+
+	primary.down = true
+	suite.Assert().NoError(repo.Save(exec4))
+	suite.Assert().NoError(repo.Remove(exec1))
+
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{exec4}, executions)
+	found, err := repo.FindOne(4)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&exec4, found)
+
+	suite.Assert().Equal(map[uint64]bool{4: false, 1: true}, repo.PendingChanges())
+	suite.Assert().Len(*warnings, 4)
+	for _, warning := range *warnings {
+		suite.Assert().ErrorIs(warning, errRepositoryDown)
+	}
+
+	primary.down = false
+	suite.Assert().NoError(repo.Reconcile())
+	suite.Assert().Empty(repo.PendingChanges())
+
+	executions, _ = primary.LoadExecutions()
+	suite.Assert().Equal([]MigrationExecution{exec4}, executions)
+}
+
+func (suite *FailoverTestSuite) TestItInitializesWhenOnlyTheFallbackIsAvailable() {
+	primary, _, repo, warnings := suite.newRepositories()
+	primary.down = true
+
+	suite.Assert().NoError(repo.Init())
+	suite.Assert().Len(*warnings, 1)
+	suite.Assert().ErrorContains((*warnings)[0], "primary repository init failed")
+
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(executions)
+
+	failing := NewFailoverRepository(
+		primary, &InMemoryRepository{InitErr: errors.New("read-only file system")}, nil,
+	)
+	suite.Assert().ErrorContains(failing.Init(), "failed to initialize the fallback repository")
+}
@@ -0,0 +1,103 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrInjectedFault is returned (wrapped) by the operations failed on purpose, in chaos tests
+// (see FaultyRepository)
+var ErrInjectedFault = errors.New("injected fault")
+
+// RepositoryOperation A Repository operation which can be failed by a FaultyRepository
+type RepositoryOperation string
+
+const (
+	OperationLoad   RepositoryOperation = "load"
+	OperationFind   RepositoryOperation = "find"
+	OperationSave   RepositoryOperation = "save"
+	OperationRemove RepositoryOperation = "remove"
+)
+
+// repositoryFault An armed fault of a FaultyRepository
+type repositoryFault struct {
+	operation RepositoryOperation
+	version   uint64
+}
+
+// FaultyRepository Repository wrapper meant for chaos tests, which fails the armed operations
+// with ErrInjectedFault, without forwarding them to the wrapped repository. It can be used to
+// test operational runbooks and repair tooling against realistic partial failures (for example,
+// a migration which ran, but whose execution was not saved).
+type FaultyRepository struct {
+	Repository
+
+	mu     sync.Mutex
+	faults []repositoryFault
+}
+
+// NewFaultyRepository Wraps the repository. No operation fails until faults are armed with
+// FailNext.
+func NewFaultyRepository(repository Repository) *FaultyRepository {
+	return &FaultyRepository{Repository: repository}
+}
+
+// FailNext Arms a fault which fails the next operation for the execution with the provided
+// version (0 matches any version; the version is ignored for OperationLoad). Each armed fault
+// fails a single operation.
+func (repo *FaultyRepository) FailNext(operation RepositoryOperation, version uint64) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.faults = append(repo.faults, repositoryFault{operation: operation, version: version})
+}
+
+func (repo *FaultyRepository) LoadExecutions() ([]MigrationExecution, error) {
+	if err := repo.fault(OperationLoad, 0); err != nil {
+		return nil, err
+	}
+	return repo.Repository.LoadExecutions()
+}
+
+func (repo *FaultyRepository) FindOne(version uint64) (*MigrationExecution, error) {
+	if err := repo.fault(OperationFind, version); err != nil {
+		return nil, err
+	}
+	return repo.Repository.FindOne(version)
+}
+
+func (repo *FaultyRepository) Save(execution MigrationExecution) error {
+	if err := repo.fault(OperationSave, execution.Version); err != nil {
+		return err
+	}
+	return repo.Repository.Save(execution)
+}
+
+func (repo *FaultyRepository) Remove(execution MigrationExecution) error {
+	if err := repo.fault(OperationRemove, execution.Version); err != nil {
+		return err
+	}
+	return repo.Repository.Remove(execution)
+}
+
+// fault Disarms and returns the first fault armed for the operation and version, if any
+func (repo *FaultyRepository) fault(operation RepositoryOperation, version uint64) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	index := slices.IndexFunc(repo.faults, func(fault repositoryFault) bool {
+		return fault.operation == operation &&
+			(fault.version == 0 || version == 0 || fault.version == version)
+	})
+
+	if index < 0 {
+		return nil
+	}
+
+	repo.faults = slices.Delete(repo.faults, index, index+1)
+	if operation == OperationLoad {
+		return fmt.Errorf("%w: %s failed", ErrInjectedFault, operation)
+	}
+	return fmt.Errorf("%w: %s failed for execution %d", ErrInjectedFault, operation, version)
+}
@@ -0,0 +1,52 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FaultsTestSuite struct {
+	suite.Suite
+}
+
+func TestFaultsTestSuite(t *testing.T) {
+	suite.Run(t, new(FaultsTestSuite))
+}
+
+func (suite *FaultsTestSuite) TestItFailsOnlyTheArmedOperations() {
+	exec := MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	inner := &InMemoryRepository{PersistedExecutions: []MigrationExecution{exec}}
+	repo := NewFaultyRepository(inner)
+
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{exec}, executions)
+
+	repo.FailNext(OperationLoad, 0)
+	repo.FailNext(OperationFind, 1)
+	repo.FailNext(OperationSave, 3)
+	repo.FailNext(OperationRemove, 0)
+
+	_, err = repo.LoadExecutions()
+	suite.Assert().ErrorIs(err, ErrInjectedFault)
+	_, err = repo.LoadExecutions()
+	suite.Assert().NoError(err)
+
+	_, err = repo.FindOne(1)
+	suite.Assert().ErrorIs(err, ErrInjectedFault)
+	found, err := repo.FindOne(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&exec, found)
+
+	suite.Assert().NoError(repo.Save(MigrationExecution{Version: 2}))
+	err = repo.Save(MigrationExecution{Version: 3})
+	suite.Assert().ErrorIs(err, ErrInjectedFault)
+	suite.Assert().ErrorContains(err, "save failed for execution 3")
+	suite.Assert().Len(inner.PersistedExecutions, 2)
+
+	suite.Assert().ErrorIs(repo.Remove(exec), ErrInjectedFault)
+	suite.Assert().Len(inner.PersistedExecutions, 2)
+	suite.Assert().NoError(repo.Remove(exec))
+	suite.Assert().Len(inner.PersistedExecutions, 1)
+}
@@ -0,0 +1,152 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// FileRepository Repository implementation which stores the executions in a local file, encoded
+// with a Codec (JSON by default, see EncodeExecutions). It's meant for single process use, for
+// example, as the local cache of a FailoverRepository.
+type FileRepository struct {
+	path  string
+	codec Codec
+	mu    sync.Mutex
+}
+
+// FileRepositoryOption Optional behaviour for the FileRepository
+type FileRepositoryOption func(repo *FileRepository)
+
+// WithCodec Sets the codec the executions are encoded with (see ProtobufCodec and
+// MsgpackCodec). Defaults to JSONCodec. Existing files must be converted when changing it.
+func WithCodec(codec Codec) FileRepositoryOption {
+	return func(repo *FileRepository) {
+		repo.codec = codec
+	}
+}
+
+// NewFileRepository Builds a new FileRepository which stores the executions in the file from
+// path. The file is created by Init, if it does not exist.
+func NewFileRepository(path string, options ...FileRepositoryOption) *FileRepository {
+	repo := &FileRepository{path: path, codec: JSONCodec}
+	for _, option := range options {
+		option(repo)
+	}
+	return repo
+}
+
+func (repo *FileRepository) Init() error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, err := os.Stat(repo.path); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check the executions file with error: %w", err)
+	}
+
+	return repo.write(nil)
+}
+
+func (repo *FileRepository) LoadExecutions() ([]MigrationExecution, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return repo.read()
+}
+
+func (repo *FileRepository) Save(execution MigrationExecution) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	executions, err := repo.read()
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(executions, func(e MigrationExecution) bool {
+		return e.Version == execution.Version
+	})
+
+	if index < 0 {
+		executions = append(executions, execution)
+	} else {
+		executions[index] = execution
+	}
+
+	return repo.write(executions)
+}
+
+func (repo *FileRepository) Remove(execution MigrationExecution) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	executions, err := repo.read()
+	if err != nil {
+		return err
+	}
+
+	return repo.write(
+		slices.DeleteFunc(executions, func(e MigrationExecution) bool {
+			return e.Version == execution.Version
+		}),
+	)
+}
+
+func (repo *FileRepository) FindOne(version uint64) (*MigrationExecution, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	executions, err := repo.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, execution := range executions {
+		if execution.Version == version {
+			return &execution, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// read Decodes the executions from the file
+func (repo *FileRepository) read() ([]MigrationExecution, error) {
+	file, err := os.Open(repo.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the executions file with error: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	executions, err := repo.codec.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the executions file with error: %w", err)
+	}
+
+	return executions, nil
+}
+
+// write Replaces the file with the encoded executions. The executions are written to a
+// temporary file first, which is then renamed, so an interrupted write does not corrupt the file
+func (repo *FileRepository) write(executions []MigrationExecution) error {
+	tmp, err := os.CreateTemp(filepath.Dir(repo.path), filepath.Base(repo.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write the executions file with error: %w", err)
+	}
+
+	err = errors.Join(repo.codec.Encode(tmp, executions), tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), repo.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write the executions file with error: %w", err)
+	}
+
+	return nil
+}
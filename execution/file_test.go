@@ -0,0 +1,86 @@
+package execution
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FileRepositoryTestSuite struct {
+	suite.Suite
+}
+
+func TestFileRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(FileRepositoryTestSuite))
+}
+
+func (suite *FileRepositoryTestSuite) TestItCanSaveFindAndRemoveExecutions() {
+	path := filepath.Join(suite.T().TempDir(), "executions.json")
+	repo := NewFileRepository(path)
+
+	suite.Require().NoError(repo.Init())
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(executions)
+
+	started := MigrationExecution{Version: 1, ExecutedAtMs: 2}
+	finished := MigrationExecution{
+		Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3, Run: RunMetadata{DeployID: "deploy-12"},
+	}
+	skipped := MigrationExecution{
+		Version: 4, ExecutedAtMs: 5, FinishedAtMs: 5, SkipReason: "superseded by 7",
+	}
+	suite.Assert().NoError(repo.Save(started))
+	suite.Assert().NoError(repo.Save(finished))
+	suite.Assert().NoError(repo.Save(skipped))
+
+	found, err := repo.FindOne(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&finished, found)
+
+	suite.Assert().NoError(repo.Remove(finished))
+	found, err = repo.FindOne(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(found)
+
+	// The executions are kept between repository instances
+	suite.Assert().NoError(NewFileRepository(path).Init())
+	executions, err = NewFileRepository(path).LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{skipped}, executions)
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	suite.Assert().Len(entries, 1)
+}
+
+func (suite *FileRepositoryTestSuite) TestItCanStoreExecutionsWithOtherCodecs() {
+	path := filepath.Join(suite.T().TempDir(), "executions.msgpack")
+	repo := NewFileRepository(path, WithCodec(MsgpackCodec))
+	finished := MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+
+	suite.Require().NoError(repo.Init())
+	suite.Assert().NoError(repo.Save(finished))
+
+	executions, err := NewFileRepository(path, WithCodec(MsgpackCodec)).LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{finished}, executions)
+
+	_, err = NewFileRepository(path).LoadExecutions()
+	suite.Assert().ErrorContains(err, "failed to read the executions file")
+}
+
+func (suite *FileRepositoryTestSuite) TestItFailsForInvalidFiles() {
+	path := filepath.Join(suite.T().TempDir(), "executions.json")
+	_ = os.WriteFile(path, []byte("{"), 0600)
+	repo := NewFileRepository(path)
+
+	suite.Assert().NoError(repo.Init())
+	_, err := repo.LoadExecutions()
+	suite.Assert().ErrorContains(err, "failed to read the executions file")
+	suite.Assert().Error(repo.Save(MigrationExecution{Version: 1}))
+
+	_, err = NewFileRepository(filepath.Join(path, "missing")).FindOne(1)
+	suite.Assert().ErrorContains(err, "failed to open the executions file")
+}
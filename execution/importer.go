@@ -0,0 +1,334 @@
+package execution
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StateImporter Must be implemented by any mechanism which reads the migrations state persisted
+// by other migration tools and converts it to migration executions. It eases switching to
+// this tool without re-running already applied migrations.
+type StateImporter interface {
+	// Name Must return the name of the tool the state is imported from. Used by the user
+	// to select the importer.
+	Name() string
+
+	// Import Must return the migration executions that correspond to the state persisted by
+	// the other tool. The registered versions (ascending) are provided for the tools that
+	// only store the current version and not the full history.
+	Import(registeredVersions []uint64) ([]MigrationExecution, error)
+}
+
+// TimedStateImporter Optional StateImporter capability, for importers which stamp executions
+// with the import time when the other tool did not store when a version was applied. The
+// handler imports through it with its own clock (see handler.WithClock and
+// handler.WithServerClock), instead of the local system time.
+type TimedStateImporter interface {
+	StateImporter
+
+	// ImportAt Same as Import, but now is used as the import time
+	ImportAt(registeredVersions []uint64, now time.Time) ([]MigrationExecution, error)
+}
+
+// Default table names used by the supported migration tools
+const (
+	GolangMigrateTable = "schema_migrations"
+	GooseTable         = "goose_db_version"
+	FlywayTable        = "flyway_schema_history"
+)
+
+// GolangMigrateImporter StateImporter implementation for golang-migrate's state table. Since
+// golang-migrate stores only the current version, all registered versions lower or equal to it
+// are considered executed. A "dirty" current version results in an unfinished execution.
+// Execution timestamps are set to the import time.
+type GolangMigrateImporter struct {
+	Db        *sql.DB
+	TableName string
+}
+
+func (i *GolangMigrateImporter) Name() string {
+	return "golang-migrate"
+}
+
+func (i *GolangMigrateImporter) Import(registeredVersions []uint64) ([]MigrationExecution, error) {
+	return i.ImportAt(registeredVersions, time.Now())
+}
+
+func (i *GolangMigrateImporter) ImportAt(
+	registeredVersions []uint64,
+	now time.Time,
+) ([]MigrationExecution, error) {
+	rows, err := i.Db.Query(
+		"SELECT version, dirty FROM " + tableNameOrDefault(i.TableName, GolangMigrateTable),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query golang-migrate state with error: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var version int64
+	var dirty, found bool
+	for rows.Next() {
+		if err = rows.Scan(&version, &dirty); err != nil {
+			return nil, fmt.Errorf("failed to read golang-migrate state with error: %w", err)
+		}
+		found = true
+	}
+
+	if err = rows.Err(); err != nil || !found {
+		return nil, err
+	}
+
+	return golangMigrateToExecutions(version, dirty, registeredVersions, importTimeMs(now)), nil
+}
+
+func golangMigrateToExecutions(
+	version int64,
+	dirty bool,
+	registeredVersions []uint64,
+	nowMs uint64,
+) []MigrationExecution {
+	var executions []MigrationExecution
+	if version < 0 {
+		return executions
+	}
+
+	for _, registered := range registeredVersions {
+		if registered > uint64(version) {
+			break
+		}
+
+		exec := MigrationExecution{Version: registered, ExecutedAtMs: nowMs, FinishedAtMs: nowMs}
+		if dirty && registered == uint64(version) {
+			exec.FinishedAtMs = 0
+		}
+		executions = append(executions, exec)
+	}
+
+	return executions
+}
+
+// GooseImporter StateImporter implementation for goose's state table. The latest row of each
+// version decides if the version is applied or not. Execution timestamps are set to the time
+// goose applied the version (the import time, if it is missing).
+type GooseImporter struct {
+	Db        *sql.DB
+	TableName string
+}
+
+func (i *GooseImporter) Name() string {
+	return "goose"
+}
+
+type gooseRow struct {
+	version     int64
+	isApplied   bool
+	appliedAtMs uint64
+}
+
+func (i *GooseImporter) Import(registeredVersions []uint64) ([]MigrationExecution, error) {
+	return i.ImportAt(registeredVersions, time.Now())
+}
+
+func (i *GooseImporter) ImportAt(_ []uint64, now time.Time) ([]MigrationExecution, error) {
+	rows, err := i.Db.Query(
+		"SELECT version_id, is_applied, tstamp FROM " +
+			tableNameOrDefault(i.TableName, GooseTable) + " ORDER BY id ASC",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goose state with error: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var gooseRows []gooseRow
+	for rows.Next() {
+		var row gooseRow
+		var appliedAt any
+		if err = rows.Scan(&row.version, &row.isApplied, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read goose state with error: %w", err)
+		}
+		row.appliedAtMs = importedTimeMs(appliedAt)
+		gooseRows = append(gooseRows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return gooseToExecutions(gooseRows, importTimeMs(now)), nil
+}
+
+func gooseToExecutions(rows []gooseRow, nowMs uint64) []MigrationExecution {
+	applied := make(map[uint64]MigrationExecution)
+	for _, row := range rows {
+		// Version 0 is goose's own initialization row
+		if row.version <= 0 {
+			continue
+		}
+
+		version := uint64(row.version)
+		if row.isApplied {
+			appliedAtMs := cmp.Or(row.appliedAtMs, nowMs)
+			applied[version] = MigrationExecution{
+				Version: version, ExecutedAtMs: appliedAtMs, FinishedAtMs: appliedAtMs,
+			}
+		} else {
+			delete(applied, version)
+		}
+	}
+
+	return sortedExecutions(applied)
+}
+
+// FlywayImporter StateImporter implementation for Flyway's schema history table. Only integer
+// Flyway versions are supported (they must match the versions of the registered migrations).
+// Repeatable migrations are ignored, undo migrations revert the version and failed migrations
+// result in unfinished executions. Executions start when Flyway installed the version and
+// finish after its execution time (they start at the import time, if it is missing).
+type FlywayImporter struct {
+	Db        *sql.DB
+	TableName string
+}
+
+func (i *FlywayImporter) Name() string {
+	return "flyway"
+}
+
+type flywayRow struct {
+	version         string
+	migrationType   string
+	success         bool
+	installedOnMs   uint64
+	executionTimeMs int64
+}
+
+func (i *FlywayImporter) Import(registeredVersions []uint64) ([]MigrationExecution, error) {
+	return i.ImportAt(registeredVersions, time.Now())
+}
+
+func (i *FlywayImporter) ImportAt(_ []uint64, now time.Time) ([]MigrationExecution, error) {
+	rows, err := i.Db.Query(
+		"SELECT version, type, success, installed_on, execution_time FROM " +
+			tableNameOrDefault(i.TableName, FlywayTable) +
+			" WHERE version IS NOT NULL ORDER BY installed_rank ASC",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flyway state with error: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var flywayRows []flywayRow
+	for rows.Next() {
+		var row flywayRow
+		var installedOn any
+		var executionTime sql.NullInt64
+		err = rows.Scan(
+			&row.version, &row.migrationType, &row.success, &installedOn, &executionTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flyway state with error: %w", err)
+		}
+		row.installedOnMs = importedTimeMs(installedOn)
+		row.executionTimeMs = executionTime.Int64
+		flywayRows = append(flywayRows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return flywayToExecutions(flywayRows, importTimeMs(now))
+}
+
+func flywayToExecutions(rows []flywayRow, nowMs uint64) ([]MigrationExecution, error) {
+	applied := make(map[uint64]MigrationExecution)
+	for _, row := range rows {
+		version, err := strconv.ParseUint(row.version, 10, 64)
+		if err != nil {
+			return nil, errors.New(
+				"failed to convert flyway state, only integer versions are supported." +
+					" Found version: " + row.version,
+			)
+		}
+
+		if strings.HasPrefix(strings.ToUpper(row.migrationType), "UNDO") {
+			if row.success {
+				delete(applied, version)
+			}
+			continue
+		}
+
+		exec := MigrationExecution{
+			Version: version, ExecutedAtMs: cmp.Or(row.installedOnMs, nowMs),
+		}
+		if row.success {
+			exec.FinishedAtMs = exec.ExecutedAtMs + uint64(max(row.executionTimeMs, 0))
+		}
+		applied[version] = exec
+	}
+
+	return sortedExecutions(applied), nil
+}
+
+func sortedExecutions(byVersion map[uint64]MigrationExecution) []MigrationExecution {
+	var executions []MigrationExecution
+	for _, exec := range byVersion {
+		executions = append(executions, exec)
+	}
+
+	slices.SortFunc(
+		executions, func(a, b MigrationExecution) int {
+			return cmp.Compare(a.Version, b.Version)
+		},
+	)
+
+	return executions
+}
+
+func tableNameOrDefault(tableName string, defaultTableName string) string {
+	if tableName == "" {
+		return defaultTableName
+	}
+	return tableName
+}
+
+func importTimeMs(now time.Time) uint64 {
+	return uint64(max(now.UnixMilli(), 0))
+}
+
+// importedTimestampLayouts The layouts of the timestamps read as text from the state tables of
+// other tools (for example, by the mysql driver without parseTime). Timestamps without a time
+// zone are read as UTC.
+var importedTimestampLayouts = []string{
+	time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999",
+}
+
+// importedTimeMs Converts a timestamp read from the state table of another tool to unix
+// milliseconds. Returns 0 if the timestamp is missing or its format is not known.
+func importedTimeMs(value any) uint64 {
+	var timestamp time.Time
+	switch typed := value.(type) {
+	case time.Time:
+		timestamp = typed
+	case []byte:
+		return importedTimeMs(string(typed))
+	case string:
+		for _, layout := range importedTimestampLayouts {
+			if parsed, err := time.Parse(layout, typed); err == nil {
+				timestamp = parsed
+				break
+			}
+		}
+	}
+
+	if timestamp.UnixMilli() <= 0 {
+		return 0
+	}
+	return uint64(timestamp.UnixMilli())
+}
@@ -0,0 +1,98 @@
+package execution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ImporterTestSuite struct {
+	suite.Suite
+}
+
+func TestImporterTestSuite(t *testing.T) {
+	suite.Run(t, new(ImporterTestSuite))
+}
+
+func (suite *ImporterTestSuite) TestItCanConvertGolangMigrateState() {
+	registered := []uint64{1, 2, 3, 4}
+
+	suite.Assert().Equal(
+		[]MigrationExecution{
+			{Version: 1, ExecutedAtMs: 10, FinishedAtMs: 10},
+			{Version: 2, ExecutedAtMs: 10, FinishedAtMs: 10},
+			{Version: 3, ExecutedAtMs: 10, FinishedAtMs: 10},
+		},
+		golangMigrateToExecutions(3, false, registered, 10),
+	)
+	suite.Assert().Equal(
+		[]MigrationExecution{
+			{Version: 1, ExecutedAtMs: 10, FinishedAtMs: 10},
+			{Version: 2, ExecutedAtMs: 10, FinishedAtMs: 0},
+		},
+		golangMigrateToExecutions(2, true, registered, 10),
+	)
+	suite.Assert().Empty(golangMigrateToExecutions(-1, false, registered, 10))
+}
+
+func (suite *ImporterTestSuite) TestItCanConvertGooseState() {
+	rows := []gooseRow{
+		{0, true, 1}, {3, true, 2}, {1, true, 3}, {2, true, 0}, {3, false, 5}, {4, true, 6},
+	}
+
+	suite.Assert().Equal(
+		[]MigrationExecution{
+			{Version: 1, ExecutedAtMs: 3, FinishedAtMs: 3},
+			// Versions without a timestamp are executed at the import time
+			{Version: 2, ExecutedAtMs: 10, FinishedAtMs: 10},
+			{Version: 4, ExecutedAtMs: 6, FinishedAtMs: 6},
+		},
+		gooseToExecutions(rows, 10),
+	)
+}
+
+func (suite *ImporterTestSuite) TestItCanConvertFlywayState() {
+	rows := []flywayRow{
+		{"1", "SQL", true, 100, 5}, {"2", "JDBC", true, 0, 7}, {"3", "SQL", true, 200, 1},
+		{"3", "UNDO_SQL", true, 300, 1}, {"4", "SQL", false, 400, 9},
+	}
+
+	executions, err := flywayToExecutions(rows, 10)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]MigrationExecution{
+			{Version: 1, ExecutedAtMs: 100, FinishedAtMs: 105},
+			// Versions without an install time are executed at the import time
+			{Version: 2, ExecutedAtMs: 10, FinishedAtMs: 17},
+			{Version: 4, ExecutedAtMs: 400, FinishedAtMs: 0},
+		},
+		executions,
+	)
+
+	_, err = flywayToExecutions([]flywayRow{{"1.1", "SQL", true, 0, 0}}, 10)
+	suite.Assert().ErrorContains(err, "only integer versions are supported")
+}
+
+func (suite *ImporterTestSuite) TestItConvertsTheImportedTimestamps() {
+	appliedAt := time.Date(2024, 6, 1, 10, 0, 0, 500_000_000, time.UTC)
+	scenarios := map[string]struct {
+		value    any
+		expected uint64
+	}{
+		"time":             {appliedAt, 1717236000500},
+		"text":             {[]byte("2024-06-01 10:00:00.5"), 1717236000500},
+		"text with zone":   {"2024-06-01 12:00:00.5+02:00", 1717236000500},
+		"rfc3339":          {"2024-06-01T10:00:00.5Z", 1717236000500},
+		"missing":          {nil, 0},
+		"unknown format":   {"01/06/2024", 0},
+		"before the epoch": {time.Time{}, 0},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Assert().Equal(
+			scenario.expected, importedTimeMs(scenario.value), "failed scenario %s", name,
+		)
+	}
+}
@@ -0,0 +1,84 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrLockLost is returned (wrapped) when the migrations lock held by the current process was lost
+// (for example, its session expired), so another process may be running migrations
+var ErrLockLost = errors.New("migrations lock was lost")
+
+// Locker Optional Repository capability which allows taking an exclusive migrations lock, so
+// only one process runs migrations at a time, cluster-wide
+type Locker interface {
+	// Lock Must acquire the lock or, if it is held by another process, return an error
+	// wrapping ErrLockHeld. Must not block until the lock is released.
+	Lock() error
+
+	// Unlock Must release the lock, if it is held by the current process
+	Unlock() error
+}
+
+// LockHolder The process holding the migrations lock
+type LockHolder struct {
+	// Owner Identifies the process (for example, host:pid)
+	Owner        string
+	AcquiredAtMs uint64
+}
+
+// LockInspector Optional Locker capability which reports who holds the migrations lock, so
+// operators can tell why their runs wait or fail
+type LockInspector interface {
+	// CurrentLockHolder Must return the current lock holder, or nil if the lock is free
+	CurrentLockHolder() (*LockHolder, error)
+}
+
+// LockKeeper Optional Locker capability for locks which can be lost while held (for example,
+// session based locks). Runs check the lock before saving each execution, so a run which lost the
+// lock stops instead of racing with the next lock holder.
+type LockKeeper interface {
+	// CheckLock Must return an error wrapping ErrLockLost if the lock held by the current process
+	// was lost, nil otherwise
+	CheckLock() error
+}
+
+// InMemoryLocker Implementation of Locker and LockInspector. Can be used in unit tests. Held
+// (and optionally Owner and AcquiredAtMs) can be set to simulate a lock held by another process.
+type InMemoryLocker struct {
+	mu           sync.Mutex
+	Held         bool
+	Owner        string
+	AcquiredAtMs uint64
+}
+
+func (locker *InMemoryLocker) Lock() error {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if locker.Held {
+		return fmt.Errorf("failed to acquire the in memory lock: %w", ErrLockHeld)
+	}
+
+	locker.Held = true
+	return nil
+}
+
+func (locker *InMemoryLocker) Unlock() error {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	locker.Held = false
+	return nil
+}
+
+func (locker *InMemoryLocker) CurrentLockHolder() (*LockHolder, error) {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if !locker.Held {
+		return nil, nil
+	}
+	return &LockHolder{Owner: locker.Owner, AcquiredAtMs: locker.AcquiredAtMs}, nil
+}
@@ -0,0 +1,106 @@
+// Package local includes an execution.Locker implementation backed by an operating system lock,
+// for agents which run migrations on the same host (for example, CI runners sharing a build
+// machine). It behaves the same on Linux, macOS and Windows: the lock is not waited for and it is
+// released by the operating system if the process dies. It can be used with any repository, via
+// the handler.WithLocker option.
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// validName The allowed lock names, which are used both in file names and in Windows object
+// names (which can not contain backslashes)
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Settings The lock name and the platform specific lock settings
+type Settings struct {
+	// Name Identifies the lock, processes using the same name exclude each other (for example,
+	// the name of the migrated database). Can contain letters, digits, dots, dashes and
+	// underscores.
+	Name string
+
+	// Dir Unix-like systems only. The directory of the lock file (<Name>.lock), which is locked
+	// with flock. Defaults to os.TempDir()
+	Dir string
+
+	// Global Windows only. Creates the named mutex in the Global\ namespace, shared by all
+	// sessions (for example, a service and the logged-on users), instead of the session's
+	// (Local\). Creating global objects may require the SeCreateGlobalPrivilege privilege.
+	Global bool
+}
+
+// Locker execution.Locker implementation which holds an operating system lock: an exclusive
+// flock on a lock file on Unix-like systems and a named mutex on Windows. If another process
+// holds the lock, execution.ErrLockHeld is returned, without waiting. On other platforms, Lock
+// fails with errors.ErrUnsupported.
+type Locker struct {
+	settings Settings
+
+	mu     sync.Mutex
+	unlock func() error
+}
+
+// NewLocker Builds a new Locker. The lock is not taken until Lock is called.
+func NewLocker(settings Settings) (*Locker, error) {
+	if !validName.MatchString(settings.Name) {
+		return nil, fmt.Errorf(
+			"invalid local lock name %q, it can contain only letters, digits, ., - and _",
+			settings.Name,
+		)
+	}
+
+	if settings.Dir == "" {
+		settings.Dir = os.TempDir()
+	}
+
+	return &Locker{settings: settings}, nil
+}
+
+// Lock See execution.Locker
+func (locker *Locker) Lock() error {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if locker.unlock != nil {
+		return nil
+	}
+
+	unlock, err := lock(locker.settings)
+	if err != nil {
+		if errors.Is(err, execution.ErrLockHeld) {
+			return fmt.Errorf("failed to acquire the local lock %s: %w", locker.settings.Name, err)
+		}
+		return fmt.Errorf(
+			"failed to acquire the local lock %s with error: %w", locker.settings.Name, err,
+		)
+	}
+
+	locker.unlock = unlock
+	return nil
+}
+
+// Unlock See execution.Locker
+func (locker *Locker) Unlock() error {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if locker.unlock == nil {
+		return nil
+	}
+
+	err := locker.unlock()
+	locker.unlock = nil
+	if err != nil {
+		return fmt.Errorf(
+			"failed to release the local lock %s with error: %w", locker.settings.Name, err,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package local
+
+import "errors"
+
+// lock Operating system locks are not supported on this platform
+func lock(Settings) (func() error, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,46 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalTestSuite struct {
+	suite.Suite
+}
+
+func TestLocalTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalTestSuite))
+}
+
+func (suite *LocalTestSuite) TestItExcludesOtherLockersWithTheSameName() {
+	settings := Settings{Name: "app-db", Dir: suite.T().TempDir()}
+	first, err := NewLocker(settings)
+	suite.Require().NoError(err)
+	second, _ := NewLocker(settings)
+	other, _ := NewLocker(Settings{Name: "billing-db", Dir: settings.Dir})
+
+	suite.Assert().NoError(first.Lock())
+	// Locking a held lock again is a no-op
+	suite.Assert().NoError(first.Lock())
+
+	err = second.Lock()
+	suite.Assert().ErrorIs(err, execution.ErrLockHeld)
+	suite.Assert().ErrorContains(err, "failed to acquire the local lock app-db")
+	suite.Assert().NoError(other.Lock())
+
+	suite.Assert().NoError(first.Unlock())
+	suite.Assert().NoError(first.Unlock())
+	suite.Assert().NoError(second.Lock())
+	suite.Assert().NoError(second.Unlock())
+	suite.Assert().NoError(other.Unlock())
+}
+
+func (suite *LocalTestSuite) TestItRejectsInvalidNames() {
+	for _, name := range []string{"", `app\db`, "app/db", "app db"} {
+		_, err := NewLocker(Settings{Name: name})
+		suite.Assert().ErrorContains(err, "invalid local lock name", name)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package local
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// lock Takes an exclusive, non-blocking flock on the lock file, which is kept open while the
+// lock is held. The lock file is not removed on unlock, so processes racing for the lock always
+// lock the same file.
+func lock(settings Settings) (func() error, error) {
+	file, err := os.OpenFile(
+		filepath.Join(settings.Dir, settings.Name+".lock"), os.O_CREATE|os.O_RDWR, 0644,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, execution.ErrLockHeld
+		}
+		return nil, err
+	}
+
+	return func() error {
+		return errors.Join(syscall.Flock(int(file.Fd()), syscall.LOCK_UN), file.Close())
+	}, nil
+}
@@ -0,0 +1,84 @@
+//go:build windows
+
+package local
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procCreateMutexW = kernel32.NewProc("CreateMutexW")
+	procReleaseMutex = kernel32.NewProc("ReleaseMutex")
+)
+
+// lock Acquires the named mutex, without waiting. Mutexes are owned by the thread which acquired
+// them, so the mutex is acquired and released by a goroutine locked to its thread, which waits
+// for the unlock in between. A mutex abandoned by a dead process is acquired.
+func lock(settings Settings) (func() error, error) {
+	name := `Local\go-migrations-` + settings.Name
+	if settings.Global {
+		name = `Global\go-migrations-` + settings.Name
+	}
+
+	acquired := make(chan error, 1)
+	release := make(chan struct{})
+	released := make(chan error, 1)
+
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		handle, err := createMutex(name)
+		if err != nil {
+			acquired <- err
+			return
+		}
+
+		event, err := syscall.WaitForSingleObject(handle, 0)
+		if err != nil || (event != syscall.WAIT_OBJECT_0 && event != syscall.WAIT_ABANDONED) {
+			_ = syscall.CloseHandle(handle)
+			if err == nil {
+				err = execution.ErrLockHeld
+			}
+			acquired <- err
+			return
+		}
+		acquired <- nil
+
+		<-release
+		result, _, releaseErr := procReleaseMutex.Call(uintptr(handle))
+		if result != 0 {
+			releaseErr = nil
+		}
+		released <- errors.Join(releaseErr, syscall.CloseHandle(handle))
+	}()
+
+	if err := <-acquired; err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		close(release)
+		return <-released
+	}, nil
+}
+
+// createMutex Opens the named mutex, creating it if it does not exist, without owning it
+func createMutex(name string) (syscall.Handle, error) {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+
+	handle, _, err := procCreateMutexW.Call(0, 0, uintptr(unsafe.Pointer(namePtr)))
+	if handle == 0 {
+		return 0, err
+	}
+	return syscall.Handle(handle), nil
+}
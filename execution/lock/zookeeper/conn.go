@@ -0,0 +1,425 @@
+package zookeeper
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The request op codes used by the locker (see the ZooKeeper jute protocol)
+const (
+	opCreate       int32 = 1
+	opDelete       int32 = 2
+	opGetData      int32 = 4
+	opGetChildren  int32 = 8
+	opPing         int32 = 11
+	opAuth         int32 = 100
+	opCloseSession int32 = -11
+)
+
+// The xids of the ping requests, which keep the session alive, and of the auth requests
+const (
+	pingXid int32 = -2
+	authXid int32 = -4
+)
+
+// The create flags
+const (
+	flagEphemeral int32 = 1
+	flagSequence  int32 = 2
+)
+
+// permAll All the node permissions (read, write, create, delete and admin)
+const permAll int32 = 0x1f
+
+// The ZooKeeper error codes handled by the locker
+const (
+	errCodeNoNode         int32 = -101
+	errCodeNodeExists     int32 = -110
+	errCodeSessionExpired int32 = -112
+	errCodeAuthFailed     int32 = -115
+)
+
+var (
+	errNoNode         = errors.New("zookeeper node does not exist")
+	errNodeExists     = errors.New("zookeeper node already exists")
+	errSessionExpired = errors.New("zookeeper session expired")
+	errAuthFailed     = errors.New("zookeeper authentication failed")
+)
+
+// acl A node access control entry
+type acl struct {
+	perms  int32
+	scheme string
+	id     string
+}
+
+// openACL Grants all permissions to any client
+var openACL = []acl{{perms: permAll, scheme: "world", id: "anyone"}}
+
+// digestACL Grants all permissions only to the sessions authenticated with the user:password
+// digest credentials
+func digestACL(auth string) []acl {
+	user, _, _ := strings.Cut(auth, ":")
+	hash := sha1.Sum([]byte(auth))
+	id := user + ":" + base64.StdEncoding.EncodeToString(hash[:])
+	return []acl{{perms: permAll, scheme: "digest", id: id}}
+}
+
+// codeErr Converts the ZooKeeper error code of a response to an error
+func codeErr(code int32) error {
+	switch code {
+	case 0:
+		return nil
+	case errCodeNoNode:
+		return errNoNode
+	case errCodeNodeExists:
+		return errNodeExists
+	case errCodeSessionExpired:
+		return errSessionExpired
+	case errCodeAuthFailed:
+		return errAuthFailed
+	}
+	return fmt.Errorf("zookeeper request failed with error code %d", code)
+}
+
+// encoder Encodes the request fields with the jute (big endian, length prefixed) encoding
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int32(value int32) *encoder {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(value))
+	return e
+}
+
+func (e *encoder) int64(value int64) *encoder {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(value))
+	return e
+}
+
+func (e *encoder) bool(value bool) *encoder {
+	if value {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+	return e
+}
+
+// bytes Encodes a buffer. A nil buffer is encoded with the -1 length.
+func (e *encoder) bytes(value []byte) *encoder {
+	if value == nil {
+		return e.int32(-1)
+	}
+	e.int32(int32(len(value)))
+	e.buf = append(e.buf, value...)
+	return e
+}
+
+func (e *encoder) string(value string) *encoder {
+	e.int32(int32(len(value)))
+	e.buf = append(e.buf, value...)
+	return e
+}
+
+// decoder Decodes the response fields. The first decoding error is kept in err and the following
+// reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(size int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if size < 0 || len(d.buf) < size {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	value := d.buf[:size]
+	d.buf = d.buf[size:]
+	return value
+}
+
+func (d *decoder) int32() int32 {
+	if value := d.next(4); value != nil {
+		return int32(binary.BigEndian.Uint32(value))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if value := d.next(8); value != nil {
+		return int64(binary.BigEndian.Uint64(value))
+	}
+	return 0
+}
+
+// bytes Decodes a buffer. The -1 length is decoded as nil.
+func (d *decoder) bytes() []byte {
+	size := d.int32()
+	if size == -1 {
+		return nil
+	}
+	return d.next(int(size))
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+// conn A ZooKeeper session, over a single server connection. Requests are sent one at a time (the
+// locker does not use watches), so each response is read right after its request. The session is
+// not moved to another server if the connection fails, it is lost instead (see lost).
+type conn struct {
+	mu      sync.Mutex
+	netConn net.Conn
+	timeout time.Duration
+	xid     int32
+	stop    chan struct{}
+
+	lostMu  sync.Mutex
+	lostErr error
+}
+
+// dial Opens a session on the first reachable server, with the session timeout, authenticates
+// it with the digest credentials (if any) and keeps it alive with pings until closed
+func dial(servers []string, timeout time.Duration, auth string) (*conn, error) {
+	var errs []error
+	for _, server := range servers {
+		netConn, err := net.DialTimeout("tcp", server, timeout)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		c := &conn{netConn: netConn, timeout: timeout, stop: make(chan struct{})}
+		if err = c.connect(); err == nil && auth != "" {
+			err = c.authenticate(auth)
+		}
+		if err != nil {
+			_ = netConn.Close()
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+			continue
+		}
+
+		go c.ping()
+		return c, nil
+	}
+	return nil, fmt.Errorf("failed to connect to zookeeper with error: %w", errors.Join(errs...))
+}
+
+// connect Sends the connect request, which creates a new session
+func (c *conn) connect() error {
+	// The protocol version, the last seen zxid, the timeout, the session id (0 for a new
+	// session) and the session password
+	request := (&encoder{}).int32(0).int64(0).int32(int32(c.timeout.Milliseconds())).
+		int64(0).bytes(make([]byte, 16))
+
+	_ = c.netConn.SetDeadline(time.Now().Add(c.timeout))
+	defer func() { _ = c.netConn.SetDeadline(time.Time{}) }()
+
+	if err := c.write(request.buf); err != nil {
+		return err
+	}
+	response, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	d := &decoder{buf: response}
+	d.int32() // protocol version
+	negotiatedTimeout := d.int32()
+	d.int64() // session id
+	d.bytes() // password
+	if d.err != nil {
+		return fmt.Errorf("invalid zookeeper connect response: %w", d.err)
+	}
+	if negotiatedTimeout <= 0 {
+		return errors.New("the zookeeper session was rejected")
+	}
+
+	c.timeout = time.Duration(negotiatedTimeout) * time.Millisecond
+	return nil
+}
+
+// authenticate Adds the user:password digest credentials to the session
+func (c *conn) authenticate(auth string) error {
+	body := (&encoder{}).int32(0).string("digest").bytes([]byte(auth))
+	if _, err := c.send(authXid, opAuth, body.buf); err != nil {
+		return fmt.Errorf("failed to authenticate with error: %w", err)
+	}
+	return nil
+}
+
+// ping Pings the server, at a third of the session timeout, until the connection is closed. If a
+// ping fails, the session is lost (see lost): it expires and its ephemeral nodes (the lock) are
+// deleted by the server.
+func (c *conn) ping() {
+	ticker := time.NewTicker(c.timeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if _, err := c.send(pingXid, opPing, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// lose Records that the session was lost and closes the connection, so the following requests
+// fail
+func (c *conn) lose(err error) {
+	c.lostMu.Lock()
+	defer c.lostMu.Unlock()
+
+	if c.lostErr == nil {
+		c.lostErr = err
+		_ = c.netConn.Close()
+	}
+}
+
+// lost Returns the reason the session was lost (the connection failed or the session expired),
+// nil if it is alive
+func (c *conn) lost() error {
+	c.lostMu.Lock()
+	defer c.lostMu.Unlock()
+	return c.lostErr
+}
+
+// request Sends the request and returns the response body
+func (c *conn) request(op int32, body []byte) ([]byte, error) {
+	c.mu.Lock()
+	c.xid++
+	xid := c.xid
+	c.mu.Unlock()
+	return c.send(xid, op, body)
+}
+
+// send Writes the request and reads its response, within the session timeout. If the connection
+// fails or the session expired, the session is lost (see lost).
+func (c *conn) send(xid int32, op int32, body []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.netConn.SetDeadline(time.Now().Add(c.timeout))
+	request := (&encoder{}).int32(xid).int32(op)
+	err := c.write(append(request.buf, body...))
+	var response []byte
+	if err == nil {
+		response, err = c.read()
+	}
+	if err != nil {
+		c.lose(fmt.Errorf("the zookeeper connection failed with error: %w", err))
+		return nil, err
+	}
+
+	d := &decoder{buf: response}
+	responseXid := d.int32()
+	d.int64() // zxid
+	code := d.int32()
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid zookeeper response: %w", d.err)
+	}
+	if responseXid != xid {
+		return nil, fmt.Errorf("unexpected zookeeper response xid %d, expected %d", responseXid, xid)
+	}
+	if code == errCodeSessionExpired {
+		c.lose(errSessionExpired)
+	}
+	return d.buf, codeErr(code)
+}
+
+// write Writes the length prefixed packet
+func (c *conn) write(packet []byte) error {
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(packet)))
+	_, err := c.netConn.Write(append(frame, packet...))
+	return err
+}
+
+// read Reads a length prefixed packet
+func (c *conn) read() ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.netConn, header); err != nil {
+		return nil, err
+	}
+	packet := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(c.netConn, packet); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+// close Closes the session, which deletes its ephemeral nodes, and the connection
+func (c *conn) close() error {
+	close(c.stop)
+	_, err := c.request(opCloseSession, nil)
+	return errors.Join(err, c.netConn.Close())
+}
+
+// create Creates the node with the ACL and returns its path (which includes the sequence number,
+// for sequential nodes)
+func (c *conn) create(path string, data []byte, acls []acl, flags int32) (string, error) {
+	body := (&encoder{}).string(path).bytes(data).int32(int32(len(acls)))
+	for _, entry := range acls {
+		body.int32(entry.perms).string(entry.scheme).string(entry.id)
+	}
+	body.int32(flags)
+	response, err := c.request(opCreate, body.buf)
+	if err != nil {
+		return "", err
+	}
+
+	d := &decoder{buf: response}
+	created := d.string()
+	return created, d.err
+}
+
+// delete Deletes the node, regardless of its version
+func (c *conn) delete(path string) error {
+	_, err := c.request(opDelete, (&encoder{}).string(path).int32(-1).buf)
+	return err
+}
+
+// children Returns the names of the node children
+func (c *conn) children(path string) ([]string, error) {
+	response, err := c.request(opGetChildren, (&encoder{}).string(path).bool(false).buf)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{buf: response}
+	children := make([]string, max(d.int32(), 0))
+	for i := range children {
+		children[i] = d.string()
+	}
+	return children, d.err
+}
+
+// data Returns the node data and its creation time (the ctime of the node stat)
+func (c *conn) data(path string) ([]byte, int64, error) {
+	response, err := c.request(opGetData, (&encoder{}).string(path).bool(false).buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	d := &decoder{buf: response}
+	data := d.bytes()
+	d.int64() // czxid
+	d.int64() // mzxid
+	createdAtMs := d.int64()
+	return data, createdAtMs, d.err
+}
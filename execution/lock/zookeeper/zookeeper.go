@@ -0,0 +1,276 @@
+// Package zookeeper includes an execution.Locker implementation backed by ZooKeeper, for
+// organizations standardized on ZooKeeper for coordination. It can be used with any repository,
+// via the handler.WithLocker option.
+package zookeeper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// lockNodePrefix The name prefix of the sequential lock nodes
+const lockNodePrefix = "lock-"
+
+// Settings The ZooKeeper ensemble and the lock settings
+type Settings struct {
+	// Servers The host:port addresses of the ensemble servers, tried in order
+	Servers []string
+
+	// Path The lock node (for example, /go-migrations/app/lock). The lock is held by the process
+	// owning its lowest sequential child. Missing parent nodes are created.
+	Path string
+
+	// SessionTimeout The timeout of the ZooKeeper session, kept alive while the lock is held. If
+	// the process dies, the lock is released after the timeout. Defaults to 10s (the server may
+	// negotiate a different one)
+	SessionTimeout time.Duration
+
+	// Auth The user:password digest credentials of the sessions. When set, the lock nodes (and
+	// their missing parents) are created with an ACL granting access only to these credentials.
+	// Otherwise, they are created with the open ACL, so any client of the ensemble can delete the
+	// lock.
+	Auth string
+}
+
+// Locker execution.Locker implementation which takes the lock by creating an ephemeral,
+// sequential child of the lock node (the usual ZooKeeper lock recipe). The lock is held by the
+// lowest child, so, if another process owns it, the child is deleted and execution.ErrLockHeld is
+// returned, without waiting. Also implements execution.LockInspector and execution.LockKeeper:
+// if the session is lost while the lock is held (it failed or expired), the lock is reported as
+// lost, since the server deletes the lock node.
+type Locker struct {
+	settings Settings
+	owner    string
+	acls     []acl
+
+	mu      sync.Mutex
+	session *conn
+	node    string
+}
+
+// NewLocker Builds a new Locker. No connection is made until the lock is taken or inspected.
+func NewLocker(settings Settings) (*Locker, error) {
+	if len(settings.Servers) == 0 {
+		return nil, errors.New("at least one zookeeper server is required")
+	}
+
+	settings.Path = "/" + strings.Trim(settings.Path, "/")
+	if settings.Path == "/" {
+		return nil, errors.New("the zookeeper lock path is required")
+	}
+
+	if settings.SessionTimeout == 0 {
+		settings.SessionTimeout = 10 * time.Second
+	}
+
+	acls := openACL
+	if settings.Auth != "" {
+		if user, _, found := strings.Cut(settings.Auth, ":"); !found || user == "" {
+			return nil, errors.New("invalid zookeeper auth, it must be user:password")
+		}
+		acls = digestACL(settings.Auth)
+	}
+
+	return &Locker{settings: settings, owner: newLockOwner(), acls: acls}, nil
+}
+
+// newLockOwner Identifies the process taking the lock (host:pid:random)
+func newLockOwner() string {
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// lockValue The data of the lock nodes, identifying the lock holder
+type lockValue struct {
+	Owner        string `json:"owner"`
+	AcquiredAtMs uint64 `json:"acquiredAtMs"`
+}
+
+// Lock See execution.Locker. Opens a session, which is kept alive until Unlock, and creates the
+// ephemeral lock child, failing with execution.ErrLockHeld if a lower child exists.
+func (locker *Locker) Lock() error {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if locker.session != nil {
+		return nil
+	}
+
+	session, err := locker.dial()
+	if err != nil {
+		return err
+	}
+
+	node, err := locker.createLockNode(session)
+	if err != nil {
+		_ = session.close()
+		return fmt.Errorf("failed to acquire the zookeeper lock with error: %w", err)
+	}
+
+	holder, err := lowestLockNode(session, locker.settings.Path)
+	if err != nil || holder != node {
+		_ = session.close()
+		if err != nil {
+			return fmt.Errorf("failed to acquire the zookeeper lock with error: %w", err)
+		}
+		return fmt.Errorf("failed to acquire the zookeeper lock: %w", execution.ErrLockHeld)
+	}
+
+	locker.session = session
+	locker.node = node
+	return nil
+}
+
+// dial Opens a session on the ensemble
+func (locker *Locker) dial() (*conn, error) {
+	return dial(locker.settings.Servers, locker.settings.SessionTimeout, locker.settings.Auth)
+}
+
+// createLockNode Creates the missing parents of the lock children and the ephemeral lock child,
+// returning its name
+func (locker *Locker) createLockNode(session *conn) (string, error) {
+	path := ""
+	for _, name := range strings.Split(strings.TrimPrefix(locker.settings.Path, "/"), "/") {
+		path += "/" + name
+		_, err := session.create(path, nil, locker.acls, 0)
+		if err != nil && !errors.Is(err, errNodeExists) {
+			return "", err
+		}
+	}
+
+	data, err := json.Marshal(
+		lockValue{Owner: locker.owner, AcquiredAtMs: uint64(time.Now().UnixMilli())},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	created, err := session.create(
+		locker.settings.Path+"/"+lockNodePrefix, data, locker.acls, flagEphemeral|flagSequence,
+	)
+	if err != nil {
+		return "", err
+	}
+	return created[strings.LastIndex(created, "/")+1:], nil
+}
+
+// lowestLockNode Returns the name of the lock child holding the lock (the one with the lowest
+// sequence number), or an empty string if the lock is free
+func lowestLockNode(session *conn, path string) (string, error) {
+	children, err := session.children(path)
+	if errors.Is(err, errNoNode) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var nodes []string
+	for _, child := range children {
+		if strings.HasPrefix(child, lockNodePrefix) {
+			nodes = append(nodes, child)
+		}
+	}
+	if len(nodes) == 0 {
+		return "", nil
+	}
+
+	// The sequence numbers are zero padded to 10 digits, so they sort lexicographically
+	sort.Strings(nodes)
+	return nodes[0], nil
+}
+
+// CheckLock See execution.LockKeeper
+func (locker *Locker) CheckLock() error {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if locker.session == nil {
+		return nil
+	}
+	if err := locker.session.lost(); err != nil {
+		return fmt.Errorf("%w, the zookeeper lock session was lost: %w", execution.ErrLockLost, err)
+	}
+	return nil
+}
+
+// Unlock See execution.Locker. Deletes the lock child and closes the session.
+func (locker *Locker) Unlock() error {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if locker.session == nil {
+		return nil
+	}
+
+	session, node := locker.session, locker.node
+	locker.session = nil
+	locker.node = ""
+	if session.lost() != nil {
+		// The session, and its lock node, are already gone
+		_ = session.close()
+		return nil
+	}
+
+	err := session.delete(locker.settings.Path + "/" + node)
+	if errors.Is(err, errNoNode) {
+		err = nil
+	}
+	err = errors.Join(err, session.close())
+
+	if err != nil {
+		return fmt.Errorf("failed to release the zookeeper lock with error: %w", err)
+	}
+	return nil
+}
+
+// CurrentLockHolder See execution.LockInspector. Uses the lock session, if the lock is held,
+// otherwise a short-lived session.
+func (locker *Locker) CurrentLockHolder() (*execution.LockHolder, error) {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	session := locker.session
+	if session == nil {
+		var err error
+		if session, err = locker.dial(); err != nil {
+			return nil, err
+		}
+		defer func() { _ = session.close() }()
+	}
+
+	node, err := lowestLockNode(session, locker.settings.Path)
+	if err != nil || node == "" {
+		return nil, err
+	}
+
+	data, createdAtMs, err := session.data(locker.settings.Path + "/" + node)
+	if errors.Is(err, errNoNode) {
+		// Released in the meantime
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var holder lockValue
+	if err = json.Unmarshal(data, &holder); err != nil {
+		return nil, fmt.Errorf("invalid zookeeper lock node data with error: %w", err)
+	}
+	if holder.AcquiredAtMs == 0 {
+		holder.AcquiredAtMs = uint64(createdAtMs)
+	}
+	return &execution.LockHolder{Owner: holder.Owner, AcquiredAtMs: holder.AcquiredAtMs}, nil
+}
@@ -0,0 +1,349 @@
+package zookeeper
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+type ZookeeperTestSuite struct {
+	suite.Suite
+	server   *fakeServer
+	listener net.Listener
+}
+
+func TestZookeeperTestSuite(t *testing.T) {
+	suite.Run(t, new(ZookeeperTestSuite))
+}
+
+// fakeNode A node of the fake server tree
+type fakeNode struct {
+	data      []byte
+	acls      []acl
+	session   int64
+	createdAt int64
+	sequence  int
+}
+
+// fakeServer Serves the subset of the ZooKeeper protocol used by the locker
+type fakeServer struct {
+	mu       sync.Mutex
+	nodes    map[string]*fakeNode
+	sessions int64
+	pings    int
+	timeout  int32
+	// auth The accepted digest credentials
+	auth string
+	// expired Expires all sessions
+	expired bool
+}
+
+func (s *fakeServer) serve(listener net.Listener) {
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(&conn{netConn: netConn})
+	}
+}
+
+func (s *fakeServer) handle(c *conn) {
+	s.mu.Lock()
+	s.sessions++
+	session := s.sessions
+	s.mu.Unlock()
+
+	defer func() {
+		_ = c.netConn.Close()
+		s.expire(session)
+	}()
+
+	if _, err := c.read(); err != nil {
+		return
+	}
+	connected := (&encoder{}).int32(0).int32(s.timeout).int64(session).bytes(make([]byte, 16))
+	if err := c.write(connected.buf); err != nil {
+		return
+	}
+
+	for {
+		packet, err := c.read()
+		if err != nil {
+			return
+		}
+
+		d := &decoder{buf: packet}
+		xid := d.int32()
+		op := d.int32()
+		body, code := s.apply(session, op, d)
+
+		response := (&encoder{}).int32(xid).int64(0).int32(code)
+		if err = c.write(append(response.buf, body...)); err != nil || op == opCloseSession {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) apply(session int64, op int32, d *decoder) ([]byte, int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.expired {
+		return nil, errCodeSessionExpired
+	}
+
+	switch op {
+	case opPing:
+		s.pings++
+	case opAuth:
+		d.int32()
+		if scheme, auth := d.string(), d.string(); scheme != "digest" || auth != s.auth {
+			return nil, errCodeAuthFailed
+		}
+	case opCreate:
+		path, data := d.string(), d.bytes()
+		acls := make([]acl, d.int32())
+		for i := range acls {
+			acls[i] = acl{perms: d.int32(), scheme: d.string(), id: d.string()}
+		}
+		flags := d.int32()
+
+		parent := path[:strings.LastIndex(path, "/")]
+		if parent != "" && s.nodes[parent] == nil {
+			return nil, errCodeNoNode
+		}
+		if flags&flagSequence != 0 {
+			s.nodes[parent].sequence++
+			path += fmt.Sprintf("%010d", s.nodes[parent].sequence)
+		}
+		if s.nodes[path] != nil {
+			return nil, errCodeNodeExists
+		}
+
+		node := &fakeNode{data: data, acls: acls, createdAt: 1700000000000}
+		if flags&flagEphemeral != 0 {
+			node.session = session
+		}
+		s.nodes[path] = node
+		return (&encoder{}).string(path).buf, 0
+	case opDelete:
+		path := d.string()
+		if s.nodes[path] == nil {
+			return nil, errCodeNoNode
+		}
+		delete(s.nodes, path)
+	case opGetChildren:
+		path := d.string()
+		if s.nodes[path] == nil {
+			return nil, errCodeNoNode
+		}
+		var children []string
+		for child := range s.nodes {
+			name, found := strings.CutPrefix(child, path+"/")
+			if found && !strings.Contains(name, "/") {
+				children = append(children, name)
+			}
+		}
+		response := (&encoder{}).int32(int32(len(children)))
+		for _, child := range children {
+			response.string(child)
+		}
+		return response.buf, 0
+	case opGetData:
+		node := s.nodes[d.string()]
+		if node == nil {
+			return nil, errCodeNoNode
+		}
+		stat := (&encoder{}).bytes(node.data).int64(0).int64(0).int64(node.createdAt).
+			int64(node.createdAt).int32(0).int32(0).int32(0).int64(node.session).
+			int32(int32(len(node.data))).int32(0).int64(0)
+		return stat.buf, 0
+	case opCloseSession:
+		s.expireLocked(session)
+	}
+	return nil, 0
+}
+
+func (s *fakeServer) hasNode(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nodes[path] != nil
+}
+
+func (s *fakeServer) expire(session int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(session)
+}
+
+func (s *fakeServer) expireLocked(session int64) {
+	for path, node := range s.nodes {
+		if node.session == session {
+			delete(s.nodes, path)
+		}
+	}
+}
+
+func (suite *ZookeeperTestSuite) SetupTest() {
+	var err error
+	suite.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	suite.server = &fakeServer{nodes: map[string]*fakeNode{}, timeout: 60}
+	go suite.server.serve(suite.listener)
+}
+
+func (suite *ZookeeperTestSuite) TearDownTest() {
+	_ = suite.listener.Close()
+}
+
+func (suite *ZookeeperTestSuite) newLocker(path string) *Locker {
+	locker, err := NewLocker(
+		Settings{Servers: []string{"127.0.0.1:1", suite.listener.Addr().String()}, Path: path},
+	)
+	suite.Require().NoError(err)
+	return locker
+}
+
+func (suite *ZookeeperTestSuite) TestItTakesTheLockWithAnEphemeralNode() {
+	locker := suite.newLocker("/migrations/app/lock/")
+	other := suite.newLocker("migrations/app/lock")
+
+	holder, err := locker.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(holder)
+
+	suite.Require().NoError(locker.Lock())
+	suite.Require().NoError(locker.Lock())
+	suite.Assert().True(suite.server.hasNode("/migrations/app/lock/lock-0000000001"))
+
+	suite.Assert().ErrorIs(other.Lock(), execution.ErrLockHeld)
+	holder, err = other.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(locker.owner, holder.Owner)
+	suite.Assert().NotZero(holder.AcquiredAtMs)
+
+	holder, err = locker.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(locker.owner, holder.Owner)
+
+	suite.Eventually(func() bool {
+		suite.server.mu.Lock()
+		defer suite.server.mu.Unlock()
+		return suite.server.pings > 0
+	}, time.Second, 5*time.Millisecond)
+
+	suite.Assert().NoError(locker.Unlock())
+	suite.Assert().NoError(locker.Unlock())
+	suite.Eventually(func() bool {
+		suite.server.mu.Lock()
+		defer suite.server.mu.Unlock()
+		return len(suite.server.nodes) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	suite.Assert().NoError(other.Lock())
+	suite.Assert().True(suite.server.hasNode("/migrations/app/lock/lock-0000000003"))
+	suite.Assert().NoError(other.Unlock())
+}
+
+func (suite *ZookeeperTestSuite) TestItReleasesTheLockWhenTheSessionEnds() {
+	locker := suite.newLocker("/lock")
+	suite.Require().NoError(locker.Lock())
+	suite.Assert().NoError(locker.CheckLock())
+
+	_ = locker.session.netConn.Close()
+	suite.Eventually(func() bool {
+		holder, err := suite.newLocker("/lock").CurrentLockHolder()
+		return err == nil && holder == nil
+	}, time.Second, 5*time.Millisecond)
+
+	// The failed session is reported, so the run stops (see execution.LockKeeper)
+	suite.Eventually(func() bool {
+		return locker.CheckLock() != nil
+	}, time.Second, 5*time.Millisecond)
+	err := locker.CheckLock()
+	suite.Assert().ErrorIs(err, execution.ErrLockLost)
+	suite.Assert().ErrorContains(err, "the zookeeper connection failed")
+
+	suite.Assert().NoError(locker.Unlock())
+	suite.Assert().NoError(locker.CheckLock())
+	suite.Assert().NoError(locker.Lock())
+	suite.Assert().NoError(locker.Unlock())
+}
+
+func (suite *ZookeeperTestSuite) TestItReportsExpiredSessions() {
+	locker := suite.newLocker("/lock")
+	suite.Require().NoError(locker.Lock())
+
+	// The server expires the session, for example, after a long pause of the process
+	suite.server.mu.Lock()
+	suite.server.expired = true
+	suite.server.mu.Unlock()
+
+	suite.Eventually(func() bool {
+		return locker.CheckLock() != nil
+	}, time.Second, 5*time.Millisecond)
+	err := locker.CheckLock()
+	suite.Assert().ErrorIs(err, execution.ErrLockLost)
+	suite.Assert().ErrorIs(err, errSessionExpired)
+	suite.Assert().NoError(locker.Unlock())
+}
+
+func (suite *ZookeeperTestSuite) TestItRestrictsTheLockNodesToTheAuthCredentials() {
+	suite.server.auth = "migrator:secret"
+	settings := Settings{Servers: []string{suite.listener.Addr().String()}, Path: "/app/lock"}
+
+	settings.Auth = "migrator:wrong"
+	locker, err := NewLocker(settings)
+	suite.Require().NoError(err)
+	suite.Assert().ErrorContains(locker.Lock(), "failed to authenticate")
+
+	settings.Auth = "migrator:secret"
+	locker, _ = NewLocker(settings)
+	suite.Require().NoError(locker.Lock())
+
+	// echo -n migrator:secret | openssl dgst -binary -sha1 | openssl base64
+	expected := []acl{
+		{perms: permAll, scheme: "digest", id: "migrator:QE61dQEuAfK/e5FrxxI1YDL9CgU="},
+	}
+	suite.server.mu.Lock()
+	for _, path := range []string{"/app", "/app/lock", "/app/lock/lock-0000000001"} {
+		suite.Assert().Equal(expected, suite.server.nodes[path].acls, path)
+	}
+	suite.server.mu.Unlock()
+	suite.Assert().NoError(locker.Unlock())
+
+	for _, auth := range []string{"migrator", ":secret"} {
+		settings.Auth = auth
+		_, err = NewLocker(settings)
+		suite.Assert().ErrorContains(err, "invalid zookeeper auth", auth)
+	}
+}
+
+func (suite *ZookeeperTestSuite) TestItValidatesTheSettings() {
+	_, err := NewLocker(Settings{Path: "/lock"})
+	suite.Assert().ErrorContains(err, "at least one zookeeper server is required")
+
+	_, err = NewLocker(Settings{Servers: []string{"localhost:2181"}, Path: "/"})
+	suite.Assert().ErrorContains(err, "the zookeeper lock path is required")
+
+	locker, err := NewLocker(Settings{Servers: []string{"127.0.0.1:1"}, Path: "/lock"})
+	suite.Require().NoError(err)
+	suite.Assert().Equal(10*time.Second, locker.settings.SessionTimeout)
+	suite.Assert().ErrorContains(locker.Lock(), "failed to connect to zookeeper")
+}
+
+func (suite *ZookeeperTestSuite) TestItMapsTheErrorCodes() {
+	suite.Assert().NoError(codeErr(0))
+	suite.Assert().True(errors.Is(codeErr(errCodeNoNode), errNoNode))
+	suite.Assert().True(errors.Is(codeErr(errCodeNodeExists), errNodeExists))
+	suite.Assert().True(errors.Is(codeErr(errCodeSessionExpired), errSessionExpired))
+	suite.Assert().ErrorContains(codeErr(-113), "error code -113")
+}
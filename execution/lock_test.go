@@ -0,0 +1,37 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LockTestSuite struct {
+	suite.Suite
+}
+
+func TestLockTestSuite(t *testing.T) {
+	suite.Run(t, new(LockTestSuite))
+}
+
+func (suite *LockTestSuite) TestInMemoryLockerIsExclusive() {
+	locker := &InMemoryLocker{}
+
+	suite.Assert().NoError(locker.Lock())
+	suite.Assert().ErrorIs(locker.Lock(), ErrLockHeld)
+	suite.Assert().NoError(locker.Unlock())
+	suite.Assert().NoError(locker.Lock())
+}
+
+func (suite *LockTestSuite) TestInMemoryLockerReportsTheLockHolder() {
+	locker := &InMemoryLocker{}
+
+	holder, err := locker.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(holder)
+
+	locker.Held, locker.Owner, locker.AcquiredAtMs = true, "ci-1:42", 1717236000000
+	holder, err = locker.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&LockHolder{Owner: "ci-1:42", AcquiredAtMs: 1717236000000}, holder)
+}
@@ -0,0 +1,52 @@
+package execution
+
+import "sync"
+
+// Note A free-text note attached by an operator to the execution of a migration (for example,
+// "backfill verified"), capturing operational context alongside the execution record
+type Note struct {
+	Version uint64 `json:"version"`
+	// ExecutedAtMs The ExecutedAtMs of the annotated execution, so the notes of a rolled back
+	// execution are not attached to the later executions of the same migration
+	ExecutedAtMs uint64 `json:"executedAtMs"`
+	Text         string `json:"text"`
+	Author       string `json:"author,omitempty"`
+	CreatedAtMs  uint64 `json:"createdAtMs"`
+}
+
+// NoteStore Optional Repository capability which stores the notes attached to executions. Notes
+// are only appended, never changed. The bundled repositories (mysql, mongo) implement it,
+// storing the notes next to the executions.
+type NoteStore interface {
+	AddNote(note Note) error
+
+	// LoadNotes Must return the notes of all executions, in the order they were added
+	LoadNotes() ([]Note, error)
+}
+
+// InMemoryNoteStore Implementation of NoteStore. Can be used in unit tests. AddErr can be used
+// to force AddNote to return an error.
+type InMemoryNoteStore struct {
+	mu     sync.Mutex
+	Notes  []Note
+	AddErr error
+}
+
+func (store *InMemoryNoteStore) AddNote(note Note) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.AddErr != nil {
+		return store.AddErr
+	}
+
+	store.Notes = append(store.Notes, note)
+	return nil
+}
+
+func (store *InMemoryNoteStore) LoadNotes() ([]Note, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return append([]Note(nil), store.Notes...), nil
+}
@@ -0,0 +1,36 @@
+package execution
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NoteTestSuite struct {
+	suite.Suite
+}
+
+func TestNoteTestSuite(t *testing.T) {
+	suite.Run(t, new(NoteTestSuite))
+}
+
+func (suite *NoteTestSuite) TestItCanAddAndLoadNotesInMemory() {
+	store := &InMemoryNoteStore{}
+
+	suite.Assert().NoError(store.AddNote(Note{Version: 2, Text: "backfill verified"}))
+	suite.Assert().NoError(store.AddNote(Note{Version: 1, Text: "index rebuilt", Author: "ana"}))
+
+	notes, err := store.LoadNotes()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]Note{
+			{Version: 2, Text: "backfill verified"},
+			{Version: 1, Text: "index rebuilt", Author: "ana"},
+		},
+		notes,
+	)
+
+	store.AddErr = errors.New("add failed")
+	suite.Assert().ErrorIs(store.AddNote(Note{Version: 3}), store.AddErr)
+}
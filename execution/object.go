@@ -0,0 +1,252 @@
+package execution
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// ErrObjectModified is returned (wrapped) by ObjectStore implementations when the object was
+// modified (or created) by another process since it was read
+var ErrObjectModified = errors.New("the object was modified by another process")
+
+// objectUpdateAttempts How many times ObjectRepository reads and writes the object again, when
+// it was modified by another process in the meantime
+const objectUpdateAttempts = 5
+
+// ObjectStore Must store a single object (for example, in S3 or GCS), with optimistic
+// concurrency, based on the object version (for example, the ETag)
+type ObjectStore interface {
+	// Get Must return the object content and its version, or an empty version if the object
+	// does not exist
+	Get() (data []byte, version string, err error)
+
+	// Put Must replace the object only if its current version is ifVersion or, if ifVersion is
+	// empty, create it only if it does not exist. Must return the new object version, or an
+	// error wrapping ErrObjectModified if the precondition is not met.
+	Put(data []byte, ifVersion string) (version string, err error)
+}
+
+// ObjectRepository Repository implementation which stores the executions as a single object, in
+// an object storage (see the s3 repository package), encoded with a Codec. Each change reads
+// the object and replaces it only if it was not modified in the meantime (retrying otherwise),
+// so concurrent processes do not lose changes. Suitable for jobs which have no database for
+// the state (for example, serverless jobs which migrate external systems).
+type ObjectRepository struct {
+	store ObjectStore
+	codec Codec
+}
+
+// NewObjectRepository Builds a new ObjectRepository. If codec is nil, JSONCodec is used.
+func NewObjectRepository(store ObjectStore, codec Codec) *ObjectRepository {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	return &ObjectRepository{store: store, codec: codec}
+}
+
+// Init Creates the object, with no executions, if it does not exist
+func (repo *ObjectRepository) Init() error {
+	_, version, err := repo.store.Get()
+	if err != nil {
+		return fmt.Errorf("failed to read the executions object with error: %w", err)
+	}
+
+	if version != "" {
+		return nil
+	}
+
+	data, err := repo.encode(nil)
+	if err == nil {
+		_, err = repo.store.Put(data, "")
+	}
+
+	if err != nil && !errors.Is(err, ErrObjectModified) {
+		return fmt.Errorf("failed to create the executions object with error: %w", err)
+	}
+	return nil
+}
+
+func (repo *ObjectRepository) LoadExecutions() ([]MigrationExecution, error) {
+	executions, _, err := repo.read()
+	return executions, err
+}
+
+func (repo *ObjectRepository) Save(execution MigrationExecution) error {
+	return repo.update(func(executions []MigrationExecution) ([]MigrationExecution, error) {
+		index := slices.IndexFunc(executions, func(e MigrationExecution) bool {
+			return e.Version == execution.Version
+		})
+
+		if index < 0 {
+			return append(executions, execution), nil
+		}
+		executions[index] = execution
+		return executions, nil
+	})
+}
+
+// SaveIf See ConditionalSaver. The persisted execution matches expected if it has the same
+// timestamps.
+func (repo *ObjectRepository) SaveIf(
+	execution MigrationExecution,
+	expected *MigrationExecution,
+) error {
+	return repo.update(func(executions []MigrationExecution) ([]MigrationExecution, error) {
+		index := slices.IndexFunc(executions, func(e MigrationExecution) bool {
+			return e.Version == execution.Version
+		})
+
+		switch {
+		case index < 0 && expected == nil:
+			return append(executions, execution), nil
+		case index < 0 || expected == nil:
+			return nil, ErrExecutionConflict
+		case executions[index].ExecutedAtMs != expected.ExecutedAtMs ||
+			executions[index].FinishedAtMs != expected.FinishedAtMs:
+			return nil, ErrExecutionConflict
+		}
+
+		executions[index] = execution
+		return executions, nil
+	})
+}
+
+func (repo *ObjectRepository) Remove(execution MigrationExecution) error {
+	return repo.update(func(executions []MigrationExecution) ([]MigrationExecution, error) {
+		return slices.DeleteFunc(executions, func(e MigrationExecution) bool {
+			return e.Version == execution.Version
+		}), nil
+	})
+}
+
+func (repo *ObjectRepository) FindOne(version uint64) (*MigrationExecution, error) {
+	executions, _, err := repo.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, execution := range executions {
+		if execution.Version == version {
+			return &execution, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// read Decodes the executions from the object and returns them with the object version
+func (repo *ObjectRepository) read() ([]MigrationExecution, string, error) {
+	data, version, err := repo.store.Get()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read the executions object with error: %w", err)
+	}
+
+	if version == "" {
+		return nil, "", errors.New("the executions object does not exist, run Init first")
+	}
+
+	executions, err := repo.codec.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode the executions object with error: %w", err)
+	}
+
+	return executions, version, nil
+}
+
+// update Applies the change to the persisted executions and replaces the object, if it was not
+// modified in the meantime. Otherwise, the change is applied again, to the new executions.
+func (repo *ObjectRepository) update(
+	change func(executions []MigrationExecution) ([]MigrationExecution, error),
+) error {
+	for attempt := 1; ; attempt++ {
+		executions, version, err := repo.read()
+		if err != nil {
+			return err
+		}
+
+		if executions, err = change(executions); err != nil {
+			return err
+		}
+
+		data, err := repo.encode(executions)
+		if err != nil {
+			return err
+		}
+
+		_, err = repo.store.Put(data, version)
+		if err == nil {
+			return nil
+		}
+
+		if !errors.Is(err, ErrObjectModified) || attempt == objectUpdateAttempts {
+			return fmt.Errorf("failed to write the executions object with error: %w", err)
+		}
+	}
+}
+
+func (repo *ObjectRepository) encode(executions []MigrationExecution) ([]byte, error) {
+	var data bytes.Buffer
+	if err := repo.codec.Encode(&data, executions); err != nil {
+		return nil, fmt.Errorf("failed to encode the executions object with error: %w", err)
+	}
+	return data.Bytes(), nil
+}
+
+// InMemoryObjectStore Implementation of ObjectStore. Can be used in unit tests. BeforePut, if
+// set, is called before each Put (for example, to simulate a concurrent change) and PutErr
+// forces Put to fail.
+type InMemoryObjectStore struct {
+	BeforePut func(store *InMemoryObjectStore)
+	PutErr    error
+
+	mu      sync.Mutex
+	data    []byte
+	version int
+}
+
+func (store *InMemoryObjectStore) Get() ([]byte, string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return slices.Clone(store.data), store.currentVersion(), nil
+}
+
+func (store *InMemoryObjectStore) Put(data []byte, ifVersion string) (string, error) {
+	if store.BeforePut != nil {
+		store.BeforePut(store)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.PutErr != nil {
+		return "", store.PutErr
+	}
+
+	if ifVersion != store.currentVersion() {
+		return "", ErrObjectModified
+	}
+
+	store.data = slices.Clone(data)
+	store.version++
+	return store.currentVersion(), nil
+}
+
+// Replace Replaces the object, regardless of its version
+func (store *InMemoryObjectStore) Replace(data []byte) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.data = slices.Clone(data)
+	store.version++
+}
+
+// currentVersion The object version, empty if the object was not created yet
+func (store *InMemoryObjectStore) currentVersion() string {
+	if store.version == 0 {
+		return ""
+	}
+	return strconv.Itoa(store.version)
+}
@@ -0,0 +1,102 @@
+package execution
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ObjectRepositoryTestSuite struct {
+	suite.Suite
+}
+
+func TestObjectRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ObjectRepositoryTestSuite))
+}
+
+func (suite *ObjectRepositoryTestSuite) TestItCanSaveFindAndRemoveExecutions() {
+	store := &InMemoryObjectStore{}
+	repo := NewObjectRepository(store, MsgpackCodec)
+
+	_, err := repo.LoadExecutions()
+	suite.Assert().ErrorContains(err, "run Init first")
+	suite.Require().NoError(repo.Init())
+	suite.Require().NoError(repo.Init())
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(executions)
+
+	started := MigrationExecution{Version: 1, ExecutedAtMs: 2}
+	finished := MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	other := MigrationExecution{Version: 4, ExecutedAtMs: 5, FinishedAtMs: 5}
+	suite.Assert().NoError(repo.Save(started))
+	suite.Assert().NoError(repo.Save(finished))
+	suite.Assert().NoError(repo.Save(other))
+
+	found, err := repo.FindOne(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&finished, found)
+
+	suite.Assert().NoError(repo.Remove(finished))
+	found, err = repo.FindOne(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(found)
+
+	executions, err = NewObjectRepository(store, MsgpackCodec).LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{other}, executions)
+}
+
+func (suite *ObjectRepositoryTestSuite) TestItKeepsTheChangesOfConcurrentProcesses() {
+	store := &InMemoryObjectStore{}
+	repo := NewObjectRepository(store, nil)
+	suite.Require().NoError(repo.Init())
+
+	// Another process saves version 1 right before the first write of version 2
+	store.BeforePut = func(store *InMemoryObjectStore) {
+		store.BeforePut = nil
+		suite.Require().NoError(repo.Save(MigrationExecution{Version: 1, FinishedAtMs: 1}))
+	}
+	suite.Assert().NoError(repo.Save(MigrationExecution{Version: 2}))
+
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]MigrationExecution{{Version: 1, FinishedAtMs: 1}, {Version: 2}}, executions,
+	)
+}
+
+func (suite *ObjectRepositoryTestSuite) TestItFailsWhenTheObjectKeepsChanging() {
+	store := &InMemoryObjectStore{}
+	repo := NewObjectRepository(store, nil)
+	suite.Require().NoError(repo.Init())
+	store.BeforePut = func(store *InMemoryObjectStore) {
+		store.Replace([]byte("[]"))
+	}
+
+	err := repo.Save(MigrationExecution{Version: 1})
+
+	suite.Assert().ErrorIs(err, ErrObjectModified)
+	store.BeforePut = nil
+	store.PutErr = errors.New("access denied")
+	suite.Assert().ErrorContains(repo.Remove(MigrationExecution{Version: 1}), "access denied")
+}
+
+func (suite *ObjectRepositoryTestSuite) TestItSavesExecutionsConditionally() {
+	repo := NewObjectRepository(&InMemoryObjectStore{}, nil)
+	suite.Require().NoError(repo.Init())
+	started := MigrationExecution{Version: 1, ExecutedAtMs: 2}
+	finished := MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+
+	suite.Assert().NoError(repo.SaveIf(started, nil))
+	suite.Assert().ErrorIs(repo.SaveIf(started, nil), ErrExecutionConflict)
+	suite.Assert().ErrorIs(repo.SaveIf(finished, &finished), ErrExecutionConflict)
+	suite.Assert().ErrorIs(
+		repo.SaveIf(MigrationExecution{Version: 2}, &started), ErrExecutionConflict,
+	)
+	suite.Assert().NoError(repo.SaveIf(finished, &started))
+
+	found, _ := repo.FindOne(1)
+	suite.Assert().Equal(&finished, found)
+}
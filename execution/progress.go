@@ -0,0 +1,56 @@
+package execution
+
+import "sync"
+
+// Progress The persisted progress of a long-running, resumable task (for example, a chunked data
+// backfill, see the online/backfill package). Token identifies where the task stopped (for
+// example, the last processed key) and is opaque for the store.
+type Progress struct {
+	Key   string
+	Token string
+	Done  bool
+}
+
+// ProgressStore Optional Repository capability which allows persisting the progress of
+// resumable tasks executed by migrations, so they can continue after a crash instead of
+// starting over
+type ProgressStore interface {
+	// LoadProgress Must return the progress saved for the key, or nil if there is none
+	LoadProgress(key string) (*Progress, error)
+
+	// SaveProgress Must persist the progress, replacing the progress saved for the same key
+	SaveProgress(progress Progress) error
+}
+
+// InMemoryProgressStore Implementation of ProgressStore. Can be used in unit tests.
+// SaveErr can be used to force SaveProgress to return an error.
+type InMemoryProgressStore struct {
+	mu       sync.Mutex
+	progress map[string]Progress
+	SaveErr  error
+}
+
+func (store *InMemoryProgressStore) LoadProgress(key string) (*Progress, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if progress, found := store.progress[key]; found {
+		return &progress, nil
+	}
+	return nil, nil
+}
+
+func (store *InMemoryProgressStore) SaveProgress(progress Progress) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.SaveErr != nil {
+		return store.SaveErr
+	}
+
+	if store.progress == nil {
+		store.progress = make(map[string]Progress)
+	}
+	store.progress[progress.Key] = progress
+	return nil
+}
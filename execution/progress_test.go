@@ -0,0 +1,36 @@
+package execution
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProgressTestSuite struct {
+	suite.Suite
+}
+
+func TestProgressTestSuite(t *testing.T) {
+	suite.Run(t, new(ProgressTestSuite))
+}
+
+func (suite *ProgressTestSuite) TestItCanSaveAndLoadProgressInMemory() {
+	store := &InMemoryProgressStore{}
+
+	progress, err := store.LoadProgress("users_backfill")
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(progress)
+
+	suite.Assert().NoError(store.SaveProgress(Progress{Key: "users_backfill", Token: "10"}))
+	suite.Assert().NoError(
+		store.SaveProgress(Progress{Key: "users_backfill", Token: "20", Done: true}),
+	)
+
+	progress, err = store.LoadProgress("users_backfill")
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&Progress{Key: "users_backfill", Token: "20", Done: true}, progress)
+
+	store.SaveErr = errors.New("save failed")
+	suite.Assert().ErrorIs(store.SaveProgress(Progress{Key: "other"}), store.SaveErr)
+}
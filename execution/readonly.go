@@ -0,0 +1,35 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly is returned (wrapped) when a change is attempted through a read-only repository or
+// handler
+var ErrReadOnly = errors.New("repository is read-only")
+
+// ReadOnlyRepository Repository wrapper which allows reads, but rejects all changes with
+// ErrReadOnly. Init does nothing (the executions table must exist), so it can be used with
+// reduced-privilege credentials, for example, for informational commands (status, plan,
+// validate) in production.
+type ReadOnlyRepository struct {
+	Repository
+}
+
+// NewReadOnlyRepository Wraps the repository, making it read-only
+func NewReadOnlyRepository(repository Repository) *ReadOnlyRepository {
+	return &ReadOnlyRepository{repository}
+}
+
+func (repo *ReadOnlyRepository) Init() error {
+	return nil
+}
+
+func (repo *ReadOnlyRepository) Save(execution MigrationExecution) error {
+	return fmt.Errorf("failed to save execution %d, %w", execution.Version, ErrReadOnly)
+}
+
+func (repo *ReadOnlyRepository) Remove(execution MigrationExecution) error {
+	return fmt.Errorf("failed to remove execution %d, %w", execution.Version, ErrReadOnly)
+}
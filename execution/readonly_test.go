@@ -0,0 +1,36 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReadOnlyTestSuite struct {
+	suite.Suite
+}
+
+func TestReadOnlyTestSuite(t *testing.T) {
+	suite.Run(t, new(ReadOnlyTestSuite))
+}
+
+func (suite *ReadOnlyTestSuite) TestItAllowsReadsButRejectsChanges() {
+	exec := MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	inner := &InMemoryRepository{PersistedExecutions: []MigrationExecution{exec}}
+	repo := NewReadOnlyRepository(inner)
+
+	suite.Assert().NoError(repo.Init())
+	executions, err := repo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]MigrationExecution{exec}, executions)
+	found, err := repo.FindOne(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&exec, found)
+
+	suite.Assert().ErrorIs(repo.Save(MigrationExecution{Version: 2}), ErrReadOnly)
+	suite.Assert().ErrorIs(repo.Remove(exec), ErrReadOnly)
+	suite.Assert().Equal([]MigrationExecution{exec}, inner.PersistedExecutions)
+
+	_, isConditionalSaver := interface{}(repo).(ConditionalSaver)
+	suite.Assert().False(isConditionalSaver)
+}
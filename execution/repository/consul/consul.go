@@ -0,0 +1,426 @@
+// Package consul includes the execution.Repository implementation for the Consul KV store, with
+// a cluster-wide migrations lock based on Consul sessions (see execution.Locker). Importing it
+// registers the consul scheme for repository.FromDSN.
+package consul
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// DefaultAddress The address of the local Consul agent
+const DefaultAddress = "http://127.0.0.1:8500"
+
+// Settings The Consul agent, the KV prefix and the lock settings
+type Settings struct {
+	// Address The base URL of the Consul agent HTTP API. Defaults to DefaultAddress
+	Address string
+	// Token The ACL token, if ACLs are enabled
+	Token string
+	// Datacenter Defaults to the datacenter of the agent
+	Datacenter string
+
+	// Prefix The KV prefix, under which the executions ({Prefix}/executions) and the lock
+	// ({Prefix}/lock) keys are stored
+	Prefix string
+
+	// LockTTL The TTL of the lock session, renewed while the lock is held. If the process dies,
+	// the lock is released after the TTL. Defaults to 15s (Consul allows 10s to 24h)
+	LockTTL time.Duration
+
+	// RequestTimeout Limits each request. Zero means the requests are limited only by the
+	// handler context
+	RequestTimeout time.Duration
+}
+
+// Handler execution.Repository implementation which stores the executions as a single Consul KV
+// value (see execution.ObjectRepository), written with check-and-set, so concurrent processes
+// do not lose changes. Consul limits values to 512KB, so compact codecs (see
+// execution.MsgpackCodec) are recommended for very large histories. Also implements
+// execution.Locker, execution.LockInspector and execution.LockKeeper, with a Consul session lock.
+type Handler struct {
+	*execution.ObjectRepository
+	settings Settings
+	ctx      context.Context
+	client   *http.Client
+	owner    string
+
+	mu        sync.Mutex
+	session   string
+	stopRenew chan struct{}
+
+	// lockErr Set when the held lock was lost (see CheckLock)
+	lockErr error
+}
+
+// NewHandler Builds a new Handler, which encodes the executions with the codec
+// (execution.JSONCodec if nil). If client is nil, http.DefaultClient is used.
+func NewHandler(
+	settings Settings,
+	ctx context.Context,
+	client *http.Client,
+	codec execution.Codec,
+) (*Handler, error) {
+	if settings.Prefix == "" {
+		return nil, errors.New("the consul KV prefix is required")
+	}
+
+	if settings.Address == "" {
+		settings.Address = DefaultAddress
+	}
+
+	if settings.LockTTL == 0 {
+		settings.LockTTL = 15 * time.Second
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	h := &Handler{settings: settings, ctx: ctx, client: client, owner: newLockOwner()}
+	h.ObjectRepository = execution.NewObjectRepository(&kvStore{h}, codec)
+	return h, nil
+}
+
+// newLockOwner Identifies the process taking the lock (host:pid:random)
+func newLockOwner() string {
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// key The full KV key, under the prefix
+func (h *Handler) key(name string) string {
+	return strings.Trim(h.settings.Prefix, "/") + "/" + name
+}
+
+// kvEntry A KV entry, as returned by the KV and transaction endpoints
+type kvEntry struct {
+	Key         string
+	Value       []byte
+	Session     string
+	ModifyIndex uint64
+}
+
+// getKey Reads the KV entry, returning nil if it does not exist
+func (h *Handler) getKey(key string) (*kvEntry, error) {
+	var entries []kvEntry
+	err := h.do(http.MethodGet, "/v1/kv/"+key, nil, nil, func(response *http.Response) error {
+		if response.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if response.StatusCode != http.StatusOK {
+			return responseErr(response)
+		}
+		return json.NewDecoder(response.Body).Decode(&entries)
+	})
+
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// do Sends the request to the Consul HTTP API and handles the response, within the request
+// timeout
+func (h *Handler) do(
+	method string,
+	path string,
+	query url.Values,
+	body any,
+	handle func(response *http.Response) error,
+) error {
+	ctx := h.ctx
+	if h.settings.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.settings.RequestTimeout)
+		defer cancel()
+	}
+
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	if query == nil {
+		query = url.Values{}
+	}
+	if h.settings.Datacenter != "" {
+		query.Set("dc", h.settings.Datacenter)
+	}
+
+	requestURL := strings.TrimSuffix(h.settings.Address, "/") + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+
+	if h.settings.Token != "" {
+		request.Header.Set("X-Consul-Token", h.settings.Token)
+	}
+
+	response, err := h.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	return handle(response)
+}
+
+// decodeBool Decodes the true or false response of the KV writes
+func decodeBool(response *http.Response, result *bool) error {
+	if response.StatusCode != http.StatusOK {
+		return responseErr(response)
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// responseErr The error for an unexpected response, including the error message returned by
+// Consul
+func responseErr(response *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf(
+		"unexpected consul response %s: %s", response.Status, strings.TrimSpace(string(message)),
+	)
+}
+
+// kvStore execution.ObjectStore implementation which stores the executions in the
+// {Prefix}/executions key. The object version is the key's ModifyIndex.
+type kvStore struct {
+	handler *Handler
+}
+
+func (s *kvStore) Get() ([]byte, string, error) {
+	entry, err := s.handler.getKey(s.handler.key("executions"))
+	if err != nil || entry == nil {
+		return nil, "", err
+	}
+	return entry.Value, strconv.FormatUint(entry.ModifyIndex, 10), nil
+}
+
+// Put Writes the key with a check-and-set operation, in a transaction, which also returns the
+// new ModifyIndex. An empty version is the 0 index, which only creates the key.
+func (s *kvStore) Put(data []byte, ifVersion string) (string, error) {
+	index := uint64(0)
+	if ifVersion != "" {
+		var err error
+		if index, err = strconv.ParseUint(ifVersion, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid executions key index %q: %w", ifVersion, err)
+		}
+	}
+
+	operations := []map[string]any{{
+		"KV": map[string]any{
+			"Verb": "cas", "Key": s.handler.key("executions"), "Value": data, "Index": index,
+		},
+	}}
+
+	var result struct {
+		Results []struct{ KV kvEntry }
+	}
+	err := s.handler.do(
+		http.MethodPut, "/v1/txn", nil, operations,
+		func(response *http.Response) error {
+			switch response.StatusCode {
+			case http.StatusOK:
+				return json.NewDecoder(response.Body).Decode(&result)
+			case http.StatusConflict:
+				return fmt.Errorf("%w: %w", execution.ErrObjectModified, responseErr(response))
+			}
+			return responseErr(response)
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+	if len(result.Results) == 0 {
+		return "", errors.New("the consul transaction returned no results")
+	}
+	return strconv.FormatUint(result.Results[0].KV.ModifyIndex, 10), nil
+}
+
+// lockValue The value of the lock key, identifying the lock holder
+type lockValue struct {
+	Owner        string `json:"owner"`
+	AcquiredAtMs uint64 `json:"acquiredAtMs"`
+}
+
+// Lock See execution.Locker. Creates a session (with the LockTTL, renewed while the lock is
+// held) and acquires the {Prefix}/lock key with it, failing with execution.ErrLockHeld if the
+// key is held by another session. The key is deleted when the session ends.
+func (h *Handler) Lock() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.session != "" {
+		return nil
+	}
+
+	var session struct{ ID string }
+	err := h.do(
+		http.MethodPut, "/v1/session/create", nil,
+		map[string]string{
+			"Name":      "go-migrations lock " + h.owner,
+			"TTL":       h.settings.LockTTL.String(),
+			"Behavior":  "delete",
+			"LockDelay": "0s",
+		},
+		func(response *http.Response) error {
+			if response.StatusCode != http.StatusOK {
+				return responseErr(response)
+			}
+			return json.NewDecoder(response.Body).Decode(&session)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create the consul lock session with error: %w", err)
+	}
+
+	var acquired bool
+	err = h.do(
+		http.MethodPut, "/v1/kv/"+h.key("lock"), url.Values{"acquire": {session.ID}},
+		lockValue{Owner: h.owner, AcquiredAtMs: uint64(time.Now().UnixMilli())},
+		func(response *http.Response) error { return decodeBool(response, &acquired) },
+	)
+
+	if err != nil || !acquired {
+		_ = h.destroySession(session.ID)
+		if err != nil {
+			return fmt.Errorf("failed to acquire the consul lock with error: %w", err)
+		}
+		return fmt.Errorf("failed to acquire the consul lock: %w", execution.ErrLockHeld)
+	}
+
+	h.session = session.ID
+	h.lockErr = nil
+	h.stopRenew = make(chan struct{})
+	go h.renewSession(session.ID, h.stopRenew)
+	return nil
+}
+
+// renewSession Renews the session, at half of the LockTTL, until stopped. The lock is lost (see
+// CheckLock) if the session is gone (for example, Consul invalidated it) or if it could not be
+// renewed for a whole LockTTL, since it expired in the meantime.
+func (h *Handler) renewSession(session string, stop chan struct{}) {
+	ticker := time.NewTicker(h.settings.LockTTL / 2)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+			gone := false
+			err := h.do(
+				http.MethodPut, "/v1/session/renew/"+session, nil, nil,
+				func(response *http.Response) error {
+					gone = response.StatusCode == http.StatusNotFound
+					if response.StatusCode != http.StatusOK {
+						return responseErr(response)
+					}
+					return nil
+				},
+			)
+
+			if err == nil {
+				renewedAt = time.Now()
+			} else if gone || time.Since(renewedAt) >= h.settings.LockTTL {
+				h.loseLock(session, err)
+				return
+			}
+		}
+	}
+}
+
+// loseLock Records that the lock held with the session was lost
+func (h *Handler) loseLock(session string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.session == session {
+		h.lockErr = fmt.Errorf(
+			"%w, failed to renew the consul lock session with error: %w", execution.ErrLockLost, err,
+		)
+	}
+}
+
+// CheckLock See execution.LockKeeper
+func (h *Handler) CheckLock() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.lockErr
+}
+
+// Unlock See execution.Locker. Destroys the lock session, which deletes the lock key
+func (h *Handler) Unlock() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.session == "" {
+		return nil
+	}
+
+	close(h.stopRenew)
+	err := h.destroySession(h.session)
+	h.session = ""
+	if h.lockErr != nil {
+		// The session is already gone
+		h.lockErr = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release the consul lock with error: %w", err)
+	}
+	return nil
+}
+
+func (h *Handler) destroySession(session string) error {
+	var destroyed bool
+	return h.do(
+		http.MethodPut, "/v1/session/destroy/"+session, nil, nil,
+		func(response *http.Response) error { return decodeBool(response, &destroyed) },
+	)
+}
+
+// CurrentLockHolder See execution.LockInspector
+func (h *Handler) CurrentLockHolder() (*execution.LockHolder, error) {
+	entry, err := h.getKey(h.key("lock"))
+	if err != nil || entry == nil || entry.Session == "" {
+		return nil, err
+	}
+
+	var holder lockValue
+	if err = json.Unmarshal(entry.Value, &holder); err != nil {
+		return nil, fmt.Errorf("invalid consul lock value with error: %w", err)
+	}
+	return &execution.LockHolder{Owner: holder.Owner, AcquiredAtMs: holder.AcquiredAtMs}, nil
+}
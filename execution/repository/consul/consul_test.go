@@ -0,0 +1,233 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConsulTestSuite struct {
+	suite.Suite
+	agent  *fakeAgent
+	server *httptest.Server
+}
+
+func TestConsulTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsulTestSuite))
+}
+
+// fakeAgent Serves the subset of the Consul HTTP API used by the handler
+type fakeAgent struct {
+	mu       sync.Mutex
+	index    uint64
+	entries  map[string]kvEntry
+	sessions map[string]bool
+	renewals int
+	tokens   []string
+}
+
+func (a *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tokens = append(a.tokens, r.Header.Get("X-Consul-Token"))
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/v1/kv/"):
+		entry, found := a.entries[strings.TrimPrefix(path, "/v1/kv/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]kvEntry{entry})
+	case path == "/v1/txn":
+		a.txn(w, r)
+	case strings.HasPrefix(path, "/v1/kv/"):
+		a.acquire(w, r, strings.TrimPrefix(path, "/v1/kv/"))
+	case path == "/v1/session/create":
+		a.index++
+		id := "session-" + strconv.FormatUint(a.index, 10)
+		a.sessions[id] = true
+		_ = json.NewEncoder(w).Encode(map[string]string{"ID": id})
+	case strings.HasPrefix(path, "/v1/session/renew/"):
+		if !a.sessions[strings.TrimPrefix(path, "/v1/session/renew/")] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		a.renewals++
+	case strings.HasPrefix(path, "/v1/session/destroy/"):
+		id := strings.TrimPrefix(path, "/v1/session/destroy/")
+		delete(a.sessions, id)
+		for key, entry := range a.entries {
+			if entry.Session == id {
+				delete(a.entries, key)
+			}
+		}
+		_, _ = w.Write([]byte("true"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (a *fakeAgent) txn(w http.ResponseWriter, r *http.Request) {
+	var operations []struct {
+		KV struct {
+			Key   string
+			Value []byte
+			Index uint64
+		}
+	}
+	_ = json.NewDecoder(r.Body).Decode(&operations)
+	op := operations[0].KV
+
+	if a.entries[op.Key].ModifyIndex != op.Index {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"Errors":[{"What":"index is stale"}]}`))
+		return
+	}
+
+	a.index++
+	a.entries[op.Key] = kvEntry{Key: op.Key, Value: op.Value, ModifyIndex: a.index}
+	_ = json.NewEncoder(w).Encode(
+		map[string]any{"Results": []map[string]kvEntry{{"KV": {ModifyIndex: a.index}}}},
+	)
+}
+
+func (a *fakeAgent) acquire(w http.ResponseWriter, r *http.Request, key string) {
+	session := r.URL.Query().Get("acquire")
+	if !a.sessions[session] || a.entries[key].Session != "" {
+		_, _ = w.Write([]byte("false"))
+		return
+	}
+
+	var value json.RawMessage
+	_ = json.NewDecoder(r.Body).Decode(&value)
+	a.index++
+	a.entries[key] = kvEntry{Key: key, Value: value, Session: session, ModifyIndex: a.index}
+	_, _ = w.Write([]byte("true"))
+}
+
+func (suite *ConsulTestSuite) SetupTest() {
+	suite.agent = &fakeAgent{entries: map[string]kvEntry{}, sessions: map[string]bool{}}
+	suite.server = httptest.NewServer(suite.agent)
+}
+
+func (suite *ConsulTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *ConsulTestSuite) newHandler(settings Settings) *Handler {
+	settings.Address = suite.server.URL
+	handler, err := NewHandler(settings, context.Background(), nil, nil)
+	suite.Require().NoError(err)
+	return handler
+}
+
+func (suite *ConsulTestSuite) TestItStoresTheExecutionsInTheKVStore() {
+	handler := suite.newHandler(Settings{Prefix: "migrations/app", Token: "acl-token"})
+	suite.Require().NoError(handler.Init())
+	suite.Require().NoError(handler.Init())
+
+	finished := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	suite.Assert().NoError(handler.Save(finished))
+	suite.Assert().NoError(handler.SaveIf(execution.MigrationExecution{Version: 2}, nil))
+	suite.Assert().ErrorIs(
+		handler.SaveIf(execution.MigrationExecution{Version: 2}, nil),
+		execution.ErrExecutionConflict,
+	)
+	suite.Assert().NoError(handler.Remove(execution.MigrationExecution{Version: 2}))
+
+	executions, err := handler.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]execution.MigrationExecution{finished}, executions)
+	suite.Assert().Contains(suite.agent.entries, "migrations/app/executions")
+	suite.Assert().Equal("acl-token", suite.agent.tokens[0])
+}
+
+func (suite *ConsulTestSuite) TestItDetectsKeysModifiedByOtherProcesses() {
+	handler := suite.newHandler(Settings{Prefix: "app"})
+	store := &kvStore{handler}
+
+	version, err := store.Put([]byte("[]"), "")
+	suite.Require().NoError(err)
+	_, err = store.Put([]byte("[]"), "")
+	suite.Assert().ErrorIs(err, execution.ErrObjectModified)
+
+	newVersion, err := store.Put([]byte("[1]"), version)
+	suite.Assert().NoError(err)
+	data, currentVersion, err := store.Get()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]byte("[1]"), data)
+	suite.Assert().Equal(newVersion, currentVersion)
+
+	_, err = store.Put([]byte("[2]"), version)
+	suite.Assert().ErrorIs(err, execution.ErrObjectModified)
+}
+
+func (suite *ConsulTestSuite) TestItTakesTheLockWithASession() {
+	handler := suite.newHandler(Settings{Prefix: "app", LockTTL: 20 * time.Millisecond})
+	other := suite.newHandler(Settings{Prefix: "app"})
+
+	holder, err := handler.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(holder)
+
+	suite.Require().NoError(handler.Lock())
+	suite.Assert().ErrorIs(other.Lock(), execution.ErrLockHeld)
+	holder, err = other.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(handler.owner, holder.Owner)
+	suite.Assert().NotZero(holder.AcquiredAtMs)
+
+	suite.Eventually(func() bool {
+		suite.agent.mu.Lock()
+		defer suite.agent.mu.Unlock()
+		return suite.agent.renewals > 0
+	}, time.Second, 5*time.Millisecond)
+
+	suite.Assert().NoError(handler.Unlock())
+	suite.Assert().NoError(handler.Unlock())
+	suite.Assert().Len(suite.agent.sessions, 0)
+	suite.Assert().NoError(other.Lock())
+	suite.Assert().NoError(other.Unlock())
+}
+
+func (suite *ConsulTestSuite) TestItReportsTheLostLock() {
+	handler := suite.newHandler(Settings{Prefix: "app", LockTTL: 20 * time.Millisecond})
+	suite.Require().NoError(handler.Lock())
+	suite.Assert().NoError(handler.CheckLock())
+
+	// The session is invalidated by Consul
+	suite.agent.mu.Lock()
+	suite.agent.sessions = map[string]bool{}
+	suite.agent.mu.Unlock()
+
+	suite.Eventually(func() bool {
+		return errors.Is(handler.CheckLock(), execution.ErrLockLost)
+	}, time.Second, 5*time.Millisecond)
+	suite.Assert().ErrorContains(handler.CheckLock(), "404 Not Found")
+
+	suite.Assert().NoError(handler.Unlock())
+	suite.Assert().NoError(handler.CheckLock())
+}
+
+func (suite *ConsulTestSuite) TestItFailsForUnexpectedResponses() {
+	handler := suite.newHandler(Settings{Prefix: "app"})
+	handler.settings.Address = suite.server.URL + "/missing"
+
+	suite.Assert().ErrorContains(handler.Init(), "404 Not Found")
+	suite.Assert().ErrorContains(handler.Lock(), "failed to create the consul lock session")
+
+	_, err := NewHandler(Settings{}, context.Background(), nil, nil)
+	suite.Assert().ErrorContains(err, "the consul KV prefix is required")
+}
@@ -0,0 +1,94 @@
+package consul
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/execution/repository"
+)
+
+// getenv Used to read the default ACL token
+var getenv = os.Getenv
+
+func init() {
+	repository.RegisterScheme("consul", openConsul)
+}
+
+// openConsul Builds a Handler for the agent and the KV prefix from the DSN
+func openConsul(
+	dsn *url.URL,
+	table string,
+	opts repository.DSNOptions,
+) (execution.Repository, error) {
+	settings, codec, err := settingsFromDSN(dsn, table, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewHandler(settings, opts.Ctx, nil, codec)
+}
+
+// settingsFromDSN Converts a consul://token@host:8500/prefix?dc=&tls=&lockTtl=&codec= DSN to the
+// handler settings and the codec (see execution.CodecByName). The prefix defaults to the table
+// name and is prefixed with the scope, if any. The token defaults to the CONSUL_HTTP_TOKEN
+// environment variable and tls=true uses https.
+func settingsFromDSN(
+	dsn *url.URL,
+	table string,
+	opts repository.DSNOptions,
+) (Settings, execution.Codec, error) {
+	if opts.Sequence {
+		return Settings{}, nil, errors.New("the consul repository does not support sequences")
+	}
+
+	query := dsn.Query()
+	scheme := "http"
+	if query.Get("tls") == "true" {
+		scheme = "https"
+	}
+
+	settings := Settings{
+		Address:        scheme + "://" + dsn.Host,
+		Token:          dsn.User.Username(),
+		Datacenter:     query.Get("dc"),
+		Prefix:         strings.Trim(dsn.Path, "/"),
+		RequestTimeout: opts.QueryTimeout,
+	}
+
+	if dsn.Host == "" {
+		settings.Address = DefaultAddress
+	}
+
+	if settings.Token == "" {
+		settings.Token = getenv("CONSUL_HTTP_TOKEN")
+	}
+
+	if settings.Prefix == "" {
+		settings.Prefix = table
+	}
+
+	if opts.Scope != "" {
+		settings.Prefix = opts.Scope + "/" + settings.Prefix
+	}
+
+	if lockTTL := query.Get("lockTtl"); lockTTL != "" {
+		var err error
+		if settings.LockTTL, err = time.ParseDuration(lockTTL); err != nil {
+			return Settings{}, nil, fmt.Errorf("invalid consul lockTtl %q: %w", lockTTL, err)
+		}
+	}
+
+	codec := execution.JSONCodec
+	if name := query.Get("codec"); name != "" {
+		var err error
+		if codec, err = execution.CodecByName(name); err != nil {
+			return Settings{}, nil, err
+		}
+	}
+
+	return settings, codec, nil
+}
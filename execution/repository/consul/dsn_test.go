@@ -0,0 +1,60 @@
+package consul
+
+import (
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/execution/repository"
+	"github.com/stretchr/testify/suite"
+)
+
+type DSNTestSuite struct {
+	suite.Suite
+}
+
+func TestDSNTestSuite(t *testing.T) {
+	suite.Run(t, new(DSNTestSuite))
+}
+
+func (suite *DSNTestSuite) TestItRegistersItsScheme() {
+	repo, err := repository.FromDSN("consul://localhost:8500/app", "execs", repository.DSNOptions{})
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("app", repo.(*Handler).settings.Prefix)
+
+	_, err = repository.FromDSN("consul://localhost", "execs", repository.DSNOptions{Sequence: true})
+	suite.Assert().ErrorContains(err, "does not support sequences")
+}
+
+func (suite *DSNTestSuite) TestItBuildsTheSettingsFromTheDSN() {
+	getenv = func(name string) string { return map[string]string{"CONSUL_HTTP_TOKEN": "env"}[name] }
+	defer func() { getenv = os.Getenv }()
+
+	dsn, _ := url.Parse("consul://consul.service:8501?tls=true&dc=eu1&lockTtl=30s&codec=msgpack")
+	settings, codec, err := settingsFromDSN(
+		dsn, "execs", repository.DSNOptions{Scope: "billing", QueryTimeout: time.Second},
+	)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(execution.MsgpackCodec, codec)
+	suite.Assert().Equal(
+		Settings{
+			Address: "https://consul.service:8501", Token: "env", Datacenter: "eu1",
+			Prefix: "billing/execs", LockTTL: 30 * time.Second, RequestTimeout: time.Second,
+		},
+		settings,
+	)
+
+	dsn, _ = url.Parse("consul://acl-token@/migrations/app/")
+	settings, codec, err = settingsFromDSN(dsn, "execs", repository.DSNOptions{})
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(execution.JSONCodec, codec)
+	suite.Assert().Equal(DefaultAddress, settings.Address)
+	suite.Assert().Equal("acl-token", settings.Token)
+	suite.Assert().Equal("migrations/app", settings.Prefix)
+
+	dsn, _ = url.Parse("consul://localhost?lockTtl=soon")
+	_, _, err = settingsFromDSN(dsn, "execs", repository.DSNOptions{})
+	suite.Assert().ErrorContains(err, "invalid consul lockTtl")
+}
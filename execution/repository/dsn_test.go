@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+type DSNTestSuite struct {
+	suite.Suite
+}
+
+func TestDSNTestSuite(t *testing.T) {
+	suite.Run(t, new(DSNTestSuite))
+}
+
+func (suite *DSNTestSuite) TestItPicksTheRepositoryByScheme() {
+	repo := &execution.InMemoryRepository{}
+	RegisterScheme(
+		"memory", func(dsn *url.URL, table string, opts DSNOptions) (execution.Repository, error) {
+			suite.Assert().Equal("executions", table)
+			suite.Assert().NotNil(opts.Ctx)
+			return repo, nil
+		},
+	)
+
+	built, err := FromDSN("memory://local", "executions", DSNOptions{})
+
+	suite.Assert().NoError(err)
+	suite.Assert().Same(repo, built)
+	suite.Assert().Contains(Schemes(), "memory")
+}
+
+func (suite *DSNTestSuite) TestItFailsForUnsupportedDsns() {
+	scenarios := map[string]string{
+		"postgres://localhost/app":        "unsupported dsn scheme postgres, supported schemes:",
+		"mysql://localhost/app":           "import github.com/rsgcata/go-migrations/execution",
+		"sqlserver://localhost?database=": "unsupported dsn scheme sqlserver",
+		"localhost:3306":                  "the dsn must be a URL",
+		"user:pass@tcp(localhost)/app":    "the dsn must be a URL",
+	}
+
+	for dsn, expected := range scenarios {
+		suite.Run(dsn, func() {
+			_, err := FromDSN(dsn, "executions", DSNOptions{})
+			suite.Assert().ErrorContains(err, expected)
+		})
+	}
+}
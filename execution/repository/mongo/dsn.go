@@ -0,0 +1,34 @@
+package mongo
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/execution/repository"
+)
+
+func init() {
+	repository.RegisterScheme("mongodb", openMongo)
+	repository.RegisterScheme("mongodb+srv", openMongo)
+}
+
+// openMongo Builds a Handler which stores the executions in the database from the DSN path
+func openMongo(
+	dsn *url.URL,
+	table string,
+	opts repository.DSNOptions,
+) (execution.Repository, error) {
+	dbName := strings.TrimPrefix(dsn.Path, "/")
+	if dbName == "" {
+		return nil, errors.New("the mongo dsn must include the database, mongodb://host/db")
+	}
+
+	options := []Option{WithQueryTimeout(opts.QueryTimeout), WithScope(opts.Scope)}
+	if opts.Sequence {
+		options = append(options, WithSequence())
+	}
+
+	return NewHandler(dsn.String(), dbName, table, opts.Ctx, nil, options...)
+}
@@ -0,0 +1,28 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution/repository"
+	"github.com/stretchr/testify/suite"
+)
+
+type DSNTestSuite struct {
+	suite.Suite
+}
+
+func TestDSNTestSuite(t *testing.T) {
+	suite.Run(t, new(DSNTestSuite))
+}
+
+func (suite *DSNTestSuite) TestItRegistersItsSchemes() {
+	repo, err := repository.FromDSN(
+		"mongodb://localhost:27017/app", "execs", repository.DSNOptions{},
+	)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("app", repo.(*Handler).databaseName)
+	suite.Assert().Equal("execs", repo.(*Handler).collectionName)
+
+	_, err = repository.FromDSN("mongodb://localhost:27017", "execs", repository.DSNOptions{})
+	suite.Assert().ErrorContains(err, "must include the database")
+}
@@ -0,0 +1,667 @@
+// Package mongo includes the execution.Repository implementation for MongoDb. Importing it
+// registers the mongodb and mongodb+srv schemes for repository.FromDSN.
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// executionID The _id of an execution document: the version or, for the executions of a scope
+// (see WithScope), a {scope, version} document
+type executionID struct {
+	Scope   string
+	Version uint64
+}
+
+type bsonScopedID struct {
+	Scope   string `bson:"scope"`
+	Version uint64 `bson:"version"`
+}
+
+func (id executionID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if id.Scope == "" {
+		return bson.MarshalValue(id.Version)
+	}
+	return bson.MarshalValue(bsonScopedID(id))
+}
+
+func (id *executionID) UnmarshalBSONValue(valueType bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: valueType, Value: data}
+	if valueType != bson.TypeEmbeddedDocument {
+		id.Scope = ""
+		return value.Unmarshal(&id.Version)
+	}
+
+	var scopedID bsonScopedID
+	if err := value.Unmarshal(&scopedID); err != nil {
+		return err
+	}
+	*id = executionID(scopedID)
+	return nil
+}
+
+type bsonExecution struct {
+	ID           executionID           `bson:"_id"`
+	ExecutedAtMs uint64                `bson:"executedAtMs"`
+	FinishedAtMs uint64                `bson:"finishedAtMs"`
+	Run          execution.RunMetadata `bson:"run"`
+	SkipReason   string                `bson:"skipReason,omitempty"`
+	Sequence     uint64                `bson:"sequence,omitempty"`
+}
+
+func toBsonExecution(scope string, exec execution.MigrationExecution) bsonExecution {
+	return bsonExecution{
+		ID:           executionID{scope, exec.Version},
+		ExecutedAtMs: exec.ExecutedAtMs,
+		FinishedAtMs: exec.FinishedAtMs,
+		Run:          exec.Run,
+		SkipReason:   exec.SkipReason,
+	}
+}
+
+func toMigrationExecution(exec bsonExecution) execution.MigrationExecution {
+	return execution.MigrationExecution{
+		Version:      exec.ID.Version,
+		ExecutedAtMs: exec.ExecutedAtMs,
+		FinishedAtMs: exec.FinishedAtMs,
+		Run:          exec.Run,
+		SkipReason:   exec.SkipReason,
+		Sequence:     exec.Sequence,
+	}
+}
+
+func newMongoClient(dsn string, ctx context.Context) (*mongodriver.Client, error) {
+	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
+	opts := options.Client().ApplyURI(dsn).SetServerAPIOptions(serverAPI)
+	opts.SetMaxPoolSize(1)
+	return mongodriver.Connect(ctx, opts)
+}
+
+// Handler Repository implementation for MongoDb integration
+type Handler struct {
+	client         *mongodriver.Client
+	databaseName   string
+	collectionName string
+	ctx            context.Context
+
+	// queryTimeout Limits each repository operation. Zero means the operations are limited
+	// only by the handler context
+	queryTimeout time.Duration
+
+	// scope The logical database whose executions are stored and read (see WithScope)
+	scope string
+
+	// sequence Set if the executions are assigned sequence numbers (see WithSequence)
+	sequence bool
+}
+
+// Option Optional behaviour for the mongo handler
+type Option func(*Handler)
+
+// WithQueryTimeout Limits each repository operation (separately from the handler context), so
+// a hung operation fails fast with execution.ErrQueryTimeout instead of hanging the whole
+// command
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.queryTimeout = timeout
+	}
+}
+
+// WithScope Stores and reads only the executions of the scope (a logical database), so one
+// executions collection can track the migrations of multiple logical databases managed by the
+// same binary. The executions of a scope are identified by {scope, version} documents, while
+// the executions saved without a scope keep the version as _id.
+func WithScope(scope string) Option {
+	return func(h *Handler) {
+		h.scope = scope
+	}
+}
+
+// WithSequence Makes the repository assign a monotonic sequence number, from a counters
+// collection, to each execution, when it is first saved (see
+// execution.MigrationExecution.Sequence), so the executions history is ordered reliably even
+// when the clocks of the hosts running migrations are skewed. The executions saved before have
+// no sequence.
+func WithSequence() Option {
+	return func(h *Handler) {
+		h.sequence = true
+	}
+}
+
+// NewHandler Builds a new Handler. If client is nil, it will try to build a client
+// from the provided dsn. It's preferable to not share the client used by the handler with
+// the one you pass in your migrations (this way, client sessions will not be mixed)
+func NewHandler(
+	dsn string,
+	databaseName string,
+	collectionName string,
+	ctx context.Context,
+	client *mongodriver.Client,
+	handlerOptions ...Option,
+) (*Handler, error) {
+	if client == nil {
+		var err error
+		client, err = newMongoClient(dsn, ctx)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	handler := &Handler{
+		client:         client,
+		databaseName:   databaseName,
+		collectionName: collectionName,
+		ctx:            ctx,
+	}
+
+	for _, option := range handlerOptions {
+		option(handler)
+	}
+
+	return handler, nil
+}
+
+func (h *Handler) Context() context.Context {
+	return h.ctx
+}
+
+// query Runs the operation within the query timeout, if set. Operations cancelled by the query
+// timeout (and not by the handler context) fail with execution.ErrQueryTimeout
+func (h *Handler) query(operation func(ctx context.Context) error) error {
+	if h.queryTimeout <= 0 {
+		return operation(h.ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(h.ctx, h.queryTimeout)
+	defer cancel()
+
+	err := operation(ctx)
+	if err == nil || h.ctx.Err() != nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w after %s, %w", execution.ErrQueryTimeout, h.queryTimeout, err)
+}
+
+// executionID The _id of the execution document with the version, in the handler scope
+func (h *Handler) executionID(version uint64) executionID {
+	return executionID{h.scope, version}
+}
+
+// scopeFilter Matches the execution documents of the handler scope
+func (h *Handler) scopeFilter() bson.D {
+	if h.scope == "" {
+		return bson.D{{"_id", bson.D{{"$type", "long"}}}}
+	}
+	return bson.D{{"_id.scope", h.scope}}
+}
+
+func (h *Handler) Init() error {
+	var names []string
+	err := h.query(func(ctx context.Context) (err error) {
+		names, err = h.client.Database(h.databaseName).ListCollectionNames(ctx, bson.D{})
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if name == h.collectionName {
+			if err = h.allowScopedIDs(); err != nil {
+				return err
+			}
+			return h.ensureIndexes()
+		}
+	}
+
+	if err = h.createCollection(); err != nil {
+		return err
+	}
+	return h.ensureIndexes()
+}
+
+// createCollection Creates the executions collection, with its validator
+func (h *Handler) createCollection() error {
+	collectionOpts := options.CreateCollection()
+	collectionOpts.SetValidator(executionsValidator())
+
+	return h.query(func(ctx context.Context) error {
+		return h.client.Database(h.databaseName).CreateCollection(
+			ctx, h.collectionName, collectionOpts,
+		)
+	})
+}
+
+// allowScopedIDs Replaces the validator of executions collections created by older library
+// versions, which only allows version _ids, so scoped handlers can save their executions
+func (h *Handler) allowScopedIDs() error {
+	if h.scope == "" {
+		return nil
+	}
+
+	return h.query(func(ctx context.Context) error {
+		return h.client.Database(h.databaseName).RunCommand(
+			ctx,
+			bson.D{{"collMod", h.collectionName}, {"validator", executionsValidator()}},
+		).Err()
+	})
+}
+
+// executionsValidator The validator of the executions collection documents
+func executionsValidator() bson.D {
+	return bson.D{
+		{
+			"$jsonSchema", bson.D{
+				{"bsonType", "object"},
+				{"title", "migration execution object validation"},
+				{
+					"properties", bson.D{
+						{
+							"_id", bson.D{
+								{"bsonType", bson.A{"long", "object"}},
+								{"minimum", 0},
+								{
+									"description",
+									"_id (executed version, or {scope, version}) must be greater" +
+										" than 0",
+								},
+							},
+						},
+						{
+							"executedAtMs", bson.D{
+								{"bsonType", "long"},
+								{"minimum", 0},
+								{"description", "executed at must be greater than 0"},
+							},
+						},
+						{
+							"finishedAtMs", bson.D{
+								{"bsonType", "long"},
+								{"minimum", 0},
+								{"description", "finished at must be greater than 0"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ensureIndexes Creates the secondary indexes which keep history queries, ordered by execution
+// time, and state (finished, unfinished) queries fast on long-lived databases. Creating indexes
+// which already exist is a no-op, so collections created by older library versions get them too.
+func (h *Handler) ensureIndexes() error {
+	return h.query(func(ctx context.Context) error {
+		_, err := h.client.Database(h.databaseName).Collection(h.collectionName).Indexes().
+			CreateMany(
+				ctx,
+				[]mongodriver.IndexModel{
+					{Keys: bson.D{{"executedAtMs", 1}}},
+					{Keys: bson.D{{"finishedAtMs", 1}}},
+				},
+			)
+		return err
+	})
+}
+
+func (h *Handler) LoadExecutions() (executions []execution.MigrationExecution, err error) {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+
+	var bsonExecutions []bsonExecution
+	err = h.query(func(ctx context.Context) error {
+		cursor, err := collection.Find(ctx, h.scopeFilter())
+		if err != nil {
+			return err
+		}
+		return cursor.All(ctx, &bsonExecutions)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var migrationExecutions []execution.MigrationExecution
+	for _, b := range bsonExecutions {
+		migrationExecutions = append(migrationExecutions, toMigrationExecution(b))
+	}
+
+	return migrationExecutions, nil
+}
+
+func (h *Handler) Save(exec execution.MigrationExecution) error {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+	filter := bson.D{{"_id", h.executionID(exec.Version)}}
+	updateOpts := options.Update()
+	updateOpts.SetUpsert(true)
+	update := bson.D{{"$set", toBsonExecution(h.scope, exec)}}
+
+	if h.sequence {
+		// The sequence is kept if the execution is already saved, so a number may be unused
+		sequence, err := h.nextSequence()
+		if err != nil {
+			return err
+		}
+		update = append(update, bson.E{"$setOnInsert", bson.D{{"sequence", sequence}}})
+	}
+
+	return h.query(func(ctx context.Context) error {
+		_, err := collection.UpdateOne(ctx, filter, update, updateOpts)
+		return err
+	})
+}
+
+// countersCollection The collection which holds the counter the execution sequence numbers are
+// taken from (see WithSequence)
+func (h *Handler) countersCollection() *mongodriver.Collection {
+	return h.client.Database(h.databaseName).Collection(h.collectionName + "_counters")
+}
+
+// nextSequence Atomically increments the executions counter and returns its new value
+func (h *Handler) nextSequence() (uint64, error) {
+	var counter struct {
+		Value uint64 `bson:"value"`
+	}
+
+	err := h.query(func(ctx context.Context) error {
+		return h.countersCollection().FindOneAndUpdate(
+			ctx,
+			bson.D{{"_id", "executions"}},
+			bson.D{{"$inc", bson.D{{"value", int64(1)}}}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&counter)
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to take the next execution sequence with error: %w", err)
+	}
+	return counter.Value, nil
+}
+
+// SaveIf See execution.ConditionalSaver. Uses an insert (failing on duplicate key) when no
+// execution is expected and a conditional update otherwise.
+func (h *Handler) SaveIf(
+	exec execution.MigrationExecution,
+	expected *execution.MigrationExecution,
+) error {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+
+	if expected == nil {
+		document := toBsonExecution(h.scope, exec)
+		if h.sequence {
+			sequence, err := h.nextSequence()
+			if err != nil {
+				return err
+			}
+			document.Sequence = sequence
+		}
+
+		err := h.query(func(ctx context.Context) error {
+			_, err := collection.InsertOne(ctx, document)
+			return err
+		})
+		if mongodriver.IsDuplicateKeyError(err) {
+			return execution.ErrExecutionConflict
+		}
+		return err
+	}
+
+	filter := bson.D{
+		{"_id", h.executionID(expected.Version)},
+		{"executedAtMs", expected.ExecutedAtMs},
+		{"finishedAtMs", expected.FinishedAtMs},
+	}
+	var result *mongodriver.UpdateResult
+	err := h.query(func(ctx context.Context) (err error) {
+		result, err = collection.UpdateOne(
+			ctx, filter, bson.D{{"$set", toBsonExecution(h.scope, exec)}},
+		)
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return execution.ErrExecutionConflict
+	}
+
+	return nil
+}
+
+func (h *Handler) Remove(exec execution.MigrationExecution) error {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+	filter := bson.D{{"_id", h.executionID(exec.Version)}}
+	return h.query(func(ctx context.Context) error {
+		_, err := collection.DeleteOne(ctx, filter)
+		return err
+	})
+}
+
+func (h *Handler) FindOne(version uint64) (*execution.MigrationExecution, error) {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+	filter := bson.D{{"_id", h.executionID(version)}}
+
+	var result bsonExecution
+	err := h.query(func(ctx context.Context) error {
+		return collection.FindOne(ctx, filter).Decode(&result)
+	})
+
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	exec := toMigrationExecution(result)
+	return &exec, err
+}
+
+func (h *Handler) LatestExecution() (*execution.MigrationExecution, error) {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+	findOpts := options.FindOne().SetSort(bson.D{{"_id", -1}})
+
+	var result bsonExecution
+	err := h.query(func(ctx context.Context) error {
+		return collection.FindOne(ctx, h.scopeFilter(), findOpts).Decode(&result)
+	})
+
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	exec := toMigrationExecution(result)
+	return &exec, nil
+}
+
+func (h *Handler) CountFinished() (int, error) {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+	var count int64
+	err := h.query(func(ctx context.Context) (err error) {
+		count, err = collection.CountDocuments(
+			ctx, append(h.scopeFilter(), bson.E{"finishedAtMs", bson.D{{"$gt", 0}}}),
+		)
+		return err
+	})
+	return int(count), err
+}
+
+// ServerTime See execution.ServerClock. Reads the localTime reported by the hello command of
+// the server the client is connected to
+func (h *Handler) ServerTime() (time.Time, error) {
+	var hello struct {
+		LocalTime time.Time `bson:"localTime"`
+	}
+	err := h.query(func(ctx context.Context) error {
+		return h.client.Database(h.databaseName).RunCommand(
+			ctx, bson.D{{"hello", 1}},
+		).Decode(&hello)
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the server time with error: %w", err)
+	}
+	return hello.LocalTime, nil
+}
+
+func (h *Handler) CheckRead() error {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+	err := h.query(func(ctx context.Context) error {
+		return collection.FindOne(ctx, bson.D{}).Err()
+	})
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil
+	}
+	return err
+}
+
+func (h *Handler) CheckWrite() error {
+	collection := h.client.Database(h.databaseName).Collection(h.collectionName)
+	// Matches no documents, but still requires the update privilege and a writable node
+	return h.query(func(ctx context.Context) error {
+		_, err := collection.UpdateOne(
+			ctx, bson.D{{"_id", -1}}, bson.D{{"$set", bson.D{{"finishedAtMs", 0}}}},
+		)
+		return err
+	})
+}
+
+// progressKey The stored key of the progress, prefixed with the scope, so the resumable tasks
+// of different scopes do not share progress
+func (h *Handler) progressKey(key string) string {
+	if h.scope == "" {
+		return key
+	}
+	return h.scope + ":" + key
+}
+
+type bsonProgress struct {
+	Key   string `bson:"_id"`
+	Token string `bson:"token"`
+	Done  bool   `bson:"done"`
+}
+
+// progressCollection The collection which holds the progress of resumable tasks (see
+// execution.ProgressStore)
+func (h *Handler) progressCollection() *mongodriver.Collection {
+	return h.client.Database(h.databaseName).Collection(h.collectionName + "_progress")
+}
+
+func (h *Handler) LoadProgress(key string) (*execution.Progress, error) {
+	var result bsonProgress
+	err := h.query(func(ctx context.Context) error {
+		return h.progressCollection().FindOne(ctx, bson.D{{"_id", h.progressKey(key)}}).
+			Decode(&result)
+	})
+
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &execution.Progress{Key: key, Token: result.Token, Done: result.Done}, nil
+}
+
+func (h *Handler) SaveProgress(progress execution.Progress) error {
+	return h.query(func(ctx context.Context) error {
+		_, err := h.progressCollection().ReplaceOne(
+			ctx,
+			bson.D{{"_id", h.progressKey(progress.Key)}},
+			bsonProgress{
+				Key: h.progressKey(progress.Key), Token: progress.Token, Done: progress.Done,
+			},
+			options.Replace().SetUpsert(true),
+		)
+		return err
+	})
+}
+
+type bsonAuditEntry struct {
+	Time       time.Time `bson:"time"`
+	Command    string    `bson:"command"`
+	Args       []string  `bson:"args"`
+	User       string    `bson:"user"`
+	Host       string    `bson:"host"`
+	Operator   string    `bson:"operator,omitempty"`
+	Result     string    `bson:"result"`
+	Error      string    `bson:"error,omitempty"`
+	DurationMs int64     `bson:"durationMs"`
+	WorkDirs   []string  `bson:"workDirs,omitempty"`
+}
+
+// auditCollection The collection which holds the audit entries (see execution.AuditLog)
+func (h *Handler) auditCollection() *mongodriver.Collection {
+	return h.client.Database(h.databaseName).Collection(h.collectionName + "_audit")
+}
+
+func (h *Handler) AppendAudit(entry execution.AuditEntry) error {
+	return h.query(func(ctx context.Context) error {
+		_, err := h.auditCollection().InsertOne(ctx, bsonAuditEntry(entry))
+		return err
+	})
+}
+
+type bsonNote struct {
+	Scope        string `bson:"scope"`
+	Version      uint64 `bson:"version"`
+	ExecutedAtMs uint64 `bson:"executedAtMs"`
+	Text         string `bson:"text"`
+	Author       string `bson:"author,omitempty"`
+	CreatedAtMs  uint64 `bson:"createdAtMs"`
+}
+
+// notesCollection The collection which holds the notes attached to executions (see
+// execution.NoteStore)
+func (h *Handler) notesCollection() *mongodriver.Collection {
+	return h.client.Database(h.databaseName).Collection(h.collectionName + "_notes")
+}
+
+func (h *Handler) AddNote(note execution.Note) error {
+	return h.query(func(ctx context.Context) error {
+		_, err := h.notesCollection().InsertOne(ctx, bsonNote{
+			Scope: h.scope, Version: note.Version, ExecutedAtMs: note.ExecutedAtMs,
+			Text: note.Text, Author: note.Author, CreatedAtMs: note.CreatedAtMs,
+		})
+		return err
+	})
+}
+
+func (h *Handler) LoadNotes() ([]execution.Note, error) {
+	var bsonNotes []bsonNote
+	err := h.query(func(ctx context.Context) error {
+		cursor, err := h.notesCollection().Find(
+			ctx, bson.D{{"scope", h.scope}}, options.Find().SetSort(bson.D{{"_id", 1}}),
+		)
+		if err != nil {
+			return err
+		}
+		return cursor.All(ctx, &bsonNotes)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []execution.Note
+	for _, note := range bsonNotes {
+		notes = append(notes, execution.Note{
+			Version: note.Version, ExecutedAtMs: note.ExecutedAtMs, Text: note.Text,
+			Author: note.Author, CreatedAtMs: note.CreatedAtMs,
+		})
+	}
+	return notes, nil
+}
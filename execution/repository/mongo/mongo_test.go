@@ -0,0 +1,475 @@
+package mongo
+
+import (
+	"context"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+const MongoDnsEnv = "MONGO_DSN"
+const MongoDbNameEnv = "MONGO_DATABASE"
+const MongoCollectionName = "migration_executions"
+
+type MongoTestSuite struct {
+	suite.Suite
+	dbName  string
+	dsn     string
+	client  *mongodriver.Client
+	handler *Handler
+}
+
+func TestMongoTestSuite(t *testing.T) {
+	suite.Run(t, new(MongoTestSuite))
+}
+
+func (suite *MongoTestSuite) SetupSuite() {
+	suite.dbName = os.Getenv(MongoDbNameEnv)
+	suite.dsn = os.Getenv(MongoDnsEnv)
+
+	if suite.dbName == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dbName = "migrations"
+	}
+
+	if suite.dsn == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dsn = "mongodb://localhost:27017"
+	}
+
+	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
+	opts := options.Client().ApplyURI(suite.dsn).SetServerAPIOptions(serverAPI)
+	opts.SetMaxPoolSize(1)
+	opts.SetMaxConnIdleTime(3 * time.Second)
+	opts.SetConnectTimeout(3 * time.Second)
+	opts.SetServerSelectionTimeout(3 * time.Second)
+	opts.SetTimeout(5 * time.Second)
+	opts.SetSocketTimeout(5 * time.Second)
+	client, _ := mongodriver.Connect(context.Background(), opts)
+
+	suite.handler = &Handler{
+		client: client, databaseName: suite.dbName, collectionName: MongoCollectionName,
+		ctx: context.Background(),
+	}
+	suite.client = suite.handler.client
+	_ = suite.handler.Init()
+}
+
+func (suite *MongoTestSuite) TearDownSuite() {
+	_ = suite.client.Database(suite.dbName).Drop(context.Background())
+}
+
+func (suite *MongoTestSuite) SetupTest() {
+	_, _ = suite.client.Database(suite.dbName).Collection(MongoCollectionName).DeleteMany(
+		context.Background(), bson.D{},
+	)
+}
+
+func (suite *MongoTestSuite) TearDownTest() {
+	_, _ = suite.client.Database(suite.dbName).Collection(MongoCollectionName).DeleteMany(
+		context.Background(), bson.D{},
+	)
+}
+
+func (suite *MongoTestSuite) TestItCanInitializeTheRepository() {
+	_ = suite.client.Database(suite.dbName).Collection(MongoCollectionName).
+		Drop(context.Background())
+	errInit1 := suite.handler.Init()
+	errInit2 := suite.handler.Init()
+	suite.Assert().Nil(errInit1)
+	suite.Assert().Nil(errInit2)
+	names, _ := suite.client.Database(suite.dbName).ListCollectionNames(suite.handler.ctx, bson.D{})
+	suite.Assert().Contains(names, MongoCollectionName)
+}
+
+func (suite *MongoTestSuite) TestItIndexesExistingCollections() {
+	db := suite.client.Database(suite.dbName)
+	_ = db.Collection(MongoCollectionName).Drop(context.Background())
+	_ = db.CreateCollection(context.Background(), MongoCollectionName)
+
+	suite.Assert().NoError(suite.handler.Init())
+	suite.Assert().NoError(suite.handler.Init())
+
+	specs, err := db.Collection(MongoCollectionName).Indexes().
+		ListSpecifications(context.Background())
+	suite.Require().NoError(err)
+	var names []string
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	suite.Assert().ElementsMatch([]string{"_id_", "executedAtMs_1", "finishedAtMs_1"}, names)
+}
+
+func (suite *MongoTestSuite) TestItCanLoadAllExecutions() {
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		_, _ = suite.client.Database(suite.dbName).Collection(MongoCollectionName).InsertOne(
+			context.Background(), toBsonExecution("", exec),
+		)
+	}
+
+	loadedExecs, err := suite.handler.LoadExecutions()
+
+	suite.Assert().NoError(err)
+	for _, exec := range loadedExecs {
+		suite.Assert().Contains(executions, exec.Version)
+		suite.Assert().Equal(executions[exec.Version], exec)
+		delete(executions, exec.Version)
+	}
+	suite.Assert().Len(executions, 0)
+}
+
+func (suite *MongoTestSuite) TestItCanSaveExecutions() {
+	// Insert
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		err := suite.handler.Save(exec)
+		suite.Assert().NoError(err)
+	}
+
+	savedExecs, _ := suite.handler.LoadExecutions()
+	for _, exec := range savedExecs {
+		suite.Assert().Contains(executions, exec.Version)
+		suite.Assert().Equal(executions[exec.Version], exec)
+	}
+
+	// Update
+	for i, exec := range executions {
+		exec.FinishedAtMs++
+		exec.ExecutedAtMs++
+		executions[i] = exec
+		err := suite.handler.Save(executions[i])
+		suite.Assert().NoError(err)
+	}
+
+	savedExecs, _ = suite.handler.LoadExecutions()
+	for _, exec := range savedExecs {
+		suite.Assert().Contains(executions, exec.Version)
+		suite.Assert().Equal(executions[exec.Version], exec)
+	}
+}
+
+func (suite *MongoTestSuite) TestItCanRemoveExecution() {
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		_ = suite.handler.Save(exec)
+		err := suite.handler.Remove(exec)
+		suite.Assert().NoError(err)
+	}
+
+	savedExecs, _ := suite.handler.LoadExecutions()
+
+	suite.Assert().Len(savedExecs, 0)
+}
+
+func (suite *MongoTestSuite) TestItCanFindOne() {
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		_ = suite.handler.Save(exec)
+	}
+
+	execToFind := executions[uint64(4)]
+	foundExec, err := suite.handler.FindOne(uint64(4))
+	suite.Assert().Equal(&execToFind, foundExec)
+	suite.Assert().Nil(err)
+	_ = suite.handler.Remove(*foundExec)
+	foundExec, err = suite.handler.FindOne(uint64(4))
+	suite.Assert().Nil(foundExec)
+	suite.Assert().Nil(err)
+}
+
+func (suite *MongoTestSuite) TestItCanSaveExecutionsConditionally() {
+	exec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 0}
+	finished := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+
+	suite.Assert().NoError(suite.handler.SaveIf(exec, nil))
+	suite.Assert().ErrorIs(suite.handler.SaveIf(exec, nil), execution.ErrExecutionConflict)
+	suite.Assert().NoError(suite.handler.SaveIf(finished, &exec))
+	suite.Assert().ErrorIs(
+		suite.handler.SaveIf(finished, &exec), execution.ErrExecutionConflict,
+	)
+
+	foundExec, _ := suite.handler.FindOne(1)
+	suite.Assert().Equal(&finished, foundExec)
+}
+
+func (suite *MongoTestSuite) TestItCanSaveExecutionsRunMetadata() {
+	exec := execution.MigrationExecution{
+		Version: 1, ExecutedAtMs: 2,
+		Run: execution.RunMetadata{DeployID: "deploy-12", GitSHA: "a1b2c3", Operator: "jane"},
+	}
+	finished := exec
+	finished.FinishedAtMs = 3
+
+	suite.Assert().NoError(suite.handler.SaveIf(exec, nil))
+	foundExec, _ := suite.handler.FindOne(1)
+	suite.Assert().Equal(&exec, foundExec)
+
+	suite.Assert().NoError(suite.handler.SaveIf(finished, &exec))
+	foundExec, _ = suite.handler.FindOne(1)
+	suite.Assert().Equal(&finished, foundExec)
+
+	finished.Run.Operator = "john"
+	suite.Assert().NoError(suite.handler.Save(finished))
+	executions, _ := suite.handler.LoadExecutions()
+	suite.Assert().Equal([]execution.MigrationExecution{finished}, executions)
+}
+
+func (suite *MongoTestSuite) TestItCanSaveSkippedExecutions() {
+	exec := execution.MigrationExecution{
+		Version: 1, ExecutedAtMs: 2, FinishedAtMs: 2, SkipReason: "superseded by 2",
+	}
+
+	suite.Assert().NoError(suite.handler.Save(exec))
+	foundExec, _ := suite.handler.FindOne(1)
+	suite.Assert().Equal(&exec, foundExec)
+	suite.Assert().True(foundExec.Skipped())
+}
+
+func (suite *MongoTestSuite) TestItCanReadExecutionsSummary() {
+	latest, err := suite.handler.LatestExecution()
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(latest)
+
+	for _, exec := range []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+		{Version: 4, ExecutedAtMs: 5, FinishedAtMs: 6},
+		{Version: 9, ExecutedAtMs: 10},
+	} {
+		_ = suite.handler.Save(exec)
+	}
+
+	latest, err = suite.handler.LatestExecution()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&execution.MigrationExecution{Version: 9, ExecutedAtMs: 10}, latest)
+
+	count, err := suite.handler.CountFinished()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(2, count)
+}
+
+func (suite *MongoTestSuite) TestItCanCheckRepositoryAccess() {
+	suite.Assert().NoError(suite.handler.CheckRead())
+	suite.Assert().NoError(suite.handler.CheckWrite())
+
+	executions, _ := suite.handler.LoadExecutions()
+	suite.Assert().Empty(executions)
+}
+
+func (suite *MongoTestSuite) TestItCanSaveAndLoadProgress() {
+	_, _ = suite.handler.progressCollection().DeleteMany(context.Background(), bson.D{})
+
+	progress, err := suite.handler.LoadProgress("users_backfill")
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(progress)
+
+	suite.Assert().NoError(
+		suite.handler.SaveProgress(execution.Progress{Key: "users_backfill", Token: "10"}),
+	)
+	suite.Assert().NoError(
+		suite.handler.SaveProgress(
+			execution.Progress{Key: "users_backfill", Token: "20", Done: true},
+		),
+	)
+
+	progress, err = suite.handler.LoadProgress("users_backfill")
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		&execution.Progress{Key: "users_backfill", Token: "20", Done: true}, progress,
+	)
+}
+
+func (suite *MongoTestSuite) TestItCanAppendAuditEntries() {
+	_, _ = suite.handler.auditCollection().DeleteMany(context.Background(), bson.D{})
+	entry := execution.AuditEntry{
+		Time:       time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		Command:    "up",
+		Args:       []string{"up", "all"},
+		User:       "deployer",
+		Host:       "ci-1",
+		Result:     execution.AuditResultSuccess,
+		DurationMs: 1500,
+		WorkDirs:   []string{"/tmp/go-migrations-1-up-1"},
+	}
+
+	suite.Assert().NoError(suite.handler.AppendAudit(entry))
+	suite.Assert().NoError(suite.handler.AppendAudit(entry))
+
+	var stored bsonAuditEntry
+	suite.Require().NoError(
+		suite.handler.auditCollection().FindOne(context.Background(), bson.D{}).Decode(&stored),
+	)
+	suite.Assert().Equal(entry, execution.AuditEntry(stored))
+
+	count, _ := suite.handler.auditCollection().CountDocuments(context.Background(), bson.D{})
+	suite.Assert().Equal(int64(2), count)
+}
+
+func (suite *MongoTestSuite) TestItCanAddAndLoadNotes() {
+	_, _ = suite.handler.notesCollection().DeleteMany(context.Background(), bson.D{})
+	notes := []execution.Note{
+		{
+			Version: 2, ExecutedAtMs: 1717235000000, Text: "backfill verified", Author: "jane",
+			CreatedAtMs: 1717236000000,
+		},
+		{Version: 1, Text: "index rebuilt", CreatedAtMs: 1717236001000},
+	}
+
+	for _, note := range notes {
+		suite.Assert().NoError(suite.handler.AddNote(note))
+	}
+
+	loaded, err := suite.handler.LoadNotes()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(notes, loaded)
+}
+
+func (suite *MongoTestSuite) TestItKeepsTheExecutionsOfScopesApart() {
+	suite.Require().NoError(suite.handler.Init())
+	billing := &Handler{
+		client: suite.client, databaseName: suite.dbName, collectionName: MongoCollectionName,
+		ctx: context.Background(), scope: "billing",
+	}
+	suite.Require().NoError(billing.Init())
+	unscopedExec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	billingExec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 4}
+
+	suite.Require().NoError(suite.handler.Save(unscopedExec))
+	suite.Require().NoError(billing.SaveIf(billingExec, nil))
+
+	executions, _ := billing.LoadExecutions()
+	suite.Assert().Equal([]execution.MigrationExecution{billingExec}, executions)
+	executions, _ = suite.handler.LoadExecutions()
+	suite.Assert().Equal([]execution.MigrationExecution{unscopedExec}, executions)
+
+	finished, _ := billing.CountFinished()
+	suite.Assert().Equal(0, finished)
+	latest, _ := billing.LatestExecution()
+	suite.Assert().Equal(&billingExec, latest)
+
+	suite.Assert().NoError(billing.Remove(billingExec))
+	found, _ := billing.FindOne(1)
+	suite.Assert().Nil(found)
+	found, _ = suite.handler.FindOne(1)
+	suite.Assert().Equal(&unscopedExec, found)
+}
+
+func (suite *MongoTestSuite) TestItReadsTheServerTime() {
+	serverTime, err := suite.handler.ServerTime()
+
+	suite.Assert().NoError(err)
+	suite.Assert().WithinDuration(time.Now(), serverTime, time.Minute)
+}
+
+func (suite *MongoTestSuite) TestItAssignsSequencesToExecutions() {
+	suite.Require().NoError(suite.handler.Init())
+	_ = suite.handler.countersCollection().Drop(context.Background())
+	suite.Require().NoError(
+		suite.handler.Save(execution.MigrationExecution{Version: 5, ExecutedAtMs: 1}),
+	)
+	sequenced := &Handler{
+		client: suite.client, databaseName: suite.dbName, collectionName: MongoCollectionName,
+		ctx: context.Background(), sequence: true,
+	}
+
+	suite.Require().NoError(sequenced.Save(execution.MigrationExecution{Version: 1}))
+	suite.Require().NoError(
+		sequenced.Save(execution.MigrationExecution{Version: 1, FinishedAtMs: 2}),
+	)
+	suite.Require().NoError(sequenced.SaveIf(execution.MigrationExecution{Version: 3}, nil))
+
+	executions, err := sequenced.LoadExecutions()
+	suite.Assert().NoError(err)
+	execution.SortHistory(executions)
+	suite.Assert().Equal(
+		[]execution.MigrationExecution{
+			{Version: 5, ExecutedAtMs: 1},
+			{Version: 1, FinishedAtMs: 2, Sequence: 1},
+			{Version: 3, Sequence: 3},
+		},
+		executions,
+	)
+}
+
+func executionsProvider() map[uint64]execution.MigrationExecution {
+	return map[uint64]execution.MigrationExecution{
+		uint64(1): {Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+		uint64(4): {Version: 4, ExecutedAtMs: 5, FinishedAtMs: 6},
+		uint64(7): {Version: 7, ExecutedAtMs: 8, FinishedAtMs: 9},
+	}
+}
+
+type QueryTimeoutTestSuite struct {
+	suite.Suite
+}
+
+func TestQueryTimeoutTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryTimeoutTestSuite))
+}
+
+func (suite *QueryTimeoutTestSuite) TestItFailsOperationsWhichDoNotFinishInTime() {
+	// Accepts connections, but never answers, like a hung server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			if _, err := listener.Accept(); err != nil {
+				return
+			}
+		}
+	}()
+
+	handler, err := NewHandler(
+		"mongodb://"+listener.Addr().String()+"/?directConnection=true", "app",
+		MongoCollectionName, context.Background(), nil, WithQueryTimeout(50*time.Millisecond),
+	)
+	suite.Require().NoError(err)
+
+	start := time.Now()
+	_, err = handler.FindOne(1)
+	suite.Assert().ErrorIs(err, execution.ErrQueryTimeout)
+	suite.Assert().ErrorContains(err, "after 50ms")
+	suite.Assert().Less(time.Since(start), 5*time.Second)
+
+	_, err = handler.LoadExecutions()
+	suite.Assert().ErrorIs(err, execution.ErrQueryTimeout)
+	err = handler.Save(execution.MigrationExecution{Version: 1})
+	suite.Assert().ErrorIs(err, execution.ErrQueryTimeout)
+}
+
+type ExecutionIDTestSuite struct {
+	suite.Suite
+}
+
+func TestExecutionIDTestSuite(t *testing.T) {
+	suite.Run(t, new(ExecutionIDTestSuite))
+}
+
+func (suite *ExecutionIDTestSuite) TestItEncodesScopedAndUnscopedIDs() {
+	for _, id := range []executionID{{"", 12}, {"billing", 12}} {
+		encoded, err := bson.Marshal(bsonExecution{ID: id})
+		suite.Require().NoError(err)
+
+		var decoded bsonExecution
+		suite.Require().NoError(bson.Unmarshal(encoded, &decoded))
+		suite.Assert().Equal(id, decoded.ID)
+	}
+
+	encoded, _ := bson.Marshal(bsonExecution{ID: executionID{"", 12}})
+	suite.Assert().Equal(bson.TypeInt64, bson.Raw(encoded).Lookup("_id").Type)
+	encoded, _ = bson.Marshal(bsonExecution{ID: executionID{"billing", 12}})
+	suite.Assert().Equal(
+		"billing", bson.Raw(encoded).Lookup("_id", "scope").StringValue(),
+	)
+}
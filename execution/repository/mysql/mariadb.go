@@ -0,0 +1,268 @@
+package mysql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// mysqlDeadlockErrNo Mysql error number for deadlocks. Galera reports certification failures
+// (conflicting writes committed on another node) with the same error number.
+const mysqlDeadlockErrNo = 1213
+
+// mysqlUnknownCommandErrNo Mysql error number returned by Galera nodes which are not ready to
+// accept queries (for example, while they are syncing with the cluster)
+const mysqlUnknownCommandErrNo = 1047
+
+// mariaDBLockName The name of the migrations lock row, from the lock table
+const mariaDBLockName = "migrations"
+
+// MariaDBSettings Optional MariaDBHandler behaviour
+type MariaDBSettings struct {
+	// Galera Enables causal reads (wsrep_sync_wait) for the executions reads, so they see the
+	// writes committed on the other cluster nodes
+	Galera bool
+
+	// WriteRetries How many times writes failing with certification conflicts or on nodes
+	// which are not ready are retried. Defaults to 3
+	WriteRetries int
+
+	// RetryDelay The delay before the first retry, doubled for each next retry. Defaults to
+	// 50 milliseconds
+	RetryDelay time.Duration
+
+	// LockTTL Locks acquired longer than this ago are considered abandoned (for example, by a
+	// crashed process) and can be taken over. Zero means locks never expire
+	LockTTL time.Duration
+}
+
+// MariaDBHandler Repository implementation for MariaDB, including multi-writer Galera
+// clusters. Writes (including the INSERT ... ON DUPLICATE KEY UPDATE upserts) which fail
+// Galera certification, because a conflicting write was committed on another node, are
+// retried, so the outcome is the same as on a single node. GET_LOCK and LOCK TABLES are node
+// local on Galera, so the cluster-wide migrations lock (see execution.Locker and
+// handler.WithExclusiveLock) is a row in a lock table, replicated like any other write.
+type MariaDBHandler struct {
+	*Handler
+	settings MariaDBSettings
+	owner    string
+}
+
+// NewMariaDBHandler Builds a new MariaDBHandler. If db is nil, it will try to build a db handle
+// from the provided dsn (see NewHandler)
+func NewMariaDBHandler(
+	dsn string,
+	tableName string,
+	ctx context.Context,
+	db *sql.DB,
+	settings MariaDBSettings,
+	options ...Option,
+) (*MariaDBHandler, error) {
+	mysqlHandler, err := NewHandler(dsn, tableName, ctx, db, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.WriteRetries == 0 {
+		settings.WriteRetries = 3
+	}
+
+	if settings.RetryDelay == 0 {
+		settings.RetryDelay = 50 * time.Millisecond
+	}
+
+	if settings.Galera {
+		mysqlHandler.statementPrefix = "SET STATEMENT wsrep_sync_wait = 1 FOR "
+	}
+
+	return &MariaDBHandler{
+		Handler:  mysqlHandler,
+		settings: settings,
+		owner:    newLockOwner(),
+	}, nil
+}
+
+// newLockOwner Builds an identifier of the current process, for the lock table
+func newLockOwner() string {
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+func (h *MariaDBHandler) Init() error {
+	err := h.retry(h.Handler.Init)
+	if err != nil {
+		return err
+	}
+
+	return h.retry(
+		func() error {
+			_, err := h.exec(
+				"CREATE TABLE IF NOT EXISTS `" + h.lockTableName() + "` (" +
+					"`name` VARCHAR(64) NOT NULL," +
+					"`owner` VARCHAR(255) NOT NULL," +
+					"`acquired_at_ms` BIGINT UNSIGNED NOT NULL," +
+					"PRIMARY KEY (`name`)" +
+					")" + h.tableOptions,
+			)
+			return err
+		},
+	)
+}
+
+// lockTableName The table which holds the migrations lock
+func (h *MariaDBHandler) lockTableName() string {
+	return h.tableName + "_lock"
+}
+
+// retry Calls write until it succeeds, fails with a non retryable error or the retries are
+// exhausted
+func (h *MariaDBHandler) retry(write func() error) error {
+	delay := h.settings.RetryDelay
+	err := write()
+
+	for attempt := 0; attempt < h.settings.WriteRetries && isRetryable(err); attempt++ {
+		select {
+		case <-h.ctx.Done():
+			return errors.Join(err, h.ctx.Err())
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		err = write()
+	}
+
+	return err
+}
+
+// isRetryable Checks if the error is a Galera certification failure or a node not ready error
+func isRetryable(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	return errors.As(err, &mysqlErr) &&
+		(mysqlErr.Number == mysqlDeadlockErrNo || mysqlErr.Number == mysqlUnknownCommandErrNo)
+}
+
+func (h *MariaDBHandler) Save(exec execution.MigrationExecution) error {
+	return h.retry(
+		func() error {
+			return h.Handler.Save(exec)
+		},
+	)
+}
+
+// SaveIf See execution.ConditionalSaver. If the write loses a certification conflict, it is
+// retried and fails with execution.ErrExecutionConflict, since the persisted execution was
+// changed by the conflicting write.
+func (h *MariaDBHandler) SaveIf(
+	exec execution.MigrationExecution,
+	expected *execution.MigrationExecution,
+) error {
+	return h.retry(
+		func() error {
+			return h.Handler.SaveIf(exec, expected)
+		},
+	)
+}
+
+func (h *MariaDBHandler) Remove(exec execution.MigrationExecution) error {
+	return h.retry(
+		func() error {
+			return h.Handler.Remove(exec)
+		},
+	)
+}
+
+func (h *MariaDBHandler) SaveProgress(progress execution.Progress) error {
+	return h.retry(
+		func() error {
+			return h.Handler.SaveProgress(progress)
+		},
+	)
+}
+
+// Lock See execution.Locker. Inserts the lock row, failing with execution.ErrLockHeld if it
+// exists. On Galera, if two nodes insert the row at the same time, only one of the inserts
+// passes certification. Abandoned locks (see MariaDBSettings.LockTTL) are taken over.
+func (h *MariaDBHandler) Lock() error {
+	now := time.Now().UnixMilli()
+	_, err := h.exec(
+		"INSERT INTO `"+h.lockTableName()+"` (`name`, `owner`, `acquired_at_ms`)"+
+			" VALUES (?, ?, ?)",
+		mariaDBLockName, h.owner, now,
+	)
+
+	var mysqlErr *mysqldriver.MySQLError
+	if err == nil {
+		return nil
+	} else if !errors.As(err, &mysqlErr) ||
+		(mysqlErr.Number != mysqlDuplicateEntryErrNo && mysqlErr.Number != mysqlDeadlockErrNo) {
+		return err
+	}
+
+	if h.settings.LockTTL > 0 {
+		result, err := h.exec(
+			"UPDATE `"+h.lockTableName()+"` SET `owner` = ?, `acquired_at_ms` = ?"+
+				" WHERE `name` = ? AND `acquired_at_ms` < ?",
+			h.owner, now, mariaDBLockName, now-h.settings.LockTTL.Milliseconds(),
+		)
+
+		if err == nil {
+			if affected, err := result.RowsAffected(); err == nil && affected == 1 {
+				return nil
+			}
+		} else if !isRetryable(err) {
+			return err
+		}
+	}
+
+	var holder execution.LockHolder
+	if current, _ := h.CurrentLockHolder(); current != nil {
+		holder = *current
+	}
+
+	return fmt.Errorf(
+		"%w: held by %s since %s",
+		execution.ErrLockHeld, holder.Owner, execution.FormatTimestampMs(holder.AcquiredAtMs),
+	)
+}
+
+// CurrentLockHolder See execution.LockInspector. Abandoned locks (see MariaDBSettings.LockTTL)
+// are reported until they are taken over.
+func (h *MariaDBHandler) CurrentLockHolder() (*execution.LockHolder, error) {
+	var holder execution.LockHolder
+	err := h.queryRow(
+		h.selectClause()+"`owner`, `acquired_at_ms` FROM `"+
+			h.lockTableName()+"` WHERE `name` = ?",
+		mariaDBLockName,
+	).Scan(&holder.Owner, &holder.AcquiredAtMs)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &holder, nil
+}
+
+// Unlock See execution.Locker. Removes the lock row, only if it is owned by the handler
+func (h *MariaDBHandler) Unlock() error {
+	return h.retry(
+		func() error {
+			_, err := h.exec(
+				"DELETE FROM `"+h.lockTableName()+"` WHERE `name` = ? AND `owner` = ?",
+				mariaDBLockName, h.owner,
+			)
+			return err
+		},
+	)
+}
@@ -0,0 +1,162 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+const MariaDBDnsEnv = "MARIADB_DSN"
+
+type MariaDBTestSuite struct {
+	suite.Suite
+	dbName  string
+	dsn     string
+	db      *sql.DB
+	handler *MariaDBHandler
+}
+
+func TestMariaDBTestSuite(t *testing.T) {
+	suite.Run(t, new(MariaDBTestSuite))
+}
+
+func (suite *MariaDBTestSuite) SetupSuite() {
+	suite.dbName = os.Getenv(DbNameEnv)
+	suite.dsn = os.Getenv(MariaDBDnsEnv)
+
+	if suite.dbName == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dbName = "migrations"
+	}
+
+	if suite.dsn == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dsn = "root:123456789@tcp(localhost:3307)/" + suite.dbName
+	}
+
+	tmpDb, _ := sql.Open("mysql", strings.TrimRight(suite.dsn, suite.dbName))
+	_, _ = tmpDb.Exec("DROP DATABASE IF EXISTS " + suite.dbName)
+	_, _ = tmpDb.Exec("CREATE DATABASE " + suite.dbName)
+	_ = tmpDb.Close()
+
+	suite.handler, _ = NewMariaDBHandler(
+		suite.dsn, ExecutionsTable, context.Background(), nil, MariaDBSettings{Galera: true},
+	)
+	suite.db = suite.handler.db
+}
+
+func (suite *MariaDBTestSuite) TearDownSuite() {
+	_, _ = suite.db.Exec("DROP DATABASE IF EXISTS " + suite.dbName)
+	_ = suite.db.Close()
+}
+
+func (suite *MariaDBTestSuite) SetupTest() {
+	_ = suite.handler.Init()
+	_, _ = suite.db.Exec("DELETE FROM " + ExecutionsTable)
+	_, _ = suite.db.Exec("DELETE FROM " + suite.handler.lockTableName())
+}
+
+func (suite *MariaDBTestSuite) TestItCanSaveAndLoadExecutions() {
+	exec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2}
+	finished := exec
+	finished.FinishedAtMs = 3
+
+	suite.Assert().NoError(suite.handler.SaveIf(exec, nil))
+	suite.Assert().ErrorIs(suite.handler.SaveIf(exec, nil), execution.ErrExecutionConflict)
+	suite.Assert().NoError(suite.handler.SaveIf(finished, &exec))
+	suite.Assert().NoError(suite.handler.Save(finished))
+
+	executions, err := suite.handler.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]execution.MigrationExecution{finished}, executions)
+
+	suite.Assert().NoError(suite.handler.Remove(finished))
+	foundExec, err := suite.handler.FindOne(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(foundExec)
+}
+
+func (suite *MariaDBTestSuite) TestItTakesAnExclusiveLock() {
+	other, _ := NewMariaDBHandler(
+		"", ExecutionsTable, context.Background(), suite.db, MariaDBSettings{},
+	)
+
+	suite.Assert().NoError(suite.handler.Lock())
+	suite.Assert().ErrorIs(other.Lock(), execution.ErrLockHeld)
+	suite.Assert().NoError(other.Unlock())
+	suite.Assert().ErrorIs(other.Lock(), execution.ErrLockHeld)
+
+	suite.Assert().NoError(suite.handler.Unlock())
+	suite.Assert().NoError(other.Lock())
+	suite.Assert().NoError(other.Unlock())
+}
+
+func (suite *MariaDBTestSuite) TestItReportsTheLockHolder() {
+	holder, err := suite.handler.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(holder)
+
+	before := uint64(time.Now().UnixMilli())
+	suite.Require().NoError(suite.handler.Lock())
+	holder, err = suite.handler.CurrentLockHolder()
+	suite.Assert().NoError(err)
+	suite.Require().NotNil(holder)
+	suite.Assert().Equal(suite.handler.owner, holder.Owner)
+	suite.Assert().GreaterOrEqual(holder.AcquiredAtMs, before)
+
+	suite.Require().NoError(suite.handler.Unlock())
+	holder, _ = suite.handler.CurrentLockHolder()
+	suite.Assert().Nil(holder)
+}
+
+func (suite *MariaDBTestSuite) TestItTakesOverAbandonedLocks() {
+	other, _ := NewMariaDBHandler(
+		"", ExecutionsTable, context.Background(), suite.db,
+		MariaDBSettings{LockTTL: time.Minute},
+	)
+	_, _ = suite.db.Exec(
+		"INSERT INTO `"+suite.handler.lockTableName()+"` VALUES (?, ?, ?)",
+		mariaDBLockName, "crashed", time.Now().Add(-time.Hour).UnixMilli(),
+	)
+
+	suite.Assert().ErrorIs(suite.handler.Lock(), execution.ErrLockHeld)
+	suite.Assert().NoError(other.Lock())
+	suite.Assert().ErrorIs(suite.handler.Lock(), execution.ErrLockHeld)
+}
+
+func (suite *MariaDBTestSuite) TestItRetriesCertificationFailures() {
+	attempts := 0
+	handler := &MariaDBHandler{
+		Handler:  suite.handler.Handler,
+		settings: MariaDBSettings{WriteRetries: 2, RetryDelay: time.Millisecond},
+	}
+
+	err := handler.retry(
+		func() error {
+			attempts++
+			if attempts < 3 {
+				return &mysqldriver.MySQLError{Number: mysqlDeadlockErrNo}
+			}
+			return nil
+		},
+	)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(3, attempts)
+
+	attempts = 0
+	err = handler.retry(
+		func() error {
+			attempts++
+			return &mysqldriver.MySQLError{Number: mysqlDuplicateEntryErrNo}
+		},
+	)
+	suite.Assert().Error(err)
+	suite.Assert().Equal(1, attempts)
+}
@@ -0,0 +1,804 @@
+// Package mysql includes the execution.Repository implementations for Mysql and its derivatives
+// (MariaDB, TiDB). Importing it registers the mysql, mariadb and tidb schemes for
+// repository.FromDSN.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// mysqlExecutionColumns The executions table columns, in the order they are scanned
+const mysqlExecutionColumns = "`version`, `executed_at_ms`, `finished_at_ms`," +
+	" `deploy_id`, `git_sha`, `operator`, `skip_reason`"
+
+// mysqlLayoutUpgrade An additive change of the executions table layout, released after the
+// table was first released (a migration of the tool's own table). It adds a column or, if index
+// is set, a secondary index on the definition columns.
+type mysqlLayoutUpgrade struct {
+	column     string
+	definition string
+	index      string
+}
+
+// mysqlLayoutUpgrades The executions table layout upgrades, in the order they were released.
+// The layout version of a table is the number of upgrades it includes. New execution fields and
+// indexes must be added to the CREATE TABLE statement and appended here (never reordered or
+// removed), so Init upgrades the tables created by older library versions.
+var mysqlLayoutUpgrades = []mysqlLayoutUpgrade{
+	{column: "deploy_id", definition: "VARCHAR(255) NOT NULL DEFAULT ''"},
+	{column: "git_sha", definition: "VARCHAR(255) NOT NULL DEFAULT ''"},
+	{column: "operator", definition: "VARCHAR(255) NOT NULL DEFAULT ''"},
+	{column: "skip_reason", definition: "VARCHAR(1024) NOT NULL DEFAULT ''"},
+	// Keep history queries, ordered by execution time, and state (finished, unfinished)
+	// queries fast on long-lived databases
+	{index: "idx_executed_at_ms", definition: "(`executed_at_ms`)"},
+	{index: "idx_finished_at_ms", definition: "(`finished_at_ms`)"},
+	// The scope is part of the primary key, which is replaced by the same (atomic) statement
+	{
+		column: "scope",
+		definition: "VARCHAR(255) NOT NULL DEFAULT '' FIRST, DROP PRIMARY KEY," +
+			" ADD PRIMARY KEY (`scope`, `version`)",
+	},
+}
+
+// mysqlLayoutComment The executions table comment, which holds the table layout version
+const mysqlLayoutComment = "go-migrations executions, layout %d"
+
+// mysqlDuplicateEntryErrNo Mysql error number for duplicate key violations
+const mysqlDuplicateEntryErrNo = 1062
+
+// Handler Repository implementation for Mysql integration
+type Handler struct {
+	db        *sql.DB
+	tableName string
+	ctx       context.Context
+
+	// statementPrefix Prepended to the executions read statements (for example, to set
+	// statement level variables)
+	statementPrefix string
+
+	// selectHints The hints added to the executions read statements
+	selectHints string
+
+	// tableOptions The options of the created tables
+	tableOptions string
+
+	// queryTimeout Limits each repository query. Zero means the queries are limited only by
+	// the handler context
+	queryTimeout time.Duration
+
+	// scope The logical database whose executions are stored and read (see WithScope)
+	scope string
+
+	// sequence Set if the executions are assigned sequence numbers (see WithSequence)
+	sequence bool
+
+	// replica The read replica serving the executions reads, if any (see WithReadReplica)
+	replica *readReplica
+}
+
+// Option Optional behaviour for the mysql handlers
+type Option func(*Handler)
+
+// WithQueryTimeout Limits each repository query (separately from the handler context), so a
+// hung query (for example, waiting for a metadata lock) fails fast with
+// execution.ErrQueryTimeout instead of hanging the whole command
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.queryTimeout = timeout
+	}
+}
+
+// WithScope Stores and reads only the executions of the scope (a logical database or schema),
+// so one executions table can track the migrations of multiple logical databases managed by the
+// same binary. The executions saved without a scope belong to the empty scope.
+func WithScope(scope string) Option {
+	return func(h *Handler) {
+		h.scope = scope
+	}
+}
+
+// WithSequence Makes the repository assign a monotonic sequence number (an AUTO_INCREMENT
+// column) to each execution, when it is first saved (see execution.MigrationExecution.Sequence),
+// so the executions history is ordered reliably even when the clocks of the hosts running
+// migrations are skewed. Init adds the column to existing tables, numbering the executions saved
+// before in version order. Not supported by TiDB, which can not add AUTO_INCREMENT columns to
+// existing tables.
+func WithSequence() Option {
+	return func(h *Handler) {
+		h.sequence = true
+	}
+}
+
+func newMysqlDbHandle(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+
+	if db == nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(1)
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxIdleTime(0)
+	db.SetConnMaxLifetime(0)
+	return db, err
+}
+
+// NewHandler Builds a new Handler. If db is nil, it will try to build a db handle
+// from the provided dsn. It's preferable to not share the db handle used by the handler with
+// the one you pass in your migrations (this way, db sessions will not be mixed)
+func NewHandler(
+	dsn string,
+	tableName string,
+	ctx context.Context,
+	db *sql.DB,
+	options ...Option,
+) (*Handler, error) {
+	if db == nil {
+		var err error
+		db, err = newMysqlDbHandle(dsn)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	handler := &Handler{
+		db:           db,
+		tableName:    tableName,
+		ctx:          ctx,
+		selectHints:  "SQL_NO_CACHE ",
+		tableOptions: " ENGINE=InnoDB CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci",
+	}
+
+	for _, option := range options {
+		option(handler)
+	}
+
+	if err := handler.openReplica(); err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+func (h *Handler) Context() context.Context {
+	return h.ctx
+}
+
+// queryContext The context of a repository query, limited by the query timeout, if set
+func (h *Handler) queryContext() (context.Context, context.CancelFunc) {
+	if h.queryTimeout <= 0 {
+		return h.ctx, func() {}
+	}
+	return context.WithTimeout(h.ctx, h.queryTimeout)
+}
+
+// timeoutErr Reports the errors of queries cancelled by the query timeout (and not by the
+// handler context) as execution.ErrQueryTimeout
+func (h *Handler) timeoutErr(ctx context.Context, err error) error {
+	if err == nil || h.queryTimeout <= 0 || h.ctx.Err() != nil ||
+		!errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w after %s, %w", execution.ErrQueryTimeout, h.queryTimeout, err)
+}
+
+// exec Runs the statement within the query timeout
+func (h *Handler) exec(query string, args ...any) (sql.Result, error) {
+	ctx, cancel := h.queryContext()
+	defer cancel()
+	result, err := h.db.ExecContext(ctx, query, args...)
+	return result, h.timeoutErr(ctx, err)
+}
+
+// timedRow A query row which is read within the query timeout, when scanned
+type timedRow struct {
+	handler *Handler
+	ctx     context.Context
+	cancel  context.CancelFunc
+	row     *sql.Row
+}
+
+func (r *timedRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.handler.timeoutErr(r.ctx, r.row.Scan(dest...))
+}
+
+// queryRow Runs the query, which returns at most one row, within the query timeout
+func (h *Handler) queryRow(query string, args ...any) *timedRow {
+	return h.queryRowOn(h.db, query, args...)
+}
+
+// queryRowOn Same as queryRow, on the provided db handle (for example, the read replica)
+func (h *Handler) queryRowOn(db *sql.DB, query string, args ...any) *timedRow {
+	ctx, cancel := h.queryContext()
+	return &timedRow{h, ctx, cancel, db.QueryRowContext(ctx, query, args...)}
+}
+
+func (h *Handler) Init() error {
+	_, err := h.exec(
+		"CREATE TABLE IF NOT EXISTS `" + h.tableName + "` (" +
+			"`scope` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`version` BIGINT UNSIGNED NOT NULL," +
+			"`executed_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`finished_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`deploy_id` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`git_sha` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`operator` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`skip_reason` VARCHAR(1024) NOT NULL DEFAULT ''," +
+			"PRIMARY KEY (`scope`, `version`)," +
+			"KEY `idx_executed_at_ms` (`executed_at_ms`)," +
+			"KEY `idx_finished_at_ms` (`finished_at_ms`)" +
+			")" + h.tableOptions + " COMMENT='" + layoutComment(len(mysqlLayoutUpgrades)) + "'",
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err = h.upgradeLayout(); err != nil {
+		return fmt.Errorf("failed to upgrade the executions table layout: %w", err)
+	}
+
+	if err = h.addSequenceColumn(); err != nil {
+		return fmt.Errorf("failed to add the executions sequence column: %w", err)
+	}
+
+	_, err = h.exec(
+		"CREATE TABLE IF NOT EXISTS `" + h.progressTableName() + "` (" +
+			"`key` VARCHAR(255) NOT NULL," +
+			"`token` TEXT NOT NULL," +
+			"`done` TINYINT(1) NOT NULL DEFAULT 0," +
+			"PRIMARY KEY (`key`)" +
+			")" + h.tableOptions,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = h.exec(
+		"CREATE TABLE IF NOT EXISTS `" + h.auditTableName() + "` (" +
+			"`id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`time_ms` BIGINT NOT NULL," +
+			"`command` VARCHAR(255) NOT NULL," +
+			"`args` TEXT NOT NULL," +
+			"`user` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`host` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`operator` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`result` VARCHAR(32) NOT NULL," +
+			"`error` TEXT NOT NULL," +
+			"`duration_ms` BIGINT NOT NULL," +
+			"`work_dirs` TEXT NOT NULL," +
+			"PRIMARY KEY (`id`)" +
+			")" + h.tableOptions,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = h.exec(
+		"CREATE TABLE IF NOT EXISTS `" + h.notesTableName() + "` (" +
+			"`id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`scope` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`version` BIGINT UNSIGNED NOT NULL," +
+			"`executed_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`text` TEXT NOT NULL," +
+			"`author` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`created_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_scope_version` (`scope`, `version`)" +
+			")" + h.tableOptions,
+	)
+	return err
+}
+
+// selectClause The SELECT clause of the executions read statements, with the statement prefix
+// and the select hints
+func (h *Handler) selectClause() string {
+	return h.statementPrefix + "SELECT " + h.selectHints
+}
+
+// auditTableName The table which holds the audit entries (see execution.AuditLog)
+func (h *Handler) auditTableName() string {
+	return h.tableName + "_audit"
+}
+
+// notesTableName The table which holds the notes attached to executions (see
+// execution.NoteStore)
+func (h *Handler) notesTableName() string {
+	return h.tableName + "_notes"
+}
+
+// progressTableName The table which holds the progress of resumable tasks (see
+// execution.ProgressStore)
+func (h *Handler) progressTableName() string {
+	return h.tableName + "_progress"
+}
+
+// progressKey The stored key of the progress, prefixed with the scope, so the resumable tasks
+// of different scopes do not share progress
+func (h *Handler) progressKey(key string) string {
+	if h.scope == "" {
+		return key
+	}
+	return h.scope + ":" + key
+}
+
+// layoutComment The executions table comment for the layout version
+func layoutComment(version int) string {
+	return fmt.Sprintf(mysqlLayoutComment, version)
+}
+
+// upgradeLayout Applies the layout upgrades missing from executions tables created by older
+// library versions and records the new layout version. Tables with the current (or a newer)
+// layout version are left unchanged.
+func (h *Handler) upgradeLayout() error {
+	var comment string
+	err := h.queryRow(
+		"SELECT `TABLE_COMMENT` FROM `information_schema`.`TABLES`"+
+			" WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?",
+		h.tableName,
+	).Scan(&comment)
+
+	if err != nil {
+		return err
+	}
+
+	// Tables created before the layout version was recorded have no layout comment, so they
+	// are treated as layout 0 (the upgrades are checked against the existing columns)
+	var version int
+	_, _ = fmt.Sscanf(comment, mysqlLayoutComment, &version)
+
+	if version >= len(mysqlLayoutUpgrades) {
+		return nil
+	}
+
+	if err = h.applyMissingUpgrades(mysqlLayoutUpgrades[version:]); err != nil {
+		return err
+	}
+
+	// DDL statements can not use placeholders, the comment does not include quotes
+	comment = layoutComment(len(mysqlLayoutUpgrades))
+	_, err = h.exec("ALTER TABLE `" + h.tableName + "` COMMENT = '" + comment + "'")
+	return err
+}
+
+// applyMissingUpgrades Adds the upgrades' columns and indexes which are missing from the
+// executions table. Columns and indexes which already exist (for example, added by a previously
+// interrupted upgrade) are skipped.
+func (h *Handler) applyMissingUpgrades(upgrades []mysqlLayoutUpgrade) error {
+	existingColumns, err := h.existingNames(
+		"SELECT `COLUMN_NAME` FROM `information_schema`.`COLUMNS`" +
+			" WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?",
+	)
+	if err != nil {
+		return err
+	}
+
+	existingIndexes, err := h.existingNames(
+		"SELECT DISTINCT `INDEX_NAME` FROM `information_schema`.`STATISTICS`" +
+			" WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?",
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, upgrade := range upgrades {
+		statement := "ALTER TABLE `" + h.tableName + "` ADD COLUMN `" + upgrade.column + "` " +
+			upgrade.definition
+		exists := existingColumns[upgrade.column]
+
+		if upgrade.index != "" {
+			statement = "ALTER TABLE `" + h.tableName + "` ADD INDEX `" + upgrade.index + "` " +
+				upgrade.definition
+			exists = existingIndexes[upgrade.index]
+		}
+
+		if exists {
+			continue
+		}
+
+		if _, err = h.exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addSequenceColumn Adds the sequence column to the executions table, if sequences are enabled
+// (see WithSequence) and the column does not exist yet
+func (h *Handler) addSequenceColumn() error {
+	if !h.sequence {
+		return nil
+	}
+
+	existingColumns, err := h.existingNames(
+		"SELECT `COLUMN_NAME` FROM `information_schema`.`COLUMNS`" +
+			" WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?",
+	)
+	if err != nil || existingColumns["sequence"] {
+		return err
+	}
+
+	_, err = h.exec(
+		"ALTER TABLE `" + h.tableName + "` ADD COLUMN `sequence` BIGINT UNSIGNED NOT NULL" +
+			" AUTO_INCREMENT, ADD UNIQUE KEY `idx_sequence` (`sequence`)",
+	)
+	return err
+}
+
+// existingNames Runs the information schema query, which selects the names of the executions
+// table columns or indexes
+func (h *Handler) existingNames(query string) (existing map[string]bool, err error) {
+	ctx, cancel := h.queryContext()
+	defer cancel()
+	defer func() { err = h.timeoutErr(ctx, err) }()
+
+	rows, err := h.db.QueryContext(ctx, query, h.tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing = make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+		existing[name] = true
+	}
+
+	return existing, errors.Join(rows.Err(), rows.Close())
+}
+
+func (h *Handler) LoadExecutions() ([]execution.MigrationExecution, error) {
+	return h.loadExecutions(h.db)
+}
+
+// loadExecutions Loads the executions from the provided db handle (the primary or the replica)
+func (h *Handler) loadExecutions(
+	db *sql.DB,
+) (executions []execution.MigrationExecution, err error) {
+	ctx, cancel := h.queryContext()
+	defer cancel()
+	defer func() { err = h.timeoutErr(ctx, err) }()
+
+	rows, err := db.QueryContext(
+		ctx,
+		h.selectClause()+h.readColumns()+" FROM `"+h.tableName+"` WHERE `scope` = ?",
+		h.scope,
+	)
+
+	if err != nil {
+		return executions, err
+	}
+
+	defer func(rows *sql.Rows) {
+		if closeErr := rows.Close(); closeErr != nil && err != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var exec execution.MigrationExecution
+		if err = rows.Scan(h.readFields(&exec)...); err != nil {
+			return executions, err
+		}
+		executions = append(executions, exec)
+	}
+
+	err = rows.Err()
+	return executions, err
+}
+
+func (h *Handler) Save(execution execution.MigrationExecution) error {
+	h.markWritten()
+	_, err := h.exec(
+		"INSERT INTO `"+h.tableName+"` (`scope`, "+mysqlExecutionColumns+")"+
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE "+
+			" `executed_at_ms` = VALUES(`executed_at_ms`), "+
+			" `finished_at_ms` = VALUES(`finished_at_ms`), "+
+			" `deploy_id` = VALUES(`deploy_id`), "+
+			" `git_sha` = VALUES(`git_sha`), "+
+			" `operator` = VALUES(`operator`), "+
+			" `skip_reason` = VALUES(`skip_reason`)",
+		h.executionValues(execution)...,
+	)
+	return err
+}
+
+// SaveIf See execution.ConditionalSaver. Uses a plain INSERT (failing on duplicate key) when no
+// execution is expected and a conditional UPDATE otherwise. Mysql reports only the changed rows
+// as affected (unless the clientFoundRows DSN parameter is set), so, if no row was affected, the
+// expected execution is looked up, to tell an unchanged row from a conflict.
+func (h *Handler) SaveIf(
+	exec execution.MigrationExecution,
+	expected *execution.MigrationExecution,
+) error {
+	h.markWritten()
+	if expected == nil {
+		_, err := h.exec(
+			"INSERT INTO `"+h.tableName+"` (`scope`, "+mysqlExecutionColumns+")"+
+				" VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			h.executionValues(exec)...,
+		)
+
+		var mysqlErr *mysqldriver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntryErrNo {
+			return execution.ErrExecutionConflict
+		}
+
+		return err
+	}
+
+	result, err := h.exec(
+		"UPDATE `"+h.tableName+"` SET `executed_at_ms` = ?, `finished_at_ms` = ?,"+
+			" `deploy_id` = ?, `git_sha` = ?, `operator` = ?, `skip_reason` = ?"+
+			" WHERE `scope` = ? AND `version` = ? AND `executed_at_ms` = ?"+
+			" AND `finished_at_ms` = ?",
+		exec.ExecutedAtMs, exec.FinishedAtMs,
+		exec.Run.DeployID, exec.Run.GitSHA, exec.Run.Operator, exec.SkipReason,
+		h.scope, expected.Version, expected.ExecutedAtMs, expected.FinishedAtMs,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+
+	var matched int
+	err = h.queryRow(
+		"SELECT COUNT(*) FROM `"+h.tableName+"` WHERE `scope` = ? AND `version` = ?"+
+			" AND `executed_at_ms` = ? AND `finished_at_ms` = ?",
+		h.scope, expected.Version, expected.ExecutedAtMs, expected.FinishedAtMs,
+	).Scan(&matched)
+	if err != nil {
+		return err
+	} else if matched == 0 {
+		return execution.ErrExecutionConflict
+	}
+
+	return nil
+}
+
+func (h *Handler) Remove(execution execution.MigrationExecution) error {
+	h.markWritten()
+	_, err := h.exec(
+		"DELETE FROM `"+h.tableName+"` WHERE `scope` = ? AND `version` = ?",
+		h.scope, execution.Version,
+	)
+	return err
+}
+
+func (h *Handler) FindOne(version uint64) (*execution.MigrationExecution, error) {
+	return h.findOne(h.db, version)
+}
+
+// findOne Finds the execution on the provided db handle (the primary or the replica)
+func (h *Handler) findOne(db *sql.DB, version uint64) (*execution.MigrationExecution, error) {
+	var exec execution.MigrationExecution
+	err := h.queryRowOn(
+		db,
+		h.selectClause()+h.readColumns()+" FROM `"+h.tableName+
+			"` WHERE `scope` = ? AND `version` = ?",
+		h.scope, version,
+	).Scan(h.readFields(&exec)...)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &exec, nil
+}
+
+func (h *Handler) LatestExecution() (*execution.MigrationExecution, error) {
+	return h.latestExecution(h.db)
+}
+
+func (h *Handler) latestExecution(db *sql.DB) (*execution.MigrationExecution, error) {
+	var exec execution.MigrationExecution
+	err := h.queryRowOn(
+		db,
+		h.selectClause()+h.readColumns()+" FROM `"+
+			h.tableName+"` WHERE `scope` = ? ORDER BY `version` DESC LIMIT 1",
+		h.scope,
+	).Scan(h.readFields(&exec)...)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &exec, nil
+}
+
+func (h *Handler) CountFinished() (int, error) {
+	return h.countFinished(h.db)
+}
+
+func (h *Handler) countFinished(db *sql.DB) (int, error) {
+	var count int
+	err := h.queryRowOn(
+		db,
+		h.selectClause()+"COUNT(*) FROM `"+h.tableName+
+			"` WHERE `scope` = ? AND `finished_at_ms` > 0",
+		h.scope,
+	).Scan(&count)
+	return count, err
+}
+
+// ServerTime See execution.ServerClock. Reads NOW(3), as unix milliseconds, so the session
+// time zone does not matter
+func (h *Handler) ServerTime() (time.Time, error) {
+	var nowMs int64
+	err := h.queryRow(
+		h.statementPrefix + "SELECT CAST(UNIX_TIMESTAMP(NOW(3)) * 1000 AS SIGNED)",
+	).Scan(&nowMs)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the server time with error: %w", err)
+	}
+	return time.UnixMilli(nowMs), nil
+}
+
+func (h *Handler) CheckRead() error {
+	var found int
+	err := h.queryRow(h.selectClause() + "1 FROM `" + h.tableName + "` LIMIT 1").Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func (h *Handler) CheckWrite() error {
+	// Matches no rows, but still requires the update privilege and a writable server
+	_, err := h.exec(
+		"UPDATE `" + h.tableName + "` SET `version` = `version` WHERE 1 = 0",
+	)
+	return err
+}
+
+// executionFields The execution fields to scan, matching mysqlExecutionColumns
+func executionFields(exec *execution.MigrationExecution) []any {
+	return []any{
+		&exec.Version, &exec.ExecutedAtMs, &exec.FinishedAtMs,
+		&exec.Run.DeployID, &exec.Run.GitSHA, &exec.Run.Operator, &exec.SkipReason,
+	}
+}
+
+// readColumns The executions columns to read: mysqlExecutionColumns, followed by the sequence
+// column, if sequences are enabled (see WithSequence)
+func (h *Handler) readColumns() string {
+	if !h.sequence {
+		return mysqlExecutionColumns
+	}
+	return mysqlExecutionColumns + ", `sequence`"
+}
+
+// readFields The execution fields to scan, matching readColumns
+func (h *Handler) readFields(exec *execution.MigrationExecution) []any {
+	if !h.sequence {
+		return executionFields(exec)
+	}
+	return append(executionFields(exec), &exec.Sequence)
+}
+
+// executionValues The scope and the execution values to save, matching the scope column
+// followed by mysqlExecutionColumns
+func (h *Handler) executionValues(exec execution.MigrationExecution) []any {
+	return []any{
+		h.scope, exec.Version, exec.ExecutedAtMs, exec.FinishedAtMs,
+		exec.Run.DeployID, exec.Run.GitSHA, exec.Run.Operator, exec.SkipReason,
+	}
+}
+
+func (h *Handler) LoadProgress(key string) (*execution.Progress, error) {
+	progress := execution.Progress{Key: key}
+	err := h.queryRow(
+		h.selectClause()+"`token`, `done` FROM `"+h.progressTableName()+
+			"` WHERE `key` = ?",
+		h.progressKey(key),
+	).Scan(&progress.Token, &progress.Done)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+func (h *Handler) SaveProgress(progress execution.Progress) error {
+	_, err := h.exec(
+		"INSERT INTO `"+h.progressTableName()+"` (`key`, `token`, `done`) VALUES (?, ?, ?)"+
+			" ON DUPLICATE KEY UPDATE `token` = VALUES(`token`), `done` = VALUES(`done`)",
+		h.progressKey(progress.Key), progress.Token, progress.Done,
+	)
+	return err
+}
+
+func (h *Handler) AppendAudit(entry execution.AuditEntry) error {
+	args, err := json.Marshal(entry.Args)
+	if err != nil {
+		return fmt.Errorf("failed to encode the audit entry args with error: %w", err)
+	}
+
+	workDirs, err := json.Marshal(entry.WorkDirs)
+	if err != nil {
+		return fmt.Errorf("failed to encode the audit entry work dirs with error: %w", err)
+	}
+
+	_, err = h.exec(
+		"INSERT INTO `"+h.auditTableName()+"` (`time_ms`, `command`, `args`, `user`, `host`,"+
+			" `operator`, `result`, `error`, `duration_ms`, `work_dirs`)"+
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.Time.UnixMilli(), entry.Command, string(args), entry.User, entry.Host,
+		entry.Operator, entry.Result, entry.Error, entry.DurationMs, string(workDirs),
+	)
+	return err
+}
+
+func (h *Handler) AddNote(note execution.Note) error {
+	_, err := h.exec(
+		"INSERT INTO `"+h.notesTableName()+"` (`scope`, `version`, `executed_at_ms`, `text`,"+
+			" `author`, `created_at_ms`) VALUES (?, ?, ?, ?, ?, ?)",
+		h.scope, note.Version, note.ExecutedAtMs, note.Text, note.Author, note.CreatedAtMs,
+	)
+	return err
+}
+
+func (h *Handler) LoadNotes() (notes []execution.Note, err error) {
+	ctx, cancel := h.queryContext()
+	defer cancel()
+	defer func() { err = h.timeoutErr(ctx, err) }()
+
+	rows, err := h.db.QueryContext(
+		ctx,
+		h.selectClause()+"`version`, `executed_at_ms`, `text`, `author`, `created_at_ms` FROM `"+
+			h.notesTableName()+"` WHERE `scope` = ? ORDER BY `id`",
+		h.scope,
+	)
+
+	if err != nil {
+		return notes, err
+	}
+
+	defer func(rows *sql.Rows) {
+		if closeErr := rows.Close(); closeErr != nil && err != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var note execution.Note
+		err = rows.Scan(
+			&note.Version, &note.ExecutedAtMs, &note.Text, &note.Author, &note.CreatedAtMs,
+		)
+		if err != nil {
+			return notes, err
+		}
+		notes = append(notes, note)
+	}
+
+	err = rows.Err()
+	return notes, err
+}
@@ -0,0 +1,668 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+const DnsEnv = "MYSQL_DSN"
+const DbNameEnv = "MYSQL_DATABASE"
+const ExecutionsTable = "migration_executions"
+
+type MysqlTestSuite struct {
+	suite.Suite
+	dbName  string
+	dsn     string
+	db      *sql.DB
+	handler *Handler
+}
+
+func TestMysqlTestSuite(t *testing.T) {
+	suite.Run(t, new(MysqlTestSuite))
+}
+
+func (suite *MysqlTestSuite) SetupSuite() {
+	suite.dbName = os.Getenv(DbNameEnv)
+	suite.dsn = os.Getenv(DnsEnv)
+
+	if suite.dbName == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dbName = "migrations"
+	}
+
+	if suite.dsn == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dsn = "root:123456789@tcp(localhost:3306)/" + suite.dbName
+	}
+
+	tmpDb, _ := sql.Open("mysql", strings.TrimRight(suite.dsn, suite.dbName))
+	_, _ = tmpDb.Exec("DROP DATABASE IF EXISTS " + suite.dbName)
+	_, _ = tmpDb.Exec("CREATE DATABASE " + suite.dbName)
+	_ = tmpDb.Close()
+
+	suite.handler, _ = NewHandler(suite.dsn, ExecutionsTable, context.Background(), nil)
+	suite.db = suite.handler.db
+}
+
+func (suite *MysqlTestSuite) TearDownSuite() {
+	_, _ = suite.db.Exec("DROP DATABASE IF EXISTS " + suite.dbName)
+	_ = suite.db.Close()
+}
+
+func (suite *MysqlTestSuite) SetupTest() {
+	_ = suite.handler.Init()
+	_, _ = suite.db.Exec("DELETE FROM " + ExecutionsTable)
+}
+
+func (suite *MysqlTestSuite) TearDownTest() {
+	_, _ = suite.db.Exec("DELETE FROM " + ExecutionsTable)
+}
+
+func (suite *MysqlTestSuite) TestItCanBuildMigrationsExclusiveDbHandle() {
+	handle, err := newMysqlDbHandle(suite.dsn)
+
+	suite.Assert().Nil(err)
+	suite.Assert().Equal(1, handle.Stats().MaxOpenConnections)
+
+	var dbName string
+	_ = handle.QueryRow("select database()").Scan(&dbName)
+	suite.Assert().Equal(suite.dbName, dbName)
+}
+
+func (suite *MysqlTestSuite) TestItCanBuildHandlerWithProvidedContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler, err := NewHandler(suite.dsn, "migration_execs", ctx, nil)
+	suite.Assert().Nil(err)
+	suite.Assert().Same(ctx, handler.Context())
+}
+
+func (suite *MysqlTestSuite) TestItCanInitializeExecutionsTable() {
+	_, _ = suite.db.Exec("DROP TABLE IF EXISTS " + ExecutionsTable)
+	tableExists := func() bool {
+		var table string
+		_ = suite.db.QueryRow("SHOW TABLES LIKE '" + ExecutionsTable + "'").Scan(&table)
+		return table == ExecutionsTable
+	}
+
+	suite.Assert().False(tableExists())
+	_ = suite.handler.Init()
+	suite.Assert().True(tableExists())
+}
+
+func (suite *MysqlTestSuite) TestItAddsMissingColumnsToExistingExecutionsTable() {
+	_, _ = suite.db.Exec("DROP TABLE IF EXISTS " + ExecutionsTable)
+	_, _ = suite.db.Exec(
+		"CREATE TABLE `" + ExecutionsTable + "` (" +
+			"`version` BIGINT UNSIGNED NOT NULL," +
+			"`executed_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`finished_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"PRIMARY KEY (`version`))",
+	)
+	_, _ = suite.db.Exec("insert into " + ExecutionsTable + " values (1, 2, 3)")
+
+	suite.Assert().NoError(suite.handler.Init())
+	suite.Assert().NoError(suite.handler.Init())
+
+	executions, err := suite.handler.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}}, executions,
+	)
+	suite.Assert().Equal(layoutComment(len(mysqlLayoutUpgrades)), suite.tableComment())
+}
+
+func (suite *MysqlTestSuite) TestItUpgradesExecutionsTableFromRecordedLayoutVersion() {
+	_, _ = suite.db.Exec("DROP TABLE IF EXISTS " + ExecutionsTable)
+	_, _ = suite.db.Exec(
+		"CREATE TABLE `" + ExecutionsTable + "` (" +
+			"`version` BIGINT UNSIGNED NOT NULL," +
+			"`executed_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`finished_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`deploy_id` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`git_sha` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`operator` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"PRIMARY KEY (`version`)) COMMENT = '" + layoutComment(3) + "'",
+	)
+
+	suite.Assert().NoError(suite.handler.Init())
+	suite.Assert().Equal(layoutComment(len(mysqlLayoutUpgrades)), suite.tableComment())
+
+	exec := execution.MigrationExecution{
+		Version: 1, ExecutedAtMs: 2, FinishedAtMs: 2, SkipReason: "superseded by 2",
+	}
+	suite.Assert().NoError(suite.handler.Save(exec))
+	foundExec, _ := suite.handler.FindOne(1)
+	suite.Assert().Equal(&exec, foundExec)
+}
+
+func (suite *MysqlTestSuite) TestItAddsIndexesToExecutionsTablesCreatedWithoutThem() {
+	_, _ = suite.db.Exec("DROP TABLE IF EXISTS " + ExecutionsTable)
+	_, _ = suite.db.Exec(
+		"CREATE TABLE `" + ExecutionsTable + "` (" +
+			"`version` BIGINT UNSIGNED NOT NULL," +
+			"`executed_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`finished_at_ms` BIGINT UNSIGNED NOT NULL," +
+			"`deploy_id` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`git_sha` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`operator` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`skip_reason` VARCHAR(1024) NOT NULL DEFAULT ''," +
+			"PRIMARY KEY (`version`)," +
+			"KEY `idx_executed_at_ms` (`executed_at_ms`)) COMMENT = '" + layoutComment(4) + "'",
+	)
+
+	suite.Assert().NoError(suite.handler.Init())
+	suite.Assert().NoError(suite.handler.Init())
+	suite.Assert().Equal(layoutComment(len(mysqlLayoutUpgrades)), suite.tableComment())
+	suite.Assert().Equal(
+		[]string{"PRIMARY", "idx_executed_at_ms", "idx_finished_at_ms"}, suite.tableIndexes(),
+	)
+}
+
+func (suite *MysqlTestSuite) TestItCreatesExecutionsTableWithIndexes() {
+	_, _ = suite.db.Exec("DROP TABLE IF EXISTS " + ExecutionsTable)
+
+	suite.Assert().NoError(suite.handler.Init())
+	suite.Assert().Equal(
+		[]string{"PRIMARY", "idx_executed_at_ms", "idx_finished_at_ms"}, suite.tableIndexes(),
+	)
+}
+
+func (suite *MysqlTestSuite) tableIndexes() []string {
+	rows, err := suite.db.Query(
+		"SELECT DISTINCT `INDEX_NAME` FROM `information_schema`.`STATISTICS`"+
+			" WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ? ORDER BY `INDEX_NAME`",
+		ExecutionsTable,
+	)
+	suite.Require().NoError(err)
+	defer func() { _ = rows.Close() }()
+
+	var indexes []string
+	for rows.Next() {
+		var index string
+		suite.Require().NoError(rows.Scan(&index))
+		indexes = append(indexes, index)
+	}
+	return indexes
+}
+
+func (suite *MysqlTestSuite) TestItKeepsTheExecutionsOfScopesApart() {
+	billing, _ := NewHandler(
+		suite.dsn, ExecutionsTable, context.Background(), suite.db, WithScope("billing"),
+	)
+	unscopedExec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+	billingExec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 4}
+
+	suite.Require().NoError(suite.handler.Save(unscopedExec))
+	suite.Require().NoError(billing.SaveIf(billingExec, nil))
+
+	executions, _ := billing.LoadExecutions()
+	suite.Assert().Equal([]execution.MigrationExecution{billingExec}, executions)
+	executions, _ = suite.handler.LoadExecutions()
+	suite.Assert().Equal([]execution.MigrationExecution{unscopedExec}, executions)
+
+	finished, _ := billing.CountFinished()
+	suite.Assert().Equal(0, finished)
+	latest, _ := billing.LatestExecution()
+	suite.Assert().Equal(&billingExec, latest)
+
+	suite.Assert().NoError(billing.Remove(billingExec))
+	found, _ := billing.FindOne(1)
+	suite.Assert().Nil(found)
+	found, _ = suite.handler.FindOne(1)
+	suite.Assert().Equal(&unscopedExec, found)
+}
+
+func (suite *MysqlTestSuite) TestItReadsTheServerTime() {
+	serverTime, err := suite.handler.ServerTime()
+
+	suite.Assert().NoError(err)
+	suite.Assert().WithinDuration(time.Now(), serverTime, time.Minute)
+}
+
+func (suite *MysqlTestSuite) TestItAssignsSequencesToExecutions() {
+	_, _ = suite.db.Exec("DROP TABLE IF EXISTS " + ExecutionsTable)
+	suite.Require().NoError(suite.handler.Init())
+	suite.Require().NoError(
+		suite.handler.Save(execution.MigrationExecution{Version: 5, ExecutedAtMs: 1}),
+	)
+	sequenced, _ := NewHandler(
+		suite.dsn, ExecutionsTable, context.Background(), suite.db, WithSequence(),
+	)
+
+	suite.Require().NoError(sequenced.Init())
+	suite.Require().NoError(sequenced.Init())
+	suite.Require().NoError(sequenced.Save(execution.MigrationExecution{Version: 1}))
+	suite.Require().NoError(
+		sequenced.Save(execution.MigrationExecution{Version: 1, FinishedAtMs: 2}),
+	)
+	suite.Require().NoError(sequenced.SaveIf(execution.MigrationExecution{Version: 3}, nil))
+
+	executions, err := sequenced.LoadExecutions()
+	suite.Assert().NoError(err)
+	execution.SortHistory(executions)
+	suite.Assert().Len(executions, 3)
+	suite.Assert().Equal([]uint64{5, 1, 3}, []uint64{
+		executions[0].Version, executions[1].Version, executions[2].Version,
+	})
+	suite.Assert().Less(executions[0].Sequence, executions[1].Sequence)
+	suite.Assert().Less(executions[1].Sequence, executions[2].Sequence)
+
+	found, _ := sequenced.FindOne(1)
+	suite.Assert().Equal(executions[1], *found)
+	latest, _ := sequenced.LatestExecution()
+	suite.Assert().Equal(executions[0], *latest)
+}
+
+func (suite *MysqlTestSuite) TestItReadsExecutionsFromTheReadReplica() {
+	replicaName := suite.dbName + "_replica"
+	_, _ = suite.db.Exec("CREATE DATABASE IF NOT EXISTS " + replicaName)
+	defer func() { _, _ = suite.db.Exec("DROP DATABASE IF EXISTS " + replicaName) }()
+
+	replicaDSN := strings.TrimSuffix(suite.dsn, suite.dbName) + replicaName
+	replica, err := NewHandler(replicaDSN, ExecutionsTable, context.Background(), nil)
+	suite.Require().NoError(err)
+	suite.Require().NoError(replica.Init())
+	suite.Require().NoError(replica.Save(execution.MigrationExecution{Version: 7}))
+
+	handler, err := NewHandler(
+		suite.dsn, ExecutionsTable, context.Background(), suite.db,
+		WithReadReplica(replicaDSN, nil, time.Second),
+	)
+	suite.Require().NoError(err)
+
+	replicaRepo := handler.ReplicaRepository()
+
+	// The handler itself always reads from the primary
+	executions, err := handler.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(executions)
+
+	// Not replicating, so the replica is not considered stale
+	executions, err = replicaRepo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]execution.MigrationExecution{{Version: 7}}, executions)
+	found, err := replicaRepo.FindOne(7)
+	suite.Assert().NoError(err)
+	suite.Assert().NotNil(found)
+	summaryReader := replicaRepo.(execution.SummaryReader)
+	latest, err := summaryReader.LatestExecution()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&execution.MigrationExecution{Version: 7}, latest)
+	count, err := summaryReader.CountFinished()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(0, count)
+
+	// Reads its own writes
+	suite.Require().NoError(handler.Save(execution.MigrationExecution{Version: 1}))
+	executions, err = replicaRepo.LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]execution.MigrationExecution{{Version: 1}}, executions)
+	found, err = replicaRepo.FindOne(7)
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(found)
+
+	// Lagging more than allowed
+	lagging, _ := NewHandler(
+		suite.dsn, ExecutionsTable, context.Background(), suite.db,
+		WithReadReplica("", replica.db, -time.Second),
+	)
+	executions, err = lagging.ReplicaRepository().LoadExecutions()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]execution.MigrationExecution{{Version: 1}}, executions)
+}
+
+func (suite *MysqlTestSuite) tableComment() string {
+	var comment string
+	_ = suite.db.QueryRow(
+		"SELECT `TABLE_COMMENT` FROM `information_schema`.`TABLES`"+
+			" WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?",
+		ExecutionsTable,
+	).Scan(&comment)
+	return comment
+}
+
+func (suite *MysqlTestSuite) TestItCanSaveExecutionsRunMetadata() {
+	exec := execution.MigrationExecution{
+		Version: 1, ExecutedAtMs: 2,
+		Run: execution.RunMetadata{DeployID: "deploy-12", GitSHA: "a1b2c3", Operator: "jane"},
+	}
+	finished := exec
+	finished.FinishedAtMs = 3
+
+	suite.Assert().NoError(suite.handler.SaveIf(exec, nil))
+	foundExec, _ := suite.handler.FindOne(1)
+	suite.Assert().Equal(&exec, foundExec)
+
+	suite.Assert().NoError(suite.handler.SaveIf(finished, &exec))
+	foundExec, _ = suite.handler.FindOne(1)
+	suite.Assert().Equal(&finished, foundExec)
+
+	finished.Run.Operator = "john"
+	suite.Assert().NoError(suite.handler.Save(finished))
+	executions, _ := suite.handler.LoadExecutions()
+	suite.Assert().Equal([]execution.MigrationExecution{finished}, executions)
+}
+
+func (suite *MysqlTestSuite) TestItCanSaveSkippedExecutions() {
+	exec := execution.MigrationExecution{
+		Version: 1, ExecutedAtMs: 2, FinishedAtMs: 2, SkipReason: "superseded by 2",
+	}
+
+	suite.Assert().NoError(suite.handler.Save(exec))
+	foundExec, _ := suite.handler.FindOne(1)
+	suite.Assert().Equal(&exec, foundExec)
+	suite.Assert().True(foundExec.Skipped())
+}
+
+func executionsProvider() map[uint64]execution.MigrationExecution {
+	return map[uint64]execution.MigrationExecution{
+		uint64(1): {Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+		uint64(4): {Version: 4, ExecutedAtMs: 5, FinishedAtMs: 6},
+		uint64(7): {Version: 7, ExecutedAtMs: 8, FinishedAtMs: 9},
+	}
+}
+
+func (suite *MysqlTestSuite) TestItCanLoadExecutions() {
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		_, _ = suite.db.Exec(
+			"insert into " + ExecutionsTable +
+				" (version, executed_at_ms, finished_at_ms) values (" +
+				strconv.Itoa(int(exec.Version)) + "," +
+				strconv.Itoa(int(exec.ExecutedAtMs)) + "," +
+				strconv.Itoa(int(exec.FinishedAtMs)) + ")",
+		)
+	}
+
+	loadedExecs, err := suite.handler.LoadExecutions()
+
+	suite.Assert().NoError(err)
+	for _, exec := range loadedExecs {
+		suite.Assert().Contains(executions, exec.Version)
+		suite.Assert().Equal(executions[exec.Version], exec)
+		delete(executions, exec.Version)
+	}
+	suite.Assert().Len(executions, 0)
+}
+
+func (suite *MysqlTestSuite) TestItFailsToExecuteAnyChangesWhenMissingTable() {
+	_, _ = suite.db.Exec("drop table `" + suite.handler.tableName + "`")
+	migrationExecution := execution.StartExecution(migration.NewDummyMigration(123))
+	_, errLoad := suite.handler.LoadExecutions()
+	errSave := suite.handler.Save(*migrationExecution)
+	errRemove := suite.handler.Remove(*migrationExecution)
+	_, errFindOne := suite.handler.FindOne(uint64(123))
+
+	suite.Assert().Error(errLoad)
+	suite.Assert().ErrorContains(errLoad, suite.handler.tableName)
+	suite.Assert().Error(errSave)
+	suite.Assert().ErrorContains(errSave, suite.handler.tableName)
+	suite.Assert().Error(errRemove)
+	suite.Assert().ErrorContains(errRemove, suite.handler.tableName)
+	suite.Assert().Error(errFindOne)
+	suite.Assert().ErrorContains(errFindOne, suite.handler.tableName)
+}
+
+func (suite *MysqlTestSuite) TestItFailsToLoadExecutionsFromInvalidRepoData() {
+	_, _ = suite.db.Exec(
+		"alter table `" + suite.handler.tableName +
+			"` modify column `finished_at_ms` bigint unsigned default null",
+	)
+	_, _ = suite.db.Exec(
+		"insert into `" + suite.handler.tableName +
+			"` (version, executed_at_ms, finished_at_ms) values (1,2,1), (3,4,null)",
+	)
+	execs, err := suite.handler.LoadExecutions()
+	suite.Assert().Len(execs, 1)
+	suite.Assert().Error(err)
+	suite.Assert().ErrorContains(err, "Scan error")
+}
+
+func (suite *MysqlTestSuite) TestItCanSaveExecutions() {
+	// Insert
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		err := suite.handler.Save(exec)
+		suite.Assert().NoError(err)
+	}
+
+	savedExecs, _ := suite.handler.LoadExecutions()
+	for _, exec := range savedExecs {
+		suite.Assert().Contains(executions, exec.Version)
+		suite.Assert().Equal(executions[exec.Version], exec)
+	}
+
+	// Update
+	for i, exec := range executions {
+		exec.FinishedAtMs++
+		exec.ExecutedAtMs++
+		executions[i] = exec
+		err := suite.handler.Save(executions[i])
+		suite.Assert().NoError(err)
+	}
+
+	savedExecs, _ = suite.handler.LoadExecutions()
+	for _, exec := range savedExecs {
+		suite.Assert().Contains(executions, exec.Version)
+		suite.Assert().Equal(executions[exec.Version], exec)
+	}
+}
+
+func (suite *MysqlTestSuite) TestItCanRemoveExecution() {
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		_ = suite.handler.Save(exec)
+		err := suite.handler.Remove(exec)
+		suite.Assert().NoError(err)
+	}
+
+	savedExecs, _ := suite.handler.LoadExecutions()
+
+	suite.Assert().Len(savedExecs, 0)
+}
+
+func (suite *MysqlTestSuite) TestItCanFindOne() {
+	executions := executionsProvider()
+
+	for _, exec := range executions {
+		_, _ = suite.db.Exec(
+			"insert into " + ExecutionsTable +
+				" (version, executed_at_ms, finished_at_ms) values (" +
+				strconv.Itoa(int(exec.Version)) + "," +
+				strconv.Itoa(int(exec.ExecutedAtMs)) + "," +
+				strconv.Itoa(int(exec.FinishedAtMs)) + ")",
+		)
+	}
+
+	execToFind := executions[uint64(4)]
+	foundExec, err := suite.handler.FindOne(uint64(4))
+	suite.Assert().Equal(&execToFind, foundExec)
+	suite.Assert().Nil(err)
+	_, _ = suite.db.Exec("delete from `" + suite.handler.tableName + "`")
+	foundExec, err = suite.handler.FindOne(uint64(4))
+	suite.Assert().Nil(foundExec)
+	suite.Assert().Nil(err)
+}
+
+func (suite *MysqlTestSuite) TestItCanSaveExecutionsConditionally() {
+	exec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 0}
+	finished := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+
+	suite.Assert().NoError(suite.handler.SaveIf(exec, nil))
+	suite.Assert().ErrorIs(suite.handler.SaveIf(exec, nil), execution.ErrExecutionConflict)
+	suite.Assert().NoError(suite.handler.SaveIf(finished, &exec))
+	suite.Assert().ErrorIs(
+		suite.handler.SaveIf(finished, &exec), execution.ErrExecutionConflict,
+	)
+	// Saving the expected values again changes no row, but is not a conflict
+	suite.Assert().NoError(suite.handler.SaveIf(finished, &finished))
+
+	foundExec, _ := suite.handler.FindOne(1)
+	suite.Assert().Equal(&finished, foundExec)
+}
+
+func (suite *MysqlTestSuite) TestItCanReadExecutionsSummary() {
+	latest, err := suite.handler.LatestExecution()
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(latest)
+
+	for _, exec := range executionsProvider() {
+		_ = suite.handler.Save(exec)
+	}
+	_ = suite.handler.Save(execution.MigrationExecution{Version: 9, ExecutedAtMs: 10})
+
+	latest, err = suite.handler.LatestExecution()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(&execution.MigrationExecution{Version: 9, ExecutedAtMs: 10}, latest)
+
+	count, err := suite.handler.CountFinished()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(3, count)
+}
+
+func (suite *MysqlTestSuite) TestItCanCheckRepositoryAccess() {
+	suite.Assert().NoError(suite.handler.CheckRead())
+	suite.Assert().NoError(suite.handler.CheckWrite())
+
+	_, _ = suite.db.Exec("drop table `" + suite.handler.tableName + "`")
+
+	suite.Assert().ErrorContains(suite.handler.CheckRead(), suite.handler.tableName)
+	suite.Assert().ErrorContains(suite.handler.CheckWrite(), suite.handler.tableName)
+}
+
+func (suite *MysqlTestSuite) TestItCanSaveAndLoadProgress() {
+	_, _ = suite.db.Exec("DELETE FROM `" + suite.handler.progressTableName() + "`")
+
+	progress, err := suite.handler.LoadProgress("users_backfill")
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(progress)
+
+	suite.Assert().NoError(
+		suite.handler.SaveProgress(execution.Progress{Key: "users_backfill", Token: "10"}),
+	)
+	suite.Assert().NoError(
+		suite.handler.SaveProgress(
+			execution.Progress{Key: "users_backfill", Token: "20", Done: true},
+		),
+	)
+
+	progress, err = suite.handler.LoadProgress("users_backfill")
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		&execution.Progress{Key: "users_backfill", Token: "20", Done: true}, progress,
+	)
+}
+
+func (suite *MysqlTestSuite) TestItCanAddAndLoadNotes() {
+	_, _ = suite.db.Exec("DELETE FROM `" + suite.handler.notesTableName() + "`")
+	notes := []execution.Note{
+		{
+			Version: 2, ExecutedAtMs: 1717235000000, Text: "backfill verified", Author: "jane",
+			CreatedAtMs: 1717236000000,
+		},
+		{Version: 1, Text: "index rebuilt", CreatedAtMs: 1717236001000},
+	}
+
+	for _, note := range notes {
+		suite.Assert().NoError(suite.handler.AddNote(note))
+	}
+
+	loaded, err := suite.handler.LoadNotes()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(notes, loaded)
+}
+
+func (suite *MysqlTestSuite) TestItCanAppendAuditEntries() {
+	_, _ = suite.db.Exec("DELETE FROM `" + suite.handler.auditTableName() + "`")
+	entry := execution.AuditEntry{
+		Time:       time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		Command:    "down",
+		Args:       []string{"down", "--operator=jane"},
+		User:       "deployer",
+		Host:       "ci-1",
+		Operator:   "jane",
+		Result:     execution.AuditResultFailure,
+		Error:      "mig err",
+		DurationMs: 1500,
+		WorkDirs:   []string{"/tmp/go-migrations-2-down-1"},
+	}
+
+	suite.Assert().NoError(suite.handler.AppendAudit(entry))
+	suite.Assert().NoError(suite.handler.AppendAudit(entry))
+
+	var timeMs, durationMs int64
+	var command, args, operator, result, errMsg, workDirs string
+	suite.Require().NoError(
+		suite.db.QueryRow(
+			"SELECT `time_ms`, `command`, `args`, `operator`, `result`, `error`, `duration_ms`,"+
+				" `work_dirs` FROM `"+suite.handler.auditTableName()+"` ORDER BY `id` LIMIT 1",
+		).Scan(&timeMs, &command, &args, &operator, &result, &errMsg, &durationMs, &workDirs),
+	)
+	suite.Assert().Equal(entry.Time.UnixMilli(), timeMs)
+	suite.Assert().Equal("down", command)
+	suite.Assert().JSONEq(`["down","--operator=jane"]`, args)
+	suite.Assert().Equal("jane", operator)
+	suite.Assert().Equal(execution.AuditResultFailure, result)
+	suite.Assert().Equal("mig err", errMsg)
+	suite.Assert().Equal(int64(1500), durationMs)
+	suite.Assert().JSONEq(`["/tmp/go-migrations-2-down-1"]`, workDirs)
+
+	var count int
+	_ = suite.db.QueryRow("SELECT COUNT(*) FROM `" + suite.handler.auditTableName() + "`").
+		Scan(&count)
+	suite.Assert().Equal(2, count)
+}
+
+type QueryTimeoutTestSuite struct {
+	suite.Suite
+}
+
+func TestQueryTimeoutTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryTimeoutTestSuite))
+}
+
+func (suite *QueryTimeoutTestSuite) TestItFailsQueriesWhichDoNotFinishInTime() {
+	// Accepts connections, but never answers, like a hung server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			if _, err := listener.Accept(); err != nil {
+				return
+			}
+		}
+	}()
+
+	handler, err := NewHandler(
+		"root@tcp("+listener.Addr().String()+")/app", ExecutionsTable, context.Background(), nil,
+		WithQueryTimeout(50*time.Millisecond),
+	)
+	suite.Require().NoError(err)
+
+	start := time.Now()
+	_, err = handler.FindOne(1)
+	suite.Assert().ErrorIs(err, execution.ErrQueryTimeout)
+	suite.Assert().ErrorContains(err, "after 50ms")
+	suite.Assert().Less(time.Since(start), 5*time.Second)
+
+	_, err = handler.LoadExecutions()
+	suite.Assert().ErrorIs(err, execution.ErrQueryTimeout)
+	err = handler.Save(execution.MigrationExecution{Version: 1})
+	suite.Assert().ErrorIs(err, execution.ErrQueryTimeout)
+}
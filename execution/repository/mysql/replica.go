@@ -0,0 +1,154 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// readReplica The replica serving the executions reads (see WithReadReplica)
+type readReplica struct {
+	dsn    string
+	db     *sql.DB
+	maxLag time.Duration
+
+	// wrote Set after the handler saved or removed an execution, from then on the executions
+	// are read from the primary
+	wrote bool
+}
+
+// WithReadReplica Sends the executions reads (LoadExecutions, FindOne, LatestExecution and
+// CountFinished) of the replica repository (see ReplicaRepository) to a read replica, so large
+// fleets running frequent status checks do not load the primary. The replica repository is used
+// only by read-only handlers (see execution.ReplicaReader), the handler itself always reads from
+// the primary. If db is nil, the replica handle is built from replicaDSN (see NewHandler). Stale
+// reads are guarded against: the primary is read instead when the replica lags more than maxLag
+// (Seconds_Behind_Source, so the lag is checked with a resolution of one second), when its
+// replication is stopped or its lag can not be read (for example, without the REPLICATION CLIENT
+// privilege) and after the handler saved or removed executions, so it always reads its own
+// writes. Servers without replication status (for example, Aurora readers) are not considered
+// stale.
+func WithReadReplica(replicaDSN string, db *sql.DB, maxLag time.Duration) Option {
+	return func(h *Handler) {
+		h.replica = &readReplica{dsn: replicaDSN, db: db, maxLag: maxLag}
+	}
+}
+
+// openReplica Builds the replica db handle, if a replica without a handle is configured
+func (h *Handler) openReplica() error {
+	if h.replica == nil || h.replica.db != nil {
+		return nil
+	}
+
+	db, err := newMysqlDbHandle(h.replica.dsn)
+	if err != nil {
+		return fmt.Errorf("failed to build the read replica db handle with error: %w", err)
+	}
+	h.replica.db = db
+	return nil
+}
+
+// replicaRepository The handler, with the executions reads served by the read replica. The
+// notes and the lock holder are still read from the primary.
+type replicaRepository struct {
+	*Handler
+}
+
+// ReplicaRepository See execution.ReplicaReader. Without a read replica, the executions are read
+// from the primary.
+func (h *Handler) ReplicaRepository() execution.Repository {
+	return &replicaRepository{h}
+}
+
+func (repo *replicaRepository) LoadExecutions() ([]execution.MigrationExecution, error) {
+	return repo.loadExecutions(repo.readDB())
+}
+
+func (repo *replicaRepository) FindOne(version uint64) (*execution.MigrationExecution, error) {
+	return repo.findOne(repo.readDB(), version)
+}
+
+func (repo *replicaRepository) LatestExecution() (*execution.MigrationExecution, error) {
+	return repo.latestExecution(repo.readDB())
+}
+
+func (repo *replicaRepository) CountFinished() (int, error) {
+	return repo.countFinished(repo.readDB())
+}
+
+// markWritten Makes the executions reads go to the primary, after the handler writes executions
+func (h *Handler) markWritten() {
+	if h.replica != nil {
+		h.replica.wrote = true
+	}
+}
+
+// readDB The handle serving the executions reads: the replica, if configured and fresh enough
+// (see WithReadReplica), otherwise the primary
+func (h *Handler) readDB() *sql.DB {
+	if h.replica == nil || h.replica.wrote {
+		return h.db
+	}
+
+	lag, err := h.replicaLag()
+	if err != nil || lag > h.replica.maxLag {
+		return h.db
+	}
+	return h.replica.db
+}
+
+// replicaLag Reads the replication lag of the replica, from SHOW REPLICA STATUS (or SHOW SLAVE
+// STATUS, for servers older than Mysql 8.0.22 and MariaDB 10.5.1). Zero is returned if the
+// server has no replication status.
+func (h *Handler) replicaLag() (time.Duration, error) {
+	lag, err := h.replicaStatusLag("SHOW REPLICA STATUS")
+	if err != nil {
+		lag, err = h.replicaStatusLag("SHOW SLAVE STATUS")
+	}
+	return lag, err
+}
+
+func (h *Handler) replicaStatusLag(statement string) (lag time.Duration, err error) {
+	ctx, cancel := h.queryContext()
+	defer cancel()
+	defer func() { err = h.timeoutErr(ctx, err) }()
+
+	rows, err := h.replica.db.QueryContext(ctx, statement)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = errors.Join(err, rows.Close()) }()
+
+	columns, err := rows.Columns()
+	if err != nil || !rows.Next() {
+		return 0, errors.Join(err, rows.Err())
+	}
+
+	values := make([]sql.NullString, len(columns))
+	fields := make([]any, len(columns))
+	for i := range values {
+		fields[i] = &values[i]
+	}
+	if err = rows.Scan(fields...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, errors.New("the read replica is not replicating")
+		}
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid read replica lag %q: %w", values[i].String, err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, errors.New("the read replica status has no lag column")
+}
@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TiDBSettings Optional TiDBHandler behaviour
+type TiDBSettings struct {
+	// DDLPollInterval How often the pending DDL jobs are checked. Defaults to 1 second
+	DDLPollInterval time.Duration
+
+	// DDLTimeout How long to wait for the pending DDL jobs. Defaults to 1 hour
+	DDLTimeout time.Duration
+
+	// DeleteBatchSize The number of rows deleted per statement by DeleteInBatches. Defaults
+	// to 1000
+	DeleteBatchSize int
+}
+
+// TiDBHandler Repository implementation for TiDB. It works as the Handler, but creates
+// tables without the Mysql specific options and reads without the Mysql specific hints. TiDB
+// runs DDL statements as asynchronous jobs, so the handler implements
+// execution.AsyncChangesWaiter, making the migrations handler wait for the pending DDL jobs
+// (see ADMIN SHOW DDL) before marking a migration finished.
+type TiDBHandler struct {
+	*Handler
+	settings TiDBSettings
+}
+
+// NewTiDBHandler Builds a new TiDBHandler. If db is nil, it will try to build a db handle from
+// the provided dsn (see NewHandler)
+func NewTiDBHandler(
+	dsn string,
+	tableName string,
+	ctx context.Context,
+	db *sql.DB,
+	settings TiDBSettings,
+	options ...Option,
+) (*TiDBHandler, error) {
+	mysqlHandler, err := NewHandler(dsn, tableName, ctx, db, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.DDLPollInterval == 0 {
+		settings.DDLPollInterval = time.Second
+	}
+
+	if settings.DDLTimeout == 0 {
+		settings.DDLTimeout = time.Hour
+	}
+
+	if settings.DeleteBatchSize == 0 {
+		settings.DeleteBatchSize = 1000
+	}
+
+	mysqlHandler.selectHints = ""
+	mysqlHandler.tableOptions = ""
+
+	return &TiDBHandler{Handler: mysqlHandler, settings: settings}, nil
+}
+
+// WaitForChanges See execution.AsyncChangesWaiter. Polls ADMIN SHOW DDL until there are no
+// running DDL jobs
+func (h *TiDBHandler) WaitForChanges() error {
+	ctx, cancel := context.WithTimeout(h.ctx, h.settings.DDLTimeout)
+	defer cancel()
+
+	for {
+		runningJobs, err := h.runningDDLJobs(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check the running DDL jobs with error: %w", err)
+		}
+
+		if runningJobs == "" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"DDL jobs are still running after %s: %s, error: %w",
+				h.settings.DDLTimeout, runningJobs, ctx.Err(),
+			)
+		case <-time.After(h.settings.DDLPollInterval):
+		}
+	}
+}
+
+// runningDDLJobs Returns the RUNNING_JOBS column of ADMIN SHOW DDL, empty if no job is running
+func (h *TiDBHandler) runningDDLJobs(ctx context.Context) (runningJobs string, err error) {
+	rows, err := h.db.QueryContext(ctx, "ADMIN SHOW DDL")
+	if err != nil {
+		return "", err
+	}
+
+	defer func(rows *sql.Rows) {
+		err = errors.Join(err, rows.Close())
+	}(rows)
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]sql.NullString, len(columns))
+	fields := make([]any, len(columns))
+	for i := range values {
+		fields[i] = &values[i]
+	}
+
+	if rows.Next() {
+		if err = rows.Scan(fields...); err != nil {
+			return "", err
+		}
+
+		for i, column := range columns {
+			if column == "RUNNING_JOBS" {
+				runningJobs = values[i].String
+			}
+		}
+	}
+
+	return runningJobs, rows.Err()
+}
+
+// DeleteInBatches Deletes the rows of the table which match the where condition, in batches of
+// TiDBSettings.DeleteBatchSize rows, so large deletes do not exceed the TiDB transaction size
+// limits. Can be used with the migrations db handle (db). Returns the number of deleted rows.
+func (h *TiDBHandler) DeleteInBatches(
+	db *sql.DB,
+	table string,
+	where string,
+	args ...any,
+) (int64, error) {
+	var deleted int64
+	query := fmt.Sprintf(
+		"DELETE FROM `%s` WHERE %s LIMIT %d", table, where, h.settings.DeleteBatchSize,
+	)
+
+	for {
+		result, err := db.ExecContext(h.ctx, query, args...)
+		if err != nil {
+			return deleted, fmt.Errorf(
+				"failed to delete from %s after %d rows with error: %w", table, deleted, err,
+			)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += affected
+		if affected < int64(h.settings.DeleteBatchSize) {
+			return deleted, nil
+		}
+	}
+}
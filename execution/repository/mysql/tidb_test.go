@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+const TiDBDnsEnv = "TIDB_DSN"
+
+type TiDBTestSuite struct {
+	suite.Suite
+	dbName  string
+	dsn     string
+	db      *sql.DB
+	handler *TiDBHandler
+}
+
+func TestTiDBTestSuite(t *testing.T) {
+	suite.Run(t, new(TiDBTestSuite))
+}
+
+func (suite *TiDBTestSuite) SetupSuite() {
+	suite.dbName = os.Getenv(DbNameEnv)
+	suite.dsn = os.Getenv(TiDBDnsEnv)
+
+	if suite.dbName == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dbName = "migrations"
+	}
+
+	if suite.dsn == "" {
+		// Needed if tests are ran on the host not docker
+		suite.dsn = "root@tcp(localhost:4000)/" + suite.dbName
+	}
+
+	tmpDb, _ := sql.Open("mysql", strings.TrimRight(suite.dsn, suite.dbName))
+	_, _ = tmpDb.Exec("DROP DATABASE IF EXISTS " + suite.dbName)
+	_, _ = tmpDb.Exec("CREATE DATABASE " + suite.dbName)
+	_ = tmpDb.Close()
+
+	suite.handler, _ = NewTiDBHandler(
+		suite.dsn, ExecutionsTable, context.Background(), nil,
+		TiDBSettings{DDLPollInterval: 10 * time.Millisecond, DeleteBatchSize: 2},
+	)
+	suite.db = suite.handler.db
+}
+
+func (suite *TiDBTestSuite) TearDownSuite() {
+	_, _ = suite.db.Exec("DROP DATABASE IF EXISTS " + suite.dbName)
+	_ = suite.db.Close()
+}
+
+func (suite *TiDBTestSuite) SetupTest() {
+	_ = suite.handler.Init()
+	_, _ = suite.db.Exec("DELETE FROM " + ExecutionsTable)
+}
+
+func (suite *TiDBTestSuite) TestItCanSaveAndLoadExecutions() {
+	exec := execution.MigrationExecution{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}
+
+	suite.Assert().NoError(suite.handler.Save(exec))
+	executions, err := suite.handler.LoadExecutions()
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]execution.MigrationExecution{exec}, executions)
+}
+
+func (suite *TiDBTestSuite) TestItWaitsForDDLJobs() {
+	_, err := suite.db.Exec("CREATE TABLE IF NOT EXISTS users (id INT PRIMARY KEY, age INT)")
+	suite.Assert().NoError(err)
+	_, err = suite.db.Exec("ALTER TABLE users ADD INDEX age_idx (age)")
+	suite.Assert().NoError(err)
+
+	suite.Assert().NoError(suite.handler.WaitForChanges())
+	runningJobs, err := suite.handler.runningDDLJobs(context.Background())
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(runningJobs)
+}
+
+func (suite *TiDBTestSuite) TestItDeletesInBatches() {
+	_, _ = suite.db.Exec("CREATE TABLE IF NOT EXISTS events (id INT PRIMARY KEY, old TINYINT)")
+	_, _ = suite.db.Exec("INSERT INTO events VALUES (1, 1), (2, 1), (3, 1), (4, 1), (5, 1), (6, 0)")
+
+	deleted, err := suite.handler.DeleteInBatches(suite.db, "events", "`old` = ?", 1)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(int64(5), deleted)
+
+	var remaining int
+	_ = suite.db.QueryRow("SELECT COUNT(*) FROM events").Scan(&remaining)
+	suite.Assert().Equal(1, remaining)
+}
@@ -0,0 +1,88 @@
+package s3
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/execution/repository"
+)
+
+// getenv Used to read the default credentials and region
+var getenv = os.Getenv
+
+func init() {
+	repository.RegisterScheme("s3", openObject)
+	repository.RegisterScheme("gs", openObject)
+}
+
+// openObject Builds an execution.ObjectRepository for the object from the DSN
+func openObject(
+	dsn *url.URL,
+	table string,
+	opts repository.DSNOptions,
+) (execution.Repository, error) {
+	settings, codec, err := settingsFromDSN(dsn, table, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewRepository(settings, opts.Ctx, nil, codec)
+}
+
+// settingsFromDSN Converts a s3://access:secret@bucket/key?region=&endpoint=&codec= (or gs://)
+// DSN to the store settings and the codec (see execution.CodecByName). The key defaults to the
+// table name and is prefixed with the scope, if any. The credentials and the region default to
+// the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION environment
+// variables.
+func settingsFromDSN(
+	dsn *url.URL,
+	table string,
+	opts repository.DSNOptions,
+) (Settings, execution.Codec, error) {
+	if opts.Sequence {
+		return Settings{}, nil, errors.New("object repositories do not support sequences")
+	}
+
+	query := dsn.Query()
+	settings := Settings{
+		Endpoint:        query.Get("endpoint"),
+		Region:          query.Get("region"),
+		Bucket:          dsn.Host,
+		Key:             strings.TrimPrefix(dsn.Path, "/"),
+		AccessKeyID:     dsn.User.Username(),
+		GCS:             dsn.Scheme == "gs",
+		RequestTimeout:  opts.QueryTimeout,
+		SessionToken:    getenv("AWS_SESSION_TOKEN"),
+		SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+	}
+
+	if secret, isSet := dsn.User.Password(); isSet {
+		settings.SecretAccessKey = secret
+	} else {
+		settings.AccessKeyID = getenv("AWS_ACCESS_KEY_ID")
+	}
+
+	if settings.Region == "" {
+		settings.Region = getenv("AWS_REGION")
+	}
+
+	if settings.Key == "" {
+		settings.Key = table
+	}
+
+	if opts.Scope != "" {
+		settings.Key = opts.Scope + "/" + settings.Key
+	}
+
+	codec := execution.JSONCodec
+	if name := query.Get("codec"); name != "" {
+		var err error
+		if codec, err = execution.CodecByName(name); err != nil {
+			return Settings{}, nil, err
+		}
+	}
+
+	return settings, codec, nil
+}
@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/execution/repository"
+	"github.com/stretchr/testify/suite"
+)
+
+type DSNTestSuite struct {
+	suite.Suite
+}
+
+func TestDSNTestSuite(t *testing.T) {
+	suite.Run(t, new(DSNTestSuite))
+}
+
+func (suite *DSNTestSuite) TestItRegistersItsSchemes() {
+	for _, dsn := range []string{"s3://bucket/executions.json", "gs://bucket"} {
+		repo, err := repository.FromDSN(dsn, "execs", repository.DSNOptions{})
+		suite.Assert().NoError(err, dsn)
+		suite.Assert().IsType(&execution.ObjectRepository{}, repo, dsn)
+	}
+
+	_, err := repository.FromDSN("s3://bucket", "execs", repository.DSNOptions{Sequence: true})
+	suite.Assert().ErrorContains(err, "do not support sequences")
+}
+
+func (suite *DSNTestSuite) TestItBuildsTheSettingsFromTheDSN() {
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID": "env-key", "AWS_SECRET_ACCESS_KEY": "env-secret",
+		"AWS_SESSION_TOKEN": "env-token", "AWS_REGION": "eu-west-1",
+	}
+	getenv = func(name string) string { return env[name] }
+	defer func() { getenv = os.Getenv }()
+
+	dsn, _ := url.Parse("s3://state/prod/executions.bin?codec=msgpack")
+	settings, codec, err := settingsFromDSN(
+		dsn, "execs", repository.DSNOptions{QueryTimeout: time.Second},
+	)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(execution.MsgpackCodec, codec)
+	suite.Assert().Equal(
+		Settings{
+			Region: "eu-west-1", Bucket: "state", Key: "prod/executions.bin",
+			AccessKeyID: "env-key", SecretAccessKey: "env-secret", SessionToken: "env-token",
+			RequestTimeout: time.Second,
+		},
+		settings,
+	)
+
+	dsn, _ = url.Parse("gs://hmac-id:hmac-secret@state?endpoint=http://localhost:4443")
+	settings, codec, err = settingsFromDSN(dsn, "execs", repository.DSNOptions{Scope: "billing"})
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(execution.JSONCodec, codec)
+	suite.Assert().Equal("hmac-id", settings.AccessKeyID)
+	suite.Assert().Equal("hmac-secret", settings.SecretAccessKey)
+	suite.Assert().Equal("http://localhost:4443", settings.Endpoint)
+	suite.Assert().Equal("billing/execs", settings.Key)
+	suite.Assert().True(settings.GCS)
+
+	dsn, _ = url.Parse("s3://state?codec=xml")
+	_, _, err = settingsFromDSN(dsn, "execs", repository.DSNOptions{})
+	suite.Assert().ErrorContains(err, "unknown executions codec")
+}
@@ -0,0 +1,309 @@
+// Package s3 includes the execution.ObjectStore implementation for S3 and the object storages
+// compatible with its API (for example, GCS, via HMAC keys, MinIO or R2), so the executions can
+// be stored as a single object (see execution.ObjectRepository). Importing it registers the s3
+// and gs schemes for repository.FromDSN.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// GCSEndpoint The endpoint of the GCS XML API, which is compatible with the S3 API
+const GCSEndpoint = "https://storage.googleapis.com"
+
+// Settings The location of the object and the credentials used to access it
+type Settings struct {
+	// Endpoint The base URL of the object storage. Defaults to https://s3.{Region}.amazonaws.com.
+	// The objects are addressed path style ({Endpoint}/{Bucket}/{Key}).
+	Endpoint string
+	// Region Used to sign the requests. Defaults to us-east-1 (auto for GCS)
+	Region string
+	Bucket string
+	Key    string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken The token of temporary credentials, if any
+	SessionToken string
+
+	// GCS Uses the GCS generation numbers, instead of the ETags, as object versions, since the
+	// GCS XML API supports conditional writes only via the generation. Endpoint defaults to
+	// GCSEndpoint.
+	GCS bool
+
+	// RequestTimeout Limits each request. Zero means the requests are limited only by the store
+	// context
+	RequestTimeout time.Duration
+}
+
+// Store execution.ObjectStore implementation which stores the object in S3 (or a compatible
+// storage), using conditional writes (If-Match, If-None-Match) for optimistic concurrency
+type Store struct {
+	settings Settings
+	ctx      context.Context
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewStore Builds a new Store. If client is nil, http.DefaultClient is used.
+func NewStore(settings Settings, ctx context.Context, client *http.Client) (*Store, error) {
+	if settings.Bucket == "" || settings.Key == "" {
+		return nil, errors.New("the bucket and the key of the executions object are required")
+	}
+
+	if settings.Region == "" {
+		settings.Region = "us-east-1"
+		if settings.GCS {
+			settings.Region = "auto"
+		}
+	}
+
+	if settings.Endpoint == "" {
+		settings.Endpoint = "https://s3." + settings.Region + ".amazonaws.com"
+		if settings.GCS {
+			settings.Endpoint = GCSEndpoint
+		}
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Store{settings: settings, ctx: ctx, client: client, now: time.Now}, nil
+}
+
+// NewRepository Builds an execution.ObjectRepository which stores the executions in the object,
+// encoded with the codec (execution.JSONCodec if nil)
+func NewRepository(
+	settings Settings,
+	ctx context.Context,
+	client *http.Client,
+	codec execution.Codec,
+) (*execution.ObjectRepository, error) {
+	store, err := NewStore(settings, ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return execution.NewObjectRepository(store, codec), nil
+}
+
+// Get See execution.ObjectStore
+func (s *Store) Get() ([]byte, string, error) {
+	var data []byte
+	var version string
+
+	err := s.do(http.MethodGet, nil, nil, func(response *http.Response) error {
+		if response.StatusCode == http.StatusNotFound {
+			return nil
+		}
+
+		if response.StatusCode != http.StatusOK {
+			return responseErr(response)
+		}
+
+		var err error
+		data, err = io.ReadAll(response.Body)
+		version = s.version(response)
+		return err
+	})
+
+	return data, version, err
+}
+
+// Put See execution.ObjectStore
+func (s *Store) Put(data []byte, ifVersion string) (string, error) {
+	headers := map[string]string{}
+	switch {
+	case s.settings.GCS && ifVersion == "":
+		headers["x-goog-if-generation-match"] = "0"
+	case s.settings.GCS:
+		headers["x-goog-if-generation-match"] = ifVersion
+	case ifVersion == "":
+		headers["If-None-Match"] = "*"
+	default:
+		headers["If-Match"] = ifVersion
+	}
+
+	var version string
+	err := s.do(http.MethodPut, data, headers, func(response *http.Response) error {
+		switch response.StatusCode {
+		case http.StatusOK:
+			version = s.version(response)
+			return nil
+		case http.StatusPreconditionFailed, http.StatusConflict:
+			return fmt.Errorf("%w: %w", execution.ErrObjectModified, responseErr(response))
+		}
+		return responseErr(response)
+	})
+
+	return version, err
+}
+
+// version The object version: the generation for GCS, the ETag otherwise
+func (s *Store) version(response *http.Response) string {
+	if s.settings.GCS {
+		return response.Header.Get("x-goog-generation")
+	}
+	return response.Header.Get("ETag")
+}
+
+// do Sends the signed request for the object and handles the response, within the request
+// timeout
+func (s *Store) do(
+	method string,
+	body []byte,
+	headers map[string]string,
+	handle func(response *http.Response) error,
+) error {
+	ctx := s.ctx
+	if s.settings.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.settings.RequestTimeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(
+		ctx, method, s.objectURL(), bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	s.sign(request, body)
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	return handle(response)
+}
+
+// objectURL The path style URL of the object
+func (s *Store) objectURL() string {
+	return strings.TrimSuffix(s.settings.Endpoint, "/") + "/" +
+		uriEncode(s.settings.Bucket, false) + "/" + uriEncode(s.settings.Key, false)
+}
+
+// sign Signs the request with AWS Signature Version 4, signing all the request headers
+func (s *Store) sign(request *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("x-amz-date", amzDate)
+	request.Header.Set("x-amz-content-sha256", payloadHash)
+	if s.settings.SessionToken != "" {
+		request.Header.Set("x-amz-security-token", s.settings.SessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		uriEncode(request.URL.Path, false),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.settings.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" +
+		sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.settings.SecretAccessKey), date)
+	for _, part := range []string{s.settings.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	request.Header.Set(
+		"Authorization",
+		"AWS4-HMAC-SHA256 Credential="+s.settings.AccessKeyID+"/"+scope+
+			", SignedHeaders="+signedHeaders+
+			", Signature="+hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	)
+}
+
+// canonicalQuery The query parameters, sorted and encoded, as required by the signature
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+// uriEncode Encodes all characters except the unreserved ones (and the slashes, unless
+// encodeSlash is set), as required by the signature
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			encoded.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+	return encoded.String()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// responseErr The error for an unexpected response, including the error message returned by the
+// object storage
+func responseErr(response *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf(
+		"unexpected object storage response %s: %s",
+		response.Status, strings.TrimSpace(string(message)),
+	)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// ErrStateNotEmpty is returned when trying to adopt the state of another migrations tool into
+// a repository which already has executions persisted
+var ErrStateNotEmpty = errors.New("executions repository is not empty")
+
+// AdoptState Imports the migrations state persisted by another migrations tool (see
+// execution.StateImporter) and saves it in the handler's repository. It will refuse to adopt
+// the state if the repository already has persisted executions or if the imported state
+// is not consistent with the registered migrations. Importers which stamp executions with the
+// import time (see execution.TimedStateImporter) use the handler clock.
+// Returns the persisted executions.
+func (handler *MigrationsHandler) AdoptState(
+	importer execution.StateImporter,
+) ([]execution.MigrationExecution, error) {
+	errMsg := "failed to adopt state from " + importer.Name()
+
+	if err := handler.checkWritable("state adoption"); err != nil {
+		return nil, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	existing, err := handler.repository.LoadExecutions()
+	if err != nil {
+		return nil, fmt.Errorf("%s, failed to load executions with error: %w", errMsg, err)
+	}
+
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%s, %w", errMsg, ErrStateNotEmpty)
+	}
+
+	var imported []execution.MigrationExecution
+	if timed, isTimed := importer.(execution.TimedStateImporter); isTimed {
+		imported, err = timed.ImportAt(handler.registry.OrderedVersions(), handler.clock.Now())
+	} else {
+		imported, err = importer.Import(handler.registry.OrderedVersions())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s, import failed with error: %w", errMsg, err)
+	}
+
+	if _, err = handler.planFor(
+		&execution.InMemoryRepository{PersistedExecutions: imported},
+	); err != nil {
+		return nil, fmt.Errorf(
+			"%s, imported state is not consistent with registered migrations: %w", errMsg, err,
+		)
+	}
+
+	var adopted []execution.MigrationExecution
+	for _, exec := range imported {
+		if err = handler.repository.Save(exec); err != nil {
+			return adopted, fmt.Errorf(
+				"%s, failed to save execution %d with error: %w", errMsg, exec.Version, err,
+			)
+		}
+		adopted = append(adopted, exec)
+	}
+
+	return adopted, nil
+}
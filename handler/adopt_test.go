@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdoptTestSuite struct {
+	suite.Suite
+}
+
+func TestAdoptTestSuite(t *testing.T) {
+	suite.Run(t, new(AdoptTestSuite))
+}
+
+type FakeStateImporter struct {
+	executions []execution.MigrationExecution
+	err        error
+}
+
+func (f *FakeStateImporter) Name() string {
+	return "fake"
+}
+
+func (f *FakeStateImporter) Import([]uint64) ([]execution.MigrationExecution, error) {
+	return f.executions, f.err
+}
+
+type FakeTimedStateImporter struct {
+	FakeStateImporter
+}
+
+func (f *FakeTimedStateImporter) ImportAt(
+	_ []uint64,
+	now time.Time,
+) ([]execution.MigrationExecution, error) {
+	nowMs := uint64(now.UnixMilli())
+	return []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: nowMs, FinishedAtMs: nowMs},
+	}, nil
+}
+
+func (suite *AdoptTestSuite) newRegistry() *migration.GenericRegistry {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	return registry
+}
+
+func (suite *AdoptTestSuite) TestItCanAdoptState() {
+	imported := []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+		{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+	}
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(suite.newRegistry(), repo, nil)
+
+	adopted, err := handler.AdoptState(&FakeStateImporter{executions: imported})
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(imported, adopted)
+	suite.Assert().Equal(imported, repo.PersistedExecutions)
+}
+
+func (suite *AdoptTestSuite) TestItImportsWithTheHandlerClock() {
+	now := time.UnixMilli(1717200000000)
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		suite.newRegistry(), repo, nil, WithClock(clock.NewFixed(now)),
+	)
+
+	adopted, err := handler.AdoptState(&FakeTimedStateImporter{})
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1717200000000, FinishedAtMs: 1717200000000},
+		},
+		adopted,
+	)
+}
+
+func (suite *AdoptTestSuite) TestItFailsToAdoptInvalidState() {
+	scenarios := map[string]struct {
+		persisted   []execution.MigrationExecution
+		importer    *FakeStateImporter
+		expectedErr string
+	}{
+		"repository not empty": {
+			[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			&FakeStateImporter{},
+			ErrStateNotEmpty.Error(),
+		},
+		"import failed": {
+			nil,
+			&FakeStateImporter{err: errors.New("import err")},
+			"import err",
+		},
+		"inconsistent state": {
+			nil,
+			&FakeStateImporter{
+				executions: []execution.MigrationExecution{
+					{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+				},
+			},
+			"not consistent with registered migrations",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		repo := &execution.InMemoryRepository{PersistedExecutions: scenario.persisted}
+		handler, _ := NewHandler(suite.newRegistry(), repo, nil)
+
+		_, err := handler.AdoptState(scenario.importer)
+
+		suite.Assert().ErrorContains(err, scenario.expectedErr, "failed scenario %s", name)
+		suite.Assert().Equal(scenario.persisted, repo.PersistedExecutions)
+	}
+}
@@ -0,0 +1,13 @@
+package handler
+
+import "github.com/rsgcata/go-migrations/execution"
+
+// waitForChanges Waits for the changes made by the migration's Up() or Down() to be applied, if
+// the repository applies them asynchronously (see execution.AsyncChangesWaiter)
+func (handler *MigrationsHandler) waitForChanges(version uint64, stage MigrationStage) error {
+	waiter, isWaiter := handler.repository.(execution.AsyncChangesWaiter)
+	if !isWaiter {
+		return nil
+	}
+	return newMigrationFailed(version, stage, waiter.WaitForChanges())
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type AsyncTestSuite struct {
+	suite.Suite
+}
+
+func TestAsyncTestSuite(t *testing.T) {
+	suite.Run(t, new(AsyncTestSuite))
+}
+
+type asyncRepository struct {
+	execution.InMemoryRepository
+	waits   int
+	waitErr error
+}
+
+func (repo *asyncRepository) WaitForChanges() error {
+	repo.waits++
+	return repo.waitErr
+}
+
+func (suite *AsyncTestSuite) TestItWaitsForAsyncChangesBeforeRecordingExecutions() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &asyncRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	_, err = handler.ForceUp(2)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(2, repo.waits)
+
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	_, err = handler.ForceDown(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(4, repo.waits)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *AsyncTestSuite) TestItFailsMigrationsWhoseChangesAreNotApplied() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &asyncRepository{waitErr: errors.New("ddl job timed out")}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	var failed *MigrationFailedError
+	suite.Assert().ErrorAs(err, &failed)
+	suite.Assert().Equal(StageUp, failed.Stage)
+	suite.Assert().ErrorContains(err, "ddl job timed out")
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().False(repo.PersistedExecutions[0].Finished())
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WithBaseline Makes the handler ignore all migrations and executions with a version lower or
+// equal to the provided baseline version (see migration.ReadBaseline). Used after old
+// migration files were archived (see Prune), so the executions persisted for them do not make
+// the state inconsistent.
+func WithBaseline(version uint64) Option {
+	return func(handler *MigrationsHandler) {
+		handler.baseline = version
+	}
+}
+
+// Plan Returns the current execution plan
+func (handler *MigrationsHandler) Plan() (*ExecutionPlan, error) {
+	return handler.plan()
+}
+
+// plan Creates the execution plan for the handler's repository, ignoring the migrations and
+// executions covered by the baseline
+func (handler *MigrationsHandler) plan() (*ExecutionPlan, error) {
+	return handler.planFor(handler.repository)
+}
+
+// planFor Same as plan, but for the provided repository. Plan inconsistencies are reported to
+// the consistency monitors (see WithConsistencyMonitor)
+func (handler *MigrationsHandler) planFor(repository execution.Repository) (*ExecutionPlan, error) {
+	registry := handler.registry
+	if handler.baseline != 0 {
+		registry = &baselineRegistry{registry, handler.baseline}
+		repository = &baselineRepository{repository, handler.baseline}
+	}
+
+	plan, err := handler.newExecutionPlan(registry, repository)
+	if err != nil {
+		return nil, handler.reportInconsistency(err)
+	}
+	return plan, nil
+}
+
+// baselineRegistry Registry view which hides the migrations covered by the baseline
+type baselineRegistry struct {
+	migration.MigrationsRegistry
+	baseline uint64
+}
+
+func (r *baselineRegistry) OrderedVersions() []uint64 {
+	var versions []uint64
+	for _, version := range r.MigrationsRegistry.OrderedVersions() {
+		if version > r.baseline {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+func (r *baselineRegistry) OrderedMigrations() []migration.Migration {
+	var migrations []migration.Migration
+	for _, mig := range r.MigrationsRegistry.OrderedMigrations() {
+		if mig.Version() > r.baseline {
+			migrations = append(migrations, mig)
+		}
+	}
+	return migrations
+}
+
+func (r *baselineRegistry) Get(version uint64) migration.Migration {
+	if version <= r.baseline {
+		return nil
+	}
+	return r.MigrationsRegistry.Get(version)
+}
+
+func (r *baselineRegistry) Count() int {
+	return len(r.OrderedVersions())
+}
+
+// baselineRepository Repository view which hides the executions covered by the baseline, when
+// loading all executions
+type baselineRepository struct {
+	execution.Repository
+	baseline uint64
+}
+
+func (r *baselineRepository) LoadExecutions() ([]execution.MigrationExecution, error) {
+	executions, err := r.Repository.LoadExecutions()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []execution.MigrationExecution
+	for _, exec := range executions {
+		if exec.Version > r.baseline {
+			filtered = append(filtered, exec)
+		}
+	}
+	return filtered, nil
+}
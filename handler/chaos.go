@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// FaultPoint A point of a MigrateUp run where a fault can be injected
+type FaultPoint string
+
+const (
+	// FaultAfterUp Fails the migration after its Up() succeeded, like a migration which applied
+	// only a part of its changes. The execution is saved as unfinished.
+	FaultAfterUp FaultPoint = "after-up"
+	// FaultBeforeSave Fails saving the execution after Up() succeeded, so the migration's changes
+	// are applied, but the migration is not recorded as executed
+	FaultBeforeSave FaultPoint = "before-save"
+	// FaultCrash Stops the run after Up() succeeded, like a killed process: the execution is not
+	// saved, nothing else runs and the migrations lock (see WithExclusiveLock) is not released
+	FaultCrash FaultPoint = "crash"
+)
+
+// ErrSimulatedCrash is returned (wrapped) by runs stopped by a FaultCrash fault. It wraps
+// execution.ErrInjectedFault.
+var ErrSimulatedCrash = fmt.Errorf("simulated crash, %w", execution.ErrInjectedFault)
+
+// Fault A fault injected at the point, for the migration with the version (0 matches any
+// migration)
+type Fault struct {
+	Point   FaultPoint
+	Version uint64
+}
+
+// WithFaultInjection Enables the chaos test mode, injecting the faults in MigrateUp runs, so the
+// operational runbooks and the repair tooling (for example, force:up, force:down, state adoption)
+// can be tested against realistic failures. Each fault fails (or crashes) a single migration,
+// the first one it matches. Injected failures wrap execution.ErrInjectedFault. Meant for tests
+// only; repository failures can be injected with execution.FaultyRepository.
+func WithFaultInjection(faults ...Fault) Option {
+	return func(handler *MigrationsHandler) {
+		handler.faults = append(handler.faults, faults...)
+	}
+}
+
+// injectFault Returns the error of the first fault injected at the point, for the migration
+// with the version, if any. The fault is removed, so it's injected only once.
+func (handler *MigrationsHandler) injectFault(point FaultPoint, version uint64) error {
+	index := slices.IndexFunc(handler.faults, func(fault Fault) bool {
+		return fault.Point == point && (fault.Version == 0 || fault.Version == version)
+	})
+
+	if index < 0 {
+		return nil
+	}
+
+	handler.faults = slices.Delete(handler.faults, index, index+1)
+	if point == FaultCrash {
+		return fmt.Errorf("%w after migration %d", ErrSimulatedCrash, version)
+	}
+	return fmt.Errorf("%w %s of migration %d", execution.ErrInjectedFault, point, version)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChaosTestSuite struct {
+	suite.Suite
+}
+
+func TestChaosTestSuite(t *testing.T) {
+	suite.Run(t, new(ChaosTestSuite))
+}
+
+func (suite *ChaosTestSuite) newRegistry(
+	schema map[string]bool,
+	calls *[]string,
+) migration.MigrationsRegistry {
+	registry := migration.NewGenericRegistry()
+	for _, version := range []uint64{1, 2, 3} {
+		_ = registry.Register(
+			&FakeTableMigration{
+				DummyMigration: *migration.NewDummyMigration(version),
+				schema:         schema,
+				calls:          calls,
+			},
+		)
+	}
+	return registry
+}
+
+func (suite *ChaosTestSuite) TestItCanFailMigrationsAfterUp() {
+	schema := map[string]bool{}
+	var calls []string
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		suite.newRegistry(schema, &calls), repo, nil,
+		WithFaultInjection(Fault{Point: FaultAfterUp, Version: 2}),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(3))
+
+	suite.Assert().ErrorIs(err, execution.ErrInjectedFault)
+	var migrationErr *MigrationFailedError
+	suite.Assert().ErrorAs(err, &migrationErr)
+	suite.Assert().Equal(uint64(2), migrationErr.Version)
+	suite.Assert().Equal([]string{"up 1", "up 2"}, calls)
+	suite.Assert().True(schema["table_2"])
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().False(repo.PersistedExecutions[1].Finished())
+}
+
+func (suite *ChaosTestSuite) TestItCanFailSavingExecutions() {
+	schema := map[string]bool{}
+	var calls []string
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		suite.newRegistry(schema, &calls), repo, nil,
+		WithFaultInjection(Fault{Point: FaultBeforeSave}),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(3))
+
+	suite.Assert().ErrorIs(err, execution.ErrInjectedFault)
+	suite.Assert().ErrorContains(err, "before-save of migration 1")
+	suite.Assert().Equal([]string{"up 1"}, calls)
+	suite.Assert().True(schema["table_1"])
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	// The fault is injected once, so the next run fails like a real partially applied migration
+	_, err = handler.MigrateUp(NumOfRuns(3))
+	suite.Assert().ErrorContains(err, "table table_1 already exists")
+}
+
+func (suite *ChaosTestSuite) TestItCanSimulateCrashesWhichKeepTheLock() {
+	schema := map[string]bool{}
+	var calls []string
+	repo := &lockingRepository{}
+	handler, _ := NewHandler(
+		suite.newRegistry(schema, &calls), repo, nil,
+		WithExclusiveLock(), WithFaultInjection(Fault{Point: FaultCrash, Version: 2}),
+	)
+
+	report, err := handler.MigrateUpWithReport(NumOfRuns(3))
+
+	suite.Assert().ErrorIs(err, ErrSimulatedCrash)
+	suite.Assert().ErrorIs(err, execution.ErrInjectedFault)
+	suite.Assert().Equal([]string{"up 1", "up 2"}, calls)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().True(repo.Held)
+	suite.Assert().Len(report.Migrations, 2)
+	suite.Assert().Equal(OutcomeFailed, report.Migrations[1].Outcome)
+
+	_, err = handler.MigrateUp(NumOfRuns(3))
+	suite.Assert().ErrorIs(err, execution.ErrLockHeld)
+}
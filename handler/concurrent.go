@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ConcurrentChangePolicy What MigrateUp does when it detects that a migration it was about to
+// run was executed by another process after the execution plan was built
+type ConcurrentChangePolicy int
+
+const (
+	// ConcurrentChangeAbort Stop the run with an error wrapping ErrPlanStateChanged
+	ConcurrentChangeAbort ConcurrentChangePolicy = iota
+
+	// ConcurrentChangeSkip Skip the migrations which were executed (finished) by another
+	// process and continue with the next ones. Migrations which another process is still
+	// executing stop the run, as with ConcurrentChangeAbort.
+	ConcurrentChangeSkip
+)
+
+// WithConcurrentChangeDetection Makes MigrateUp re-check the persisted execution of each
+// migration right before running it, so migrations executed by another process after the plan
+// was built are never executed twice. Repositories which implement execution.ConditionalSaver
+// detect the change when the execution is claimed, the others are checked with FindOne. The
+// policy decides if such migrations are skipped or if the run is aborted.
+func WithConcurrentChangeDetection(policy ConcurrentChangePolicy) Option {
+	return func(handler *MigrationsHandler) {
+		handler.detectConcurrentChanges = true
+		handler.concurrentChangePolicy = policy
+	}
+}
+
+// concurrentChange Checks if the migration was executed by another process after the plan was
+// built. conflict is set when claiming the execution failed with execution.ErrExecutionConflict,
+// which means the persisted state changed, even if no execution is found for the migration.
+// Returns true if the migration must be skipped (see ConcurrentChangeSkip), or an error wrapping
+// ErrPlanStateChanged if the run must stop.
+func (handler *MigrationsHandler) concurrentChange(
+	plan *ExecutionPlan,
+	mig migration.Migration,
+	conflict bool,
+) (bool, error) {
+	persisted, err := handler.repository.FindOne(mig.Version())
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to re-check the execution of migration %d with error: %w", mig.Version(), err,
+		)
+	}
+
+	if !conflict && (persisted == nil || plannedExecution(plan, *persisted)) {
+		return false, nil
+	}
+
+	if persisted == nil {
+		return false, fmt.Errorf(
+			"%w, the executions of migration %d were changed by another process",
+			ErrPlanStateChanged, mig.Version(),
+		)
+	}
+
+	if !persisted.Finished() {
+		return false, fmt.Errorf(
+			"%w, migration %d is being executed by another process",
+			ErrPlanStateChanged, mig.Version(),
+		)
+	}
+
+	if handler.concurrentChangePolicy == ConcurrentChangeSkip {
+		plan.markExecuted(*persisted)
+		return true, nil
+	}
+
+	return false, fmt.Errorf(
+		"%w, migration %d was executed by another process", ErrPlanStateChanged, mig.Version(),
+	)
+}
+
+// plannedExecution Checks if the persisted execution is the one the plan was built with (for
+// example, the unfinished execution of a migration which is retried)
+func plannedExecution(plan *ExecutionPlan, persisted execution.MigrationExecution) bool {
+	last := plan.LastExecuted().Execution
+	return last != nil && last.Version == persisted.Version &&
+		last.ExecutedAtMs == persisted.ExecutedAtMs && last.FinishedAtMs == persisted.FinishedAtMs
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConcurrentTestSuite struct {
+	suite.Suite
+}
+
+func TestConcurrentTestSuite(t *testing.T) {
+	suite.Run(t, new(ConcurrentTestSuite))
+}
+
+// SideEffectMigration Migration which runs the side effect in Up(), for example to simulate
+// another process which executes migrations during the run
+type SideEffectMigration struct {
+	migration.DummyMigration
+	sideEffect func()
+}
+
+func (m *SideEffectMigration) Up() error {
+	m.sideEffect()
+	return nil
+}
+
+func (suite *ConcurrentTestSuite) TestItSkipsOrAbortsOnMigrationsExecutedByAnotherProcess() {
+	scenarios := map[string]struct {
+		policy        ConcurrentChangePolicy
+		expectedErr   bool
+		expectedUpRan bool
+	}{
+		"skip":  {ConcurrentChangeSkip, false, true},
+		"abort": {ConcurrentChangeAbort, true, false},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Run(name, func() {
+			repo := &execution.InMemoryRepository{}
+			mig2 := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(2)}
+			mig3 := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(3)}
+			registry := migration.NewGenericRegistry()
+			_ = registry.Register(&SideEffectMigration{
+				*migration.NewDummyMigration(1),
+				func() {
+					_ = repo.Save(
+						execution.MigrationExecution{Version: 2, ExecutedAtMs: 5, FinishedAtMs: 6},
+					)
+				},
+			})
+			_ = registry.Register(mig2)
+			_ = registry.Register(mig3)
+			handler, _ := NewHandler(
+				registry, repo, nil, WithConcurrentChangeDetection(scenario.policy),
+			)
+
+			_, err := handler.MigrateUp(NumOfRuns(3))
+
+			if scenario.expectedErr {
+				suite.Assert().ErrorIs(err, ErrPlanStateChanged)
+				suite.Assert().ErrorContains(err, "migration 2 was executed by another process")
+			} else {
+				suite.Assert().NoError(err)
+			}
+			suite.Assert().False(mig2.upRan)
+			suite.Assert().Equal(scenario.expectedUpRan, mig3.upRan)
+		})
+	}
+}
+
+func (suite *ConcurrentTestSuite) TestItDetectsConcurrentChangesWhenClaimingExecutions() {
+	scenarios := map[string]struct {
+		otherProcessExec execution.MigrationExecution
+		expectedErr      string
+	}{
+		"finished by another process": {
+			execution.MigrationExecution{Version: 2, ExecutedAtMs: 5, FinishedAtMs: 6}, "",
+		},
+		"being executed by another process": {
+			execution.MigrationExecution{Version: 2, ExecutedAtMs: 5},
+			"migration 2 is being executed by another process",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Run(name, func() {
+			mig2 := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(2)}
+			mig3 := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(3)}
+			registry := migration.NewGenericRegistry()
+			_ = registry.Register(migration.NewDummyMigration(1))
+			_ = registry.Register(mig2)
+			_ = registry.Register(mig3)
+			repo := &ConditionalInMemoryRepository{
+				onSaveIf: func(repo *ConditionalInMemoryRepository) {
+					// Another process claims migration 2 once migration 1 is finished
+					exec1, _ := repo.FindOne(1)
+					if exec2, _ := repo.FindOne(2); exec2 == nil && exec1 != nil &&
+						exec1.Finished() {
+						_ = repo.Save(scenario.otherProcessExec)
+					}
+				},
+			}
+			handler, _ := NewHandler(
+				registry, repo, nil, WithConcurrentChangeDetection(ConcurrentChangeSkip),
+			)
+
+			_, err := handler.MigrateUp(NumOfRuns(3))
+
+			if scenario.expectedErr != "" {
+				suite.Assert().ErrorIs(err, ErrPlanStateChanged)
+				suite.Assert().ErrorContains(err, scenario.expectedErr)
+				suite.Assert().False(mig3.upRan)
+			} else {
+				suite.Assert().NoError(err)
+				suite.Assert().True(mig3.upRan)
+			}
+			suite.Assert().False(mig2.upRan)
+		})
+	}
+}
+
+func (suite *ConcurrentTestSuite) TestItRetriesUnfinishedExecutionsFromThePlan() {
+	mig1 := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig1)
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{{Version: 1, ExecutedAtMs: 2}},
+	}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithConcurrentChangeDetection(ConcurrentChangeAbort),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().NoError(err)
+	suite.Assert().True(mig1.upRan)
+}
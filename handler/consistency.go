@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// Inconsistency An execution plan consistency failure, with the context of the handler which
+// detected it
+type Inconsistency struct {
+	*InconsistentPlanError
+	// Environment The environment the handler runs against (see WithGuardrails), if set
+	Environment string
+	Run         execution.RunMetadata
+}
+
+// ConsistencyMonitor Receives the execution plan consistency failures (see
+// InconsistentPlanError), so dashboards can alert on executions state corruption separately from
+// migration failures (for example, by incrementing a counter labeled with the inconsistency
+// kind). The notify.Webhook monitor posts them to an HTTP endpoint.
+type ConsistencyMonitor interface {
+	ReportInconsistency(inconsistency Inconsistency) error
+}
+
+// WithConsistencyMonitor Makes the handler report to the monitor each time building the
+// execution plan fails because the persisted executions are inconsistent with the registered
+// migrations (more executions than migrations, unfinished executions, executions out of order).
+// Reporting failures do not stop the request, they are joined with the plan error.
+func WithConsistencyMonitor(monitor ConsistencyMonitor) Option {
+	return func(handler *MigrationsHandler) {
+		handler.consistencyMonitors = append(handler.consistencyMonitors, monitor)
+	}
+}
+
+// reportInconsistency Reports the plan inconsistency wrapped by the plan error, if any,
+// returning the plan error joined with the reporting failures
+func (handler *MigrationsHandler) reportInconsistency(planErr error) error {
+	var inconsistent *InconsistentPlanError
+	if !errors.As(planErr, &inconsistent) {
+		return planErr
+	}
+
+	inconsistency := Inconsistency{
+		InconsistentPlanError: inconsistent,
+		Environment:           handler.environment,
+		Run:                   handler.runMetadata,
+	}
+
+	err := planErr
+	for _, monitor := range handler.consistencyMonitors {
+		if reportErr := monitor.ReportInconsistency(inconsistency); reportErr != nil {
+			err = errors.Join(
+				err,
+				fmt.Errorf("failed to report plan inconsistency with error: %w", reportErr),
+			)
+		}
+	}
+
+	return err
+}
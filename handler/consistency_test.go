@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConsistencyTestSuite struct {
+	suite.Suite
+}
+
+func TestConsistencyTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsistencyTestSuite))
+}
+
+type fakeConsistencyMonitor struct {
+	inconsistencies []Inconsistency
+	err             error
+}
+
+func (m *fakeConsistencyMonitor) ReportInconsistency(inconsistency Inconsistency) error {
+	m.inconsistencies = append(m.inconsistencies, inconsistency)
+	return m.err
+}
+
+func (suite *ConsistencyTestSuite) TestItReportsPlanInconsistencies() {
+	scenarios := map[string]struct {
+		executions      []execution.MigrationExecution
+		expectedKind    InconsistencyKind
+		expectedVersion uint64
+	}{
+		"more executions than migrations": {
+			[]execution.MigrationExecution{
+				{Version: 1, FinishedAtMs: 1}, {Version: 2, FinishedAtMs: 1},
+				{Version: 3, FinishedAtMs: 1},
+			},
+			InconsistencyExtraExecutions, 0,
+		},
+		"unfinished execution": {
+			[]execution.MigrationExecution{{Version: 1}, {Version: 2, FinishedAtMs: 1}},
+			InconsistencyUnfinishedExecution, 1,
+		},
+		"out of order": {
+			[]execution.MigrationExecution{{Version: 2, FinishedAtMs: 1}},
+			InconsistencyOutOfOrder, 2,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		registry := migration.NewGenericRegistry()
+		_ = registry.Register(migration.NewDummyMigration(1))
+		_ = registry.Register(migration.NewDummyMigration(2))
+		repo := &execution.InMemoryRepository{PersistedExecutions: scenario.executions}
+		monitor := &fakeConsistencyMonitor{}
+		handler, _ := NewHandler(
+			registry, repo, nil, WithConsistencyMonitor(monitor),
+			WithRunMetadata(execution.RunMetadata{DeployID: "deploy-1"}),
+		)
+
+		_, err := handler.MigrateUp(AllRuns)
+
+		suite.Assert().ErrorIs(err, ErrPlanInconsistent, name)
+		suite.Require().Len(monitor.inconsistencies, 1, name)
+		suite.Assert().Equal(scenario.expectedKind, monitor.inconsistencies[0].Kind, name)
+		suite.Assert().Equal(scenario.expectedVersion, monitor.inconsistencies[0].Version, name)
+		suite.Assert().Equal("deploy-1", monitor.inconsistencies[0].Run.DeployID, name)
+	}
+}
+
+func (suite *ConsistencyTestSuite) TestItDoesNotReportOtherPlanFailures() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{LoadErr: errors.New("connection lost")}
+	monitor := &fakeConsistencyMonitor{}
+	handler, _ := NewHandler(registry, repo, nil, WithConsistencyMonitor(monitor))
+
+	_, err := handler.Plan()
+
+	suite.Assert().ErrorContains(err, "connection lost")
+	suite.Assert().Empty(monitor.inconsistencies)
+}
+
+func (suite *ConsistencyTestSuite) TestItJoinsReportingFailuresWithThePlanError() {
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{{Version: 1, FinishedAtMs: 1}},
+	}
+	monitor := &fakeConsistencyMonitor{err: errors.New("metrics endpoint down")}
+	handler, _ := NewHandler(
+		migration.NewGenericRegistry(), repo, nil, WithConsistencyMonitor(monitor),
+	)
+
+	_, err := handler.Plan()
+
+	suite.Assert().ErrorIs(err, ErrPlanInconsistent)
+	suite.Assert().ErrorContains(err, "metrics endpoint down")
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WithContext The context set for the context-aware migrations (see migration.ContextAware),
+// for example, one holding the shared resources of the migrations (see migration.WithResources).
+// Defaults to context.Background() (also used if ctx is nil)
+func WithContext(ctx context.Context) Option {
+	return func(handler *MigrationsHandler) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		handler.ctx = ctx
+	}
+}
+
+// WithResources Adds the shared resources (see migration.WithResources) to the context set for
+// the context-aware migrations. Can be combined with WithContext, in any order.
+func WithResources(resources ...migration.Resource) Option {
+	return func(handler *MigrationsHandler) {
+		handler.resources = append(handler.resources, resources...)
+	}
+}
+
+// migrationContext The handler context, with the shared resources
+func (handler *MigrationsHandler) migrationContext() context.Context {
+	return migration.WithResources(handler.ctx, handler.resources...)
+}
+
+// setContext Sets the handler context, with the shared resources, for the context-aware
+// migrations, before they are validated and executed
+func (handler *MigrationsHandler) setContext(migrations ...migration.Migration) {
+	ctx := handler.migrationContext()
+	for _, mig := range migrations {
+		if contextAware, isContextAware := mig.(migration.ContextAware); isContextAware {
+			contextAware.SetContext(ctx)
+		}
+	}
+}
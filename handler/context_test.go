@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextTestSuite struct {
+	suite.Suite
+}
+
+func TestContextTestSuite(t *testing.T) {
+	suite.Run(t, new(ContextTestSuite))
+}
+
+var tableKey = migration.NewKey[string]("table")
+
+// ResourceMigration Retrieves the table it changes from its context
+type ResourceMigration struct {
+	migration.DummyMigration
+	ctx     context.Context
+	changed *[]string
+}
+
+func (mig *ResourceMigration) SetContext(ctx context.Context) {
+	mig.ctx = ctx
+}
+
+func (mig *ResourceMigration) Validate() error {
+	_, err := tableKey.Get(mig.ctx)
+	return err
+}
+
+func (mig *ResourceMigration) Up() error {
+	table, _ := tableKey.From(mig.ctx)
+	*mig.changed = append(*mig.changed, "up "+table)
+	return nil
+}
+
+func (mig *ResourceMigration) Down() error {
+	table, _ := tableKey.From(mig.ctx)
+	*mig.changed = append(*mig.changed, "down "+table)
+	return nil
+}
+
+func (suite *ContextTestSuite) newRegistry(changed *[]string) *migration.GenericRegistry {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&ResourceMigration{DummyMigration: *migration.NewDummyMigration(1), changed: changed},
+	)
+	return registry
+}
+
+func (suite *ContextTestSuite) TestItSetsTheContextWithTheResources() {
+	type requestKey struct{}
+	var changed []string
+	registry := suite.newRegistry(&changed)
+	ctx := context.WithValue(context.Background(), requestKey{}, "deploy-1")
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithResources(tableKey.Provide("users")), WithContext(ctx),
+	)
+
+	_, err := handler.MigrateUp(AllRuns)
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateDown(AllRuns)
+	suite.Assert().NoError(err)
+	_, err = handler.ForceUp(1)
+	suite.Assert().NoError(err)
+	_, err = handler.ForceDown(1)
+	suite.Assert().NoError(err)
+
+	suite.Assert().Equal([]string{"up users", "down users", "up users", "down users"}, changed)
+	mig := registry.Get(1).(*ResourceMigration)
+	suite.Assert().Equal("deploy-1", mig.ctx.Value(requestKey{}))
+}
+
+func (suite *ContextTestSuite) TestItFailsValidationWhenAResourceIsMissing() {
+	var changed []string
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(suite.newRegistry(&changed), repo, nil)
+
+	_, err := handler.MigrateUp(AllRuns)
+	suite.Assert().ErrorContains(err, "the table resource is missing from the migration context")
+	suite.Assert().Empty(changed)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *ContextTestSuite) TestItKeepsTheResourcesWithoutContext() {
+	var changed []string
+	registry := suite.newRegistry(&changed)
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithResources(tableKey.Provide("users")), WithContext(nil),
+	)
+
+	_, err := handler.MigrateUp(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]string{"up users"}, changed)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// ErrEnvironmentsOutOfSync is returned when two environments did not execute the same
+// migrations
+var ErrEnvironmentsOutOfSync = errors.New("environments are out of sync")
+
+// EnvironmentDiff The versions of the migrations executed (finished) in only one of two
+// environments (see DiffEnvironments)
+type EnvironmentDiff struct {
+	Left      string
+	Right     string
+	OnlyLeft  []uint64
+	OnlyRight []uint64
+}
+
+// InSync Checks if both environments executed the same migrations
+func (diff EnvironmentDiff) InSync() bool {
+	return len(diff.OnlyLeft) == 0 && len(diff.OnlyRight) == 0
+}
+
+// Err Returns ErrEnvironmentsOutOfSync, with the differing versions, if the environments are
+// not in sync
+func (diff EnvironmentDiff) Err() error {
+	if diff.InSync() {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w, executed only in %s: %v, executed only in %s: %v",
+		ErrEnvironmentsOutOfSync, diff.Left, diff.OnlyLeft, diff.Right, diff.OnlyRight,
+	)
+}
+
+// DiffEnvironments Compares the executed (finished) migrations of two environments. Can be
+// used to verify that environments are in sync before promoting a release. The states can be
+// loaded from repositories (see LoadEnvironmentState) or from exports (see ExportState).
+func DiffEnvironments(left EnvironmentState, right EnvironmentState) EnvironmentDiff {
+	leftVersions := finishedVersions(left.Executions)
+	rightVersions := finishedVersions(right.Executions)
+	diff := EnvironmentDiff{Left: left.Name, Right: right.Name}
+
+	for version := range leftVersions {
+		if !rightVersions[version] {
+			diff.OnlyLeft = append(diff.OnlyLeft, version)
+		}
+	}
+
+	for version := range rightVersions {
+		if !leftVersions[version] {
+			diff.OnlyRight = append(diff.OnlyRight, version)
+		}
+	}
+
+	slices.Sort(diff.OnlyLeft)
+	slices.Sort(diff.OnlyRight)
+	return diff
+}
+
+// LoadEnvironmentState Loads the executions state of an environment from its repository
+func LoadEnvironmentState(name string, repo execution.Repository) (EnvironmentState, error) {
+	executions, err := repo.LoadExecutions()
+	if err != nil {
+		return EnvironmentState{}, fmt.Errorf(
+			"failed to load %s state, failed to load executions with error: %w", name, err,
+		)
+	}
+
+	return EnvironmentState{Name: name, Executions: executions}, nil
+}
+
+// State Loads the executions state of the environment managed by the handler
+func (handler *MigrationsHandler) State(name string) (EnvironmentState, error) {
+	return LoadEnvironmentState(name, handler.repository)
+}
+
+func finishedVersions(executions []execution.MigrationExecution) map[uint64]bool {
+	versions := make(map[uint64]bool)
+	for _, exec := range executions {
+		if exec.Finished() {
+			versions[exec.Version] = true
+		}
+	}
+	return versions
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type DiffTestSuite struct {
+	suite.Suite
+}
+
+func TestDiffTestSuite(t *testing.T) {
+	suite.Run(t, new(DiffTestSuite))
+}
+
+func (suite *DiffTestSuite) TestItCanDiffEnvironments() {
+	staging := EnvironmentState{
+		Name: "staging",
+		Executions: append(
+			finishedExecutions(4, 1, 2, 3),
+			execution.MigrationExecution{Version: 5, ExecutedAtMs: 1},
+		),
+	}
+	production := EnvironmentState{Name: "production", Executions: finishedExecutions(1, 6)}
+
+	diff := DiffEnvironments(staging, production)
+
+	suite.Assert().False(diff.InSync())
+	suite.Assert().Equal("staging", diff.Left)
+	suite.Assert().Equal("production", diff.Right)
+	suite.Assert().Equal([]uint64{2, 3, 4}, diff.OnlyLeft)
+	suite.Assert().Equal([]uint64{6}, diff.OnlyRight)
+	suite.Assert().ErrorIs(diff.Err(), ErrEnvironmentsOutOfSync)
+}
+
+func (suite *DiffTestSuite) TestItReportsEnvironmentsInSync() {
+	diff := DiffEnvironments(
+		EnvironmentState{Name: "staging", Executions: finishedExecutions(1, 2)},
+		EnvironmentState{Name: "production", Executions: finishedExecutions(2, 1)},
+	)
+
+	suite.Assert().True(diff.InSync())
+	suite.Assert().NoError(diff.Err())
+}
+
+func (suite *DiffTestSuite) TestItCanLoadEnvironmentStateFromRepository() {
+	repo := &execution.InMemoryRepository{PersistedExecutions: finishedExecutions(1, 2)}
+
+	state, err := LoadEnvironmentState("staging", repo)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(EnvironmentState{"staging", finishedExecutions(1, 2)}, state)
+
+	handler, _ := NewHandler(migration.NewGenericRegistry(), repo, nil)
+	state, err = handler.State("current")
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(EnvironmentState{"current", finishedExecutions(1, 2)}, state)
+}
+
+func (suite *DiffTestSuite) TestItFailsToLoadEnvironmentStateWhenRepositoryFails() {
+	loadErr := errors.New("load failed")
+	repo := &execution.InMemoryRepository{LoadErr: loadErr}
+
+	_, err := LoadEnvironmentState("staging", repo)
+
+	suite.Assert().ErrorIs(err, loadErr)
+}
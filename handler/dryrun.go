@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+)
+
+// DryRunMigration Value object which holds the SQL statements a migration's Up() would execute.
+// Captured is false if the migration does not implement migration.SQLDryRunner, in which
+// case its statements could not be captured.
+type DryRunMigration struct {
+	Migration  migration.Migration
+	Captured   bool
+	Statements []sqlcapture.Statement
+}
+
+// DryRunUp Same as MigrateUp, but Up() is called with a capturing db handle (see
+// migration.SQLDryRunner), so statements are recorded instead of executed. No execution
+// is persisted. Migrations which do not implement migration.SQLDryRunner are not run at all,
+// neither are the ones from the skip list (see WithSkipList).
+func (handler *MigrationsHandler) DryRunUp(numOfRuns NumOfRuns) ([]DryRunMigration, error) {
+	errMsg := "failed to dry-run up"
+
+	plan, err := handler.plan()
+	if err != nil {
+		return []DryRunMigration{}, fmt.Errorf(
+			"%s, failed to create execution plan with error: %w", errMsg, err,
+		)
+	}
+
+	allToBeExec := plan.AllToBeExecuted()
+	// Migrations from the skip list count as runs, like in MigrateUp, but are never executed
+	allToBeExec = handler.withoutSkipped(allToBeExec[:min(len(allToBeExec), int(numOfRuns))])
+
+	var dryRuns []DryRunMigration
+	for i := 0; i < len(allToBeExec); i++ {
+		dryRun, err := dryRunUp(allToBeExec[i])
+		dryRuns = append(dryRuns, dryRun)
+
+		if err != nil {
+			return dryRuns, fmt.Errorf("%s, %w", errMsg, err)
+		}
+	}
+
+	return dryRuns, nil
+}
+
+// DryRunForceUp Same as ForceUp, but Up() is called with a capturing db handle, so statements
+// are recorded instead of executed. No execution is persisted.
+func (handler *MigrationsHandler) DryRunForceUp(version uint64) (DryRunMigration, error) {
+	mig := handler.registry.Get(version)
+	if mig == nil {
+		return DryRunMigration{}, nil
+	}
+
+	dryRun, err := dryRunUp(mig)
+	if err != nil {
+		err = fmt.Errorf("failed to dry-run up forcefully, %w", err)
+	}
+
+	return dryRun, err
+}
+
+func dryRunUp(mig migration.Migration) (DryRunMigration, error) {
+	dryRunner, ok := mig.(migration.SQLDryRunner)
+	if !ok {
+		return DryRunMigration{Migration: mig}, nil
+	}
+
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	err := dryRunner.WithDB(db).Up()
+	dryRun := DryRunMigration{Migration: mig, Captured: true, Statements: recorder.Statements()}
+
+	if err != nil {
+		return dryRun, fmt.Errorf(
+			"up() for migration %d failed with error: %w", mig.Version(), err,
+		)
+	}
+
+	return dryRun, nil
+}
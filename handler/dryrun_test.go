@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type DryRunTestSuite struct {
+	suite.Suite
+}
+
+func TestDryRunTestSuite(t *testing.T) {
+	suite.Run(t, new(DryRunTestSuite))
+}
+
+type FakeDbMigration struct {
+	migration.DummyMigration
+	db *sql.DB
+}
+
+func (f *FakeDbMigration) Up() error {
+	_, err := f.db.Exec("INSERT INTO t VALUES (?)", f.Version())
+	return err
+}
+
+func (f *FakeDbMigration) WithDB(db *sql.DB) migration.Migration {
+	return &FakeDbMigration{f.DummyMigration, db}
+}
+
+func (suite *DryRunTestSuite) TestItCanDryRunUp() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeDbMigration{DummyMigration: *migration.NewDummyMigration(1)})
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(&FakeDbMigration{DummyMigration: *migration.NewDummyMigration(3)})
+
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	dryRuns, err := handler.DryRunUp(NumOfRuns(2))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(dryRuns, 2)
+	suite.Assert().True(dryRuns[0].Captured)
+	suite.Assert().Equal(
+		[]sqlcapture.Statement{{Query: "INSERT INTO t VALUES (?)", Args: []any{uint64(1)}}},
+		dryRuns[0].Statements,
+	)
+	suite.Assert().False(dryRuns[1].Captured)
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	dryRun, err := handler.DryRunForceUp(3)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]sqlcapture.Statement{{Query: "INSERT INTO t VALUES (?)", Args: []any{uint64(3)}}},
+		dryRun.Statements,
+	)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *DryRunTestSuite) TestItDoesNotDryRunSkippedMigrations() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeDbMigration{DummyMigration: *migration.NewDummyMigration(1)})
+	_ = registry.Register(&FakeDbMigration{DummyMigration: *migration.NewDummyMigration(2)})
+	_ = registry.Register(&FakeDbMigration{DummyMigration: *migration.NewDummyMigration(3)})
+
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSkipList(migration.SkipList{2: "superseded"}),
+	)
+
+	dryRuns, err := handler.DryRunUp(NumOfRuns(2))
+
+	suite.Assert().NoError(err)
+	suite.Require().Len(dryRuns, 1)
+	suite.Assert().Equal(uint64(1), dryRuns[0].Migration.Version())
+
+	dryRuns, err = handler.DryRunUp(NumOfRuns(10))
+
+	suite.Assert().NoError(err)
+	suite.Require().Len(dryRuns, 2)
+	suite.Assert().Equal(uint64(1), dryRuns[0].Migration.Version())
+	suite.Assert().Equal(uint64(3), dryRuns[1].Migration.Version())
+}
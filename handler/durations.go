@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// MigrationDuration How long a migration ran, as recorded by its execution
+type MigrationDuration struct {
+	Version  uint64
+	Duration time.Duration
+}
+
+// DurationStats Value object with the time spent running the executed migrations. Unfinished
+// and skipped executions are not included.
+type DurationStats struct {
+	Count int
+	Total time.Duration
+	// Slowest The slowest migrations, slowest first
+	Slowest []MigrationDuration
+}
+
+// Average Returns the average migration duration, 0 if no migration was executed
+func (stats DurationStats) Average() time.Duration {
+	if stats.Count == 0 {
+		return 0
+	}
+	return stats.Total / time.Duration(stats.Count)
+}
+
+// DurationStats Returns the time spent running the executed migrations and the top slowest
+// migrations, helping to spot problematic migrations before they run in production
+func (handler *MigrationsHandler) DurationStats(top int) (DurationStats, error) {
+	executions, err := handler.repository.LoadExecutions()
+	if err != nil {
+		return DurationStats{}, fmt.Errorf(
+			"failed to compute duration stats, failed to load executions with error: %w", err,
+		)
+	}
+
+	return durationStatsOf(executions, top), nil
+}
+
+// durationStatsOf Computes the duration stats of the provided executions
+func durationStatsOf(executions []execution.MigrationExecution, top int) DurationStats {
+	var stats DurationStats
+	var durations []MigrationDuration
+	for _, exec := range executions {
+		if !exec.Finished() || exec.Skipped() {
+			continue
+		}
+
+		stats.Count++
+		stats.Total += exec.Duration()
+		durations = append(durations, MigrationDuration{exec.Version, exec.Duration()})
+	}
+
+	slices.SortStableFunc(durations, func(a, b MigrationDuration) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	stats.Slowest = durations[:min(max(top, 0), len(durations))]
+
+	return stats
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type DurationsTestSuite struct {
+	suite.Suite
+}
+
+func TestDurationsTestSuite(t *testing.T) {
+	suite.Run(t, new(DurationsTestSuite))
+}
+
+func (suite *DurationsTestSuite) TestItComputesTheDurationStatsOfExecutedMigrations() {
+	registry := migration.NewGenericRegistry()
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1000, FinishedAtMs: 1500},
+			{Version: 2, ExecutedAtMs: 2000, FinishedAtMs: 2000, SkipReason: "superseded"},
+			{Version: 3, ExecutedAtMs: 3000, FinishedAtMs: 6000},
+			{Version: 4, ExecutedAtMs: 7000, FinishedAtMs: 8000},
+			{Version: 5, ExecutedAtMs: 9000},
+		},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	stats, err := handler.DurationStats(2)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(3, stats.Count)
+	suite.Assert().Equal(4500*time.Millisecond, stats.Total)
+	suite.Assert().Equal(1500*time.Millisecond, stats.Average())
+	suite.Assert().Equal(
+		[]MigrationDuration{{3, 3 * time.Second}, {4, time.Second}}, stats.Slowest,
+	)
+
+	stats, _ = handler.DurationStats(10)
+	suite.Assert().Len(stats.Slowest, 3)
+
+	stats, _ = handler.DurationStats(0)
+	suite.Assert().Empty(stats.Slowest)
+}
+
+func (suite *DurationsTestSuite) TestItHandlesMissingExecutionsAndLoadFailures() {
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(migration.NewGenericRegistry(), repo, nil)
+
+	stats, err := handler.DurationStats(5)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(0, stats.Count)
+	suite.Assert().Equal(time.Duration(0), stats.Average())
+
+	repo.LoadErr = errors.New("load err")
+	_, err = handler.DurationStats(5)
+	suite.Assert().ErrorIs(err, repo.LoadErr)
+}
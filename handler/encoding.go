@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+type jsonExecutedMigration struct {
+	Version   *uint64                       `json:"version"`
+	Execution *execution.MigrationExecution `json:"execution"`
+}
+
+// MarshalJSON Encodes the executed migration as its version and its execution. version is null
+// if there is no migration, execution is null if there is no execution.
+func (executed ExecutedMigration) MarshalJSON() ([]byte, error) {
+	encoded := jsonExecutedMigration{Execution: executed.Execution}
+
+	if executed.Migration != nil {
+		version := executed.Migration.Version()
+		encoded.Version = &version
+	}
+
+	return json.Marshal(encoded)
+}
+
+type jsonSummary struct {
+	RegisteredCount int                `json:"registeredCount"`
+	FinishedCount   int                `json:"finishedCount"`
+	PendingCount    int                `json:"pendingCount"`
+	LastExecuted    *ExecutedMigration `json:"lastExecuted"`
+	NextToExecute   *uint64            `json:"nextToExecute"`
+}
+
+// MarshalJSON Encodes the summary. lastExecuted and nextToExecute (a migration version) are null
+// if there is no such migration.
+func (s Summary) MarshalJSON() ([]byte, error) {
+	encoded := jsonSummary{
+		RegisteredCount: s.RegisteredCount,
+		FinishedCount:   s.FinishedCount,
+		PendingCount:    s.PendingCount(),
+	}
+
+	if s.LastExecuted.Migration != nil || s.LastExecuted.Execution != nil {
+		encoded.LastExecuted = &s.LastExecuted
+	}
+
+	if s.NextToExecute != nil {
+		next := s.NextToExecute.Version()
+		encoded.NextToExecute = &next
+	}
+
+	return json.Marshal(encoded)
+}
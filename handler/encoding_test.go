@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type EncodingTestSuite struct {
+	suite.Suite
+}
+
+func TestEncodingTestSuite(t *testing.T) {
+	suite.Run(t, new(EncodingTestSuite))
+}
+
+func (suite *EncodingTestSuite) TestItCanEncodeExecutedMigrationsAsJson() {
+	encoded, err := json.Marshal(
+		[]ExecutedMigration{
+			{
+				migration.NewDummyMigration(1),
+				&execution.MigrationExecution{Version: 1, ExecutedAtMs: 1000, FinishedAtMs: 2000},
+			},
+			{migration.NewDummyMigration(2), nil},
+		},
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().JSONEq(
+		`[{"version":1,"execution":{"version":1,"executedAt":"1970-01-01T00:00:01.000Z",`+
+			`"finishedAt":"1970-01-01T00:00:02.000Z","finished":true,"executedAtMs":1000,`+
+			`"finishedAtMs":2000}},{"version":2,"execution":null}]`,
+		string(encoded),
+	)
+}
+
+func (suite *EncodingTestSuite) TestItCanEncodeSummaryAsJson() {
+	scenarios := map[string]struct {
+		summary      Summary
+		expectedJson string
+	}{
+		"empty": {
+			Summary{RegisteredCount: 1, NextToExecute: migration.NewDummyMigration(1)},
+			`{"registeredCount":1,"finishedCount":0,"pendingCount":1,"lastExecuted":null,` +
+				`"nextToExecute":1}`,
+		},
+		"all executed": {
+			Summary{
+				RegisteredCount: 1,
+				FinishedCount:   1,
+				LastExecuted: ExecutedMigration{
+					migration.NewDummyMigration(1),
+					&execution.MigrationExecution{
+						Version: 1, ExecutedAtMs: 1000, FinishedAtMs: 1000,
+					},
+				},
+			},
+			`{"registeredCount":1,"finishedCount":1,"pendingCount":0,"lastExecuted":{` +
+				`"version":1,"execution":{"version":1,"executedAt":"1970-01-01T00:00:01.000Z",` +
+				`"finishedAt":"1970-01-01T00:00:01.000Z","finished":true,"executedAtMs":1000,` +
+				`"finishedAtMs":1000}},"nextToExecute":null}`,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		encoded, err := json.Marshal(scenario.summary)
+		suite.Assert().NoError(err, "failed scenario %s", name)
+		suite.Assert().JSONEq(scenario.expectedJson, string(encoded), "failed scenario %s", name)
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrPlanInconsistent is wrapped by all errors returned when the persisted executions are in an
+// inconsistent state compared to the registered migrations (for example, more executions than
+// registered migrations or executions out of order)
+var ErrPlanInconsistent = errors.New("executions are inconsistent with registered migrations")
+
+// InconsistencyKind The kind of inconsistency which made building the execution plan fail
+type InconsistencyKind string
+
+const (
+	// InconsistencyExtraExecutions There are more executions than registered migrations
+	InconsistencyExtraExecutions InconsistencyKind = "extra_executions"
+	// InconsistencyUnfinishedExecution An execution other than the last one is not finished
+	InconsistencyUnfinishedExecution InconsistencyKind = "unfinished_execution"
+	// InconsistencyOutOfOrder An execution does not match the registered migration at the same
+	// position
+	InconsistencyOutOfOrder InconsistencyKind = "out_of_order"
+)
+
+// InconsistentPlanError is returned (wrapped) when the execution plan can not be built because
+// the persisted executions are inconsistent with the registered migrations. Wraps
+// ErrPlanInconsistent. Can be checked with errors.As to find the kind of inconsistency.
+type InconsistentPlanError struct {
+	Kind InconsistencyKind
+	// Version The version of the execution the inconsistency was detected at. 0 for
+	// InconsistencyExtraExecutions
+	Version uint64
+	detail  string
+}
+
+func (e *InconsistentPlanError) Error() string {
+	return ErrPlanInconsistent.Error() + ": " + e.detail
+}
+
+func (e *InconsistentPlanError) Unwrap() error {
+	return ErrPlanInconsistent
+}
+
+// MigrationStage The migration step which was running when a migration failed
+type MigrationStage string
+
+const (
+	StageValidate MigrationStage = "validate"
+	StageUp       MigrationStage = "up"
+	StageDown     MigrationStage = "down"
+	// StageSave Saving the execution, after Up() (or after it failed)
+	StageSave MigrationStage = "save"
+	// StageRemove Removing the execution, after Down()
+	StageRemove MigrationStage = "remove"
+	// StagePrepare Preparing a two-phase migration (see migration.TwoPhase)
+	StagePrepare MigrationStage = "prepare"
+	// StageAbort Aborting the preparation of a two-phase migration
+	StageAbort MigrationStage = "abort"
+)
+
+// MigrationFailedError is returned (wrapped) when a migration's Validate(), Up() or Down() fails,
+// or when its execution could not be saved or removed. Can be checked with errors.As to find
+// which migration failed, in which direction, at which stage and after how long.
+type MigrationFailedError struct {
+	Version uint64
+	// Direction StageUp or StageDown, the direction in which the migration was running
+	Direction MigrationStage
+	Stage     MigrationStage
+	// Elapsed The time from the migration start until it failed. 0 for validation failures
+	Elapsed time.Duration
+	Err     error
+	// Stack The stack trace of the goroutine which detected the failure
+	Stack []byte
+}
+
+func (e *MigrationFailedError) Error() string {
+	return fmt.Sprintf("migration %d failed at stage %s with error: %s", e.Version, e.Stage, e.Err)
+}
+
+func (e *MigrationFailedError) Unwrap() error {
+	return e.Err
+}
+
+// newMigrationFailed Wraps a non nil error returned by a migration's Up() or Down(), or by the
+// repository, when saving or removing its execution
+func newMigrationFailed(version uint64, stage MigrationStage, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	direction := StageUp
+	if stage == StageDown || stage == StageRemove {
+		direction = StageDown
+	}
+
+	return &MigrationFailedError{
+		Version: version, Direction: direction, Stage: stage, Err: err, Stack: debug.Stack(),
+	}
+}
+
+// withElapsed Sets the elapsed time of the migration failure wrapped by err, if any
+func withElapsed(err error, elapsed time.Duration) error {
+	var failed *MigrationFailedError
+	if errors.As(err, &failed) && failed.Elapsed == 0 {
+		failed.Elapsed = elapsed
+	}
+	return err
+}
+
+// validateMigrations Calls Validate() for all provided migrations which implement
+// migration.Validator. Returns all validation failures joined.
+func validateMigrations(migrations []migration.Migration) error {
+	var failures []error
+	for _, mig := range migrations {
+		if validator, isValidator := mig.(migration.Validator); isValidator {
+			failures = append(
+				failures, newMigrationFailed(mig.Version(), StageValidate, validator.Validate()),
+			)
+		}
+	}
+	return errors.Join(failures...)
+}
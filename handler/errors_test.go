@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorsTestSuite struct {
+	suite.Suite
+}
+
+func TestErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsTestSuite))
+}
+
+type FailingMigration struct {
+	migration.DummyMigration
+	err error
+}
+
+func (f *FailingMigration) Up() error {
+	return f.err
+}
+
+func (f *FailingMigration) Down() error {
+	return f.err
+}
+
+func (suite *ErrorsTestSuite) TestItReturnsTypedErrorWhenMigrationFails() {
+	migErr := errors.New("mig err")
+	scenarios := map[string]struct {
+		executions    []execution.MigrationExecution
+		run           func(handler *MigrationsHandler) error
+		expectedStage MigrationStage
+	}{
+		"migrate up": {
+			nil,
+			func(handler *MigrationsHandler) error {
+				_, err := handler.MigrateUp(1)
+				return err
+			},
+			StageUp,
+		},
+		"migrate down": {
+			[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			func(handler *MigrationsHandler) error {
+				_, err := handler.MigrateDown(1)
+				return err
+			},
+			StageDown,
+		},
+		"force up": {
+			nil,
+			func(handler *MigrationsHandler) error {
+				_, err := handler.ForceUp(1)
+				return err
+			},
+			StageUp,
+		},
+		"force down": {
+			[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			func(handler *MigrationsHandler) error {
+				_, err := handler.ForceDown(1)
+				return err
+			},
+			StageDown,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		registry := migration.NewGenericRegistry()
+		_ = registry.Register(&FailingMigration{*migration.NewDummyMigration(1), migErr})
+		repo := &execution.InMemoryRepository{PersistedExecutions: scenario.executions}
+		handler, _ := NewHandler(registry, repo, nil)
+
+		err := scenario.run(handler)
+
+		var failedErr *MigrationFailedError
+		suite.Assert().ErrorAs(err, &failedErr, "failed scenario %s", name)
+		suite.Assert().ErrorIs(err, migErr, "failed scenario %s", name)
+		suite.Assert().Equal(uint64(1), failedErr.Version, "failed scenario %s", name)
+		suite.Assert().Equal(scenario.expectedStage, failedErr.Stage, "failed scenario %s", name)
+		suite.Assert().Equal(
+			scenario.expectedStage, failedErr.Direction, "failed scenario %s", name,
+		)
+	}
+}
+
+func (suite *ErrorsTestSuite) TestItReturnsTypedErrorWhenExecutionCanNotBePersisted() {
+	repoErr := errors.New("repo err")
+	executed := []execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}}
+	scenarios := map[string]struct {
+		repo              *execution.InMemoryRepository
+		run               func(handler *MigrationsHandler) error
+		expectedDirection MigrationStage
+		expectedStage     MigrationStage
+	}{
+		"migrate up": {
+			&execution.InMemoryRepository{SaveErr: repoErr},
+			func(handler *MigrationsHandler) error {
+				_, err := handler.MigrateUp(1)
+				return err
+			},
+			StageUp,
+			StageSave,
+		},
+		"migrate down": {
+			&execution.InMemoryRepository{PersistedExecutions: executed, RemoveErr: repoErr},
+			func(handler *MigrationsHandler) error {
+				_, err := handler.MigrateDown(1)
+				return err
+			},
+			StageDown,
+			StageRemove,
+		},
+		"force up": {
+			&execution.InMemoryRepository{SaveErr: repoErr},
+			func(handler *MigrationsHandler) error {
+				_, err := handler.ForceUp(1)
+				return err
+			},
+			StageUp,
+			StageSave,
+		},
+		"force down": {
+			&execution.InMemoryRepository{PersistedExecutions: executed, RemoveErr: repoErr},
+			func(handler *MigrationsHandler) error {
+				_, err := handler.ForceDown(1)
+				return err
+			},
+			StageDown,
+			StageRemove,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		registry := migration.NewGenericRegistry()
+		_ = registry.Register(migration.NewDummyMigration(1))
+		handler, _ := NewHandler(registry, scenario.repo, nil)
+
+		err := scenario.run(handler)
+
+		var failedErr *MigrationFailedError
+		suite.Assert().ErrorAs(err, &failedErr, "failed scenario %s", name)
+		suite.Assert().ErrorIs(err, repoErr, "failed scenario %s", name)
+		suite.Assert().Equal(uint64(1), failedErr.Version, "failed scenario %s", name)
+		suite.Assert().Equal(
+			scenario.expectedDirection, failedErr.Direction, "failed scenario %s", name,
+		)
+		suite.Assert().Equal(scenario.expectedStage, failedErr.Stage, "failed scenario %s", name)
+	}
+}
+
+type SlowFailingMigration struct {
+	migration.DummyMigration
+	clock *clock.Fixed
+}
+
+func (s *SlowFailingMigration) Up() error {
+	s.clock.Advance(1500 * time.Millisecond)
+	return errors.New("mig err")
+}
+
+func (suite *ErrorsTestSuite) TestItReportsTheElapsedTimeOfFailedMigrations() {
+	fixedClock := clock.NewFixed(time.Now())
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&SlowFailingMigration{*migration.NewDummyMigration(1), fixedClock},
+	)
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithClock(fixedClock),
+	)
+
+	_, err := handler.MigrateUp(1)
+
+	var failedErr *MigrationFailedError
+	suite.Assert().ErrorAs(err, &failedErr)
+	suite.Assert().Equal(StageUp, failedErr.Stage)
+	suite.Assert().Equal(1500*time.Millisecond, failedErr.Elapsed)
+
+	_, err = handler.ForceUp(1)
+	suite.Assert().ErrorAs(err, &failedErr)
+	suite.Assert().Equal(1500*time.Millisecond, failedErr.Elapsed)
+}
+
+func (suite *ErrorsTestSuite) TestItReturnsPlanInconsistentError() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{{Version: 2, ExecutedAtMs: 1}},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	_, err := handler.MigrateUp(1)
+
+	suite.Assert().ErrorIs(err, ErrPlanInconsistent)
+	var inconsistentErr *InconsistentPlanError
+	suite.Assert().ErrorAs(err, &inconsistentErr)
+	suite.Assert().Equal(InconsistencyOutOfOrder, inconsistentErr.Kind)
+	suite.Assert().Equal(uint64(2), inconsistentErr.Version)
+	suite.Assert().ErrorContains(
+		err, "executions are inconsistent with registered migrations: execution 2 at index 0",
+	)
+}
+
+type ValidatingMigration struct {
+	FakeUpMigration
+	err error
+}
+
+func (v *ValidatingMigration) Validate() error {
+	return v.err
+}
+
+func (suite *ErrorsTestSuite) TestItValidatesMigrationsBeforeExecutingAny() {
+	validationErr := errors.New("missing table")
+	first := &ValidatingMigration{
+		FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}, nil,
+	}
+	second := &ValidatingMigration{
+		FakeUpMigration{DummyMigration: *migration.NewDummyMigration(2)}, validationErr,
+	}
+	notInRun := &ValidatingMigration{
+		FakeUpMigration{DummyMigration: *migration.NewDummyMigration(3)}, validationErr,
+	}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(first)
+	_ = registry.Register(second)
+	_ = registry.Register(notInRun)
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	handled, err := handler.MigrateUp(NumOfRuns(2))
+
+	var failedErr *MigrationFailedError
+	suite.Assert().ErrorAs(err, &failedErr)
+	suite.Assert().Equal(uint64(2), failedErr.Version)
+	suite.Assert().Equal(StageValidate, failedErr.Stage)
+	suite.Assert().ErrorIs(err, validationErr)
+	suite.Assert().Empty(handled)
+	suite.Assert().False(first.upRan)
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	_, err = handler.ForceUp(3)
+	suite.Assert().ErrorIs(err, validationErr)
+	suite.Assert().False(notInRun.upRan)
+
+	handled, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(handled, 1)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// GolangMigrateStateFileName The name of the file, generated on export, which includes the
+// SQL needed to initialize golang-migrate's state table
+const GolangMigrateStateFileName = "schema_migrations.sql"
+
+// ExportGolangMigrate Exports all registered migrations into the specified directory, using
+// golang-migrate's file name conventions ({version}_{title}.up.sql, {version}_{title}.down.sql).
+// All registered migrations must implement migration.SQLRecorder and, optionally,
+// migration.DownSQLRecorder. If any execution is persisted, a GolangMigrateStateFileName file
+// is also generated, with the SQL needed to set golang-migrate's state to the last execution.
+// The files are only written if all of them can be written and none of them exists. Returns the
+// generated file names.
+func (handler *MigrationsHandler) ExportGolangMigrate(dirPath string) ([]string, error) {
+	errMsg := "failed to export migrations to golang-migrate layout"
+
+	plan, err := handler.plan()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%s, failed to create execution plan with error: %w", errMsg, err,
+		)
+	}
+
+	files := make(map[string][]byte)
+	var fileNames []string
+
+	for _, mig := range handler.registry.OrderedMigrations() {
+		upRecorder, ok := mig.(migration.SQLRecorder)
+		if !ok {
+			return nil, fmt.Errorf(
+				"%s, %w: %d", errMsg, ErrMigrationNotRecordable, mig.Version(),
+			)
+		}
+
+		baseName := strconv.FormatUint(mig.Version(), 10) + "_migration"
+
+		var upSQL bytes.Buffer
+		if err = upRecorder.RecordSQL(&upSQL); err != nil {
+			return nil, fmt.Errorf(
+				"%s, recording up sql for migration %d failed with error: %w",
+				errMsg, mig.Version(), err,
+			)
+		}
+		files[baseName+".up.sql"] = upSQL.Bytes()
+		fileNames = append(fileNames, baseName+".up.sql")
+
+		if downRecorder, ok := mig.(migration.DownSQLRecorder); ok {
+			var downSQL bytes.Buffer
+			if err = downRecorder.RecordDownSQL(&downSQL); err != nil {
+				return nil, fmt.Errorf(
+					"%s, recording down sql for migration %d failed with error: %w",
+					errMsg, mig.Version(), err,
+				)
+			}
+			files[baseName+".down.sql"] = downSQL.Bytes()
+			fileNames = append(fileNames, baseName+".down.sql")
+		}
+	}
+
+	if lastExecuted := plan.LastExecuted(); lastExecuted.Execution != nil {
+		files[GolangMigrateStateFileName] = golangMigrateState(*lastExecuted.Execution)
+		fileNames = append(fileNames, GolangMigrateStateFileName)
+	}
+
+	if err = writeExportFiles(dirPath, fileNames, files); err != nil {
+		return nil, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	return fileNames, nil
+}
+
+// writeExportFiles Writes the files to temporary files, in the directory, which are published
+// (hard linked to their final path) once all of them were written, so a failed export does not
+// leave partial files behind. Existing files are never overwritten, linking fails if a file was
+// created in the meantime.
+func writeExportFiles(dirPath string, fileNames []string, files map[string][]byte) (err error) {
+	tmpPaths := make(map[string]string)
+	var published []string
+	defer func() {
+		for _, tmpPath := range tmpPaths {
+			_ = os.Remove(tmpPath)
+		}
+		if err == nil {
+			return
+		}
+		for _, path := range published {
+			_ = os.Remove(path)
+		}
+	}()
+
+	for _, fileName := range fileNames {
+		tmp, createErr := os.CreateTemp(dirPath, fileName+".*.tmp")
+		if createErr != nil {
+			return fmt.Errorf("file creation failed with error: %w", createErr)
+		}
+		tmpPaths[fileName] = tmp.Name()
+
+		_, writeErr := tmp.Write(files[fileName])
+		if err = errors.Join(writeErr, tmp.Close()); err != nil {
+			return fmt.Errorf("file write failed with error: %w", err)
+		}
+	}
+
+	for _, fileName := range fileNames {
+		path := filepath.Join(dirPath, fileName)
+		if err = os.Link(tmpPaths[fileName], path); errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("file %s already exists: %w", fileName, os.ErrExist)
+		} else if err != nil {
+			return fmt.Errorf("file publishing failed with error: %w", err)
+		}
+		published = append(published, path)
+	}
+
+	return nil
+}
+
+func golangMigrateState(lastExecution execution.MigrationExecution) []byte {
+	return []byte(
+		"CREATE TABLE IF NOT EXISTS " + execution.GolangMigrateTable +
+			" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL);\n" +
+			"DELETE FROM " + execution.GolangMigrateTable + ";\n" +
+			"INSERT INTO " + execution.GolangMigrateTable + " (version, dirty) VALUES (" +
+			strconv.FormatUint(lastExecution.Version, 10) + ", " +
+			strconv.FormatBool(!lastExecution.Finished()) + ");\n",
+	)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExportTestSuite struct {
+	suite.Suite
+}
+
+func TestExportTestSuite(t *testing.T) {
+	suite.Run(t, new(ExportTestSuite))
+}
+
+type FakeReversibleSQLMigration struct {
+	FakeSQLMigration
+	downSQL string
+}
+
+func (f *FakeReversibleSQLMigration) RecordDownSQL(w io.Writer) error {
+	_, err := io.WriteString(w, f.downSQL)
+	return err
+}
+
+func (suite *ExportTestSuite) TestItCanExportToGolangMigrateLayout() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&FakeReversibleSQLMigration{
+			FakeSQLMigration{*migration.NewDummyMigration(1), "CREATE TABLE a (id int);"},
+			"DROP TABLE a;",
+		},
+	)
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(2), "SELECT 2;"})
+
+	repo := &execution.InMemoryRepository{}
+	repo.SaveAll([]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 2}})
+	handler, _ := NewHandler(registry, repo, nil)
+
+	dir := suite.T().TempDir()
+	fileNames, err := handler.ExportGolangMigrate(dir)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]string{
+			"1_migration.up.sql", "1_migration.down.sql", "2_migration.up.sql",
+			GolangMigrateStateFileName,
+		},
+		fileNames,
+	)
+
+	contents, _ := os.ReadFile(filepath.Join(dir, "1_migration.down.sql"))
+	suite.Assert().Equal("DROP TABLE a;", string(contents))
+
+	contents, _ = os.ReadFile(filepath.Join(dir, GolangMigrateStateFileName))
+	suite.Assert().Contains(string(contents), "VALUES (1, false)")
+}
+
+func (suite *ExportTestSuite) TestItFailsToExportWhenMigrationIsNotRecordable() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	dir := suite.T().TempDir()
+	fileNames, err := handler.ExportGolangMigrate(dir)
+
+	suite.Assert().ErrorIs(err, ErrMigrationNotRecordable)
+	suite.Assert().Empty(fileNames)
+
+	entries, _ := os.ReadDir(dir)
+	suite.Assert().Empty(entries)
+}
+
+func (suite *ExportTestSuite) TestItDoesNotWritePartialExports() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(1), "SELECT 1;"})
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(2), "SELECT 2;"})
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	dir := suite.T().TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "2_migration.up.sql"), []byte("SELECT 0;"), 0600)
+	fileNames, err := handler.ExportGolangMigrate(dir)
+
+	suite.Assert().ErrorIs(err, os.ErrExist)
+	suite.Assert().ErrorContains(err, "file 2_migration.up.sql already exists")
+	suite.Assert().Empty(fileNames)
+
+	entries, _ := os.ReadDir(dir)
+	suite.Require().Len(entries, 1)
+	contents, _ := os.ReadFile(filepath.Join(dir, "2_migration.up.sql"))
+	suite.Assert().Equal("SELECT 0;", string(contents))
+
+	_ = os.Remove(filepath.Join(dir, "2_migration.up.sql"))
+	fileNames, err = handler.ExportGolangMigrate(dir)
+	suite.Assert().NoError(err)
+	entries, _ = os.ReadDir(dir)
+	suite.Assert().Len(entries, len(fileNames))
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/schema"
+)
+
+// ErrNoSnapshot is returned when a fresh database can not be fast-forwarded because there is
+// no stored schema snapshot
+var ErrNoSnapshot = errors.New("no schema snapshot found")
+
+// FastForwardFromSnapshot Brings a fresh database (no executions persisted) to the state of the
+// latest stored schema snapshot: the snapshot is restored and all registered migrations with
+// a version lower or equal to the snapshot version are marked as executed, without running
+// them. Remaining migrations can then be executed with MigrateUp.
+// Returns the migrations marked as executed.
+func (handler *MigrationsHandler) FastForwardFromSnapshot(
+	store schema.SnapshotStore,
+	restorer schema.Restorer,
+) ([]ExecutedMigration, error) {
+	errMsg := "failed to fast-forward from snapshot"
+
+	if err := handler.checkWritable("fresh"); err != nil {
+		return nil, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	if err := handler.guard("fresh"); err != nil {
+		return nil, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	executions, err := handler.repository.LoadExecutions()
+	if err != nil {
+		return nil, fmt.Errorf("%s, failed to load executions with error: %w", errMsg, err)
+	}
+
+	if len(executions) > 0 {
+		return nil, fmt.Errorf("%s, %w", errMsg, ErrStateNotEmpty)
+	}
+
+	version, dump, found, err := store.LatestSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("%s, failed to load snapshot with error: %w", errMsg, err)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%s, %w", errMsg, ErrNoSnapshot)
+	}
+
+	if handler.registry.Get(version) == nil {
+		return nil, fmt.Errorf(
+			"%s, snapshot version %d is not a registered migration", errMsg, version,
+		)
+	}
+
+	if err = restorer.Restore(dump); err != nil {
+		return nil, fmt.Errorf("%s, restore failed with error: %w", errMsg, err)
+	}
+
+	var handledMigrations []ExecutedMigration
+	for _, mig := range handler.registry.OrderedMigrations() {
+		if mig.Version() > version {
+			break
+		}
+
+		exec := handler.startExecution(mig)
+		exec.FinishExecutionAt(handler.clock.Now())
+
+		if err = handler.repository.Save(*exec); err != nil {
+			return handledMigrations, fmt.Errorf(
+				"%s, failed to save execution %d with error: %w", errMsg, mig.Version(), err,
+			)
+		}
+
+		handledMigrations = append(handledMigrations, ExecutedMigration{mig, exec})
+	}
+
+	return handledMigrations, nil
+}
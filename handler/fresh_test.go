@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/schema"
+	"github.com/stretchr/testify/suite"
+)
+
+type FreshTestSuite struct {
+	suite.Suite
+}
+
+func TestFreshTestSuite(t *testing.T) {
+	suite.Run(t, new(FreshTestSuite))
+}
+
+type FakeRestorer struct {
+	restored []string
+}
+
+func (f *FakeRestorer) Restore(dump string) error {
+	f.restored = append(f.restored, dump)
+	return nil
+}
+
+func (suite *FreshTestSuite) newRegistry() *migration.GenericRegistry {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	return registry
+}
+
+func (suite *FreshTestSuite) TestItCanFastForwardFromSnapshot() {
+	store := &schema.DirSnapshotStore{DirPath: suite.T().TempDir()}
+	_ = store.SaveSnapshot(1, "dump 1")
+	_ = store.SaveSnapshot(2, "dump 2")
+
+	repo := &execution.InMemoryRepository{}
+	restorer := &FakeRestorer{}
+	handler, _ := NewHandler(suite.newRegistry(), repo, nil)
+
+	handled, err := handler.FastForwardFromSnapshot(store, restorer)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(handled, 2)
+	suite.Assert().Equal([]string{"dump 2"}, restorer.restored)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().True(repo.PersistedExecutions[1].Finished())
+
+	plan, _ := NewPlan(handler.registry, repo)
+	suite.Assert().Equal(uint64(3), plan.NextToExecute().Version())
+}
+
+func (suite *FreshTestSuite) TestItFailsToFastForwardInvalidState() {
+	scenarios := map[string]struct {
+		executions  []execution.MigrationExecution
+		snapshots   map[uint64]string
+		expectedErr string
+	}{
+		"not fresh": {
+			[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			map[uint64]string{1: "dump"},
+			ErrStateNotEmpty.Error(),
+		},
+		"no snapshot": {nil, map[uint64]string{}, ErrNoSnapshot.Error()},
+		"unknown version": {
+			nil, map[uint64]string{7: "dump"}, "is not a registered migration",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		store := &schema.DirSnapshotStore{DirPath: suite.T().TempDir()}
+		for version, dump := range scenario.snapshots {
+			_ = store.SaveSnapshot(version, dump)
+		}
+
+		restorer := &FakeRestorer{}
+		repo := &execution.InMemoryRepository{PersistedExecutions: scenario.executions}
+		handler, _ := NewHandler(suite.newRegistry(), repo, nil)
+
+		_, err := handler.FastForwardFromSnapshot(store, restorer)
+
+		suite.Assert().ErrorContains(err, scenario.expectedErr, "failed scenario %s", name)
+		suite.Assert().Empty(restorer.restored, "failed scenario %s", name)
+	}
+}
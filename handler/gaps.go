@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// VersionGap A registered migration which is pending, although migrations ordered after it were
+// executed (for example, a migration from an old branch, merged late), or an execution of a
+// migration which is not registered. Both make the executions inconsistent with the registered
+// migrations (see ErrPlanInconsistent).
+type VersionGap struct {
+	Version uint64
+	// Registered false if the migration was executed, but is not registered
+	Registered bool
+	// ExecutedAfter The last executed migration, ordered after the pending migration. 0 for
+	// executions of migrations which are not registered.
+	ExecutedAfter uint64
+	// Remediations Suggested ways to fix the gap
+	Remediations []string
+}
+
+func (gap VersionGap) String() string {
+	description := fmt.Sprintf("migration %d was executed, but is not registered", gap.Version)
+	if gap.Registered {
+		description = fmt.Sprintf(
+			"migration %d is pending, but migration %d, ordered after it, was executed",
+			gap.Version, gap.ExecutedAfter,
+		)
+	}
+
+	return description + ". Suggested fixes: " + strings.Join(gap.Remediations, "; ")
+}
+
+// VersionGaps Analyzes the registered migrations and their executions, without requiring them
+// to be consistent, and returns the pending migrations ordered before executed ones, followed by
+// the executions of migrations which are not registered, each with remediation suggestions.
+// The migrations and executions covered by the baseline are ignored.
+func (handler *MigrationsHandler) VersionGaps() ([]VersionGap, error) {
+	errMsg := "failed to detect version gaps"
+
+	// The plan of an empty repository holds the registered migrations in the execution order
+	ordered, err := handler.planFor(&execution.InMemoryRepository{})
+	if err != nil {
+		return nil, fmt.Errorf("%s, failed to order migrations with error: %w", errMsg, err)
+	}
+
+	repository := handler.repository
+	if handler.baseline != 0 {
+		repository = &baselineRepository{repository, handler.baseline}
+	}
+
+	executions, err := repository.LoadExecutions()
+	if err != nil {
+		return nil, fmt.Errorf("%s, failed to load executions with error: %w", errMsg, err)
+	}
+
+	executed := make(map[uint64]bool, len(executions))
+	for _, exec := range executions {
+		executed[exec.Version] = true
+	}
+
+	lastExecuted := -1
+	registered := make(map[uint64]bool, len(ordered.orderedMigrations))
+	for i, mig := range ordered.orderedMigrations {
+		registered[mig.Version()] = true
+		if executed[mig.Version()] {
+			lastExecuted = i
+		}
+	}
+
+	var gaps []VersionGap
+	for i := 0; i < lastExecuted; i++ {
+		version := ordered.orderedMigrations[i].Version()
+		if executed[version] {
+			continue
+		}
+
+		executedAfter := ordered.orderedMigrations[lastExecuted].Version()
+		gaps = append(gaps, VersionGap{
+			Version:       version,
+			Registered:    true,
+			ExecutedAfter: executedAfter,
+			Remediations: []string{
+				fmt.Sprintf(
+					"if it does not depend on the migrations executed after it, run it out of"+
+						" order with \"force:up %d\"", version,
+				),
+				fmt.Sprintf(
+					"renumber it to a version greater than %d, so it runs after the executed"+
+						" migrations", executedAfter,
+				),
+				fmt.Sprintf(
+					"if its changes are already applied in all environments, set the baseline"+
+						" to %d (see migrations.baseline), which also ignores all migrations"+
+						" before it", version,
+				),
+			},
+		})
+	}
+
+	var unregistered []uint64
+	for _, exec := range executions {
+		if !registered[exec.Version] && !slices.Contains(unregistered, exec.Version) {
+			unregistered = append(unregistered, exec.Version)
+		}
+	}
+	slices.Sort(unregistered)
+
+	for _, version := range unregistered {
+		gaps = append(gaps, VersionGap{
+			Version: version,
+			Remediations: []string{
+				"register the migration again, if its file was removed by mistake or was" +
+					" merged from another branch",
+				fmt.Sprintf(
+					"if its file was archived, set the baseline to at least %d (see"+
+						" migrations.baseline)", version,
+				),
+			},
+		})
+	}
+
+	return gaps, nil
+}
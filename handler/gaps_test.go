@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type GapsTestSuite struct {
+	suite.Suite
+}
+
+func TestGapsTestSuite(t *testing.T) {
+	suite.Run(t, new(GapsTestSuite))
+}
+
+func (suite *GapsTestSuite) TestItDetectsVersionGaps() {
+	scenarios := map[string]struct {
+		executedVersions []uint64
+		baseline         uint64
+		expectedGaps     []VersionGap
+	}{
+		"no executions": {nil, 0, nil},
+		"in order":      {[]uint64{1, 2}, 0, nil},
+		"merged late": {
+			[]uint64{1, 3, 4}, 0,
+			[]VersionGap{{Version: 2, Registered: true, ExecutedAfter: 4}},
+		},
+		"multiple gaps": {
+			[]uint64{3}, 0,
+			[]VersionGap{
+				{Version: 1, Registered: true, ExecutedAfter: 3},
+				{Version: 2, Registered: true, ExecutedAfter: 3},
+			},
+		},
+		"not registered": {
+			[]uint64{1, 2, 7, 5}, 0,
+			[]VersionGap{{Version: 5}, {Version: 7}},
+		},
+		"covered by baseline": {[]uint64{3}, 2, nil},
+	}
+
+	for name, scenario := range scenarios {
+		registry := migration.NewGenericRegistry()
+		for version := uint64(1); version <= 4; version++ {
+			_ = registry.Register(migration.NewDummyMigration(version))
+		}
+		repo := &execution.InMemoryRepository{}
+		for _, version := range scenario.executedVersions {
+			_ = repo.Save(
+				execution.MigrationExecution{Version: version, ExecutedAtMs: 1, FinishedAtMs: 1},
+			)
+		}
+		handler, _ := NewHandler(registry, repo, nil, WithBaseline(scenario.baseline))
+
+		gaps, err := handler.VersionGaps()
+
+		suite.Assert().NoError(err, "failed scenario %s", name)
+		suite.Assert().Len(gaps, len(scenario.expectedGaps), "failed scenario %s", name)
+		for i, gap := range gaps {
+			expected := scenario.expectedGaps[i]
+			suite.Assert().Equal(expected.Version, gap.Version, "failed scenario %s", name)
+			suite.Assert().Equal(expected.Registered, gap.Registered, "failed scenario %s", name)
+			suite.Assert().Equal(
+				expected.ExecutedAfter, gap.ExecutedAfter, "failed scenario %s", name,
+			)
+			suite.Assert().NotEmpty(gap.Remediations, "failed scenario %s", name)
+		}
+	}
+}
+
+func (suite *GapsTestSuite) TestItSuggestsRemediationsForVersionGaps() {
+	pending := VersionGap{
+		Version:       2,
+		Registered:    true,
+		ExecutedAfter: 4,
+		Remediations:  []string{"a", "b"},
+	}
+	notRegistered := VersionGap{Version: 5, Remediations: []string{"c"}}
+
+	suite.Assert().Equal(
+		"migration 2 is pending, but migration 4, ordered after it, was executed."+
+			" Suggested fixes: a; b",
+		pending.String(),
+	)
+	suite.Assert().Equal(
+		"migration 5 was executed, but is not registered. Suggested fixes: c",
+		notRegistered.String(),
+	)
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	gaps, _ := handler.VersionGaps()
+
+	suite.Assert().Len(gaps, 1)
+	suite.Assert().Contains(gaps[0].String(), "force:up 1")
+	suite.Assert().Contains(gaps[0].String(), "renumber it to a version greater than 2")
+	suite.Assert().Contains(gaps[0].String(), "set the baseline to 1")
+}
+
+func (suite *GapsTestSuite) TestItFailsToDetectVersionGapsWhenExecutionsCanNotBeLoaded() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{LoadErr: errors.New("connection lost")}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	gaps, err := handler.VersionGaps()
+
+	suite.Assert().Empty(gaps)
+	suite.Assert().ErrorContains(err, "connection lost")
+}
+
+func (suite *GapsTestSuite) TestItReportsVersionGapsInsteadOfTheInconsistentExecutions() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+			{Version: 3, ExecutedAtMs: 2, FinishedAtMs: 2},
+		},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	problems, err := handler.Validate(nil)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(problems, 1)
+	suite.Assert().Contains(problems[0], "migration 2 is pending, but migration 3")
+}
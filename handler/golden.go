@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrGoldenPlanMismatch is returned when a rendered execution plan does not match the contents
+// of the golden file it was compared against.
+var ErrGoldenPlanMismatch = errors.New("execution plan does not match golden file")
+
+// RenderPlan Renders the execution plan in a deterministic, human-readable form. Execution
+// timestamps are ignored, so the output changes only when the executed or the pending
+// migrations change. Can be used to snapshot-test that a release will execute exactly
+// the expected migrations.
+func RenderPlan(plan *ExecutionPlan) string {
+	var builder strings.Builder
+
+	builder.WriteString("executed:\n")
+	for _, execMig := range plan.AllExecuted() {
+		state := "finished"
+		if !execMig.Execution.Finished() {
+			state = "unfinished"
+		}
+		builder.WriteString(
+			"  " + strconv.FormatUint(execMig.Migration.Version(), 10) + " " + state + "\n",
+		)
+	}
+
+	builder.WriteString("to be executed:\n")
+	for _, mig := range plan.AllToBeExecuted() {
+		builder.WriteString("  " + strconv.FormatUint(mig.Version(), 10) + "\n")
+	}
+
+	return builder.String()
+}
+
+// CompareGoldenPlan Compares the rendered execution plan (see RenderPlan) with the contents of
+// the golden file found at goldenFilePath. If update is true, the golden file is (re)written
+// with the rendered plan instead. Errors with ErrGoldenPlanMismatch if the contents differ.
+func CompareGoldenPlan(plan *ExecutionPlan, goldenFilePath string, update bool) error {
+	rendered := []byte(RenderPlan(plan))
+
+	if update {
+		if err := os.WriteFile(goldenFilePath, rendered, 0644); err != nil {
+			return fmt.Errorf("failed to update golden file with error: %w", err)
+		}
+		return nil
+	}
+
+	expected, err := os.ReadFile(goldenFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read golden file with error: %w", err)
+	}
+
+	if !bytes.Equal(bytes.ReplaceAll(expected, []byte("\r\n"), []byte("\n")), rendered) {
+		return fmt.Errorf(
+			"%w %s\nexpected:\n%s\nactual:\n%s",
+			ErrGoldenPlanMismatch, goldenFilePath, expected, rendered,
+		)
+	}
+
+	return nil
+}
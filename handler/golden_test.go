@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type GoldenTestSuite struct {
+	suite.Suite
+}
+
+func TestGoldenTestSuite(t *testing.T) {
+	suite.Run(t, new(GoldenTestSuite))
+}
+
+func (suite *GoldenTestSuite) buildPlan(executions []execution.MigrationExecution) *ExecutionPlan {
+	repo := &execution.InMemoryRepository{}
+	repo.SaveAll(executions)
+
+	registry := migration.NewGenericRegistry()
+	for _, version := range []uint64{1, 2, 3, 4} {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+
+	plan, err := NewPlan(registry, repo)
+	suite.Require().NoError(err)
+	return plan
+}
+
+func (suite *GoldenTestSuite) TestItCanRenderPlanIgnoringTimestamps() {
+	plan1 := suite.buildPlan(
+		[]execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+			{Version: 2, ExecutedAtMs: 4, FinishedAtMs: 0},
+		},
+	)
+	plan2 := suite.buildPlan(
+		[]execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 20, FinishedAtMs: 30},
+			{Version: 2, ExecutedAtMs: 40, FinishedAtMs: 0},
+		},
+	)
+
+	expected := "executed:\n  1 finished\n  2 unfinished\nto be executed:\n  2\n  3\n  4\n"
+	suite.Assert().Equal(expected, RenderPlan(plan1))
+	suite.Assert().Equal(RenderPlan(plan1), RenderPlan(plan2))
+}
+
+func (suite *GoldenTestSuite) TestItCanUpdateAndCompareGoldenFile() {
+	goldenPath := filepath.Join(suite.T().TempDir(), "plan.golden")
+	plan := suite.buildPlan(
+		[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}},
+	)
+
+	suite.Assert().Error(CompareGoldenPlan(plan, goldenPath, false))
+	suite.Assert().NoError(CompareGoldenPlan(plan, goldenPath, true))
+	suite.Assert().NoError(CompareGoldenPlan(plan, goldenPath, false))
+
+	otherPlan := suite.buildPlan([]execution.MigrationExecution{})
+	suite.Assert().ErrorIs(CompareGoldenPlan(otherPlan, goldenPath, false), ErrGoldenPlanMismatch)
+
+	contents, _ := os.ReadFile(goldenPath)
+	suite.Assert().Equal(RenderPlan(plan), string(contents))
+}
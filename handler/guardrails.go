@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrProtectedEnvironment is returned when a destructive operation is attempted in a protected
+// environment without explicit approval (see WithDestructiveApproval) or when destructive
+// operations are disabled there
+var ErrProtectedEnvironment = errors.New("operation is not allowed in a protected environment")
+
+// Guardrails Policy for protected environments (for example, production). In protected
+// environments, destructive operations (down, force:up, force:down, fresh and running
+// destructive migrations, see migration.Destructive) require explicit approval or, if
+// DisableDestructive is set, are disabled entirely.
+type Guardrails struct {
+	// Protected The names of the protected environments
+	Protected []string
+
+	// DisableDestructive Disables destructive operations in protected environments, even if
+	// they were approved
+	DisableDestructive bool
+}
+
+// IsProtected Checks if the environment is protected
+func (guardrails Guardrails) IsProtected(environment string) bool {
+	return slices.Contains(guardrails.Protected, environment)
+}
+
+// WithGuardrails Sets the name of the environment the handler runs against and the policy
+// enforced for protected environments
+func WithGuardrails(environment string, guardrails Guardrails) Option {
+	return func(handler *MigrationsHandler) {
+		handler.environment = environment
+		handler.guardrails = guardrails
+	}
+}
+
+// WithDestructiveApproval Explicitly approves destructive operations in protected environments
+// (see Guardrails)
+func WithDestructiveApproval() Option {
+	return func(handler *MigrationsHandler) {
+		handler.destructiveApproved = true
+	}
+}
+
+// guard Checks if the destructive operation is allowed in the handler's environment
+func (handler *MigrationsHandler) guard(operation string) error {
+	if !handler.guardrails.IsProtected(handler.environment) {
+		return nil
+	}
+
+	if handler.guardrails.DisableDestructive {
+		return fmt.Errorf(
+			"%w: %s is disabled in %s", ErrProtectedEnvironment, operation, handler.environment,
+		)
+	}
+
+	if !handler.destructiveApproved {
+		return fmt.Errorf(
+			"%w: %s in %s requires explicit approval",
+			ErrProtectedEnvironment, operation, handler.environment,
+		)
+	}
+
+	return nil
+}
+
+// guardMigrations Checks if the migrations to be executed are allowed in the handler's
+// environment
+func (handler *MigrationsHandler) guardMigrations(migrations []migration.Migration) error {
+	for _, mig := range migrations {
+		if destructive, isDestructive := mig.(migration.Destructive); isDestructive &&
+			destructive.Destructive() {
+			return handler.guard(fmt.Sprintf("destructive migration %d", mig.Version()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/schema"
+	"github.com/stretchr/testify/suite"
+)
+
+type GuardrailsTestSuite struct {
+	suite.Suite
+}
+
+func TestGuardrailsTestSuite(t *testing.T) {
+	suite.Run(t, new(GuardrailsTestSuite))
+}
+
+type DestructiveMigration struct {
+	migration.DummyMigration
+}
+
+func (mig *DestructiveMigration) Destructive() bool {
+	return true
+}
+
+func (suite *GuardrailsTestSuite) newHandler(
+	environment string,
+	options ...Option,
+) (*MigrationsHandler, *execution.InMemoryRepository) {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(&DestructiveMigration{*migration.NewDummyMigration(2)})
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+
+	guardrails := Guardrails{Protected: []string{"production"}}
+	options = append([]Option{WithGuardrails(environment, guardrails)}, options...)
+	handler, _ := NewHandler(registry, repo, nil, options...)
+	return handler, repo
+}
+
+func (suite *GuardrailsTestSuite) TestItBlocksDestructiveOperationsInProtectedEnvironments() {
+	handler, repo := suite.newHandler("production")
+
+	_, err := handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, ErrProtectedEnvironment)
+	suite.Assert().ErrorContains(err, "down in production requires explicit approval")
+
+	_, err = handler.ForceUp(3)
+	suite.Assert().ErrorIs(err, ErrProtectedEnvironment)
+
+	_, err = handler.ForceDown(1)
+	suite.Assert().ErrorIs(err, ErrProtectedEnvironment)
+
+	_, err = handler.FastForwardFromSnapshot(
+		&schema.DirSnapshotStore{DirPath: suite.T().TempDir()}, &FakeRestorer{},
+	)
+	suite.Assert().ErrorIs(err, ErrProtectedEnvironment)
+
+	_, err = handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().ErrorIs(err, ErrProtectedEnvironment)
+	suite.Assert().ErrorContains(err, "destructive migration 2")
+
+	suite.Assert().Equal(
+		[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+		repo.PersistedExecutions,
+	)
+}
+
+func (suite *GuardrailsTestSuite) TestItAllowsApprovedDestructiveOperations() {
+	handler, repo := suite.newHandler("production", WithDestructiveApproval())
+
+	_, err := handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+}
+
+func (suite *GuardrailsTestSuite) TestItBlocksDisabledDestructiveOperations() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+	guardrails := Guardrails{Protected: []string{"production"}, DisableDestructive: true}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithGuardrails("production", guardrails), WithDestructiveApproval(),
+	)
+
+	_, err := handler.MigrateDown(NumOfRuns(1))
+
+	suite.Assert().ErrorIs(err, ErrProtectedEnvironment)
+	suite.Assert().ErrorContains(err, "down is disabled in production")
+}
+
+func (suite *GuardrailsTestSuite) TestItAllowsAllOperationsInUnprotectedEnvironments() {
+	handler, repo := suite.newHandler("staging")
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	_, err = handler.ForceUp(3)
+	suite.Assert().NoError(err)
+	_, err = handler.ForceDown(3)
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().NoError(err)
+
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+}
+
+func (suite *GuardrailsTestSuite) TestItChecksIfEnvironmentIsProtected() {
+	guardrails := Guardrails{Protected: []string{"production", "eu-production"}}
+
+	suite.Assert().True(guardrails.IsProtected("eu-production"))
+	suite.Assert().False(guardrails.IsProtected("staging"))
+	suite.Assert().False(guardrails.IsProtected(""))
+}
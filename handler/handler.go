@@ -3,15 +3,21 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"slices"
 	"sort"
-	"strconv"
 	"strings"
+	"time"
 
+	"github.com/rsgcata/go-migrations/clock"
 	"github.com/rsgcata/go-migrations/execution"
 	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/online"
+	"github.com/rsgcata/go-migrations/schema"
 )
 
 // ExecutedMigration Value object that groups information related to a migration execution
@@ -35,53 +41,90 @@ func NewPlan(
 	registry migration.MigrationsRegistry,
 	repository execution.Repository,
 ) (*ExecutionPlan, error) {
-	genericErrMsg := "failed to create new execution plan"
-	errHelpMsg := "Fix executions issues before trying to manipulate their state"
+	executions, err := loadPlanExecutions(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPlan(
+		registry.OrderedMigrations(),
+		executions,
+		func(a execution.MigrationExecution, b execution.MigrationExecution) bool {
+			return a.Version < b.Version
+		},
+	)
+}
 
+// loadPlanExecutions Loads the executions an ExecutionPlan is created for
+func loadPlanExecutions(repository execution.Repository) ([]execution.MigrationExecution, error) {
 	executions, err := repository.LoadExecutions()
 	if err != nil {
 		return nil, fmt.Errorf(
-			"%s, failed to load executions with error: %w. %s", genericErrMsg, err, errHelpMsg,
+			"failed to create new execution plan, failed to load executions with error: %w."+
+				" Fix executions issues before trying to manipulate their state", err,
 		)
 	}
+	return executions, nil
+}
+
+// newPlan Creates a new ExecutionPlan for the migrations, in the provided order. Executions are
+// ordered with the provided less function, before being checked against the migrations.
+func newPlan(
+	orderedMigrations []migration.Migration,
+	executions []execution.MigrationExecution,
+	less func(a execution.MigrationExecution, b execution.MigrationExecution) bool,
+) (*ExecutionPlan, error) {
+	genericErrMsg := "failed to create new execution plan"
+	errHelpMsg := "Fix executions issues before trying to manipulate their state"
 
 	sort.Slice(
 		executions, func(i, j int) bool {
-			return executions[i].Version < executions[j].Version
+			return less(executions[i], executions[j])
 		},
 	)
 
 	plan := &ExecutionPlan{
-		orderedMigrations: registry.OrderedMigrations(),
+		orderedMigrations: orderedMigrations,
 		orderedExecutions: executions,
 	}
 
 	if len(plan.orderedExecutions) > len(plan.orderedMigrations) {
 		return nil, fmt.Errorf(
-			"%s, there are more executions than registered migrations. %s",
-			genericErrMsg, errHelpMsg,
+			"%s, %w. %s", genericErrMsg, &InconsistentPlanError{
+				Kind:   InconsistencyExtraExecutions,
+				detail: "there are more executions than registered migrations",
+			}, errHelpMsg,
 		)
 	}
 
 	for i, exec := range plan.orderedExecutions {
 		if !exec.Finished() && i != len(plan.orderedExecutions)-1 {
 			return nil, fmt.Errorf(
-				"%s, there are multiple executions which are not finished."+
-					" Only the last execution should have an \"unfinished\" state. %s",
-				genericErrMsg, errHelpMsg,
+				"%s, %w. %s", genericErrMsg, &InconsistentPlanError{
+					Kind:    InconsistencyUnfinishedExecution,
+					Version: exec.Version,
+					detail: "there are multiple executions which are not finished." +
+						" Only the last execution should have an \"unfinished\" state",
+				}, errHelpMsg,
 			)
 		}
 
 		if exec.Version != plan.orderedMigrations[i].Version() {
 			return nil, fmt.Errorf(
-				"%s, execution %d at index %d does not match with registered migration"+
-					" %d at index %d. Migrations and executions are out of order. %s",
-				genericErrMsg, exec, i, plan.orderedMigrations[i].Version(), i, errHelpMsg,
+				"%s, %w. %s", genericErrMsg, &InconsistentPlanError{
+					Kind:    InconsistencyOutOfOrder,
+					Version: exec.Version,
+					detail: fmt.Sprintf(
+						"execution %d at index %d does not match with registered migration"+
+							" %d at index %d. Migrations and executions are out of order",
+						exec.Version, i, plan.orderedMigrations[i].Version(), i,
+					),
+				}, errHelpMsg,
 			)
 		}
 	}
 
-	return plan, err
+	return plan, nil
 }
 
 func (plan *ExecutionPlan) RegisteredMigrationsCount() int {
@@ -141,6 +184,36 @@ func (plan *ExecutionPlan) LastExecuted() ExecutedMigration {
 	return ExecutedMigration{}
 }
 
+// markExecuted Updates the in memory state of the plan with a saved execution, so the plan
+// can be reused after executions are persisted, without reloading them
+func (plan *ExecutionPlan) markExecuted(exec execution.MigrationExecution) {
+	count := len(plan.orderedExecutions)
+	if count > 0 && plan.orderedExecutions[count-1].Version == exec.Version {
+		plan.orderedExecutions[count-1] = exec
+		return
+	}
+	plan.orderedExecutions = append(plan.orderedExecutions, exec)
+}
+
+// markRolledBack Updates the in memory state of the plan with a removed execution
+func (plan *ExecutionPlan) markRolledBack(version uint64) {
+	count := len(plan.orderedExecutions)
+	if count > 0 && plan.orderedExecutions[count-1].Version == version {
+		plan.orderedExecutions = plan.orderedExecutions[:count-1]
+	}
+}
+
+// sameState Checks if both plans have the same executions, ignoring timestamps
+func (plan *ExecutionPlan) sameState(other *ExecutionPlan) bool {
+	return slices.EqualFunc(
+		plan.orderedExecutions,
+		other.orderedExecutions,
+		func(a execution.MigrationExecution, b execution.MigrationExecution) bool {
+			return a.Version == b.Version && a.Finished() == b.Finished()
+		},
+	)
+}
+
 type ExecutionPlanBuilder func(
 	registry migration.MigrationsRegistry,
 	repository execution.Repository,
@@ -151,90 +224,464 @@ type ExecutionPlanBuilder func(
 type MigrationsHandler struct {
 	registry         migration.MigrationsRegistry
 	repository       execution.Repository
+	readRepository   execution.Repository
 	newExecutionPlan ExecutionPlanBuilder
+	customPlan       bool
+	snapshotDumper   schema.Dumper
+	snapshotStore    schema.SnapshotStore
+	revalidatePlan   bool
+	continueOnError  bool
+	preflight        bool
+	migrationDbs     []Pinger
+	clock            clock.Clock
+	serverClock      bool
+	baseline         uint64
+	runMetadata      execution.RunMetadata
+	throttle         Throttle
+	skipList         migration.SkipList
+	exclusiveLock    bool
+	locker           execution.Locker
+	heldLock         execution.Locker
+	readOnly         bool
+	sleep            func(time.Duration)
+	wait             func(context.Context, time.Duration) error
+
+	schemaChangeExecutor online.Executor
+	schemaChangePoll     time.Duration
+	schemaChangeTimeout  time.Duration
+
+	environment         string
+	guardrails          Guardrails
+	destructiveApproved bool
+
+	detectConcurrentChanges bool
+	concurrentChangePolicy  ConcurrentChangePolicy
+
+	faults    []Fault
+	logger    *slog.Logger
+	notifiers []Notifier
+
+	errorReporters      []ErrorReporter
+	consistencyMonitors []ConsistencyMonitor
+
+	beforeRunHooks []RunHook
+	afterRunHooks  []RunHook
+
+	enterMaintenance  RunHook
+	exitMaintenance   RunHook
+	maintenancePolicy MaintenancePolicy
+
+	lockFile   migration.LockFile
+	lockedRuns bool
+
+	releases migration.Releases
+
+	workDirRoot  string
+	workDirHooks []WorkDirHook
+	ctx          context.Context
+	resources    []migration.Resource
+}
+
+// ErrPlanStateChanged is returned when, at the end of a run, the persisted executions do not
+// match the executions handled by the run (for example, another process changed them)
+var ErrPlanStateChanged = errors.New("executions state changed during the run")
+
+// Option Configures optional MigrationsHandler behaviour
+type Option func(handler *MigrationsHandler)
+
+// WithSchemaSnapshots Enables capturing a schema dump after each successful MigrateUp run.
+// The dump is saved in the store, keyed by the last executed migration version.
+func WithSchemaSnapshots(dumper schema.Dumper, store schema.SnapshotStore) Option {
+	return func(handler *MigrationsHandler) {
+		handler.snapshotDumper = dumper
+		handler.snapshotStore = store
+	}
+}
+
+// WithPlanRevalidation Enables reloading the executions at the end of each MigrateUp and
+// MigrateDown run and checking them against the state the run ended with. The execution plan
+// is built once per run and updated in memory as executions are saved, so, without this option,
+// changes made by other processes during the run are not detected.
+func WithPlanRevalidation() Option {
+	return func(handler *MigrationsHandler) {
+		handler.revalidatePlan = true
+	}
+}
+
+// WithContinueOnError Makes MigrateUp continue with the next migrations when a migration fails,
+// instead of stopping the run. Failed migrations are recorded as skipped executions, with the
+// failure as skip reason (see Skipped), so later runs do not retry them. The returned error
+// aggregates all failures. Useful for batches of independent migrations (for example, data
+// backfills). Once fixed, failed migrations can be executed with ForceUp.
+func WithContinueOnError() Option {
+	return func(handler *MigrationsHandler) {
+		handler.continueOnError = true
+	}
+}
+
+// WithClock Sets the clock used for execution timestamps. Defaults to clock.System
+func WithClock(executionsClock clock.Clock) Option {
+	return func(handler *MigrationsHandler) {
+		handler.clock = executionsClock
+	}
+}
+
+// WithServerClock Sources the execution timestamps from the clock of the database server (see
+// execution.ServerClock), instead of the clock of the host running the migrations, so the
+// executions history is not misleading when hosts have bad clocks. The server time is read once,
+// when the handler is built, and the offset from the handler clock (see WithClock) is applied to
+// all the timestamps. Building the handler fails if the repository can not report the server
+// time.
+func WithServerClock() Option {
+	return func(handler *MigrationsHandler) {
+		handler.serverClock = true
+	}
 }
 
 func NewHandler(
 	registry migration.MigrationsRegistry,
 	repository execution.Repository,
 	newExecutionPlan ExecutionPlanBuilder,
+	options ...Option,
 ) (*MigrationsHandler, error) {
-	err := repository.Init()
+	customPlan := newExecutionPlan != nil
+	if !customPlan {
+		newExecutionPlan = NewPlan
+	}
 
-	if err != nil {
+	handler := &MigrationsHandler{
+		registry:         registry,
+		repository:       repository,
+		readRepository:   repository,
+		newExecutionPlan: newExecutionPlan,
+		customPlan:       customPlan,
+		clock:            clock.System{},
+		sleep:            time.Sleep,
+		wait:             sleepContext,
+		ctx:              context.Background(),
+	}
+
+	for _, option := range options {
+		option(handler)
+	}
+
+	if handler.readOnly {
+		reads := repository
+		if replicaReader, isReplicaReader := repository.(execution.ReplicaReader); isReplicaReader {
+			reads = replicaReader.ReplicaRepository()
+		}
+		handler.repository = execution.NewReadOnlyRepository(reads)
+		// Not wrapped, so its read capabilities can be detected
+		handler.readRepository = reads
+	}
+
+	if err := handler.repository.Init(); err != nil {
 		return nil, fmt.Errorf(
 			"could not create new migrations handler,"+
 				" failed to initialize the repository with error: %w", err,
 		)
 	}
 
-	if newExecutionPlan == nil {
-		newExecutionPlan = NewPlan
+	if handler.serverClock {
+		if err := handler.syncWithServerClock(repository); err != nil {
+			return nil, fmt.Errorf("could not create new migrations handler, %w", err)
+		}
 	}
 
-	return &MigrationsHandler{
-		registry:         registry,
-		repository:       repository,
-		newExecutionPlan: newExecutionPlan,
-	}, nil
+	return handler, nil
+}
+
+// syncWithServerClock Replaces the handler clock with one following the database server clock
+// (see WithServerClock)
+func (handler *MigrationsHandler) syncWithServerClock(repository execution.Repository) error {
+	server, isServerClock := repository.(execution.ServerClock)
+	if !isServerClock {
+		return errors.New("the repository can not report the database server time")
+	}
+
+	synced, err := clock.SyncWith(handler.clock, server.ServerTime)
+	if err != nil {
+		return fmt.Errorf("failed to sync with the database server clock with error: %w", err)
+	}
+
+	handler.clock = synced
+	return nil
 }
 
 // NumOfRuns Type which is used to process the allowed user input for specifying the number
 // of migrations to run
 type NumOfRuns int
 
-func NewNumOfRuns(num string) (NumOfRuns, error) {
-	var parsedNum int
+// AllRuns Runs all migrations, without an upper bound
+const AllRuns = NumOfRuns(math.MaxInt)
 
-	if num == "all" {
-		num = "99999"
-	} else if len(strings.TrimSpace(num)) == 0 {
-		num = "1"
+// NewNumOfRuns Parses the number of migrations to run (see ParseSteps)
+func NewNumOfRuns(num string) (NumOfRuns, error) {
+	steps, err := ParseSteps(num)
+	if err != nil {
+		return NumOfRuns(1), err
 	}
+	return steps.NumOfRuns(), nil
+}
 
-	parsedNum, err := strconv.Atoi(num)
+func (handler *MigrationsHandler) MigrateUp(numOfRuns NumOfRuns) ([]ExecutedMigration, error) {
+	report, err := handler.migrateUp(Runs(numOfRuns), nil, false)
+	return report.Executed(), err
+}
 
-	if err != nil {
-		return NumOfRuns(1), errors.New(
-			"failed to build new num of runs from provided string." +
-				" Accepted values: integer number or \"all\"",
-		)
-	}
+// MigrateUpWithReport Same as MigrateUp, but returns the full run report
+func (handler *MigrationsHandler) MigrateUpWithReport(numOfRuns NumOfRuns) (*RunReport, error) {
+	return handler.migrateUp(Runs(numOfRuns), nil, false)
+}
+
+// MigrateUpTo Same as MigrateUpWithReport, but executes the migrations of the target, which is
+// resolved while holding the run lock (see Target)
+func (handler *MigrationsHandler) MigrateUpTo(target Target) (*RunReport, error) {
+	return handler.migrateUp(target, nil, false)
+}
 
-	if parsedNum <= 0 {
-		parsedNum = 1
+// migrateUp Executes Up() for the pending migrations. If approve is not nil, it is asked for a
+// decision before each migration. If commit is set, two-phase migrations are committed instead
+// (see CommitUp).
+func (handler *MigrationsHandler) migrateUp(
+	target Target,
+	approve Approver,
+	commit bool,
+) (*RunReport, error) {
+	report := newRunReport(StageUp, handler.clock.Now())
+	err := handler.checkWritable("up")
+	if err == nil {
+		err = handler.withLock(
+			func() error {
+				return handler.runUp(report, target, approve, commit)
+			},
+		)
 	}
-	return NumOfRuns(parsedNum), nil
+	report.FinishedAt = handler.clock.Now()
+	return report, handler.notify(report, handler.reportFailures(err))
 }
 
-func (handler *MigrationsHandler) MigrateUp(numOfRuns NumOfRuns) ([]ExecutedMigration, error) {
+// runUp Executes Up() for the pending migrations, recording them in the report
+func (handler *MigrationsHandler) runUp(
+	report *RunReport,
+	target Target,
+	approve Approver,
+	commit bool,
+) (runErr error) {
 	if handler.registry.Count() == 0 {
-		return []ExecutedMigration{}, nil
+		return nil
 	}
 
 	errMsg := "failed to migrate all up"
 
-	plan, err := handler.newExecutionPlan(handler.registry, handler.repository)
+	if err := handler.runPreflight(); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	plan, err := handler.plan()
 	if err != nil {
-		return []ExecutedMigration{}, fmt.Errorf(
-			"%s, failed to create execution plan with error: %w", errMsg, err,
-		)
+		return fmt.Errorf("%s, failed to create execution plan with error: %w", errMsg, err)
+	}
+
+	numOfRuns, err := target(handler, plan)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
 	}
 
 	allToBeExec := plan.AllToBeExecuted()
 	actualNumOfRuns := min(len(allToBeExec), int(numOfRuns))
+	toRun := handler.withoutSkipped(allToBeExec[:actualNumOfRuns])
+	handler.setContext(toRun...)
+
+	if err = validateMigrations(toRun); err != nil {
+		return fmt.Errorf("%s, validation failed: %w", errMsg, err)
+	}
+
+	if err = handler.guardMigrations(toRun); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
 
-	var handledMigrations []ExecutedMigration
+	if err = handler.checkLocked(toRun); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	apply := handler.upUnprepared
+	if commit {
+		if err = handler.checkPrepared(toRun); err != nil {
+			return fmt.Errorf("%s, %w", errMsg, err)
+		}
+		apply = handler.commit
+	}
+
+	if actualNumOfRuns > 0 {
+		defer func() {
+			runErr = handler.runAfterHooks(StageUp, runErr)
+		}()
+
+		if err = handler.runBeforeHooks(StageUp); err != nil {
+			return fmt.Errorf("%s, %w", errMsg, err)
+		}
+	}
+
+	if handler.requiresMaintenance(toRun) {
+		defer func() {
+			runErr = handler.exitMaintenanceMode(StageUp, runErr)
+		}()
+
+		if err = handler.enterMaintenanceMode(StageUp); err != nil {
+			return fmt.Errorf("%s, %w", errMsg, err)
+		}
+	}
+
+	conditionalSaver, canClaim := handler.repository.(execution.ConditionalSaver)
+
+	var failures []error
 	for i := 0; i < actualNumOfRuns; i++ {
 		migrationToExec := allToBeExec[i]
-		exec := execution.StartExecution(migrationToExec)
 
-		if err = migrationToExec.Up(); err == nil {
-			exec.FinishExecution()
+		if throttleErr := handler.throttleRun(
+			report.StartedAt, i, allToBeExec[i:actualNumOfRuns],
+		); throttleErr != nil {
+			err = fmt.Errorf("%s, %w", errMsg, throttleErr)
+			break
 		}
 
-		handledMigrations = append(handledMigrations, ExecutedMigration{migrationToExec, exec})
-		saveErr := handler.repository.Save(*exec)
+		if lockErr := handler.checkLock(); lockErr != nil {
+			err = fmt.Errorf("%s, %w", errMsg, lockErr)
+			break
+		}
+
+		if handler.detectConcurrentChanges && !canClaim {
+			skip, changeErr := handler.concurrentChange(plan, migrationToExec, false)
+			if changeErr != nil {
+				err = fmt.Errorf("%s, %w", errMsg, changeErr)
+				break
+			} else if skip {
+				continue
+			}
+		}
+
+		decision := DecisionApprove
+		if approve != nil {
+			decision = approve(migrationToExec)
+		}
+
+		if decision == DecisionAbort {
+			err = fmt.Errorf(
+				"%s, %w before migration %d", errMsg, ErrRunAborted, migrationToExec.Version(),
+			)
+			break
+		}
+
+		exec := handler.startExecution(migrationToExec)
+		skipReason, skipped := handler.skipReason(migrationToExec)
+		if decision == DecisionSkip && !skipped {
+			skipReason, skipped = InteractiveSkipReason, true
+		}
+		exec.SkipReason = skipReason
+
+		if canClaim {
+			claimErr := handler.claimExecution(conditionalSaver, plan, *exec)
+			if errors.Is(claimErr, execution.ErrExecutionConflict) &&
+				handler.detectConcurrentChanges {
+				skip, changeErr := handler.concurrentChange(plan, migrationToExec, true)
+				if skip {
+					continue
+				}
+				err = fmt.Errorf("%s, %w", errMsg, changeErr)
+				break
+			} else if errors.Is(claimErr, execution.ErrExecutionConflict) {
+				// Another process is executing or already executed the migration
+				break
+			} else if claimErr != nil {
+				err = fmt.Errorf("%s, failed to claim execution with error: %w", errMsg, claimErr)
+				break
+			}
+		}
+
+		claimed := *exec
+		migStartedAt := handler.clock.Now()
+		outcome := OutcomeSkipped
+		var logs *logCapture
+		var workDir WorkDir
+		if !skipped {
+			outcome = OutcomeExecuted
+			logs = handler.scopeLogger(migrationToExec, StageUp)
+			workDir, err = handler.withWorkDir(
+				migrationToExec, StageUp, func() error { return apply(migrationToExec) },
+			)
+			err = newMigrationFailed(migrationToExec.Version(), StageUp, err)
+			if err == nil {
+				err = handler.waitForChanges(migrationToExec.Version(), StageUp)
+			}
+			if err == nil {
+				err = newMigrationFailed(
+					migrationToExec.Version(), StageUp,
+					handler.injectFault(FaultAfterUp, migrationToExec.Version()),
+				)
+			}
+
+			if crashErr := handler.injectFault(
+				FaultCrash, migrationToExec.Version(),
+			); crashErr != nil {
+				report.add(
+					ExecutedMigration{migrationToExec, nil},
+					OutcomeFailed,
+					handler.clock.Now().Sub(migStartedAt),
+					crashErr,
+					logs.Entries(),
+					workDir,
+				)
+				err = fmt.Errorf("%s, %w", errMsg, crashErr)
+				break
+			}
+		}
+		if err == nil {
+			exec.FinishExecutionAt(handler.clock.Now())
+		} else if handler.continueOnError {
+			// Recorded as skipped, an unfinished execution followed by the next migrations'
+			// executions would make the plan inconsistent
+			exec.SkipReason = failedSkipReason(err)
+			exec.FinishExecutionAt(handler.clock.Now())
+		}
+
+		saveErr := handler.injectFault(FaultBeforeSave, migrationToExec.Version())
+		if saveErr == nil {
+			saveErr = handler.checkLock()
+		}
+		if saveErr == nil && canClaim {
+			saveErr = conditionalSaver.SaveIf(*exec, &claimed)
+		} else if saveErr == nil {
+			saveErr = handler.repository.Save(*exec)
+		}
+
+		elapsed := handler.clock.Now().Sub(migStartedAt)
+		err = withElapsed(err, elapsed)
+		saveErr = withElapsed(
+			newMigrationFailed(migrationToExec.Version(), StageSave, saveErr), elapsed,
+		)
+
+		report.add(
+			ExecutedMigration{migrationToExec, exec},
+			outcome,
+			elapsed,
+			errors.Join(err, saveErr),
+			logs.Entries(),
+			workDir,
+		)
+
+		if saveErr == nil {
+			plan.markExecuted(*exec)
+		} else if err == nil && errors.Is(saveErr, execution.ErrExecutionConflict) {
+			// Another process changed the execution in the meantime, it owns it from now on
+			break
+		}
+
+		if err != nil && saveErr == nil && handler.continueOnError {
+			failures = append(failures, err)
+			err = nil
+			continue
+		}
 
 		if err != nil || saveErr != nil {
 			err = fmt.Errorf("%s, errors: %w, %w", errMsg, err, saveErr)
@@ -242,58 +689,299 @@ func (handler *MigrationsHandler) MigrateUp(numOfRuns NumOfRuns) ([]ExecutedMigr
 		}
 	}
 
-	return handledMigrations, err
+	if len(failures) > 0 {
+		return fmt.Errorf(
+			"%s, %d migrations failed and were skipped: %w",
+			errMsg, len(failures), errors.Join(append(failures, err)...),
+		)
+	}
+
+	if err == nil {
+		err = handler.revalidate(plan)
+	}
+
+	if err == nil && len(report.Migrations) > 0 {
+		err = handler.snapshotSchema(report.Migrations[len(report.Migrations)-1].Migration)
+	}
+
+	return err
+}
+
+// maxFailedSkipReasonLength The skip reason length limit of the repositories with the smallest
+// limit (mysql)
+const maxFailedSkipReasonLength = 1024
+
+// failedSkipReason The skip reason of migrations which failed with WithContinueOnError
+func failedSkipReason(err error) string {
+	reason := err.Error()
+	if len(reason) > maxFailedSkipReasonLength {
+		reason = strings.ToValidUTF8(reason[:maxFailedSkipReasonLength], "")
+	}
+	return reason
+}
+
+// claimExecution Persists the started (unfinished) execution before the migration runs, only if
+// the persisted state is the one from the plan. This way, processes racing to execute the same
+// migration can not both execute it. Errors with execution.ErrExecutionConflict if the
+// persisted state was changed by another process.
+func (handler *MigrationsHandler) claimExecution(
+	saver execution.ConditionalSaver,
+	plan *ExecutionPlan,
+	exec execution.MigrationExecution,
+) error {
+	var expected *execution.MigrationExecution
+	last := plan.LastExecuted()
+	if last.Execution != nil && last.Execution.Version == exec.Version {
+		expected = last.Execution
+	}
+
+	if err := saver.SaveIf(exec, expected); err != nil {
+		return err
+	}
+
+	plan.markExecuted(exec)
+	return nil
+}
+
+// snapshotSchema Captures and stores the schema dump for the provided (last executed) migration,
+// if schema snapshots are enabled
+func (handler *MigrationsHandler) snapshotSchema(lastExecuted migration.Migration) error {
+	if handler.snapshotDumper == nil || handler.snapshotStore == nil {
+		return nil
+	}
+
+	dump, err := handler.snapshotDumper.Dump()
+	if err == nil {
+		err = handler.snapshotStore.SaveSnapshot(lastExecuted.Version(), dump)
+	}
+
+	if err != nil {
+		return fmt.Errorf(
+			"migrations were executed, but the schema snapshot for version %d failed: %w",
+			lastExecuted.Version(), err,
+		)
+	}
+
+	return nil
 }
 
 func (handler *MigrationsHandler) MigrateDown(numOfRuns NumOfRuns) ([]ExecutedMigration, error) {
+	report, err := handler.MigrateDownWithReport(numOfRuns)
+	return report.Executed(), err
+}
+
+// MigrateDownWithReport Same as MigrateDown, but returns the full run report
+func (handler *MigrationsHandler) MigrateDownWithReport(
+	numOfRuns NumOfRuns,
+) (*RunReport, error) {
+	return handler.MigrateDownTo(Runs(numOfRuns))
+}
+
+// MigrateDownTo Same as MigrateDownWithReport, but rolls back the migrations of the target,
+// which is resolved while holding the run lock (see Target)
+func (handler *MigrationsHandler) MigrateDownTo(target Target) (*RunReport, error) {
+	report := newRunReport(StageDown, handler.clock.Now())
+	err := handler.checkWritable("down")
+	if err == nil {
+		err = handler.withLock(
+			func() error {
+				return handler.runDown(report, target)
+			},
+		)
+	}
+	report.FinishedAt = handler.clock.Now()
+	return report, handler.notify(report, handler.reportFailures(err))
+}
+
+// runDown Executes Down() for the last executed migrations, recording them in the report
+func (handler *MigrationsHandler) runDown(
+	report *RunReport,
+	target Target,
+) (runErr error) {
 	errMsg := "failed to migrate all down"
 
-	plan, err := handler.newExecutionPlan(handler.registry, handler.repository)
+	if err := handler.guard("down"); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	if err := handler.runPreflight(); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	plan, err := handler.plan()
 	if err != nil {
-		return []ExecutedMigration{}, fmt.Errorf(
-			"%s, failed to create execution plan with error: %w", errMsg, err,
-		)
+		return fmt.Errorf("%s, failed to create execution plan with error: %w", errMsg, err)
+	}
+
+	numOfRuns, err := target(handler, plan)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
 	}
 
 	execMigrations := plan.AllExecuted()
 	slices.Reverse(execMigrations)
 	actualNumOfRuns := min(len(execMigrations), int(numOfRuns))
 
-	var handledMigrations []ExecutedMigration
+	if actualNumOfRuns > 0 {
+		defer func() {
+			runErr = handler.runAfterHooks(StageDown, runErr)
+		}()
+
+		if err = handler.runBeforeHooks(StageDown); err != nil {
+			return fmt.Errorf("%s, %w", errMsg, err)
+		}
+	}
+
+	toRollBack := make([]migration.Migration, 0, actualNumOfRuns)
+	for _, execMig := range execMigrations[:actualNumOfRuns] {
+		toRollBack = append(toRollBack, execMig.Migration)
+	}
+	handler.setContext(toRollBack...)
+
+	if handler.requiresMaintenance(toRollBack) {
+		defer func() {
+			runErr = handler.exitMaintenanceMode(StageDown, runErr)
+		}()
+
+		if err = handler.enterMaintenanceMode(StageDown); err != nil {
+			return fmt.Errorf("%s, %w", errMsg, err)
+		}
+	}
+
 	for i := 0; i < actualNumOfRuns; i++ {
 		execMig := execMigrations[i]
-		if err = execMig.Migration.Down(); err != nil {
-			handledMigrations = append(handledMigrations, ExecutedMigration{execMig.Migration, nil})
-			break
+		migStartedAt := handler.clock.Now()
+		outcome := OutcomeSkipped
+		var logs *logCapture
+		var workDir WorkDir
+
+		// Skipped executions were recorded without running Up(), so only the record is removed
+		if !execMig.Execution.Skipped() {
+			outcome = OutcomeExecuted
+			logs = handler.scopeLogger(execMig.Migration, StageDown)
+			var downErr error
+			workDir, downErr = handler.withWorkDir(
+				execMig.Migration, StageDown, execMig.Migration.Down,
+			)
+			err = newMigrationFailed(execMig.Migration.Version(), StageDown, downErr)
+
+			if err == nil {
+				err = handler.waitForChanges(execMig.Migration.Version(), StageDown)
+			}
+			if err == nil {
+				err = handler.resetSchemaChanges(execMig.Migration)
+			}
+			if err == nil {
+				err = handler.clearPrepared(execMig.Migration)
+			}
+		}
+		if err == nil {
+			err = newMigrationFailed(execMig.Migration.Version(), StageRemove, handler.checkLock())
+		}
+		if err == nil {
+			err = newMigrationFailed(
+				execMig.Migration.Version(),
+				StageRemove,
+				handler.repository.Remove(*execMig.Execution),
+			)
 		}
 
-		err = handler.repository.Remove(*execMig.Execution)
-
+		duration := handler.clock.Now().Sub(migStartedAt)
 		if err != nil {
-			handledMigrations = append(handledMigrations, ExecutedMigration{execMig.Migration, nil})
+			err = withElapsed(err, duration)
+			report.add(
+				ExecutedMigration{execMig.Migration, nil}, OutcomeFailed, duration, err,
+				logs.Entries(), workDir,
+			)
 			break
 		}
 
-		handledMigrations = append(handledMigrations, execMig)
+		plan.markRolledBack(execMig.Migration.Version())
+		report.add(execMig, outcome, duration, nil, logs.Entries(), workDir)
 	}
 
-	return handledMigrations, err
+	if err == nil {
+		err = handler.revalidate(plan)
+	}
+
+	return err
+}
+
+// revalidate Reloads the executions and checks them against the in memory plan, if plan
+// revalidation is enabled
+func (handler *MigrationsHandler) revalidate(plan *ExecutionPlan) error {
+	if !handler.revalidatePlan {
+		return nil
+	}
+
+	reloaded, err := handler.plan()
+	if err != nil {
+		return fmt.Errorf("failed to revalidate execution plan with error: %w", err)
+	}
+
+	if !plan.sameState(reloaded) {
+		return ErrPlanStateChanged
+	}
+
+	return nil
 }
 
 func (handler *MigrationsHandler) ForceUp(version uint64) (ExecutedMigration, error) {
+	executed, err := handler.forceUp(version)
+	return executed, handler.reportFailures(err)
+}
+
+// forceUp Executes Up() for the migration, regardless of the executions state
+func (handler *MigrationsHandler) forceUp(version uint64) (ExecutedMigration, error) {
 	migrationToExec := handler.registry.Get(version)
 	if migrationToExec == nil {
 		return ExecutedMigration{nil, nil}, nil
 	}
 
-	exec := execution.StartExecution(migrationToExec)
+	if err := handler.checkWritable("force:up"); err != nil {
+		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf(
+			"failed to migrate up forcefully, %w", err,
+		)
+	}
+
+	if err := handler.guard("force:up"); err != nil {
+		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf(
+			"failed to migrate up forcefully, %w", err,
+		)
+	}
+
+	if err := handler.runPreflight(); err != nil {
+		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf(
+			"failed to migrate up forcefully, %w", err,
+		)
+	}
+
+	handler.setContext(migrationToExec)
+	err := validateMigrations([]migration.Migration{migrationToExec})
+	if err != nil {
+		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf(
+			"failed to migrate up forcefully, validation failed: %w", err,
+		)
+	}
+
+	exec := handler.startExecution(migrationToExec)
+	startedAt := handler.clock.Now()
+	handler.scopeLogger(migrationToExec, StageUp)
 
-	err := migrationToExec.Up()
+	_, err = handler.withWorkDir(
+		migrationToExec, StageUp, func() error { return handler.upUnprepared(migrationToExec) },
+	)
+	err = newMigrationFailed(migrationToExec.Version(), StageUp, err)
+	if err == nil {
+		err = handler.waitForChanges(version, StageUp)
+	}
 	if err == nil {
-		exec.FinishExecution()
+		exec.FinishExecutionAt(handler.clock.Now())
 	}
 
-	errSave := handler.repository.Save(*exec)
+	errSave := newMigrationFailed(version, StageSave, handler.repository.Save(*exec))
+	elapsed := handler.clock.Now().Sub(startedAt)
+	err, errSave = withElapsed(err, elapsed), withElapsed(errSave, elapsed)
 
 	if err == nil {
 		err = errSave
@@ -305,6 +993,12 @@ func (handler *MigrationsHandler) ForceUp(version uint64) (ExecutedMigration, er
 }
 
 func (handler *MigrationsHandler) ForceDown(version uint64) (ExecutedMigration, error) {
+	executed, err := handler.forceDown(version)
+	return executed, handler.reportFailures(err)
+}
+
+// forceDown Executes Down() for the executed migration, regardless of the executions state
+func (handler *MigrationsHandler) forceDown(version uint64) (ExecutedMigration, error) {
 	errMsg := "failed to migrate down forcefully"
 
 	migrationToExec := handler.registry.Get(version)
@@ -312,6 +1006,18 @@ func (handler *MigrationsHandler) ForceDown(version uint64) (ExecutedMigration,
 		return ExecutedMigration{nil, nil}, nil
 	}
 
+	if err := handler.checkWritable("force:down"); err != nil {
+		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	if err := handler.guard("force:down"); err != nil {
+		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	if err := handler.runPreflight(); err != nil {
+		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
 	exec, err := handler.repository.FindOne(version)
 	if err != nil {
 		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf(
@@ -325,13 +1031,33 @@ func (handler *MigrationsHandler) ForceDown(version uint64) (ExecutedMigration,
 		)
 	}
 
-	if errDown := migrationToExec.Down(); errDown != nil {
+	startedAt := handler.clock.Now()
+	var errDown error
+	// Skipped executions were recorded without running Up(), so only the record is removed
+	if !exec.Skipped() {
+		handler.setContext(migrationToExec)
+		handler.scopeLogger(migrationToExec, StageDown)
+		_, errDown = handler.withWorkDir(migrationToExec, StageDown, migrationToExec.Down)
+		errDown = newMigrationFailed(version, StageDown, errDown)
+		if errDown == nil {
+			errDown = handler.waitForChanges(version, StageDown)
+		}
+		if errDown == nil {
+			errDown = handler.resetSchemaChanges(migrationToExec)
+		}
+		if errDown == nil {
+			errDown = handler.clearPrepared(migrationToExec)
+		}
+	}
+	if errDown != nil {
 		return ExecutedMigration{migrationToExec, nil}, fmt.Errorf(
-			"%s, down() failed with error: %w", errMsg, errDown,
+			"%s, down() failed with error: %w",
+			errMsg, withElapsed(errDown, handler.clock.Now().Sub(startedAt)),
 		)
 	}
 
-	err = handler.repository.Remove(*exec)
+	err = newMigrationFailed(version, StageRemove, handler.repository.Remove(*exec))
+	err = withElapsed(err, handler.clock.Now().Sub(startedAt))
 
 	return ExecutedMigration{migrationToExec, exec}, err
 }
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"errors"
+	"github.com/rsgcata/go-migrations/clock"
 	"github.com/rsgcata/go-migrations/execution"
 	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/schema"
 	"github.com/stretchr/testify/suite"
 	"slices"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -376,20 +379,28 @@ func (suite *HandlerTestSuite) TestItFailsToBuildHandlerWhenRepoInitializationFa
 func (suite *HandlerTestSuite) TestItCanBuildNewNumOfRuns() {
 	scenarios := map[string]struct {
 		arg         string
-		expectedNum int
+		expectedNum NumOfRuns
+		expectedErr bool
 	}{
-		"0":                 {"0", 1},
-		"all":               {"all", 99999},
-		"1":                 {"1", 1},
-		"-1":                {"-1", 1},
-		"9":                 {"9", 9},
-		"empty":             {"", 1},
-		"empty with spaces": {"", 1},
+		"0":                 {"0", 1, true},
+		"all":               {"all", AllRuns, false},
+		"1":                 {"1", 1, false},
+		"-1":                {"-1", 1, true},
+		"9":                 {"9", 9, false},
+		"100000":            {"100000", 100000, false},
+		"typo":              {"al", 1, true},
+		"empty":             {"", 1, false},
+		"empty with spaces": {"  ", 1, false},
 	}
 
-	for _, scenario := range scenarios {
-		actualRuns, _ := NewNumOfRuns(scenario.arg)
-		suite.Assert().Equal(int(actualRuns), scenario.expectedNum)
+	for name, scenario := range scenarios {
+		actualRuns, err := NewNumOfRuns(scenario.arg)
+		suite.Assert().Equal(scenario.expectedNum, actualRuns, "failed scenario: %s", name)
+		if scenario.expectedErr {
+			suite.Assert().ErrorIs(err, ErrInvalidSteps, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
 	}
 }
 
@@ -722,3 +733,240 @@ func (suite *HandlerTestSuite) TestItCanMigrateDown() {
 		)
 	}
 }
+
+type FakeDumper struct {
+	dumps int
+	err   error
+}
+
+func (f *FakeDumper) Dump() (string, error) {
+	f.dumps++
+	return "dump " + strconv.Itoa(f.dumps), f.err
+}
+
+func (suite *HandlerTestSuite) TestItCanSnapshotSchemaAfterMigratingUp() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+
+	dumper := &FakeDumper{}
+	store := &schema.DirSnapshotStore{DirPath: suite.T().TempDir()}
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithSchemaSnapshots(dumper, store),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+
+	dump, found, _ := store.LoadSnapshot(2)
+	suite.Assert().True(found)
+	suite.Assert().Equal("dump 1", dump)
+
+	_, err = handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(2, dumper.dumps)
+
+	dumper.err = errors.New("dump err")
+	_ = handler.repository.Remove(execution.MigrationExecution{Version: 3})
+	executed, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().Len(executed, 1)
+	suite.Assert().ErrorContains(err, "dump err")
+}
+
+func (suite *HandlerTestSuite) TestItLoadsExecutionsOncePerRunAndCanRevalidatePlan() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{}
+
+	builds := 0
+	concurrentChange := false
+	planBuilder := func(
+		registry migration.MigrationsRegistry,
+		repository execution.Repository,
+	) (*ExecutionPlan, error) {
+		builds++
+		if concurrentChange && builds%2 == 0 {
+			// Simulates another process which rolled back a migration during the run
+			_ = repository.Remove(execution.MigrationExecution{Version: 2})
+		}
+		return NewPlan(registry, repository)
+	}
+
+	handler, _ := NewHandler(registry, repo, planBuilder)
+	_, err := handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(1, builds)
+
+	builds = 0
+	handler, _ = NewHandler(registry, repo, planBuilder, WithPlanRevalidation())
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(2, builds)
+
+	builds = 0
+	concurrentChange = true
+	_, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, ErrPlanStateChanged)
+}
+
+// ConditionalInMemoryRepository InMemoryRepository which also implements
+// execution.ConditionalSaver. onSaveIf can be used to simulate concurrent processes.
+type ConditionalInMemoryRepository struct {
+	execution.InMemoryRepository
+	onSaveIf func(repo *ConditionalInMemoryRepository)
+}
+
+func (repo *ConditionalInMemoryRepository) SaveIf(
+	exec execution.MigrationExecution,
+	expected *execution.MigrationExecution,
+) error {
+	if repo.onSaveIf != nil {
+		repo.onSaveIf(repo)
+	}
+
+	persisted, _ := repo.FindOne(exec.Version)
+	if (expected == nil) != (persisted == nil) || (expected != nil && *expected != *persisted) {
+		return execution.ErrExecutionConflict
+	}
+
+	_ = repo.Remove(exec)
+	return repo.Save(exec)
+}
+
+func (suite *HandlerTestSuite) TestItTreatsConditionalSaveConflictsAsConcurrentRuns() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+
+	repo := &ConditionalInMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	executed, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(executed, 1)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().True(repo.PersistedExecutions[0].Finished())
+
+	repo.onSaveIf = func(repo *ConditionalInMemoryRepository) {
+		// Another process claims migration 2 first
+		if exec, _ := repo.FindOne(2); exec == nil {
+			_ = repo.Save(execution.MigrationExecution{Version: 2, ExecutedAtMs: 1})
+		}
+	}
+
+	executed, err = handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(executed)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().False(repo.PersistedExecutions[1].Finished())
+}
+
+func (suite *HandlerTestSuite) TestItCanContinueOnErrorWhenMigratingUp() {
+	migErr := errors.New("mig err")
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(&FailingMigration{*migration.NewDummyMigration(2), migErr})
+	_ = registry.Register(&FailingMigration{*migration.NewDummyMigration(3), migErr})
+	_ = registry.Register(migration.NewDummyMigration(4))
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil, WithContinueOnError())
+
+	handled, err := handler.MigrateUp(NumOfRuns(10))
+
+	suite.Assert().Len(handled, 4)
+	suite.Assert().ErrorIs(err, migErr)
+	suite.Assert().ErrorContains(err, "2 migrations failed and were skipped")
+	suite.Assert().Len(repo.PersistedExecutions, 4)
+
+	skipReasons := map[uint64]string{}
+	for _, exec := range repo.PersistedExecutions {
+		suite.Assert().True(exec.Finished())
+		skipReasons[exec.Version] = exec.SkipReason
+	}
+	suite.Assert().Equal(
+		map[uint64]string{
+			1: "", 2: "migration 2 failed at stage up with error: mig err",
+			3: "migration 3 failed at stage up with error: mig err", 4: "",
+		},
+		skipReasons,
+	)
+
+	// Later runs execute only the new migrations
+	_ = registry.Register(migration.NewDummyMigration(5))
+	handled, err = handler.MigrateUp(AllRuns)
+
+	suite.Assert().NoError(err)
+	suite.Require().Len(handled, 1)
+	suite.Assert().Equal(uint64(5), handled[0].Migration.Version())
+}
+
+func (suite *HandlerTestSuite) TestItUsesTheConfiguredClockForExecutions() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	fixedClock := clock.NewFixed(time.UnixMilli(1000))
+	handler, _ := NewHandler(registry, repo, nil, WithClock(fixedClock))
+
+	_, _ = handler.MigrateUp(NumOfRuns(1))
+	fixedClock.Advance(time.Second)
+	_, _ = handler.ForceUp(2)
+
+	suite.Assert().Equal(
+		[]execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1000, FinishedAtMs: 1000},
+			{Version: 2, ExecutedAtMs: 2000, FinishedAtMs: 2000},
+		},
+		repo.PersistedExecutions,
+	)
+}
+
+type serverClockRepository struct {
+	execution.InMemoryRepository
+	serverTime time.Time
+	timeErr    error
+}
+
+func (repo *serverClockRepository) ServerTime() (time.Time, error) {
+	return repo.serverTime, repo.timeErr
+}
+
+func (suite *HandlerTestSuite) TestItUsesTheServerClockForExecutions() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &serverClockRepository{serverTime: time.UnixMilli(5000)}
+	fixedClock := clock.NewFixed(time.UnixMilli(1000))
+	handler, err := NewHandler(
+		registry, repo, nil, WithServerClock(), WithClock(fixedClock),
+	)
+	suite.Require().NoError(err)
+
+	fixedClock.Advance(time.Second)
+	_, _ = handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().Equal(
+		[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 6000, FinishedAtMs: 6000}},
+		repo.PersistedExecutions,
+	)
+}
+
+func (suite *HandlerTestSuite) TestItFailsToBuildWhenTheServerClockCanNotBeRead() {
+	registry := migration.NewGenericRegistry()
+
+	handler, err := NewHandler(
+		registry, &serverClockRepository{timeErr: errors.New("connection lost")}, nil,
+		WithServerClock(),
+	)
+	suite.Assert().Nil(handler)
+	suite.Assert().ErrorContains(err, "connection lost")
+
+	handler, err = NewHandler(registry, &execution.InMemoryRepository{}, nil, WithServerClock())
+	suite.Assert().Nil(handler)
+	suite.Assert().ErrorContains(err, "can not report the database server time")
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// History Returns the persisted executions in the order they were executed (see
+// execution.SortHistory), which, for repositories assigning sequence numbers, does not depend on
+// the clocks of the hosts which ran the migrations. The executions covered by the baseline are
+// ignored.
+func (handler *MigrationsHandler) History() ([]execution.MigrationExecution, error) {
+	repository := handler.repository
+	if handler.baseline != 0 {
+		repository = &baselineRepository{repository, handler.baseline}
+	}
+
+	executions, err := repository.LoadExecutions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load executions history with error: %w", err)
+	}
+
+	execution.SortHistory(executions)
+	return executions, nil
+}
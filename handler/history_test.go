@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type HistoryTestSuite struct {
+	suite.Suite
+}
+
+func TestHistoryTestSuite(t *testing.T) {
+	suite.Run(t, new(HistoryTestSuite))
+}
+
+func (suite *HistoryTestSuite) TestItOrdersTheExecutionsHistory() {
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 3, ExecutedAtMs: 10, Sequence: 2},
+			{Version: 2, ExecutedAtMs: 30, Sequence: 1},
+			{Version: 1, ExecutedAtMs: 5},
+		},
+	}
+	handler, _ := NewHandler(migration.NewGenericRegistry(), repo, nil, WithBaseline(1))
+
+	history, err := handler.History()
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]execution.MigrationExecution{
+			{Version: 2, ExecutedAtMs: 30, Sequence: 1},
+			{Version: 3, ExecutedAtMs: 10, Sequence: 2},
+		},
+		history,
+	)
+}
+
+func (suite *HistoryTestSuite) TestItFailsToLoadTheHistoryWhenExecutionsCanNotBeLoaded() {
+	repo := &execution.InMemoryRepository{LoadErr: errors.New("connection lost")}
+	handler, _ := NewHandler(migration.NewGenericRegistry(), repo, nil)
+
+	history, err := handler.History()
+
+	suite.Assert().Nil(history)
+	suite.Assert().ErrorContains(err, "connection lost")
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRunHookFailed is returned (wrapped) when a run hook fails
+var ErrRunHookFailed = errors.New("run hook failed")
+
+// RunHook A step executed once per MigrateUp or MigrateDown run, rather than once per migration
+// (for example, setting session options, disabling triggers or refreshing materialized views).
+// The direction is StageUp or StageDown. See sqlhelpers.RunHook for SQL scripts.
+type RunHook func(direction MigrationStage) error
+
+// WithBeforeRun Registers hooks which are executed, in order, before the first migration of
+// each MigrateUp and MigrateDown run. Runs without migrations to execute do not execute hooks.
+// If a hook fails, the run stops without executing any migration.
+func WithBeforeRun(hooks ...RunHook) Option {
+	return func(handler *MigrationsHandler) {
+		handler.beforeRunHooks = append(handler.beforeRunHooks, hooks...)
+	}
+}
+
+// WithAfterRun Registers hooks which are executed, in order, at the end of each MigrateUp and
+// MigrateDown run with migrations to execute, even if a migration (or a before run hook) failed,
+// so, for example, disabled triggers are always enabled back. All hooks are executed, their
+// failures are joined with the run error.
+func WithAfterRun(hooks ...RunHook) Option {
+	return func(handler *MigrationsHandler) {
+		handler.afterRunHooks = append(handler.afterRunHooks, hooks...)
+	}
+}
+
+// runBeforeHooks Executes the before run hooks, stopping at the first failure
+func (handler *MigrationsHandler) runBeforeHooks(direction MigrationStage) error {
+	for i, hook := range handler.beforeRunHooks {
+		if err := hook(direction); err != nil {
+			return fmt.Errorf(
+				"%w, before run hook %d failed with error: %w", ErrRunHookFailed, i, err,
+			)
+		}
+	}
+	return nil
+}
+
+// runAfterHooks Executes all after run hooks, returning the run error joined with their
+// failures
+func (handler *MigrationsHandler) runAfterHooks(direction MigrationStage, runErr error) error {
+	err := runErr
+	for i, hook := range handler.afterRunHooks {
+		if hookErr := hook(direction); hookErr != nil {
+			err = errors.Join(
+				err,
+				fmt.Errorf(
+					"%w, after run hook %d failed with error: %w", ErrRunHookFailed, i, hookErr,
+				),
+			)
+		}
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type HooksTestSuite struct {
+	suite.Suite
+}
+
+func TestHooksTestSuite(t *testing.T) {
+	suite.Run(t, new(HooksTestSuite))
+}
+
+func recordingHook(calls *[]string, name string, err error) RunHook {
+	return func(direction MigrationStage) error {
+		*calls = append(*calls, name+" "+string(direction))
+		return err
+	}
+}
+
+func (suite *HooksTestSuite) TestItRunsTheHooksOncePerRun() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	var calls []string
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithBeforeRun(recordingHook(&calls, "before1", nil), recordingHook(&calls, "before2", nil)),
+		WithAfterRun(recordingHook(&calls, "after", nil)),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateDown(NumOfRuns(2))
+	suite.Assert().NoError(err)
+
+	suite.Assert().Equal(
+		[]string{
+			"before1 up", "before2 up", "after up", "before1 down", "before2 down", "after down",
+		},
+		calls,
+	)
+}
+
+func (suite *HooksTestSuite) TestItStopsTheRunWhenABeforeHookFails() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+	hookErr := errors.New("timeout not set")
+	var calls []string
+	handler, _ := NewHandler(
+		registry, repo, nil,
+		WithBeforeRun(
+			recordingHook(&calls, "before1", hookErr), recordingHook(&calls, "before2", nil),
+		),
+		WithAfterRun(recordingHook(&calls, "after", nil)),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, ErrRunHookFailed)
+	suite.Assert().ErrorIs(err, hookErr)
+	suite.Assert().Empty(repo.PersistedExecutions)
+	suite.Assert().Equal([]string{"before1 up", "after up"}, calls)
+}
+
+func (suite *HooksTestSuite) TestItRunsAfterHooksWhenMigrationsFail() {
+	registry := migration.NewGenericRegistry()
+	upErr := errors.New("up failed")
+	_ = registry.Register(&FailingMigration{*migration.NewDummyMigration(1), upErr})
+	hookErr := errors.New("refresh failed")
+	var calls []string
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithAfterRun(
+			recordingHook(&calls, "after1", hookErr), recordingHook(&calls, "after2", nil),
+		),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, upErr)
+	suite.Assert().ErrorIs(err, hookErr)
+	suite.Assert().ErrorIs(err, ErrRunHookFailed)
+	suite.Assert().Equal([]string{"after1 up", "after2 up"}, calls)
+}
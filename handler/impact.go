@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/impact"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// MigrationImpact The estimated impact of the statements a migration's Up() would execute.
+// Captured is false if the migration does not implement migration.SQLDryRunner, in which case
+// its impact could not be estimated.
+type MigrationImpact struct {
+	Migration migration.Migration
+	Captured  bool
+	Estimates []impact.Estimate
+}
+
+// EstimateImpact Dry-runs the pending migrations (see DryRunUp) and estimates, via the
+// analyzer, the impact (locks, rows touched) of each captured statement. Can be used to review
+// the impact of a run before executing it in production.
+func (handler *MigrationsHandler) EstimateImpact(
+	numOfRuns NumOfRuns,
+	analyzer impact.Analyzer,
+) ([]MigrationImpact, error) {
+	errMsg := "failed to estimate impact"
+
+	dryRuns, err := handler.DryRunUp(numOfRuns)
+	if err != nil {
+		return []MigrationImpact{}, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	var impacts []MigrationImpact
+	for _, dryRun := range dryRuns {
+		migImpact := MigrationImpact{Migration: dryRun.Migration, Captured: dryRun.Captured}
+
+		for _, statement := range dryRun.Statements {
+			estimate, err := analyzer.Estimate(statement)
+			if err != nil {
+				return append(impacts, migImpact), fmt.Errorf(
+					"%s for migration %d, %w", errMsg, dryRun.Migration.Version(), err,
+				)
+			}
+			migImpact.Estimates = append(migImpact.Estimates, estimate)
+		}
+
+		impacts = append(impacts, migImpact)
+	}
+
+	return impacts, nil
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/impact"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImpactTestSuite struct {
+	suite.Suite
+}
+
+func TestImpactTestSuite(t *testing.T) {
+	suite.Run(t, new(ImpactTestSuite))
+}
+
+type FakeAnalyzer struct {
+	err error
+}
+
+func (f *FakeAnalyzer) Estimate(statement sqlcapture.Statement) (impact.Estimate, error) {
+	table, lock := impact.Classify(statement.Query)
+	return impact.Estimate{
+		Statement: statement, Table: table, Lock: lock, EstimatedRows: 10,
+	}, f.err
+}
+
+func (suite *ImpactTestSuite) TestItCanEstimateImpactOfPendingMigrations() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeDbMigration{DummyMigration: *migration.NewDummyMigration(1)})
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	impacts, err := handler.EstimateImpact(NumOfRuns(2), &FakeAnalyzer{})
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(impacts, 2)
+	suite.Assert().True(impacts[0].Captured)
+	suite.Assert().Equal(
+		[]impact.Estimate{
+			{
+				Statement: sqlcapture.Statement{
+					Query: "INSERT INTO t VALUES (?)", Args: []any{uint64(1)},
+				},
+				Table:         "t",
+				Lock:          impact.LockRows,
+				EstimatedRows: 10,
+			},
+		},
+		impacts[0].Estimates,
+	)
+	suite.Assert().False(impacts[1].Captured)
+	suite.Assert().Empty(impacts[1].Estimates)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *ImpactTestSuite) TestItFailsToEstimateImpactWhenAnalyzerFails() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeDbMigration{DummyMigration: *migration.NewDummyMigration(1)})
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+	analyzerErr := errors.New("explain failed")
+
+	impacts, err := handler.EstimateImpact(NumOfRuns(1), &FakeAnalyzer{analyzerErr})
+
+	suite.Assert().ErrorIs(err, analyzerErr)
+	suite.Assert().ErrorContains(err, "migration 1")
+	suite.Assert().Len(impacts, 1)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrRunAborted is returned when a run was aborted by the Approver
+var ErrRunAborted = errors.New("run aborted")
+
+// Decision What should be done with a pending migration, in an interactive run
+type Decision int
+
+const (
+	// DecisionApprove Execute the migration
+	DecisionApprove Decision = iota
+
+	// DecisionSkip Do not execute the migration, but record it as executed, with
+	// InteractiveSkipReason, so the run can continue with the next migrations (executions must
+	// follow the registration order). Rolling back the execution only removes it, without
+	// running Down().
+	DecisionSkip
+
+	// DecisionAbort Stop the run, without executing the migration
+	DecisionAbort
+)
+
+// InteractiveSkipReason The skip reason of the executions skipped with DecisionSkip
+const InteractiveSkipReason = "skipped interactively"
+
+// Approver Must decide what should be done with the provided pending migration, for example by
+// asking an operator
+type Approver func(mig migration.Migration) Decision
+
+// MigrateUpInteractive Same as MigrateUp, but asks the approver for a decision before each
+// pending migration. Skipped migrations are part of the returned migrations, with a finished
+// execution. Errors with ErrRunAborted if the run was aborted.
+func (handler *MigrationsHandler) MigrateUpInteractive(
+	numOfRuns NumOfRuns,
+	approve Approver,
+) ([]ExecutedMigration, error) {
+	return handler.MigrateUpInteractiveTo(Runs(numOfRuns), approve)
+}
+
+// MigrateUpInteractiveTo Same as MigrateUpInteractive, but for the migrations of the target,
+// which is resolved while holding the run lock (see Target)
+func (handler *MigrationsHandler) MigrateUpInteractiveTo(
+	target Target,
+	approve Approver,
+) ([]ExecutedMigration, error) {
+	report, err := handler.migrateUp(target, approve, false)
+	return report.Executed(), err
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type InteractiveTestSuite struct {
+	suite.Suite
+}
+
+func TestInteractiveTestSuite(t *testing.T) {
+	suite.Run(t, new(InteractiveTestSuite))
+}
+
+func (suite *InteractiveTestSuite) TestItAsksForDecisionBeforeEachMigration() {
+	migrations := []*FakeUpMigration{
+		{DummyMigration: *migration.NewDummyMigration(1)},
+		{DummyMigration: *migration.NewDummyMigration(2)},
+		{DummyMigration: *migration.NewDummyMigration(3)},
+	}
+	registry := migration.NewGenericRegistry()
+	for _, mig := range migrations {
+		_ = registry.Register(mig)
+	}
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	decisions := map[uint64]Decision{1: DecisionApprove, 2: DecisionSkip, 3: DecisionAbort}
+	var asked []uint64
+	handled, err := handler.MigrateUpInteractive(
+		NumOfRuns(10), func(mig migration.Migration) Decision {
+			asked = append(asked, mig.Version())
+			return decisions[mig.Version()]
+		},
+	)
+
+	suite.Assert().ErrorIs(err, ErrRunAborted)
+	suite.Assert().Equal([]uint64{1, 2, 3}, asked)
+	suite.Assert().Len(handled, 2)
+	suite.Assert().True(migrations[0].upRan)
+	suite.Assert().False(migrations[1].upRan)
+	suite.Assert().False(migrations[2].upRan)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().True(repo.PersistedExecutions[1].Finished())
+}
+
+func (suite *InteractiveTestSuite) TestItDoesNotRollBackInteractivelySkippedMigrations() {
+	mig := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	_, err := handler.MigrateUpInteractive(
+		NumOfRuns(1), func(mig migration.Migration) Decision { return DecisionSkip },
+	)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(InteractiveSkipReason, repo.PersistedExecutions[0].SkipReason)
+
+	skipped, err := handler.Skipped()
+	suite.Require().NoError(err)
+	suite.Assert().Len(skipped, 1)
+
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Require().NoError(err)
+	suite.Assert().False(mig.upRan)
+	suite.Assert().False(mig.downRan)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
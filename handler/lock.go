@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// WithExclusiveLock Makes MigrateUp and MigrateDown runs hold the repository's migrations lock
+// (see execution.Locker), so only one process runs migrations at a time. Runs fail if the lock
+// is held by another process (errors.Is(err, execution.ErrLockHeld)) or if the repository does
+// not support locking.
+func WithExclusiveLock() Option {
+	return func(handler *MigrationsHandler) {
+		handler.exclusiveLock = true
+	}
+}
+
+// WithLocker Same as WithExclusiveLock, but the runs hold the lock of the provided locker (for
+// example, zookeeper.Locker) instead of the repository's, so any repository can be used with an
+// external locking service. The lock holder is reported (see RunLockStatus) if the locker
+// implements execution.LockInspector.
+func WithLocker(locker execution.Locker) Option {
+	return func(handler *MigrationsHandler) {
+		handler.exclusiveLock = true
+		handler.locker = locker
+	}
+}
+
+// withLock Calls run while holding the migrations lock (see WithLocker), or the repository's, if
+// exclusive locking is enabled
+func (handler *MigrationsHandler) withLock(run func() error) error {
+	if !handler.exclusiveLock {
+		return run()
+	}
+
+	locker, isLocker := handler.locker, handler.locker != nil
+	if !isLocker {
+		locker, isLocker = handler.repository.(execution.Locker)
+	}
+	if !isLocker {
+		return errors.New(
+			"exclusive locking is enabled, but the repository does not support locking",
+		)
+	}
+
+	if err := locker.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire the migrations lock: %w", err)
+	}
+	handler.heldLock = locker
+	defer func() { handler.heldLock = nil }()
+
+	err := run()
+	if errors.Is(err, ErrSimulatedCrash) {
+		// A crashed process does not release the lock
+		return err
+	}
+
+	if unlockErr := locker.Unlock(); unlockErr != nil {
+		err = errors.Join(
+			err, fmt.Errorf("failed to release the migrations lock: %w", unlockErr),
+		)
+	}
+
+	return err
+}
+
+// checkLock Checks that the migrations lock held by the run was not lost (see
+// execution.LockKeeper). A lost lock is logged, since the run stops with a save error.
+func (handler *MigrationsHandler) checkLock() error {
+	keeper, isKeeper := handler.heldLock.(execution.LockKeeper)
+	if !isKeeper {
+		return nil
+	}
+
+	err := keeper.CheckLock()
+	if err != nil && handler.logger != nil {
+		handler.logger.Error("the migrations lock was lost, stopping the run", "error", err)
+	}
+	return err
+}
+
+// RunLockStatus The state of the migrations run lock (see WithExclusiveLock)
+type RunLockStatus struct {
+	// Enabled If exclusive locking is enabled. The other fields are not set if it is not.
+	Enabled bool
+	// Inspectable If the locker (or the repository) reports the lock holder (see
+	// execution.LockInspector)
+	Inspectable bool
+	// Holder The process holding the lock, nil if the lock is free (or not inspectable)
+	Holder *execution.LockHolder
+}
+
+// RunLockStatus Reports if the migrations run lock is currently held, by whom and since when,
+// so operators can tell why their runs are waiting or failing
+func (handler *MigrationsHandler) RunLockStatus() (RunLockStatus, error) {
+	if !handler.exclusiveLock {
+		return RunLockStatus{}, nil
+	}
+
+	status := RunLockStatus{Enabled: true}
+	var inspector execution.LockInspector
+	var isInspector bool
+	if handler.locker != nil {
+		inspector, isInspector = handler.locker.(execution.LockInspector)
+	} else {
+		inspector, isInspector = handler.readRepository.(execution.LockInspector)
+	}
+	if !isInspector {
+		return status, nil
+	}
+
+	holder, err := inspector.CurrentLockHolder()
+	if err != nil {
+		return status, fmt.Errorf("failed to read the migrations lock holder with error: %w", err)
+	}
+
+	status.Inspectable = true
+	status.Holder = holder
+	return status, nil
+}
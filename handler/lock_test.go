@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type LockTestSuite struct {
+	suite.Suite
+}
+
+func TestLockTestSuite(t *testing.T) {
+	suite.Run(t, new(LockTestSuite))
+}
+
+type lockingRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryLocker
+	heldDuringRun bool
+}
+
+func (repo *lockingRepository) Save(exec execution.MigrationExecution) error {
+	repo.heldDuringRun = repo.Held
+	return repo.InMemoryRepository.Save(exec)
+}
+
+func (suite *LockTestSuite) TestItHoldsTheLockDuringRuns() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &lockingRepository{}
+
+	handler, _ := NewHandler(registry, repo, nil, WithExclusiveLock())
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().True(repo.heldDuringRun)
+	suite.Assert().False(repo.Held)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+
+	repo.Held = true
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, execution.ErrLockHeld)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+
+	repo.Held = false
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *LockTestSuite) TestItFailsIfRepositoryDoesNotSupportLocking() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+
+	handler, _ := NewHandler(registry, repo, nil, WithExclusiveLock())
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().ErrorContains(err, "repository does not support locking")
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *LockTestSuite) TestItReportsTheRunLockStatus() {
+	registry := migration.NewGenericRegistry()
+	repo := &lockingRepository{}
+
+	handler, _ := NewHandler(registry, repo, nil)
+	status, err := handler.RunLockStatus()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(RunLockStatus{}, status)
+
+	handler, _ = NewHandler(registry, repo, nil, WithExclusiveLock())
+	status, err = handler.RunLockStatus()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(RunLockStatus{Enabled: true, Inspectable: true}, status)
+
+	repo.Held, repo.Owner, repo.AcquiredAtMs = true, "ci-1:42", 1717236000000
+	status, err = handler.RunLockStatus()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		&execution.LockHolder{Owner: "ci-1:42", AcquiredAtMs: 1717236000000}, status.Holder,
+	)
+
+	handler, _ = NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithExclusiveLock(),
+	)
+	status, err = handler.RunLockStatus()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(RunLockStatus{Enabled: true}, status)
+}
+
+func (suite *LockTestSuite) TestItHoldsTheLockOfTheProvidedLocker() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &lockingRepository{}
+	locker := &execution.InMemoryLocker{Held: true, Owner: "ci-2:7"}
+
+	handler, _ := NewHandler(registry, repo, nil, WithLocker(locker))
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, execution.ErrLockHeld)
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	status, err := handler.RunLockStatus()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("ci-2:7", status.Holder.Owner)
+
+	locker.Held = false
+	_, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().False(repo.heldDuringRun)
+	suite.Assert().False(locker.Held)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+
+	handler, _ = NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithLocker(&execution.InMemoryLocker{}),
+	)
+	_, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+}
+
+// losingLocker Lock which reports itself as lost once lost is set
+type losingLocker struct {
+	execution.InMemoryLocker
+	lost bool
+}
+
+func (locker *losingLocker) CheckLock() error {
+	if locker.lost {
+		return fmt.Errorf("%w, session expired", execution.ErrLockLost)
+	}
+	return nil
+}
+
+type lockLosingMigration struct {
+	migration.DummyMigration
+	locker *losingLocker
+}
+
+func (mig *lockLosingMigration) Up() error {
+	mig.locker.lost = true
+	return nil
+}
+
+func (suite *LockTestSuite) TestItStopsTheRunWhenTheLockIsLost() {
+	locker := &losingLocker{}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(&lockLosingMigration{*migration.NewDummyMigration(2), locker})
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &execution.InMemoryRepository{}
+	var logs bytes.Buffer
+	handler, _ := NewHandler(
+		registry, repo, nil, WithLocker(locker),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	_, err := handler.MigrateUp(AllRuns)
+
+	suite.Assert().ErrorIs(err, execution.ErrLockLost)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().Contains(logs.String(), "the migrations lock was lost")
+
+	_, err = handler.MigrateDown(AllRuns)
+	suite.Assert().ErrorIs(err, execution.ErrLockLost)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrNotInLockFile is returned (wrapped) when a locked run (see WithLockedRuns) would execute
+// migrations which are not pinned by the lock file
+var ErrNotInLockFile = errors.New("migration is not pinned by the lock file")
+
+// WithLockFile Sets the versions expected to be applied for the release (see
+// migration.ReadLockFile). Validate reports the discrepancies between the lock file and the
+// registered or executed migrations.
+func WithLockFile(lock migration.LockFile) Option {
+	return func(handler *MigrationsHandler) {
+		handler.lockFile = lock
+	}
+}
+
+// WithLockedRuns Makes MigrateUp refuse to run migrations which are not pinned by the lock file
+// (see WithLockFile), so a deploy executes exactly the migrations of its release
+func WithLockedRuns() Option {
+	return func(handler *MigrationsHandler) {
+		handler.lockedRuns = true
+	}
+}
+
+// checkLocked Checks, for locked runs, that all migrations to be executed are pinned by the lock
+// file
+func (handler *MigrationsHandler) checkLocked(migrations []migration.Migration) error {
+	if !handler.lockedRuns {
+		return nil
+	}
+
+	if handler.lockFile == nil {
+		return fmt.Errorf("%w, no lock file was found", ErrNotInLockFile)
+	}
+
+	for _, mig := range migrations {
+		if !handler.lockFile.Contains(mig.Version()) {
+			return fmt.Errorf("%w, migration %d", ErrNotInLockFile, mig.Version())
+		}
+	}
+	return nil
+}
+
+// LockFileDiscrepancies Returns a human-readable description for each discrepancy between the
+// lock file and the migrations: registered (pending or executed) migrations which are not
+// pinned and pinned migrations which are not registered. Versions covered by the baseline are
+// ignored. Returns no discrepancies if no lock file is configured.
+func (handler *MigrationsHandler) LockFileDiscrepancies() ([]string, error) {
+	if handler.lockFile == nil {
+		return nil, nil
+	}
+
+	plan, err := handler.plan()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to check the lock file, failed to create execution plan with error: %w", err,
+		)
+	}
+
+	var discrepancies []string
+	registered := map[uint64]bool{}
+	executedCount := plan.FinishedExecutionsCount()
+
+	for i, mig := range plan.orderedMigrations {
+		registered[mig.Version()] = true
+		if handler.lockFile.Contains(mig.Version()) {
+			continue
+		}
+
+		state := "pending"
+		if i < executedCount {
+			state = "executed"
+		}
+		discrepancies = append(
+			discrepancies,
+			fmt.Sprintf(
+				"migration %d is %s, but not pinned by the lock file", mig.Version(), state,
+			),
+		)
+	}
+
+	for _, version := range handler.lockFile {
+		if version > handler.baseline && !registered[version] {
+			discrepancies = append(
+				discrepancies,
+				fmt.Sprintf("migration %d is pinned by the lock file, but not registered", version),
+			)
+		}
+	}
+
+	return discrepancies, nil
+}
+
+// WriteLockFile Pins all registered migrations, except the ones covered by the baseline, in the
+// lock file from the migrations directory (see migration.WriteLockFile). Returns the pinned
+// versions.
+func (handler *MigrationsHandler) WriteLockFile(
+	dirPath migration.MigrationsDirPath,
+) ([]uint64, error) {
+	var versions []uint64
+	for _, version := range handler.registry.OrderedVersions() {
+		if version > handler.baseline {
+			versions = append(versions, version)
+		}
+	}
+
+	if err := migration.WriteLockFile(dirPath, versions); err != nil {
+		return nil, fmt.Errorf("failed to write the lock file with error: %w", err)
+	}
+	return versions, nil
+}
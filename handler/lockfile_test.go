@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type LockFileTestSuite struct {
+	suite.Suite
+}
+
+func TestLockFileTestSuite(t *testing.T) {
+	suite.Run(t, new(LockFileTestSuite))
+}
+
+func (suite *LockFileTestSuite) TestItRefusesToRunMigrationsWhichAreNotPinned() {
+	scenarios := map[string]struct {
+		lock          migration.LockFile
+		numOfRuns     NumOfRuns
+		expectedErr   string
+		expectedExecs int
+	}{
+		"all pinned":      {migration.LockFile{1, 2}, 2, "", 2},
+		"not pinned":      {migration.LockFile{1}, 2, "migration 2", 0},
+		"pinned subset":   {migration.LockFile{1}, 1, "", 1},
+		"no lock file":    {nil, 1, "no lock file was found", 0},
+		"empty lock file": {migration.LockFile{}, 1, "migration 1", 0},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Run(name, func() {
+			registry := migration.NewGenericRegistry()
+			_ = registry.Register(migration.NewDummyMigration(1))
+			_ = registry.Register(migration.NewDummyMigration(2))
+			repo := &execution.InMemoryRepository{}
+			handler, _ := NewHandler(
+				registry, repo, nil, WithLockFile(scenario.lock), WithLockedRuns(),
+			)
+
+			_, err := handler.MigrateUp(scenario.numOfRuns)
+
+			if scenario.expectedErr != "" {
+				suite.Assert().ErrorIs(err, ErrNotInLockFile)
+				suite.Assert().ErrorContains(err, scenario.expectedErr)
+			} else {
+				suite.Assert().NoError(err)
+			}
+			suite.Assert().Len(repo.PersistedExecutions, scenario.expectedExecs)
+		})
+	}
+}
+
+func (suite *LockFileTestSuite) TestItRunsMigrationsWhichAreNotPinnedWhenRunsAreNotLocked() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithLockFile(migration.LockFile{}),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().NoError(err)
+}
+
+func (suite *LockFileTestSuite) TestItReportsLockFileDiscrepancies() {
+	registry := migration.NewGenericRegistry()
+	for version := uint64(2); version <= 5; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+			{Version: 3, ExecutedAtMs: 2, FinishedAtMs: 2},
+		},
+	}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithBaseline(1), WithLockFile(migration.LockFile{1, 2, 4, 6}),
+	)
+
+	discrepancies, err := handler.LockFileDiscrepancies()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]string{
+			"migration 3 is executed, but not pinned by the lock file",
+			"migration 5 is pending, but not pinned by the lock file",
+			"migration 6 is pinned by the lock file, but not registered",
+		},
+		discrepancies,
+	)
+
+	problems, err := handler.Validate(nil)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(discrepancies, problems)
+
+	handler, _ = NewHandler(registry, repo, nil)
+	discrepancies, err = handler.LockFileDiscrepancies()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(discrepancies)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WithLogger Forwards the messages logged by the migrations (see migration.LoggerAware) to the
+// logger, with the migration version and direction as attributes. The messages are captured in
+// the run report regardless of this option.
+func WithLogger(logger *slog.Logger) Option {
+	return func(handler *MigrationsHandler) {
+		handler.logger = logger
+	}
+}
+
+// LogEntry A message logged by a migration, via its scoped logger. Messages below
+// slog.LevelInfo are not captured.
+type LogEntry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs The attributes of the message, including the ones added with Logger.With. Keys of
+	// attributes in groups are prefixed with the group names, separated by dots.
+	Attrs []slog.Attr
+}
+
+// logCapture The messages logged by a migration during a Up() or Down() call
+type logCapture struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+// Entries Returns the captured messages
+func (capture *logCapture) Entries() []LogEntry {
+	if capture == nil {
+		return nil
+	}
+
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	return slices.Clone(capture.entries)
+}
+
+// scopeLogger Sets a logger scoped to the migration and direction, if the migration implements
+// migration.LoggerAware. Returns the capture of the messages it logs, nil otherwise.
+func (handler *MigrationsHandler) scopeLogger(
+	mig migration.Migration,
+	direction MigrationStage,
+) *logCapture {
+	loggerAware, isLoggerAware := mig.(migration.LoggerAware)
+	if !isLoggerAware {
+		return nil
+	}
+
+	capture := &logCapture{}
+	captureHandler := &captureHandler{capture: capture}
+
+	if handler.logger != nil {
+		captureHandler.next = handler.logger.Handler().WithAttrs(
+			[]slog.Attr{
+				slog.Uint64("migration", mig.Version()),
+				slog.String("direction", string(direction)),
+			},
+		)
+	}
+
+	loggerAware.SetLogger(slog.New(captureHandler))
+	return capture
+}
+
+// captureHandler slog.Handler which captures the messages and forwards them to the next
+// handler, if any
+type captureHandler struct {
+	capture *logCapture
+	next    slog.Handler
+	attrs   []slog.Attr
+	prefix  string
+}
+
+func (h *captureHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || (h.next != nil && h.next.Enabled(ctx, level))
+}
+
+func (h *captureHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelInfo {
+		entry := LogEntry{
+			Time:    record.Time,
+			Level:   record.Level,
+			Message: record.Message,
+			Attrs:   slices.Clone(h.attrs),
+		}
+
+		record.Attrs(func(attr slog.Attr) bool {
+			entry.Attrs = appendAttr(entry.Attrs, h.prefix, attr)
+			return true
+		})
+
+		h.capture.mu.Lock()
+		h.capture.entries = append(h.capture.entries, entry)
+		h.capture.mu.Unlock()
+	}
+
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		return h.next.Handle(ctx, record)
+	}
+
+	return nil
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = slices.Clone(h.attrs)
+	for _, attr := range attrs {
+		clone.attrs = appendAttr(clone.attrs, h.prefix, attr)
+	}
+
+	if h.next != nil {
+		clone.next = h.next.WithAttrs(attrs)
+	}
+
+	return &clone
+}
+
+func (h *captureHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+
+	if h.next != nil {
+		clone.next = h.next.WithGroup(name)
+	}
+
+	return &clone
+}
+
+// appendAttr Appends the attribute, with its key prefixed with the groups. Group attributes are
+// flattened.
+func appendAttr(attrs []slog.Attr, prefix string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Value.Kind() != slog.KindGroup {
+		attr.Key = prefix + attr.Key
+		return append(attrs, attr)
+	}
+
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+
+	for _, groupAttr := range attr.Value.Group() {
+		attrs = appendAttr(attrs, prefix, groupAttr)
+	}
+
+	return attrs
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggerTestSuite struct {
+	suite.Suite
+}
+
+func TestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
+
+// LoggingMigration Logs its progress via the logger set by the handler
+type LoggingMigration struct {
+	migration.DummyMigration
+	logger *slog.Logger
+	err    error
+}
+
+func (l *LoggingMigration) SetLogger(logger *slog.Logger) {
+	l.logger = logger
+}
+
+func (l *LoggingMigration) Up() error {
+	l.logger.Debug("connecting")
+	l.logger.With("table", "users").Info("copied rows", "rows", 10)
+	l.logger.WithGroup("batch").Warn("slow batch", slog.Group("stats", "ms", 1500))
+	return l.err
+}
+
+func (l *LoggingMigration) Down() error {
+	l.logger.Info("dropped table", "err", errors.New("already dropped"))
+	return l.err
+}
+
+func (suite *LoggerTestSuite) TestItCapturesTheMigrationLogsInTheRunReport() {
+	mig := &LoggingMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	_ = registry.Register(migration.NewDummyMigration(2))
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	report, err := handler.MigrateUpWithReport(NumOfRuns(2))
+
+	suite.Require().NoError(err)
+	logs := report.Migrations[0].Logs
+	suite.Require().Len(logs, 2)
+	suite.Assert().Equal(slog.LevelInfo, logs[0].Level)
+	suite.Assert().Equal("copied rows", logs[0].Message)
+	suite.Assert().Equal("[table=users rows=10]", attrsString(logs[0].Attrs))
+	suite.Assert().Equal(slog.LevelWarn, logs[1].Level)
+	suite.Assert().Equal("[batch.stats.ms=1500]", attrsString(logs[1].Attrs))
+	suite.Assert().Empty(report.Migrations[1].Logs)
+
+	downReport, err := handler.MigrateDownWithReport(NumOfRuns(2))
+
+	suite.Require().NoError(err)
+	suite.Assert().Empty(downReport.Migrations[0].Logs)
+	suite.Require().Len(downReport.Migrations[1].Logs, 1)
+	suite.Assert().Equal("dropped table", downReport.Migrations[1].Logs[0].Message)
+
+	encoded, _ := json.Marshal(downReport)
+	suite.Assert().Contains(
+		string(encoded),
+		`"message":"dropped table","attrs":{"err":"already dropped"}`,
+	)
+}
+
+func (suite *LoggerTestSuite) TestItForwardsTheMigrationLogsToTheLogger() {
+	var output bytes.Buffer
+	logger := slog.New(
+		slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	mig := &LoggingMigration{
+		DummyMigration: *migration.NewDummyMigration(1), err: errors.New("up failed"),
+	}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithLogger(logger),
+	)
+
+	report, err := handler.MigrateUpWithReport(NumOfRuns(1))
+
+	suite.Assert().ErrorContains(err, "up failed")
+	suite.Assert().Len(report.Migrations[0].Logs, 2)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	suite.Require().Len(lines, 3)
+	suite.Assert().Contains(lines[0], `level=DEBUG msg=connecting migration=1 direction=up`)
+	suite.Assert().Contains(
+		lines[1], `msg="copied rows" migration=1 direction=up table=users rows=10`,
+	)
+	suite.Assert().Contains(lines[2], `batch.stats.ms=1500`)
+
+	output.Reset()
+	_, _ = handler.ForceDown(1)
+	suite.Assert().Contains(output.String(), `msg="dropped table" migration=1 direction=down`)
+}
+
+// attrsString Formats the attributes, for assertions
+func attrsString(attrs []slog.Attr) string {
+	formatted := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		formatted = append(formatted, attr.String())
+	}
+	return "[" + strings.Join(formatted, " ") + "]"
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrMaintenanceModeFailed is returned (wrapped) when entering or exiting the application
+// maintenance mode fails
+var ErrMaintenanceModeFailed = errors.New("maintenance mode toggle failed")
+
+// MaintenancePolicy Which runs enter the application maintenance mode
+type MaintenancePolicy int
+
+const (
+	// MaintenanceWhenRequired Only runs including migrations which require downtime (see
+	// migration.DowntimeRequirer) enter the maintenance mode
+	MaintenanceWhenRequired MaintenancePolicy = iota
+
+	// MaintenanceAlways All runs with migrations to execute enter the maintenance mode
+	MaintenanceAlways
+)
+
+// WithMaintenanceMode Coordinates the application maintenance mode (for example, an HTTP call,
+// a feature flag toggle or a k8s annotation, see notify.Webhook.MaintenanceHook) with the
+// MigrateUp and MigrateDown runs. enter is executed right before the first migration of the run
+// and exit right after the last one, even if a migration failed. If enter fails, the run stops
+// without executing any migration, but exit is still executed, to revert a partially applied
+// toggle. The policy decides which runs enter the maintenance mode.
+func WithMaintenanceMode(enter RunHook, exit RunHook, policy MaintenancePolicy) Option {
+	return func(handler *MigrationsHandler) {
+		handler.enterMaintenance = enter
+		handler.exitMaintenance = exit
+		handler.maintenancePolicy = policy
+	}
+}
+
+// requiresMaintenance Checks if the run, executing the migrations, must enter the maintenance
+// mode
+func (handler *MigrationsHandler) requiresMaintenance(migrations []migration.Migration) bool {
+	if handler.enterMaintenance == nil || len(migrations) == 0 {
+		return false
+	}
+
+	if handler.maintenancePolicy == MaintenanceAlways {
+		return true
+	}
+
+	for _, mig := range migrations {
+		if requirer, isRequirer := mig.(migration.DowntimeRequirer); isRequirer &&
+			requirer.RequiresDowntime() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// enterMaintenanceMode Executes the enter maintenance mode hook
+func (handler *MigrationsHandler) enterMaintenanceMode(direction MigrationStage) error {
+	if err := handler.enterMaintenance(direction); err != nil {
+		return fmt.Errorf(
+			"%w, failed to enter maintenance mode with error: %w", ErrMaintenanceModeFailed, err,
+		)
+	}
+	return nil
+}
+
+// exitMaintenanceMode Executes the exit maintenance mode hook, returning the run error joined
+// with its failure
+func (handler *MigrationsHandler) exitMaintenanceMode(
+	direction MigrationStage,
+	runErr error,
+) error {
+	if handler.exitMaintenance == nil {
+		return runErr
+	}
+
+	if err := handler.exitMaintenance(direction); err != nil {
+		return errors.Join(
+			runErr,
+			fmt.Errorf(
+				"%w, failed to exit maintenance mode with error: %w", ErrMaintenanceModeFailed, err,
+			),
+		)
+	}
+	return runErr
+}
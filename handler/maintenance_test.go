@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type MaintenanceTestSuite struct {
+	suite.Suite
+}
+
+func TestMaintenanceTestSuite(t *testing.T) {
+	suite.Run(t, new(MaintenanceTestSuite))
+}
+
+// DowntimeMigration Migration which requires downtime
+type DowntimeMigration struct {
+	migration.DummyMigration
+}
+
+func (m *DowntimeMigration) RequiresDowntime() bool {
+	return true
+}
+
+func (suite *MaintenanceTestSuite) TestItEntersMaintenanceModeAccordingToThePolicy() {
+	scenarios := map[string]struct {
+		policy        MaintenancePolicy
+		downtime      bool
+		expectedCalls []string
+	}{
+		"required": {
+			MaintenanceWhenRequired, true,
+			[]string{"before up", "enter up", "exit up", "after up"},
+		},
+		"not required": {MaintenanceWhenRequired, false, []string{"before up", "after up"}},
+		"always": {
+			MaintenanceAlways, false,
+			[]string{"before up", "enter up", "exit up", "after up"},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Run(name, func() {
+			registry := migration.NewGenericRegistry()
+			_ = registry.Register(migration.NewDummyMigration(1))
+			if scenario.downtime {
+				_ = registry.Register(&DowntimeMigration{*migration.NewDummyMigration(2)})
+			} else {
+				_ = registry.Register(migration.NewDummyMigration(2))
+			}
+			var calls []string
+			handler, _ := NewHandler(
+				registry, &execution.InMemoryRepository{}, nil,
+				WithBeforeRun(recordingHook(&calls, "before", nil)),
+				WithAfterRun(recordingHook(&calls, "after", nil)),
+				WithMaintenanceMode(
+					recordingHook(&calls, "enter", nil), recordingHook(&calls, "exit", nil),
+					scenario.policy,
+				),
+			)
+
+			_, err := handler.MigrateUp(NumOfRuns(2))
+
+			suite.Assert().NoError(err)
+			suite.Assert().Equal(scenario.expectedCalls, calls)
+		})
+	}
+}
+
+func (suite *MaintenanceTestSuite) TestItEntersMaintenanceModeOnlyForMigrationsInTheRun() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(&DowntimeMigration{*migration.NewDummyMigration(2)})
+	var calls []string
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithMaintenanceMode(
+			recordingHook(&calls, "enter", nil), recordingHook(&calls, "exit", nil),
+			MaintenanceWhenRequired,
+		),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(calls)
+
+	_, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateDown(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]string{"enter up", "exit up", "enter down", "exit down"}, calls)
+}
+
+func (suite *MaintenanceTestSuite) TestItExitsMaintenanceModeWhenMigrationsFail() {
+	registry := migration.NewGenericRegistry()
+	upErr := errors.New("up failed")
+	_ = registry.Register(&FailingMigration{*migration.NewDummyMigration(1), upErr})
+	exitErr := errors.New("flag service unavailable")
+	var calls []string
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithMaintenanceMode(
+			recordingHook(&calls, "enter", nil), recordingHook(&calls, "exit", exitErr),
+			MaintenanceAlways,
+		),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().ErrorIs(err, upErr)
+	suite.Assert().ErrorIs(err, exitErr)
+	suite.Assert().ErrorIs(err, ErrMaintenanceModeFailed)
+	suite.Assert().Equal([]string{"enter up", "exit up"}, calls)
+}
+
+func (suite *MaintenanceTestSuite) TestItStopsTheRunWhenEnteringMaintenanceModeFails() {
+	registry := migration.NewGenericRegistry()
+	mig := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	_ = registry.Register(mig)
+	repo := &execution.InMemoryRepository{}
+	enterErr := errors.New("annotation update denied")
+	var calls []string
+	handler, _ := NewHandler(
+		registry, repo, nil,
+		WithMaintenanceMode(
+			recordingHook(&calls, "enter", enterErr), recordingHook(&calls, "exit", nil),
+			MaintenanceAlways,
+		),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().ErrorIs(err, enterErr)
+	suite.Assert().ErrorIs(err, ErrMaintenanceModeFailed)
+	suite.Assert().False(mig.upRan)
+	suite.Assert().Empty(repo.PersistedExecutions)
+	suite.Assert().Equal([]string{"enter up", "exit up"}, calls)
+}
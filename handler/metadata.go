@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WithRunMetadata Attaches the run metadata (for example, the deploy id, git SHA and operator)
+// to every execution created by the handler, tying schema changes to specific deployments
+func WithRunMetadata(metadata execution.RunMetadata) Option {
+	return func(handler *MigrationsHandler) {
+		handler.runMetadata = metadata
+	}
+}
+
+// startExecution Starts a new execution for the migration, using the handler's clock and run
+// metadata
+func (handler *MigrationsHandler) startExecution(
+	mig migration.Migration,
+) *execution.MigrationExecution {
+	exec := execution.StartExecutionAt(mig, handler.clock.Now())
+	exec.Run = handler.runMetadata
+	return exec
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetadataTestSuite struct {
+	suite.Suite
+}
+
+func TestMetadataTestSuite(t *testing.T) {
+	suite.Run(t, new(MetadataTestSuite))
+}
+
+func (suite *MetadataTestSuite) TestItStoresRunMetadataWithEachExecution() {
+	metadata := execution.RunMetadata{DeployID: "deploy-12", GitSHA: "a1b2c3", Operator: "jane"}
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 3; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil, WithRunMetadata(metadata))
+
+	_, err := handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	_, err = handler.ForceUp(3)
+	suite.Assert().NoError(err)
+
+	executions, _ := repo.LoadExecutions()
+	suite.Assert().Len(executions, 3)
+	for _, exec := range executions {
+		suite.Assert().Equal(metadata, exec.Run)
+		suite.Assert().True(exec.Finished())
+	}
+}
+
+func (suite *MetadataTestSuite) TestItStoresNoRunMetadataByDefault() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+
+	executions, _ := repo.LoadExecutions()
+	suite.Assert().Len(executions, 1)
+	suite.Assert().True(executions[0].Run.IsZero())
+}
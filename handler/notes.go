@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// ErrNotesNotSupported is returned when the repository can not store notes (see
+// execution.NoteStore)
+var ErrNotesNotSupported = errors.New("the repository can not store execution notes")
+
+// AddNote Attaches a free-text note (for example, "backfill verified") to the execution of the
+// migration, for operational context. The note author is the operator from the run metadata
+// (see WithRunMetadata). Fails if the migration was not executed.
+func (handler *MigrationsHandler) AddNote(version uint64, text string) (execution.Note, error) {
+	errMsg := fmt.Sprintf("failed to add note to migration %d", version)
+
+	if err := handler.checkWritable("note"); err != nil {
+		return execution.Note{}, err
+	}
+
+	store, isStore := handler.repository.(execution.NoteStore)
+	if !isStore {
+		return execution.Note{}, fmt.Errorf("%s, %w", errMsg, ErrNotesNotSupported)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return execution.Note{}, fmt.Errorf("%s, the note is empty", errMsg)
+	}
+
+	exec, err := handler.repository.FindOne(version)
+	if err != nil {
+		return execution.Note{}, fmt.Errorf(
+			"%s, failed to find execution with error: %w", errMsg, err,
+		)
+	} else if exec == nil {
+		return execution.Note{}, fmt.Errorf("%s, the migration was not executed", errMsg)
+	}
+
+	note := execution.Note{
+		Version:      version,
+		ExecutedAtMs: exec.ExecutedAtMs,
+		Text:         text,
+		Author:       handler.runMetadata.Operator,
+		CreatedAtMs:  uint64(handler.clock.Now().UnixMilli()),
+	}
+	if err = store.AddNote(note); err != nil {
+		return execution.Note{}, fmt.Errorf("%s, failed to store note with error: %w", errMsg, err)
+	}
+	return note, nil
+}
+
+// Notes Returns the notes attached to the current executions, by version, in the order they were
+// added. The notes of rolled back executions are not returned, even if the migration was
+// executed again. Returns no notes if the repository can not store notes.
+func (handler *MigrationsHandler) Notes() (map[uint64][]execution.Note, error) {
+	store, isStore := handler.readRepository.(execution.NoteStore)
+	if !isStore {
+		return nil, nil
+	}
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load execution notes with error: %w", err)
+	}
+
+	executions, err := handler.readRepository.LoadExecutions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load executions with error: %w", err)
+	}
+
+	executedAt := make(map[uint64]uint64, len(executions))
+	for _, exec := range executions {
+		executedAt[exec.Version] = exec.ExecutedAtMs
+	}
+
+	byVersion := make(map[uint64][]execution.Note)
+	for _, note := range notes {
+		if executedAtMs, found := executedAt[note.Version]; found &&
+			executedAtMs == note.ExecutedAtMs {
+			byVersion[note.Version] = append(byVersion[note.Version], note)
+		}
+	}
+	return byVersion, nil
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotesTestSuite struct {
+	suite.Suite
+}
+
+func TestNotesTestSuite(t *testing.T) {
+	suite.Run(t, new(NotesTestSuite))
+}
+
+type notesRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryNoteStore
+}
+
+func (suite *NotesTestSuite) newHandler(
+	repo execution.Repository,
+	options ...Option,
+) *MigrationsHandler {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+
+	handler, err := NewHandler(registry, repo, nil, options...)
+	suite.Require().NoError(err)
+	return handler
+}
+
+func (suite *NotesTestSuite) TestItAttachesNotesToExecutions() {
+	repo := &notesRepository{}
+	repo.PersistedExecutions = []execution.MigrationExecution{{Version: 1, FinishedAtMs: 1}}
+	handler := suite.newHandler(
+		repo,
+		WithClock(clock.NewFixed(time.UnixMilli(1717236000000))),
+		WithRunMetadata(execution.RunMetadata{Operator: "jane"}),
+	)
+
+	note, err := handler.AddNote(1, "  backfill verified ")
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		execution.Note{
+			Version: 1, Text: "backfill verified", Author: "jane", CreatedAtMs: 1717236000000,
+		},
+		note,
+	)
+	_, err = handler.AddNote(1, "replicas caught up")
+	suite.Assert().NoError(err)
+
+	notes, err := handler.Notes()
+	suite.Assert().NoError(err)
+	suite.Assert().Len(notes[1], 2)
+	suite.Assert().Equal("replicas caught up", notes[1][1].Text)
+	suite.Assert().Empty(notes[2])
+}
+
+func (suite *NotesTestSuite) TestItDoesNotAttachNotesToLaterExecutions() {
+	repo := &notesRepository{}
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1717235000000, FinishedAtMs: 1717235000001},
+	}
+	handler := suite.newHandler(repo)
+
+	note, err := handler.AddNote(1, "backfill verified")
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(uint64(1717235000000), note.ExecutedAtMs)
+
+	// The migration is rolled back and executed again
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1717236000000, FinishedAtMs: 1717236000001},
+	}
+	notes, err := handler.Notes()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(notes[1])
+
+	_, err = handler.AddNote(1, "replicas caught up")
+	suite.Assert().NoError(err)
+	notes, err = handler.Notes()
+	suite.Assert().NoError(err)
+	suite.Assert().Len(notes[1], 1)
+	suite.Assert().Equal("replicas caught up", notes[1][0].Text)
+
+	repo.PersistedExecutions = nil
+	notes, err = handler.Notes()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(notes)
+}
+
+func (suite *NotesTestSuite) TestItRefusesInvalidNotes() {
+	repo := &notesRepository{}
+	repo.PersistedExecutions = []execution.MigrationExecution{{Version: 1, FinishedAtMs: 1}}
+	handler := suite.newHandler(repo)
+
+	_, err := handler.AddNote(2, "verified")
+	suite.Assert().ErrorContains(
+		err, "failed to add note to migration 2, the migration was not executed",
+	)
+	_, err = handler.AddNote(1, " ")
+	suite.Assert().ErrorContains(err, "the note is empty")
+
+	repo.AddErr = errors.New("connection lost")
+	_, err = handler.AddNote(1, "verified")
+	suite.Assert().ErrorIs(err, repo.AddErr)
+	suite.Assert().Empty(repo.Notes)
+
+	plain := suite.newHandler(&execution.InMemoryRepository{})
+	_, err = plain.AddNote(1, "verified")
+	suite.Assert().ErrorIs(err, ErrNotesNotSupported)
+	notes, err := plain.Notes()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(notes)
+
+	readOnly := suite.newHandler(repo, WithReadOnly())
+	_, err = readOnly.AddNote(1, "verified")
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+}
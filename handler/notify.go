@@ -0,0 +1,41 @@
+package handler
+
+import "fmt"
+
+// Notifier Receives the reports of the MigrateUp and MigrateDown runs (for example, to send
+// them to a webhook, see the notify package)
+type Notifier interface {
+	Notify(report *RunReport) error
+}
+
+// WithNotifier Makes the handler send the reports of the MigrateUp and MigrateDown runs,
+// including the failed ones, to the notifier. Runs which did not handle any migration and did
+// not fail are not sent. Notification failures do not change the run result, they are added to
+// the report warnings (see RunReport.Warnings) and logged (see WithLogger).
+func WithNotifier(notifier Notifier) Option {
+	return func(handler *MigrationsHandler) {
+		handler.notifiers = append(handler.notifiers, notifier)
+	}
+}
+
+// notify Sends the run report to the notifiers, recording the notification failures as report
+// warnings. Returns the run error.
+func (handler *MigrationsHandler) notify(report *RunReport, runErr error) error {
+	if len(report.Migrations) == 0 && runErr == nil {
+		return nil
+	}
+
+	for _, notifier := range handler.notifiers {
+		if notifyErr := notifier.Notify(report); notifyErr != nil {
+			warning := fmt.Sprintf(
+				"failed to send the run notification with error: %s", notifyErr,
+			)
+			report.Warnings = append(report.Warnings, warning)
+			if handler.logger != nil {
+				handler.logger.Warn(warning, "batch", report.BatchID)
+			}
+		}
+	}
+
+	return runErr
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotifyTestSuite struct {
+	suite.Suite
+}
+
+func TestNotifyTestSuite(t *testing.T) {
+	suite.Run(t, new(NotifyTestSuite))
+}
+
+type FakeNotifier struct {
+	reports []*RunReport
+	err     error
+}
+
+func (f *FakeNotifier) Notify(report *RunReport) error {
+	f.reports = append(f.reports, report)
+	return f.err
+}
+
+func (suite *NotifyTestSuite) TestItSendsTheRunReportsToTheNotifiers() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(
+		&FailingMigration{*migration.NewDummyMigration(2), errors.New("up failed")},
+	)
+	notifier := &FakeNotifier{}
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithNotifier(notifier),
+	)
+
+	upReport, err := handler.MigrateUpWithReport(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().ErrorContains(err, "up failed")
+	downReport, _ := handler.MigrateDownWithReport(NumOfRuns(2))
+
+	suite.Require().Len(notifier.reports, 3)
+	suite.Assert().Same(upReport, notifier.reports[0])
+	suite.Assert().Equal(1, notifier.reports[1].Count(OutcomeFailed))
+	suite.Assert().Same(downReport, notifier.reports[2])
+}
+
+func (suite *NotifyTestSuite) TestItSkipsEmptyRunsAndWarnsAboutNotificationFailures() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	notifyErr := errors.New("endpoint down")
+	notifier := &FakeNotifier{err: notifyErr}
+	repo := &execution.InMemoryRepository{}
+	var logs bytes.Buffer
+	handler, _ := NewHandler(
+		registry, repo, nil,
+		WithNotifier(notifier), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	_, err := handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(notifier.reports)
+
+	report, err := handler.MigrateUpWithReport(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]string{"failed to send the run notification with error: endpoint down"},
+		report.Warnings,
+	)
+	suite.Assert().Contains(logs.String(), "level=WARN")
+	suite.Assert().Contains(logs.String(), "endpoint down")
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+}
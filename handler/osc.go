@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/online"
+)
+
+// DefaultSchemaChangeTimeout How long each delegated schema change job is polled for, unless
+// changed with WithSchemaChangeTimeout
+const DefaultSchemaChangeTimeout = 24 * time.Hour
+
+// WithSchemaChangeExecutor Makes the handler delegate the schema changes of the migrations
+// which implement online.SchemaChangeMigration to the online schema change executor, polling
+// for their completion at the provided interval. If the repository implements
+// execution.ProgressStore, the submitted jobs are persisted, so a run resumed after a crash
+// continues polling them instead of submitting the changes again.
+func WithSchemaChangeExecutor(executor online.Executor, pollInterval time.Duration) Option {
+	return func(handler *MigrationsHandler) {
+		handler.schemaChangeExecutor = executor
+		handler.schemaChangePoll = pollInterval
+	}
+}
+
+// WithSchemaChangeTimeout Limits how long each delegated schema change job is polled for (see
+// WithSchemaChangeExecutor). At the limit, the migration fails, but the job is not forgotten, so
+// the next run resumes polling it.
+func WithSchemaChangeTimeout(timeout time.Duration) Option {
+	return func(handler *MigrationsHandler) {
+		handler.schemaChangeTimeout = timeout
+	}
+}
+
+// up Applies the migration's delegated schema changes, if any, and executes its Up()
+func (handler *MigrationsHandler) up(mig migration.Migration) error {
+	if changeMigration, isChangeMigration := mig.(online.SchemaChangeMigration); isChangeMigration {
+		if err := handler.applySchemaChanges(mig.Version(), changeMigration); err != nil {
+			return err
+		}
+	}
+	return mig.Up()
+}
+
+// applySchemaChanges Submits the schema changes to the executor and waits for them, one by one
+func (handler *MigrationsHandler) applySchemaChanges(
+	version uint64,
+	changeMigration online.SchemaChangeMigration,
+) error {
+	if handler.schemaChangeExecutor == nil {
+		return errors.New("the migration has schema changes, but no schema change executor is set")
+	}
+
+	store, _ := handler.repository.(execution.ProgressStore)
+
+	for i, change := range changeMigration.SchemaChanges() {
+		key := fmt.Sprintf("osc:%d:%d", version, i)
+		if err := handler.applySchemaChange(store, key, change); err != nil {
+			return fmt.Errorf("schema change %d for table %s failed: %w", i, change.Table, err)
+		}
+	}
+
+	return nil
+}
+
+// resetSchemaChanges Clears the persisted progress of the migration's schema changes (after
+// Down() reverted them), so the next Up() submits them again
+func (handler *MigrationsHandler) resetSchemaChanges(mig migration.Migration) error {
+	changeMigration, isChangeMigration := mig.(online.SchemaChangeMigration)
+	store, isStore := handler.repository.(execution.ProgressStore)
+	if !isChangeMigration || !isStore {
+		return nil
+	}
+
+	for i := range changeMigration.SchemaChanges() {
+		key := fmt.Sprintf("osc:%d:%d", mig.Version(), i)
+		if err := saveProgress(store, execution.Progress{Key: key}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applySchemaChange Submits the change, unless a job for it was already submitted, and polls
+// the job until it completes, the run context is done or the schema change timeout is reached
+func (handler *MigrationsHandler) applySchemaChange(
+	store execution.ProgressStore,
+	key string,
+	change online.SchemaChange,
+) error {
+	timeout := handler.schemaChangeTimeout
+	if timeout <= 0 {
+		timeout = DefaultSchemaChangeTimeout
+	}
+	ctx, cancel := context.WithTimeout(handler.migrationContext(), timeout)
+	defer cancel()
+
+	progress := &execution.Progress{Key: key}
+
+	if store != nil {
+		saved, err := store.LoadProgress(key)
+		if err != nil {
+			return fmt.Errorf("failed to load the submitted job with error: %w", err)
+		} else if saved != nil {
+			progress = saved
+		}
+	}
+
+	if progress.Done {
+		return nil
+	}
+
+	if progress.Token == "" {
+		jobID, err := handler.schemaChangeExecutor.Submit(ctx, change)
+		if err != nil {
+			return fmt.Errorf("failed to submit the job with error: %w", err)
+		}
+
+		progress.Token = jobID
+		if err = saveProgress(store, *progress); err != nil {
+			return err
+		}
+	}
+
+	for {
+		status, err := handler.schemaChangeExecutor.Status(ctx, progress.Token)
+		if err != nil {
+			return fmt.Errorf("failed to check job %s with error: %w", progress.Token, err)
+		}
+
+		switch status.State {
+		case online.JobCompleted:
+			progress.Done = true
+			return saveProgress(store, *progress)
+		case online.JobFailed:
+			// The next run submits the change again
+			return errors.Join(
+				fmt.Errorf("job %s failed: %s", progress.Token, status.Message),
+				saveProgress(store, execution.Progress{Key: progress.Key}),
+			)
+		}
+
+		if err = handler.wait(ctx, handler.schemaChangePoll); err != nil {
+			return fmt.Errorf("stopped polling job %s: %w", progress.Token, err)
+		}
+	}
+}
+
+// saveProgress Persists the schema change progress, if there is a store
+func saveProgress(store execution.ProgressStore, progress execution.Progress) error {
+	if store == nil {
+		return nil
+	}
+
+	if err := store.SaveProgress(progress); err != nil {
+		return fmt.Errorf("failed to save the job progress with error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/online"
+	"github.com/stretchr/testify/suite"
+)
+
+type OscTestSuite struct {
+	suite.Suite
+}
+
+func TestOscTestSuite(t *testing.T) {
+	suite.Run(t, new(OscTestSuite))
+}
+
+type SchemaChangeMigration struct {
+	migration.DummyMigration
+	changes []online.SchemaChange
+	upCalls int
+}
+
+func (mig *SchemaChangeMigration) SchemaChanges() []online.SchemaChange {
+	return mig.changes
+}
+
+func (mig *SchemaChangeMigration) Up() error {
+	mig.upCalls++
+	return nil
+}
+
+// fakeExecutor Completes (or fails) jobs after the configured number of status checks
+type fakeExecutor struct {
+	submitted  []online.SchemaChange
+	checks     map[string]int
+	runningFor int
+	failWith   string
+}
+
+func (executor *fakeExecutor) Submit(
+	_ context.Context, change online.SchemaChange,
+) (string, error) {
+	executor.submitted = append(executor.submitted, change)
+	return fmt.Sprintf("job-%d", len(executor.submitted)), nil
+}
+
+func (executor *fakeExecutor) Status(_ context.Context, jobID string) (online.JobStatus, error) {
+	if executor.checks == nil {
+		executor.checks = make(map[string]int)
+	}
+	executor.checks[jobID]++
+
+	if executor.checks[jobID] <= executor.runningFor {
+		return online.JobStatus{State: online.JobRunning}, nil
+	} else if executor.failWith != "" {
+		return online.JobStatus{State: online.JobFailed, Message: executor.failWith}, nil
+	}
+	return online.JobStatus{State: online.JobCompleted}, nil
+}
+
+type oscRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryProgressStore
+}
+
+func newSchemaChangeMigration(version uint64) *SchemaChangeMigration {
+	return &SchemaChangeMigration{
+		DummyMigration: *migration.NewDummyMigration(version),
+		changes: []online.SchemaChange{
+			{Table: "users", Alter: "ADD COLUMN age INT"},
+			{Table: "orders", Alter: "ADD INDEX idx_user (user_id)"},
+		},
+	}
+}
+
+func (suite *OscTestSuite) TestItDelegatesSchemaChangesAndPollsForCompletion() {
+	mig := newSchemaChangeMigration(1)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	repo := &oscRepository{}
+	executor := &fakeExecutor{runningFor: 2}
+	var slept []time.Duration
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSchemaChangeExecutor(executor, time.Second),
+	)
+	handler.wait = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(mig.changes, executor.submitted)
+	suite.Assert().Equal(map[string]int{"job-1": 3, "job-2": 3}, executor.checks)
+	suite.Assert().Len(slept, 4)
+	suite.Assert().Equal(time.Second, slept[0])
+	suite.Assert().Equal(1, mig.upCalls)
+	suite.Assert().True(repo.PersistedExecutions[0].Finished())
+
+	progress, _ := repo.LoadProgress("osc:1:1")
+	suite.Assert().Equal(&execution.Progress{Key: "osc:1:1", Token: "job-2", Done: true}, progress)
+}
+
+func (suite *OscTestSuite) TestItResumesSubmittedJobsInsteadOfSubmittingThemAgain() {
+	mig := newSchemaChangeMigration(1)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	repo := &oscRepository{}
+	_ = repo.SaveProgress(execution.Progress{Key: "osc:1:0", Token: "job-0", Done: true})
+	_ = repo.SaveProgress(execution.Progress{Key: "osc:1:1", Token: "job-7"})
+	executor := &fakeExecutor{}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSchemaChangeExecutor(executor, time.Second),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(executor.submitted)
+	suite.Assert().Equal(map[string]int{"job-7": 1}, executor.checks)
+	suite.Assert().Equal(1, mig.upCalls)
+}
+
+func (suite *OscTestSuite) TestItFailsMigrationsWhoseSchemaChangesFailed() {
+	mig := newSchemaChangeMigration(1)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	repo := &oscRepository{}
+	executor := &fakeExecutor{failWith: "cut-over timed out"}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSchemaChangeExecutor(executor, time.Second),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	var failed *MigrationFailedError
+	suite.Assert().ErrorAs(err, &failed)
+	suite.Assert().Equal(uint64(1), failed.Version)
+	suite.Assert().ErrorContains(err, "cut-over timed out")
+	suite.Assert().Equal(0, mig.upCalls)
+
+	// The failed job is forgotten, so the next run submits the change again
+	progress, _ := repo.LoadProgress("osc:1:0")
+	suite.Assert().Equal(&execution.Progress{Key: "osc:1:0"}, progress)
+}
+
+func (suite *OscTestSuite) TestItFailsSchemaChangeMigrationsWithoutExecutor() {
+	mig := newSchemaChangeMigration(1)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	handler, _ := NewHandler(registry, &oscRepository{}, nil)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().ErrorContains(err, "no schema change executor is set")
+	suite.Assert().Equal(0, mig.upCalls)
+}
+
+func (suite *OscTestSuite) TestItStopsPollingAtTheSchemaChangeTimeout() {
+	mig := newSchemaChangeMigration(1)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	repo := &oscRepository{}
+	executor := &fakeExecutor{runningFor: 1000}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSchemaChangeExecutor(executor, time.Hour),
+		WithSchemaChangeTimeout(time.Millisecond),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+
+	suite.Assert().ErrorIs(err, context.DeadlineExceeded)
+	suite.Assert().ErrorContains(err, "stopped polling job job-1")
+	suite.Assert().Equal(0, mig.upCalls)
+
+	// The job is kept, so the next run resumes polling it
+	progress, _ := repo.LoadProgress("osc:1:0")
+	suite.Assert().Equal(&execution.Progress{Key: "osc:1:0", Token: "job-1"}, progress)
+}
+
+func (suite *OscTestSuite) TestItClearsTheSchemaChangesWhenRollingBack() {
+	mig := newSchemaChangeMigration(1)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	repo := &oscRepository{}
+	executor := &fakeExecutor{}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSchemaChangeExecutor(executor, time.Second),
+	)
+
+	_, _ = handler.MigrateUp(NumOfRuns(1))
+	_, err := handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	progress, _ := repo.LoadProgress("osc:1:0")
+	suite.Assert().Equal(&execution.Progress{Key: "osc:1:0"}, progress)
+
+	_, _ = handler.MigrateUp(NumOfRuns(1))
+	_, err = handler.ForceDown(1)
+	suite.Assert().NoError(err)
+	progress, _ = repo.LoadProgress("osc:1:1")
+	suite.Assert().Equal(&execution.Progress{Key: "osc:1:1"}, progress)
+
+	_, _ = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().Len(executor.submitted, 6)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+var (
+	// ErrPreflightRepositoryRead is returned when the executions repository can not be read
+	ErrPreflightRepositoryRead = errors.New("pre-flight check failed, repository is not readable")
+
+	// ErrPreflightRepositoryWrite is returned when the executions repository can not be written
+	ErrPreflightRepositoryWrite = errors.New(
+		"pre-flight check failed, repository is not writable",
+	)
+
+	// ErrPreflightMigrationDb is returned when a database used by the migrations is not
+	// reachable
+	ErrPreflightMigrationDb = errors.New(
+		"pre-flight check failed, migrations database is not reachable",
+	)
+)
+
+// Pinger Must check that a database connection works. *sql.DB implements it.
+type Pinger interface {
+	Ping() error
+}
+
+// WithPreflightChecks Enables running Preflight before each run (MigrateUp, MigrateDown,
+// ForceUp, ForceDown), so connectivity and permission problems are reported before any
+// migration is executed, instead of halfway through a run. migrationDbs are the database
+// handles used by the migrations.
+func WithPreflightChecks(migrationDbs ...Pinger) Option {
+	return func(handler *MigrationsHandler) {
+		handler.preflight = true
+		handler.migrationDbs = migrationDbs
+	}
+}
+
+// Preflight Checks, without changing anything, that the executions repository can be read and
+// written and that the migration databases configured via WithPreflightChecks are reachable.
+// Each problem is reported with a distinct error: ErrPreflightRepositoryRead,
+// ErrPreflightRepositoryWrite or ErrPreflightMigrationDb. The write check is done only if the
+// repository implements execution.AccessChecker.
+func (handler *MigrationsHandler) Preflight() error {
+	checker, canCheck := handler.repository.(execution.AccessChecker)
+
+	var err error
+	if canCheck {
+		err = checker.CheckRead()
+	} else {
+		_, err = handler.repository.FindOne(0)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPreflightRepositoryRead, err)
+	}
+
+	if canCheck {
+		if err = checker.CheckWrite(); err != nil {
+			return fmt.Errorf("%w: %w", ErrPreflightRepositoryWrite, err)
+		}
+	}
+
+	for _, db := range handler.migrationDbs {
+		if err = db.Ping(); err != nil {
+			return fmt.Errorf("%w: %w", ErrPreflightMigrationDb, err)
+		}
+	}
+
+	return nil
+}
+
+// runPreflight Runs Preflight, if pre-flight checks are enabled
+func (handler *MigrationsHandler) runPreflight() error {
+	if !handler.preflight {
+		return nil
+	}
+	return handler.Preflight()
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type PreflightTestSuite struct {
+	suite.Suite
+}
+
+func TestPreflightTestSuite(t *testing.T) {
+	suite.Run(t, new(PreflightTestSuite))
+}
+
+type AccessCheckingInMemoryRepository struct {
+	execution.InMemoryRepository
+	readErr  error
+	writeErr error
+}
+
+func (r *AccessCheckingInMemoryRepository) CheckRead() error {
+	return r.readErr
+}
+
+func (r *AccessCheckingInMemoryRepository) CheckWrite() error {
+	return r.writeErr
+}
+
+type FakePinger struct {
+	err error
+}
+
+func (f *FakePinger) Ping() error {
+	return f.err
+}
+
+func (suite *PreflightTestSuite) TestItReportsDistinctPreflightErrors() {
+	checkErr := errors.New("check err")
+	scenarios := map[string]struct {
+		repo        execution.Repository
+		pinger      *FakePinger
+		expectedErr error
+	}{
+		"all good": {&AccessCheckingInMemoryRepository{}, &FakePinger{}, nil},
+		"read fails": {
+			&AccessCheckingInMemoryRepository{readErr: checkErr},
+			&FakePinger{},
+			ErrPreflightRepositoryRead,
+		},
+		"read fails without access checker": {
+			&execution.InMemoryRepository{FindOneErr: checkErr},
+			&FakePinger{},
+			ErrPreflightRepositoryRead,
+		},
+		"write fails": {
+			&AccessCheckingInMemoryRepository{writeErr: checkErr},
+			&FakePinger{},
+			ErrPreflightRepositoryWrite,
+		},
+		"migration db fails": {
+			&AccessCheckingInMemoryRepository{},
+			&FakePinger{err: checkErr},
+			ErrPreflightMigrationDb,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		mig := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}
+		registry := migration.NewGenericRegistry()
+		_ = registry.Register(mig)
+		handler, _ := NewHandler(
+			registry, scenario.repo, nil, WithPreflightChecks(scenario.pinger),
+		)
+
+		_, err := handler.MigrateUp(NumOfRuns(1))
+
+		if scenario.expectedErr == nil {
+			suite.Assert().NoError(err, "failed scenario %s", name)
+			suite.Assert().True(mig.upRan, "failed scenario %s", name)
+		} else {
+			suite.Assert().ErrorIs(err, scenario.expectedErr, "failed scenario %s", name)
+			suite.Assert().ErrorIs(err, checkErr, "failed scenario %s", name)
+			suite.Assert().False(mig.upRan, "failed scenario %s", name)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrEnvironmentBehind is returned when migrations can not be archived because an environment
+// did not execute all of them
+var ErrEnvironmentBehind = errors.New("environment did not execute all migrations to archive")
+
+// EnvironmentState The executions state of an environment (see ExportState)
+type EnvironmentState struct {
+	Name       string
+	Executions []execution.MigrationExecution
+}
+
+// ExportState Writes all persisted executions, as JSON, to w. The export of each environment
+// can be used to check, via Prune, that all environments are past a version.
+func (handler *MigrationsHandler) ExportState(w io.Writer) error {
+	executions, err := handler.repository.LoadExecutions()
+	if err != nil {
+		return fmt.Errorf("failed to export state, failed to load executions with error: %w", err)
+	}
+
+	return execution.EncodeExecutions(w, executions)
+}
+
+// Prune Archives the files of all registered migrations with a version lower or equal to the
+// provided version: after checking that all provided environments executed them, the files are
+// moved from the migrations directory into the archive directory (keeping their relative
+// path) and the version is recorded as baseline (see migration.WriteBaseline and WithBaseline).
+// The archived migrations must also be removed from the registry. The handler is not changed,
+// the recorded baseline is used by the handlers built afterwards (see migration.ReadBaseline).
+// The archive directory should be outside the Go module (or the archived files excluded from
+// the build), as they are not part of the migrations package anymore.
+// Returns the archived files, relative to the migrations directory.
+func (handler *MigrationsHandler) Prune(
+	dirPath migration.MigrationsDirPath,
+	archiveDir string,
+	version uint64,
+	environments []EnvironmentState,
+) ([]string, error) {
+	errMsg := fmt.Sprintf("failed to archive migrations up to version %d", version)
+
+	if handler.registry.Get(version) == nil {
+		return nil, fmt.Errorf("%s, the version is not a registered migration", errMsg)
+	}
+
+	var toArchive []migration.Migration
+	for _, mig := range handler.registry.OrderedMigrations() {
+		if mig.Version() > handler.baseline && mig.Version() <= version {
+			toArchive = append(toArchive, mig)
+		}
+	}
+
+	for _, env := range environments {
+		finished := make(map[uint64]bool)
+		for _, exec := range env.Executions {
+			finished[exec.Version] = exec.Finished()
+		}
+
+		for _, mig := range toArchive {
+			if !finished[mig.Version()] {
+				return nil, fmt.Errorf(
+					"%s, %w: %s did not execute migration %d",
+					errMsg, ErrEnvironmentBehind, env.Name, mig.Version(),
+				)
+			}
+		}
+	}
+
+	var archived []string
+	for _, mig := range toArchive {
+		filePath, err := migration.FindFile(dirPath, mig.Version())
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return archived, fmt.Errorf("%s, failed to find migration file: %w", errMsg, err)
+		}
+
+		relPath, err := filepath.Rel(string(dirPath), filePath)
+		if err == nil {
+			err = moveFile(filePath, filepath.Join(archiveDir, relPath))
+		}
+
+		if err != nil {
+			return archived, fmt.Errorf(
+				"%s, failed to archive migration %d with error: %w", errMsg, mig.Version(), err,
+			)
+		}
+
+		archived = append(archived, relPath)
+	}
+
+	if err := migration.WriteBaseline(dirPath, version); err != nil {
+		return archived, fmt.Errorf("%s, failed to record baseline with error: %w", errMsg, err)
+	}
+
+	return archived, nil
+}
+
+func moveFile(source string, destination string) error {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(destination); err == nil {
+		return fmt.Errorf("%s already exists", destination)
+	}
+
+	return os.Rename(source, destination)
+}
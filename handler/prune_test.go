@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type PruneTestSuite struct {
+	suite.Suite
+}
+
+func TestPruneTestSuite(t *testing.T) {
+	suite.Run(t, new(PruneTestSuite))
+}
+
+func finishedExecutions(versions ...uint64) []execution.MigrationExecution {
+	var executions []execution.MigrationExecution
+	for _, version := range versions {
+		executions = append(
+			executions,
+			execution.MigrationExecution{Version: version, ExecutedAtMs: 1, FinishedAtMs: 1},
+		)
+	}
+	return executions
+}
+
+func (suite *PruneTestSuite) newRegistry(
+	migDir migration.MigrationsDirPath,
+) migration.MigrationsRegistry {
+	registry := migration.NewGenericRegistry()
+	for _, version := range []uint64{1, 2, 3} {
+		_ = registry.Register(migration.NewDummyMigration(version))
+		_ = os.WriteFile(
+			filepath.Join(string(migDir), migration.FileName(version)), []byte("go"), 0600,
+		)
+	}
+	return registry
+}
+
+func (suite *PruneTestSuite) TestItCanExportState() {
+	executions := finishedExecutions(1, 2)
+	repo := &execution.InMemoryRepository{PersistedExecutions: executions}
+	handler, _ := NewHandler(migration.NewGenericRegistry(), repo, nil)
+	var buffer bytes.Buffer
+
+	err := handler.ExportState(&buffer)
+
+	suite.Assert().NoError(err)
+	decoded, _ := execution.DecodeExecutions(&buffer)
+	suite.Assert().Equal(executions, decoded)
+}
+
+func (suite *PruneTestSuite) TestItCanArchiveMigrationsExecutedInAllEnvironments() {
+	migDir, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	archiveDir := suite.T().TempDir()
+	registry := suite.newRegistry(migDir)
+	repo := &execution.InMemoryRepository{PersistedExecutions: finishedExecutions(1, 2, 3)}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	archived, err := handler.Prune(
+		migDir,
+		archiveDir,
+		2,
+		[]EnvironmentState{
+			{"staging", finishedExecutions(1, 2, 3)},
+			{"production", finishedExecutions(1, 2)},
+		},
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]string{migration.FileName(1), migration.FileName(2)}, archived)
+	suite.Assert().FileExists(filepath.Join(archiveDir, migration.FileName(1)))
+	suite.Assert().NoFileExists(filepath.Join(string(migDir), migration.FileName(2)))
+	suite.Assert().FileExists(filepath.Join(string(migDir), migration.FileName(3)))
+
+	baseline, found, _ := migration.ReadBaseline(migDir)
+	suite.Assert().True(found)
+	suite.Assert().Equal(uint64(2), baseline)
+	suite.Assert().Equal(uint64(0), handler.baseline)
+
+	// After pruning, the archived migrations are removed from the registry, but their
+	// executions are still persisted
+	prunedRegistry := migration.NewGenericRegistry()
+	_ = prunedRegistry.Register(migration.NewDummyMigration(3))
+	_ = prunedRegistry.Register(migration.NewDummyMigration(4))
+	prunedHandler, _ := NewHandler(prunedRegistry, repo, nil, WithBaseline(baseline))
+
+	plan, err := prunedHandler.plan()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(uint64(4), plan.NextToExecute().Version())
+
+	_, err = NewPlan(prunedRegistry, repo)
+	suite.Assert().ErrorIs(err, ErrPlanInconsistent)
+}
+
+func (suite *PruneTestSuite) TestItFailsToArchiveWhenAnEnvironmentIsBehind() {
+	migDir, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	registry := suite.newRegistry(migDir)
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	archived, err := handler.Prune(
+		migDir,
+		suite.T().TempDir(),
+		2,
+		[]EnvironmentState{
+			{"staging", finishedExecutions(1, 2)},
+			{"production", finishedExecutions(1)},
+		},
+	)
+
+	suite.Assert().ErrorIs(err, ErrEnvironmentBehind)
+	suite.Assert().ErrorContains(err, "production")
+	suite.Assert().Empty(archived)
+	suite.Assert().FileExists(filepath.Join(string(migDir), migration.FileName(1)))
+
+	_, found, _ := migration.ReadBaseline(migDir)
+	suite.Assert().False(found)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WithReadOnly Makes the handler read-only: informational operations (status, plan, validate)
+// work, but operations which change the executions (up, down, force:up, force:down, fresh,
+// state adoption) fail with execution.ErrReadOnly before running any migration. The repository
+// is wrapped with execution.NewReadOnlyRepository and is not initialized, so the handler can be
+// used with reduced-privilege credentials. The repository read capabilities (see
+// execution.SummaryReader and execution.LockInspector) are still used. Repositories with read
+// replicas (see execution.ReplicaReader) serve all reads, including the read capabilities, from
+// the replica repository.
+func WithReadOnly() Option {
+	return func(handler *MigrationsHandler) {
+		handler.readOnly = true
+	}
+}
+
+// NewReadOnlyHandler Builds a read-only handler (see WithReadOnly), which never initializes the
+// repository nor takes locks, so informational operations (status, plan, validate) work with
+// read-only credentials and while another process is running migrations
+func NewReadOnlyHandler(
+	registry migration.MigrationsRegistry,
+	repository execution.Repository,
+	newExecutionPlan ExecutionPlanBuilder,
+	options ...Option,
+) (*MigrationsHandler, error) {
+	return NewHandler(
+		registry, repository, newExecutionPlan, append(slices.Clone(options), WithReadOnly())...,
+	)
+}
+
+// checkWritable Checks if the operation, which changes the executions, is allowed
+func (handler *MigrationsHandler) checkWritable(operation string) error {
+	if handler.readOnly {
+		return fmt.Errorf("%w: %s is not allowed", execution.ErrReadOnly, operation)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReadOnlyTestSuite struct {
+	suite.Suite
+}
+
+func TestReadOnlyTestSuite(t *testing.T) {
+	suite.Run(t, new(ReadOnlyTestSuite))
+}
+
+func (suite *ReadOnlyTestSuite) TestItAllowsInformationalOperations() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{
+		InitErr: errors.New("CREATE command denied"),
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+		},
+	}
+
+	handler, err := NewHandler(registry, repo, nil, WithReadOnly())
+	suite.Require().NoError(err)
+
+	summary, err := handler.Summary()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(1, summary.PendingCount())
+
+	plan, err := handler.Plan()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(uint64(2), plan.NextToExecute().Version())
+
+	_, err = handler.Validate(nil)
+	suite.Assert().NoError(err)
+}
+
+func (suite *ReadOnlyTestSuite) TestItRejectsChangesBeforeRunningMigrations() {
+	mig1 := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	mig2 := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(2)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig1)
+	_ = registry.Register(mig2)
+	executions := []execution.MigrationExecution{{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3}}
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: append([]execution.MigrationExecution{}, executions...),
+	}
+	handler, _ := NewHandler(registry, repo, nil, WithReadOnly())
+
+	_, err := handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+	_, err = handler.ForceUp(2)
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+	_, err = handler.ForceDown(1)
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+	_, err = handler.FastForwardFromSnapshot(nil, nil)
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+	_, err = handler.AdoptState(&FakeStateImporter{})
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+
+	suite.Assert().False(mig1.downRan)
+	suite.Assert().False(mig2.upRan)
+	suite.Assert().Equal(executions, repo.PersistedExecutions)
+}
+
+func (suite *ReadOnlyTestSuite) TestItBuildsReadOnlyHandlersWhichKeepTheReadCapabilities() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &SummaryInMemoryRepository{
+		InMemoryRepository: execution.InMemoryRepository{
+			InitErr: errors.New("CREATE command denied"),
+			PersistedExecutions: []execution.MigrationExecution{
+				{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+			},
+		},
+	}
+
+	handler, err := NewReadOnlyHandler(registry, repo, nil)
+	suite.Require().NoError(err)
+
+	summary, err := handler.Summary()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(1, summary.FinishedCount)
+	suite.Assert().Equal(0, repo.loadCalls)
+
+	_, err = handler.MigrateDown(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+}
+
+type replicaInMemoryRepository struct {
+	execution.InMemoryRepository
+	replica execution.Repository
+}
+
+func (repo *replicaInMemoryRepository) ReplicaRepository() execution.Repository {
+	return repo.replica
+}
+
+func (suite *ReadOnlyTestSuite) TestItReadsFromTheReplicaOnlyWhenReadOnly() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &replicaInMemoryRepository{
+		InMemoryRepository: execution.InMemoryRepository{
+			PersistedExecutions: []execution.MigrationExecution{
+				{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+			},
+		},
+		// Lagging behind the primary
+		replica: &execution.InMemoryRepository{},
+	}
+
+	readOnly, _ := NewReadOnlyHandler(registry, repo, nil)
+	plan, err := readOnly.Plan()
+	suite.Require().NoError(err)
+	suite.Assert().Equal(uint64(1), plan.NextToExecute().Version())
+
+	writable, _ := NewHandler(registry, repo, nil)
+	executed, err := writable.MigrateUp(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Require().Len(executed, 1)
+	suite.Assert().Equal(uint64(2), executed[0].Migration.Version())
+}
+
+func (suite *ReadOnlyTestSuite) TestItUsesTheReplicaReadCapabilitiesWhenReadOnly() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	replica := &SummaryInMemoryRepository{}
+	repo := &replicaInMemoryRepository{
+		InMemoryRepository: execution.InMemoryRepository{
+			PersistedExecutions: []execution.MigrationExecution{
+				{Version: 1, ExecutedAtMs: 2, FinishedAtMs: 3},
+			},
+		},
+		// Lagging behind the primary
+		replica: replica,
+	}
+
+	readOnly, _ := NewReadOnlyHandler(registry, repo, nil)
+	summary, err := readOnly.Summary()
+
+	suite.Require().NoError(err)
+	suite.Assert().Equal(0, summary.FinishedCount)
+	suite.Assert().Equal(uint64(1), summary.NextToExecute.Version())
+	suite.Assert().Equal(0, replica.loadCalls)
+}
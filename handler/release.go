@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WithReleases Sets the releases of the migrations which do not declare their release (see
+// migration.Releaser and migration.ReadReleases), so they can be staged per release
+func WithReleases(releases migration.Releases) Option {
+	return func(handler *MigrationsHandler) {
+		handler.releases = releases
+	}
+}
+
+// ReleaseSummary The state of the migrations which belong to a release
+type ReleaseSummary struct {
+	Release  string
+	Executed int
+	Pending  int
+}
+
+// ReleaseSummaries Returns the state of the migrations grouped per release, in the order of
+// their first migration. Migrations which do not belong to a release are grouped under an empty
+// release.
+func (handler *MigrationsHandler) ReleaseSummaries() ([]ReleaseSummary, error) {
+	plan, err := handler.plan()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to summarize releases, failed to create execution plan with error: %w", err,
+		)
+	}
+
+	return handler.releaseSummariesOf(plan), nil
+}
+
+// releaseSummariesOf Returns the release summaries of the provided plan
+func (handler *MigrationsHandler) releaseSummariesOf(plan *ExecutionPlan) []ReleaseSummary {
+	var summaries []ReleaseSummary
+	positions := map[string]int{}
+	executedCount := plan.FinishedExecutionsCount()
+
+	for i, mig := range plan.orderedMigrations {
+		release := handler.releases.Of(mig)
+		position, found := positions[release]
+		if !found {
+			position = len(summaries)
+			positions[release] = position
+			summaries = append(summaries, ReleaseSummary{Release: release})
+		}
+
+		if i < executedCount {
+			summaries[position].Executed++
+		} else {
+			summaries[position].Pending++
+		}
+	}
+
+	return summaries
+}
+
+// UpToRelease Targets all migrations of the release, and all migrations ordered before them.
+// Fails with ErrInvalidTarget if no migration belongs to the release or if migrations of a
+// release which is not complete before it (for example, a later release) would be executed.
+func UpToRelease(release string) Target {
+	return func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error) {
+		bounds, err := handler.releaseBounds(plan, release)
+		if err != nil {
+			return 0, err
+		}
+
+		last := bounds[release].last
+		executedCount := plan.FinishedExecutionsCount()
+		for i := executedCount; i <= last; i++ {
+			other := handler.releases.Of(plan.orderedMigrations[i])
+			if other != "" && bounds[other].last > last {
+				return 0, fmt.Errorf(
+					"%w, migration %d, from release %q, is ordered before the last migration"+
+						" of release %q", ErrInvalidTarget, plan.orderedMigrations[i].Version(),
+					other, release,
+				)
+			}
+		}
+
+		return NumOfRuns(max(last+1-executedCount, 0)), nil
+	}
+}
+
+// DownToRelease Targets all migrations of the release, and all migrations executed after them,
+// for a rollback. Fails with ErrInvalidTarget if no migration belongs to the release or if
+// migrations of a release which starts before it (for example, an earlier release) would be
+// rolled back.
+func DownToRelease(release string) Target {
+	return func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error) {
+		bounds, err := handler.releaseBounds(plan, release)
+		if err != nil {
+			return 0, err
+		}
+
+		first := bounds[release].first
+		executedCount := len(plan.orderedExecutions)
+		for i := first; i < executedCount; i++ {
+			other := handler.releases.Of(plan.orderedMigrations[i])
+			if other != "" && bounds[other].first < first {
+				return 0, fmt.Errorf(
+					"%w, migration %d, from release %q, is ordered after the first migration"+
+						" of release %q", ErrInvalidTarget, plan.orderedMigrations[i].Version(),
+					other, release,
+				)
+			}
+		}
+
+		return NumOfRuns(max(executedCount-first, 0)), nil
+	}
+}
+
+// StepsUpToRelease Resolves the number of migrations MigrateUp must run so that all migrations
+// of the release, and all migrations ordered before them, are executed (see UpToRelease)
+func (handler *MigrationsHandler) StepsUpToRelease(release string) (NumOfRuns, error) {
+	return handler.ResolveTarget(UpToRelease(release))
+}
+
+// StepsDownToRelease Resolves the number of migrations MigrateDown must run so that all
+// migrations of the release, and all migrations executed after them, are rolled back (see
+// DownToRelease)
+func (handler *MigrationsHandler) StepsDownToRelease(release string) (NumOfRuns, error) {
+	return handler.ResolveTarget(DownToRelease(release))
+}
+
+// releaseBounds The positions, in the execution plan, of the first and the last migration of a
+// release
+type releaseBounds struct {
+	first int
+	last  int
+}
+
+// releaseBounds Returns the bounds of each release, in the execution plan. Fails with
+// ErrInvalidTarget if no migration belongs to the release.
+func (handler *MigrationsHandler) releaseBounds(
+	plan *ExecutionPlan,
+	release string,
+) (map[string]releaseBounds, error) {
+	bounds := map[string]releaseBounds{}
+	for i, mig := range plan.orderedMigrations {
+		current := handler.releases.Of(mig)
+		if existing, found := bounds[current]; found {
+			bounds[current] = releaseBounds{existing.first, i}
+		} else {
+			bounds[current] = releaseBounds{i, i}
+		}
+	}
+
+	if _, found := bounds[release]; release == "" || !found {
+		return nil, fmt.Errorf("%w, no migration belongs to release %q", ErrInvalidTarget, release)
+	}
+
+	return bounds, nil
+}
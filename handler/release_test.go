@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReleaseTestSuite struct {
+	suite.Suite
+}
+
+func TestReleaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ReleaseTestSuite))
+}
+
+// ReleasedMigration Migration which declares its release
+type ReleasedMigration struct {
+	migration.DummyMigration
+	release string
+}
+
+func (m *ReleasedMigration) Release() string {
+	return m.release
+}
+
+// newReleaseHandler Creates a handler with 6 registered migrations, of which the first
+// executedCount are executed. Migration 3 does not belong to a release and migration 5, from
+// 2024.07, is ordered between the migrations of 2024.06.
+func newReleaseHandler(executedCount uint64) *MigrationsHandler {
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 6; version++ {
+		if version == 5 {
+			_ = registry.Register(&ReleasedMigration{*migration.NewDummyMigration(5), "2024.07"})
+		} else {
+			_ = registry.Register(migration.NewDummyMigration(version))
+		}
+	}
+
+	repo := &execution.InMemoryRepository{}
+	for version := uint64(1); version <= executedCount; version++ {
+		_ = repo.Save(
+			execution.MigrationExecution{Version: version, ExecutedAtMs: 1, FinishedAtMs: 1},
+		)
+	}
+
+	handler, _ := NewHandler(
+		registry, repo, nil,
+		WithReleases(migration.Releases{1: "2024.05", 2: "2024.05", 4: "2024.06", 6: "2024.06"}),
+	)
+	return handler
+}
+
+func (suite *ReleaseTestSuite) TestItSummarizesTheMigrationsPerRelease() {
+	summaries, err := newReleaseHandler(3).ReleaseSummaries()
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]ReleaseSummary{
+			{"2024.05", 2, 0}, {"", 1, 0}, {"2024.06", 0, 2}, {"2024.07", 0, 1},
+		},
+		summaries,
+	)
+}
+
+func (suite *ReleaseTestSuite) TestItResolvesTheStepsToARelease() {
+	scenarios := map[string]struct {
+		stage         MigrationStage
+		executedCount uint64
+		release       string
+		expected      NumOfRuns
+		expectedErr   string
+	}{
+		"up to partially executed": {StageUp, 1, "2024.05", 1, ""},
+		"up to executed":           {StageUp, 2, "2024.05", 0, ""},
+		"up to interleaved":        {StageUp, 1, "2024.06", 5, ""},
+		"up to incomplete":         {StageUp, 1, "2024.07", 0, "migration 4, from release"},
+		"up to unknown":            {StageUp, 1, "2024.08", 0, "no migration belongs"},
+		"up to no release":         {StageUp, 1, "", 0, "no migration belongs"},
+		"down to first":            {StageDown, 6, "2024.05", 6, ""},
+		"down to interleaved":      {StageDown, 6, "2024.06", 3, ""},
+		"down to pending":          {StageDown, 2, "2024.06", 0, ""},
+		"down to incomplete":       {StageDown, 6, "2024.07", 0, "migration 6, from release"},
+		"down to unknown":          {StageDown, 6, "2024.08", 0, "no migration belongs"},
+	}
+
+	for name, scenario := range scenarios {
+		handler := newReleaseHandler(scenario.executedCount)
+		resolve := handler.StepsUpToRelease
+		if scenario.stage == StageDown {
+			resolve = handler.StepsDownToRelease
+		}
+
+		numOfRuns, err := resolve(scenario.release)
+
+		suite.Assert().Equal(scenario.expected, numOfRuns, "failed scenario: %s", name)
+		if scenario.expectedErr != "" {
+			suite.Assert().ErrorIs(err, ErrInvalidTarget, "failed scenario: %s", name)
+			suite.Assert().ErrorContains(err, scenario.expectedErr, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
+	}
+}
+
+func (suite *ReleaseTestSuite) TestItResolvesReleaseTargetsWhileHoldingTheRunLock() {
+	handler := newReleaseHandler(0)
+	repo := handler.repository.(*execution.InMemoryRepository)
+	locker := &racingLocker{
+		repo: repo,
+		executions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	WithLocker(locker)(handler)
+
+	report, err := handler.MigrateUpTo(UpToRelease("2024.05"))
+	suite.Assert().NoError(err)
+	suite.Require().Len(report.Migrations, 1)
+	suite.Assert().Equal(uint64(2), report.Migrations[0].Migration.Version())
+
+	locker.executions = []execution.MigrationExecution{
+		{Version: 3, ExecutedAtMs: 3, FinishedAtMs: 3},
+	}
+	report, err = handler.MigrateDownTo(DownToRelease("2024.05"))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(report.Migrations, 3)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
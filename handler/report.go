@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// Outcome What happened with a migration handled by a run
+type Outcome string
+
+const (
+	// OutcomeExecuted Up() or Down() was executed successfully
+	OutcomeExecuted Outcome = "executed"
+
+	// OutcomeSkipped The migration was recorded as executed without running it (see
+	// DecisionSkip and WithSkipList)
+	OutcomeSkipped Outcome = "skipped"
+
+	// OutcomeFailed Up() or Down(), or persisting the execution, failed
+	OutcomeFailed Outcome = "failed"
+)
+
+// MigrationReport Value object with the outcome of a migration handled by a run
+type MigrationReport struct {
+	ExecutedMigration
+	Outcome  Outcome
+	Duration time.Duration
+	Err      error
+	// Logs The messages logged by the migration (see migration.LoggerAware)
+	Logs []LogEntry
+	// WorkDir The work directory set for the migration (see migration.WorkDirAware), removed
+	// after the migration was handled, so operators can trace its intermediate files
+	WorkDir string
+	// WorkDirRemoveErr Why the work directory could not be removed, nil if it was removed
+	WorkDirRemoveErr error
+}
+
+// RunReport Value object which describes a MigrateUp or MigrateDown run. It is the single
+// source for everything which reports runs (CLI output, JSON output, notifications, metrics).
+type RunReport struct {
+	// BatchID Random identifier of the run
+	BatchID    string
+	Stage      MigrationStage
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	// Migrations The migrations handled by the run, in the order they were handled
+	Migrations []MigrationReport
+
+	// Warnings Problems which did not fail the run, for example, failed notifications (see
+	// WithNotifier)
+	Warnings []string
+}
+
+// newRunReport Starts a new run report, with a random batch id
+func newRunReport(stage MigrationStage, startedAt time.Time) *RunReport {
+	batchID := make([]byte, 8)
+	_, _ = rand.Read(batchID)
+
+	return &RunReport{BatchID: hex.EncodeToString(batchID), Stage: stage, StartedAt: startedAt}
+}
+
+// add Records a handled migration. The outcome is OutcomeFailed if err is not nil
+func (report *RunReport) add(
+	executed ExecutedMigration,
+	outcome Outcome,
+	duration time.Duration,
+	err error,
+	logs []LogEntry,
+	workDir WorkDir,
+) {
+	if err != nil {
+		outcome = OutcomeFailed
+	}
+
+	report.Migrations = append(
+		report.Migrations,
+		MigrationReport{
+			ExecutedMigration: executed,
+			Outcome:           outcome,
+			Duration:          duration,
+			Err:               err,
+			Logs:              logs,
+			WorkDir:           workDir.Path,
+			WorkDirRemoveErr:  workDir.RemoveErr,
+		},
+	)
+}
+
+// Executed Returns the handled migrations, as returned by MigrateUp and MigrateDown
+func (report *RunReport) Executed() []ExecutedMigration {
+	executed := make([]ExecutedMigration, 0, len(report.Migrations))
+	for _, migrationReport := range report.Migrations {
+		executed = append(executed, migrationReport.ExecutedMigration)
+	}
+	return executed
+}
+
+// Count Returns the number of handled migrations with the provided outcome
+func (report *RunReport) Count(outcome Outcome) int {
+	count := 0
+	for _, migrationReport := range report.Migrations {
+		if migrationReport.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// Duration Returns how long the run took
+func (report *RunReport) Duration() time.Duration {
+	return report.FinishedAt.Sub(report.StartedAt)
+}
+
+type jsonMigrationReport struct {
+	Version    *uint64                       `json:"version"`
+	Execution  *execution.MigrationExecution `json:"execution"`
+	Outcome    Outcome                       `json:"outcome"`
+	DurationMs int64                         `json:"durationMs"`
+	Error      *string                       `json:"error"`
+	Logs       []jsonLogEntry                `json:"logs,omitempty"`
+	WorkDir    string                        `json:"workDir,omitempty"`
+	// WorkDirRemoveError Set only if the work directory could not be removed
+	WorkDirRemoveError string `json:"workDirRemoveError,omitempty"`
+}
+
+type jsonLogEntry struct {
+	Time    string         `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+type jsonRunReport struct {
+	BatchID    string                `json:"batchId"`
+	Stage      MigrationStage        `json:"stage"`
+	StartedAt  string                `json:"startedAt"`
+	FinishedAt string                `json:"finishedAt"`
+	DurationMs int64                 `json:"durationMs"`
+	Migrations []jsonMigrationReport `json:"migrations"`
+	Warnings   []string              `json:"warnings,omitempty"`
+}
+
+// MarshalJSON Encodes the report, with execution.TimestampFormat timestamps and millisecond
+// durations. A migration's error is null if it did not fail and its logs (and work directory) are
+// omitted if it did not log any messages (or had no work directory, or its work directory was
+// removed). The warnings are omitted if there are none.
+func (report *RunReport) MarshalJSON() ([]byte, error) {
+	encoded := jsonRunReport{
+		BatchID:    report.BatchID,
+		Stage:      report.Stage,
+		StartedAt:  execution.FormatTimestampMs(uint64(max(report.StartedAt.UnixMilli(), 0))),
+		FinishedAt: execution.FormatTimestampMs(uint64(max(report.FinishedAt.UnixMilli(), 0))),
+		DurationMs: report.Duration().Milliseconds(),
+		Migrations: []jsonMigrationReport{},
+		Warnings:   report.Warnings,
+	}
+
+	for _, migrationReport := range report.Migrations {
+		encodedMigration := jsonMigrationReport{
+			Execution:  migrationReport.Execution,
+			Outcome:    migrationReport.Outcome,
+			DurationMs: migrationReport.Duration.Milliseconds(),
+			WorkDir:    migrationReport.WorkDir,
+		}
+
+		if migrationReport.Migration != nil {
+			version := migrationReport.Migration.Version()
+			encodedMigration.Version = &version
+		}
+
+		if migrationReport.Err != nil {
+			errMsg := migrationReport.Err.Error()
+			encodedMigration.Error = &errMsg
+		}
+
+		if migrationReport.WorkDirRemoveErr != nil {
+			encodedMigration.WorkDirRemoveError = migrationReport.WorkDirRemoveErr.Error()
+		}
+
+		for _, entry := range migrationReport.Logs {
+			encodedMigration.Logs = append(encodedMigration.Logs, encodeLogEntry(entry))
+		}
+
+		encoded.Migrations = append(encoded.Migrations, encodedMigration)
+	}
+
+	return json.Marshal(encoded)
+}
+
+// encodeLogEntry Converts the log entry to its JSON representation
+func encodeLogEntry(entry LogEntry) jsonLogEntry {
+	encoded := jsonLogEntry{
+		Time:    execution.FormatTimestampMs(uint64(max(entry.Time.UnixMilli(), 0))),
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+	}
+
+	if len(entry.Attrs) > 0 {
+		encoded.Attrs = make(map[string]any, len(entry.Attrs))
+		for _, attr := range entry.Attrs {
+			value := attr.Value.Resolve().Any()
+			if err, isErr := value.(error); isErr {
+				value = err.Error()
+			}
+			encoded.Attrs[attr.Key] = value
+		}
+	}
+
+	return encoded
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportTestSuite struct {
+	suite.Suite
+}
+
+func TestReportTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportTestSuite))
+}
+
+func (suite *ReportTestSuite) TestItReportsMigrateUpRuns() {
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	fixedClock := clock.NewFixed(now)
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&SlowMigration{*migration.NewDummyMigration(1), fixedClock, 2 * time.Second},
+	)
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(
+		&FailingMigration{*migration.NewDummyMigration(3), errors.New("up failed")},
+	)
+	repo := &execution.InMemoryRepository{}
+
+	handler, _ := NewHandler(
+		registry, repo, nil,
+		WithClock(fixedClock), WithSkipList(migration.SkipList{2: "superseded"}),
+	)
+	report, err := handler.MigrateUpWithReport(NumOfRuns(3))
+
+	suite.Assert().ErrorContains(err, "up failed")
+	suite.Assert().Len(report.BatchID, 16)
+	suite.Assert().Equal(StageUp, report.Stage)
+	suite.Assert().Equal(now, report.StartedAt)
+	suite.Assert().Equal(2*time.Second, report.Duration())
+	suite.Assert().Len(report.Migrations, 3)
+	suite.Assert().Equal(OutcomeExecuted, report.Migrations[0].Outcome)
+	suite.Assert().Equal(2*time.Second, report.Migrations[0].Duration)
+	suite.Assert().Equal(OutcomeSkipped, report.Migrations[1].Outcome)
+	suite.Assert().Equal(OutcomeFailed, report.Migrations[2].Outcome)
+	suite.Assert().ErrorContains(report.Migrations[2].Err, "up failed")
+	suite.Assert().Equal(1, report.Count(OutcomeExecuted))
+	suite.Assert().Equal(1, report.Count(OutcomeSkipped))
+	suite.Assert().Equal(1, report.Count(OutcomeFailed))
+
+	executed := report.Executed()
+	suite.Assert().Len(executed, 3)
+	suite.Assert().Equal(uint64(3), executed[2].Execution.Version)
+	suite.Assert().False(executed[2].Execution.Finished())
+}
+
+func (suite *ReportTestSuite) TestItReportsMigrateDownRuns() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&FailingMigration{*migration.NewDummyMigration(1), errors.New("down failed")},
+	)
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	repo.SaveAll(finishedExecutions(1, 2))
+
+	handler, _ := NewHandler(registry, repo, nil)
+	report, err := handler.MigrateDownWithReport(NumOfRuns(2))
+
+	suite.Assert().ErrorContains(err, "down failed")
+	suite.Assert().Equal(StageDown, report.Stage)
+	suite.Assert().Len(report.Migrations, 2)
+	suite.Assert().Equal(OutcomeExecuted, report.Migrations[0].Outcome)
+	suite.Assert().Equal(OutcomeFailed, report.Migrations[1].Outcome)
+	suite.Assert().Nil(report.Migrations[1].Execution)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+}
+
+func (suite *ReportTestSuite) TestItReturnsEmptyReportsForFailedRuns() {
+	handler, _ := NewHandler(
+		migration.NewGenericRegistry(), &execution.InMemoryRepository{LoadErr: errors.New("x")},
+		nil,
+	)
+	report, err := handler.MigrateDownWithReport(NumOfRuns(1))
+
+	suite.Assert().Error(err)
+	suite.Assert().Empty(report.Migrations)
+	suite.Assert().Equal([]ExecutedMigration{}, report.Executed())
+}
+
+func (suite *ReportTestSuite) TestItCanEncodeReportsAsJson() {
+	startedAt := time.Date(2024, 4, 12, 20, 18, 3, 0, time.UTC)
+	report := &RunReport{
+		BatchID:    "a1b2",
+		Stage:      StageUp,
+		StartedAt:  startedAt,
+		FinishedAt: startedAt.Add(1500 * time.Millisecond),
+		Migrations: []MigrationReport{
+			{
+				ExecutedMigration: ExecutedMigration{
+					migration.NewDummyMigration(1),
+					&execution.MigrationExecution{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 2},
+				},
+				Outcome:  OutcomeExecuted,
+				Duration: time.Second,
+			},
+			{
+				ExecutedMigration: ExecutedMigration{migration.NewDummyMigration(2), nil},
+				Outcome:           OutcomeFailed,
+				Err:               errors.New("down failed"),
+			},
+		},
+	}
+
+	encoded, err := json.Marshal(report)
+
+	suite.Assert().NoError(err)
+	suite.Assert().JSONEq(
+		`{"batchId":"a1b2","stage":"up","startedAt":"2024-04-12T20:18:03.000Z",`+
+			`"finishedAt":"2024-04-12T20:18:04.500Z","durationMs":1500,"migrations":[`+
+			`{"version":1,"execution":{"version":1,"executedAt":"1970-01-01T00:00:00.001Z",`+
+			`"finishedAt":"1970-01-01T00:00:00.002Z","finished":true,"executedAtMs":1,`+
+			`"finishedAtMs":2},"outcome":"executed","durationMs":1000,"error":null},`+
+			`{"version":2,"execution":null,"outcome":"failed","durationMs":0,`+
+			`"error":"down failed"}]}`,
+		string(encoded),
+	)
+}
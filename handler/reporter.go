@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// Failure A migration failure, with the context of the run which detected it
+type Failure struct {
+	*MigrationFailedError
+	// Environment The environment the handler runs against (see WithGuardrails), if set
+	Environment string
+	Run         execution.RunMetadata
+}
+
+// ErrorReporter Receives the migration failures (for example, to send them to an error
+// tracking service, see notify.SentryReporter), so the failures of unattended runs are not
+// missed
+type ErrorReporter interface {
+	ReportFailure(failure Failure) error
+}
+
+// WithErrorReporter Makes the handler report the migration failures (failed Validate(), Up(),
+// Down() calls or executions which could not be saved or removed) of all runs, including
+// forced runs and reversibility checks, to the reporter. Reporting failures do not stop the
+// run, they are joined with the run error.
+func WithErrorReporter(reporter ErrorReporter) Option {
+	return func(handler *MigrationsHandler) {
+		handler.errorReporters = append(handler.errorReporters, reporter)
+	}
+}
+
+// reportFailures Reports the migration failures wrapped by the run error, returning the run
+// error joined with the reporting failures
+func (handler *MigrationsHandler) reportFailures(runErr error) error {
+	if runErr == nil || len(handler.errorReporters) == 0 {
+		return runErr
+	}
+
+	err := runErr
+	for _, failed := range migrationFailures(runErr) {
+		failure := Failure{
+			MigrationFailedError: failed,
+			Environment:          handler.environment,
+			Run:                  handler.runMetadata,
+		}
+
+		for _, reporter := range handler.errorReporters {
+			if reportErr := reporter.ReportFailure(failure); reportErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("failed to report migration failure with error: %w", reportErr),
+				)
+			}
+		}
+	}
+
+	return err
+}
+
+// migrationFailures Returns all migration failures wrapped by err, including the ones joined
+// with errors.Join
+func migrationFailures(err error) []*MigrationFailedError {
+	if failed, isFailed := err.(*MigrationFailedError); isFailed {
+		return []*MigrationFailedError{failed}
+	}
+
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		if wrapped := wrapper.Unwrap(); wrapped != nil {
+			return migrationFailures(wrapped)
+		}
+	case interface{ Unwrap() []error }:
+		var failures []*MigrationFailedError
+		for _, wrapped := range wrapper.Unwrap() {
+			failures = append(failures, migrationFailures(wrapped)...)
+		}
+		return failures
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReporterTestSuite struct {
+	suite.Suite
+}
+
+func TestReporterTestSuite(t *testing.T) {
+	suite.Run(t, new(ReporterTestSuite))
+}
+
+type FakeErrorReporter struct {
+	failures []Failure
+	err      error
+}
+
+func (f *FakeErrorReporter) ReportFailure(failure Failure) error {
+	f.failures = append(f.failures, failure)
+	return f.err
+}
+
+func (suite *ReporterTestSuite) TestItReportsMigrationFailuresWithTheRunContext() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(
+		&FailingMigration{*migration.NewDummyMigration(2), errors.New("up failed")},
+	)
+	reporter := &FakeErrorReporter{}
+	metadata := execution.RunMetadata{DeployID: "deploy-1", GitSHA: "abc123"}
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithErrorReporter(reporter),
+		WithRunMetadata(metadata),
+		WithGuardrails("staging", Guardrails{}),
+	)
+
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(reporter.failures)
+
+	_, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().ErrorContains(err, "up failed")
+
+	suite.Require().Len(reporter.failures, 1)
+	failure := reporter.failures[0]
+	suite.Assert().Equal(uint64(2), failure.Version)
+	suite.Assert().Equal(StageUp, failure.Stage)
+	suite.Assert().Equal(StageUp, failure.Direction)
+	suite.Assert().EqualError(failure.Err, "up failed")
+	suite.Assert().NotEmpty(failure.Stack)
+	suite.Assert().Equal("staging", failure.Environment)
+	suite.Assert().Equal(metadata, failure.Run)
+}
+
+func (suite *ReporterTestSuite) TestItReportsForcedRunFailuresAndReportingFailures() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&FailingMigration{*migration.NewDummyMigration(1), errors.New("mig failed")},
+	)
+	reportErr := errors.New("tracker down")
+	reporter := &FakeErrorReporter{err: reportErr}
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil, WithErrorReporter(reporter))
+
+	_, err := handler.ForceUp(1)
+	suite.Assert().ErrorContains(err, "mig failed")
+	suite.Assert().ErrorIs(err, reportErr)
+	suite.Assert().ErrorContains(err, "failed to report migration failure")
+
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+	}
+	_, err = handler.ForceDown(1)
+	suite.Assert().ErrorIs(err, reportErr)
+
+	suite.Require().Len(reporter.failures, 2)
+	suite.Assert().Equal(StageUp, reporter.failures[0].Stage)
+	suite.Assert().Equal(StageDown, reporter.failures[1].Stage)
+}
+
+func (suite *ReporterTestSuite) TestItFindsAllJoinedMigrationFailures() {
+	first := &MigrationFailedError{Version: 1, Stage: StageValidate, Err: errors.New("invalid")}
+	second := &MigrationFailedError{Version: 2, Stage: StageValidate, Err: errors.New("invalid")}
+
+	failures := migrationFailures(
+		errors.Join(errors.New("other"), first, errors.Join(second)),
+	)
+
+	suite.Assert().Equal([]*MigrationFailedError{first, second}, failures)
+	suite.Assert().Empty(migrationFailures(errors.New("other")))
+}
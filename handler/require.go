@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchemaOutdated is returned when the executed migrations are older than what the
+// application requires
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+// DefaultRequirePollInterval The default interval between checks, when waiting for the
+// required migrations to be executed
+const DefaultRequirePollInterval = time.Second
+
+// RequireOptions Configures the wait/poll behaviour of RequireVersion and RequireUpToDate.
+// The zero value fails fast, without waiting.
+type RequireOptions struct {
+	// Wait The maximum duration to wait for the required migrations to be executed (for
+	// example, by a deploy job running in parallel). Zero means no waiting.
+	Wait time.Duration
+
+	// PollInterval The interval between checks while waiting. Defaults to
+	// DefaultRequirePollInterval
+	PollInterval time.Duration
+}
+
+// RequireVersion Can be called by applications on startup to make sure all registered
+// migrations up to (and including) the provided version have been executed. Errors with
+// ErrSchemaOutdated if that is not the case after the configured wait time, or with the
+// context's error if the context is done before that.
+func (handler *MigrationsHandler) RequireVersion(
+	ctx context.Context,
+	version uint64,
+	opts RequireOptions,
+) error {
+	return handler.require(
+		ctx, opts, func(summary Summary) error {
+			next := summary.NextToExecute
+			if next != nil && next.Version() <= version {
+				return fmt.Errorf(
+					"%w, required version %d, next migration to execute %d",
+					ErrSchemaOutdated, version, next.Version(),
+				)
+			}
+			return nil
+		},
+	)
+}
+
+// RequireUpToDate Same as RequireVersion, but requires all registered migrations to be executed
+func (handler *MigrationsHandler) RequireUpToDate(ctx context.Context, opts RequireOptions) error {
+	return handler.require(
+		ctx, opts, func(summary Summary) error {
+			if pending := summary.PendingCount(); pending > 0 {
+				return fmt.Errorf(
+					"%w, %d migrations are not executed yet", ErrSchemaOutdated, pending,
+				)
+			}
+			return nil
+		},
+	)
+}
+
+func (handler *MigrationsHandler) require(
+	ctx context.Context,
+	opts RequireOptions,
+	check func(summary Summary) error,
+) error {
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultRequirePollInterval
+	}
+	deadline := time.Now().Add(opts.Wait)
+
+	for {
+		summary, err := handler.Summary()
+		if err != nil {
+			return fmt.Errorf("failed to load migrations state with error: %w", err)
+		}
+
+		err = check(summary)
+		if err == nil || !time.Now().Before(deadline) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(min(pollInterval, time.Until(deadline))):
+		}
+	}
+}
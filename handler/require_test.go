@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequireTestSuite struct {
+	suite.Suite
+}
+
+func TestRequireTestSuite(t *testing.T) {
+	suite.Run(t, new(RequireTestSuite))
+}
+
+func (suite *RequireTestSuite) newHandler(
+	executions []execution.MigrationExecution,
+) (*MigrationsHandler, *execution.InMemoryRepository) {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+
+	repo := &execution.InMemoryRepository{}
+	repo.SaveAll(executions)
+	handler, _ := NewHandler(registry, repo, nil)
+	return handler, repo
+}
+
+func (suite *RequireTestSuite) TestItCanRequireVersion() {
+	handler, _ := suite.newHandler(
+		[]execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 2},
+			{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 0},
+		},
+	)
+	ctx := context.Background()
+
+	suite.Assert().NoError(handler.RequireVersion(ctx, 1, RequireOptions{}))
+	suite.Assert().ErrorIs(handler.RequireVersion(ctx, 2, RequireOptions{}), ErrSchemaOutdated)
+	suite.Assert().ErrorIs(handler.RequireUpToDate(ctx, RequireOptions{}), ErrSchemaOutdated)
+}
+
+func (suite *RequireTestSuite) TestItCanWaitForRequiredVersion() {
+	_, repo := suite.newHandler(
+		[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 2}},
+	)
+
+	checks := 0
+	planBuilder := func(
+		registry migration.MigrationsRegistry,
+		repository execution.Repository,
+	) (*ExecutionPlan, error) {
+		// Simulates a parallel process which executes the remaining migrations
+		if checks++; checks == 3 {
+			repo.SaveAll(
+				[]execution.MigrationExecution{
+					{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 2},
+					{Version: 3, ExecutedAtMs: 1, FinishedAtMs: 2},
+				},
+			)
+		}
+		return NewPlan(registry, repository)
+	}
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	handler, _ := NewHandler(registry, repo, planBuilder)
+
+	err := handler.RequireUpToDate(
+		context.Background(),
+		RequireOptions{Wait: time.Second, PollInterval: time.Millisecond},
+	)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(3, checks)
+}
+
+func (suite *RequireTestSuite) TestItStopsWaitingWhenContextIsDone() {
+	handler, _ := suite.newHandler(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := handler.RequireVersion(
+		ctx, 3, RequireOptions{Wait: time.Minute, PollInterval: 5 * time.Millisecond},
+	)
+
+	suite.Assert().ErrorIs(err, ErrSchemaOutdated)
+	suite.Assert().ErrorIs(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrNotReversible is returned (wrapped) by VerifyReversible when a migration fails the
+// Up, Down, Up round-trip
+var ErrNotReversible = errors.New("migration is not reversible")
+
+// ReversibilityResult The outcome of the Up, Down, Up round-trip of a migration. Err is nil if
+// the round-trip passed, otherwise it wraps MigrationFailedError, for the failed step.
+type ReversibilityResult struct {
+	Migration migration.Migration
+	Err       error
+}
+
+// Reversible Checks if the migration passed the round-trip
+func (result ReversibilityResult) Reversible() bool {
+	return result.Err == nil
+}
+
+// VerifyReversible Runs Up(), then Down() and Up() again for the next numOfRuns pending
+// migrations, in order, recording the migrations which passed the round-trip as executed. Meant
+// to be used against a disposable database (for example, in CI), to catch broken rollbacks
+// before release. Stops at the first migration which fails the round-trip, since the next
+// migrations may depend on it, and errors with ErrNotReversible. Migrations in the skip list are
+// recorded as executed, without running them (see WithSkipList).
+func (handler *MigrationsHandler) VerifyReversible(
+	numOfRuns NumOfRuns,
+) ([]ReversibilityResult, error) {
+	errMsg := "failed to verify reversibility"
+
+	if err := handler.checkWritable("verify:reversible"); err != nil {
+		return nil, fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	var results []ReversibilityResult
+	err := handler.withLock(
+		func() (err error) {
+			results, err = handler.verifyReversible(numOfRuns)
+			return err
+		},
+	)
+
+	if err != nil {
+		return results, handler.reportFailures(fmt.Errorf("%s, %w", errMsg, err))
+	}
+
+	return results, nil
+}
+
+// verifyReversible Runs the round-trip for the next numOfRuns pending migrations
+func (handler *MigrationsHandler) verifyReversible(
+	numOfRuns NumOfRuns,
+) ([]ReversibilityResult, error) {
+	if err := handler.guard("verify:reversible"); err != nil {
+		return nil, err
+	}
+
+	if err := handler.runPreflight(); err != nil {
+		return nil, err
+	}
+
+	plan, err := handler.plan()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create execution plan with error: %w", err)
+	}
+
+	toBeExecuted := plan.AllToBeExecuted()
+	toBeExecuted = toBeExecuted[:min(len(toBeExecuted), int(numOfRuns))]
+	handler.setContext(toBeExecuted...)
+	if err = validateMigrations(handler.withoutSkipped(toBeExecuted)); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	var results []ReversibilityResult
+	for _, mig := range toBeExecuted {
+		exec := handler.startExecution(mig)
+		skipReason, skipped := handler.skipReason(mig)
+		exec.SkipReason = skipReason
+
+		if !skipped {
+			result := ReversibilityResult{Migration: mig, Err: handler.roundTrip(mig)}
+			results = append(results, result)
+
+			if !result.Reversible() {
+				return results, fmt.Errorf(
+					"%w: migration %d, %w", ErrNotReversible, mig.Version(), result.Err,
+				)
+			}
+		}
+
+		exec.FinishExecutionAt(handler.clock.Now())
+		if err = handler.repository.Save(*exec); err != nil {
+			return results, fmt.Errorf(
+				"failed to save execution %d with error: %w", mig.Version(), err,
+			)
+		}
+	}
+
+	return results, nil
+}
+
+// roundTrip Runs Up(), Down() and Up() again for the migration, stopping at the first failure
+func (handler *MigrationsHandler) roundTrip(mig migration.Migration) error {
+	version := mig.Version()
+
+	up := func() error { return handler.up(mig) }
+
+	handler.scopeLogger(mig, StageUp)
+	_, err := handler.withWorkDir(mig, StageUp, up)
+	err = newMigrationFailed(version, StageUp, err)
+	if err == nil {
+		err = handler.waitForChanges(version, StageUp)
+	}
+	if err != nil {
+		return err
+	}
+
+	handler.scopeLogger(mig, StageDown)
+	_, err = handler.withWorkDir(mig, StageDown, mig.Down)
+	err = newMigrationFailed(version, StageDown, err)
+	if err == nil {
+		err = handler.waitForChanges(version, StageDown)
+	}
+	if err == nil {
+		err = handler.resetSchemaChanges(mig)
+	}
+	if err != nil {
+		return err
+	}
+
+	handler.scopeLogger(mig, StageUp)
+	_, err = handler.withWorkDir(mig, StageUp, up)
+	err = newMigrationFailed(version, StageUp, err)
+	if err == nil {
+		err = handler.waitForChanges(version, StageUp)
+	}
+	if err != nil {
+		return fmt.Errorf("up after down failed: %w", err)
+	}
+
+	return nil
+}
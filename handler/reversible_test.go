@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReversibleTestSuite struct {
+	suite.Suite
+}
+
+func TestReversibleTestSuite(t *testing.T) {
+	suite.Run(t, new(ReversibleTestSuite))
+}
+
+// FakeTableMigration Creates a table in the fake schema. Its Down() forgets to drop the table if
+// brokenDown is set
+type FakeTableMigration struct {
+	migration.DummyMigration
+	schema     map[string]bool
+	calls      *[]string
+	brokenDown bool
+}
+
+func (f *FakeTableMigration) table() string {
+	return fmt.Sprintf("table_%d", f.Version())
+}
+
+func (f *FakeTableMigration) Up() error {
+	*f.calls = append(*f.calls, fmt.Sprintf("up %d", f.Version()))
+	if f.schema[f.table()] {
+		return errors.New("table " + f.table() + " already exists")
+	}
+	f.schema[f.table()] = true
+	return nil
+}
+
+func (f *FakeTableMigration) Down() error {
+	*f.calls = append(*f.calls, fmt.Sprintf("down %d", f.Version()))
+	if !f.brokenDown {
+		delete(f.schema, f.table())
+	}
+	return nil
+}
+
+func (suite *ReversibleTestSuite) newMigration(
+	version uint64,
+	schema map[string]bool,
+	calls *[]string,
+) *FakeTableMigration {
+	return &FakeTableMigration{
+		DummyMigration: *migration.NewDummyMigration(version), schema: schema, calls: calls,
+	}
+}
+
+func (suite *ReversibleTestSuite) TestItRunsTheRoundTripForPendingMigrations() {
+	schema := map[string]bool{}
+	var calls []string
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(suite.newMigration(1, schema, &calls))
+	_ = registry.Register(suite.newMigration(2, schema, &calls))
+	_ = registry.Register(suite.newMigration(3, schema, &calls))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	results, err := handler.VerifyReversible(NumOfRuns(99999))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(results, 2)
+	for _, result := range results {
+		suite.Assert().True(result.Reversible())
+	}
+	suite.Assert().Equal(
+		[]string{"up 2", "down 2", "up 2", "up 3", "down 3", "up 3"}, calls,
+	)
+	suite.Assert().Equal(map[string]bool{"table_2": true, "table_3": true}, schema)
+
+	plan, _ := handler.Plan()
+	suite.Assert().Empty(plan.AllToBeExecuted())
+}
+
+func (suite *ReversibleTestSuite) TestItRunsTheRoundTripForTheGivenNumOfMigrations() {
+	schema := map[string]bool{}
+	var calls []string
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(suite.newMigration(1, schema, &calls))
+	_ = registry.Register(suite.newMigration(2, schema, &calls))
+	_ = registry.Register(suite.newMigration(3, schema, &calls))
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	results, err := handler.VerifyReversible(NumOfRuns(2))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(results, 2)
+	suite.Assert().Equal(
+		[]string{"up 1", "down 1", "up 1", "up 2", "down 2", "up 2"}, calls,
+	)
+
+	plan, _ := handler.Plan()
+	suite.Assert().Len(plan.AllToBeExecuted(), 1)
+}
+
+func (suite *ReversibleTestSuite) TestItReportsTheMigrationsWhichFailTheRoundTrip() {
+	schema := map[string]bool{}
+	var calls []string
+	broken := suite.newMigration(2, schema, &calls)
+	broken.brokenDown = true
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(suite.newMigration(1, schema, &calls))
+	_ = registry.Register(broken)
+	_ = registry.Register(suite.newMigration(3, schema, &calls))
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	results, err := handler.VerifyReversible(NumOfRuns(99999))
+
+	suite.Assert().ErrorIs(err, ErrNotReversible)
+	suite.Assert().Len(results, 2)
+	suite.Assert().True(results[0].Reversible())
+	suite.Assert().False(results[1].Reversible())
+	suite.Assert().Same(broken, results[1].Migration)
+	suite.Assert().ErrorContains(results[1].Err, "up after down failed")
+
+	var migrationErr *MigrationFailedError
+	suite.Assert().ErrorAs(results[1].Err, &migrationErr)
+	suite.Assert().Equal(uint64(2), migrationErr.Version)
+	suite.Assert().Equal(StageUp, migrationErr.Stage)
+
+	suite.Assert().NotContains(calls, "up 3")
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+	suite.Assert().Equal(uint64(1), repo.PersistedExecutions[0].Version)
+}
+
+func (suite *ReversibleTestSuite) TestItIsNotAllowedInProtectedOrReadOnlyMode() {
+	schema := map[string]bool{}
+	var calls []string
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(suite.newMigration(1, schema, &calls))
+	repo := &execution.InMemoryRepository{}
+
+	handler, _ := NewHandler(
+		registry, repo, nil,
+		WithGuardrails("production", Guardrails{Protected: []string{"production"}}),
+	)
+	_, err := handler.VerifyReversible(NumOfRuns(99999))
+	suite.Assert().ErrorIs(err, ErrProtectedEnvironment)
+
+	handler, _ = NewHandler(registry, repo, nil, WithReadOnly())
+	_, err = handler.VerifyReversible(NumOfRuns(99999))
+	suite.Assert().ErrorIs(err, execution.ErrReadOnly)
+
+	suite.Assert().Empty(calls)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ScheduleFileName The name of the file, from the migrations directory, which stores a deferred
+// run (see WriteScheduleFile and RunStoredSchedule)
+const ScheduleFileName = "migrations.schedule.json"
+
+// ErrScheduleMissed is returned (wrapped) when a scheduled run could not start before the end of
+// its window (see Schedule.Until)
+var ErrScheduleMissed = errors.New("the scheduled run window was missed")
+
+// Schedule A deferred MigrateUp run, for executing migrations during approved windows, without
+// a human at the keyboard
+type Schedule struct {
+	// At The time when the run starts
+	At time.Time
+
+	// Until If set, the end of the approved window: the run is not started after it
+	Until time.Time
+
+	Steps Steps
+}
+
+// checkWindow Returns ErrScheduleMissed if the run can not start at the time, because its
+// window ended
+func (schedule Schedule) checkWindow(now time.Time) error {
+	if !schedule.Until.IsZero() && now.After(schedule.Until) {
+		return fmt.Errorf(
+			"%w, it ended at %s", ErrScheduleMissed, schedule.Until.Format(time.RFC3339),
+		)
+	}
+	return nil
+}
+
+// scheduleFile The JSON format of the schedule file
+type scheduleFile struct {
+	At    string `json:"at"`
+	Until string `json:"until,omitempty"`
+	Steps string `json:"steps"`
+}
+
+// ParseScheduleTime Parses a schedule time, in the RFC 3339 format, with or without seconds (for
+// example, 2024-07-01T02:00Z or 2024-07-01T02:00:00+02:00)
+func ParseScheduleTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(
+		"invalid schedule time %q, expected the RFC 3339 format, for example 2024-07-01T02:00Z",
+		value,
+	)
+}
+
+// WaitUntil Blocks until the time, read from the handler clock (see WithClock), is reached or
+// the context is done, in which case the context error is returned
+func (handler *MigrationsHandler) WaitUntil(ctx context.Context, at time.Time) error {
+	if wait := at.Sub(handler.clock.Now()); wait > 0 {
+		return handler.wait(ctx, wait)
+	}
+	return ctx.Err()
+}
+
+// WaitForSchedule Waits until the scheduled time (see WaitUntil). If the schedule has a window
+// end and it passed by the time the run could start, ErrScheduleMissed is returned.
+func (handler *MigrationsHandler) WaitForSchedule(ctx context.Context, schedule Schedule) error {
+	if err := handler.WaitUntil(ctx, schedule.At); err != nil {
+		return err
+	}
+	return schedule.checkWindow(handler.clock.Now())
+}
+
+// MigrateUpAt Waits for the schedule (see WaitForSchedule) and then runs MigrateUpWithReport for
+// the scheduled steps. No migration is executed if the window of the schedule was missed.
+func (handler *MigrationsHandler) MigrateUpAt(
+	ctx context.Context,
+	schedule Schedule,
+) (*RunReport, error) {
+	if err := handler.WaitForSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return handler.MigrateUpWithReport(schedule.Steps.NumOfRuns())
+}
+
+// RunStoredSchedule Honors the schedule stored in the migrations directory (see
+// WriteScheduleFile), meant to be called by the runner executing migrations on application
+// startup. Blocks until the scheduled time (call it in a goroutine to not delay the startup),
+// then runs the migrations and removes the schedule, so it is executed once, even if the run
+// failed or its window was missed. If the context is done before the run, the schedule is kept.
+// Returns a nil report and no error if no schedule is stored.
+func (handler *MigrationsHandler) RunStoredSchedule(
+	ctx context.Context,
+	dirPath migration.MigrationsDirPath,
+) (*RunReport, error) {
+	schedule, found, err := ReadScheduleFile(dirPath)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	if err = handler.WaitUntil(ctx, schedule.At); err != nil {
+		return nil, err
+	}
+
+	report, err := handler.MigrateUpAt(ctx, schedule)
+	return report, errors.Join(err, RemoveScheduleFile(dirPath))
+}
+
+// WriteScheduleFile Stores the schedule in the migrations directory (see RunStoredSchedule),
+// replacing the previously stored schedule, if any
+func WriteScheduleFile(dirPath migration.MigrationsDirPath, schedule Schedule) error {
+	file := scheduleFile{At: schedule.At.Format(time.RFC3339), Steps: "all"}
+	if !schedule.Until.IsZero() {
+		file.Until = schedule.Until.Format(time.RFC3339)
+	}
+	if !schedule.Steps.All {
+		file.Steps = strconv.Itoa(schedule.Steps.N)
+	}
+
+	contents, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(
+		filepath.Join(string(dirPath), ScheduleFileName), append(contents, '\n'), 0644,
+	)
+}
+
+// ReadScheduleFile Reads the schedule stored in the migrations directory. found is false if no
+// schedule is stored.
+func ReadScheduleFile(
+	dirPath migration.MigrationsDirPath,
+) (schedule Schedule, found bool, err error) {
+	contents, err := os.ReadFile(filepath.Join(string(dirPath), ScheduleFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Schedule{}, false, nil
+	} else if err != nil {
+		return Schedule{}, false, err
+	}
+
+	var file scheduleFile
+	if err = json.Unmarshal(contents, &file); err != nil {
+		return Schedule{}, false, fmt.Errorf("invalid %s: %w", ScheduleFileName, err)
+	}
+
+	if schedule.At, err = ParseScheduleTime(file.At); err != nil {
+		return Schedule{}, false, fmt.Errorf("invalid %s: %w", ScheduleFileName, err)
+	}
+	if file.Until != "" {
+		if schedule.Until, err = ParseScheduleTime(file.Until); err != nil {
+			return Schedule{}, false, fmt.Errorf("invalid %s: %w", ScheduleFileName, err)
+		}
+	}
+	if schedule.Steps, err = ParseSteps(file.Steps); err != nil {
+		return Schedule{}, false, fmt.Errorf("invalid %s: %w", ScheduleFileName, err)
+	}
+
+	return schedule, true, nil
+}
+
+// RemoveScheduleFile Removes the schedule stored in the migrations directory, if any
+func RemoveScheduleFile(dirPath migration.MigrationsDirPath) error {
+	err := os.Remove(filepath.Join(string(dirPath), ScheduleFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// sleepContext Sleeps for the duration, or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
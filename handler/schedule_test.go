@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScheduleTestSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func TestScheduleTestSuite(t *testing.T) {
+	suite.Run(t, new(ScheduleTestSuite))
+}
+
+func (suite *ScheduleTestSuite) SetupTest() {
+	suite.now = time.Date(2024, 7, 1, 1, 0, 0, 0, time.UTC)
+}
+
+func (suite *ScheduleTestSuite) newHandler() (
+	*MigrationsHandler,
+	*execution.InMemoryRepository,
+	*[]time.Duration,
+) {
+	fixedClock := clock.NewFixed(suite.now)
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 3; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{}
+
+	handler, err := NewHandler(registry, repo, nil, WithClock(fixedClock))
+	suite.Require().NoError(err)
+
+	var waited []time.Duration
+	handler.wait = func(ctx context.Context, d time.Duration) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		waited = append(waited, d)
+		fixedClock.Advance(d)
+		return nil
+	}
+	return handler, repo, &waited
+}
+
+func (suite *ScheduleTestSuite) TestItParsesScheduleTimes() {
+	for value, expected := range map[string]time.Time{
+		"2024-07-01T02:00Z":         time.Date(2024, 7, 1, 2, 0, 0, 0, time.UTC),
+		"2024-07-01T02:00:30Z":      time.Date(2024, 7, 1, 2, 0, 30, 0, time.UTC),
+		"2024-07-01T04:00:00+02:00": time.Date(2024, 7, 1, 2, 0, 0, 0, time.UTC),
+	} {
+		parsed, err := ParseScheduleTime(value)
+		suite.Assert().NoError(err, value)
+		suite.Assert().True(expected.Equal(parsed), value)
+	}
+
+	_, err := ParseScheduleTime("2024-07-01 02:00")
+	suite.Assert().ErrorContains(err, "expected the RFC 3339 format")
+}
+
+func (suite *ScheduleTestSuite) TestItRunsMigrationsAtTheScheduledTime() {
+	handler, repo, waited := suite.newHandler()
+
+	report, err := handler.MigrateUpAt(context.Background(), Schedule{
+		At:    suite.now.Add(time.Hour),
+		Until: suite.now.Add(2 * time.Hour),
+		Steps: Steps{N: 2},
+	})
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]time.Duration{time.Hour}, *waited)
+	suite.Assert().Len(report.Executed(), 2)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().Equal(
+		suite.now.Add(time.Hour).UnixMilli(), int64(repo.PersistedExecutions[0].ExecutedAtMs),
+	)
+
+	*waited = nil
+	_, err = handler.MigrateUpAt(
+		context.Background(), Schedule{At: suite.now, Steps: Steps{All: true}},
+	)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(*waited)
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+}
+
+func (suite *ScheduleTestSuite) TestItDoesNotRunAfterTheWindowOrWhenCancelled() {
+	handler, repo, _ := suite.newHandler()
+
+	_, err := handler.MigrateUpAt(context.Background(), Schedule{
+		At:    suite.now.Add(-2 * time.Hour),
+		Until: suite.now.Add(-time.Hour),
+		Steps: Steps{All: true},
+	})
+	suite.Assert().ErrorIs(err, ErrScheduleMissed)
+	suite.Assert().ErrorContains(err, "it ended at 2024-07-01T00:00:00Z")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = handler.MigrateUpAt(ctx, Schedule{At: suite.now.Add(time.Hour), Steps: Steps{N: 1}})
+	suite.Assert().ErrorIs(err, context.Canceled)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
+
+func (suite *ScheduleTestSuite) TestItRunsTheStoredScheduleOnce() {
+	dirPath := migration.MigrationsDirPath(suite.T().TempDir())
+	handler, repo, waited := suite.newHandler()
+
+	report, err := handler.RunStoredSchedule(context.Background(), dirPath)
+	suite.Assert().NoError(err)
+	suite.Assert().Nil(report)
+
+	schedule := Schedule{
+		At:    suite.now.Add(30 * time.Minute),
+		Until: suite.now.Add(time.Hour),
+		Steps: Steps{N: 2},
+	}
+	suite.Require().NoError(WriteScheduleFile(dirPath, schedule))
+
+	stored, found, err := ReadScheduleFile(dirPath)
+	suite.Assert().NoError(err)
+	suite.Assert().True(found)
+	suite.Assert().True(schedule.At.Equal(stored.At))
+	suite.Assert().True(schedule.Until.Equal(stored.Until))
+	suite.Assert().Equal(schedule.Steps, stored.Steps)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = handler.RunStoredSchedule(ctx, dirPath)
+	suite.Assert().ErrorIs(err, context.Canceled)
+	_, found, _ = ReadScheduleFile(dirPath)
+	suite.Assert().True(found)
+
+	report, err = handler.RunStoredSchedule(context.Background(), dirPath)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(report.Executed(), 2)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().Equal([]time.Duration{30 * time.Minute}, *waited)
+
+	_, found, err = ReadScheduleFile(dirPath)
+	suite.Assert().NoError(err)
+	suite.Assert().False(found)
+}
+
+func (suite *ScheduleTestSuite) TestItRemovesMissedStoredSchedules() {
+	dirPath := migration.MigrationsDirPath(suite.T().TempDir())
+	handler, repo, _ := suite.newHandler()
+
+	suite.Require().NoError(WriteScheduleFile(dirPath, Schedule{
+		At:    suite.now.Add(-2 * time.Hour),
+		Until: suite.now.Add(-time.Hour),
+		Steps: Steps{All: true},
+	}))
+
+	_, err := handler.RunStoredSchedule(context.Background(), dirPath)
+	suite.Assert().ErrorIs(err, ErrScheduleMissed)
+	suite.Assert().Empty(repo.PersistedExecutions)
+	_, found, _ := ReadScheduleFile(dirPath)
+	suite.Assert().False(found)
+}
+
+func (suite *ScheduleTestSuite) TestItFailsToReadInvalidScheduleFiles() {
+	dirPath := suite.T().TempDir()
+	suite.Require().NoError(os.WriteFile(
+		filepath.Join(dirPath, ScheduleFileName), []byte(`{"at":"soon","steps":"all"}`), 0644,
+	))
+
+	_, found, err := ReadScheduleFile(migration.MigrationsDirPath(dirPath))
+	suite.Assert().False(found)
+	suite.Assert().ErrorContains(err, "invalid "+ScheduleFileName)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrMigrationNotRecordable is returned when a SQL script is requested for pending migrations
+// and at least one of them does not implement migration.SQLRecorder
+var ErrMigrationNotRecordable = errors.New("migration can not be rendered as sql")
+
+// ScriptUp Writes to w a single, ordered SQL script with the changes of all migrations that
+// are to be executed. Nothing is executed and no execution is persisted. All pending migrations
+// must implement migration.SQLRecorder, otherwise the script would be incomplete and
+// ErrMigrationNotRecordable is returned before anything is written. Migrations from the skip
+// list (see WithSkipList) are left out, they are never executed.
+// Returns the migrations included in the script.
+func (handler *MigrationsHandler) ScriptUp(w io.Writer) ([]migration.Migration, error) {
+	errMsg := "failed to generate sql script"
+
+	plan, err := handler.plan()
+	if err != nil {
+		return []migration.Migration{}, fmt.Errorf(
+			"%s, failed to create execution plan with error: %w", errMsg, err,
+		)
+	}
+
+	allToBeExec := handler.withoutSkipped(plan.AllToBeExecuted())
+	for _, mig := range allToBeExec {
+		if _, ok := mig.(migration.SQLRecorder); !ok {
+			return []migration.Migration{}, fmt.Errorf(
+				"%s, %w: %d", errMsg, ErrMigrationNotRecordable, mig.Version(),
+			)
+		}
+	}
+
+	var scripted []migration.Migration
+	for _, mig := range allToBeExec {
+		if _, err = fmt.Fprintf(w, "-- Migration version %d\n", mig.Version()); err != nil {
+			return scripted, fmt.Errorf("%s, write failed with error: %w", errMsg, err)
+		}
+
+		if err = mig.(migration.SQLRecorder).RecordSQL(w); err != nil {
+			return scripted, fmt.Errorf(
+				"%s, recording sql for migration %d failed with error: %w",
+				errMsg, mig.Version(), err,
+			)
+		}
+
+		if _, err = fmt.Fprint(w, "\n"); err != nil {
+			return scripted, fmt.Errorf("%s, write failed with error: %w", errMsg, err)
+		}
+
+		scripted = append(scripted, mig)
+	}
+
+	return scripted, nil
+}
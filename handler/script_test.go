@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScriptTestSuite struct {
+	suite.Suite
+}
+
+func TestScriptTestSuite(t *testing.T) {
+	suite.Run(t, new(ScriptTestSuite))
+}
+
+type FakeSQLMigration struct {
+	migration.DummyMigration
+	sql string
+}
+
+func (f *FakeSQLMigration) RecordSQL(w io.Writer) error {
+	_, err := io.WriteString(w, f.sql)
+	return err
+}
+
+func (suite *ScriptTestSuite) TestItCanScriptPendingMigrations() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(1), "SELECT 1;\n"})
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(2), "SELECT 2;\n"})
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(3), "SELECT 3;\n"})
+
+	repo := &execution.InMemoryRepository{}
+	repo.SaveAll([]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 2}})
+	handler, _ := NewHandler(registry, repo, nil)
+
+	var buffer bytes.Buffer
+	scripted, err := handler.ScriptUp(&buffer)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(scripted, 2)
+	suite.Assert().Equal(
+		"-- Migration version 2\nSELECT 2;\n\n-- Migration version 3\nSELECT 3;\n\n",
+		buffer.String(),
+	)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+}
+
+func (suite *ScriptTestSuite) TestItDoesNotScriptSkippedMigrations() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(1), "SELECT 1;\n"})
+	_ = registry.Register(migration.NewDummyMigration(2))
+
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSkipList(migration.SkipList{2: "superseded"}),
+	)
+
+	var buffer bytes.Buffer
+	scripted, err := handler.ScriptUp(&buffer)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(scripted, 1)
+	suite.Assert().Equal("-- Migration version 1\nSELECT 1;\n\n", buffer.String())
+}
+
+func (suite *ScriptTestSuite) TestItFailsToScriptWhenMigrationIsNotRecordable() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&FakeSQLMigration{*migration.NewDummyMigration(1), "SELECT 1;\n"})
+	_ = registry.Register(migration.NewDummyMigration(2))
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	var buffer bytes.Buffer
+	scripted, err := handler.ScriptUp(&buffer)
+
+	suite.Assert().ErrorIs(err, ErrMigrationNotRecordable)
+	suite.Assert().Empty(scripted)
+	suite.Assert().Empty(buffer.String())
+}
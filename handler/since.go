@@ -0,0 +1,29 @@
+package handler
+
+import "time"
+
+// MigrateDownSince Executes Down() for all migrations executed at or after the provided time
+// (based on the execution's ExecutedAtMs). Rollbacks follow the reverse version order, so, if
+// a migration with a greater version was executed before the provided time (for example via
+// ForceUp), it is rolled back also.
+func (handler *MigrationsHandler) MigrateDownSince(since time.Time) ([]ExecutedMigration, error) {
+	report, err := handler.MigrateDownTo(DownSince(since))
+	return report.Executed(), err
+}
+
+// DownSince Targets the migrations executed at or after the provided time, for a rollback (see
+// MigrateDownSince)
+func DownSince(since time.Time) Target {
+	return func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error) {
+		sinceMs := uint64(max(since.UnixMilli(), 0))
+		executed := plan.AllExecuted()
+
+		for i, execMig := range executed {
+			if execMig.Execution.ExecutedAtMs >= sinceMs {
+				return NumOfRuns(len(executed) - i), nil
+			}
+		}
+
+		return 0, nil
+	}
+}
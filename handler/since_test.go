@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type SinceTestSuite struct {
+	suite.Suite
+}
+
+func TestSinceTestSuite(t *testing.T) {
+	suite.Run(t, new(SinceTestSuite))
+}
+
+func (suite *SinceTestSuite) TestItCanMigrateDownExecutionsSinceTime() {
+	scenarios := map[string]struct {
+		sinceMs           int64
+		expectedRemaining []uint64
+	}{
+		"none since":          {5000, []uint64{1, 2, 3, 4}},
+		"last since":          {3000, []uint64{1, 2, 3}},
+		"greater version too": {2000, []uint64{1}},
+		"all since":           {0, nil},
+	}
+
+	for name, scenario := range scenarios {
+		registry := migration.NewGenericRegistry()
+		for version := uint64(1); version <= 4; version++ {
+			_ = registry.Register(migration.NewDummyMigration(version))
+		}
+		repo := &execution.InMemoryRepository{
+			PersistedExecutions: []execution.MigrationExecution{
+				{Version: 1, ExecutedAtMs: 1000, FinishedAtMs: 1000},
+				{Version: 2, ExecutedAtMs: 2000, FinishedAtMs: 2000},
+				{Version: 3, ExecutedAtMs: 1500, FinishedAtMs: 1500},
+				{Version: 4, ExecutedAtMs: 4000, FinishedAtMs: 4000},
+			},
+		}
+		handler, _ := NewHandler(registry, repo, nil)
+
+		_, err := handler.MigrateDownSince(time.UnixMilli(scenario.sinceMs))
+
+		var remaining []uint64
+		for _, exec := range repo.PersistedExecutions {
+			remaining = append(remaining, exec.Version)
+		}
+		suite.Assert().NoError(err, "failed scenario %s", name)
+		suite.Assert().Equal(scenario.expectedRemaining, remaining, "failed scenario %s", name)
+	}
+}
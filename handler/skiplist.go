@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WithSkipList Makes MigrateUp record the migrations from the skip list as executed, without
+// running (or validating) them. Their executions hold the skip reason (see
+// execution.MigrationExecution.Skipped). Used for known-bad migrations which were superseded,
+// but must remain in the history. Rolling back skipped executions (MigrateDown, ForceDown) only
+// removes them, without running Down().
+func WithSkipList(skipList migration.SkipList) Option {
+	return func(handler *MigrationsHandler) {
+		handler.skipList = skipList
+	}
+}
+
+// skipReason Returns the skip reason of the migration, if it is in the skip list
+func (handler *MigrationsHandler) skipReason(mig migration.Migration) (string, bool) {
+	reason, found := handler.skipList[mig.Version()]
+	return reason, found
+}
+
+// withoutSkipped Returns the migrations which are not in the skip list
+func (handler *MigrationsHandler) withoutSkipped(
+	migrations []migration.Migration,
+) []migration.Migration {
+	var result []migration.Migration
+	for _, mig := range migrations {
+		if _, skipped := handler.skipReason(mig); !skipped {
+			result = append(result, mig)
+		}
+	}
+	return result
+}
+
+// Skipped Returns the executed migrations which were recorded without running them, because
+// they were in the skip list, were skipped interactively (DecisionSkip) or failed with
+// WithContinueOnError
+func (handler *MigrationsHandler) Skipped() ([]ExecutedMigration, error) {
+	plan, err := handler.plan()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to load skipped migrations, failed to create execution plan with error: %w",
+			err,
+		)
+	}
+
+	return skippedOf(plan), nil
+}
+
+// skippedOf Returns the skipped executed migrations of the provided plan
+func skippedOf(plan *ExecutionPlan) []ExecutedMigration {
+	var skipped []ExecutedMigration
+	for _, executed := range plan.AllExecuted() {
+		if executed.Execution.Skipped() {
+			skipped = append(skipped, executed)
+		}
+	}
+	return skipped
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type SkipListTestSuite struct {
+	suite.Suite
+}
+
+func TestSkipListTestSuite(t *testing.T) {
+	suite.Run(t, new(SkipListTestSuite))
+}
+
+func (suite *SkipListTestSuite) TestItRecordsSkippedMigrationsWithoutRunningThem() {
+	registry := migration.NewGenericRegistry()
+	first := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	third := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(3)}
+	_ = registry.Register(first)
+	_ = registry.Register(
+		&FailingMigration{*migration.NewDummyMigration(2), errors.New("known bad")},
+	)
+	_ = registry.Register(third)
+	repo := &execution.InMemoryRepository{}
+
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSkipList(migration.SkipList{2: "superseded by 3"}),
+	)
+	executed, err := handler.MigrateUp(NumOfRuns(3))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(executed, 3)
+	suite.Assert().True(first.upRan)
+	suite.Assert().True(third.upRan)
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+	suite.Assert().Equal("superseded by 3", repo.PersistedExecutions[1].SkipReason)
+	suite.Assert().True(repo.PersistedExecutions[1].Finished())
+	suite.Assert().False(repo.PersistedExecutions[2].Skipped())
+
+	skipped, err := handler.Skipped()
+	suite.Assert().NoError(err)
+	suite.Assert().Len(skipped, 1)
+	suite.Assert().Equal(uint64(2), skipped[0].Migration.Version())
+}
+
+func (suite *SkipListTestSuite) TestItDoesNotValidateSkippedMigrations() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&ValidatingMigration{
+			FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)},
+			errors.New("missing table"),
+		},
+	)
+	repo := &execution.InMemoryRepository{}
+
+	handler, _ := NewHandler(registry, repo, nil)
+	_, err := handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().ErrorContains(err, "missing table")
+
+	handler, _ = NewHandler(registry, repo, nil, WithSkipList(migration.SkipList{1: "broken"}))
+	_, err = handler.MigrateUp(NumOfRuns(1))
+	suite.Assert().NoError(err)
+	suite.Assert().True(repo.PersistedExecutions[0].Skipped())
+}
+
+func (suite *SkipListTestSuite) TestItRemovesSkippedExecutionsWithoutRunningDown() {
+	registry := migration.NewGenericRegistry()
+	first := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	second := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(2)}
+	third := &FakeUpMigration{DummyMigration: *migration.NewDummyMigration(3)}
+	_ = registry.Register(first)
+	_ = registry.Register(second)
+	_ = registry.Register(third)
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1, SkipReason: "superseded by 3"},
+			{Version: 2, ExecutedAtMs: 2, FinishedAtMs: 2},
+			{Version: 3, ExecutedAtMs: 3, FinishedAtMs: 3, SkipReason: "broken"},
+		},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	report, err := handler.MigrateDownWithReport(NumOfRuns(2))
+
+	suite.Assert().NoError(err)
+	suite.Assert().False(third.downRan)
+	suite.Assert().True(second.downRan)
+	suite.Assert().Equal(1, report.Count(OutcomeSkipped))
+	suite.Assert().Equal(1, report.Count(OutcomeExecuted))
+
+	_, err = handler.ForceDown(1)
+
+	suite.Assert().NoError(err)
+	suite.Assert().False(first.downRan)
+	suite.Assert().Empty(repo.PersistedExecutions)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// Sorter Customizes the execution order of the registered migrations. It receives the
+// migrations ordered by version ascending and must return all of them, in the desired order.
+// The order must be stable across runs, since executions are checked against it.
+type Sorter func(migrations []migration.Migration) []migration.Migration
+
+// NewSortedPlan Creates an ExecutionPlanBuilder which orders the migrations with the provided
+// sorter, instead of by version. The executed migrations and the pending ones are sorted
+// separately, the executed ones first, so a migration registered after a run is never ordered
+// before the executed ones (even if the sorter would). The sorted migrations and the persisted
+// executions are checked for consistency, the same way as for NewPlan.
+func NewSortedPlan(sorter Sorter) ExecutionPlanBuilder {
+	return func(
+		registry migration.MigrationsRegistry,
+		repository execution.Repository,
+	) (*ExecutionPlan, error) {
+		executions, err := loadPlanExecutions(repository)
+		if err != nil {
+			return nil, err
+		}
+
+		executedVersions := make(map[uint64]bool, len(executions))
+		for _, exec := range executions {
+			executedVersions[exec.Version] = true
+		}
+
+		var executed, pending []migration.Migration
+		for _, mig := range registry.OrderedMigrations() {
+			if executedVersions[mig.Version()] {
+				executed = append(executed, mig)
+			} else {
+				pending = append(pending, mig)
+			}
+		}
+
+		var sorted []migration.Migration
+		for _, migrations := range [][]migration.Migration{executed, pending} {
+			if len(migrations) == 0 {
+				continue
+			}
+
+			sortedPart := sorter(slices.Clone(migrations))
+			if err = checkSorted(migrations, sortedPart); err != nil {
+				return nil, fmt.Errorf("failed to create new execution plan, %w", err)
+			}
+			sorted = append(sorted, sortedPart...)
+		}
+
+		positions := make(map[uint64]int, len(sorted))
+		for i, mig := range sorted {
+			positions[mig.Version()] = i
+		}
+
+		position := func(version uint64) int {
+			if i, found := positions[version]; found {
+				return i
+			}
+			return len(sorted)
+		}
+
+		return newPlan(
+			sorted,
+			executions,
+			func(a execution.MigrationExecution, b execution.MigrationExecution) bool {
+				if position(a.Version) != position(b.Version) {
+					return position(a.Version) < position(b.Version)
+				}
+				return a.Version < b.Version
+			},
+		)
+	}
+}
+
+// WithSorter Makes the handler order the migrations with the provided sorter (see
+// NewSortedPlan). Overrides the ExecutionPlanBuilder passed to NewHandler.
+func WithSorter(sorter Sorter) Option {
+	return func(handler *MigrationsHandler) {
+		handler.newExecutionPlan = NewSortedPlan(sorter)
+		handler.customPlan = true
+	}
+}
+
+// checkSorted Checks that the sorter returned exactly the registered migrations
+func checkSorted(migrations []migration.Migration, sorted []migration.Migration) error {
+	if len(sorted) != len(migrations) {
+		return fmt.Errorf(
+			"sorter returned %d migrations, expected %d", len(sorted), len(migrations),
+		)
+	}
+
+	seen := make(map[uint64]bool, len(sorted))
+	for _, mig := range sorted {
+		if mig == nil || seen[mig.Version()] {
+			return errors.New("sorter returned nil or duplicated migrations")
+		}
+		seen[mig.Version()] = true
+	}
+
+	for _, mig := range migrations {
+		if !seen[mig.Version()] {
+			return fmt.Errorf("sorter did not return migration %d", mig.Version())
+		}
+	}
+
+	return nil
+}
+
+// TagPrioritySorter Creates a Sorter which orders the migrations by their tags (see
+// migration.Tagger), in the provided tags order, for example "schema" migrations before
+// "data" migrations. Migrations without any of the tags are ordered last. Migrations with the
+// same priority are ordered by version.
+func TagPrioritySorter(tags ...string) Sorter {
+	priority := func(mig migration.Migration) int {
+		tagger, isTagger := mig.(migration.Tagger)
+		if !isTagger {
+			return len(tags)
+		}
+
+		migrationTags := tagger.Tags()
+		for i, tag := range tags {
+			if slices.Contains(migrationTags, tag) {
+				return i
+			}
+		}
+		return len(tags)
+	}
+
+	return func(migrations []migration.Migration) []migration.Migration {
+		slices.SortStableFunc(
+			migrations, func(a migration.Migration, b migration.Migration) int {
+				return priority(a) - priority(b)
+			},
+		)
+		return migrations
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type SorterTestSuite struct {
+	suite.Suite
+}
+
+func TestSorterTestSuite(t *testing.T) {
+	suite.Run(t, new(SorterTestSuite))
+}
+
+type TaggedMigration struct {
+	migration.DummyMigration
+	tags []string
+}
+
+func (mig *TaggedMigration) Tags() []string {
+	return mig.tags
+}
+
+func (suite *SorterTestSuite) newRegistry() *migration.GenericRegistry {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&TaggedMigration{*migration.NewDummyMigration(1), []string{"data"}})
+	_ = registry.Register(&TaggedMigration{*migration.NewDummyMigration(2), []string{"schema"}})
+	_ = registry.Register(migration.NewDummyMigration(3))
+	_ = registry.Register(&TaggedMigration{*migration.NewDummyMigration(4), []string{"schema"}})
+	return registry
+}
+
+func versions(migrations []migration.Migration) []uint64 {
+	var result []uint64
+	for _, mig := range migrations {
+		result = append(result, mig.Version())
+	}
+	return result
+}
+
+func (suite *SorterTestSuite) TestItOrdersMigrationsByTagPriority() {
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		suite.newRegistry(), repo, nil, WithSorter(TagPrioritySorter("schema", "data")),
+	)
+
+	plan, err := handler.plan()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{2, 4, 1, 3}, versions(plan.AllToBeExecuted()))
+
+	executed, err := handler.MigrateUp(NumOfRuns(3))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(executed, 3)
+	suite.Assert().Equal(uint64(1), executed[2].Migration.Version())
+
+	plan, err = handler.plan()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{3}, versions(plan.AllToBeExecuted()))
+	suite.Assert().Equal(uint64(1), plan.LastExecuted().Execution.Version)
+}
+
+func (suite *SorterTestSuite) TestItValidatesExecutionsAgainstSortedMigrations() {
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 5, ExecutedAtMs: 1, FinishedAtMs: 1},
+		},
+	}
+	planBuilder := NewSortedPlan(TagPrioritySorter("schema"))
+
+	_, err := planBuilder(suite.newRegistry(), repo)
+	suite.Assert().ErrorIs(err, ErrPlanInconsistent)
+	suite.Assert().ErrorContains(err, "execution 5 at index 0 does not match")
+
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 4, ExecutedAtMs: 1, FinishedAtMs: 1},
+		{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+	}
+	plan, err := planBuilder(suite.newRegistry(), repo)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{1, 3}, versions(plan.AllToBeExecuted()))
+}
+
+func (suite *SorterTestSuite) TestItDoesNotSortNewMigrationsBeforeExecutedOnes() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&TaggedMigration{*migration.NewDummyMigration(1), []string{"data"}})
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithSorter(TagPrioritySorter("schema", "data")),
+	)
+
+	_, err := handler.MigrateUp(AllRuns)
+	suite.Require().NoError(err)
+
+	// A new, higher priority migration is added after the run
+	_ = registry.Register(&TaggedMigration{*migration.NewDummyMigration(3), []string{"schema"}})
+	_ = registry.Register(&TaggedMigration{*migration.NewDummyMigration(4), []string{"data"}})
+
+	plan, err := handler.plan()
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]uint64{1, 2}, versions(plan.orderedMigrations[:2]))
+	suite.Assert().Equal([]uint64{3, 4}, versions(plan.AllToBeExecuted()))
+
+	executed, err := handler.MigrateUp(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(executed, 2)
+}
+
+func (suite *SorterTestSuite) TestItFailsIfSorterDoesNotReturnRegisteredMigrations() {
+	scenarios := map[string]struct {
+		sorter      Sorter
+		expectedErr string
+	}{
+		"missing": {
+			func(migrations []migration.Migration) []migration.Migration {
+				return migrations[1:]
+			},
+			"sorter returned 3 migrations, expected 4",
+		},
+		"duplicated": {
+			func(migrations []migration.Migration) []migration.Migration {
+				migrations[0] = migrations[1]
+				return migrations
+			},
+			"sorter returned nil or duplicated migrations",
+		},
+		"unknown": {
+			func(migrations []migration.Migration) []migration.Migration {
+				migrations[0] = migration.NewDummyMigration(5)
+				return migrations
+			},
+			"sorter did not return migration 1",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		_, err := NewSortedPlan(scenario.sorter)(
+			suite.newRegistry(), &execution.InMemoryRepository{},
+		)
+		suite.Assert().ErrorContains(err, scenario.expectedErr, "failed scenario %s", name)
+	}
+}
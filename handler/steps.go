@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSteps is returned (wrapped) when the number of migrations to run is not a positive
+// number or "all"
+var ErrInvalidSteps = errors.New("invalid number of migrations to run")
+
+// Steps The number of migrations to run, as requested by the user: all of them or N
+type Steps struct {
+	All bool
+	N   int
+}
+
+// ParseSteps Parses the number of migrations to run: "all" or a positive number. An empty value
+// means one migration. Other values (for example, 0, negative numbers or typos) are rejected
+// with ErrInvalidSteps, instead of being silently coerced.
+func ParseSteps(value string) (Steps, error) {
+	value = strings.TrimSpace(value)
+
+	switch value {
+	case "all":
+		return Steps{All: true}, nil
+	case "":
+		return Steps{N: 1}, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return Steps{}, fmt.Errorf(
+			"%w %q, accepted values: a positive number or \"all\"", ErrInvalidSteps, value,
+		)
+	}
+
+	return Steps{N: n}, nil
+}
+
+// NumOfRuns Converts the steps to the number of runs accepted by MigrateUp and MigrateDown
+func (steps Steps) NumOfRuns() NumOfRuns {
+	if steps.All {
+		return AllRuns
+	}
+	return NumOfRuns(steps.N)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StepsTestSuite struct {
+	suite.Suite
+}
+
+func TestStepsTestSuite(t *testing.T) {
+	suite.Run(t, new(StepsTestSuite))
+}
+
+func (suite *StepsTestSuite) TestItParsesSteps() {
+	scenarios := map[string]struct {
+		value         string
+		expectedSteps Steps
+		expectedErr   bool
+	}{
+		"all":             {"all", Steps{All: true}, false},
+		"number":          {"3", Steps{N: 3}, false},
+		"padded number":   {" 3 ", Steps{N: 3}, false},
+		"empty":           {"", Steps{N: 1}, false},
+		"zero":            {"0", Steps{}, true},
+		"negative":        {"-2", Steps{}, true},
+		"not a number":    {"three", Steps{}, true},
+		"uppercase all":   {"ALL", Steps{}, true},
+		"fractional":      {"1.5", Steps{}, true},
+		"above old bound": {"250000", Steps{N: 250000}, false},
+	}
+
+	for name, scenario := range scenarios {
+		steps, err := ParseSteps(scenario.value)
+		suite.Assert().Equal(scenario.expectedSteps, steps, "failed scenario: %s", name)
+		if scenario.expectedErr {
+			suite.Assert().ErrorIs(err, ErrInvalidSteps, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
+	}
+}
+
+func (suite *StepsTestSuite) TestItConvertsStepsToNumOfRuns() {
+	suite.Assert().Equal(AllRuns, Steps{All: true}.NumOfRuns())
+	suite.Assert().Equal(NumOfRuns(4), Steps{N: 4}.NumOfRuns())
+}
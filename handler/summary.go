@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// Summary Value object with the current migrations & executions state, without the full
+// executions history
+type Summary struct {
+	RegisteredCount int
+	FinishedCount   int
+	LastExecuted    ExecutedMigration
+	// NextToExecute The next migration MigrateUp runs, migrations from the skip list are only
+	// recorded, without running them (see WithSkipList)
+	NextToExecute migration.Migration
+}
+
+// PendingCount Returns the number of registered migrations which are not executed yet
+func (s Summary) PendingCount() int {
+	return max(s.RegisteredCount-s.FinishedCount, 0)
+}
+
+// Stats Value object with the state reported by the stats command
+type Stats struct {
+	Summary   Summary
+	Releases  []ReleaseSummary
+	Durations DurationStats
+	Skipped   []ExecutedMigration
+}
+
+// Summary Returns the current migrations & executions state. If the repository implements
+// execution.SummaryReader (and no baseline, sorter, custom ExecutionPlanBuilder or skip list is
+// configured, since the summary is read by version), only the latest execution and the finished
+// executions count are loaded, otherwise a full execution plan is created. The fast path does
+// not check the executions consistency, use Validate for that.
+func (handler *MigrationsHandler) Summary() (Summary, error) {
+	reader, isReader := handler.readRepository.(execution.SummaryReader)
+	if !isReader || handler.baseline > 0 || handler.customPlan || len(handler.skipList) > 0 {
+		plan, err := handler.plan()
+		if err != nil {
+			return Summary{}, err
+		}
+
+		return handler.summaryOf(plan), nil
+	}
+
+	errMsg := "failed to load executions summary"
+
+	latest, err := reader.LatestExecution()
+	if err != nil {
+		return Summary{}, fmt.Errorf("%s, latest execution error: %w", errMsg, err)
+	}
+
+	finishedCount, err := reader.CountFinished()
+	if err != nil {
+		return Summary{}, fmt.Errorf("%s, finished count error: %w", errMsg, err)
+	}
+
+	orderedMigrations := handler.registry.OrderedMigrations()
+	summary := Summary{RegisteredCount: len(orderedMigrations), FinishedCount: finishedCount}
+
+	if latest != nil {
+		summary.LastExecuted = ExecutedMigration{
+			Migration: handler.registry.Get(latest.Version),
+			Execution: latest,
+		}
+		if !latest.Finished() {
+			summary.NextToExecute = summary.LastExecuted.Migration
+			return summary, nil
+		}
+	}
+
+	for _, mig := range orderedMigrations {
+		if latest == nil || mig.Version() > latest.Version {
+			summary.NextToExecute = mig
+			break
+		}
+	}
+
+	return summary, nil
+}
+
+// Stats Returns the summary, the release summaries, the duration stats (with the top slowest
+// migrations, see DurationStats) and the skipped migrations, loading the executions only once.
+// If the executions are inconsistent with the registered migrations, only the duration stats are
+// returned, along with the plan error (see ErrPlanInconsistent).
+func (handler *MigrationsHandler) Stats(top int) (Stats, error) {
+	executions, err := handler.repository.LoadExecutions()
+	if err != nil {
+		return Stats{}, fmt.Errorf(
+			"failed to load stats, failed to load executions with error: %w", err,
+		)
+	}
+
+	stats := Stats{Durations: durationStatsOf(executions, top)}
+	plan, err := handler.planFor(&execution.InMemoryRepository{PersistedExecutions: executions})
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Summary = handler.summaryOf(plan)
+	stats.Releases = handler.releaseSummariesOf(plan)
+	stats.Skipped = skippedOf(plan)
+	return stats, nil
+}
+
+// summaryOf Returns the summary of the provided plan
+func (handler *MigrationsHandler) summaryOf(plan *ExecutionPlan) Summary {
+	summary := Summary{
+		RegisteredCount: plan.RegisteredMigrationsCount(),
+		FinishedCount:   plan.FinishedExecutionsCount(),
+		LastExecuted:    plan.LastExecuted(),
+	}
+	if toBeExecuted := handler.withoutSkipped(plan.AllToBeExecuted()); len(toBeExecuted) > 0 {
+		summary.NextToExecute = toBeExecuted[0]
+	}
+	return summary
+}
+
+// DisplayName Returns the name displayed for the migration with the provided version (see
+// migration.DisplayName)
+func (handler *MigrationsHandler) DisplayName(version uint64) string {
+	return migration.DisplayName(handler.registry, version)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type SummaryTestSuite struct {
+	suite.Suite
+}
+
+func TestSummaryTestSuite(t *testing.T) {
+	suite.Run(t, new(SummaryTestSuite))
+}
+
+type SummaryInMemoryRepository struct {
+	execution.InMemoryRepository
+	loadCalls int
+}
+
+func (r *SummaryInMemoryRepository) LoadExecutions() ([]execution.MigrationExecution, error) {
+	r.loadCalls++
+	return r.InMemoryRepository.LoadExecutions()
+}
+
+func (r *SummaryInMemoryRepository) LatestExecution() (*execution.MigrationExecution, error) {
+	var latest *execution.MigrationExecution
+	for _, exec := range r.PersistedExecutions {
+		if latest == nil || exec.Version > latest.Version {
+			latest = &exec
+		}
+	}
+	return latest, nil
+}
+
+func (r *SummaryInMemoryRepository) CountFinished() (int, error) {
+	count := 0
+	for _, exec := range r.PersistedExecutions {
+		if exec.Finished() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (suite *SummaryTestSuite) TestItBuildsSameSummaryWithAndWithoutFastPath() {
+	scenarios := map[string]struct {
+		executions            []execution.MigrationExecution
+		expectedFinishedCount int
+		expectedLast          uint64
+		expectedNext          uint64
+	}{
+		"no executions": {nil, 0, 0, 1},
+		"some finished": {
+			[]execution.MigrationExecution{
+				{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+				{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+			},
+			2, 2, 3,
+		},
+		"last unfinished": {
+			[]execution.MigrationExecution{
+				{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+				{Version: 2, ExecutedAtMs: 1},
+			},
+			1, 2, 2,
+		},
+		"all finished": {
+			[]execution.MigrationExecution{
+				{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+				{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1},
+				{Version: 3, ExecutedAtMs: 1, FinishedAtMs: 1},
+			},
+			3, 3, 0,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		registry := migration.NewGenericRegistry()
+		_ = registry.Register(migration.NewDummyMigration(1))
+		_ = registry.Register(migration.NewDummyMigration(2))
+		_ = registry.Register(migration.NewDummyMigration(3))
+
+		repos := []execution.Repository{
+			&execution.InMemoryRepository{PersistedExecutions: scenario.executions},
+			&SummaryInMemoryRepository{
+				InMemoryRepository: execution.InMemoryRepository{
+					PersistedExecutions: scenario.executions,
+				},
+			},
+		}
+
+		for _, repo := range repos {
+			handler, _ := NewHandler(registry, repo, nil)
+			summary, err := handler.Summary()
+
+			suite.Assert().NoError(err, "failed scenario %s", name)
+			suite.Assert().Equal(3, summary.RegisteredCount, "failed scenario %s", name)
+			suite.Assert().Equal(
+				scenario.expectedFinishedCount, summary.FinishedCount, "failed scenario %s", name,
+			)
+			suite.Assert().Equal(
+				3-scenario.expectedFinishedCount,
+				summary.PendingCount(),
+				"failed scenario %s",
+				name,
+			)
+
+			if scenario.expectedLast == 0 {
+				suite.Assert().Nil(summary.LastExecuted.Migration, "failed scenario %s", name)
+			} else {
+				suite.Assert().Equal(
+					scenario.expectedLast,
+					summary.LastExecuted.Execution.Version,
+					"failed scenario %s",
+					name,
+				)
+			}
+
+			if scenario.expectedNext == 0 {
+				suite.Assert().Nil(summary.NextToExecute, "failed scenario %s", name)
+			} else {
+				suite.Assert().Equal(
+					scenario.expectedNext,
+					summary.NextToExecute.Version(),
+					"failed scenario %s",
+					name,
+				)
+			}
+		}
+	}
+}
+
+func (suite *SummaryTestSuite) TestItDoesNotLoadAllExecutionsOnFastPath() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	repo := &SummaryInMemoryRepository{}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	_, _ = handler.Summary()
+
+	suite.Assert().Equal(0, repo.loadCalls)
+}
+
+func (suite *SummaryTestSuite) TestItSummarizesTheMigrationsMigrateUpRuns() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(&TaggedMigration{*migration.NewDummyMigration(3), []string{"schema"}})
+	repo := &SummaryInMemoryRepository{}
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+	}
+
+	sorted, _ := NewHandler(registry, repo, nil, WithSorter(TagPrioritySorter("schema")))
+	summary, err := sorted.Summary()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(uint64(3), summary.NextToExecute.Version())
+
+	skipping, _ := NewHandler(
+		registry, repo, nil, WithSkipList(migration.SkipList{2: "superseded"}),
+	)
+	summary, err = skipping.Summary()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(uint64(3), summary.NextToExecute.Version())
+	suite.Assert().Equal(2, summary.PendingCount())
+}
+
+func (suite *SummaryTestSuite) TestItLoadsTheExecutionsOnceForStats() {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(migration.NewDummyMigration(3))
+	repo := &SummaryInMemoryRepository{}
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 3},
+		{Version: 2, ExecutedAtMs: 3, FinishedAtMs: 3, SkipReason: "superseded"},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+
+	stats, err := handler.Stats(1)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(1, repo.loadCalls)
+	suite.Assert().Equal(uint64(3), stats.Summary.NextToExecute.Version())
+	suite.Assert().Equal(2, stats.Summary.FinishedCount)
+	suite.Assert().Equal([]ReleaseSummary{{Executed: 2, Pending: 1}}, stats.Releases)
+	suite.Assert().Equal(1, stats.Durations.Count)
+	suite.Assert().Len(stats.Skipped, 1)
+
+	repo.PersistedExecutions = append(
+		repo.PersistedExecutions, execution.MigrationExecution{Version: 5, ExecutedAtMs: 4},
+	)
+	stats, err = handler.Stats(1)
+	suite.Assert().ErrorIs(err, ErrPlanInconsistent)
+	suite.Assert().Equal(1, stats.Durations.Count)
+}
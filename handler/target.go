@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTarget is returned (wrapped) when the target version or version range of a run can
+// not be resolved against the registered migrations and their executions
+var ErrInvalidTarget = errors.New("invalid migrations target")
+
+// VersionRange A range of migration versions, both ends included
+type VersionRange struct {
+	From uint64
+	To   uint64
+}
+
+// ParseVersionRange Parses a version range formatted as "<from>..<to>", for example
+// "20240101000000..20240301000000"
+func ParseVersionRange(value string) (VersionRange, error) {
+	fromValue, toValue, found := strings.Cut(strings.TrimSpace(value), "..")
+	from, fromErr := strconv.ParseUint(fromValue, 10, 64)
+	to, toErr := strconv.ParseUint(toValue, 10, 64)
+
+	if !found || fromErr != nil || toErr != nil || from > to {
+		return VersionRange{}, fmt.Errorf(
+			"%w, invalid version range %q, expected <from>..<to>, with from <= to",
+			ErrInvalidTarget, value,
+		)
+	}
+
+	return VersionRange{From: from, To: to}, nil
+}
+
+// Target Resolves the number of migrations a run must execute (or roll back) against the
+// execution plan. Runs (see MigrateUpTo and MigrateDownTo) resolve their target against the plan
+// they build while holding the run lock, so targets which depend on the executions (for example,
+// a version) are resolved against the executions the run acts on.
+type Target func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error)
+
+// Runs Targets the next numOfRuns migrations
+func Runs(numOfRuns NumOfRuns) Target {
+	return func(*MigrationsHandler, *ExecutionPlan) (NumOfRuns, error) {
+		return numOfRuns, nil
+	}
+}
+
+// UpToVersion Targets all migrations up to and including the provided version. If the version is
+// already executed, there is nothing to run.
+func UpToVersion(version uint64) Target {
+	return func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error) {
+		if err := handler.checkRegistered(version); err != nil {
+			return 0, err
+		}
+
+		numOfRuns := 0
+		for _, mig := range plan.AllToBeExecuted() {
+			if mig.Version() > version {
+				break
+			}
+			numOfRuns++
+		}
+
+		return NumOfRuns(numOfRuns), nil
+	}
+}
+
+// DownToVersion Targets the provided version, and all executed migrations after it, for a
+// rollback. If the version is not executed, there is nothing to run.
+func DownToVersion(version uint64) Target {
+	return func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error) {
+		if err := handler.checkRegistered(version); err != nil {
+			return 0, err
+		}
+
+		numOfRuns := 0
+		for _, execMig := range plan.AllExecuted() {
+			if execMig.Migration.Version() >= version {
+				numOfRuns++
+			}
+		}
+
+		return NumOfRuns(numOfRuns), nil
+	}
+}
+
+// UpRange Targets exactly the migrations in the range. Since migrations are executed in order,
+// the range must start with the next migration to execute.
+func UpRange(versions VersionRange) Target {
+	return func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error) {
+		if err := handler.checkRegistered(versions.From, versions.To); err != nil {
+			return 0, err
+		}
+
+		next := plan.NextToExecute()
+		if next == nil || next.Version() != versions.From {
+			return 0, fmt.Errorf(
+				"%w, the range %d..%d does not start with the next migration to execute",
+				ErrInvalidTarget, versions.From, versions.To,
+			)
+		}
+
+		return UpToVersion(versions.To)(handler, plan)
+	}
+}
+
+// DownRange Targets exactly the migrations in the range, for a rollback. Since migrations are
+// rolled back in reverse order, the range must end with the last executed migration.
+func DownRange(versions VersionRange) Target {
+	return func(handler *MigrationsHandler, plan *ExecutionPlan) (NumOfRuns, error) {
+		if err := handler.checkRegistered(versions.From, versions.To); err != nil {
+			return 0, err
+		}
+
+		last := plan.LastExecuted()
+		if last.Migration == nil || last.Migration.Version() != versions.To {
+			return 0, fmt.Errorf(
+				"%w, the range %d..%d does not end with the last executed migration",
+				ErrInvalidTarget, versions.From, versions.To,
+			)
+		}
+
+		return DownToVersion(versions.From)(handler, plan)
+	}
+}
+
+// ResolveTarget Resolves the number of migrations the target stands for, against the current
+// executions, for operations which do not change them (for example, dry runs). Runs resolve
+// their targets themselves, while holding the run lock.
+func (handler *MigrationsHandler) ResolveTarget(target Target) (NumOfRuns, error) {
+	plan, err := handler.plan()
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to resolve the migrations target, failed to create execution plan with"+
+				" error: %w", err,
+		)
+	}
+
+	return target(handler, plan)
+}
+
+// StepsUpTo Resolves the number of migrations MigrateUp must run so that all migrations up to
+// and including the provided version are executed (see UpToVersion)
+func (handler *MigrationsHandler) StepsUpTo(version uint64) (NumOfRuns, error) {
+	return handler.ResolveTarget(UpToVersion(version))
+}
+
+// StepsDownTo Resolves the number of migrations MigrateDown must run so that the provided
+// version, and all executed migrations after it, are rolled back (see DownToVersion)
+func (handler *MigrationsHandler) StepsDownTo(version uint64) (NumOfRuns, error) {
+	return handler.ResolveTarget(DownToVersion(version))
+}
+
+// StepsUpRange Resolves the number of migrations MigrateUp must run to execute exactly the
+// migrations in the range (see UpRange)
+func (handler *MigrationsHandler) StepsUpRange(versions VersionRange) (NumOfRuns, error) {
+	return handler.ResolveTarget(UpRange(versions))
+}
+
+// StepsDownRange Resolves the number of migrations MigrateDown must run to roll back exactly
+// the migrations in the range (see DownRange)
+func (handler *MigrationsHandler) StepsDownRange(versions VersionRange) (NumOfRuns, error) {
+	return handler.ResolveTarget(DownRange(versions))
+}
+
+// checkRegistered Checks that the target versions are registered
+func (handler *MigrationsHandler) checkRegistered(versions ...uint64) error {
+	for _, version := range versions {
+		if handler.registry.Get(version) == nil {
+			return fmt.Errorf("%w, migration %d is not registered", ErrInvalidTarget, version)
+		}
+	}
+	return nil
+}
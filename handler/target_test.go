@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type TargetTestSuite struct {
+	suite.Suite
+}
+
+func TestTargetTestSuite(t *testing.T) {
+	suite.Run(t, new(TargetTestSuite))
+}
+
+// newTargetHandler Creates a handler with 5 registered migrations, of which the first 3 are
+// executed
+func newTargetHandler() *MigrationsHandler {
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 5; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+			{Version: 2, ExecutedAtMs: 2, FinishedAtMs: 2},
+			{Version: 3, ExecutedAtMs: 3, FinishedAtMs: 3},
+		},
+	}
+	handler, _ := NewHandler(registry, repo, nil)
+	return handler
+}
+
+func (suite *TargetTestSuite) TestItCanParseVersionRanges() {
+	scenarios := map[string]struct {
+		value       string
+		expected    VersionRange
+		expectedErr bool
+	}{
+		"valid":          {"2..5", VersionRange{2, 5}, false},
+		"single version": {" 3..3 ", VersionRange{3, 3}, false},
+		"reversed":       {"5..2", VersionRange{}, true},
+		"no separator":   {"2-5", VersionRange{}, true},
+		"missing end":    {"2..", VersionRange{}, true},
+		"not a number":   {"a..5", VersionRange{}, true},
+	}
+
+	for name, scenario := range scenarios {
+		versions, err := ParseVersionRange(scenario.value)
+		suite.Assert().Equal(scenario.expected, versions, "failed scenario: %s", name)
+		if scenario.expectedErr {
+			suite.Assert().ErrorIs(err, ErrInvalidTarget, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
+	}
+}
+
+func (suite *TargetTestSuite) TestItResolvesTheStepsToAVersion() {
+	scenarios := map[string]struct {
+		stage       MigrationStage
+		version     uint64
+		expected    NumOfRuns
+		expectedErr bool
+	}{
+		"up to pending":        {StageUp, 4, 1, false},
+		"up to last":           {StageUp, 5, 2, false},
+		"up to executed":       {StageUp, 2, 0, false},
+		"up to unregistered":   {StageUp, 9, 0, true},
+		"down to executed":     {StageDown, 2, 2, false},
+		"down to first":        {StageDown, 1, 3, false},
+		"down to pending":      {StageDown, 4, 0, false},
+		"down to unregistered": {StageDown, 9, 0, true},
+	}
+
+	for name, scenario := range scenarios {
+		handler := newTargetHandler()
+		resolve := handler.StepsUpTo
+		if scenario.stage == StageDown {
+			resolve = handler.StepsDownTo
+		}
+
+		numOfRuns, err := resolve(scenario.version)
+
+		suite.Assert().Equal(scenario.expected, numOfRuns, "failed scenario: %s", name)
+		if scenario.expectedErr {
+			suite.Assert().ErrorIs(err, ErrInvalidTarget, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
+	}
+}
+
+func (suite *TargetTestSuite) TestItResolvesTheStepsForARange() {
+	scenarios := map[string]struct {
+		stage       MigrationStage
+		versions    VersionRange
+		expected    NumOfRuns
+		expectedErr bool
+	}{
+		"up next":           {StageUp, VersionRange{4, 4}, 1, false},
+		"up all pending":    {StageUp, VersionRange{4, 5}, 2, false},
+		"up not from next":  {StageUp, VersionRange{5, 5}, 0, true},
+		"up executed":       {StageUp, VersionRange{2, 4}, 0, true},
+		"up unregistered":   {StageUp, VersionRange{4, 9}, 0, true},
+		"down last":         {StageDown, VersionRange{3, 3}, 1, false},
+		"down all executed": {StageDown, VersionRange{1, 3}, 3, false},
+		"down not to last":  {StageDown, VersionRange{1, 2}, 0, true},
+		"down pending":      {StageDown, VersionRange{3, 4}, 0, true},
+		"down unregistered": {StageDown, VersionRange{0, 3}, 0, true},
+	}
+
+	for name, scenario := range scenarios {
+		handler := newTargetHandler()
+		resolve := handler.StepsUpRange
+		if scenario.stage == StageDown {
+			resolve = handler.StepsDownRange
+		}
+
+		numOfRuns, err := resolve(scenario.versions)
+
+		suite.Assert().Equal(scenario.expected, numOfRuns, "failed scenario: %s", name)
+		if scenario.expectedErr {
+			suite.Assert().ErrorIs(err, ErrInvalidTarget, "failed scenario: %s", name)
+		} else {
+			suite.Assert().NoError(err, "failed scenario: %s", name)
+		}
+	}
+}
+
+// racingLocker Simulates another process which executed migrations before the lock was acquired
+type racingLocker struct {
+	repo       *execution.InMemoryRepository
+	executions []execution.MigrationExecution
+}
+
+func (locker *racingLocker) Lock() error {
+	locker.repo.SaveAll(locker.executions)
+	return nil
+}
+
+func (locker *racingLocker) Unlock() error {
+	return nil
+}
+
+func (suite *TargetTestSuite) TestItResolvesTargetsWhileHoldingTheRunLock() {
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 5; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{}
+	locker := &racingLocker{
+		repo: repo,
+		executions: []execution.MigrationExecution{
+			{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1},
+			{Version: 2, ExecutedAtMs: 2, FinishedAtMs: 2},
+		},
+	}
+	handler, _ := NewHandler(registry, repo, nil, WithLocker(locker))
+
+	report, err := handler.MigrateUpTo(UpToVersion(3))
+	suite.Assert().NoError(err)
+	suite.Require().Len(report.Migrations, 1)
+	suite.Assert().Equal(uint64(3), report.Migrations[0].Migration.Version())
+
+	locker.executions = nil
+	report, err = handler.MigrateDownTo(DownToVersion(2))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(report.Migrations, 2)
+	suite.Assert().Len(repo.PersistedExecutions, 1)
+
+	_, err = handler.MigrateUpTo(UpRange(VersionRange{3, 4}))
+	suite.Assert().ErrorIs(err, ErrInvalidTarget)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// Throttle Operational limits for MigrateUp runs, for databases under strict operational
+// windows. When a limit is reached, the run stops cleanly before the next migration and
+// returns an *RunThrottledError error, which holds the remaining migrations.
+type Throttle struct {
+	// MaxRunDuration No new migration is started after the run took this long. Zero means no
+	// limit. A migration which is already running is not interrupted.
+	MaxRunDuration time.Duration
+
+	// Pause Time to wait between migrations, to let the database (and its replicas) catch up
+	Pause time.Duration
+
+	// Window If set, migrations are started only inside this time window
+	Window *Window
+}
+
+// Window A daily time window, defined by offsets from midnight. If End is before Start, the
+// window spans midnight (for example, 22:00-04:00).
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+
+	// Location The time zone of the window. Defaults to UTC
+	Location *time.Location
+}
+
+// ParseWindow Creates a Window from the "HH:MM-HH:MM" format, for example "22:00-04:00"
+func ParseWindow(window string, location *time.Location) (Window, error) {
+	start, end, found := strings.Cut(window, "-")
+	if !found {
+		return Window{}, fmt.Errorf(
+			"invalid window %q, expected the HH:MM-HH:MM format", window,
+		)
+	}
+
+	startOffset, err := parseTimeOfDay(start)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window %q start: %w", window, err)
+	}
+
+	endOffset, err := parseTimeOfDay(end)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window %q end: %w", window, err)
+	}
+
+	return Window{Start: startOffset, End: endOffset, Location: location}, nil
+}
+
+// parseTimeOfDay Parses a HH:MM time of day as an offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute,
+		nil
+}
+
+// Contains Checks if the time is inside the window
+func (window Window) Contains(t time.Time) bool {
+	location := window.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	t = t.In(location)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+	offset := t.Sub(midnight)
+
+	if window.Start <= window.End {
+		return offset >= window.Start && offset < window.End
+	}
+	return offset >= window.Start || offset < window.End
+}
+
+func (window Window) String() string {
+	format := func(offset time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+	}
+	return format(window.Start) + "-" + format(window.End)
+}
+
+// RunThrottledError is returned when a MigrateUp run was stopped by the configured Throttle.
+// The migrations executed before the stop are persisted, so the run can be continued later.
+type RunThrottledError struct {
+	// Reason Why the run was stopped
+	Reason string
+
+	// Remaining The versions of the migrations which were not executed by the run
+	Remaining []uint64
+}
+
+func (e *RunThrottledError) Error() string {
+	return fmt.Sprintf(
+		"run stopped, %s. %d migrations remaining", e.Reason, len(e.Remaining),
+	)
+}
+
+// WithThrottle Sets the operational limits enforced for MigrateUp runs (see Throttle)
+func WithThrottle(throttle Throttle) Option {
+	return func(handler *MigrationsHandler) {
+		handler.throttle = throttle
+	}
+}
+
+// throttleRun Waits between migrations, if needed, and checks if the next migration can be
+// started. Returns an *RunThrottledError error if the run must stop.
+func (handler *MigrationsHandler) throttleRun(
+	startedAt time.Time,
+	executedCount int,
+	remaining []migration.Migration,
+) error {
+	if executedCount > 0 && handler.throttle.Pause > 0 {
+		handler.sleep(handler.throttle.Pause)
+	}
+
+	now := handler.clock.Now()
+	reason := ""
+
+	if handler.throttle.MaxRunDuration > 0 && executedCount > 0 &&
+		now.Sub(startedAt) >= handler.throttle.MaxRunDuration {
+		reason = fmt.Sprintf(
+			"max run duration of %s was reached", handler.throttle.MaxRunDuration,
+		)
+	} else if handler.throttle.Window != nil && !handler.throttle.Window.Contains(now) {
+		reason = fmt.Sprintf("outside the allowed time window %s", handler.throttle.Window)
+	}
+
+	if reason == "" {
+		return nil
+	}
+
+	versions := make([]uint64, 0, len(remaining))
+	for _, mig := range remaining {
+		versions = append(versions, mig.Version())
+	}
+	return &RunThrottledError{Reason: reason, Remaining: versions}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ThrottleTestSuite struct {
+	suite.Suite
+}
+
+func TestThrottleTestSuite(t *testing.T) {
+	suite.Run(t, new(ThrottleTestSuite))
+}
+
+// SlowMigration Migration which advances the clock, as if it took the provided duration
+type SlowMigration struct {
+	migration.DummyMigration
+	clock    *clock.Fixed
+	duration time.Duration
+}
+
+func (mig *SlowMigration) Up() error {
+	mig.clock.Advance(mig.duration)
+	return nil
+}
+
+func (suite *ThrottleTestSuite) newHandler(
+	now time.Time,
+	throttle Throttle,
+) (*MigrationsHandler, *execution.InMemoryRepository, *clock.Fixed) {
+	fixedClock := clock.NewFixed(now)
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 4; version++ {
+		_ = registry.Register(
+			&SlowMigration{*migration.NewDummyMigration(version), fixedClock, 10 * time.Minute},
+		)
+	}
+	repo := &execution.InMemoryRepository{}
+
+	handler, _ := NewHandler(
+		registry, repo, nil, WithClock(fixedClock), WithThrottle(throttle),
+	)
+	handler.sleep = fixedClock.Advance
+	return handler, repo, fixedClock
+}
+
+func (suite *ThrottleTestSuite) TestItStopsWhenMaxRunDurationIsReached() {
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	handler, repo, _ := suite.newHandler(
+		now, Throttle{MaxRunDuration: 25 * time.Minute, Pause: 5 * time.Minute},
+	)
+
+	execs, err := handler.MigrateUp(NumOfRuns(4))
+
+	var throttled *RunThrottledError
+	suite.Assert().True(errors.As(err, &throttled))
+	suite.Assert().Equal([]uint64{3, 4}, throttled.Remaining)
+	suite.Assert().ErrorContains(
+		err, "max run duration of 25m0s was reached. 2 migrations remaining",
+	)
+	suite.Assert().Len(execs, 2)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().Equal(
+		uint64(now.Add(15*time.Minute).UnixMilli()), repo.PersistedExecutions[1].ExecutedAtMs,
+	)
+}
+
+func (suite *ThrottleTestSuite) TestItStopsOutsideTheAllowedWindow() {
+	window, _ := ParseWindow("22:00-00:20", nil)
+	throttle := Throttle{Window: &window}
+
+	handler, repo, _ := suite.newHandler(time.Date(2024, 6, 1, 21, 0, 0, 0, time.UTC), throttle)
+	_, err := handler.MigrateUp(NumOfRuns(4))
+
+	var throttled *RunThrottledError
+	suite.Assert().True(errors.As(err, &throttled))
+	suite.Assert().Equal([]uint64{1, 2, 3, 4}, throttled.Remaining)
+	suite.Assert().ErrorContains(err, "outside the allowed time window 22:00-00:20")
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	handler, repo, _ = suite.newHandler(time.Date(2024, 6, 1, 23, 55, 0, 0, time.UTC), throttle)
+	_, err = handler.MigrateUp(NumOfRuns(4))
+
+	suite.Assert().True(errors.As(err, &throttled))
+	suite.Assert().Equal([]uint64{4}, throttled.Remaining)
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+}
+
+func (suite *ThrottleTestSuite) TestItRunsAllMigrationsWithinLimits() {
+	window, _ := ParseWindow("09:00-18:00", nil)
+	handler, repo, fixedClock := suite.newHandler(
+		time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		Throttle{MaxRunDuration: time.Hour, Pause: time.Minute, Window: &window},
+	)
+
+	execs, err := handler.MigrateUp(NumOfRuns(4))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(execs, 4)
+	suite.Assert().Len(repo.PersistedExecutions, 4)
+	suite.Assert().Equal(time.Date(2024, 6, 1, 10, 43, 0, 0, time.UTC), fixedClock.Now())
+}
+
+func (suite *ThrottleTestSuite) TestItCanParseAndCheckWindows() {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	window, err := ParseWindow("01:30 - 03:00", location)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(Window{90 * time.Minute, 3 * time.Hour, location}, window)
+	suite.Assert().Equal("01:30-03:00", window.String())
+	suite.Assert().True(window.Contains(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+	suite.Assert().False(window.Contains(time.Date(2024, 6, 1, 1, 0, 0, 0, time.UTC)))
+
+	for _, invalid := range []string{"", "01:30", "1:3x-02:00", "01:00-25:00"} {
+		_, err = ParseWindow(invalid, nil)
+		suite.Assert().Error(err, "failed scenario %q", invalid)
+	}
+}
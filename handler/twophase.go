@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrNotPrepared is returned (wrapped) when CommitUp would commit a two-phase migration which was
+// not prepared (see PrepareUp)
+var ErrNotPrepared = errors.New("two-phase migration was not prepared")
+
+// PrepareUp Executes Prepare() for the two-phase migrations (see migration.TwoPhase) among the
+// next numOfRuns pending migrations, so heavy work is staged ahead of the release, and persists
+// them as prepared, in the repository, which must implement execution.ProgressStore. No
+// execution is recorded and the other migrations are not executed. Migrations which are already
+// prepared are not prepared again. If Prepare() fails, Abort() is called and the preparation
+// stops. Returns the versions of the migrations prepared by the call. Executing the migration
+// with Up(), instead of Commit(), or rolling it back discards the preparation.
+func (handler *MigrationsHandler) PrepareUp(numOfRuns NumOfRuns) ([]uint64, error) {
+	return handler.PrepareUpTo(Runs(numOfRuns))
+}
+
+// PrepareUpTo Same as PrepareUp, but for the two-phase migrations of the target, which is
+// resolved while holding the run lock (see Target)
+func (handler *MigrationsHandler) PrepareUpTo(target Target) ([]uint64, error) {
+	var prepared []uint64
+	err := handler.checkWritable("prepare")
+	if err == nil {
+		err = handler.withLock(
+			func() (runErr error) {
+				prepared, runErr = handler.runPrepare(target)
+				return runErr
+			},
+		)
+	}
+	return prepared, err
+}
+
+func (handler *MigrationsHandler) runPrepare(target Target) ([]uint64, error) {
+	store, twoPhases, err := handler.pendingTwoPhases(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare migrations, %w", err)
+	}
+
+	var prepared []uint64
+	for _, mig := range twoPhases {
+		version, twoPhase := mig.Version(), mig.(migration.TwoPhase)
+		isPrepared, err := isPrepared(store, version)
+		if err != nil {
+			return prepared, fmt.Errorf("failed to prepare migrations, %w", err)
+		} else if isPrepared {
+			continue
+		}
+
+		if err = twoPhase.Prepare(); err != nil {
+			return prepared, fmt.Errorf(
+				"failed to prepare migrations, %w",
+				errors.Join(
+					newMigrationFailed(version, StagePrepare, err),
+					newMigrationFailed(version, StageAbort, twoPhase.Abort()),
+				),
+			)
+		}
+
+		if err = savePrepared(store, version, true); err != nil {
+			return prepared, fmt.Errorf("failed to prepare migrations, %w", err)
+		}
+		prepared = append(prepared, version)
+	}
+
+	return prepared, nil
+}
+
+// CommitUp Same as MigrateUpWithReport, but executes Commit(), instead of Up(), for two-phase
+// migrations (see migration.TwoPhase), switching over to the changes staged by PrepareUp. Fails
+// with ErrNotPrepared, before executing any migration, if a two-phase migration to be executed
+// was not prepared. If Commit() fails, Abort() is called, so the migration must be prepared
+// again before the next commit.
+func (handler *MigrationsHandler) CommitUp(numOfRuns NumOfRuns) (*RunReport, error) {
+	return handler.CommitUpTo(Runs(numOfRuns))
+}
+
+// CommitUpTo Same as CommitUp, but for the migrations of the target, which is resolved while
+// holding the run lock (see Target)
+func (handler *MigrationsHandler) CommitUpTo(target Target) (*RunReport, error) {
+	return handler.migrateUp(target, nil, true)
+}
+
+// AbortPrepared Executes Abort() for the prepared two-phase migrations among the next
+// numOfRuns pending migrations (see PrepareUp), discarding their staged changes, for example,
+// when a release is cancelled. Returns the versions of the aborted migrations.
+func (handler *MigrationsHandler) AbortPrepared(numOfRuns NumOfRuns) ([]uint64, error) {
+	return handler.AbortPreparedTo(Runs(numOfRuns))
+}
+
+// AbortPreparedTo Same as AbortPrepared, but for the prepared two-phase migrations of the
+// target, which is resolved while holding the run lock (see Target)
+func (handler *MigrationsHandler) AbortPreparedTo(target Target) ([]uint64, error) {
+	var aborted []uint64
+	err := handler.checkWritable("abort")
+	if err == nil {
+		err = handler.withLock(
+			func() (runErr error) {
+				aborted, runErr = handler.runAbort(target)
+				return runErr
+			},
+		)
+	}
+	return aborted, err
+}
+
+func (handler *MigrationsHandler) runAbort(target Target) ([]uint64, error) {
+	store, twoPhases, err := handler.pendingTwoPhases(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to abort prepared migrations, %w", err)
+	}
+
+	var aborted []uint64
+	for _, mig := range twoPhases {
+		version, twoPhase := mig.Version(), mig.(migration.TwoPhase)
+		isPrepared, err := isPrepared(store, version)
+		if err != nil {
+			return aborted, fmt.Errorf("failed to abort prepared migrations, %w", err)
+		} else if !isPrepared {
+			continue
+		}
+
+		if err = newMigrationFailed(version, StageAbort, twoPhase.Abort()); err != nil {
+			return aborted, fmt.Errorf("failed to abort prepared migrations, %w", err)
+		}
+
+		if err = savePrepared(store, version, false); err != nil {
+			return aborted, fmt.Errorf("failed to abort prepared migrations, %w", err)
+		}
+		aborted = append(aborted, version)
+	}
+
+	return aborted, nil
+}
+
+// pendingTwoPhases Returns the two-phase migrations (see migration.TwoPhase) among the pending
+// migrations of the target and the store of the prepared migrations
+func (handler *MigrationsHandler) pendingTwoPhases(
+	target Target,
+) (execution.ProgressStore, []migration.Migration, error) {
+	store, isStore := handler.repository.(execution.ProgressStore)
+	if !isStore {
+		return nil, nil, errors.New(
+			"the repository can not persist the prepared migrations (see execution.ProgressStore)",
+		)
+	}
+
+	plan, err := handler.plan()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create execution plan with error: %w", err)
+	}
+
+	numOfRuns, err := target(handler, plan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allToBeExec := plan.AllToBeExecuted()
+	toRun := handler.withoutSkipped(allToBeExec[:min(len(allToBeExec), int(numOfRuns))])
+
+	var twoPhases []migration.Migration
+	for _, mig := range toRun {
+		if _, isTwoPhase := mig.(migration.TwoPhase); isTwoPhase {
+			twoPhases = append(twoPhases, mig)
+		}
+	}
+	handler.setContext(twoPhases...)
+
+	return store, twoPhases, nil
+}
+
+// checkPrepared Checks, for commit runs, that all two-phase migrations to be executed are
+// prepared
+func (handler *MigrationsHandler) checkPrepared(migrations []migration.Migration) error {
+	store, _ := handler.repository.(execution.ProgressStore)
+
+	for _, mig := range migrations {
+		if _, isTwoPhase := mig.(migration.TwoPhase); !isTwoPhase {
+			continue
+		}
+
+		if store == nil {
+			return fmt.Errorf("%w, migration %d", ErrNotPrepared, mig.Version())
+		}
+
+		isPrepared, err := isPrepared(store, mig.Version())
+		if err != nil {
+			return err
+		} else if !isPrepared {
+			return fmt.Errorf("%w, migration %d", ErrNotPrepared, mig.Version())
+		}
+	}
+	return nil
+}
+
+// commit Executes Commit() for two-phase migrations and Up() for the others. The migration is
+// no longer considered prepared, even if Commit() fails, in which case Abort() is called.
+func (handler *MigrationsHandler) commit(mig migration.Migration) error {
+	twoPhase, isTwoPhase := mig.(migration.TwoPhase)
+	if !isTwoPhase {
+		return handler.up(mig)
+	}
+
+	store, _ := handler.repository.(execution.ProgressStore)
+	if err := savePrepared(store, mig.Version(), false); err != nil {
+		return err
+	}
+
+	if err := twoPhase.Commit(); err != nil {
+		if abortErr := twoPhase.Abort(); abortErr != nil {
+			return errors.Join(err, fmt.Errorf("abort failed with error: %w", abortErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// upUnprepared Executes Up() for the migration, which is no longer considered prepared (see
+// PrepareUp), so a later CommitUp, after a rollback, does not switch over to stale staged changes
+func (handler *MigrationsHandler) upUnprepared(mig migration.Migration) error {
+	if err := handler.clearPrepared(mig); err != nil {
+		return err
+	}
+	return handler.up(mig)
+}
+
+// clearPrepared Unmarks the two-phase migration as prepared, if it was, for runs which do not
+// commit it (Up() or Down())
+func (handler *MigrationsHandler) clearPrepared(mig migration.Migration) error {
+	_, isTwoPhase := mig.(migration.TwoPhase)
+	store, isStore := handler.repository.(execution.ProgressStore)
+	if !isTwoPhase || !isStore {
+		return nil
+	}
+
+	prepared, err := isPrepared(store, mig.Version())
+	if err != nil || !prepared {
+		return err
+	}
+	return savePrepared(store, mig.Version(), false)
+}
+
+// preparedKey The key of the progress which marks the migration as prepared
+func preparedKey(version uint64) string {
+	return fmt.Sprintf("prepare:%d", version)
+}
+
+func isPrepared(store execution.ProgressStore, version uint64) (bool, error) {
+	progress, err := store.LoadProgress(preparedKey(version))
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to load the preparation of migration %d with error: %w", version, err,
+		)
+	}
+	return progress != nil && progress.Done, nil
+}
+
+func savePrepared(store execution.ProgressStore, version uint64, prepared bool) error {
+	err := store.SaveProgress(execution.Progress{Key: preparedKey(version), Done: prepared})
+	if err != nil {
+		return fmt.Errorf(
+			"failed to save the preparation of migration %d with error: %w", version, err,
+		)
+	}
+	return nil
+}
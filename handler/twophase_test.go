@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type TwoPhaseTestSuite struct {
+	suite.Suite
+}
+
+func TestTwoPhaseTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoPhaseTestSuite))
+}
+
+// TwoPhaseMigration Records the called phases
+type TwoPhaseMigration struct {
+	migration.DummyMigration
+	calls      *[]string
+	prepareErr error
+	commitErr  error
+}
+
+func (mig *TwoPhaseMigration) record(phase string) {
+	*mig.calls = append(*mig.calls, fmt.Sprintf("%s %d", phase, mig.Version()))
+}
+
+func (mig *TwoPhaseMigration) Up() error {
+	mig.record("up")
+	return nil
+}
+
+func (mig *TwoPhaseMigration) Prepare() error {
+	mig.record("prepare")
+	return mig.prepareErr
+}
+
+func (mig *TwoPhaseMigration) Commit() error {
+	mig.record("commit")
+	return mig.commitErr
+}
+
+func (mig *TwoPhaseMigration) Abort() error {
+	mig.record("abort")
+	return nil
+}
+
+func (suite *TwoPhaseTestSuite) newHandler(
+	repo execution.Repository,
+) (*MigrationsHandler, map[uint64]*TwoPhaseMigration, *[]string) {
+	calls := &[]string{}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	twoPhases := map[uint64]*TwoPhaseMigration{}
+	for version := uint64(2); version <= 3; version++ {
+		twoPhases[version] = &TwoPhaseMigration{
+			DummyMigration: *migration.NewDummyMigration(version), calls: calls,
+		}
+		_ = registry.Register(twoPhases[version])
+	}
+
+	handler, err := NewHandler(registry, repo, nil)
+	suite.Require().NoError(err)
+	return handler, twoPhases, calls
+}
+
+func (suite *TwoPhaseTestSuite) TestItPreparesAheadAndCommitsAtDeployTime() {
+	repo := &oscRepository{}
+	handler, _, calls := suite.newHandler(repo)
+
+	prepared, err := handler.PrepareUp(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{2, 3}, prepared)
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	prepared, err = handler.PrepareUp(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(prepared)
+
+	report, err := handler.CommitUp(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(report.Executed(), 3)
+	suite.Assert().Len(repo.PersistedExecutions, 3)
+	suite.Assert().True(repo.PersistedExecutions[2].Finished())
+	suite.Assert().Equal([]string{"prepare 2", "prepare 3", "commit 2", "commit 3"}, *calls)
+
+	progress, _ := repo.LoadProgress(preparedKey(2))
+	suite.Assert().False(progress.Done)
+}
+
+func (suite *TwoPhaseTestSuite) TestItRefusesToCommitMigrationsWhichWereNotPrepared() {
+	repo := &oscRepository{}
+	handler, _, calls := suite.newHandler(repo)
+
+	_, err := handler.PrepareUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+
+	_, err = handler.CommitUp(AllRuns)
+	suite.Assert().ErrorIs(err, ErrNotPrepared)
+	suite.Assert().ErrorContains(err, "migration 3")
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	_, err = handler.CommitUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+
+	_, err = handler.MigrateUp(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]string{"prepare 2", "commit 2", "up 3"}, *calls)
+
+	plain, _, _ := suite.newHandler(&execution.InMemoryRepository{})
+	_, err = plain.PrepareUp(AllRuns)
+	suite.Assert().ErrorContains(err, "can not persist the prepared migrations")
+	_, err = plain.CommitUp(AllRuns)
+	suite.Assert().ErrorIs(err, ErrNotPrepared)
+}
+
+func (suite *TwoPhaseTestSuite) TestItAbortsFailedPhases() {
+	repo := &oscRepository{}
+	handler, twoPhases, calls := suite.newHandler(repo)
+	twoPhases[3].prepareErr = errors.New("disk full")
+
+	prepared, err := handler.PrepareUp(AllRuns)
+	suite.Assert().Equal([]uint64{2}, prepared)
+	var failed *MigrationFailedError
+	suite.Assert().ErrorAs(err, &failed)
+	suite.Assert().Equal(StagePrepare, failed.Stage)
+	suite.Assert().ErrorContains(err, "disk full")
+
+	twoPhases[2].commitErr = errors.New("lock wait timeout")
+	_, err = handler.CommitUp(NumOfRuns(2))
+	suite.Assert().ErrorContains(err, "lock wait timeout")
+	suite.Assert().Len(repo.PersistedExecutions, 2)
+	suite.Assert().False(repo.PersistedExecutions[1].Finished())
+	suite.Assert().Equal(
+		[]string{"prepare 2", "prepare 3", "abort 3", "commit 2", "abort 2"}, *calls,
+	)
+
+	_, err = handler.ForceDown(2)
+	suite.Assert().NoError(err)
+	_, err = handler.CommitUp(NumOfRuns(1))
+	suite.Assert().ErrorIs(err, ErrNotPrepared)
+}
+
+func (suite *TwoPhaseTestSuite) TestItAbortsPreparedMigrations() {
+	repo := &oscRepository{}
+	handler, _, calls := suite.newHandler(repo)
+
+	_, _ = handler.PrepareUp(NumOfRuns(2))
+	aborted, err := handler.AbortPrepared(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{2}, aborted)
+
+	aborted, err = handler.AbortPrepared(AllRuns)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(aborted)
+	suite.Assert().Equal([]string{"prepare 2", "abort 2"}, *calls)
+}
+
+func (suite *TwoPhaseTestSuite) TestItForgetsThePreparationOfMigrationsWhichWereNotCommitted() {
+	repo := &oscRepository{}
+	handler, _, calls := suite.newHandler(repo)
+
+	// Executed with Up(), instead of Commit(), then rolled back
+	_, _ = handler.PrepareUp(AllRuns)
+	_, err := handler.MigrateUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateDown(AllRuns)
+	suite.Assert().NoError(err)
+
+	_, err = handler.CommitUp(NumOfRuns(2))
+	suite.Assert().ErrorIs(err, ErrNotPrepared)
+	suite.Assert().ErrorContains(err, "migration 2")
+
+	// Committed, then rolled back
+	_, _ = handler.PrepareUp(AllRuns)
+	_, err = handler.CommitUp(NumOfRuns(2))
+	suite.Assert().NoError(err)
+	_, err = handler.MigrateDown(AllRuns)
+	suite.Assert().NoError(err)
+
+	_, err = handler.CommitUp(AllRuns)
+	suite.Assert().ErrorIs(err, ErrNotPrepared)
+	suite.Assert().ErrorContains(err, "migration 2")
+	suite.Assert().Empty(repo.PersistedExecutions)
+	suite.Assert().Equal(
+		[]string{"prepare 2", "prepare 3", "up 2", "prepare 2", "commit 2"}, *calls,
+	)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/schema"
+)
+
+// registrationChecker Implemented by registries which can check if all migration files have
+// been registered (see migration.DirMigrationsRegistry)
+type registrationChecker interface {
+	HasAllMigrationsRegistered() (bool, []string, []string, error)
+}
+
+// revalidator Implemented by registries which memoize the registration check (see
+// migration.DirMigrationsRegistry)
+type revalidator interface {
+	Revalidate() error
+}
+
+// Revalidate Makes the registry check again if all migration files have been registered, for
+// example, after migration files were deployed. The check is memoized by the registry (see
+// migration.DirMigrationsRegistry), so Validate, called by status endpoints, does not read the
+// migrations directory on every call. Errors if the registry is out of sync with the migration
+// files. Registries which do not check migration files are always valid.
+func (handler *MigrationsHandler) Revalidate() error {
+	if registry, ok := handler.registry.(revalidator); ok {
+		return registry.Revalidate()
+	}
+	return nil
+}
+
+// Validate Checks the migrations & executions state without changing anything and returns a
+// human-readable description for each detected problem: migration files which are not
+// registered, version gaps, with remediation suggestions (see VersionGaps), otherwise
+// inconsistent executions, lock file discrepancies (see WithLockFile) and, if a drift detector
+// is provided, schema drift.
+// Errors only if a check could not be performed.
+func (handler *MigrationsHandler) Validate(driftDetector schema.DriftDetector) ([]string, error) {
+	var problems []string
+
+	if checker, ok := handler.registry.(registrationChecker); ok {
+		allRegistered, missing, extra, err := checker.HasAllMigrationsRegistered()
+		if err != nil {
+			return problems, fmt.Errorf("failed to check registered migrations: %w", err)
+		}
+
+		if !allRegistered {
+			problems = append(
+				problems,
+				"registry is out of sync with migration files. Not registered: "+
+					strings.Join(missing, ", ")+". Extra migrations: "+strings.Join(extra, ", "),
+			)
+		}
+	}
+
+	gaps, err := handler.VersionGaps()
+	if err != nil {
+		return problems, err
+	}
+
+	for _, gap := range gaps {
+		problems = append(problems, gap.String())
+	}
+
+	plan, err := handler.plan()
+	if err != nil {
+		// The version gaps explain why the executions are inconsistent
+		if len(gaps) == 0 {
+			problems = append(problems, err.Error())
+		}
+		return problems, nil
+	}
+
+	discrepancies, err := handler.LockFileDiscrepancies()
+	if err != nil {
+		return problems, err
+	}
+	problems = append(problems, discrepancies...)
+
+	if driftDetector != nil {
+		var lastVersion uint64
+		if last := plan.LastExecuted(); last.Migration != nil {
+			lastVersion = last.Migration.Version()
+		}
+
+		drift, err := driftDetector.DetectDrift(lastVersion)
+		if err != nil {
+			return problems, fmt.Errorf("failed to detect schema drift: %w", err)
+		}
+
+		for _, difference := range drift {
+			problems = append(problems, "schema drift detected: "+difference)
+		}
+	}
+
+	return problems, nil
+}
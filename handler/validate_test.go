@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValidateTestSuite struct {
+	suite.Suite
+}
+
+func TestValidateTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidateTestSuite))
+}
+
+type FakeDriftDetector struct {
+	drift []string
+	err   error
+}
+
+func (f *FakeDriftDetector) DetectDrift(uint64) ([]string, error) {
+	return f.drift, f.err
+}
+
+func (suite *ValidateTestSuite) TestItCanValidateState() {
+	scenarios := map[string]struct {
+		executions       []execution.MigrationExecution
+		detector         *FakeDriftDetector
+		expectedProblems int
+		expectedErr      bool
+	}{
+		"valid state": {
+			[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			&FakeDriftDetector{},
+			0,
+			false,
+		},
+		"inconsistent executions": {
+			[]execution.MigrationExecution{{Version: 2, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			&FakeDriftDetector{},
+			1,
+			false,
+		},
+		"schema drift": {
+			[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			&FakeDriftDetector{drift: []string{"a", "b"}},
+			2,
+			false,
+		},
+		"drift detection fails": {
+			[]execution.MigrationExecution{{Version: 1, ExecutedAtMs: 1, FinishedAtMs: 1}},
+			&FakeDriftDetector{err: errors.New("err")},
+			0,
+			true,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		registry := migration.NewGenericRegistry()
+		_ = registry.Register(migration.NewDummyMigration(1))
+		_ = registry.Register(migration.NewDummyMigration(2))
+		repo := &execution.InMemoryRepository{PersistedExecutions: scenario.executions}
+		handler, _ := NewHandler(registry, repo, nil)
+
+		problems, err := handler.Validate(scenario.detector)
+
+		suite.Assert().Len(problems, scenario.expectedProblems, "failed scenario %s", name)
+		suite.Assert().Equal(scenario.expectedErr, err != nil, "failed scenario %s", name)
+	}
+}
+
+func (suite *ValidateTestSuite) TestItDetectsNotRegisteredMigrationFiles() {
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	_, _ = migration.GenerateBlankMigration(migPath)
+	registry := migration.NewEmptyDirMigrationsRegistry(migPath)
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	problems, err := handler.Validate(nil)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(problems, 1)
+	suite.Assert().Contains(problems[0], "Not registered: version_")
+}
+
+func (suite *ValidateTestSuite) TestItRevalidatesMemoizedRegistrationChecks() {
+	migPath, _ := migration.NewMigrationsDirPath(suite.T().TempDir())
+	registry := migration.NewEmptyDirMigrationsRegistry(migPath)
+	handler, _ := NewHandler(registry, &execution.InMemoryRepository{}, nil)
+
+	problems, err := handler.Validate(nil)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(problems)
+
+	_, _ = migration.GenerateBlankMigration(migPath)
+	problems, _ = handler.Validate(nil)
+	suite.Assert().Empty(problems)
+
+	suite.Assert().ErrorContains(handler.Revalidate(), "Not registered: version_")
+	problems, _ = handler.Validate(nil)
+	suite.Assert().Len(problems, 1)
+
+	generic, _ := NewHandler(migration.NewGenericRegistry(), &execution.InMemoryRepository{}, nil)
+	suite.Assert().NoError(generic.Revalidate())
+}
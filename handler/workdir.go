@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// WorkDir A work directory set for a migration (see migration.WorkDirAware)
+type WorkDir struct {
+	Version   uint64
+	Direction MigrationStage
+	Path      string
+	// RemoveErr Why the directory could not be removed after the migration returned, nil if it
+	// was removed, with its contents
+	RemoveErr error
+}
+
+// WorkDirHook Receives the work directory of a migration, after the migration returned and the
+// directory was removed (see WorkDir.RemoveErr)
+type WorkDirHook func(workDir WorkDir)
+
+// WithWorkDirRoot The directory in which the work directories of the migrations (see
+// migration.WorkDirAware) are created, for example, a volume with enough space for data exports.
+// Defaults to os.TempDir()
+func WithWorkDirRoot(root string) Option {
+	return func(handler *MigrationsHandler) {
+		handler.workDirRoot = root
+	}
+}
+
+// WithWorkDirHooks Registers hooks which receive the work directories of all migrations,
+// including the ones executed by ForceUp, ForceDown and VerifyReversible, which do not return
+// run reports, for example, to audit them
+func WithWorkDirHooks(hooks ...WorkDirHook) Option {
+	return func(handler *MigrationsHandler) {
+		handler.workDirHooks = append(handler.workDirHooks, hooks...)
+	}
+}
+
+// withWorkDir Calls run after setting a new work directory for the migration, if it implements
+// migration.WorkDirAware, and removes the directory, with its contents, after run returns.
+// Returns the directory, with an empty path if none was set. Failures to remove the directory do
+// not fail the migration, they are recorded in the returned directory and logged (see
+// WithLogger).
+func (handler *MigrationsHandler) withWorkDir(
+	mig migration.Migration,
+	direction MigrationStage,
+	run func() error,
+) (WorkDir, error) {
+	workDirAware, isWorkDirAware := mig.(migration.WorkDirAware)
+	if !isWorkDirAware {
+		return WorkDir{}, run()
+	}
+
+	dir, err := os.MkdirTemp(
+		handler.workDirRoot, fmt.Sprintf("go-migrations-%d-%s-*", mig.Version(), direction),
+	)
+	if err != nil {
+		return WorkDir{}, fmt.Errorf(
+			"failed to create the work directory of migration %d with error: %w",
+			mig.Version(), err,
+		)
+	}
+
+	workDirAware.SetWorkDir(dir)
+	err = run()
+
+	workDir := WorkDir{Version: mig.Version(), Direction: direction, Path: dir}
+	workDir.RemoveErr = os.RemoveAll(dir)
+	if workDir.RemoveErr != nil && handler.logger != nil {
+		handler.logger.Warn(
+			"failed to remove the work directory",
+			"migration", mig.Version(), "dir", dir, "error", workDir.RemoveErr,
+		)
+	}
+
+	for _, hook := range handler.workDirHooks {
+		hook(workDir)
+	}
+
+	return workDir, err
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkDirTestSuite struct {
+	suite.Suite
+}
+
+func TestWorkDirTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkDirTestSuite))
+}
+
+// ExportingMigration Writes an intermediate file in its work directory
+type ExportingMigration struct {
+	migration.DummyMigration
+	workDir string
+	seen    []string
+	err     error
+}
+
+func (mig *ExportingMigration) SetWorkDir(dir string) {
+	mig.workDir = dir
+}
+
+func (mig *ExportingMigration) export() error {
+	mig.seen = append(mig.seen, mig.workDir)
+	err := os.WriteFile(filepath.Join(mig.workDir, "users.csv"), []byte("1,jane"), 0600)
+	if err != nil {
+		return err
+	}
+	return mig.err
+}
+
+func (mig *ExportingMigration) Up() error {
+	return mig.export()
+}
+
+func (mig *ExportingMigration) Down() error {
+	return mig.export()
+}
+
+func (suite *WorkDirTestSuite) TestItSetsAWorkDirForEachCallAndRemovesIt() {
+	root := suite.T().TempDir()
+	mig := &ExportingMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	_ = registry.Register(migration.NewDummyMigration(2))
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil, WithWorkDirRoot(root),
+	)
+
+	report, err := handler.MigrateUpWithReport(AllRuns)
+	suite.Require().NoError(err)
+	suite.Require().Len(mig.seen, 1)
+	suite.Assert().Equal(mig.seen[0], report.Migrations[0].WorkDir)
+	suite.Assert().Equal(root, filepath.Dir(mig.seen[0]))
+	suite.Assert().True(strings.HasPrefix(filepath.Base(mig.seen[0]), "go-migrations-1-up-"))
+	suite.Assert().NoDirExists(mig.seen[0])
+	suite.Assert().Empty(report.Migrations[1].WorkDir)
+
+	encoded, _ := json.Marshal(report)
+	suite.Assert().Contains(string(encoded), `"workDir":`)
+
+	mig.err = errors.New("export failed")
+	downReport, err := handler.MigrateDownWithReport(AllRuns)
+	suite.Assert().ErrorContains(err, "export failed")
+	suite.Require().Len(mig.seen, 2)
+	suite.Assert().NotEqual(mig.seen[0], mig.seen[1])
+	suite.Assert().Equal(mig.seen[1], downReport.Migrations[1].WorkDir)
+	suite.Assert().NoDirExists(mig.seen[1])
+
+	entries, _ := os.ReadDir(root)
+	suite.Assert().Empty(entries)
+}
+
+func (suite *WorkDirTestSuite) TestItFailsTheMigrationIfTheWorkDirCanNotBeCreated() {
+	mig := &ExportingMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	repo := &execution.InMemoryRepository{}
+	handler, _ := NewHandler(
+		registry, repo, nil, WithWorkDirRoot(filepath.Join(suite.T().TempDir(), "missing")),
+	)
+
+	_, err := handler.ForceUp(1)
+
+	var failed *MigrationFailedError
+	suite.Assert().ErrorAs(err, &failed)
+	suite.Assert().Equal(StageUp, failed.Stage)
+	suite.Assert().ErrorContains(err, "failed to create the work directory of migration 1")
+	suite.Assert().Empty(mig.seen)
+	suite.Assert().False(repo.PersistedExecutions[0].Finished())
+}
+
+func (suite *WorkDirTestSuite) TestItPassesTheWorkDirsToTheHooks() {
+	mig := &ExportingMigration{DummyMigration: *migration.NewDummyMigration(1)}
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(mig)
+	var workDirs []WorkDir
+	handler, _ := NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		WithWorkDirRoot(suite.T().TempDir()),
+		WithWorkDirHooks(func(workDir WorkDir) { workDirs = append(workDirs, workDir) }),
+	)
+
+	_, err := handler.ForceUp(1)
+	suite.Require().NoError(err)
+	_, err = handler.ForceDown(1)
+	suite.Require().NoError(err)
+
+	suite.Assert().Equal(
+		[]WorkDir{
+			{Version: 1, Direction: StageUp, Path: mig.seen[0]},
+			{Version: 1, Direction: StageDown, Path: mig.seen[1]},
+		},
+		workDirs,
+	)
+}
+
+func (suite *WorkDirTestSuite) TestItReportsWorkDirsWhichCouldNotBeRemoved() {
+	report := &RunReport{Migrations: []MigrationReport{
+		{WorkDir: "/tmp/go-migrations-1-up-1", WorkDirRemoveErr: errors.New("device busy")},
+		{WorkDir: "/tmp/go-migrations-2-up-1"},
+	}}
+
+	encoded, err := json.Marshal(report)
+
+	suite.Require().NoError(err)
+	suite.Assert().Equal(1, strings.Count(string(encoded), `"workDirRemoveError":"device busy"`))
+}
@@ -0,0 +1,238 @@
+// Package impact includes the estimation of the impact (locks, rows touched) of the SQL
+// statements captured while dry-running migrations (see handler.DryRunUp), so it can be
+// reviewed before production runs.
+package impact
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/sqlcapture"
+)
+
+// Lock The lock level a statement is expected to take
+type Lock string
+
+const (
+	// LockNone The statement does not lock existing rows (for example, a SELECT or CREATE TABLE)
+	LockNone Lock = "none"
+	// LockRows The statement locks the rows it changes (for example, an UPDATE)
+	LockRows Lock = "rows"
+	// LockTable The statement may lock the whole table (for example, an ALTER TABLE)
+	LockTable Lock = "table"
+)
+
+// UnknownRows The estimated rows value used when the number of rows could not be estimated
+const UnknownRows int64 = -1
+
+// Estimate The estimated impact of a statement
+type Estimate struct {
+	Statement sqlcapture.Statement
+	Table     string
+	Lock      Lock
+	// EstimatedRows The estimated number of rows read or changed by DML statements and the
+	// estimated number of rows of the table for table locking statements. UnknownRows if it
+	// could not be estimated.
+	EstimatedRows int64
+}
+
+// Analyzer Must be implemented by any mechanism which can estimate the impact of a statement.
+// Implementations must not change the database state.
+type Analyzer interface {
+	Estimate(statement sqlcapture.Statement) (Estimate, error)
+}
+
+type statementPattern struct {
+	pattern *regexp.Regexp
+	lock    Lock
+	explain bool
+}
+
+// statementPatterns Matches the statement types with a known impact. The first group is the
+// affected table.
+var statementPatterns = []statementPattern{
+	{regexp.MustCompile(`(?i)^\s*alter\s+table\s+([^\s(]+)`), LockTable, false},
+	{regexp.MustCompile(`(?i)^\s*drop\s+table\s+(?:if\s+exists\s+)?([^\s,;]+)`), LockTable, false},
+	{regexp.MustCompile(`(?i)^\s*truncate\s+(?:table\s+)?([^\s;]+)`), LockTable, false},
+	{regexp.MustCompile(`(?i)^\s*rename\s+table\s+([^\s]+)`), LockTable, false},
+	{
+		regexp.MustCompile(
+			`(?i)^\s*create\s+(?:unique\s+|fulltext\s+|spatial\s+)?index\s+\S+\s+on\s+([^\s(]+)`,
+		),
+		LockTable, false,
+	},
+	{regexp.MustCompile(`(?i)^\s*drop\s+index\s+\S+\s+on\s+([^\s;]+)`), LockTable, false},
+	{
+		regexp.MustCompile(`(?i)^\s*create\s+table\s+(?:if\s+not\s+exists\s+)?([^\s(]+)`),
+		LockNone, false,
+	},
+	{
+		regexp.MustCompile(
+			`(?i)^\s*(?:insert|replace)\s+(?:(?:low_priority|delayed|high_priority|ignore)\s+)*` +
+				`(?:into\s+)?([^\s(]+)`,
+		),
+		LockRows, true,
+	},
+	{
+		regexp.MustCompile(`(?i)^\s*update\s+(?:(?:low_priority|ignore)\s+)*([^\s]+)`),
+		LockRows, true,
+	},
+	{
+		regexp.MustCompile(
+			`(?i)^\s*delete\s+(?:(?:low_priority|quick|ignore)\s+)*from\s+([^\s;]+)`,
+		),
+		LockRows, true,
+	},
+	{regexp.MustCompile(`(?is)^\s*select\s.*?\sfrom\s+([^\s,;()]+)`), LockNone, true},
+}
+
+// Classify Returns the table affected by the statement and the lock level it is expected to
+// take. The table is empty if the statement type is not known.
+func Classify(query string) (table string, lock Lock) {
+	pattern, table := match(query)
+	if pattern == nil {
+		return "", LockNone
+	}
+	return table, pattern.lock
+}
+
+func match(query string) (*statementPattern, string) {
+	for i, candidate := range statementPatterns {
+		if groups := candidate.pattern.FindStringSubmatch(query); groups != nil {
+			return &statementPatterns[i], strings.ReplaceAll(groups[1], "`", "")
+		}
+	}
+	return nil, ""
+}
+
+// MysqlAnalyzer Analyzer implementation for Mysql. Rows touched by DML statements are estimated
+// via EXPLAIN (which does not execute the statement) and rows of locked tables via
+// information_schema. Statements which can not be explained (for example, because they use a table
+// created by an earlier statement of the dry run) are estimated as UnknownRows. It should use a db
+// handle for the database the migrations would run against (for example, a production replica).
+type MysqlAnalyzer struct {
+	DB  *sql.DB
+	Ctx context.Context
+}
+
+func (a *MysqlAnalyzer) Estimate(statement sqlcapture.Statement) (Estimate, error) {
+	pattern, table := match(statement.Query)
+	estimate := Estimate{
+		Statement: statement, Table: table, Lock: LockNone, EstimatedRows: UnknownRows,
+	}
+
+	if pattern == nil {
+		return estimate, nil
+	}
+
+	estimate.Lock = pattern.lock
+	var err error
+
+	if pattern.explain {
+		// EXPLAIN fails for statements which depend on the earlier, not executed, statements
+		if rows, explainErr := a.explainRows(statement); explainErr == nil {
+			estimate.EstimatedRows = rows
+		}
+	} else if pattern.lock == LockTable {
+		estimate.EstimatedRows, err = a.tableRows(table)
+	} else {
+		estimate.EstimatedRows = 0
+	}
+
+	if err != nil {
+		return estimate, fmt.Errorf(
+			"failed to estimate impact of statement %q with error: %w", statement.Query, err,
+		)
+	}
+
+	return estimate, nil
+}
+
+func (a *MysqlAnalyzer) context() context.Context {
+	if a.Ctx == nil {
+		return context.Background()
+	}
+	return a.Ctx
+}
+
+// explainRows Sums the rows column of the statement's EXPLAIN output
+func (a *MysqlAnalyzer) explainRows(statement sqlcapture.Statement) (total int64, err error) {
+	rows, err := a.DB.QueryContext(a.context(), "EXPLAIN "+statement.Query, statement.Args...)
+	if err != nil {
+		return UnknownRows, err
+	}
+
+	defer func() {
+		err = errors.Join(err, rows.Close())
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return UnknownRows, err
+	}
+
+	rowsColumn := -1
+	for i, column := range columns {
+		if strings.EqualFold(column, "rows") {
+			rowsColumn = i
+		}
+	}
+
+	if rowsColumn < 0 {
+		return UnknownRows, nil
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return UnknownRows, err
+		}
+
+		if values[rowsColumn] == nil {
+			continue
+		}
+
+		count, parseErr := strconv.ParseInt(string(values[rowsColumn]), 10, 64)
+		if parseErr != nil {
+			return UnknownRows, parseErr
+		}
+		total += count
+	}
+
+	return total, rows.Err()
+}
+
+// tableRows Returns the approximate number of rows of the table, or UnknownRows if the table
+// does not exist (yet)
+func (a *MysqlAnalyzer) tableRows(table string) (int64, error) {
+	schemaName, tableName, qualified := strings.Cut(table, ".")
+	if !qualified {
+		schemaName, tableName = "", table
+	}
+
+	var count sql.NullInt64
+	err := a.DB.QueryRowContext(
+		a.context(),
+		"SELECT `TABLE_ROWS` FROM `information_schema`.`TABLES`"+
+			" WHERE `TABLE_SCHEMA` = COALESCE(NULLIF(?, ''), DATABASE()) AND `TABLE_NAME` = ?",
+		schemaName, tableName,
+	).Scan(&count)
+
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !count.Valid) {
+		return UnknownRows, nil
+	} else if err != nil {
+		return UnknownRows, err
+	}
+
+	return count.Int64, nil
+}
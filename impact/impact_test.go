@@ -0,0 +1,93 @@
+package impact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImpactTestSuite struct {
+	suite.Suite
+}
+
+func TestImpactTestSuite(t *testing.T) {
+	suite.Run(t, new(ImpactTestSuite))
+}
+
+func (suite *ImpactTestSuite) TestItCanClassifyStatements() {
+	scenarios := map[string]struct {
+		query         string
+		expectedTable string
+		expectedLock  Lock
+	}{
+		"alter table":    {"ALTER TABLE `users` ADD COLUMN `age` INT", "users", LockTable},
+		"drop table":     {"drop table if exists users", "users", LockTable},
+		"truncate":       {"TRUNCATE TABLE app.users", "app.users", LockTable},
+		"rename table":   {"RENAME TABLE users TO members", "users", LockTable},
+		"create index":   {"CREATE UNIQUE INDEX idx_email ON users (email)", "users", LockTable},
+		"drop index":     {"DROP INDEX idx_email ON users", "users", LockTable},
+		"create table":   {"CREATE TABLE IF NOT EXISTS users (id INT)", "users", LockNone},
+		"insert":         {"INSERT IGNORE INTO users (id) VALUES (?)", "users", LockRows},
+		"replace":        {"REPLACE users (id) VALUES (1)", "users", LockRows},
+		"update":         {"  UPDATE LOW_PRIORITY users SET age = 1", "users", LockRows},
+		"delete":         {"DELETE FROM `users` WHERE id > 10", "users", LockRows},
+		"select":         {"SELECT id\nFROM users WHERE id = 1", "users", LockNone},
+		"unknown":        {"SET NAMES utf8mb4", "", LockNone},
+		"empty":          {"", "", LockNone},
+		"create view":    {"CREATE VIEW active AS SELECT 1", "", LockNone},
+		"lowercase drop": {"drop index idx on `app`.`users`", "app.users", LockTable},
+	}
+
+	for name, scenario := range scenarios {
+		table, lock := Classify(scenario.query)
+		suite.Assert().Equal(scenario.expectedTable, table, "failed scenario %s", name)
+		suite.Assert().Equal(scenario.expectedLock, lock, "failed scenario %s", name)
+	}
+}
+
+func (suite *ImpactTestSuite) TestItEstimatesImpactViaExplainAndInformationSchema() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+	analyzer := &MysqlAnalyzer{DB: db}
+
+	update := sqlcapture.Statement{Query: "UPDATE users SET age = ?", Args: []any{int64(1)}}
+	estimate, err := analyzer.Estimate(update)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(Estimate{update, "users", LockRows, UnknownRows}, estimate)
+
+	alter := sqlcapture.Statement{Query: "ALTER TABLE users ADD COLUMN age INT"}
+	estimate, err = analyzer.Estimate(alter)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(Estimate{alter, "users", LockTable, UnknownRows}, estimate)
+
+	create := sqlcapture.Statement{Query: "CREATE TABLE users (id INT)"}
+	estimate, err = analyzer.Estimate(create)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(Estimate{create, "users", LockNone, 0}, estimate)
+
+	statements := recorder.Statements()
+	suite.Assert().Len(statements, 2)
+	suite.Assert().Equal("EXPLAIN UPDATE users SET age = ?", statements[0].Query)
+	suite.Assert().Equal([]any{int64(1)}, statements[0].Args)
+	suite.Assert().Contains(statements[1].Query, "information_schema")
+	suite.Assert().Equal([]any{"", "users"}, statements[1].Args)
+}
+
+func (suite *ImpactTestSuite) TestItEstimatesUnknownRowsForStatementsWhichCanNotBeExplained() {
+	db, _ := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	analyzer := &MysqlAnalyzer{DB: db, Ctx: ctx}
+
+	insert := sqlcapture.Statement{Query: "INSERT INTO new_users SELECT * FROM users"}
+	estimate, err := analyzer.Estimate(insert)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(Estimate{insert, "new_users", LockRows, UnknownRows}, estimate)
+
+	// Only EXPLAIN failures are tolerated
+	_, err = analyzer.Estimate(sqlcapture.Statement{Query: "ALTER TABLE users ADD age INT"})
+	suite.Assert().ErrorIs(err, context.Canceled)
+}
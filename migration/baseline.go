@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BaselineFileName The name of the file, from the migrations directory, which records the
+// baseline version: all migrations with a version lower or equal to it were archived (removed
+// from the migrations directory) and are considered executed in all environments
+const BaselineFileName = "migrations.baseline"
+
+// ReadBaseline Returns the baseline version recorded in the migrations directory. found is
+// false if there is no baseline.
+func ReadBaseline(dirPath MigrationsDirPath) (version uint64, found bool, err error) {
+	contents, err := os.ReadFile(filepath.Join(string(dirPath), BaselineFileName))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	version, err = strconv.ParseUint(strings.TrimSpace(string(contents)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid baseline file contents: %w", err)
+	}
+
+	return version, true, nil
+}
+
+// WriteBaseline Records the baseline version in the migrations directory
+func WriteBaseline(dirPath MigrationsDirPath, version uint64) error {
+	return os.WriteFile(
+		filepath.Join(string(dirPath), BaselineFileName),
+		[]byte(strconv.FormatUint(version, 10)+"\n"),
+		0644,
+	)
+}
@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BaselineTestSuite struct {
+	suite.Suite
+}
+
+func TestBaselineTestSuite(t *testing.T) {
+	suite.Run(t, new(BaselineTestSuite))
+}
+
+func (suite *BaselineTestSuite) TestItCanWriteAndReadBaseline() {
+	migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+
+	_, found, err := ReadBaseline(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().False(found)
+
+	suite.Assert().NoError(WriteBaseline(migDir, 123))
+
+	version, found, err := ReadBaseline(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().True(found)
+	suite.Assert().Equal(uint64(123), version)
+}
+
+func (suite *BaselineTestSuite) TestItFailsToReadInvalidBaseline() {
+	migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+	_ = os.WriteFile(filepath.Join(string(migDir), BaselineFileName), []byte("abc"), 0600)
+
+	_, found, err := ReadBaseline(migDir)
+
+	suite.Assert().Error(err)
+	suite.Assert().False(found)
+}
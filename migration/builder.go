@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Constructor Builds a migration using the shared dependencies
+type Constructor[D any] func(deps D) Migration
+
+// Builder Registers migrations built from constructors which receive shared dependencies (for
+// example, db handles and configs). Dependencies and migrations are built lazily, only when a
+// migration is executed, so programs do not build every migration's dependencies on startup
+// just to fill the registry.
+// Migrations are registered as lazy migrations (see NewLazyMigration).
+type Builder[D any] struct {
+	newDeps      func() (D, error)
+	once         sync.Once
+	deps         D
+	depsErr      error
+	constructors map[uint64]Constructor[D]
+}
+
+// NewBuilder Creates a new Builder. newDeps is called once, when the first migration is built
+func NewBuilder[D any](newDeps func() (D, error)) *Builder[D] {
+	return &Builder[D]{newDeps: newDeps, constructors: make(map[uint64]Constructor[D])}
+}
+
+// Add Adds the constructor for the migration with the provided version. The version must match
+// the built migration's version.
+func (b *Builder[D]) Add(version uint64, constructor Constructor[D]) *Builder[D] {
+	b.constructors[version] = constructor
+	return b
+}
+
+// Register Registers all added migrations, without building them, in the registry
+func (b *Builder[D]) Register(registry MigrationsRegistry) error {
+	versions := make([]uint64, 0, len(b.constructors))
+	for version := range b.constructors {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+
+	for _, version := range versions {
+		constructor := b.constructors[version]
+		factory := func() (Migration, error) {
+			deps, err := b.resolveDeps()
+			if err != nil {
+				return nil, err
+			}
+			return constructor(deps), nil
+		}
+
+		if err := registry.Register(NewLazyMigration(version, factory)); err != nil {
+			return fmt.Errorf("failed to register migration %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Builder[D]) resolveDeps() (D, error) {
+	b.once.Do(
+		func() {
+			b.deps, b.depsErr = b.newDeps()
+			if b.depsErr != nil {
+				b.depsErr = fmt.Errorf("failed to build migration dependencies: %w", b.depsErr)
+			}
+		},
+	)
+	return b.deps, b.depsErr
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BuilderTestSuite struct {
+	suite.Suite
+}
+
+func TestBuilderTestSuite(t *testing.T) {
+	suite.Run(t, new(BuilderTestSuite))
+}
+
+type builderDeps struct {
+	dsn string
+}
+
+type depsMigration struct {
+	DummyMigration
+	deps builderDeps
+}
+
+func (suite *BuilderTestSuite) TestItBuildsMigrationsLazilyWithSharedDeps() {
+	depsBuilt := 0
+	var built []*depsMigration
+	constructor := func(version uint64) Constructor[builderDeps] {
+		return func(deps builderDeps) Migration {
+			mig := &depsMigration{*NewDummyMigration(version), deps}
+			built = append(built, mig)
+			return mig
+		}
+	}
+
+	builder := NewBuilder(
+		func() (builderDeps, error) {
+			depsBuilt++
+			return builderDeps{"dsn"}, nil
+		},
+	).Add(2, constructor(2)).Add(1, constructor(1))
+
+	registry := NewGenericRegistry()
+	suite.Assert().NoError(builder.Register(registry))
+
+	suite.Assert().Equal([]uint64{1, 2}, registry.OrderedVersions())
+	suite.Assert().Equal(0, depsBuilt)
+	suite.Assert().Empty(built)
+
+	suite.Assert().NoError(registry.Get(1).Up())
+	suite.Assert().NoError(registry.Get(1).Down())
+	suite.Assert().NoError(registry.Get(2).Up())
+
+	suite.Assert().Equal(1, depsBuilt)
+	suite.Assert().Len(built, 2)
+	for _, mig := range built {
+		suite.Assert().Equal("dsn", mig.deps.dsn)
+	}
+}
+
+func (suite *BuilderTestSuite) TestItFailsToRunMigrationsWhenDepsCanNotBeBuilt() {
+	depsErr := errors.New("no connection")
+	builder := NewBuilder(
+		func() (builderDeps, error) {
+			return builderDeps{}, depsErr
+		},
+	).Add(
+		1, func(deps builderDeps) Migration {
+			return NewDummyMigration(1)
+		},
+	)
+
+	registry := NewGenericRegistry()
+	suite.Assert().NoError(builder.Register(registry))
+
+	suite.Assert().ErrorIs(registry.Get(1).Up(), depsErr)
+	suite.Assert().ErrorIs(registry.Get(1).(Validator).Validate(), depsErr)
+}
+
+func (suite *BuilderTestSuite) TestItFailsToRunMigrationsBuiltWithOtherVersion() {
+	builder := NewBuilder(
+		func() (builderDeps, error) {
+			return builderDeps{}, nil
+		},
+	).Add(
+		1, func(deps builderDeps) Migration {
+			return NewDummyMigration(2)
+		},
+	)
+
+	registry := NewGenericRegistry()
+	suite.Assert().NoError(builder.Register(registry))
+
+	suite.Assert().ErrorContains(registry.Get(1).Up(), "returned migration 2")
+}
+
+func (suite *BuilderTestSuite) TestItFailsToRegisterDuplicateVersions() {
+	registry := NewGenericRegistry()
+	_ = registry.Register(NewDummyMigration(1))
+
+	builder := NewBuilder(
+		func() (builderDeps, error) {
+			return builderDeps{}, nil
+		},
+	).Add(
+		1, func(deps builderDeps) Migration {
+			return NewDummyMigration(1)
+		},
+	)
+
+	suite.Assert().Error(builder.Register(registry))
+}
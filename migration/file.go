@@ -0,0 +1,152 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// UpFileSuffix Marks the file holding the Up() changes of a file migration
+	UpFileSuffix = ".up"
+
+	// DownFileSuffix Marks the file holding the Down() changes of a file migration
+	DownFileSuffix = ".down"
+)
+
+// FileRunner Must execute the contents of a migration file (for example, SQL statements)
+type FileRunner func(contents string) error
+
+// FileMigration A migration whose changes are read from files instead of Go code: the Up()
+// changes from version_<version>.up<ext> and the Down() changes from
+// version_<version>.down<ext> (for example, version_1717236000.up.sql). The contents are
+// executed by the FileRunner.
+type FileMigration struct {
+	version  uint64
+	upPath   string
+	downPath string
+	run      FileRunner
+}
+
+// UpFileName Returns the name of the up file of a file migration
+func UpFileName(version uint64, ext string) string {
+	return FileNamePrefix + FileNameSeparator + strconv.FormatUint(version, 10) + UpFileSuffix + ext
+}
+
+// DownFileName Returns the name of the down file of a file migration
+func DownFileName(version uint64, ext string) string {
+	return FileNamePrefix + FileNameSeparator + strconv.FormatUint(version, 10) +
+		DownFileSuffix + ext
+}
+
+func (m *FileMigration) Version() uint64 {
+	return m.version
+}
+
+func (m *FileMigration) Up() error {
+	return m.runFile(m.upPath)
+}
+
+// Down Runs the down file. Fails if the migration has no down file.
+func (m *FileMigration) Down() error {
+	if m.downPath == "" {
+		return fmt.Errorf("migration %d has no down file", m.version)
+	}
+	return m.runFile(m.downPath)
+}
+
+// Description Returns the name of the up file
+func (m *FileMigration) Description() string {
+	return filepath.Base(m.upPath)
+}
+
+// DisplayName Returns the name of the up file. See Named.
+func (m *FileMigration) DisplayName() string {
+	return filepath.Base(m.upPath)
+}
+
+func (m *FileMigration) runFile(path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file %s with error: %w", path, err)
+	}
+
+	if strings.TrimSpace(string(contents)) == "" {
+		return nil
+	}
+	return m.run(string(contents))
+}
+
+// NewFileMigrationsRegistry Builds a registry with all the file migrations with the provided
+// extension (for example, ".sql") from the migrations directory. Up files are required, down
+// files are optional (Down() fails for migrations without one). The directory path is
+// normalized (see NormalizePath).
+func NewFileMigrationsRegistry(
+	dirPath MigrationsDirPath,
+	ext string,
+	run FileRunner,
+) (*GenericRegistry, error) {
+	errMsg := "failed to build file migrations registry"
+	dirPath = MigrationsDirPath(NormalizePath(string(dirPath)))
+
+	entries, err := os.ReadDir(string(dirPath))
+	if err != nil {
+		return nil, fmt.Errorf("%s, dir entries read failed with error: %w", errMsg, err)
+	}
+
+	migrations := make(map[uint64]*FileMigration)
+	var downOnly []uint64
+	for _, entry := range entries {
+		version, suffix, ok := parseFileMigrationName(entry.Name(), ext)
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		mig, found := migrations[version]
+		if !found {
+			mig = &FileMigration{version: version, run: run}
+			migrations[version] = mig
+		}
+
+		path := filepath.Join(string(dirPath), entry.Name())
+		if suffix == UpFileSuffix {
+			mig.upPath = path
+		} else {
+			mig.downPath = path
+		}
+	}
+
+	registry := NewGenericRegistry()
+	for version, mig := range migrations {
+		if mig.upPath == "" {
+			downOnly = append(downOnly, version)
+			continue
+		}
+		_ = registry.Register(mig)
+	}
+
+	if len(downOnly) > 0 {
+		return nil, fmt.Errorf("%s, migrations without up file: %v", errMsg, downOnly)
+	}
+	return registry, nil
+}
+
+// parseFileMigrationName Extracts the version and the up/down suffix from a file migration name
+func parseFileMigrationName(name string, ext string) (uint64, string, bool) {
+	prefix := FileNamePrefix + FileNameSeparator
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return 0, "", false
+	}
+
+	name = strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+	for _, suffix := range []string{UpFileSuffix, DownFileSuffix} {
+		if versionStr, found := strings.CutSuffix(name, suffix); found {
+			version, err := strconv.ParseUint(versionStr, 10, 64)
+			return version, suffix, err == nil
+		}
+	}
+
+	return 0, "", false
+}
@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileMigrationTestSuite struct {
+	suite.Suite
+}
+
+func TestFileMigrationTestSuite(t *testing.T) {
+	suite.Run(t, new(FileMigrationTestSuite))
+}
+
+func writeFiles(dirPath string, files map[string]string) {
+	for name, contents := range files {
+		_ = os.WriteFile(filepath.Join(dirPath, name), []byte(contents), 0600)
+	}
+}
+
+func (suite *FileMigrationTestSuite) TestItCanBuildRegistriesFromFileMigrations() {
+	dir := suite.T().TempDir()
+	writeFiles(
+		dir, map[string]string{
+			"version_2.up.sql":   "CREATE TABLE b (id INT);",
+			"version_2.down.sql": "DROP TABLE b;",
+			"version_1.up.sql":   "CREATE TABLE a (id INT);",
+			"version_3.up.json":  "[]",
+			"version_1.go":       "package migrations",
+			"notes.sql":          "",
+		},
+	)
+
+	var ran []string
+	registry, err := NewFileMigrationsRegistry(
+		MigrationsDirPath(dir), ".sql", func(contents string) error {
+			ran = append(ran, contents)
+			return nil
+		},
+	)
+
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]uint64{1, 2}, registry.OrderedVersions())
+	suite.Assert().NoError(registry.Get(2).Up())
+	suite.Assert().NoError(registry.Get(2).Down())
+	suite.Assert().Equal([]string{"CREATE TABLE b (id INT);", "DROP TABLE b;"}, ran)
+	suite.Assert().ErrorContains(registry.Get(1).Down(), "migration 1 has no down file")
+	suite.Assert().Equal("version_1.up.sql", registry.Get(1).(Describer).Description())
+	suite.Assert().Equal("version_2.up.sql", DisplayName(registry, 2))
+}
+
+func (suite *FileMigrationTestSuite) TestItDoesNotRunEmptyFiles() {
+	dir := suite.T().TempDir()
+	writeFiles(dir, map[string]string{"version_1.up.sql": " \n"})
+	runErr := errors.New("should not run")
+
+	registry, _ := NewFileMigrationsRegistry(
+		MigrationsDirPath(dir), ".sql", func(string) error { return runErr },
+	)
+
+	suite.Assert().NoError(registry.Get(1).Up())
+}
+
+func (suite *FileMigrationTestSuite) TestItFailsForFileMigrationsWithoutUpFile() {
+	dir := suite.T().TempDir()
+	writeFiles(dir, map[string]string{"version_7.down.sql": "DROP TABLE a;"})
+
+	_, err := NewFileMigrationsRegistry(MigrationsDirPath(dir), ".sql", nil)
+	suite.Assert().ErrorContains(err, "migrations without up file: [7]")
+
+	_, err = NewFileMigrationsRegistry(MigrationsDirPath(filepath.Join(dir, "x")), ".sql", nil)
+	suite.Assert().ErrorContains(err, "dir entries read failed")
+}
+
+func (suite *FileMigrationTestSuite) TestItCanGenerateBlankFileMigrations() {
+	dir := suite.T().TempDir()
+	writeFiles(dir, map[string]string{"version_1712953083.down.sql": ""})
+	opts := BlankOptions{
+		Clock:         clock.NewFixed(time.Unix(1712953083, 0)),
+		Layout:        YearMonthLayout,
+		FileExtension: ".sql",
+	}
+
+	fileName, err := GenerateBlankMigrationWithOptions(MigrationsDirPath(dir), opts)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("version_1712953084.up.sql", fileName)
+	suite.Assert().FileExists(filepath.Join(dir, "version_1712953084.up.sql"))
+	suite.Assert().FileExists(filepath.Join(dir, "version_1712953084.down.sql"))
+	suite.Assert().NoFileExists(filepath.Join(dir, "version_1712953083.up.sql"))
+}
@@ -0,0 +1,125 @@
+package migration
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// lazyMigration Migration proxy which builds the actual migration only when it is needed
+// (Up, Down or an optional interface method is called). The version must be known upfront, so
+// the migration can be registered and ordered without building it.
+type lazyMigration struct {
+	version uint64
+	factory Factory
+	once    sync.Once
+	built   Migration
+	err     error
+}
+
+// Factory Builds a migration. It is called only when the migration needs to run
+type Factory func() (Migration, error)
+
+// NewLazyMigration Creates a migration which calls the factory only when it needs to run (Up,
+// Down or an optional interface method is called). The factory is called at most once and the
+// built migration must have the provided version.
+// Lazy migrations only expose Up, Down, Validator, Describer, Destructive and LoggerAware
+// behaviour, other optional interfaces (like SQLRecorder) are not available for them.
+func NewLazyMigration(version uint64, factory Factory) Migration {
+	return &lazyMigration{version: version, factory: factory}
+}
+
+// resolve Builds the migration, once, and checks its version
+func (m *lazyMigration) resolve() (Migration, error) {
+	m.once.Do(
+		func() {
+			m.built, m.err = m.factory()
+
+			if m.err == nil && m.built == nil {
+				m.err = fmt.Errorf("factory for migration %d returned no migration", m.version)
+			} else if m.err == nil && m.built.Version() != m.version {
+				m.err = fmt.Errorf(
+					"factory for migration %d returned migration %d",
+					m.version, m.built.Version(),
+				)
+			} else if m.err != nil {
+				m.err = fmt.Errorf("failed to build migration %d: %w", m.version, m.err)
+			}
+		},
+	)
+	return m.built, m.err
+}
+
+func (m *lazyMigration) Version() uint64 {
+	return m.version
+}
+
+func (m *lazyMigration) Up() error {
+	mig, err := m.resolve()
+	if err != nil {
+		return err
+	}
+	return mig.Up()
+}
+
+func (m *lazyMigration) Down() error {
+	mig, err := m.resolve()
+	if err != nil {
+		return err
+	}
+	return mig.Down()
+}
+
+// Validate Builds the migration, so build failures are reported before a run starts, and
+// calls its Validate, if it implements Validator
+func (m *lazyMigration) Validate() error {
+	mig, err := m.resolve()
+	if err != nil {
+		return err
+	}
+
+	if validator, isValidator := mig.(Validator); isValidator {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// Description Returns the description of the built migration, if it implements Describer
+func (m *lazyMigration) Description() string {
+	mig, err := m.resolve()
+	if err != nil {
+		return ""
+	}
+
+	if describer, isDescriber := mig.(Describer); isDescriber {
+		return describer.Description()
+	}
+	return ""
+}
+
+// Destructive Reports if the built migration is destructive, if it implements Destructive.
+// Migrations which can not be built are reported as destructive.
+func (m *lazyMigration) Destructive() bool {
+	mig, err := m.resolve()
+	if err != nil {
+		return true
+	}
+
+	if destructive, isDestructive := mig.(Destructive); isDestructive {
+		return destructive.Destructive()
+	}
+	return false
+}
+
+// SetLogger Sets the logger of the built migration, if it implements LoggerAware. Build failures
+// are ignored, they are reported by Up and Down.
+func (m *lazyMigration) SetLogger(logger *slog.Logger) {
+	mig, err := m.resolve()
+	if err != nil {
+		return
+	}
+
+	if loggerAware, isLoggerAware := mig.(LoggerAware); isLoggerAware {
+		loggerAware.SetLogger(logger)
+	}
+}
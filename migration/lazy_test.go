@@ -0,0 +1,88 @@
+package migration
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LazyMigrationTestSuite struct {
+	suite.Suite
+}
+
+func TestLazyMigrationTestSuite(t *testing.T) {
+	suite.Run(t, new(LazyMigrationTestSuite))
+}
+
+type describedMigration struct {
+	DummyMigration
+	logger *slog.Logger
+}
+
+func (mig *describedMigration) SetLogger(logger *slog.Logger) {
+	mig.logger = logger
+}
+
+func (mig *describedMigration) Description() string {
+	return "adds users table"
+}
+
+func (mig *describedMigration) Destructive() bool {
+	return true
+}
+
+func (suite *LazyMigrationTestSuite) TestItCallsTheFactoryOnlyOnceWhenNeeded() {
+	calls := 0
+	var built *describedMigration
+	mig := NewLazyMigration(
+		1, func() (Migration, error) {
+			calls++
+			built = &describedMigration{DummyMigration: *NewDummyMigration(1)}
+			return built, nil
+		},
+	)
+
+	suite.Assert().Equal(uint64(1), mig.Version())
+	suite.Assert().Equal(0, calls)
+
+	suite.Assert().NoError(mig.(Validator).Validate())
+	suite.Assert().NoError(mig.Up())
+	suite.Assert().NoError(mig.Down())
+	suite.Assert().Equal("adds users table", mig.(Describer).Description())
+	suite.Assert().True(mig.(Destructive).Destructive())
+	logger := slog.Default()
+	mig.(LoggerAware).SetLogger(logger)
+	suite.Assert().Same(logger, built.logger)
+	suite.Assert().Equal(1, calls)
+}
+
+func (suite *LazyMigrationTestSuite) TestItFailsToRunWhenTheFactoryFails() {
+	factoryErr := errors.New("connection refused")
+	calls := 0
+	mig := NewLazyMigration(
+		1, func() (Migration, error) {
+			calls++
+			return nil, factoryErr
+		},
+	)
+
+	suite.Assert().ErrorIs(mig.Up(), factoryErr)
+	suite.Assert().ErrorIs(mig.Down(), factoryErr)
+	suite.Assert().ErrorIs(mig.(Validator).Validate(), factoryErr)
+	suite.Assert().Equal("", mig.(Describer).Description())
+	suite.Assert().True(mig.(Destructive).Destructive())
+	mig.(LoggerAware).SetLogger(slog.Default())
+	suite.Assert().Equal(1, calls)
+}
+
+func (suite *LazyMigrationTestSuite) TestItFailsToRunWhenTheFactoryReturnsNoMigration() {
+	mig := NewLazyMigration(
+		1, func() (Migration, error) {
+			return nil, nil
+		},
+	)
+
+	suite.Assert().ErrorContains(mig.Up(), "returned no migration")
+}
@@ -0,0 +1,92 @@
+package migration
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LockFileName The name of the file, from the migrations directory, which pins the exact
+// versions expected to be applied for a release, so deploys are reproducible. Each line holds a
+// version. Empty lines and lines starting with # are ignored.
+const LockFileName = "migrations.lock"
+
+// LockFile The versions pinned by the lock file, in ascending order
+type LockFile []uint64
+
+// Contains Checks if the version is pinned by the lock file
+func (lock LockFile) Contains(version uint64) bool {
+	_, found := slices.BinarySearch(lock, version)
+	return found
+}
+
+// ReadLockFile Returns the versions pinned by the lock file from the migrations directory. found
+// is false if there is no lock file.
+func ReadLockFile(dirPath MigrationsDirPath) (lock LockFile, found bool, err error) {
+	file, err := os.Open(filepath.Join(string(dirPath), LockFileName))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	lock, err = ParseLockFile(file)
+	return lock, err == nil, err
+}
+
+// ParseLockFile Parses the lock file lines (see LockFileName for the format)
+func ParseLockFile(r io.Reader) (LockFile, error) {
+	lock := LockFile{}
+	lines := bufio.NewScanner(r)
+
+	for lineNum := 1; lines.Scan(); lineNum++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		version, err := strconv.ParseUint(line, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid lock file version %q at line %d: %w", line, lineNum, err,
+			)
+		}
+		lock = append(lock, version)
+	}
+
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.Sort(lock)
+	return slices.Compact(lock), nil
+}
+
+// WriteLockFile Pins the versions in the lock file from the migrations directory, replacing
+// the previously pinned versions
+func WriteLockFile(dirPath MigrationsDirPath, versions []uint64) error {
+	lock := slices.Clone(versions)
+	slices.Sort(lock)
+
+	var contents strings.Builder
+	contents.WriteString("# Migration versions expected to be applied for this release.\n")
+	contents.WriteString("# Generated by the lock:write command.\n")
+	for _, version := range slices.Compact(lock) {
+		contents.WriteString(strconv.FormatUint(version, 10) + "\n")
+	}
+
+	return os.WriteFile(
+		filepath.Join(string(dirPath), LockFileName), []byte(contents.String()), 0644,
+	)
+}
@@ -0,0 +1,46 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LockFileTestSuite struct {
+	suite.Suite
+}
+
+func TestLockFileTestSuite(t *testing.T) {
+	suite.Run(t, new(LockFileTestSuite))
+}
+
+func (suite *LockFileTestSuite) TestItCanWriteAndReadLockFile() {
+	migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+
+	lock, found, err := ReadLockFile(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().False(found)
+	suite.Assert().Empty(lock)
+
+	suite.Require().NoError(WriteLockFile(migDir, []uint64{3, 1, 2, 3}))
+
+	lock, found, err = ReadLockFile(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().True(found)
+	suite.Assert().Equal(LockFile{1, 2, 3}, lock)
+	suite.Assert().True(lock.Contains(2))
+	suite.Assert().False(lock.Contains(4))
+}
+
+func (suite *LockFileTestSuite) TestItFailsToReadInvalidLockFile() {
+	migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+	contents := "# release 1.2\n\n1712953083\nabc\n"
+	_ = os.WriteFile(filepath.Join(string(migDir), LockFileName), []byte(contents), 0600)
+
+	_, found, err := ReadLockFile(migDir)
+
+	suite.Assert().False(found)
+	suite.Assert().ErrorContains(err, "invalid lock file version \"abc\" at line 4")
+}
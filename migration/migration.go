@@ -3,14 +3,23 @@
 package migration
 
 import (
+	"crypto/sha256"
+	"database/sql"
 	_ "embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"text/template"
 	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
 )
 
 // TmplContents File template to be used to generate a new, base migration file
@@ -37,7 +46,7 @@ type Migration interface {
 	// global state, to not impact other migration executions. You can have multiple migrations
 	// Up() act as a unit, but, care should be taken when coordinating them (use save points
 	// for example, and save them in a central place which can be used as a persistent
-	// source of truth).
+	// source of truth, see the savepoint package).
 	Up() error
 
 	// Down must include all necessary code that will roll back the changes made by the Up()
@@ -47,6 +56,118 @@ type Migration interface {
 	Down() error
 }
 
+// Describer Optional interface which can be implemented by migrations to provide a short,
+// human-readable description of their changes (displayed, for example, in interactive runs)
+type Describer interface {
+	Description() string
+}
+
+// Named Optional interface which can be implemented by migrations to provide the name displayed
+// for them in the CLI output (for example, the file or the embedded resource they are read
+// from). See DisplayName.
+type Named interface {
+	DisplayName() string
+}
+
+// Validator Optional interface which can be implemented by migrations to check their
+// dependencies (for example, that a required table exists or that a config value is present)
+// before any migration of a run is executed
+type Validator interface {
+	// Validate must return an error if the migration can not be executed. It must not change
+	// the database state.
+	Validate() error
+}
+
+// Destructive Optional interface which can be implemented by migrations whose Up() removes
+// data (for example, drops a table or a column). Destructive migrations need explicit approval
+// in protected environments (see handler.Guardrails).
+type Destructive interface {
+	Destructive() bool
+}
+
+// DowntimeRequirer Optional interface which can be implemented by migrations which can not run
+// while the application serves traffic (for example, a table rewrite which the application code
+// is not compatible with). Runs including such migrations enter the application maintenance mode
+// first (see handler.WithMaintenanceMode).
+type DowntimeRequirer interface {
+	RequiresDowntime() bool
+}
+
+// Tagger Optional interface which can be implemented by migrations to label them (for example,
+// "schema" or "data"). Tags can be used to customize the execution order (see
+// handler.TagPrioritySorter).
+type Tagger interface {
+	Tags() []string
+}
+
+// Releaser Optional interface which can be implemented by migrations to declare the release
+// they belong to (for example, "2024.06"), so they can be staged per release (see
+// handler.WithReleases). Takes precedence over the releases file (see ReleasesFileName).
+type Releaser interface {
+	Release() string
+}
+
+// TwoPhase Optional interface which can be implemented by migrations whose heavy work can be
+// staged ahead of a release (for example, building and backfilling a shadow table) and switched
+// over quickly at deploy time (for example, renaming the shadow table). Prepare() is executed by
+// handler.MigrationsHandler.PrepareUp and Commit(), instead of Up(), by
+// handler.MigrationsHandler.CommitUp. Regular runs still execute Up(), which must apply the whole
+// change (usually, Prepare() followed by Commit()).
+type TwoPhase interface {
+	// Prepare must stage the changes, without affecting the application. It must be safe to call
+	// it again, after a failed or an aborted preparation.
+	Prepare() error
+
+	// Commit must switch over to the staged changes, atomically if possible
+	Commit() error
+
+	// Abort must discard the staged changes. It is called when Prepare() or Commit() fail and
+	// when a preparation is aborted (see handler.MigrationsHandler.AbortPrepared).
+	Abort() error
+}
+
+// SQLRecorder Optional interface which can be implemented by migrations whose Up() changes can
+// be expressed as plain SQL. It allows rendering pending migrations into a SQL script which
+// can be reviewed and executed manually (for example, by a DBA).
+type SQLRecorder interface {
+	// RecordSQL must write to w the SQL statements that Up() would execute, in the order they
+	// would be executed. Each statement should be terminated with a semicolon.
+	RecordSQL(w io.Writer) error
+}
+
+// DownSQLRecorder Optional interface, complementary to SQLRecorder, which can be implemented
+// by migrations whose Down() changes can be expressed as plain SQL.
+type DownSQLRecorder interface {
+	// RecordDownSQL must write to w the SQL statements that Down() would execute, in the order
+	// they would be executed. Each statement should be terminated with a semicolon.
+	RecordDownSQL(w io.Writer) error
+}
+
+// SQLDryRunner Optional interface for database/sql based migrations. It allows swapping the
+// database handle used by the migration, so Up() statements can be captured instead of
+// executed (see the sqlcapture package).
+type SQLDryRunner interface {
+	// WithDB must return a copy of the migration which uses the provided db handle for all
+	// its statements. The original migration must not be changed.
+	WithDB(db *sql.DB) Migration
+}
+
+// LoggerAware Optional interface which can be implemented by migrations which log their
+// progress. Before each Up() or Down() call, the handler sets a logger scoped to the migration
+// (with the version and direction as attributes), so the messages are captured in the run
+// output (see handler.MigrationReport) instead of being printed directly.
+type LoggerAware interface {
+	SetLogger(logger *slog.Logger)
+}
+
+// WorkDirAware Optional interface which can be implemented by migrations which need space for
+// intermediate files (for example, data exports or backfill batches). Before each Up() or Down()
+// call, the handler sets an empty directory created for the call, which is removed, with its
+// contents, after the call, whether it failed or not (see handler.WithWorkDirRoot).
+type WorkDirAware interface {
+	SetWorkDir(dir string)
+}
+
 // DummyMigration struct that should be used only in tests
 type DummyMigration struct {
 	version uint64
@@ -63,6 +184,62 @@ func (dm *DummyMigration) Version() uint64 {
 func (dm *DummyMigration) Up() error   { return nil }
 func (dm *DummyMigration) Down() error { return nil }
 
+// FileName Returns the name of the migration file for the provided version
+func FileName(version uint64) string {
+	return FileNamePrefix + FileNameSeparator + strconv.FormatUint(version, 10) + ".go"
+}
+
+// FindFile Returns the path of the migration file for the provided version, searching the
+// migrations directory and its subdirectories. Errors with os.ErrNotExist if not found.
+func FindFile(dirPath MigrationsDirPath, version uint64) (string, error) {
+	fileName := FileName(version)
+	flatPath := filepath.Join(string(dirPath), fileName)
+	if _, err := os.Stat(flatPath); err == nil {
+		return flatPath, nil
+	}
+
+	found := ""
+	err := filepath.WalkDir(
+		string(dirPath), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && entry.Name() == fileName {
+				found = path
+				return fs.SkipAll
+			}
+			return nil
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	if found == "" {
+		return "", fmt.Errorf("migration file %s: %w", fileName, os.ErrNotExist)
+	}
+
+	return found, nil
+}
+
+// FileChecksum Returns the sha256 (hex encoded) checksum of the migration file for the provided
+// version, from the migrations directory or its subdirectories
+func FileChecksum(dirPath MigrationsDirPath, version uint64) (string, error) {
+	filePath, err := FindFile(dirPath, version)
+	if err != nil {
+		return "", err
+	}
+
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 type migrationTemplateData struct {
 	Version     uint64
 	PackageName string
@@ -79,8 +256,19 @@ var ErrCreateMigrationsDirPath = errors.New("could not create new migrations dir
 // ErrBlankMigration is a generic error for failing to create a blank migration
 var ErrBlankMigration = errors.New("could not generate blank migration")
 
-// NewMigrationsDirPath can be used to create a new MigrationsDirPath
+// NormalizePath Converts both / and \ to the separator of the operating system and cleans the
+// path (see filepath.Clean), so paths configured once (for example, in a config file shared by
+// Linux and Windows agents) resolve to the same directory on all platforms. On Unix-like
+// systems, \ is valid in file names, but it is not supported in migrations directory paths.
+func NormalizePath(path string) string {
+	path = strings.ReplaceAll(path, "\\", "/")
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
+// NewMigrationsDirPath can be used to create a new MigrationsDirPath. The path is normalized
+// (see NormalizePath).
 func NewMigrationsDirPath(dirPath string) (MigrationsDirPath, error) {
+	dirPath = NormalizePath(dirPath)
 	fileInfo, err := os.Stat(dirPath)
 
 	if err != nil {
@@ -98,51 +286,220 @@ func NewMigrationsDirPath(dirPath string) (MigrationsDirPath, error) {
 	return MigrationsDirPath(dirPath), nil
 }
 
-func newMigrationTemplateData(dirPath MigrationsDirPath) migrationTemplateData {
-	return migrationTemplateData{uint64(time.Now().Unix()), filepath.Base(string(dirPath))}
+func newMigrationTemplateData(dirPath MigrationsDirPath, now time.Time) migrationTemplateData {
+	return migrationTemplateData{uint64(now.Unix()), filepath.Base(string(dirPath))}
 }
 
-// GenerateBlankMigration generates a blank migration file in the specified directory
+// maxBlankVersionAttempts How many times the version of a blank migration is bumped when a
+// migration file with the same version already exists
+const maxBlankVersionAttempts = 1000
+
+// GenerateBlankMigration generates a blank migration file in the specified directory.
+// The version is the current unix timestamp in seconds, bumped, if needed, so it does not clash
+// with existing migration files.
 // Returns the generated file name
 // Errors if template processing failed or file creation failed
 func GenerateBlankMigration(dirPath MigrationsDirPath) (fileName string, err error) {
-	tmpl, err := template.New("migration").Parse(TmplContents)
+	return GenerateBlankMigrationWithClock(dirPath, clock.System{})
+}
+
+// GenerateBlankMigrationWithClock Same as GenerateBlankMigration, but the migration version is
+// generated using the provided clock
+func GenerateBlankMigrationWithClock(
+	dirPath MigrationsDirPath,
+	versionClock clock.Clock,
+) (fileName string, err error) {
+	return GenerateBlankMigrationWithOptions(dirPath, BlankOptions{Clock: versionClock})
+}
+
+// BlankLayout Determines where blank migration files are generated, relative to the migrations
+// directory
+type BlankLayout int
+
+const (
+	// FlatLayout All migration files are generated directly in the migrations directory
+	FlatLayout BlankLayout = iota
+
+	// YearMonthLayout Migration files are generated in year/month subdirectories, based on the
+	// migration version (for example, y2024/m06/version_1717200000.go), each subdirectory
+	// being a Go package named after the year and month (for example, y2024m06)
+	YearMonthLayout
+)
+
+// BlankOptions Optional settings for blank migrations generation
+type BlankOptions struct {
+	// Clock Used to generate the migration version. Defaults to clock.System
+	Clock clock.Clock
+
+	// Layout Defaults to FlatLayout
+	Layout BlankLayout
+
+	// FileExtension If set (for example, ".sql"), an empty pair of up and down files is
+	// generated instead of a Go file (see FileMigration). File migrations are always generated
+	// directly in the migrations directory, the Layout is ignored.
+	FileExtension string
+}
 
+// GenerateBlankMigrationWithOptions Same as GenerateBlankMigration, but configurable via
+// BlankOptions. The returned file name is relative to the migrations directory (it includes
+// the subdirectories, if any).
+func GenerateBlankMigrationWithOptions(
+	dirPath MigrationsDirPath,
+	opts BlankOptions,
+) (fileName string, err error) {
+	files, err := blankMigration(dirPath, opts, func(files []BlankFile) error {
+		return writeBlankFiles(dirPath, files)
+	})
 	if err != nil {
-		return "", fmt.Errorf(
-			"%w, template parsing failed with error: %w", ErrBlankMigration, err,
-		)
+		return "", err
 	}
 
-	tmplData := newMigrationTemplateData(dirPath)
-	fileName = FileNamePrefix + FileNameSeparator + strconv.Itoa(int(tmplData.Version)) + ".go"
-	filePath := filepath.Join(string(dirPath), fileName)
+	return files[0].Name, nil
+}
+
+// yearMonthSubDir Returns the YearMonthLayout subdirectory and its package name, for the time
+func yearMonthSubDir(now time.Time) (subDir string, packageName string) {
+	year, month := now.UTC().Year(), int(now.UTC().Month())
+	subDir = filepath.Join(fmt.Sprintf("y%04d", year), fmt.Sprintf("m%02d", month))
+	return subDir, fmt.Sprintf("y%04dm%02d", year, month)
+}
+
+// BlankFile A file which a blank migration generation creates
+type BlankFile struct {
+	// Name The file name, relative to the migrations directory
+	Name     string
+	Contents string
+}
+
+// RenderBlankMigration Returns the files which GenerateBlankMigrationWithOptions would generate,
+// without writing them (for example, to preview them or to pipe them into other tools). The
+// version is bumped past the existing migration files the same way. The migrations directory
+// does not need to exist.
+func RenderBlankMigration(dirPath MigrationsDirPath, opts BlankOptions) ([]BlankFile, error) {
+	return blankMigration(dirPath, opts, func(files []BlankFile) error {
+		for _, file := range files {
+			if _, err := os.Stat(filepath.Join(string(dirPath), file.Name)); err == nil {
+				return os.ErrExist
+			}
+		}
+		return nil
+	})
+}
 
-	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+// blankMigration Renders the blank migration files and passes them to accept. If a migration
+// file with the same version exists (accept fails with os.ErrExist, for example, because a
+// migration was generated in the same second), the version is bumped, at most
+// maxBlankVersionAttempts times. Returns the accepted files.
+func blankMigration(
+	dirPath MigrationsDirPath,
+	opts BlankOptions,
+	accept func(files []BlankFile) error,
+) ([]BlankFile, error) {
+	if opts.Clock == nil {
+		opts.Clock = clock.System{}
+	}
 
+	tmpl, err := template.New("migration").Parse(TmplContents)
 	if err != nil {
-		return "", fmt.Errorf(
-			"%w, file creation failed with error: %w", ErrBlankMigration, err,
+		return nil, fmt.Errorf(
+			"%w, template parsing failed with error: %w", ErrBlankMigration, err,
 		)
 	}
 
-	defer func(file *os.File) {
-		closeErr := file.Close()
+	now := opts.Clock.Now()
+	tmplData := newMigrationTemplateData(dirPath, now)
+	subDir := ""
 
+	// File migrations are always generated directly in the migrations directory
+	if opts.Layout == YearMonthLayout && opts.FileExtension == "" {
+		subDir, tmplData.PackageName = yearMonthSubDir(now)
+	}
+
+	for attempt := 0; attempt < maxBlankVersionAttempts; attempt++ {
+		files, err := renderBlankFiles(tmpl, tmplData, subDir, opts.FileExtension)
 		if err != nil {
-			if removeErr := os.Remove(filePath); removeErr != nil || closeErr != nil {
-				err = errors.Join(err, removeErr, closeErr)
+			return nil, err
+		}
+
+		if err = accept(files); !errors.Is(err, os.ErrExist) {
+			if err != nil {
+				return nil, err
 			}
+			return files, nil
 		}
-	}(file)
+		tmplData.Version++
+	}
+
+	return nil, fmt.Errorf(
+		"%w, no free version found after %d attempts", ErrBlankMigration, maxBlankVersionAttempts,
+	)
+}
 
-	if err = tmpl.Execute(file, tmplData); err != nil {
-		err = fmt.Errorf(
+// renderBlankFiles Renders the blank migration files, for the version of the template data
+func renderBlankFiles(
+	tmpl *template.Template,
+	tmplData migrationTemplateData,
+	subDir string,
+	ext string,
+) ([]BlankFile, error) {
+	if ext != "" {
+		return []BlankFile{
+			{Name: UpFileName(tmplData.Version, ext)}, {Name: DownFileName(tmplData.Version, ext)},
+		}, nil
+	}
+
+	var contents strings.Builder
+	if err := tmpl.Execute(&contents, tmplData); err != nil {
+		return nil, fmt.Errorf(
 			"%w, failed to generate contents with error: %w", ErrBlankMigration, err,
 		)
+	}
 
-		return "", err
+	return []BlankFile{
+		{Name: filepath.Join(subDir, FileName(tmplData.Version)), Contents: contents.String()},
+	}, nil
+}
+
+// writeBlankFiles Creates the blank migration files (and their subdirectories), failing with
+// os.ErrExist if any of them exists. The files created before a failure are removed.
+func writeBlankFiles(dirPath MigrationsDirPath, files []BlankFile) (err error) {
+	var created []string
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, path := range created {
+			if removeErr := os.Remove(path); removeErr != nil {
+				err = errors.Join(err, removeErr)
+			}
+		}
+	}()
+
+	for _, file := range files {
+		path := filepath.Join(string(dirPath), file.Name)
+		if subDir := filepath.Dir(file.Name); subDir != "." {
+			if err = os.MkdirAll(filepath.Join(string(dirPath), subDir), 0755); err != nil {
+				return fmt.Errorf(
+					"%w, subdirectory creation failed with error: %w", ErrBlankMigration, err,
+				)
+			}
+		}
+
+		handle, createErr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if createErr != nil {
+			return fmt.Errorf(
+				"%w, file creation failed with error: %w", ErrBlankMigration, createErr,
+			)
+		}
+		created = append(created, path)
+
+		_, writeErr := handle.WriteString(file.Contents)
+		if err = errors.Join(writeErr, handle.Close()); err != nil {
+			return fmt.Errorf(
+				"%w, failed to write contents with error: %w", ErrBlankMigration, err,
+			)
+		}
 	}
 
-	return fileName, err
+	return nil
 }
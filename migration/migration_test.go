@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rsgcata/go-migrations/clock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -45,6 +46,17 @@ func (suite *MigrationTestSuite) TestItCanCreateNewMigrationsDirPath() {
 	suite.Assert().Equal(suite.migrationsDirPath, string(migDir))
 }
 
+func (suite *MigrationTestSuite) TestItNormalizesMigrationsDirPaths() {
+	windowsStyle := strings.ReplaceAll(suite.migrationsDirPath, "/", "\\") + "\\.\\"
+	migDir, err := NewMigrationsDirPath(windowsStyle)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(filepath.Clean(suite.migrationsDirPath), string(migDir))
+
+	suite.Assert().Equal(
+		filepath.Join("migrations", "app"), NormalizePath("./migrations\\tmp\\..//app/"),
+	)
+}
+
 func (suite *MigrationTestSuite) TestItFailsToCreateNewMigrationsDirPathFromInvalidDirPath() {
 	_, err := NewMigrationsDirPath("+=;.")
 	suite.Assert().ErrorContains(err, "file info init")
@@ -132,3 +144,117 @@ func (suite *MigrationTestSuite) TestItFailsToGenerateBlankMigrationWhenNewFileC
 	expectedErr := &os.PathError{}
 	suite.Assert().ErrorAs(err, &expectedErr)
 }
+
+func (suite *MigrationTestSuite) TestItCanComputeMigrationFileChecksum() {
+	migPath, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	_ = os.WriteFile(filepath.Join(suite.migrationsDirPath, FileName(123)), []byte("abc"), 0600)
+
+	checksum, err := FileChecksum(migPath, 123)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", checksum,
+	)
+
+	_, err = FileChecksum(migPath, 456)
+	suite.Assert().ErrorIs(err, os.ErrNotExist)
+}
+
+func (suite *MigrationTestSuite) TestItCanGenerateBlankMigrationFileWithClock() {
+	migDir, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	fixedClock := clock.NewFixed(time.Unix(1712953083, 0))
+
+	fileName, err := GenerateBlankMigrationWithClock(migDir, fixedClock)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("version_1712953083.go", fileName)
+	suite.Assert().FileExists(filepath.Join(suite.migrationsDirPath, fileName))
+}
+
+func (suite *MigrationTestSuite) TestItBumpsBlankMigrationVersionWhenItAlreadyExists() {
+	migDir, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	fixedClock := clock.NewFixed(time.Unix(1712953083, 0))
+
+	var fileNames []string
+	for i := 0; i < 3; i++ {
+		fileName, err := GenerateBlankMigrationWithClock(migDir, fixedClock)
+		suite.Assert().NoError(err)
+		fileNames = append(fileNames, fileName)
+	}
+
+	suite.Assert().Equal(
+		[]string{"version_1712953083.go", "version_1712953084.go", "version_1712953085.go"},
+		fileNames,
+	)
+
+	fileContents, _ := os.ReadFile(filepath.Join(suite.migrationsDirPath, fileNames[2]))
+	suite.Assert().Contains(string(fileContents), "return 1712953085")
+}
+
+func (suite *MigrationTestSuite) TestItCanRenderBlankMigrationsWithoutWritingThem() {
+	migDir, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	fixedClock := clock.NewFixed(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	_, _ = GenerateBlankMigrationWithClock(migDir, fixedClock)
+
+	files, err := RenderBlankMigration(migDir, BlankOptions{Clock: fixedClock})
+	suite.Require().NoError(err)
+	suite.Require().Len(files, 1)
+	suite.Assert().Equal("version_1717236001.go", files[0].Name)
+	suite.Assert().Contains(files[0].Contents, "package "+filepath.Base(suite.migrationsDirPath))
+	suite.Assert().Contains(files[0].Contents, "return 1717236001")
+	suite.Assert().NoFileExists(filepath.Join(suite.migrationsDirPath, files[0].Name))
+
+	files, err = RenderBlankMigration(
+		migDir, BlankOptions{Clock: fixedClock, Layout: YearMonthLayout},
+	)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(filepath.Join("y2024", "m06", "version_1717236000.go"), files[0].Name)
+	suite.Assert().Contains(files[0].Contents, "package y2024m06")
+	suite.Assert().NoDirExists(filepath.Join(suite.migrationsDirPath, "y2024"))
+
+	files, err = RenderBlankMigration(
+		MigrationsDirPath(filepath.Join(suite.migrationsDirPath, "missing")),
+		BlankOptions{Clock: fixedClock, FileExtension: ".sql"},
+	)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(
+		[]BlankFile{{Name: "version_1717236000.up.sql"}, {Name: "version_1717236000.down.sql"}},
+		files,
+	)
+}
+
+func (suite *MigrationTestSuite) TestItStopsBumpingBlankMigrationVersionsAfterTheMaxAttempts() {
+	migDir, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	fixedClock := clock.NewFixed(time.Unix(1712953083, 0))
+	for i := uint64(0); i < maxBlankVersionAttempts; i++ {
+		_ = os.WriteFile(
+			filepath.Join(suite.migrationsDirPath, FileName(1712953083+i)), []byte{}, 0600,
+		)
+	}
+
+	_, err := GenerateBlankMigrationWithClock(migDir, fixedClock)
+	suite.Assert().ErrorIs(err, ErrBlankMigration)
+	suite.Assert().ErrorContains(err, "no free version found after 1000 attempts")
+
+	_, err = RenderBlankMigration(migDir, BlankOptions{Clock: fixedClock})
+	suite.Assert().ErrorIs(err, ErrBlankMigration)
+	suite.Assert().NoFileExists(filepath.Join(suite.migrationsDirPath, FileName(1712954083)))
+}
+
+func (suite *MigrationTestSuite) TestItCanGenerateBlankMigrationInYearMonthSubdirectory() {
+	migDir, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	fixedClock := clock.NewFixed(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+
+	fileName, err := GenerateBlankMigrationWithOptions(
+		migDir, BlankOptions{Clock: fixedClock, Layout: YearMonthLayout},
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(filepath.Join("y2024", "m06", "version_1717236000.go"), fileName)
+
+	fileContents, _ := os.ReadFile(filepath.Join(suite.migrationsDirPath, fileName))
+	suite.Assert().Contains(string(fileContents), "package y2024m06")
+
+	foundPath, err := FindFile(migDir, 1717236000)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(filepath.Join(suite.migrationsDirPath, fileName), foundPath)
+}
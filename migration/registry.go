@@ -3,11 +3,14 @@ package migration
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // MigrationsRegistry allows implementations to manage a collection of migration files.
@@ -35,6 +38,35 @@ type MigrationsRegistry interface {
 	Count() int
 }
 
+// DisplayNamer Optional interface which can be implemented by registries to provide the names
+// displayed for their migrations (for example, the migration file names). See DisplayName.
+type DisplayNamer interface {
+	// DisplayName must return the name of the migration with the provided version, which may
+	// not be registered (anymore), or an empty string if it is unknown
+	DisplayName(version uint64) string
+}
+
+// DisplayName Returns the name displayed for the migration with the provided version: the name
+// provided by the migration (see Named) or by the registry (see DisplayNamer) or, for migrations
+// built only in code, "migration <version>". The registry can be nil.
+func DisplayName(registry MigrationsRegistry, version uint64) string {
+	if registry != nil {
+		if named, isNamed := registry.Get(version).(Named); isNamed {
+			if name := named.DisplayName(); name != "" {
+				return name
+			}
+		}
+
+		if namer, isNamer := registry.(DisplayNamer); isNamer {
+			if name := namer.DisplayName(version); name != "" {
+				return name
+			}
+		}
+	}
+
+	return "migration " + strconv.FormatUint(version, 10)
+}
+
 // GenericRegistry is a generic implementation for MigrationsRegistry
 type GenericRegistry struct {
 	migrations map[uint64]Migration
@@ -56,6 +88,12 @@ func (registry *GenericRegistry) Register(migration Migration) error {
 	return nil
 }
 
+// RegisterFactory Registers a lazy migration (see NewLazyMigration), so the factory is called
+// only if the migration with the provided version needs to run
+func (registry *GenericRegistry) RegisterFactory(version uint64, factory Factory) error {
+	return registry.Register(NewLazyMigration(version, factory))
+}
+
 func (registry *GenericRegistry) OrderedVersions() []uint64 {
 	var versions []uint64
 	for _, mig := range registry.migrations {
@@ -94,15 +132,45 @@ func (registry *GenericRegistry) Count() int {
 // DirMigrationsRegistry is an implementation of MigrationsRegistry. It will include
 // all migrations available in the specified directory (see struct builder function, there
 // you can specify the used directory).
+// The result of the registration check (see HasAllMigrationsRegistered) is memoized, so
+// processes exposing status endpoints do not read the directory on every request. It is
+// discarded when migrations are registered and can be refreshed with Revalidate.
 type DirMigrationsRegistry struct {
 	GenericRegistry
 	dirPath MigrationsDirPath
+	nested  bool
+
+	mu           sync.Mutex
+	registration *registrationCheck
+}
+
+// registrationCheck The memoized result of HasAllMigrationsRegistered
+type registrationCheck struct {
+	allRegistered bool
+	missing       []string
+	extra         []string
 }
 
 // NewEmptyDirMigrationsRegistry builds an empty migrations registry which can be used
-// for the use case where migrations are saved in a directory.
+// for the use case where migrations are saved in a directory. The directory path is normalized
+// (see NormalizePath), so missing migration files are reported with the same paths on all
+// platforms.
 func NewEmptyDirMigrationsRegistry(dirPath MigrationsDirPath) *DirMigrationsRegistry {
-	return &DirMigrationsRegistry{*NewGenericRegistry(), dirPath}
+	return &DirMigrationsRegistry{
+		GenericRegistry: *NewGenericRegistry(),
+		dirPath:         MigrationsDirPath(NormalizePath(string(dirPath))),
+	}
+}
+
+// NewEmptyNestedDirMigrationsRegistry Same as NewEmptyDirMigrationsRegistry, but migration
+// files are searched also in the subdirectories of the migrations directory (see
+// YearMonthLayout)
+func NewEmptyNestedDirMigrationsRegistry(dirPath MigrationsDirPath) *DirMigrationsRegistry {
+	return &DirMigrationsRegistry{
+		GenericRegistry: *NewGenericRegistry(),
+		dirPath:         MigrationsDirPath(NormalizePath(string(dirPath))),
+		nested:          true,
+	}
 }
 
 // NewDirMigrationsRegistry builds a migrations registry with all migrations available
@@ -112,8 +180,22 @@ func NewDirMigrationsRegistry(
 	dirPath MigrationsDirPath,
 	allMigrations []Migration,
 ) *DirMigrationsRegistry {
-	migRegistry := NewEmptyDirMigrationsRegistry(dirPath)
+	return registerAllAndAssert(NewEmptyDirMigrationsRegistry(dirPath), allMigrations)
+}
+
+// NewNestedDirMigrationsRegistry Same as NewDirMigrationsRegistry, but migration files are
+// searched also in the subdirectories of the migrations directory (see YearMonthLayout)
+func NewNestedDirMigrationsRegistry(
+	dirPath MigrationsDirPath,
+	allMigrations []Migration,
+) *DirMigrationsRegistry {
+	return registerAllAndAssert(NewEmptyNestedDirMigrationsRegistry(dirPath), allMigrations)
+}
 
+func registerAllAndAssert(
+	migRegistry *DirMigrationsRegistry,
+	allMigrations []Migration,
+) *DirMigrationsRegistry {
 	for _, mig := range allMigrations {
 		if regErr := migRegistry.Register(mig); regErr != nil {
 			panic(
@@ -128,15 +210,66 @@ func NewDirMigrationsRegistry(
 	return migRegistry
 }
 
+// DisplayName Returns the name of the migration file. See DisplayNamer.
+func (registry *DirMigrationsRegistry) DisplayName(version uint64) string {
+	return FileName(version)
+}
+
+// Register See MigrationsRegistry. Discards the memoized registration check.
+func (registry *DirMigrationsRegistry) Register(migration Migration) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.registration = nil
+	return registry.GenericRegistry.Register(migration)
+}
+
+// RegisterFactory See GenericRegistry.RegisterFactory
+func (registry *DirMigrationsRegistry) RegisterFactory(version uint64, factory Factory) error {
+	return registry.Register(NewLazyMigration(version, factory))
+}
+
 // HasAllMigrationsRegistered checks if everything from the migrations directory has been
 // registered in the registry.
 // If it returns false, next 2 return values show which file names are missing and which
 // file names are extra, compare to the registered migrations.
 // Errors if reading the directory fails (maybe insufficient permissions?)
+// The directory is read only once, the result is memoized (see Revalidate).
 func (registry *DirMigrationsRegistry) HasAllMigrationsRegistered() (
 	bool, []string, []string, error,
 ) {
-	dirEntries, err := os.ReadDir(string(registry.dirPath))
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.registration == nil {
+		allRegistered, missing, extra, err := registry.checkRegistration()
+		if err != nil {
+			return allRegistered, missing, extra, err
+		}
+		registry.registration = &registrationCheck{allRegistered, missing, extra}
+	}
+
+	check := registry.registration
+	return check.allRegistered, slices.Clone(check.missing), slices.Clone(check.extra), nil
+}
+
+// Revalidate Discards the memoized registration check and checks again, reading the
+// migrations directory (for example, after migration files were added or removed). Errors,
+// instead of panicking like AssertValidRegistry, if the registry has an invalid state.
+func (registry *DirMigrationsRegistry) Revalidate() error {
+	registry.mu.Lock()
+	registry.registration = nil
+	registry.mu.Unlock()
+
+	return registry.validate()
+}
+
+// checkRegistration Reads the migrations directory and compares the migration files with the
+// registered migrations. See HasAllMigrationsRegistered.
+func (registry *DirMigrationsRegistry) checkRegistration() (
+	bool, []string, []string, error,
+) {
+	fileNames, err := registry.migrationFileNames()
 	if err != nil {
 		return false, []string{}, []string{}, fmt.Errorf(
 			"failed to check if all migrations have been registered."+
@@ -150,12 +283,13 @@ func (registry *DirMigrationsRegistry) HasAllMigrationsRegistered() (
 	}
 
 	var missing, extra []string
-	for _, item := range dirEntries {
-		if item.IsDir() || !strings.HasPrefix(item.Name(), FileNamePrefix+FileNameSeparator) {
+	for _, relPath := range fileNames {
+		name := filepath.Base(relPath)
+		if !strings.HasPrefix(name, FileNamePrefix+FileNameSeparator) {
 			continue
 		}
 
-		fname := strings.TrimLeft(item.Name(), FileNamePrefix+FileNameSeparator)
+		fname := strings.TrimLeft(name, FileNamePrefix+FileNameSeparator)
 		version, err := strconv.Atoi(strings.TrimRight(fname, ".go"))
 
 		if err != nil {
@@ -165,7 +299,7 @@ func (registry *DirMigrationsRegistry) HasAllMigrationsRegistered() (
 		if _, ok := registeredCopy[uint64(version)]; ok {
 			delete(registeredCopy, uint64(version))
 		} else {
-			missing = append(missing, item.Name())
+			missing = append(missing, relPath)
 		}
 	}
 
@@ -176,23 +310,67 @@ func (registry *DirMigrationsRegistry) HasAllMigrationsRegistered() (
 	return len(missing) == 0 && len(extra) == 0, missing, extra, nil
 }
 
+// migrationFileNames Returns the paths, relative to the migrations directory, of all files
+// from the migrations directory (and its subdirectories, for nested registries). The paths use
+// forward slashes on all platforms.
+func (registry *DirMigrationsRegistry) migrationFileNames() ([]string, error) {
+	if !registry.nested {
+		dirEntries, err := os.ReadDir(string(registry.dirPath))
+		if err != nil {
+			return nil, err
+		}
+
+		var fileNames []string
+		for _, item := range dirEntries {
+			if !item.IsDir() {
+				fileNames = append(fileNames, item.Name())
+			}
+		}
+		return fileNames, nil
+	}
+
+	var fileNames []string
+	err := filepath.WalkDir(
+		string(registry.dirPath), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+
+			relPath, err := filepath.Rel(string(registry.dirPath), path)
+			if err == nil {
+				fileNames = append(fileNames, filepath.ToSlash(relPath))
+			}
+			return err
+		},
+	)
+
+	return fileNames, err
+}
+
 // AssertValidRegistry checks if there are any issues with the list of registered
 // migrations and panics if it finds any
 func (registry *DirMigrationsRegistry) AssertValidRegistry() {
+	if err := registry.validate(); err != nil {
+		panic(err)
+	}
+}
+
+// validate Errors if there are any issues with the list of registered migrations
+func (registry *DirMigrationsRegistry) validate() error {
 	allRegistered, notRegistered, extraRegistered, registryErr :=
 		registry.HasAllMigrationsRegistered()
 
 	if registryErr != nil {
-		panic(fmt.Errorf("registry has invalid state: %w", registryErr))
+		return fmt.Errorf("registry has invalid state: %w", registryErr)
 	}
 
 	if !allRegistered {
-		panic(
-			fmt.Errorf(
-				"registry has invalid state. Not registered: %s. Extra migrations: %s",
-				strings.Join(notRegistered, ", "),
-				strings.Join(extraRegistered, ", "),
-			),
+		return fmt.Errorf(
+			"registry has invalid state. Not registered: %s. Extra migrations: %s",
+			strings.Join(notRegistered, ", "),
+			strings.Join(extraRegistered, ", "),
 		)
 	}
+
+	return nil
 }
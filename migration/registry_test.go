@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -53,6 +54,30 @@ func (suite *RegistryTestSuite) TestItFailsToRegisterDuplicateMigration() {
 	suite.Assert().ErrorContains(err, "already registered")
 }
 
+func (suite *RegistryTestSuite) TestItCanRegisterMigrationFactory() {
+	calls := 0
+	registry := NewGenericRegistry()
+	err := registry.RegisterFactory(
+		1234, func() (Migration, error) {
+			calls++
+			return NewDummyMigration(1234), nil
+		},
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{1234}, registry.OrderedVersions())
+	suite.Assert().Equal(0, calls)
+	suite.Assert().NoError(registry.Get(1234).Up())
+	suite.Assert().Equal(1, calls)
+	suite.Assert().Error(
+		registry.RegisterFactory(
+			1234, func() (Migration, error) {
+				return NewDummyMigration(1234), nil
+			},
+		),
+	)
+}
+
 func (suite *RegistryTestSuite) TestItCanProvideOrderedRegisteredVersions() {
 	versions := []uint64{123, 124, 125}
 	registry := NewGenericRegistry()
@@ -148,3 +173,99 @@ func (suite *RegistryTestSuite) TestItCanComputeExtraAndMissingRegisteredMigrati
 	suite.Assert().Equal(expectedMissing, missing)
 	suite.Assert().Equal(expectedExtra, extra)
 }
+
+func (suite *RegistryTestSuite) TestItCanValidateNestedDirMigrations() {
+	migDir, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	nestedDir := filepath.Join(suite.migrationsDirPath, "y2024", "m06")
+	_ = os.MkdirAll(nestedDir, 0755)
+	_ = os.WriteFile(filepath.Join(suite.migrationsDirPath, FileName(1)), nil, 0600)
+	_ = os.WriteFile(filepath.Join(nestedDir, FileName(2)), nil, 0600)
+	_ = os.WriteFile(filepath.Join(nestedDir, FileName(3)), nil, 0600)
+
+	flatRegistry := NewEmptyDirMigrationsRegistry(migDir)
+	nestedRegistry := NewEmptyNestedDirMigrationsRegistry(migDir)
+	for _, version := range []uint64{1, 2} {
+		_ = flatRegistry.Register(&DummyMigration{version})
+		_ = nestedRegistry.Register(&DummyMigration{version})
+	}
+
+	allRegistered, missing, extra, err := flatRegistry.HasAllMigrationsRegistered()
+	suite.Assert().NoError(err)
+	suite.Assert().False(allRegistered)
+	suite.Assert().Nil(missing)
+	suite.Assert().Equal([]string{FileName(2)}, extra)
+
+	// The same registry, with a Windows style path, reports the same files
+	nestedRegistry = NewEmptyNestedDirMigrationsRegistry(
+		MigrationsDirPath(strings.ReplaceAll(string(migDir), "/", "\\") + "\\"),
+	)
+	for _, version := range []uint64{1, 2} {
+		_ = nestedRegistry.Register(&DummyMigration{version})
+	}
+
+	allRegistered, missing, extra, err = nestedRegistry.HasAllMigrationsRegistered()
+	suite.Assert().NoError(err)
+	suite.Assert().False(allRegistered)
+	suite.Assert().Equal([]string{"y2024/m06/" + FileName(3)}, missing)
+	suite.Assert().Nil(extra)
+}
+
+func (suite *RegistryTestSuite) TestItMemoizesTheRegistrationCheck() {
+	migDir, _ := NewMigrationsDirPath(suite.migrationsDirPath)
+	dirRegistry := NewEmptyDirMigrationsRegistry(migDir)
+	_ = os.WriteFile(filepath.Join(suite.migrationsDirPath, FileName(1)), nil, 0600)
+	_ = dirRegistry.Register(&DummyMigration{1})
+
+	dirRegistry.AssertValidRegistry()
+	_ = os.WriteFile(filepath.Join(suite.migrationsDirPath, FileName(2)), nil, 0600)
+
+	allRegistered, missing, _, err := dirRegistry.HasAllMigrationsRegistered()
+	suite.Assert().NoError(err)
+	suite.Assert().True(allRegistered)
+	suite.Assert().Nil(missing)
+
+	suite.Assert().ErrorContains(dirRegistry.Revalidate(), "Not registered: "+FileName(2))
+	allRegistered, missing, _, _ = dirRegistry.HasAllMigrationsRegistered()
+	suite.Assert().False(allRegistered)
+	suite.Assert().Equal([]string{FileName(2)}, missing)
+
+	_ = dirRegistry.RegisterFactory(
+		2, func() (Migration, error) { return &DummyMigration{2}, nil },
+	)
+	allRegistered, _, _, _ = dirRegistry.HasAllMigrationsRegistered()
+	suite.Assert().True(allRegistered)
+	suite.Assert().NoError(dirRegistry.Revalidate())
+
+	_ = os.Remove(filepath.Join(suite.migrationsDirPath, FileName(2)))
+	suite.Assert().ErrorContains(dirRegistry.Revalidate(), "Extra migrations: "+FileName(2))
+}
+
+type namedMigration struct {
+	DummyMigration
+	name string
+}
+
+func (m *namedMigration) DisplayName() string {
+	return m.name
+}
+
+func (suite *RegistryTestSuite) TestItCanProvideMigrationDisplayNames() {
+	registry := NewGenericRegistry()
+	_ = registry.Register(NewDummyMigration(1))
+	_ = registry.Register(&namedMigration{*NewDummyMigration(2), "embed/users.sql"})
+	_ = registry.Register(&namedMigration{*NewDummyMigration(3), ""})
+
+	suite.Assert().Equal("migration 1", DisplayName(registry, 1))
+	suite.Assert().Equal("embed/users.sql", DisplayName(registry, 2))
+	suite.Assert().Equal("migration 3", DisplayName(registry, 3))
+	suite.Assert().Equal("migration 4", DisplayName(registry, 4))
+	suite.Assert().Equal("migration 1", DisplayName(nil, 1))
+
+	dirRegistry := NewEmptyDirMigrationsRegistry(MigrationsDirPath(suite.migrationsDirPath))
+	_ = dirRegistry.Register(NewDummyMigration(1))
+	_ = dirRegistry.Register(&namedMigration{*NewDummyMigration(2), "custom"})
+
+	suite.Assert().Equal(FileName(1), DisplayName(dirRegistry, 1))
+	suite.Assert().Equal("custom", DisplayName(dirRegistry, 2))
+	suite.Assert().Equal(FileName(3), DisplayName(dirRegistry, 3))
+}
@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ReleasesFileName The name of the file, from the migrations directory, which maps migrations to
+// the release they belong to, for migrations which do not implement Releaser (for example,
+// file migrations). Each line holds a version followed by the release, for example
+// "1712953083 2024.06". Empty lines and lines starting with # are ignored.
+const ReleasesFileName = "migrations.releases"
+
+// Releases The releases of the migrations, by version
+type Releases map[uint64]string
+
+// Of Returns the release of the migration: the one declared by the migration, if it implements
+// Releaser, or the mapped one. Empty if the migration does not belong to a release.
+func (releases Releases) Of(mig Migration) string {
+	if releaser, isReleaser := mig.(Releaser); isReleaser && releaser.Release() != "" {
+		return releaser.Release()
+	}
+	return releases[mig.Version()]
+}
+
+// ReadReleases Returns the releases recorded in the migrations directory. The releases are empty
+// if there is no releases file.
+func ReadReleases(dirPath MigrationsDirPath) (Releases, error) {
+	file, err := os.Open(filepath.Join(string(dirPath), ReleasesFileName))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return Releases{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return ParseReleases(file)
+}
+
+// ParseReleases Parses the releases file lines (see ReleasesFileName for the format)
+func ParseReleases(r io.Reader) (Releases, error) {
+	releases := Releases{}
+	lines := bufio.NewScanner(r)
+
+	for lineNum := 1; lines.Scan(); lineNum++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		version, release, _ := strings.Cut(line, " ")
+		parsedVersion, err := strconv.ParseUint(version, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid releases file version %q at line %d: %w", version, lineNum, err,
+			)
+		}
+
+		release = strings.TrimSpace(release)
+		if release == "" {
+			return nil, fmt.Errorf(
+				"missing release for version %d at line %d", parsedVersion, lineNum,
+			)
+		}
+		releases[parsedVersion] = release
+	}
+
+	return releases, lines.Err()
+}
@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReleasesTestSuite struct {
+	suite.Suite
+}
+
+func TestReleasesTestSuite(t *testing.T) {
+	suite.Run(t, new(ReleasesTestSuite))
+}
+
+type ReleasedMigration struct {
+	DummyMigration
+	release string
+}
+
+func (m *ReleasedMigration) Release() string {
+	return m.release
+}
+
+func (suite *ReleasesTestSuite) TestItCanReadReleases() {
+	migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+
+	releases, err := ReadReleases(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(releases)
+
+	contents := "# staged schema changes\n\n1712953083 2024.06\n  1712953090   2024.07 \n"
+	_ = os.WriteFile(filepath.Join(string(migDir), ReleasesFileName), []byte(contents), 0600)
+
+	releases, err = ReadReleases(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(Releases{1712953083: "2024.06", 1712953090: "2024.07"}, releases)
+}
+
+func (suite *ReleasesTestSuite) TestItFailsToReadInvalidReleases() {
+	scenarios := map[string]struct {
+		contents string
+		expected string
+	}{
+		"invalid version": {"1712953083 2024.06\nabc 2024.07\n", "invalid releases file version"},
+		"missing release": {"1712953083\n", "missing release for version 1712953083 at line 1"},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Run(name, func() {
+			migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+			_ = os.WriteFile(
+				filepath.Join(string(migDir), ReleasesFileName), []byte(scenario.contents), 0600,
+			)
+
+			_, err := ReadReleases(migDir)
+
+			suite.Assert().ErrorContains(err, scenario.expected)
+		})
+	}
+}
+
+func (suite *ReleasesTestSuite) TestItPrefersTheReleaseDeclaredByTheMigration() {
+	releases := Releases{1: "2024.06", 2: "2024.06"}
+
+	suite.Assert().Equal("2024.06", releases.Of(NewDummyMigration(1)))
+	suite.Assert().Equal(
+		"2024.07", releases.Of(&ReleasedMigration{*NewDummyMigration(2), "2024.07"}),
+	)
+	suite.Assert().Equal("2024.06", releases.Of(&ReleasedMigration{*NewDummyMigration(2), ""}))
+	suite.Assert().Empty(releases.Of(NewDummyMigration(3)))
+}
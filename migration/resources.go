@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ContextAware Optional interface which can be implemented by migrations which need the context
+// of the run, for example, to cancel long queries or to retrieve the shared resources (see
+// WithResources). The handler sets the context (see handler.WithContext and
+// handler.WithResources) before the migrations of a run are validated and executed.
+type ContextAware interface {
+	SetContext(ctx context.Context)
+}
+
+// Key Identifies a shared resource of type T (for example, a database handle or an API client)
+// in a context. Keys are compared by identity, so each key should be created once, with NewKey,
+// and stored in a package variable, next to the migrations which use it.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey Creates a new resource key. The name is only used in error messages.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// DB The key of the *sql.DB handle shared by database/sql based migrations
+var DB = NewKey[*sql.DB]("db")
+
+// Resource A shared resource bound to its key (see Key.Provide)
+type Resource struct {
+	key   any
+	value any
+}
+
+// Provide Binds the value to the key, so it can be added to a context with WithResources
+func (key *Key[T]) Provide(value T) Resource {
+	return Resource{key: key, value: value}
+}
+
+// From Returns the resource from the context, if it was added (see WithResources)
+func (key *Key[T]) From(ctx context.Context) (T, bool) {
+	value, found := ctx.Value(key).(T)
+	return value, found
+}
+
+// Get Same as From, but fails if the resource was not added to the context, for example, in
+// Validate(), so misconfigured runs stop before any migration is executed
+func (key *Key[T]) Get(ctx context.Context) (T, error) {
+	value, found := key.From(ctx)
+	if !found {
+		return value, fmt.Errorf("the %s resource is missing from the migration context", key.name)
+	}
+	return value, nil
+}
+
+// WithResources Returns a copy of the context which holds the resources, so context-aware
+// migrations (see ContextAware) can retrieve them with their typed keys, instead of each
+// migration struct holding its own dependencies. For example:
+//
+//	ctx := migration.WithResources(context.Background(), migration.DB.Provide(db))
+//	handler.NewHandler(registry, repo, nil, handler.WithContext(ctx))
+//
+// and, in a migration:
+//
+//	db, err := migration.DB.Get(m.ctx)
+func WithResources(ctx context.Context, resources ...Resource) context.Context {
+	for _, resource := range resources {
+		ctx = context.WithValue(ctx, resource.key, resource.value)
+	}
+	return ctx
+}
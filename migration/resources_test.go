@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ResourcesTestSuite struct {
+	suite.Suite
+}
+
+func TestResourcesTestSuite(t *testing.T) {
+	suite.Run(t, new(ResourcesTestSuite))
+}
+
+type apiClient struct {
+	baseURL string
+}
+
+func (suite *ResourcesTestSuite) TestItRetrievesTheResourcesByTheirTypedKeys() {
+	client := NewKey[*apiClient]("api client")
+	db := &sql.DB{}
+	ctx := WithResources(
+		context.Background(), DB.Provide(db), client.Provide(&apiClient{"https://api"}),
+	)
+
+	found, err := DB.Get(ctx)
+	suite.Assert().NoError(err)
+	suite.Assert().Same(db, found)
+
+	api, ok := client.From(ctx)
+	suite.Assert().True(ok)
+	suite.Assert().Equal("https://api", api.baseURL)
+
+	// Keys are compared by identity, not by name or type
+	other := NewKey[*apiClient]("api client")
+	_, ok = other.From(ctx)
+	suite.Assert().False(ok)
+	_, err = other.Get(ctx)
+	suite.Assert().EqualError(err, "the api client resource is missing from the migration context")
+}
@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SkipListFileName The name of the file, from the migrations directory, which lists the
+// migrations which must not be executed (for example, known-bad migrations which were
+// superseded, but must remain in the history). Each line holds a version followed by the skip
+// reason, for example "1712953083 superseded by 1712953099". Empty lines and lines starting
+// with # are ignored.
+const SkipListFileName = "migrations.skip"
+
+// SkipList The versions of the migrations which must not be executed, with their skip reasons
+type SkipList map[uint64]string
+
+// ReadSkipList Returns the skip list recorded in the migrations directory. The list is empty
+// if there is no skip list file.
+func ReadSkipList(dirPath MigrationsDirPath) (SkipList, error) {
+	file, err := os.Open(filepath.Join(string(dirPath), SkipListFileName))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return SkipList{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return ParseSkipList(file)
+}
+
+// ParseSkipList Parses the skip list lines (see SkipListFileName for the format). Versions
+// without a reason are skipped with the "skip list" reason.
+func ParseSkipList(r io.Reader) (SkipList, error) {
+	skipList := SkipList{}
+	lines := bufio.NewScanner(r)
+
+	for lineNum := 1; lines.Scan(); lineNum++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		version, reason, _ := strings.Cut(line, " ")
+		parsedVersion, err := strconv.ParseUint(version, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid skip list version %q at line %d: %w", version, lineNum, err,
+			)
+		}
+
+		reason = strings.TrimSpace(reason)
+		if reason == "" {
+			reason = "skip list"
+		}
+		skipList[parsedVersion] = reason
+	}
+
+	return skipList, lines.Err()
+}
@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SkipListTestSuite struct {
+	suite.Suite
+}
+
+func TestSkipListTestSuite(t *testing.T) {
+	suite.Run(t, new(SkipListTestSuite))
+}
+
+func (suite *SkipListTestSuite) TestItCanReadSkipList() {
+	migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+
+	skipList, err := ReadSkipList(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(skipList)
+
+	contents := "# known-bad migrations\n\n1712953083 superseded by 1712953099\n  1712953090  \n"
+	_ = os.WriteFile(filepath.Join(string(migDir), SkipListFileName), []byte(contents), 0600)
+
+	skipList, err = ReadSkipList(migDir)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		SkipList{1712953083: "superseded by 1712953099", 1712953090: "skip list"}, skipList,
+	)
+}
+
+func (suite *SkipListTestSuite) TestItFailsToReadInvalidSkipList() {
+	migDir, _ := NewMigrationsDirPath(suite.T().TempDir())
+	contents := "1712953083 superseded\nabc broken\n"
+	_ = os.WriteFile(filepath.Join(string(migDir), SkipListFileName), []byte(contents), 0600)
+
+	_, err := ReadSkipList(migDir)
+
+	suite.Assert().ErrorContains(err, "invalid skip list version \"abc\" at line 2")
+}
@@ -0,0 +1,144 @@
+// Package migrations includes Migrator, a high level entry point for programs which run
+// migrations without the CLI (for example, on application startup or from a deploy job).
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// Migrator Facade over handler.MigrationsHandler. Contexts are checked between migrations:
+// when the context is done, no new migration is started, but a running migration is not
+// interrupted (migrations should use their own contexts for that).
+type Migrator struct {
+	handler  *handler.MigrationsHandler
+	registry migration.MigrationsRegistry
+}
+
+// New Creates a new Migrator. Initializes the repository (see execution.Repository Init)
+func New(
+	registry migration.MigrationsRegistry,
+	repository execution.Repository,
+	options ...handler.Option,
+) (*Migrator, error) {
+	migrationsHandler, err := handler.NewHandler(registry, repository, nil, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{handler: migrationsHandler, registry: registry}, nil
+}
+
+// Handler Returns the underlying handler, for the features not exposed by the Migrator
+func (m *Migrator) Handler() *handler.MigrationsHandler {
+	return m.handler
+}
+
+// Up Executes the next steps pending migrations, or all of them if steps is lower than 1
+func (m *Migrator) Up(ctx context.Context, steps int) ([]handler.ExecutedMigration, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	executed, err := m.handler.MigrateUpInteractive(
+		numOfRuns(steps), func(migration.Migration) handler.Decision {
+			if ctx.Err() != nil {
+				return handler.DecisionAbort
+			}
+			return handler.DecisionApprove
+		},
+	)
+
+	if errors.Is(err, handler.ErrRunAborted) {
+		err = errors.Join(err, ctx.Err())
+	}
+
+	return executed, err
+}
+
+// Down Rolls back the last steps executed migrations, or all of them if steps is lower than 1
+func (m *Migrator) Down(ctx context.Context, steps int) ([]handler.ExecutedMigration, error) {
+	var rolledBack []handler.ExecutedMigration
+
+	for i := 0; i < int(numOfRuns(steps)); i++ {
+		if err := ctx.Err(); err != nil {
+			return rolledBack, err
+		}
+
+		handled, err := m.handler.MigrateDown(1)
+		rolledBack = append(rolledBack, handled...)
+
+		if err != nil || len(handled) == 0 {
+			return rolledBack, err
+		}
+	}
+
+	return rolledBack, nil
+}
+
+// To Executes or rolls back migrations until the provided version is the last executed one.
+// Version 0 rolls back all migrations.
+func (m *Migrator) To(ctx context.Context, version uint64) ([]handler.ExecutedMigration, error) {
+	if version != 0 && m.registry.Get(version) == nil {
+		return nil, fmt.Errorf(
+			"failed to migrate to version %d, not a registered migration", version,
+		)
+	}
+
+	plan, err := m.handler.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	toRollBack := 0
+	for _, executed := range plan.AllExecuted() {
+		if executed.Migration.Version() > version {
+			toRollBack++
+		}
+	}
+
+	if toRollBack > 0 {
+		return m.Down(ctx, toRollBack)
+	}
+
+	toExecute := 0
+	for _, mig := range plan.AllToBeExecuted() {
+		if mig.Version() <= version {
+			toExecute++
+		}
+	}
+
+	if toExecute > 0 {
+		return m.Up(ctx, toExecute)
+	}
+
+	return []handler.ExecutedMigration{}, nil
+}
+
+// Status Returns the current migrations & executions state
+func (m *Migrator) Status(ctx context.Context) (handler.Summary, error) {
+	if err := ctx.Err(); err != nil {
+		return handler.Summary{}, err
+	}
+	return m.handler.Summary()
+}
+
+// Plan Returns the current execution plan, with all executed and pending migrations
+func (m *Migrator) Plan(ctx context.Context) (*handler.ExecutionPlan, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.handler.Plan()
+}
+
+func numOfRuns(steps int) handler.NumOfRuns {
+	if steps < 1 {
+		return handler.AllRuns
+	}
+	return handler.NumOfRuns(steps)
+}
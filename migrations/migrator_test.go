@@ -0,0 +1,130 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type MigratorTestSuite struct {
+	suite.Suite
+}
+
+func TestMigratorTestSuite(t *testing.T) {
+	suite.Run(t, new(MigratorTestSuite))
+}
+
+func (suite *MigratorTestSuite) newMigrator() (*Migrator, *execution.InMemoryRepository) {
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 4; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+	repo := &execution.InMemoryRepository{}
+	migrator, err := New(registry, repo)
+	suite.Require().NoError(err)
+	return migrator, repo
+}
+
+func executedVersions(repo *execution.InMemoryRepository) []uint64 {
+	var versions []uint64
+	for _, exec := range repo.PersistedExecutions {
+		versions = append(versions, exec.Version)
+	}
+	return versions
+}
+
+func (suite *MigratorTestSuite) TestItCanMigrateUpAndDown() {
+	migrator, repo := suite.newMigrator()
+	ctx := context.Background()
+
+	executed, err := migrator.Up(ctx, 2)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(executed, 2)
+
+	executed, err = migrator.Up(ctx, 0)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(executed, 2)
+	suite.Assert().Equal([]uint64{1, 2, 3, 4}, executedVersions(repo))
+
+	rolledBack, err := migrator.Down(ctx, 3)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(rolledBack, 3)
+	suite.Assert().Equal([]uint64{1}, executedVersions(repo))
+
+	status, err := migrator.Status(ctx)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(1, status.FinishedCount)
+	suite.Assert().Equal(uint64(2), status.NextToExecute.Version())
+
+	plan, err := migrator.Plan(ctx)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(plan.AllToBeExecuted(), 3)
+}
+
+func (suite *MigratorTestSuite) TestItCanMigrateToVersion() {
+	migrator, repo := suite.newMigrator()
+	ctx := context.Background()
+
+	_, err := migrator.To(ctx, 3)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{1, 2, 3}, executedVersions(repo))
+
+	_, err = migrator.To(ctx, 1)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]uint64{1}, executedVersions(repo))
+
+	_, err = migrator.To(ctx, 0)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(executedVersions(repo))
+
+	_, err = migrator.To(ctx, 7)
+	suite.Assert().ErrorContains(err, "not a registered migration")
+}
+
+func (suite *MigratorTestSuite) TestItDoesNotStartMigrationsWhenContextIsDone() {
+	migrator, repo := suite.newMigrator()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := migrator.Up(ctx, 0)
+	suite.Assert().ErrorIs(err, context.Canceled)
+
+	_, err = migrator.Status(ctx)
+	suite.Assert().ErrorIs(err, context.Canceled)
+	suite.Assert().Empty(repo.PersistedExecutions)
+
+	_, _ = migrator.Up(context.Background(), 0)
+	_, err = migrator.Down(ctx, 0)
+	suite.Assert().ErrorIs(err, context.Canceled)
+	suite.Assert().Len(repo.PersistedExecutions, 4)
+}
+
+func (suite *MigratorTestSuite) TestItStopsBetweenMigrationsWhenContextIsCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(&cancellingMigration{*migration.NewDummyMigration(1), cancel})
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{}
+	migrator, _ := New(registry, repo)
+
+	executed, err := migrator.Up(ctx, 0)
+
+	suite.Assert().ErrorIs(err, context.Canceled)
+	suite.Assert().ErrorIs(err, handler.ErrRunAborted)
+	suite.Assert().Len(executed, 1)
+	suite.Assert().Equal([]uint64{1}, executedVersions(repo))
+}
+
+type cancellingMigration struct {
+	migration.DummyMigration
+	cancel context.CancelFunc
+}
+
+func (c *cancellingMigration) Up() error {
+	c.cancel()
+	return nil
+}
@@ -0,0 +1,40 @@
+package mongohelpers
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RunCommands Runs, one by one, the database commands from the script, which must be an
+// extended JSON array of command documents, for example
+// [{"createIndexes": "users", "indexes": [{"key": {"email": 1}, "name": "email_1"}]}]
+func RunCommands(ctx context.Context, db *mongo.Database, script string) error {
+	commands, err := ParseCommands(script)
+	if err != nil {
+		return err
+	}
+
+	for i, command := range commands {
+		if err = db.RunCommand(ctx, command).Err(); err != nil {
+			return fmt.Errorf("command %d failed with error: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ParseCommands Parses the extended JSON array of command documents. The order of the fields
+// of each document is kept (the command name must be the first field).
+func ParseCommands(script string) ([]bson.Raw, error) {
+	var wrapper struct {
+		Commands []bson.Raw `bson:"commands"`
+	}
+
+	err := bson.UnmarshalExtJSON([]byte(`{"commands": `+script+`}`), false, &wrapper)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commands script, it must be a JSON array: %w", err)
+	}
+	return wrapper.Commands, nil
+}
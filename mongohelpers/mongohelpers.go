@@ -0,0 +1,121 @@
+// Package mongohelpers includes idempotent MongoDb schema helpers, which can be used from
+// Migration implementations (Up and Down), so migrations can be retried after a failure: a
+// collection rename, index ensure/drop and chunked document transforms, which resume from the
+// last transformed document.
+package mongohelpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCollectionNotFound is returned when the collection to rename does not exist (and it was
+// not renamed already)
+var ErrCollectionNotFound = errors.New("collection not found")
+
+// RenameCollection Renames the collection from the database. It is a no-op if the collection
+// was already renamed (from does not exist, but to does). Fails if both collections exist.
+func RenameCollection(ctx context.Context, db *mongo.Database, from, to string) error {
+	errMsg := fmt.Sprintf("failed to rename collection %s to %s", from, to)
+
+	names, err := db.ListCollectionNames(ctx, bson.D{{"name", bson.D{{"$in", bson.A{from, to}}}}})
+	if err != nil {
+		return fmt.Errorf("%s, failed to list collections with error: %w", errMsg, err)
+	}
+
+	fromExists, toExists := contains(names, from), contains(names, to)
+	switch {
+	case fromExists && toExists:
+		return fmt.Errorf("%s, both collections exist", errMsg)
+	case !fromExists && toExists:
+		return nil
+	case !fromExists:
+		return fmt.Errorf("%s, %w", errMsg, ErrCollectionNotFound)
+	}
+
+	err = db.Client().Database("admin").RunCommand(
+		ctx, bson.D{
+			{"renameCollection", db.Name() + "." + from},
+			{"to", db.Name() + "." + to},
+		},
+	).Err()
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// EnsureIndex Creates the index, if the collection does not have an index with the same name.
+// The index model must have a name (options.Index().SetName), so it can be found and dropped
+// by later migrations.
+func EnsureIndex(ctx context.Context, coll *mongo.Collection, model mongo.IndexModel) error {
+	if model.Options == nil || model.Options.Name == nil || *model.Options.Name == "" {
+		return fmt.Errorf("failed to ensure index on %s, the index has no name", coll.Name())
+	}
+
+	name := *model.Options.Name
+	errMsg := fmt.Sprintf("failed to ensure index %s on %s", name, coll.Name())
+
+	exists, err := indexExists(ctx, coll, name)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	} else if exists {
+		return nil
+	}
+
+	if _, err = coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// DropIndex Drops the index with the provided name. It is a no-op if the index does not exist.
+func DropIndex(ctx context.Context, coll *mongo.Collection, name string) error {
+	errMsg := fmt.Sprintf("failed to drop index %s on %s", name, coll.Name())
+
+	exists, err := indexExists(ctx, coll, name)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	} else if !exists {
+		return nil
+	}
+
+	if _, err = coll.Indexes().DropOne(ctx, name); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// indexExists Checks if the collection has an index with the provided name. A missing
+// collection has no indexes.
+func indexExists(ctx context.Context, coll *mongo.Collection, name string) (bool, error) {
+	specs, err := coll.Indexes().ListSpecifications(ctx, options.ListIndexes())
+	if err != nil {
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceNotFound" {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, spec := range specs {
+		if spec.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package mongohelpers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoHelpersTestSuite struct {
+	suite.Suite
+	client *mongo.Client
+	db     *mongo.Database
+}
+
+func TestMongoHelpersTestSuite(t *testing.T) {
+	suite.Run(t, new(MongoHelpersTestSuite))
+}
+
+type progressRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryProgressStore
+}
+
+func (suite *MongoHelpersTestSuite) SetupSuite() {
+	dbName := os.Getenv("MONGO_DATABASE")
+	dsn := os.Getenv("MONGO_DSN")
+
+	if dbName == "" {
+		// Needed if tests are ran on the host not docker
+		dbName = "migrations"
+	}
+
+	if dsn == "" {
+		// Needed if tests are ran on the host not docker
+		dsn = "mongodb://localhost:27017"
+	}
+
+	opts := options.Client().ApplyURI(dsn)
+	opts.SetConnectTimeout(3 * time.Second)
+	opts.SetServerSelectionTimeout(3 * time.Second)
+	opts.SetTimeout(5 * time.Second)
+	suite.client, _ = mongo.Connect(context.Background(), opts)
+	suite.db = suite.client.Database(dbName + "_helpers")
+}
+
+func (suite *MongoHelpersTestSuite) TearDownTest() {
+	_ = suite.db.Drop(context.Background())
+}
+
+func (suite *MongoHelpersTestSuite) TestItCanRenameCollectionsIdempotently() {
+	ctx := context.Background()
+	_, _ = suite.db.Collection("users").InsertOne(ctx, bson.D{{"email", "a@b.c"}})
+
+	suite.Assert().NoError(RenameCollection(ctx, suite.db, "users", "accounts"))
+	suite.Assert().NoError(RenameCollection(ctx, suite.db, "users", "accounts"))
+
+	count, _ := suite.db.Collection("accounts").CountDocuments(ctx, bson.D{})
+	suite.Assert().Equal(int64(1), count)
+	suite.Assert().ErrorIs(
+		RenameCollection(ctx, suite.db, "missing", "other"), ErrCollectionNotFound,
+	)
+
+	_, _ = suite.db.Collection("users").InsertOne(ctx, bson.D{{"email", "a@b.c"}})
+	suite.Assert().ErrorContains(
+		RenameCollection(ctx, suite.db, "users", "accounts"), "both collections exist",
+	)
+}
+
+func (suite *MongoHelpersTestSuite) TestItCanEnsureAndDropIndexesIdempotently() {
+	ctx := context.Background()
+	coll := suite.db.Collection("users")
+	model := mongo.IndexModel{
+		Keys:    bson.D{{"email", 1}},
+		Options: options.Index().SetName("email_unique").SetUnique(true),
+	}
+
+	suite.Assert().NoError(EnsureIndex(ctx, coll, model))
+	suite.Assert().NoError(EnsureIndex(ctx, coll, model))
+	exists, _ := indexExists(ctx, coll, "email_unique")
+	suite.Assert().True(exists)
+
+	suite.Assert().NoError(DropIndex(ctx, coll, "email_unique"))
+	suite.Assert().NoError(DropIndex(ctx, coll, "email_unique"))
+	exists, _ = indexExists(ctx, coll, "email_unique")
+	suite.Assert().False(exists)
+
+	suite.Assert().NoError(DropIndex(ctx, suite.db.Collection("missing"), "email_unique"))
+	suite.Assert().ErrorContains(
+		EnsureIndex(ctx, coll, mongo.IndexModel{Keys: bson.D{{"email", 1}}}), "has no name",
+	)
+}
+
+func (suite *MongoHelpersTestSuite) TestItCanTransformDocumentsInResumableBatches() {
+	ctx := context.Background()
+	coll := suite.db.Collection("users")
+	for i := 1; i <= 5; i++ {
+		_, _ = coll.InsertOne(ctx, bson.D{{"_id", i}, {"phone", "123"}})
+	}
+
+	repo := &progressRepository{}
+	transformErr := errors.New("transform failed")
+	calls := 0
+	transform := func(doc bson.Raw) (interface{}, error) {
+		calls++
+		if calls == 4 {
+			return nil, transformErr
+		}
+		return bson.D{{"$set", bson.D{{"phoneNumber", doc.Lookup("phone").StringValue()}}}}, nil
+	}
+
+	runner, err := NewTransformRunner("users_phone", repo, coll, nil, transform)
+	suite.Require().NoError(err)
+	runner.BatchSize = 2
+
+	// The third batch fails, the first two are persisted
+	suite.Assert().ErrorIs(runner.Run(ctx), transformErr)
+	cursor, done, _ := runner.Progress()
+	suite.Assert().False(done)
+	suite.Assert().Equal(int64(2), cursor.Offset)
+
+	suite.Assert().NoError(runner.Run(ctx))
+	cursor, done, _ = runner.Progress()
+	suite.Assert().True(done)
+	suite.Assert().Equal(int64(5), cursor.Offset)
+
+	count, _ := coll.CountDocuments(ctx, bson.D{{"phoneNumber", "123"}})
+	suite.Assert().Equal(int64(5), count)
+}
+
+func (suite *MongoHelpersTestSuite) TestItKeepsTheIdTypeInResumeTokens() {
+	objectID := primitive.NewObjectID()
+	_, rawID, _ := bson.MarshalValue(objectID)
+
+	key, err := encodeKey(bson.RawValue{Type: bson.TypeObjectID, Value: rawID})
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(`{"_id":{"$oid":"`+objectID.Hex()+`"}}`, key)
+
+	decoded, err := decodeKey(key)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(objectID, decoded.ObjectID())
+
+	_, err = decodeKey("not json")
+	suite.Assert().ErrorContains(err, "invalid resume token")
+}
+
+func (suite *MongoHelpersTestSuite) TestItCanParseCommandScripts() {
+	commands, err := ParseCommands(
+		`[{"insert": "users", "documents": [{"_id": {"$numberLong": "1"}}]}, {"drop": "users"}]`,
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Len(commands, 2)
+	suite.Assert().Equal("insert", commands[0].Index(0).Key())
+	suite.Assert().Equal(
+		bson.TypeInt64, commands[0].Lookup("documents", "0", "_id").Type,
+	)
+	suite.Assert().Equal("drop", commands[1].Index(0).Key())
+
+	_, err = ParseCommands(`{"drop": "users"}`)
+	suite.Assert().ErrorContains(err, "it must be a JSON array")
+}
+
+func (suite *MongoHelpersTestSuite) TestItCanRunCommandScripts() {
+	ctx := context.Background()
+
+	err := RunCommands(
+		ctx, suite.db, `[{"create": "users"}, {"insert": "users", "documents": [{"_id": 1}]}]`,
+	)
+
+	suite.Assert().NoError(err)
+	count, _ := suite.db.Collection("users").CountDocuments(ctx, bson.D{})
+	suite.Assert().Equal(int64(1), count)
+}
@@ -0,0 +1,124 @@
+package mongohelpers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/online/backfill"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Transform Must return the update document (for example, bson.D{{"$set", ...}}) for the
+// provided document, or nil, if the document must not be changed. Since batches interrupted
+// by a crash are transformed again, the transform must be idempotent.
+type Transform func(doc bson.Raw) (update interface{}, err error)
+
+// NewTransformRunner Creates a backfill.Runner which applies the transform to the documents of
+// the collection matching the filter (nil for all documents), in batches ordered by _id. The
+// _id of the last transformed document is the resume token, persisted through the migrations
+// repository under key, so a crashed transform resumes from the last batch. Fails with
+// backfill.ErrNotProgressStore if the repository does not implement execution.ProgressStore.
+func NewTransformRunner(
+	key string,
+	repository execution.Repository,
+	coll *mongo.Collection,
+	filter interface{},
+	transform Transform,
+) (*backfill.Runner, error) {
+	return backfill.NewRunner(
+		key, repository, func(ctx context.Context, batch backfill.Batch) (backfill.Result, error) {
+			return transformBatch(ctx, coll, filter, transform, batch)
+		},
+	)
+}
+
+func transformBatch(
+	ctx context.Context,
+	coll *mongo.Collection,
+	filter interface{},
+	transform Transform,
+	batch backfill.Batch,
+) (backfill.Result, error) {
+	batchFilter, err := afterKeyFilter(filter, batch.LastKey)
+	if err != nil {
+		return backfill.Result{}, err
+	}
+
+	cursor, err := coll.Find(
+		ctx, batchFilter,
+		options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(int64(batch.Limit)),
+	)
+	if err != nil {
+		return backfill.Result{}, err
+	}
+
+	var docs []bson.Raw
+	if err = cursor.All(ctx, &docs); err != nil {
+		return backfill.Result{}, err
+	}
+
+	var writes []mongo.WriteModel
+	for _, doc := range docs {
+		update, err := transform(doc)
+		if err != nil {
+			return backfill.Result{}, fmt.Errorf(
+				"failed to transform document %s with error: %w", doc.Lookup("_id"), err,
+			)
+		} else if update != nil {
+			writes = append(
+				writes,
+				mongo.NewUpdateOneModel().
+					SetFilter(bson.D{{"_id", doc.Lookup("_id")}}).
+					SetUpdate(update),
+			)
+		}
+	}
+
+	if len(writes) > 0 {
+		if _, err = coll.BulkWrite(ctx, writes); err != nil {
+			return backfill.Result{}, err
+		}
+	}
+
+	result := backfill.Result{Processed: len(docs)}
+	if len(docs) > 0 {
+		result.LastKey, err = encodeKey(docs[len(docs)-1].Lookup("_id"))
+	}
+	return result, err
+}
+
+// afterKeyFilter Restricts the filter to the documents after the last transformed _id
+func afterKeyFilter(filter interface{}, lastKey string) (interface{}, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if lastKey == "" {
+		return filter, nil
+	}
+
+	lastID, err := decodeKey(lastKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.D{{"$and", bson.A{filter, bson.D{{"_id", bson.D{{"$gt", lastID}}}}}}}, nil
+}
+
+// encodeKey Encodes the _id as canonical extended JSON, so its type is kept in the token
+func encodeKey(id bson.RawValue) (string, error) {
+	key, err := bson.MarshalExtJSON(bson.D{{"_id", id}}, true, false)
+	return string(key), err
+}
+
+func decodeKey(key string) (bson.RawValue, error) {
+	var doc struct {
+		ID bson.RawValue `bson:"_id"`
+	}
+	if err := bson.UnmarshalExtJSON([]byte(key), true, &doc); err != nil {
+		return bson.RawValue{}, fmt.Errorf("invalid resume token %q: %w", key, err)
+	}
+	return doc.ID, nil
+}
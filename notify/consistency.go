@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+)
+
+// InconsistencyAlert The JSON payload posted by the webhook when the executions are inconsistent
+// with the registered migrations (see Webhook.ReportInconsistency)
+type InconsistencyAlert struct {
+	Kind handler.InconsistencyKind `json:"kind"`
+	// Version The version of the execution the inconsistency was detected at, 0 for
+	// handler.InconsistencyExtraExecutions
+	Version     uint64                `json:"version"`
+	Message     string                `json:"message"`
+	Environment string                `json:"environment,omitempty"`
+	Run         execution.RunMetadata `json:"run"`
+}
+
+// ReportInconsistency See handler.ConsistencyMonitor. Posts an InconsistencyAlert to the webhook
+// endpoint, so the webhook can be used with handler.WithConsistencyMonitor, for example:
+//
+//	handler.WithConsistencyMonitor(notify.NewWebhook(url, notify.WithSecret(secret)))
+//
+// The requests are signed and retried the same way as the run reports.
+func (webhook *Webhook) ReportInconsistency(inconsistency handler.Inconsistency) error {
+	body, err := json.Marshal(
+		InconsistencyAlert{
+			Kind:        inconsistency.Kind,
+			Version:     inconsistency.Version,
+			Message:     inconsistency.Error(),
+			Environment: inconsistency.Environment,
+			Run:         inconsistency.Run,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to encode the inconsistency alert with error: %w", err)
+	}
+
+	return webhook.send(body)
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConsistencyTestSuite struct {
+	suite.Suite
+}
+
+func TestConsistencyTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsistencyTestSuite))
+}
+
+func (suite *ConsistencyTestSuite) TestItPostsSignedInconsistencyAlerts() {
+	secret := []byte("s3cr3t")
+	var alerts []InconsistencyAlert
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			suite.Assert().NoError(VerifySignature(secret, r.Header, body, time.Minute))
+
+			var alert InconsistencyAlert
+			suite.Assert().NoError(json.Unmarshal(body, &alert))
+			alerts = append(alerts, alert)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer server.Close()
+
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{{Version: 2, FinishedAtMs: 1}},
+	}
+	migrationsHandler, _ := handler.NewHandler(
+		registry, repo, nil,
+		handler.WithConsistencyMonitor(NewWebhook(server.URL, WithSecret(secret))),
+		handler.WithRunMetadata(execution.RunMetadata{DeployID: "deploy-1"}),
+	)
+
+	_, err := migrationsHandler.MigrateUp(handler.AllRuns)
+
+	suite.Assert().ErrorIs(err, handler.ErrPlanInconsistent)
+	suite.Require().Len(alerts, 1)
+	suite.Assert().Equal(handler.InconsistencyOutOfOrder, alerts[0].Kind)
+	suite.Assert().Equal(uint64(2), alerts[0].Version)
+	suite.Assert().Contains(alerts[0].Message, handler.ErrPlanInconsistent.Error())
+	suite.Assert().Equal("deploy-1", alerts[0].Run.DeployID)
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rsgcata/go-migrations/handler"
+)
+
+// MaintenanceRequest The JSON payload posted by the maintenance hooks (see
+// Webhook.MaintenanceHook)
+type MaintenanceRequest struct {
+	// Maintenance Is true when the application must enter the maintenance mode and false when
+	// it must exit it
+	Maintenance bool `json:"maintenance"`
+
+	// Direction The direction of the run (up or down)
+	Direction handler.MigrationStage `json:"direction"`
+}
+
+// MaintenanceHook Returns a hook which posts a MaintenanceRequest to the webhook endpoint, to be
+// used with handler.WithMaintenanceMode, for example:
+//
+//	webhook := notify.NewWebhook(url, notify.WithSecret(secret))
+//	handler.WithMaintenanceMode(
+//		webhook.MaintenanceHook(true), webhook.MaintenanceHook(false),
+//		handler.MaintenanceWhenRequired,
+//	)
+//
+// The requests are signed and retried the same way as the run reports.
+func (webhook *Webhook) MaintenanceHook(maintenance bool) handler.RunHook {
+	return func(direction handler.MigrationStage) error {
+		body, err := json.Marshal(MaintenanceRequest{maintenance, direction})
+		if err != nil {
+			return fmt.Errorf("failed to encode the maintenance request with error: %w", err)
+		}
+
+		return webhook.send(body)
+	}
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type MaintenanceTestSuite struct {
+	suite.Suite
+}
+
+func TestMaintenanceTestSuite(t *testing.T) {
+	suite.Run(t, new(MaintenanceTestSuite))
+}
+
+func (suite *MaintenanceTestSuite) TestItPostsSignedMaintenanceRequests() {
+	secret := []byte("s3cr3t")
+	var requests []MaintenanceRequest
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			suite.Assert().NoError(VerifySignature(secret, r.Header, body, time.Minute))
+
+			var request MaintenanceRequest
+			suite.Assert().NoError(json.Unmarshal(body, &request))
+			requests = append(requests, request)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, WithSecret(secret))
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	migrationsHandler, _ := handler.NewHandler(
+		registry, &execution.InMemoryRepository{}, nil,
+		handler.WithMaintenanceMode(
+			webhook.MaintenanceHook(true), webhook.MaintenanceHook(false),
+			handler.MaintenanceAlways,
+		),
+	)
+
+	_, err := migrationsHandler.MigrateUp(handler.NumOfRuns(1))
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]MaintenanceRequest{{true, handler.StageUp}, {false, handler.StageUp}}, requests,
+	)
+}
+
+func (suite *MaintenanceTestSuite) TestItFailsWhenTheEndpointRejectsTheRequest() {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}),
+	)
+	defer server.Close()
+
+	err := NewWebhook(server.URL).MaintenanceHook(true)(handler.StageDown)
+
+	suite.Assert().ErrorContains(err, "403 Forbidden")
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rsgcata/go-migrations/handler"
+)
+
+// SentryReporter ErrorReporter which sends the migration failures to Sentry (or a Sentry
+// compatible service), as events, via the envelope endpoint of the project
+type SentryReporter struct {
+	endpoint string
+	key      string
+	client   *http.Client
+	now      func() time.Time
+}
+
+// SentryOption Configures optional SentryReporter behaviour
+type SentryOption func(reporter *SentryReporter)
+
+// WithSentryHTTPClient Sets the client used to send the events. Defaults to a client with a 10
+// seconds timeout.
+func WithSentryHTTPClient(client *http.Client) SentryOption {
+	return func(reporter *SentryReporter) {
+		reporter.client = client
+	}
+}
+
+// NewSentryReporter Builds a SentryReporter for the project DSN, of the form
+// https://<public key>@<host>[/<path>]/<project id>
+func NewSentryReporter(dsn string, options ...SentryOption) (*SentryReporter, error) {
+	errMsg := "failed to build the sentry reporter"
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s, invalid dsn: %w", errMsg, err)
+	}
+
+	var path, project string
+	trimmed := strings.TrimSuffix(parsed.Path, "/")
+	if lastSlash := strings.LastIndex(trimmed, "/"); lastSlash >= 0 {
+		path, project = trimmed[:lastSlash], trimmed[lastSlash+1:]
+	}
+
+	if parsed.Scheme == "" || parsed.Host == "" || parsed.User == nil ||
+		parsed.User.Username() == "" || project == "" {
+		return nil, fmt.Errorf(
+			"%s, invalid dsn: expected https://<public key>@<host>/<project id>", errMsg,
+		)
+	}
+
+	reporter := &SentryReporter{
+		endpoint: fmt.Sprintf(
+			"%s://%s%s/api/%s/envelope/", parsed.Scheme, parsed.Host, path, project,
+		),
+		key:    parsed.User.Username(),
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+
+	for _, option := range options {
+		option(reporter)
+	}
+
+	return reporter, nil
+}
+
+// sentryEvent The Sentry event payload, limited to the fields set by the reporter
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   float64           `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Message     string            `json:"message"`
+	Exception   sentryExceptions  `json:"exception"`
+	Tags        map[string]string `json:"tags"`
+	Extra       map[string]any    `json:"extra"`
+	Fingerprint []string          `json:"fingerprint"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// ReportFailure Sends the failure as a Sentry event. Failures of the same migration, at the same
+// stage, are grouped in the same issue.
+func (reporter *SentryReporter) ReportFailure(failure handler.Failure) error {
+	errMsg := "sentry request failed"
+
+	eventID, err := newEventID()
+	if err != nil {
+		return fmt.Errorf("%s, failed to generate the event id with error: %w", errMsg, err)
+	}
+
+	version := strconv.FormatUint(failure.Version, 10)
+	extra := map[string]any{
+		"elapsed": failure.Elapsed.String(),
+		"stack":   string(failure.Stack),
+	}
+	if !failure.Run.IsZero() {
+		extra["run"] = failure.Run
+	}
+
+	now := reporter.now()
+	event := sentryEvent{
+		EventID:     eventID,
+		Timestamp:   float64(now.UnixMilli()) / 1000,
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "go-migrations",
+		Environment: failure.Environment,
+		Release:     failure.Run.GitSHA,
+		Message:     failure.Error(),
+		Exception: sentryExceptions{
+			Values: []sentryException{{Type: "MigrationFailedError", Value: failure.Err.Error()}},
+		},
+		Tags: map[string]string{
+			"migration.version":   version,
+			"migration.direction": string(failure.Direction),
+			"migration.stage":     string(failure.Stage),
+		},
+		Extra:       extra,
+		Fingerprint: []string{"go-migrations", version, string(failure.Stage)},
+	}
+
+	body, err := envelope(eventID, now, event)
+	if err != nil {
+		return fmt.Errorf("%s, failed to encode the event with error: %w", errMsg, err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, reporter.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	request.Header.Set("Content-Type", "application/x-sentry-envelope")
+	request.Header.Set(
+		"X-Sentry-Auth",
+		"Sentry sentry_version=7, sentry_client=go-migrations, sentry_key="+reporter.key,
+	)
+
+	response, err := reporter.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s, unexpected response status %s", errMsg, response.Status)
+	}
+
+	return nil
+}
+
+// envelope Encodes the event as a Sentry envelope: the envelope header, the item header and the
+// event, each on its own line
+func envelope(eventID string, sentAt time.Time, event sentryEvent) ([]byte, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+
+	items := []any{
+		map[string]string{"event_id": eventID, "sent_at": sentAt.UTC().Format(time.RFC3339)},
+		map[string]string{"type": "event"},
+		event,
+	}
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return nil, err
+		}
+	}
+
+	return body.Bytes(), nil
+}
+
+// newEventID Generates a random event id (32 hex characters)
+func newEventID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/stretchr/testify/suite"
+)
+
+type SentryTestSuite struct {
+	suite.Suite
+}
+
+func TestSentryTestSuite(t *testing.T) {
+	suite.Run(t, new(SentryTestSuite))
+}
+
+func (suite *SentryTestSuite) TestItRejectsInvalidDSNs() {
+	dsns := []string{
+		"",
+		"https://sentry.example.com/42",
+		"https://key@sentry.example.com",
+		"https://key@sentry.example.com/",
+		"key@sentry.example.com/42",
+	}
+
+	for _, dsn := range dsns {
+		_, err := NewSentryReporter(dsn)
+		suite.Assert().ErrorContains(err, "invalid dsn", dsn)
+	}
+}
+
+func (suite *SentryTestSuite) TestItBuildsTheEnvelopeEndpointFromTheDSN() {
+	reporter, err := NewSentryReporter("https://key@sentry.example.com/42")
+	suite.Require().NoError(err)
+	suite.Assert().Equal("https://sentry.example.com/api/42/envelope/", reporter.endpoint)
+	suite.Assert().Equal("key", reporter.key)
+
+	reporter, err = NewSentryReporter("http://key@localhost:9000/sentry/7/")
+	suite.Require().NoError(err)
+	suite.Assert().Equal("http://localhost:9000/sentry/api/7/envelope/", reporter.endpoint)
+}
+
+func (suite *SentryTestSuite) TestItSendsFailuresAsSentryEvents() {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+		}),
+	)
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://public@", 1) + "/42"
+	reporter, err := NewSentryReporter(dsn)
+	suite.Require().NoError(err)
+	reporter.now = func() time.Time { return time.Unix(1717236000, 500_000_000) }
+
+	failure := handler.Failure{
+		MigrationFailedError: &handler.MigrationFailedError{
+			Version:   1717236000,
+			Direction: handler.StageUp,
+			Stage:     handler.StageSave,
+			Elapsed:   1500 * time.Millisecond,
+			Err:       errors.New("connection reset"),
+			Stack:     []byte("goroutine 1 [running]:"),
+		},
+		Environment: "production",
+		Run:         execution.RunMetadata{DeployID: "deploy-1", GitSHA: "abc123"},
+	}
+	suite.Require().NoError(reporter.ReportFailure(failure))
+
+	suite.Assert().Equal("/api/42/envelope/", received.URL.Path)
+	suite.Assert().Equal(
+		"Sentry sentry_version=7, sentry_client=go-migrations, sentry_key=public",
+		received.Header.Get("X-Sentry-Auth"),
+	)
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	suite.Require().Len(lines, 3)
+
+	var header map[string]string
+	suite.Require().NoError(json.Unmarshal(lines[0], &header))
+	suite.Assert().Len(header["event_id"], 32)
+	suite.Assert().Equal("2024-06-01T10:00:00Z", header["sent_at"])
+	suite.Assert().JSONEq(`{"type":"event"}`, string(lines[1]))
+
+	var event sentryEvent
+	suite.Require().NoError(json.Unmarshal(lines[2], &event))
+	suite.Assert().Equal(header["event_id"], event.EventID)
+	suite.Assert().Equal(1717236000.5, event.Timestamp)
+	suite.Assert().Equal("error", event.Level)
+	suite.Assert().Equal("production", event.Environment)
+	suite.Assert().Equal("abc123", event.Release)
+	suite.Assert().Equal(
+		[]sentryException{{Type: "MigrationFailedError", Value: "connection reset"}},
+		event.Exception.Values,
+	)
+	suite.Assert().Equal(
+		map[string]string{
+			"migration.version":   "1717236000",
+			"migration.direction": "up",
+			"migration.stage":     "save",
+		},
+		event.Tags,
+	)
+	suite.Assert().Equal("1.5s", event.Extra["elapsed"])
+	suite.Assert().Equal("goroutine 1 [running]:", event.Extra["stack"])
+	suite.Assert().Equal(
+		map[string]any{"deployId": "deploy-1", "gitSha": "abc123"}, event.Extra["run"],
+	)
+	suite.Assert().Equal([]string{"go-migrations", "1717236000", "save"}, event.Fingerprint)
+}
+
+func (suite *SentryTestSuite) TestItFailsOnUnexpectedResponses() {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}),
+	)
+	defer server.Close()
+
+	reporter, _ := NewSentryReporter(strings.Replace(server.URL, "://", "://key@", 1) + "/1")
+	err := reporter.ReportFailure(
+		handler.Failure{
+			MigrationFailedError: &handler.MigrationFailedError{
+				Version: 1, Stage: handler.StageUp, Err: errors.New("mig err"),
+			},
+		},
+	)
+
+	suite.Assert().ErrorContains(err, "unexpected response status 403")
+}
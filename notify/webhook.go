@@ -0,0 +1,199 @@
+// Package notify includes handler.Notifier implementations, which send the migrations run
+// reports to external systems (for example, internal automation endpoints), and
+// handler.ErrorReporter implementations, which send the migration failures to error trackers,
+// run hooks which toggle the application maintenance mode (see Webhook.MaintenanceHook) and a
+// handler.ConsistencyMonitor, which posts the executions inconsistencies (see
+// Webhook.ReportInconsistency).
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rsgcata/go-migrations/handler"
+)
+
+const (
+	// SignatureHeader Holds the HMAC-SHA256 signature of the payload, as sha256=<hex>. The signed
+	// message is the TimestampHeader value, a dot and the request body.
+	SignatureHeader = "X-Migrations-Signature"
+	// TimestampHeader Holds the unix time (seconds) when the request was signed
+	TimestampHeader = "X-Migrations-Timestamp"
+)
+
+// ErrInvalidSignature is returned (wrapped) by VerifySignature when the signature does not
+// match the payload or the timestamp is too old
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Webhook Notifier which posts the run reports, encoded as JSON (see handler.RunReport), to an
+// HTTP endpoint
+type Webhook struct {
+	url      string
+	client   *http.Client
+	secret   []byte
+	headers  http.Header
+	attempts int
+	backoff  time.Duration
+	now      func() time.Time
+	sleep    func(time.Duration)
+}
+
+// WebhookOption Configures optional Webhook behaviour
+type WebhookOption func(webhook *Webhook)
+
+// WithSecret Signs the requests with the secret (see SignatureHeader), so the endpoint can
+// check they were sent by the migrations tool (see VerifySignature)
+func WithSecret(secret []byte) WebhookOption {
+	return func(webhook *Webhook) {
+		webhook.secret = secret
+	}
+}
+
+// WithHeaders Adds the headers (for example, Authorization) to the requests
+func WithHeaders(headers http.Header) WebhookOption {
+	return func(webhook *Webhook) {
+		for name, values := range headers {
+			for _, value := range values {
+				webhook.headers.Add(name, value)
+			}
+		}
+	}
+}
+
+// WithRetry Makes the webhook retry failed requests (network errors, 5xx and 429 responses), up
+// to the total number of attempts, waiting for the backoff before the first retry and doubling
+// it before each next retry
+func WithRetry(attempts int, backoff time.Duration) WebhookOption {
+	return func(webhook *Webhook) {
+		webhook.attempts = max(attempts, 1)
+		webhook.backoff = backoff
+	}
+}
+
+// WithHTTPClient Sets the client used to send the requests. Defaults to a client with a 10
+// seconds timeout.
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(webhook *Webhook) {
+		webhook.client = client
+	}
+}
+
+// NewWebhook Builds a Webhook which posts the run reports to the url. Without options, the
+// requests are not signed and failed requests are not retried.
+func NewWebhook(url string, options ...WebhookOption) *Webhook {
+	webhook := &Webhook{
+		url:      url,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		headers:  http.Header{},
+		attempts: 1,
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+
+	for _, option := range options {
+		option(webhook)
+	}
+
+	return webhook
+}
+
+// Notify Posts the report to the webhook endpoint, retrying if configured (see WithRetry)
+func (webhook *Webhook) Notify(report *handler.RunReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode the run report with error: %w", err)
+	}
+
+	return webhook.send(body)
+}
+
+// send Posts the body to the webhook endpoint, retrying if configured (see WithRetry)
+func (webhook *Webhook) send(body []byte) error {
+	backoff := webhook.backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := webhook.post(body)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable || attempt >= webhook.attempts {
+			return fmt.Errorf("webhook request failed, attempts: %d, last error: %w", attempt, err)
+		}
+
+		webhook.sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post Sends the signed body. Returns if the request can be retried, when it fails
+func (webhook *Webhook) post(body []byte) (retryable bool, err error) {
+	request, err := http.NewRequest(http.MethodPost, webhook.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	request.Header = webhook.headers.Clone()
+	request.Header.Set("Content-Type", "application/json")
+
+	if len(webhook.secret) > 0 {
+		timestamp := strconv.FormatInt(webhook.now().Unix(), 10)
+		request.Header.Set(TimestampHeader, timestamp)
+		request.Header.Set(SignatureHeader, sign(webhook.secret, timestamp, body))
+	}
+
+	response, err := webhook.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+
+	retryable = response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("unexpected response status %s", response.Status)
+}
+
+// VerifySignature Checks the signature of a webhook request, for endpoints implemented in Go.
+// If maxAge is greater than 0, requests signed earlier than maxAge ago are rejected, to prevent
+// replays. Errors with ErrInvalidSignature.
+func VerifySignature(secret []byte, header http.Header, body []byte, maxAge time.Duration) error {
+	timestamp := header.Get(TimestampHeader)
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or invalid timestamp", ErrInvalidSignature)
+	}
+
+	if maxAge > 0 && time.Since(time.Unix(signedAt, 0)) > maxAge {
+		return fmt.Errorf(
+			"%w: the request was signed more than %s ago", ErrInvalidSignature, maxAge,
+		)
+	}
+
+	expected := sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(SignatureHeader))) {
+		return fmt.Errorf("%w: the signature does not match the payload", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// sign Computes the HMAC-SHA256 signature of the timestamp and body
+func sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookTestSuite struct {
+	suite.Suite
+}
+
+func TestWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}
+
+// newReport Runs a migration and returns the run report
+func (suite *WebhookTestSuite) newReport() *handler.RunReport {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	migrationsHandler, _ := handler.NewHandler(registry, &execution.InMemoryRepository{}, nil)
+	report, err := migrationsHandler.MigrateUpWithReport(handler.NumOfRuns(1))
+	suite.Require().NoError(err)
+	return report
+}
+
+func (suite *WebhookTestSuite) TestItPostsSignedReports() {
+	secret := []byte("s3cr3t")
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer server.Close()
+
+	webhook := NewWebhook(
+		server.URL,
+		WithSecret(secret),
+		WithHeaders(http.Header{"Authorization": {"Bearer token"}}),
+	)
+
+	suite.Require().NoError(webhook.Notify(suite.newReport()))
+	suite.Assert().Equal(http.MethodPost, received.Method)
+	suite.Assert().Equal("application/json", received.Header.Get("Content-Type"))
+	suite.Assert().Equal("Bearer token", received.Header.Get("Authorization"))
+	suite.Assert().Contains(string(body), `"outcome":"executed"`)
+	suite.Assert().NoError(VerifySignature(secret, received.Header, body, time.Minute))
+
+	suite.Assert().ErrorIs(
+		VerifySignature([]byte("other"), received.Header, body, 0), ErrInvalidSignature,
+	)
+	suite.Assert().ErrorIs(
+		VerifySignature(secret, received.Header, append(body, ' '), 0), ErrInvalidSignature,
+	)
+	suite.Assert().ErrorIs(VerifySignature(secret, http.Header{}, body, 0), ErrInvalidSignature)
+
+	old := received.Header.Clone()
+	old.Set(TimestampHeader, "1000")
+	old.Set(SignatureHeader, sign(secret, "1000", body))
+	suite.Assert().NoError(VerifySignature(secret, old, body, 0))
+	suite.Assert().ErrorIs(VerifySignature(secret, old, body, time.Minute), ErrInvalidSignature)
+}
+
+func (suite *WebhookTestSuite) TestItRetriesFailedRequestsWithBackoff() {
+	statuses := []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statuses[requests])
+			requests++
+		}),
+	)
+	defer server.Close()
+
+	var waits []time.Duration
+	webhook := NewWebhook(server.URL, WithRetry(3, time.Second))
+	webhook.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	suite.Assert().NoError(webhook.Notify(suite.newReport()))
+	suite.Assert().Equal(3, requests)
+	suite.Assert().Equal([]time.Duration{time.Second, 2 * time.Second}, waits)
+
+	requests, waits = 0, nil
+	statuses = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+	webhook = NewWebhook(server.URL, WithRetry(2, time.Second))
+	webhook.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	err := webhook.Notify(suite.newReport())
+	suite.Assert().ErrorContains(err, "attempts: 2, last error: unexpected response status 503")
+	suite.Assert().Equal(2, requests)
+}
+
+func (suite *WebhookTestSuite) TestItDoesNotRetryClientErrors() {
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusUnauthorized)
+		}),
+	)
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, WithRetry(5, time.Second))
+	webhook.sleep = func(time.Duration) {}
+
+	err := webhook.Notify(suite.newReport())
+	suite.Assert().ErrorContains(err, "attempts: 1, last error: unexpected response status 401")
+	suite.Assert().Equal(1, requests)
+}
@@ -0,0 +1,170 @@
+// Package backfill includes a runner for chunked data backfills, which processes a big table in
+// small batches and persists its progress, so it can be resumed after a crash
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// DefaultBatchSize The batch size used when Runner.BatchSize is not set
+const DefaultBatchSize = 1000
+
+// Cursor The position of a backfill: the key of the last processed row (for example, its
+// primary key) and the number of rows processed so far (which can also be used as offset, for
+// tables without a usable key). The zero value is the start of the backfill.
+type Cursor struct {
+	LastKey string `json:"lastKey"`
+	Offset  int64  `json:"offset"`
+}
+
+// Batch The batch the callback must process: at most Limit rows, after the Cursor
+type Batch struct {
+	Cursor
+	Limit int
+}
+
+// Result What the callback processed. LastKey is the key of the last processed row. The
+// backfill is done when Done is set or when fewer rows than the batch limit were processed.
+type Result struct {
+	LastKey   string
+	Processed int
+	Done      bool
+}
+
+// Callback Must process the batch. Since progress is saved after each batch, a batch
+// interrupted by a crash is processed again when the backfill resumes, so callbacks must be
+// idempotent (for example, UPDATE ... WHERE new_column IS NULL).
+type Callback func(ctx context.Context, batch Batch) (Result, error)
+
+// ErrNotProgressStore is returned when the repository can not persist progress
+var ErrNotProgressStore = errors.New("repository can not persist progress")
+
+// Runner Runs a chunked backfill: Callback is called for each batch until the backfill is done,
+// sleeping between batches to limit the load on the database. The cursor is persisted after
+// each batch in the Store (for example, the migrations repository, see NewRunner), under Key,
+// so a crashed backfill resumes from the last processed batch. Completed backfills are not run
+// again.
+type Runner struct {
+	Key       string
+	BatchSize int
+	Sleep     time.Duration
+	Store     execution.ProgressStore
+	Callback  Callback
+}
+
+// NewRunner Creates a Runner which persists its progress through the migrations repository.
+// Fails with ErrNotProgressStore if the repository does not implement execution.ProgressStore
+// (the bundled mysql and mongo repositories do).
+func NewRunner(
+	key string,
+	repository execution.Repository,
+	callback Callback,
+) (*Runner, error) {
+	store, ok := repository.(execution.ProgressStore)
+	if !ok {
+		return nil, fmt.Errorf("failed to create backfill runner %s, %w", key, ErrNotProgressStore)
+	}
+
+	return &Runner{Key: key, BatchSize: DefaultBatchSize, Store: store, Callback: callback}, nil
+}
+
+// Progress Returns the persisted cursor of the backfill and if the backfill is done
+func (r *Runner) Progress() (Cursor, bool, error) {
+	progress, err := r.Store.LoadProgress(r.Key)
+	if err != nil || progress == nil {
+		return Cursor{}, false, err
+	}
+
+	cursor, err := decodeCursor(progress.Token)
+	return cursor, progress.Done, err
+}
+
+// Run Runs the backfill, from the persisted cursor, until it is done or the context is
+// cancelled
+func (r *Runner) Run(ctx context.Context) error {
+	errMsg := fmt.Sprintf("failed to run backfill %s", r.Key)
+
+	cursor, done, err := r.Progress()
+	if err != nil {
+		return fmt.Errorf("%s, failed to load progress with error: %w", errMsg, err)
+	}
+
+	batchSize := r.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	for !done {
+		if err = ctx.Err(); err != nil {
+			return fmt.Errorf("%s, %w", errMsg, err)
+		}
+
+		result, err := r.Callback(ctx, Batch{Cursor: cursor, Limit: batchSize})
+		if err != nil {
+			return fmt.Errorf(
+				"%s, batch at offset %d failed with error: %w", errMsg, cursor.Offset, err,
+			)
+		}
+
+		if result.Processed > 0 {
+			cursor.LastKey = result.LastKey
+			cursor.Offset += int64(result.Processed)
+		}
+		done = result.Done || result.Processed < batchSize
+
+		if err = r.save(cursor, done); err != nil {
+			return fmt.Errorf("%s, failed to save progress with error: %w", errMsg, err)
+		}
+
+		if !done {
+			if err = sleep(ctx, r.Sleep); err != nil {
+				return fmt.Errorf("%s, %w", errMsg, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) save(cursor Cursor, done bool) error {
+	token, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+
+	return r.Store.SaveProgress(execution.Progress{Key: r.Key, Token: string(token), Done: done})
+}
+
+func decodeCursor(token string) (Cursor, error) {
+	var cursor Cursor
+	if token == "" {
+		return cursor, nil
+	}
+
+	if err := json.Unmarshal([]byte(token), &cursor); err != nil {
+		return cursor, fmt.Errorf("invalid progress token %q: %w", token, err)
+	}
+	return cursor, nil
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+type BackfillTestSuite struct {
+	suite.Suite
+}
+
+func TestBackfillTestSuite(t *testing.T) {
+	suite.Run(t, new(BackfillTestSuite))
+}
+
+type progressRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryProgressStore
+}
+
+// rowsCallback Processes rows 1..total (keyed by their number), failing once when a batch
+// starts after the provided row, if failAfter > 0
+func rowsCallback(total int, failAfter int, processed *[]int) Callback {
+	return func(ctx context.Context, batch Batch) (Result, error) {
+		last, _ := strconv.Atoi(batch.LastKey)
+		if failAfter > 0 && last >= failAfter {
+			failAfter = 0
+			return Result{}, errors.New("connection lost")
+		}
+
+		result := Result{LastKey: batch.LastKey}
+		end := min(last+batch.Limit, total)
+		for row := last + 1; row <= end; row++ {
+			*processed = append(*processed, row)
+			result.LastKey = strconv.Itoa(row)
+			result.Processed++
+		}
+		return result, nil
+	}
+}
+
+func (suite *BackfillTestSuite) TestItRunsBatchesUntilDone() {
+	var processed []int
+	runner, err := NewRunner("users", &progressRepository{}, rowsCallback(5, 0, &processed))
+	suite.Assert().NoError(err)
+	runner.BatchSize = 2
+
+	suite.Assert().NoError(runner.Run(context.Background()))
+	suite.Assert().Equal([]int{1, 2, 3, 4, 5}, processed)
+
+	cursor, done, err := runner.Progress()
+	suite.Assert().NoError(err)
+	suite.Assert().True(done)
+	suite.Assert().Equal(Cursor{LastKey: "5", Offset: 5}, cursor)
+
+	suite.Assert().NoError(runner.Run(context.Background()))
+	suite.Assert().Len(processed, 5)
+}
+
+func (suite *BackfillTestSuite) TestItFinishesOnEmptyOrExplicitlyDoneBatches() {
+	var processed []int
+	runner, _ := NewRunner("users", &progressRepository{}, rowsCallback(4, 0, &processed))
+	runner.BatchSize = 2
+
+	suite.Assert().NoError(runner.Run(context.Background()))
+	cursor, done, _ := runner.Progress()
+	suite.Assert().True(done)
+	suite.Assert().Equal(Cursor{LastKey: "4", Offset: 4}, cursor)
+
+	calls := 0
+	runner, _ = NewRunner(
+		"orders", &progressRepository{}, func(ctx context.Context, batch Batch) (Result, error) {
+			calls++
+			return Result{LastKey: "9", Processed: batch.Limit, Done: true}, nil
+		},
+	)
+
+	suite.Assert().NoError(runner.Run(context.Background()))
+	suite.Assert().Equal(1, calls)
+}
+
+func (suite *BackfillTestSuite) TestItResumesFromLastSavedBatch() {
+	var processed []int
+	runner, _ := NewRunner("users", &progressRepository{}, rowsCallback(5, 3, &processed))
+	runner.BatchSize = 2
+
+	err := runner.Run(context.Background())
+	suite.Assert().ErrorContains(err, "batch at offset 4 failed with error: connection lost")
+	suite.Assert().Equal([]int{1, 2, 3, 4}, processed)
+
+	cursor, done, _ := runner.Progress()
+	suite.Assert().False(done)
+	suite.Assert().Equal(Cursor{LastKey: "4", Offset: 4}, cursor)
+
+	suite.Assert().NoError(runner.Run(context.Background()))
+	suite.Assert().Equal([]int{1, 2, 3, 4, 5}, processed)
+}
+
+func (suite *BackfillTestSuite) TestItStopsWhenContextIsCancelled() {
+	var processed []int
+	ctx, cancel := context.WithCancel(context.Background())
+	callback := rowsCallback(5, 0, &processed)
+	runner := &Runner{
+		Key:       "users",
+		BatchSize: 1,
+		Sleep:     time.Second,
+		Store:     &execution.InMemoryProgressStore{},
+		Callback: func(ctx context.Context, batch Batch) (Result, error) {
+			cancel()
+			return callback(ctx, batch)
+		},
+	}
+
+	err := runner.Run(ctx)
+
+	suite.Assert().ErrorIs(err, context.Canceled)
+	suite.Assert().Equal([]int{1}, processed)
+}
+
+func (suite *BackfillTestSuite) TestItFailsWhenProgressCanNotBeSavedOrLoaded() {
+	var processed []int
+	saveErr := errors.New("save failed")
+	store := &execution.InMemoryProgressStore{SaveErr: saveErr}
+	runner := &Runner{Key: "users", Store: store, Callback: rowsCallback(5, 0, &processed)}
+
+	suite.Assert().ErrorIs(runner.Run(context.Background()), saveErr)
+	suite.Assert().Equal([]int{1, 2, 3, 4, 5}, processed)
+
+	store.SaveErr = nil
+	_ = store.SaveProgress(execution.Progress{Key: "users", Token: "{"})
+	suite.Assert().ErrorContains(runner.Run(context.Background()), "invalid progress token")
+}
+
+func (suite *BackfillTestSuite) TestItFailsToCreateRunnerForRepositoriesWithoutProgress() {
+	_, err := NewRunner("users", &execution.InMemoryRepository{}, nil)
+	suite.Assert().ErrorIs(err, ErrNotProgressStore)
+}
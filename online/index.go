@@ -0,0 +1,93 @@
+package online
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Dialect The SQL dialect used to build online schema change statements
+type Dialect string
+
+const (
+	// Mysql Mysql and MariaDB. Index changes use the in-place algorithm, without locking
+	Mysql Dialect = "mysql"
+	// Postgres PostgreSQL. Index changes are made concurrently. The statements can not run
+	// inside a transaction.
+	Postgres Dialect = "postgres"
+)
+
+// Index An index definition
+type Index struct {
+	Name    string
+	Table   string
+	Columns []string
+	Unique  bool
+}
+
+// CreateIndexSQL Returns the statement which creates the index without blocking writes to the
+// table (CREATE INDEX CONCURRENTLY for Postgres, ALGORITHM=INPLACE, LOCK=NONE for Mysql)
+func CreateIndexSQL(dialect Dialect, index Index) (string, error) {
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+
+	columns := strings.Join(index.Columns, ", ")
+
+	switch dialect {
+	case Mysql:
+		return fmt.Sprintf(
+			"CREATE %sINDEX %s ON %s (%s) ALGORITHM=INPLACE LOCK=NONE",
+			unique, index.Name, index.Table, columns,
+		), nil
+	case Postgres:
+		return fmt.Sprintf(
+			"CREATE %sINDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s)",
+			unique, index.Name, index.Table, columns,
+		), nil
+	}
+
+	return "", fmt.Errorf("online index changes are not supported for dialect %q", dialect)
+}
+
+// DropIndexSQL Returns the statement which drops the index without blocking writes to the table
+func DropIndexSQL(dialect Dialect, index Index) (string, error) {
+	switch dialect {
+	case Mysql:
+		return fmt.Sprintf(
+			"DROP INDEX %s ON %s ALGORITHM=INPLACE LOCK=NONE", index.Name, index.Table,
+		), nil
+	case Postgres:
+		return fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", index.Name), nil
+	}
+
+	return "", fmt.Errorf("online index changes are not supported for dialect %q", dialect)
+}
+
+// CreateIndex Creates the index without blocking writes to the table (see CreateIndexSQL)
+func CreateIndex(ctx context.Context, db *sql.DB, dialect Dialect, index Index) error {
+	query, err := CreateIndexSQL(dialect, index)
+	if err == nil {
+		_, err = db.ExecContext(ctx, query)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create index %s with error: %w", index.Name, err)
+	}
+	return nil
+}
+
+// DropIndex Drops the index without blocking writes to the table (see DropIndexSQL)
+func DropIndex(ctx context.Context, db *sql.DB, dialect Dialect, index Index) error {
+	query, err := DropIndexSQL(dialect, index)
+	if err == nil {
+		_, err = db.ExecContext(ctx, query)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to drop index %s with error: %w", index.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package online
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexTestSuite struct {
+	suite.Suite
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
+
+func (suite *IndexTestSuite) TestItCanBuildOnlineIndexStatements() {
+	index := Index{
+		Name: "idx_email", Table: "users", Columns: []string{"email", "id"}, Unique: true,
+	}
+
+	query, err := CreateIndexSQL(Mysql, index)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		"CREATE UNIQUE INDEX idx_email ON users (email, id) ALGORITHM=INPLACE LOCK=NONE", query,
+	)
+
+	query, err = CreateIndexSQL(Postgres, index)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		"CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_email ON users (email, id)", query,
+	)
+
+	query, err = DropIndexSQL(Mysql, index)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("DROP INDEX idx_email ON users ALGORITHM=INPLACE LOCK=NONE", query)
+
+	query, err = DropIndexSQL(Postgres, index)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("DROP INDEX CONCURRENTLY IF EXISTS idx_email", query)
+
+	_, err = CreateIndexSQL("sqlite", index)
+	suite.Assert().ErrorContains(err, "not supported")
+	_, err = DropIndexSQL("sqlite", index)
+	suite.Assert().ErrorContains(err, "not supported")
+}
+
+func (suite *IndexTestSuite) TestItCanChangeIndexesOnline() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+	index := Index{Name: "idx_email", Table: "users", Columns: []string{"email"}}
+
+	suite.Assert().NoError(CreateIndex(context.Background(), db, Postgres, index))
+	suite.Assert().NoError(DropIndex(context.Background(), db, Postgres, index))
+	suite.Assert().ErrorContains(
+		CreateIndex(context.Background(), db, "sqlite", index), "failed to create index idx_email",
+	)
+
+	suite.Assert().Equal(
+		[]sqlcapture.Statement{
+			{Query: "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_email ON users (email)"},
+			{Query: "DROP INDEX CONCURRENTLY IF EXISTS idx_email"},
+		},
+		recorder.Statements(),
+	)
+}
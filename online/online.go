@@ -0,0 +1,79 @@
+// Package online includes building blocks for zero-downtime (expand-contract) migrations, like
+// dual-write toggles and online index changes. See also the online/backfill package for
+// chunked data backfills.
+package online
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Phase A step of an expand-contract change, where data moves from an old storage (for example,
+// a column or a table) to a new one
+type Phase int32
+
+const (
+	// PhaseOld Only the old storage is written and read
+	PhaseOld Phase = iota
+	// PhaseDualWrite Both storages are written, the old one is read (the new storage is being
+	// backfilled)
+	PhaseDualWrite
+	// PhaseDualWriteReadNew Both storages are written, the new one is read (the old storage
+	// is kept, so the change can be rolled back)
+	PhaseDualWriteReadNew
+	// PhaseNew Only the new storage is written and read (the old storage can be dropped)
+	PhaseNew
+)
+
+func (phase Phase) String() string {
+	switch phase {
+	case PhaseOld:
+		return "old"
+	case PhaseDualWrite:
+		return "dual-write"
+	case PhaseDualWriteReadNew:
+		return "dual-write-read-new"
+	case PhaseNew:
+		return "new"
+	}
+	return fmt.Sprintf("phase(%d)", int32(phase))
+}
+
+// DualWrite Toggle which tells the application code which storages to write and read during an
+// expand-contract change. It is safe for concurrent use, so migrations (or an admin endpoint)
+// can move it to the next phase while the application is serving requests.
+type DualWrite struct {
+	phase atomic.Int32
+}
+
+// NewDualWrite Creates a new DualWrite toggle, in the provided phase
+func NewDualWrite(phase Phase) *DualWrite {
+	toggle := &DualWrite{}
+	toggle.phase.Store(int32(phase))
+	return toggle
+}
+
+// Phase Returns the current phase
+func (toggle *DualWrite) Phase() Phase {
+	return Phase(toggle.phase.Load())
+}
+
+// Set Moves the toggle to the provided phase
+func (toggle *DualWrite) Set(phase Phase) {
+	toggle.phase.Store(int32(phase))
+}
+
+// WriteOld Checks if the old storage must be written
+func (toggle *DualWrite) WriteOld() bool {
+	return toggle.Phase() < PhaseNew
+}
+
+// WriteNew Checks if the new storage must be written
+func (toggle *DualWrite) WriteNew() bool {
+	return toggle.Phase() > PhaseOld
+}
+
+// ReadNew Checks if the new storage must be read (otherwise, the old storage must be read)
+func (toggle *DualWrite) ReadNew() bool {
+	return toggle.Phase() >= PhaseDualWriteReadNew
+}
@@ -0,0 +1,42 @@
+package online
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OnlineTestSuite struct {
+	suite.Suite
+}
+
+func TestOnlineTestSuite(t *testing.T) {
+	suite.Run(t, new(OnlineTestSuite))
+}
+
+func (suite *OnlineTestSuite) TestItTogglesWritesAndReadsByPhase() {
+	scenarios := map[Phase]struct {
+		writeOld bool
+		writeNew bool
+		readNew  bool
+	}{
+		PhaseOld:              {true, false, false},
+		PhaseDualWrite:        {true, true, false},
+		PhaseDualWriteReadNew: {true, true, true},
+		PhaseNew:              {false, true, true},
+	}
+
+	toggle := NewDualWrite(PhaseOld)
+	for phase, scenario := range scenarios {
+		toggle.Set(phase)
+		suite.Assert().Equal(phase, toggle.Phase())
+		suite.Assert().Equal(scenario.writeOld, toggle.WriteOld(), "failed phase %s", phase)
+		suite.Assert().Equal(scenario.writeNew, toggle.WriteNew(), "failed phase %s", phase)
+		suite.Assert().Equal(scenario.readNew, toggle.ReadNew(), "failed phase %s", phase)
+	}
+}
+
+func (suite *OnlineTestSuite) TestItCanDescribePhases() {
+	suite.Assert().Equal("dual-write", PhaseDualWrite.String())
+	suite.Assert().Equal("phase(9)", Phase(9).String())
+}
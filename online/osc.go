@@ -0,0 +1,47 @@
+package online
+
+import "context"
+
+// SchemaChange A table change, to be applied by an online schema change tool. Alter holds the
+// ALTER TABLE specification, without the "ALTER TABLE <table>" part, for example
+// "ADD COLUMN age INT".
+type SchemaChange struct {
+	Table string
+	Alter string
+}
+
+// JobState The state of an online schema change job
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobCompleted JobState = "completed"
+	JobFailed    JobState = "failed"
+)
+
+// JobStatus The status of an online schema change job. Message can hold details, for example
+// the progress or the failure reason.
+type JobStatus struct {
+	State   JobState
+	Message string
+}
+
+// Executor Adapter for an online schema change tool (for example, gh-ost,
+// pt-online-schema-change or Vitess Online DDL)
+type Executor interface {
+	// Submit Must start applying the change, without waiting for it, and return the identifier
+	// of the started job. The identifier is persisted, so it must be usable from other
+	// processes (for example, after a crash) to check the job status.
+	Submit(ctx context.Context, change SchemaChange) (jobID string, err error)
+
+	// Status Must return the current status of the job
+	Status(ctx context.Context, jobID string) (JobStatus, error)
+}
+
+// SchemaChangeMigration Optional interface which can be implemented by migrations whose DDL
+// must be delegated to an online schema change Executor (see handler.WithSchemaChangeExecutor).
+// The handler submits the changes, in order, and polls for their completion before calling
+// Up(), which can hold the remaining (for example, data) changes or nothing.
+type SchemaChangeMigration interface {
+	SchemaChanges() []SchemaChange
+}
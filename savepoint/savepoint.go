@@ -0,0 +1,177 @@
+// Package savepoint includes helpers for migrations executed in multiple steps (or multiple
+// migrations acting as a unit), which record each completed step as a savepoint, persisted
+// through the migrations repository, so a failed or interrupted run resumes after the last
+// completed step, instead of starting over
+package savepoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/execution"
+)
+
+// ErrNotProgressStore is returned when the repository can not persist savepoints
+var ErrNotProgressStore = errors.New("repository can not persist savepoints")
+
+// ErrNotReached is returned (wrapped) by Unit.Require when the savepoint was not recorded
+var ErrNotReached = errors.New("savepoint was not reached")
+
+// Savepoint A completed step of a unit, with optional data needed by the next steps (for
+// example, the name of a temporary table)
+type Savepoint struct {
+	Name string `json:"name"`
+	Data string `json:"data,omitempty"`
+}
+
+// Unit A named unit of work, executed in steps. Migrations which act as a unit share it by using
+// the same name, for example, a migration can check that a step of a previous migration was
+// completed (see Require). The savepoints are persisted in the Store (for example, the
+// migrations repository, see NewUnit), under the "savepoint:<name>" key, in the order they were
+// recorded.
+type Unit struct {
+	Name  string
+	Store execution.ProgressStore
+}
+
+// NewUnit Creates a Unit which persists its savepoints through the migrations repository. Fails
+// with ErrNotProgressStore if the repository does not implement execution.ProgressStore (the
+// bundled mysql and mongo repositories do).
+func NewUnit(name string, repository execution.Repository) (*Unit, error) {
+	store, ok := repository.(execution.ProgressStore)
+	if !ok {
+		return nil, fmt.Errorf("failed to create savepoint unit %s, %w", name, ErrNotProgressStore)
+	}
+
+	return &Unit{Name: name, Store: store}, nil
+}
+
+// Savepoints Returns the recorded savepoints, in the order they were recorded
+func (u *Unit) Savepoints() ([]Savepoint, error) {
+	progress, err := u.Store.LoadProgress(u.key())
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to load the savepoints of unit %s with error: %w", u.Name, err,
+		)
+	} else if progress == nil || progress.Token == "" {
+		return nil, nil
+	}
+
+	var savepoints []Savepoint
+	if err = json.Unmarshal([]byte(progress.Token), &savepoints); err != nil {
+		return nil, fmt.Errorf("invalid savepoints of unit %s: %w", u.Name, err)
+	}
+	return savepoints, nil
+}
+
+// Reached Checks if the savepoint was recorded
+func (u *Unit) Reached(name string) (bool, error) {
+	_, found, err := u.Get(name)
+	return found, err
+}
+
+// Get Returns the savepoint, if it was recorded
+func (u *Unit) Get(name string) (Savepoint, bool, error) {
+	savepoints, err := u.Savepoints()
+	if err != nil {
+		return Savepoint{}, false, err
+	}
+
+	if i := index(savepoints, name); i >= 0 {
+		return savepoints[i], true, nil
+	}
+	return Savepoint{}, false, nil
+}
+
+// Require Fails with ErrNotReached if the savepoint was not recorded, for example, in the
+// Validate() of a migration which depends on a step of a previous migration of the unit
+func (u *Unit) Require(name string) error {
+	reached, err := u.Reached(name)
+	if err == nil && !reached {
+		err = fmt.Errorf("%w, unit %s, savepoint %s", ErrNotReached, u.Name, name)
+	}
+	return err
+}
+
+// Save Records the savepoint, with optional data. Saving a recorded savepoint replaces its data.
+func (u *Unit) Save(name string, data string) error {
+	savepoints, err := u.Savepoints()
+	if err != nil {
+		return err
+	}
+
+	if i := index(savepoints, name); i >= 0 {
+		savepoints[i].Data = data
+	} else {
+		savepoints = append(savepoints, Savepoint{Name: name, Data: data})
+	}
+	return u.save(savepoints)
+}
+
+// Step Runs the step, unless its savepoint was already recorded, and records the savepoint after
+// the step succeeded. Since the savepoint is recorded after the step, a step interrupted by a
+// crash is run again, so steps must be idempotent.
+func (u *Unit) Step(name string, step func() error) error {
+	reached, err := u.Reached(name)
+	if err != nil || reached {
+		return err
+	}
+
+	if err = step(); err != nil {
+		return fmt.Errorf("step %s of unit %s failed with error: %w", name, u.Name, err)
+	}
+	return u.Save(name, "")
+}
+
+// RollbackTo Removes the savepoints recorded after the savepoint, so their steps run again. An
+// empty name removes all savepoints (see Reset).
+func (u *Unit) RollbackTo(name string) error {
+	savepoints, err := u.Savepoints()
+	if err != nil {
+		return err
+	}
+
+	keep := 0
+	if name != "" {
+		if keep = index(savepoints, name) + 1; keep == 0 {
+			return fmt.Errorf("%w, unit %s, savepoint %s", ErrNotReached, u.Name, name)
+		}
+	}
+	return u.save(savepoints[:keep])
+}
+
+// Reset Removes all savepoints, for example, in Down(), after the changes of the unit were
+// reverted
+func (u *Unit) Reset() error {
+	return u.RollbackTo("")
+}
+
+func (u *Unit) save(savepoints []Savepoint) error {
+	token := ""
+	if len(savepoints) > 0 {
+		encoded, err := json.Marshal(savepoints)
+		if err != nil {
+			return err
+		}
+		token = string(encoded)
+	}
+
+	if err := u.Store.SaveProgress(execution.Progress{Key: u.key(), Token: token}); err != nil {
+		return fmt.Errorf(
+			"failed to save the savepoints of unit %s with error: %w", u.Name, err,
+		)
+	}
+	return nil
+}
+
+func (u *Unit) key() string {
+	return "savepoint:" + u.Name
+}
+
+func index(savepoints []Savepoint, name string) int {
+	return slices.IndexFunc(savepoints, func(savepoint Savepoint) bool {
+		return savepoint.Name == name
+	})
+}
@@ -0,0 +1,102 @@
+package savepoint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/stretchr/testify/suite"
+)
+
+type SavepointTestSuite struct {
+	suite.Suite
+}
+
+func TestSavepointTestSuite(t *testing.T) {
+	suite.Run(t, new(SavepointTestSuite))
+}
+
+type progressRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryProgressStore
+}
+
+func (suite *SavepointTestSuite) TestItResumesAfterTheLastCompletedStep() {
+	repo := &progressRepository{}
+	unit, err := NewUnit("split-users", repo)
+	suite.Require().NoError(err)
+
+	var executed []string
+	steps := func(failAt string) error {
+		for _, name := range []string{"create", "copy", "swap"} {
+			err := unit.Step(name, func() error {
+				executed = append(executed, name)
+				if name == failAt {
+					return errors.New("connection lost")
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = steps("copy")
+	suite.Assert().ErrorContains(
+		err, "step copy of unit split-users failed with error: connection lost",
+	)
+	suite.Assert().NoError(steps(""))
+	suite.Assert().Equal([]string{"create", "copy", "copy", "swap"}, executed)
+
+	savepoints, err := unit.Savepoints()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]Savepoint{{Name: "create"}, {Name: "copy"}, {Name: "swap"}}, savepoints,
+	)
+
+	// The savepoints are persisted, another migration of the unit sees them
+	other, _ := NewUnit("split-users", repo)
+	suite.Assert().NoError(other.Require("swap"))
+	suite.Assert().ErrorIs(other.Require("cleanup"), ErrNotReached)
+}
+
+func (suite *SavepointTestSuite) TestItRecordsDataAndRollsBack() {
+	unit, _ := NewUnit("orders", &progressRepository{})
+
+	suite.Assert().NoError(unit.Save("shadow", "orders_tmp_1"))
+	suite.Assert().NoError(unit.Save("backfill", ""))
+	suite.Assert().NoError(unit.Save("shadow", "orders_tmp_2"))
+
+	savepoint, found, err := unit.Get("shadow")
+	suite.Assert().NoError(err)
+	suite.Assert().True(found)
+	suite.Assert().Equal("orders_tmp_2", savepoint.Data)
+
+	suite.Assert().NoError(unit.RollbackTo("shadow"))
+	reached, _ := unit.Reached("backfill")
+	suite.Assert().False(reached)
+	reached, _ = unit.Reached("shadow")
+	suite.Assert().True(reached)
+
+	suite.Assert().ErrorIs(unit.RollbackTo("backfill"), ErrNotReached)
+
+	suite.Assert().NoError(unit.Reset())
+	savepoints, err := unit.Savepoints()
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(savepoints)
+}
+
+func (suite *SavepointTestSuite) TestItFailsWithoutAProgressStore() {
+	_, err := NewUnit("orders", &execution.InMemoryRepository{})
+	suite.Assert().ErrorIs(err, ErrNotProgressStore)
+
+	repo := &progressRepository{}
+	repo.InMemoryProgressStore.SaveErr = errors.New("read only")
+	unit, _ := NewUnit("orders", repo)
+	suite.Assert().ErrorContains(
+		unit.Save("shadow", ""),
+		"failed to save the savepoints of unit orders with error: read only",
+	)
+}
@@ -0,0 +1,235 @@
+// Package scaffold generates a complete migrations setup for a new project: the migrations
+// directory with an example migration, the entrypoint (a main.go or, for the standalone migrate
+// binary, a config file), a docker-compose file with a disposable database and a test harness,
+// which runs the migrations Up, Down and Up again against it.
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// MySQL and Mongo The databases which can be scaffolded
+const (
+	MySQL = "mysql"
+	Mongo = "mongo"
+)
+
+// ErrScaffold is returned (wrapped) when the setup could not be rendered or written
+var ErrScaffold = errors.New("failed to scaffold migrations")
+
+//go:embed templates
+var templates embed.FS
+
+// Options The settings of the scaffolded setup
+type Options struct {
+	// Database MySQL or Mongo
+	Database string
+
+	// Standalone If true, the migrations are file migrations (see migration.FileMigration), ran
+	// by the cmd/migrate binary, configured via a migrate.env file. Otherwise, the migrations are
+	// Go migrations, ran by a generated main.go.
+	Standalone bool
+
+	// ModulePath The Go module of the project, used by the main.go to import the migrations
+	// package. Defaults to the module from the go.mod file of the project directory. Ignored for
+	// standalone setups.
+	ModulePath string
+
+	// Clock Used to generate the version of the example migration. Defaults to clock.System
+	Clock clock.Clock
+}
+
+// File A scaffolded file
+type File struct {
+	// Path The file path, relative to the project directory, with forward slashes
+	Path       string
+	Contents   string
+	Executable bool
+}
+
+// templateData The data used to render the templates
+type templateData struct {
+	ModulePath string
+	Version    uint64
+}
+
+// scaffoldFile Maps a template to the path of the file it renders
+type scaffoldFile struct {
+	tmpl       string
+	path       string
+	executable bool
+}
+
+// Render Returns the files of the setup for the project directory, without writing them
+func Render(projectDir string, opts Options) ([]File, error) {
+	if opts.Database != MySQL && opts.Database != Mongo {
+		return nil, fmt.Errorf(
+			"%w, unsupported database %q, it must be %s or %s", ErrScaffold, opts.Database,
+			MySQL, Mongo,
+		)
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = clock.System{}
+	}
+
+	data := templateData{Version: uint64(opts.Clock.Now().Unix())}
+	dbTmpl := func(name string) string {
+		return path.Join("templates", opts.Database, name+".tmpl")
+	}
+	files := []scaffoldFile{{tmpl: dbTmpl("docker-compose.yaml"), path: "docker-compose.yaml"}}
+
+	if opts.Standalone {
+		ext := ".sql"
+		if opts.Database == Mongo {
+			ext = ".json"
+		}
+
+		files = append(
+			files,
+			scaffoldFile{
+				tmpl: dbTmpl("up" + ext),
+				path: path.Join("migrations", migration.UpFileName(data.Version, ext)),
+			},
+			scaffoldFile{
+				tmpl: dbTmpl("down" + ext),
+				path: path.Join("migrations", migration.DownFileName(data.Version, ext)),
+			},
+			scaffoldFile{tmpl: dbTmpl("migrate.env"), path: "migrate.env"},
+			scaffoldFile{
+				tmpl:       "templates/test-migrations.sh.tmpl",
+				path:       "scripts/test-migrations.sh",
+				executable: true,
+			},
+		)
+	} else {
+		modulePath, err := resolveModulePath(projectDir, opts.ModulePath)
+		if err != nil {
+			return nil, err
+		}
+		data.ModulePath = modulePath
+		versionStr := strconv.FormatUint(data.Version, 10)
+
+		files = append(
+			files,
+			scaffoldFile{
+				tmpl: dbTmpl("migration.go"),
+				path: path.Join(
+					"migrations",
+					migration.FileNamePrefix+migration.FileNameSeparator+versionStr+".go",
+				),
+			},
+			scaffoldFile{tmpl: dbTmpl("main.go"), path: "cmd/migrations/main.go"},
+			scaffoldFile{tmpl: dbTmpl("main_test.go"), path: "cmd/migrations/main_test.go"},
+		)
+	}
+
+	var rendered []File
+	for _, file := range files {
+		contents, err := renderTemplate(file.tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(
+			rendered, File{Path: file.path, Contents: contents, Executable: file.executable},
+		)
+	}
+
+	return rendered, nil
+}
+
+// Generate Writes the files returned by Render in the project directory, creating the missing
+// directories. Existing files are never overwritten: if any of the files already exist, nothing
+// is written. Returns the written files.
+func Generate(projectDir string, opts Options) ([]File, error) {
+	files, err := Render(projectDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []string
+	for _, file := range files {
+		if _, err = os.Stat(filepath.Join(projectDir, filepath.FromSlash(file.Path))); err == nil {
+			existing = append(existing, file.Path)
+		}
+	}
+
+	if len(existing) > 0 {
+		return nil, fmt.Errorf(
+			"%w, files already exist: %s", ErrScaffold, strings.Join(existing, ", "),
+		)
+	}
+
+	for _, file := range files {
+		filePath := filepath.Join(projectDir, filepath.FromSlash(file.Path))
+		perm := os.FileMode(0644)
+		if file.Executable {
+			perm = 0755
+		}
+
+		if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf(
+				"%w, directory creation failed with error: %w", ErrScaffold, err,
+			)
+		}
+
+		if err = os.WriteFile(filePath, []byte(file.Contents), perm); err != nil {
+			return nil, fmt.Errorf("%w, file creation failed with error: %w", ErrScaffold, err)
+		}
+	}
+
+	return files, nil
+}
+
+func renderTemplate(name string, data templateData) (string, error) {
+	tmpl, err := template.ParseFS(templates, name)
+	if err != nil {
+		return "", fmt.Errorf("%w, template parsing failed with error: %w", ErrScaffold, err)
+	}
+
+	var contents bytes.Buffer
+	if err = tmpl.Execute(&contents, data); err != nil {
+		return "", fmt.Errorf("%w, template execution failed with error: %w", ErrScaffold, err)
+	}
+
+	return contents.String(), nil
+}
+
+// resolveModulePath Returns the module path, if provided, otherwise the module from the go.mod
+// file of the project directory
+func resolveModulePath(projectDir string, modulePath string) (string, error) {
+	if modulePath != "" {
+		return modulePath, nil
+	}
+
+	goMod, err := os.Open(filepath.Join(projectDir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w, no module path provided and the go.mod file could not be read: %w",
+			ErrScaffold, err,
+		)
+	}
+	defer func() { _ = goMod.Close() }()
+
+	scanner := bufio.NewScanner(goMod)
+	for scanner.Scan() {
+		if module, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); found {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w, no module directive found in go.mod", ErrScaffold)
+}
@@ -0,0 +1,148 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/clock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScaffoldTestSuite struct {
+	suite.Suite
+	clock *clock.Fixed
+}
+
+func TestScaffoldTestSuite(t *testing.T) {
+	suite.Run(t, new(ScaffoldTestSuite))
+}
+
+func (suite *ScaffoldTestSuite) SetupTest() {
+	suite.clock = clock.NewFixed(time.Unix(1717236000, 0))
+}
+
+func filePaths(files []File) []string {
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func (suite *ScaffoldTestSuite) TestItCanRenderStandaloneSetups() {
+	scenarios := map[string][]string{
+		MySQL: {"migrations/version_1717236000.up.sql", "migrations/version_1717236000.down.sql"},
+		Mongo: {
+			"migrations/version_1717236000.up.json", "migrations/version_1717236000.down.json",
+		},
+	}
+
+	for database, migrationFiles := range scenarios {
+		files, err := Render(
+			suite.T().TempDir(),
+			Options{Database: database, Standalone: true, Clock: suite.clock},
+		)
+
+		suite.Require().NoError(err, database)
+		suite.Assert().Equal(
+			append(
+				append([]string{"docker-compose.yaml"}, migrationFiles...),
+				"migrate.env", "scripts/test-migrations.sh",
+			),
+			filePaths(files),
+			database,
+		)
+		suite.Assert().True(files[4].Executable, database)
+		suite.Assert().Contains(files[3].Contents, "MIGRATIONS_DSN="+database, database)
+	}
+}
+
+func (suite *ScaffoldTestSuite) TestItCanRenderGoSetups() {
+	projectDir := suite.T().TempDir()
+	suite.Require().NoError(
+		os.WriteFile(
+			filepath.Join(projectDir, "go.mod"),
+			[]byte("// The app\nmodule example.com/app\n\ngo 1.22\n"),
+			0644,
+		),
+	)
+
+	for _, database := range []string{MySQL, Mongo} {
+		files, err := Render(projectDir, Options{Database: database, Clock: suite.clock})
+
+		suite.Require().NoError(err, database)
+		suite.Assert().Equal(
+			[]string{
+				"docker-compose.yaml",
+				"migrations/version_1717236000.go",
+				"cmd/migrations/main.go",
+				"cmd/migrations/main_test.go",
+			},
+			filePaths(files),
+			database,
+		)
+		suite.Assert().Contains(files[1].Contents, "type Migration1717236000 struct", database)
+		suite.Assert().Contains(files[2].Contents, `"example.com/app/migrations"`, database)
+
+		for _, file := range files[1:] {
+			_, err = parser.ParseFile(token.NewFileSet(), file.Path, file.Contents, 0)
+			suite.Assert().NoError(err, file.Path)
+		}
+	}
+
+	files, err := Render(
+		suite.T().TempDir(),
+		Options{Database: MySQL, ModulePath: "example.com/other", Clock: suite.clock},
+	)
+	suite.Require().NoError(err)
+	suite.Assert().Contains(files[2].Contents, `"example.com/other/migrations"`)
+}
+
+func (suite *ScaffoldTestSuite) TestItFailsToRenderInvalidSetups() {
+	_, err := Render(suite.T().TempDir(), Options{Database: "postgres", Standalone: true})
+	suite.Assert().ErrorIs(err, ErrScaffold)
+	suite.Assert().ErrorContains(err, "unsupported database")
+
+	_, err = Render(suite.T().TempDir(), Options{Database: MySQL})
+	suite.Assert().ErrorIs(err, ErrScaffold)
+	suite.Assert().ErrorContains(err, "no module path provided")
+
+	projectDir := suite.T().TempDir()
+	_ = os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("go 1.22\n"), 0644)
+	_, err = Render(projectDir, Options{Database: MySQL})
+	suite.Assert().ErrorContains(err, "no module directive found")
+}
+
+func (suite *ScaffoldTestSuite) TestItGeneratesFilesWithoutOverwritingExistingOnes() {
+	projectDir := suite.T().TempDir()
+	opts := Options{Database: MySQL, Standalone: true, Clock: suite.clock}
+
+	files, err := Generate(projectDir, opts)
+	suite.Require().NoError(err)
+	suite.Assert().Len(files, 5)
+
+	for _, file := range files {
+		contents, readErr := os.ReadFile(filepath.Join(projectDir, file.Path))
+		suite.Assert().NoError(readErr)
+		suite.Assert().Equal(file.Contents, string(contents))
+	}
+
+	info, err := os.Stat(filepath.Join(projectDir, "scripts", "test-migrations.sh"))
+	suite.Require().NoError(err)
+	suite.Assert().NotZero(info.Mode() & 0100)
+
+	otherDir := suite.T().TempDir()
+	_ = os.WriteFile(filepath.Join(otherDir, "migrate.env"), []byte("custom"), 0644)
+	_, err = Generate(otherDir, opts)
+	suite.Assert().ErrorIs(err, ErrScaffold)
+	suite.Assert().ErrorContains(err, "files already exist: migrate.env")
+
+	entries, _ := os.ReadDir(otherDir)
+	suite.Assert().Len(entries, 1)
+	contents, _ := os.ReadFile(filepath.Join(otherDir, "migrate.env"))
+	suite.Assert().Equal("custom", string(contents))
+}
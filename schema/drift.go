@@ -0,0 +1,112 @@
+// Package schema includes database schema related logic, like detecting schema changes made
+// outside migrations (drift).
+package schema
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DriftDetector Must be implemented by any mechanism which can detect if the live database
+// schema differs from the schema expected after the last executed migration (for example, a
+// schema hand-edited outside migrations)
+type DriftDetector interface {
+	// DetectDrift must return a human-readable description for each detected difference.
+	// lastExecutedVersion is 0 if no migration was executed.
+	DetectDrift(lastExecutedVersion uint64) ([]string, error)
+}
+
+// Fingerprinter Must return a stable fingerprint (hash) of the live database schema
+type Fingerprinter interface {
+	Fingerprint() (string, error)
+}
+
+// HashSource Must return the expected schema fingerprint after the provided migration version
+// was executed. found must be false if no fingerprint is known for that version.
+type HashSource interface {
+	ExpectedHash(version uint64) (hash string, found bool, err error)
+}
+
+// HashDriftDetector DriftDetector implementation which compares the live schema fingerprint with
+// the expected fingerprint for the last executed version (for example, stored when the
+// migration was executed). Versions without a known fingerprint are not checked.
+type HashDriftDetector struct {
+	Live     Fingerprinter
+	Expected HashSource
+}
+
+func (d *HashDriftDetector) DetectDrift(lastExecutedVersion uint64) ([]string, error) {
+	expected, found, err := d.Expected.ExpectedHash(lastExecutedVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load expected schema hash with error: %w", err)
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	live, err := d.Live.Fingerprint()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fingerprint live schema with error: %w", err)
+	}
+
+	if live != expected {
+		return []string{
+			fmt.Sprintf(
+				"schema hash %s does not match expected hash %s for version %d",
+				live, expected, lastExecutedVersion,
+			),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// InformationSchemaFingerprinter Fingerprinter implementation for MySQL compatible databases,
+// based on information_schema. The fingerprint is the sha256 hash of all tables' columns
+// definitions. ExcludedTables can be used to ignore, for example, the migration executions table.
+type InformationSchemaFingerprinter struct {
+	Db             *sql.DB
+	SchemaName     string
+	ExcludedTables []string
+}
+
+func (f *InformationSchemaFingerprinter) Fingerprint() (string, error) {
+	rows, err := f.Db.Query(
+		"SELECT table_name, column_name, data_type, is_nullable, COALESCE(column_default, '')"+
+			" FROM information_schema.columns WHERE table_schema = ?"+
+			" ORDER BY table_name, column_name",
+		f.SchemaName,
+	)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rows.Close() }()
+
+	excluded := make(map[string]bool)
+	for _, table := range f.ExcludedTables {
+		excluded[table] = true
+	}
+
+	hash := sha256.New()
+	for rows.Next() {
+		columns := make([]string, 5)
+		err = rows.Scan(&columns[0], &columns[1], &columns[2], &columns[3], &columns[4])
+		if err != nil {
+			return "", err
+		}
+
+		if !excluded[columns[0]] {
+			hash.Write([]byte(strings.Join(columns, "|") + "\n"))
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
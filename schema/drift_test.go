@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DriftTestSuite struct {
+	suite.Suite
+}
+
+func TestDriftTestSuite(t *testing.T) {
+	suite.Run(t, new(DriftTestSuite))
+}
+
+type fakeFingerprinter struct {
+	hash string
+	err  error
+}
+
+func (f *fakeFingerprinter) Fingerprint() (string, error) {
+	return f.hash, f.err
+}
+
+type fakeHashSource map[uint64]string
+
+func (f fakeHashSource) ExpectedHash(version uint64) (string, bool, error) {
+	hash, found := f[version]
+	return hash, found, nil
+}
+
+func (suite *DriftTestSuite) TestItCanDetectDrift() {
+	expected := fakeHashSource{1: "abc", 2: "def"}
+
+	scenarios := map[string]struct {
+		version       uint64
+		live          *fakeFingerprinter
+		expectedDrift int
+		expectedErr   bool
+	}{
+		"no drift":             {1, &fakeFingerprinter{hash: "abc"}, 0, false},
+		"drift":                {2, &fakeFingerprinter{hash: "abc"}, 1, false},
+		"unknown version":      {3, &fakeFingerprinter{hash: "abc"}, 0, false},
+		"fingerprinting fails": {1, &fakeFingerprinter{err: errors.New("err")}, 0, true},
+	}
+
+	for name, scenario := range scenarios {
+		detector := &HashDriftDetector{Live: scenario.live, Expected: expected}
+		drift, err := detector.DetectDrift(scenario.version)
+
+		suite.Assert().Len(drift, scenario.expectedDrift, "failed scenario %s", name)
+		suite.Assert().Equal(scenario.expectedErr, err != nil, "failed scenario %s", name)
+	}
+}
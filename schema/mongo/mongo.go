@@ -0,0 +1,70 @@
+// Package mongo includes the schema.Dumper implementation for MongoDb
+package mongo
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// Dumper schema.Dumper implementation for MongoDb. The dump includes all collections (ordered by
+// name) with their indexes. ExcludedCollections can be used to ignore, for example, the
+// migration executions collection.
+type Dumper struct {
+	Client              *mongodriver.Client
+	DatabaseName        string
+	ExcludedCollections []string
+	Ctx                 context.Context
+}
+
+func (d *Dumper) Dump() (string, error) {
+	ctx := d.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	db := d.Client.Database(d.DatabaseName)
+	names, err := db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return "", err
+	}
+	slices.Sort(names)
+
+	var dump strings.Builder
+	for _, name := range names {
+		if slices.Contains(d.ExcludedCollections, name) {
+			continue
+		}
+
+		cursor, err := db.Collection(name).Indexes().List(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		var indexes []bson.M
+		if err = cursor.All(ctx, &indexes); err != nil {
+			return "", err
+		}
+
+		var indexDefs []string
+		for _, index := range indexes {
+			delete(index, "v")
+			indexDef, err := bson.MarshalExtJSON(index, true, false)
+			if err != nil {
+				return "", err
+			}
+			indexDefs = append(indexDefs, string(indexDef))
+		}
+		slices.Sort(indexDefs)
+
+		dump.WriteString("collection " + name + "\n")
+		for _, indexDef := range indexDefs {
+			dump.WriteString("  index " + indexDef + "\n")
+		}
+	}
+
+	return dump.String(), nil
+}
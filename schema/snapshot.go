@@ -0,0 +1,256 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Dumper Must return a textual dump of the live database schema (no data). The dump should be
+// stable: dumping the same schema twice must give the same result.
+type Dumper interface {
+	Dump() (string, error)
+}
+
+// SnapshotStore Must persist schema dumps, keyed by the migration version after which they
+// were captured
+type SnapshotStore interface {
+	// SaveSnapshot must persist the dump for the provided version, replacing any existing one
+	SaveSnapshot(version uint64, dump string) error
+
+	// LoadSnapshot must return the dump for the provided version. found must be false if
+	// there is no dump for that version.
+	LoadSnapshot(version uint64) (dump string, found bool, err error)
+
+	// LatestSnapshot must return the dump with the greatest version. found must be false if
+	// there are no dumps.
+	LatestSnapshot() (version uint64, dump string, found bool, err error)
+}
+
+// Restorer Must recreate a schema, in an empty database, from a dump generated by a Dumper
+type Restorer interface {
+	Restore(dump string) error
+}
+
+// SnapshotFilePrefix File name prefix for all snapshot files saved by DirSnapshotStore
+const SnapshotFilePrefix = "snapshot_"
+
+// DirSnapshotStore SnapshotStore implementation which saves each dump as a file
+// (snapshot_{version}.sql) in the provided directory
+type DirSnapshotStore struct {
+	DirPath string
+}
+
+func (s *DirSnapshotStore) snapshotPath(version uint64) string {
+	return filepath.Join(s.DirPath, SnapshotFilePrefix+strconv.FormatUint(version, 10)+".sql")
+}
+
+func (s *DirSnapshotStore) SaveSnapshot(version uint64, dump string) error {
+	return os.WriteFile(s.snapshotPath(version), []byte(dump), 0600)
+}
+
+func (s *DirSnapshotStore) LoadSnapshot(version uint64) (string, bool, error) {
+	contents, err := os.ReadFile(s.snapshotPath(version))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	return string(contents), true, nil
+}
+
+func (s *DirSnapshotStore) LatestSnapshot() (uint64, string, bool, error) {
+	entries, err := os.ReadDir(s.DirPath)
+	if err != nil {
+		return 0, "", false, err
+	}
+
+	var latest uint64
+	found := false
+	for _, entry := range entries {
+		name, isSnapshot := strings.CutPrefix(entry.Name(), SnapshotFilePrefix)
+		if entry.IsDir() || !isSnapshot {
+			continue
+		}
+
+		version, err := strconv.ParseUint(strings.TrimSuffix(name, ".sql"), 10, 64)
+		if err == nil && (!found || version > latest) {
+			latest = version
+			found = true
+		}
+	}
+
+	if !found {
+		return 0, "", false, nil
+	}
+
+	dump, found, err := s.LoadSnapshot(latest)
+	return latest, dump, found, err
+}
+
+// DumpDriftDetector DriftDetector implementation which compares a fresh dump of the live schema
+// with the snapshot stored for the last executed version. Versions without a snapshot are
+// not checked.
+type DumpDriftDetector struct {
+	Dumper Dumper
+	Store  SnapshotStore
+}
+
+func (d *DumpDriftDetector) DetectDrift(lastExecutedVersion uint64) ([]string, error) {
+	expected, found, err := d.Store.LoadSnapshot(lastExecutedVersion)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	live, err := d.Dumper.Dump()
+	if err != nil {
+		return nil, err
+	}
+
+	if live != expected {
+		return []string{
+			fmt.Sprintf(
+				"live schema does not match the snapshot captured after version %d",
+				lastExecutedVersion,
+			),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+var autoIncrementRegex = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// MysqlDumper Dumper implementation for MySQL compatible databases. The dump includes the
+// SHOW CREATE TABLE output for all tables (ordered by name), without auto increment counters.
+// ExcludedTables can be used to ignore, for example, the migration executions table.
+type MysqlDumper struct {
+	Db             *sql.DB
+	ExcludedTables []string
+}
+
+func (d *MysqlDumper) Dump() (string, error) {
+	rows, err := d.Db.Query("SHOW FULL TABLES WHERE Table_type = 'BASE TABLE'")
+	if err != nil {
+		return "", err
+	}
+
+	var tables []string
+	for rows.Next() {
+		var table, tableType string
+		if err = rows.Scan(&table, &tableType); err != nil {
+			_ = rows.Close()
+			return "", err
+		}
+		tables = append(tables, table)
+	}
+
+	if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+		return "", err
+	}
+
+	slices.Sort(tables)
+
+	var dump strings.Builder
+	for _, table := range tables {
+		if slices.Contains(d.ExcludedTables, table) {
+			continue
+		}
+
+		var name, createStatement string
+		err = d.Db.QueryRow("SHOW CREATE TABLE `"+table+"`").Scan(&name, &createStatement)
+		if err != nil {
+			return "", err
+		}
+
+		dump.WriteString(autoIncrementRegex.ReplaceAllString(createStatement, "") + ";\n\n")
+	}
+
+	return dump.String(), nil
+}
+
+// MysqlRestorer Restorer implementation for dumps generated by MysqlDumper
+type MysqlRestorer struct {
+	Db *sql.DB
+}
+
+func (r *MysqlRestorer) Restore(dump string) error {
+	for _, statement := range strings.Split(dump, ";\n\n") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+
+		if _, err := r.Db.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CommandDumper Dumper implementation which runs an external command and uses its standard
+// output as the dump. For example: pg_dump --schema-only --no-owner mydb
+type CommandDumper struct {
+	Ctx  context.Context
+	Name string
+	Args []string
+}
+
+func (d *CommandDumper) Dump() (string, error) {
+	ctx := d.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.Name, d.Args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(
+			"dump command failed with error: %w, %s", err, strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return stdout.String(), nil
+}
+
+// CommandRestorer Restorer implementation which runs an external command with the dump as its
+// standard input. For example: psql --single-transaction mydb
+type CommandRestorer struct {
+	Ctx  context.Context
+	Name string
+	Args []string
+}
+
+func (r *CommandRestorer) Restore(dump string) error {
+	ctx := r.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.Name, r.Args...)
+	cmd.Stdin = strings.NewReader(dump)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(
+			"restore command failed with error: %w, %s", err, strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SnapshotTestSuite struct {
+	suite.Suite
+}
+
+func TestSnapshotTestSuite(t *testing.T) {
+	suite.Run(t, new(SnapshotTestSuite))
+}
+
+type fakeDumper string
+
+func (f fakeDumper) Dump() (string, error) {
+	return string(f), nil
+}
+
+func (suite *SnapshotTestSuite) TestItCanSaveAndLoadSnapshots() {
+	store := &DirSnapshotStore{DirPath: suite.T().TempDir()}
+
+	dump, found, err := store.LoadSnapshot(1)
+	suite.Assert().NoError(err)
+	suite.Assert().False(found)
+	suite.Assert().Empty(dump)
+
+	suite.Assert().NoError(store.SaveSnapshot(1, "CREATE TABLE a;"))
+	suite.Assert().NoError(store.SaveSnapshot(1, "CREATE TABLE b;"))
+
+	dump, found, err = store.LoadSnapshot(1)
+	suite.Assert().NoError(err)
+	suite.Assert().True(found)
+	suite.Assert().Equal("CREATE TABLE b;", dump)
+}
+
+func (suite *SnapshotTestSuite) TestItCanDetectDriftFromSnapshots() {
+	store := &DirSnapshotStore{DirPath: suite.T().TempDir()}
+	_ = store.SaveSnapshot(1, "CREATE TABLE a;")
+
+	detector := &DumpDriftDetector{Dumper: fakeDumper("CREATE TABLE a;"), Store: store}
+	drift, err := detector.DetectDrift(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(drift)
+
+	detector.Dumper = fakeDumper("CREATE TABLE a2;")
+	drift, err = detector.DetectDrift(1)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(drift, 1)
+
+	drift, err = detector.DetectDrift(2)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(drift)
+}
+
+func (suite *SnapshotTestSuite) TestItCanDumpUsingExternalCommand() {
+	dump, err := (&CommandDumper{Name: "echo", Args: []string{"CREATE TABLE a;"}}).Dump()
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("CREATE TABLE a;\n", dump)
+
+	_, err = (&CommandDumper{Name: "false"}).Dump()
+	suite.Assert().ErrorContains(err, "dump command failed")
+}
+
+func (suite *SnapshotTestSuite) TestItCanLoadLatestSnapshot() {
+	store := &DirSnapshotStore{DirPath: suite.T().TempDir()}
+
+	_, _, found, err := store.LatestSnapshot()
+	suite.Assert().NoError(err)
+	suite.Assert().False(found)
+
+	_ = store.SaveSnapshot(20, "b")
+	_ = store.SaveSnapshot(3, "a")
+
+	version, dump, found, err := store.LatestSnapshot()
+	suite.Assert().NoError(err)
+	suite.Assert().True(found)
+	suite.Assert().Equal(uint64(20), version)
+	suite.Assert().Equal("b", dump)
+}
+
+func (suite *SnapshotTestSuite) TestItCanRestoreUsingExternalCommand() {
+	suite.Assert().NoError((&CommandRestorer{Name: "cat"}).Restore("CREATE TABLE a;"))
+	suite.Assert().ErrorContains(
+		(&CommandRestorer{Name: "false"}).Restore("CREATE TABLE a;"), "restore command failed",
+	)
+}
@@ -0,0 +1,461 @@
+// Package slack includes an http.Handler for Slack slash commands (for example, /migrate), so
+// on-call engineers can inspect and nudge migrations from chat: status, plan and up, which is
+// only run after confirmation and only for allow-listed users.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+)
+
+// The headers of the Slack request signature (see
+// https://api.slack.com/authentication/verifying-requests-from-slack)
+const (
+	SignatureHeader = "X-Slack-Signature"
+	TimestampHeader = "X-Slack-Request-Timestamp"
+)
+
+// ErrInvalidSignature is returned (wrapped) by VerifySignature when the signature does not match
+// the request or the request is too old
+var ErrInvalidSignature = errors.New("invalid slack request signature")
+
+// maxRequestAge Requests signed earlier are rejected, to prevent replays
+const maxRequestAge = 5 * time.Minute
+
+// maxListedMigrations The maximum number of migrations listed in the responses
+const maxListedMigrations = 20
+
+// Settings The slash command settings
+type Settings struct {
+	// SigningSecret The signing secret of the Slack app, used to verify the requests
+	SigningSecret string
+
+	// AllowedUsers The Slack user IDs (for example, U012AB3CD) allowed to use the command.
+	// Requests from other users are refused.
+	AllowedUsers []string
+
+	// ConfirmationTTL How long an up confirmation code is valid. Defaults to 5 minutes
+	ConfirmationTTL time.Duration
+}
+
+// confirmation A pending up request, waiting for confirmation
+type confirmation struct {
+	code      string
+	steps     handler.Steps
+	pending   []uint64
+	expiresAt time.Time
+}
+
+// Command http.Handler which serves a Slack slash command. The command text selects the action:
+//   - status: the registered, finished and pending migrations counts and the last execution
+//   - plan: the pending migrations
+//   - up [N|all]: replies with the migrations which would run and a confirmation code.
+//     up [N|all] confirm <code>, sent by the same user before the code expires and while the
+//     pending migrations did not change, runs them. The result is posted to the response_url
+//     of the request, since Slack requires a reply within 3 seconds.
+//
+// The request signature is verified and the user must be allow-listed. Only one run is allowed
+// at a time. Runs outlive the requests which started them, so servers must call Shutdown before
+// exiting.
+type Command struct {
+	migrations *handler.MigrationsHandler
+	settings   Settings
+	client     *http.Client
+	now        func() time.Time
+
+	mu            sync.Mutex
+	confirmations map[string]confirmation
+	// runDone Closed when the run in progress finishes, nil if no run is in progress
+	runDone      chan struct{}
+	shuttingDown bool
+}
+
+// NewCommand Builds a new Command. If client is nil, an HTTP client with a 10 seconds timeout
+// is used to post the run results.
+func NewCommand(
+	migrations *handler.MigrationsHandler,
+	settings Settings,
+	client *http.Client,
+) (*Command, error) {
+	if settings.SigningSecret == "" {
+		return nil, errors.New("the slack signing secret is required")
+	}
+
+	if len(settings.AllowedUsers) == 0 {
+		return nil, errors.New("at least one allowed slack user is required")
+	}
+
+	if settings.ConfirmationTTL == 0 {
+		settings.ConfirmationTTL = 5 * time.Minute
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Command{
+		migrations:    migrations,
+		settings:      settings,
+		client:        client,
+		now:           time.Now,
+		confirmations: map[string]confirmation{},
+	}, nil
+}
+
+// message A slash command response (or a message posted to the response_url)
+type message struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// ephemeral A response shown only to the user who sent the command
+func ephemeral(format string, args ...any) message {
+	return message{ResponseType: "ephemeral", Text: fmt.Sprintf(format, args...)}
+}
+
+func (command *Command) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64*1024))
+	if err != nil {
+		http.Error(w, "failed to read the request", http.StatusBadRequest)
+		return
+	}
+
+	err = VerifySignature(
+		[]byte(command.settings.SigningSecret), r.Header, body, command.now(),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid slash command payload", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(command.handle(form))
+}
+
+// handle Runs the action of the slash command payload and returns the response
+func (command *Command) handle(form url.Values) message {
+	user := form.Get("user_id")
+	if !slices.Contains(command.settings.AllowedUsers, user) {
+		return ephemeral("Sorry, you are not allowed to manage migrations.")
+	}
+
+	args := strings.Fields(form.Get("text"))
+	if len(args) == 0 {
+		return command.usage()
+	}
+
+	switch args[0] {
+	case "status":
+		return command.status()
+	case "plan":
+		return command.plan()
+	case "up":
+		return command.up(user, args[1:], form.Get("response_url"))
+	}
+	return command.usage()
+}
+
+func (command *Command) usage() message {
+	return ephemeral(
+		"Usage: `status`, `plan` or `up [N|all]` (asks for confirmation before running)",
+	)
+}
+
+func (command *Command) status() message {
+	summary, err := command.migrations.Summary()
+	if err != nil {
+		return ephemeral("Failed to load the migrations status: %s", err)
+	}
+
+	text := fmt.Sprintf(
+		"%d registered, %d finished, %d pending",
+		summary.RegisteredCount, summary.FinishedCount, summary.PendingCount(),
+	)
+
+	if last := summary.LastExecuted; last.Execution != nil {
+		state := "finished"
+		if !last.Execution.Finished() {
+			state = "*unfinished*"
+		}
+		text += fmt.Sprintf(
+			"\nLast executed: %s, %s, at %s", command.migrations.DisplayName(last.Execution.Version),
+			state, execution.FormatTimestampMs(last.Execution.ExecutedAtMs),
+		)
+	}
+
+	if summary.NextToExecute != nil {
+		text += "\nNext: " + command.migrations.DisplayName(summary.NextToExecute.Version())
+	}
+	return ephemeral("%s", text)
+}
+
+// pending Returns the versions of the pending migrations
+func (command *Command) pending() ([]uint64, error) {
+	plan, err := command.migrations.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []uint64
+	for _, mig := range plan.AllToBeExecuted() {
+		versions = append(versions, mig.Version())
+	}
+	return versions, nil
+}
+
+// list Lists the migrations, limited to maxListedMigrations
+func (command *Command) list(versions []uint64) string {
+	var lines []string
+	for i, version := range versions {
+		if i == maxListedMigrations {
+			lines = append(lines, fmt.Sprintf("• … and %d more", len(versions)-i))
+			break
+		}
+		lines = append(lines, "• "+command.migrations.DisplayName(version))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (command *Command) plan() message {
+	pending, err := command.pending()
+	if err != nil {
+		return ephemeral("Failed to load the pending migrations: %s", err)
+	}
+
+	if len(pending) == 0 {
+		return ephemeral("No pending migrations.")
+	}
+	return ephemeral("%d pending migrations:\n%s", len(pending), command.list(pending))
+}
+
+// up Asks for confirmation or, for confirmed requests, starts the run
+func (command *Command) up(user string, args []string, responseURL string) message {
+	rawSteps := ""
+	if len(args) > 0 && args[0] != "confirm" {
+		rawSteps, args = args[0], args[1:]
+	}
+
+	steps, err := handler.ParseSteps(rawSteps)
+	if err != nil {
+		return ephemeral("%s", err)
+	}
+
+	pending, err := command.pending()
+	if err != nil {
+		return ephemeral("Failed to load the pending migrations: %s", err)
+	}
+
+	if !steps.All && steps.N < len(pending) {
+		pending = pending[:steps.N]
+	}
+
+	if len(pending) == 0 {
+		return ephemeral("No pending migrations.")
+	}
+
+	command.mu.Lock()
+	defer command.mu.Unlock()
+
+	if len(args) != 2 || args[0] != "confirm" {
+		return command.askConfirmation(user, steps, pending, rawSteps)
+	}
+
+	expected, found := command.confirmations[user]
+	if !found || expected.code != args[1] || command.now().After(expected.expiresAt) ||
+		expected.steps != steps || !slices.Equal(expected.pending, pending) {
+		return ephemeral(
+			"The confirmation code is invalid or expired, or the pending migrations changed." +
+				" Send `up` again.",
+		)
+	}
+
+	if command.shuttingDown {
+		return ephemeral("The server is shutting down, no new runs are started.")
+	}
+
+	if command.runDone != nil {
+		return ephemeral("A migrations run is already in progress.")
+	}
+
+	delete(command.confirmations, user)
+	command.runDone = make(chan struct{})
+	go command.run(user, steps, responseURL, command.runDone)
+
+	return message{
+		ResponseType: "in_channel",
+		Text: fmt.Sprintf(
+			"<@%s> started running %d migrations:\n%s", user, len(pending), command.list(pending),
+		),
+	}
+}
+
+// askConfirmation Replies with the migrations which would run and a new confirmation code.
+// Must be called with the lock held.
+func (command *Command) askConfirmation(
+	user string,
+	steps handler.Steps,
+	pending []uint64,
+	rawSteps string,
+) message {
+	code := make([]byte, 3)
+	_, _ = rand.Read(code)
+
+	pendingConfirmation := confirmation{
+		code:      hex.EncodeToString(code),
+		steps:     steps,
+		pending:   pending,
+		expiresAt: command.now().Add(command.settings.ConfirmationTTL),
+	}
+	command.confirmations[user] = pendingConfirmation
+
+	confirm := "up confirm " + pendingConfirmation.code
+	if rawSteps != "" {
+		confirm = "up " + rawSteps + " confirm " + pendingConfirmation.code
+	}
+	return ephemeral(
+		"%d migrations will run:\n%s\nTo proceed, send `%s` within %s.",
+		len(pending), command.list(pending), confirm, command.settings.ConfirmationTTL,
+	)
+}
+
+// run Runs the migrations and posts the result to the response URL. Closes done when finished.
+func (command *Command) run(
+	user string,
+	steps handler.Steps,
+	responseURL string,
+	done chan struct{},
+) {
+	defer func() {
+		command.mu.Lock()
+		command.runDone = nil
+		command.mu.Unlock()
+		close(done)
+	}()
+
+	executed, err := command.migrations.MigrateUp(steps.NumOfRuns())
+
+	var versions []uint64
+	for _, exec := range executed {
+		versions = append(versions, exec.Migration.Version())
+	}
+
+	result := message{ResponseType: "in_channel"}
+	if err != nil {
+		result.Text = fmt.Sprintf(
+			"<@%s> the migrations run failed after %d migrations: %s", user, len(executed), err,
+		)
+	} else {
+		result.Text = fmt.Sprintf("<@%s> executed %d migrations", user, len(executed))
+	}
+	if len(versions) > 0 {
+		result.Text += ":\n" + command.list(versions)
+	}
+
+	_ = command.post(responseURL, result)
+}
+
+// Wait Blocks until the run in progress, if any, finishes and its result is posted
+func (command *Command) Wait() {
+	command.mu.Lock()
+	done := command.runDone
+	command.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// Shutdown Refuses new runs and waits for the run in progress, if any, to finish, so the process
+// does not exit in the middle of a migration. Returns the context error if the context is done
+// first (the run keeps going).
+func (command *Command) Shutdown(ctx context.Context) error {
+	command.mu.Lock()
+	command.shuttingDown = true
+	done := command.runDone
+	command.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// post Posts the message to the response URL
+func (command *Command) post(responseURL string, msg message) error {
+	if responseURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	response, err := command.client.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	return response.Body.Close()
+}
+
+// VerifySignature Checks the Slack signature of the request body: v0= followed by the hex
+// HMAC-SHA256 of v0:<timestamp>:<body>, keyed with the signing secret. Requests signed more than
+// 5 minutes before now are rejected, to prevent replays. Errors with ErrInvalidSignature.
+func VerifySignature(secret []byte, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get(TimestampHeader)
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or invalid timestamp", ErrInvalidSignature)
+	}
+
+	if age := now.Sub(time.Unix(signedAt, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("%w: the request timestamp is too old", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get(SignatureHeader))) {
+		return fmt.Errorf("%w: the signature does not match the request", ErrInvalidSignature)
+	}
+	return nil
+}
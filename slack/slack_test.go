@@ -0,0 +1,242 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+const signingSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+type SlackTestSuite struct {
+	suite.Suite
+	now       time.Time
+	repo      *execution.InMemoryRepository
+	command   *Command
+	responses *httptest.Server
+	posted    []message
+	mu        sync.Mutex
+}
+
+func TestSlackTestSuite(t *testing.T) {
+	suite.Run(t, new(SlackTestSuite))
+}
+
+func (suite *SlackTestSuite) SetupTest() {
+	registry := migration.NewGenericRegistry()
+	for version := uint64(1); version <= 3; version++ {
+		_ = registry.Register(migration.NewDummyMigration(version))
+	}
+
+	suite.repo = &execution.InMemoryRepository{}
+	migrations, err := handler.NewHandler(registry, suite.repo, nil)
+	suite.Require().NoError(err)
+
+	suite.command, err = NewCommand(
+		migrations, Settings{SigningSecret: signingSecret, AllowedUsers: []string{"U1"}}, nil,
+	)
+	suite.Require().NoError(err)
+
+	suite.now = time.Unix(1531420618, 0)
+	suite.command.now = func() time.Time { return suite.now }
+
+	suite.posted = nil
+	suite.responses = httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var posted message
+			_ = json.NewDecoder(r.Body).Decode(&posted)
+			suite.mu.Lock()
+			suite.posted = append(suite.posted, posted)
+			suite.mu.Unlock()
+		}),
+	)
+}
+
+func (suite *SlackTestSuite) TearDownTest() {
+	suite.responses.Close()
+}
+
+// send Sends a signed slash command and returns the response
+func (suite *SlackTestSuite) send(user string, text string) (int, message) {
+	body := url.Values{
+		"command": {"/migrate"}, "text": {text}, "user_id": {user},
+		"response_url": {suite.responses.URL},
+	}.Encode()
+
+	timestamp := strconv.FormatInt(suite.now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	request := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set(SignatureHeader, "v0="+hex.EncodeToString(mac.Sum(nil)))
+	response := httptest.NewRecorder()
+	suite.command.ServeHTTP(response, request)
+
+	var reply message
+	_ = json.NewDecoder(response.Body).Decode(&reply)
+	return response.Code, reply
+}
+
+func (suite *SlackTestSuite) TestItReportsTheStatusAndThePlan() {
+	code, reply := suite.send("U1", "status")
+	suite.Assert().Equal(http.StatusOK, code)
+	suite.Assert().Equal("ephemeral", reply.ResponseType)
+	suite.Assert().Equal("3 registered, 0 finished, 3 pending\nNext: migration 1", reply.Text)
+
+	suite.repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1717236000000},
+	}
+	_, reply = suite.send("U1", "status")
+	suite.Assert().Contains(
+		reply.Text, "Last executed: migration 1, *unfinished*, at 2024-06-01T10:00:00.000Z",
+	)
+
+	suite.repo.PersistedExecutions[0].FinishedAtMs = 1717236000001
+	_, reply = suite.send("U1", "plan")
+	suite.Assert().Equal("2 pending migrations:\n• migration 2\n• migration 3", reply.Text)
+
+	_, reply = suite.send("U1", "")
+	suite.Assert().Contains(reply.Text, "Usage:")
+}
+
+func (suite *SlackTestSuite) TestItRunsMigrationsAfterConfirmation() {
+	_, reply := suite.send("U1", "up 2")
+	suite.Require().Contains(reply.Text, "2 migrations will run:\n• migration 1\n• migration 2")
+	confirmation := suite.command.confirmations["U1"]
+	suite.Assert().Contains(reply.Text, "`up 2 confirm "+confirmation.code+"` within 5m0s")
+
+	_, reply = suite.send("U1", "up 2 confirm wrong")
+	suite.Assert().Contains(reply.Text, "invalid or expired")
+	_, reply = suite.send("U1", "up confirm "+confirmation.code)
+	suite.Assert().Contains(reply.Text, "invalid or expired")
+	suite.Assert().Empty(suite.repo.PersistedExecutions)
+
+	_, reply = suite.send("U1", "up 2 confirm "+confirmation.code)
+	suite.Assert().Equal("in_channel", reply.ResponseType)
+	suite.Assert().Contains(reply.Text, "<@U1> started running 2 migrations")
+	suite.command.Wait()
+
+	suite.Assert().Len(suite.repo.PersistedExecutions, 2)
+	suite.Require().Len(suite.posted, 1)
+	suite.Assert().Equal(
+		"<@U1> executed 2 migrations:\n• migration 1\n• migration 2", suite.posted[0].Text,
+	)
+
+	_, reply = suite.send("U1", "up 2 confirm "+confirmation.code)
+	suite.Assert().Contains(reply.Text, "invalid or expired")
+}
+
+// blockingMigration Its Up() blocks until release is closed
+type blockingMigration struct {
+	migration.DummyMigration
+	release chan struct{}
+}
+
+func (mig *blockingMigration) Up() error {
+	<-mig.release
+	return nil
+}
+
+func (suite *SlackTestSuite) TestItWaitsForTheRunInProgressOnShutdown() {
+	release := make(chan struct{})
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(
+		&blockingMigration{DummyMigration: *migration.NewDummyMigration(1), release: release},
+	)
+	_ = registry.Register(migration.NewDummyMigration(2))
+	suite.command.migrations, _ = handler.NewHandler(registry, suite.repo, nil)
+
+	_, _ = suite.send("U1", "up 1")
+	_, reply := suite.send("U1", "up 1 confirm "+suite.command.confirmations["U1"].code)
+	suite.Require().Contains(reply.Text, "<@U1> started running 1 migrations")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	suite.Assert().ErrorIs(suite.command.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(release)
+	suite.Assert().NoError(suite.command.Shutdown(context.Background()))
+	suite.Assert().Len(suite.repo.PersistedExecutions, 1)
+	suite.Assert().Len(suite.posted, 1)
+
+	_, _ = suite.send("U1", "up")
+	_, reply = suite.send("U1", "up confirm "+suite.command.confirmations["U1"].code)
+	suite.Assert().Contains(reply.Text, "shutting down")
+	suite.Assert().Len(suite.repo.PersistedExecutions, 1)
+}
+
+func (suite *SlackTestSuite) TestItRejectsExpiredOrOutdatedConfirmations() {
+	_, _ = suite.send("U1", "up all")
+	code := suite.command.confirmations["U1"].code
+
+	suite.now = suite.now.Add(6 * time.Minute)
+	_, reply := suite.send("U1", "up all confirm "+code)
+	suite.Assert().Contains(reply.Text, "invalid or expired")
+
+	_, _ = suite.send("U1", "up all")
+	code = suite.command.confirmations["U1"].code
+	suite.repo.PersistedExecutions = []execution.MigrationExecution{{Version: 1, FinishedAtMs: 1}}
+	_, reply = suite.send("U1", "up all confirm "+code)
+	suite.Assert().Contains(reply.Text, "pending migrations changed")
+
+	_, reply = suite.send("U1", "up zero")
+	suite.Assert().Contains(reply.Text, "invalid number of migrations to run")
+	suite.Assert().Empty(suite.posted)
+}
+
+func (suite *SlackTestSuite) TestItRefusesUnknownUsersAndUnsignedRequests() {
+	_, reply := suite.send("U2", "status")
+	suite.Assert().Contains(reply.Text, "not allowed")
+
+	request := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader("text=status"))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(suite.now.Unix(), 10))
+	request.Header.Set(SignatureHeader, "v0=00")
+	response := httptest.NewRecorder()
+	suite.command.ServeHTTP(response, request)
+	suite.Assert().Equal(http.StatusUnauthorized, response.Code)
+
+	suite.now = suite.now.Add(time.Hour)
+	err := VerifySignature(
+		[]byte(signingSecret), request.Header, []byte("text=status"), suite.now,
+	)
+	suite.Assert().ErrorIs(err, ErrInvalidSignature)
+	suite.Assert().ErrorContains(err, "too old")
+
+	_, err = NewCommand(nil, Settings{SigningSecret: "secret"}, nil)
+	suite.Assert().ErrorContains(err, "at least one allowed slack user is required")
+}
+
+func (suite *SlackTestSuite) TestItVerifiesSlackSignatures() {
+	// The example from the Slack documentation
+	body := "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&" +
+		"channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&" +
+		"command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2F" +
+		"commands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&" +
+		"trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	header := http.Header{}
+	header.Set(TimestampHeader, "1531420618")
+	header.Set(
+		SignatureHeader,
+		"v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503",
+	)
+
+	suite.Assert().NoError(
+		VerifySignature([]byte(signingSecret), header, []byte(body), suite.now),
+	)
+}
@@ -0,0 +1,156 @@
+// Package sqlcapture includes a database/sql compatible handle which records all statements
+// instead of executing them. It can be used to dry-run database/sql based migrations and see
+// the actual SQL they would execute.
+package sqlcapture
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// Statement A recorded SQL statement together with its arguments
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// Recorder Collects the statements executed via the capturing db handle
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+}
+
+// Statements Returns a copy of all recorded statements, in execution order
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement{}, r.statements...)
+}
+
+// Reset Removes all recorded statements
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = nil
+}
+
+func (r *Recorder) record(query string, args []driver.NamedValue) {
+	var values []any
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, Statement{Query: query, Args: values})
+}
+
+// NewDB Builds a new db handle which records every executed statement (Exec, Query, prepared
+// statements and statements run in transactions) in the returned Recorder. Nothing is sent to
+// a real database. Exec calls report 0 affected rows and queries return no rows.
+func NewDB() (*sql.DB, *Recorder) {
+	recorder := &Recorder{}
+	return sql.OpenDB(&connector{recorder}), recorder
+}
+
+type connector struct {
+	recorder *Recorder
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{c.recorder}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return captureDriver{c}
+}
+
+type captureDriver struct {
+	connector *connector
+}
+
+func (d captureDriver) Open(string) (driver.Conn, error) {
+	return &conn{d.connector.recorder}, nil
+}
+
+type conn struct {
+	recorder *Recorder
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{c.recorder, query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) ExecContext(
+	_ context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	c.recorder.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *conn) QueryContext(
+	_ context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	c.recorder.record(query, args)
+	return emptyRows{}, nil
+}
+
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	recorder *Recorder
+	query    string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.recorder.record(s.query, toNamedValues(args))
+	return driver.RowsAffected(0), nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.recorder.record(s.query, toNamedValues(args))
+	return emptyRows{}, nil
+}
+
+func toNamedValues(args []driver.Value) []driver.NamedValue {
+	var named []driver.NamedValue
+	for i, arg := range args {
+		named = append(named, driver.NamedValue{Ordinal: i + 1, Value: arg})
+	}
+	return named
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
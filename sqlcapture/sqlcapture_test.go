@@ -0,0 +1,52 @@
+package sqlcapture
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLCaptureTestSuite struct {
+	suite.Suite
+}
+
+func TestSQLCaptureTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLCaptureTestSuite))
+}
+
+func (suite *SQLCaptureTestSuite) TestItRecordsStatementsInsteadOfExecutingThem() {
+	db, recorder := NewDB()
+
+	_, err := db.Exec("CREATE TABLE users (id int)")
+	suite.Assert().NoError(err)
+
+	txn, err := db.Begin()
+	suite.Assert().NoError(err)
+	_, err = txn.Exec("INSERT INTO users VALUES (?)", 1)
+	suite.Assert().NoError(err)
+	suite.Assert().NoError(txn.Commit())
+
+	stmt, err := db.Prepare("UPDATE users SET id = ?")
+	suite.Assert().NoError(err)
+	_, err = stmt.Exec(2)
+	suite.Assert().NoError(err)
+
+	var id int
+	err = db.QueryRow("SELECT id FROM users").Scan(&id)
+	suite.Assert().True(errors.Is(err, sql.ErrNoRows))
+
+	suite.Assert().Equal(
+		[]Statement{
+			{Query: "CREATE TABLE users (id int)"},
+			{Query: "INSERT INTO users VALUES (?)", Args: []any{1}},
+			{Query: "UPDATE users SET id = ?", Args: []any{2}},
+			{Query: "SELECT id FROM users"},
+		},
+		recorder.Statements(),
+	)
+
+	recorder.Reset()
+	suite.Assert().Empty(recorder.Statements())
+}
@@ -0,0 +1,184 @@
+package sqlhelpers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/online"
+)
+
+// ErrIrreversible is returned (wrapped) when a change can not be reverted automatically
+var ErrIrreversible = errors.New("change can not be reverted automatically")
+
+// Change A DDL change expressed via the helpers, which can also revert itself, so the Down() of
+// the migrations built from changes is generated (see ReversibleMigration)
+type Change interface {
+	Apply(ctx context.Context, db DB, dialect online.Dialect) error
+	Revert(ctx context.Context, db DB, dialect online.Dialect) error
+}
+
+// CreateTableChange Creates the table (see CreateTableIfNotExists). Reverted by dropping it.
+type CreateTableChange struct {
+	Table       string
+	Columns     []Column
+	Constraints []string
+}
+
+func (change CreateTableChange) Apply(ctx context.Context, db DB, _ online.Dialect) error {
+	return CreateTableIfNotExists(ctx, db, change.Table, change.Columns, change.Constraints...)
+}
+
+func (change CreateTableChange) Revert(ctx context.Context, db DB, _ online.Dialect) error {
+	return DropTableIfExists(ctx, db, change.Table)
+}
+
+// AddColumnChange Adds the column (see AddColumnIfMissing). Reverted by dropping it.
+type AddColumnChange struct {
+	Table  string
+	Column Column
+}
+
+func (change AddColumnChange) Apply(ctx context.Context, db DB, dialect online.Dialect) error {
+	return AddColumnIfMissing(ctx, db, dialect, change.Table, change.Column)
+}
+
+func (change AddColumnChange) Revert(ctx context.Context, db DB, dialect online.Dialect) error {
+	return DropColumnIfExists(ctx, db, dialect, change.Table, change.Column.Name)
+}
+
+// RenameColumnChange Renames the column (see RenameColumn). Reverted by renaming it back.
+type RenameColumnChange struct {
+	Table string
+	From  string
+	To    string
+}
+
+func (change RenameColumnChange) Apply(ctx context.Context, db DB, dialect online.Dialect) error {
+	return RenameColumn(ctx, db, dialect, change.Table, change.From, change.To)
+}
+
+func (change RenameColumnChange) Revert(ctx context.Context, db DB, dialect online.Dialect) error {
+	return RenameColumn(ctx, db, dialect, change.Table, change.To, change.From)
+}
+
+// EnsureIndexChange Creates the index (see EnsureIndex). Reverted by dropping it.
+type EnsureIndexChange struct {
+	Index online.Index
+}
+
+func (change EnsureIndexChange) Apply(ctx context.Context, db DB, dialect online.Dialect) error {
+	return EnsureIndex(ctx, db, dialect, change.Index)
+}
+
+func (change EnsureIndexChange) Revert(ctx context.Context, db DB, dialect online.Dialect) error {
+	return DropIndexIfExists(ctx, db, dialect, change.Index)
+}
+
+// StatementChange Executes a statement which the helpers do not cover. It is reverted by
+// executing the Down statement, which must be provided explicitly. Without it, reverting errors
+// with ErrIrreversible.
+type StatementChange struct {
+	Up   string
+	Down string
+}
+
+func (change StatementChange) Apply(ctx context.Context, db DB, _ online.Dialect) error {
+	if _, err := db.ExecContext(ctx, change.Up); err != nil {
+		return fmt.Errorf("failed to execute statement %q with error: %w", change.Up, err)
+	}
+	return nil
+}
+
+func (change StatementChange) Revert(ctx context.Context, db DB, _ online.Dialect) error {
+	if change.Down == "" {
+		return fmt.Errorf("%w, statement %q has no down statement", ErrIrreversible, change.Up)
+	}
+
+	if _, err := db.ExecContext(ctx, change.Down); err != nil {
+		return fmt.Errorf("failed to execute statement %q with error: %w", change.Down, err)
+	}
+	return nil
+}
+
+// ReversibleMigration A migration built from changes: Up() applies them, in order, and the
+// generated Down() reverts them, in reverse order, so the rollback can not drift from Up().
+// Migrations which need a different rollback (for example, one which restores data) can opt out
+// of the generated Down() with WithDown.
+type ReversibleMigration struct {
+	version uint64
+	db      DB
+	dialect online.Dialect
+	changes []Change
+	down    func() error
+}
+
+// NewReversibleMigration Builds a migration which applies the changes on the db
+func NewReversibleMigration(
+	version uint64,
+	db DB,
+	dialect online.Dialect,
+	changes ...Change,
+) *ReversibleMigration {
+	return &ReversibleMigration{version: version, db: db, dialect: dialect, changes: changes}
+}
+
+// WithDown Opts out of the generated Down(): the provided function is executed instead
+func (m *ReversibleMigration) WithDown(down func() error) *ReversibleMigration {
+	m.down = down
+	return m
+}
+
+func (m *ReversibleMigration) Version() uint64 {
+	return m.version
+}
+
+func (m *ReversibleMigration) Up() error {
+	for i, change := range m.changes {
+		if err := change.Apply(context.Background(), m.db, m.dialect); err != nil {
+			return fmt.Errorf("failed to apply change %d with error: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Down Reverts the changes, in reverse order, or, if opted out (see WithDown), executes the
+// provided down function. If any change is irreversible (a StatementChange without a down
+// statement), errors with ErrIrreversible before reverting anything.
+func (m *ReversibleMigration) Down() error {
+	if m.down != nil {
+		return m.down()
+	}
+
+	for i, change := range m.changes {
+		if statement, isStatement := change.(StatementChange); isStatement &&
+			statement.Down == "" {
+			return fmt.Errorf(
+				"%w, change %d has no down statement, provide a down function with WithDown",
+				ErrIrreversible, i,
+			)
+		}
+	}
+
+	changes := slices.Clone(m.changes)
+	slices.Reverse(changes)
+	for i, change := range changes {
+		if err := change.Revert(context.Background(), m.db, m.dialect); err != nil {
+			return fmt.Errorf(
+				"failed to revert change %d with error: %w", len(changes)-1-i, err,
+			)
+		}
+	}
+	return nil
+}
+
+// WithDB Returns a copy of the migration which applies the changes on the provided db (see
+// migration.SQLDryRunner)
+func (m *ReversibleMigration) WithDB(db *sql.DB) migration.Migration {
+	clone := *m
+	clone.db = db
+	return &clone
+}
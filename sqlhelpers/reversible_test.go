@@ -0,0 +1,126 @@
+package sqlhelpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/rsgcata/go-migrations/online"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReversibleTestSuite struct {
+	suite.Suite
+}
+
+func TestReversibleTestSuite(t *testing.T) {
+	suite.Run(t, new(ReversibleTestSuite))
+}
+
+func (suite *ReversibleTestSuite) TestItGeneratesDownFromTheChanges() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	mig := NewReversibleMigration(
+		1, db, online.Postgres,
+		CreateTableChange{Table: "users", Columns: []Column{{"id", "BIGINT"}}},
+		AddColumnChange{Table: "users", Column: Column{"age", "INT"}},
+		StatementChange{
+			Up:   "CREATE VIEW adults AS SELECT id FROM users WHERE age >= 18",
+			Down: "DROP VIEW adults",
+		},
+	)
+
+	suite.Require().NoError(mig.Up())
+	suite.Assert().Equal(
+		[]string{
+			"CREATE TABLE IF NOT EXISTS users (id BIGINT)",
+			"SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema()" +
+				" AND table_name = $1 AND column_name = $2",
+			"ALTER TABLE users ADD COLUMN age INT",
+			"CREATE VIEW adults AS SELECT id FROM users WHERE age >= 18",
+		},
+		queries(recorder),
+	)
+
+	// The recording handle returns no rows, so the added column is not dropped again
+	recorder.Reset()
+	suite.Require().NoError(mig.Down())
+	suite.Assert().Equal(
+		[]string{
+			"DROP VIEW adults",
+			"SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema()" +
+				" AND table_name = $1 AND column_name = $2",
+			"DROP TABLE IF EXISTS users",
+		},
+		queries(recorder),
+	)
+}
+
+func (suite *ReversibleTestSuite) TestItFailsToRevertIrreversibleChanges() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	mig := NewReversibleMigration(
+		1, db, online.Mysql,
+		CreateTableChange{Table: "users", Columns: []Column{{"id", "BIGINT"}}},
+		StatementChange{Up: "INSERT INTO users (id) VALUES (1)"},
+	)
+
+	suite.Assert().ErrorIs(mig.Down(), ErrIrreversible)
+	suite.Assert().Empty(recorder.Statements())
+}
+
+func (suite *ReversibleTestSuite) TestItCanOptOutOfTheGeneratedDown() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+	downErr := errors.New("custom down")
+
+	mig := NewReversibleMigration(
+		1, db, online.Mysql,
+		StatementChange{Up: "INSERT INTO users (id) VALUES (1)"},
+	).WithDown(func() error {
+		return downErr
+	})
+
+	suite.Assert().ErrorIs(mig.Down(), downErr)
+	suite.Assert().Empty(recorder.Statements())
+}
+
+func (suite *ReversibleTestSuite) TestItCanRunTheChangesOnAnotherDb() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+	dryRunDB, dryRunRecorder := sqlcapture.NewDB()
+	defer func() { _ = dryRunDB.Close() }()
+
+	mig := NewReversibleMigration(
+		1, db, online.Mysql, CreateTableChange{Table: "users", Columns: []Column{{"id", "INT"}}},
+	)
+
+	var dryRunner migration.SQLDryRunner = mig
+	suite.Require().NoError(dryRunner.WithDB(dryRunDB).Up())
+	suite.Assert().Empty(recorder.Statements())
+	suite.Assert().Equal(
+		[]string{"CREATE TABLE IF NOT EXISTS users (id INT)"}, queries(dryRunRecorder),
+	)
+}
+
+func (suite *ReversibleTestSuite) TestItChecksIndexesBeforeDroppingThem() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	err := EnsureIndexChange{online.Index{Name: "idx_email", Table: "users"}}.Revert(
+		context.Background(), db, online.Mysql,
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]string{
+			"SELECT 1 FROM information_schema.statistics WHERE table_schema = DATABASE()" +
+				" AND table_name = ? AND index_name = ? LIMIT 1",
+		},
+		queries(recorder),
+	)
+}
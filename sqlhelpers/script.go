@@ -0,0 +1,107 @@
+package sqlhelpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/handler"
+)
+
+// SplitStatements Splits a SQL script into its statements, on the semicolons which are not
+// part of string literals, quoted identifiers, comments or Postgres dollar quoted bodies.
+// Empty statements are dropped.
+func SplitStatements(script string) []string {
+	var statements []string
+	start := 0
+
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(script, i, c)
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			i = skipUntil(script, i, "\n")
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			i = skipUntil(script, i+2, "*/")
+		case c == '$':
+			if tag := dollarTag(script[i:]); tag != "" {
+				i = skipUntil(script, i+len(tag), tag)
+			}
+		case c == ';':
+			statements = appendStatement(statements, script[start:i])
+			start = i + 1
+		}
+	}
+
+	return appendStatement(statements, script[start:])
+}
+
+// ExecScript Executes the statements of the SQL script (see SplitStatements), one by one, so
+// it does not depend on multi statement support of the driver
+func ExecScript(ctx context.Context, db DB, script string) error {
+	for i, statement := range SplitStatements(script) {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("statement %d failed with error: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// RunHook Returns a handler.RunHook which executes the SQL script (see ExecScript), for both
+// directions, for example "SET statement_timeout = '5min'" or
+// "REFRESH MATERIALIZED VIEW report". Session settings only apply to the connection they are
+// set on, so the migrations should use the same connection (for example, a *sql.Conn).
+func RunHook(ctx context.Context, db DB, script string) handler.RunHook {
+	return func(handler.MigrationStage) error {
+		return ExecScript(ctx, db, script)
+	}
+}
+
+func appendStatement(statements []string, statement string) []string {
+	if statement = strings.TrimSpace(statement); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// skipQuoted Returns the position of the closing quote. Doubled quotes and backslash escapes
+// are part of the literal.
+func skipQuoted(script string, start int, quote byte) int {
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(script)
+}
+
+// skipUntil Returns the position of the last byte of the first end marker after start
+func skipUntil(script string, start int, end string) int {
+	if pos := strings.Index(script[start:], end); pos >= 0 {
+		return start + pos + len(end) - 1
+	}
+	return len(script)
+}
+
+// dollarTag Returns the Postgres dollar quote tag (for example, "$$" or "$body$") the script
+// starts with, if any
+func dollarTag(script string) string {
+	for i := 1; i < len(script); i++ {
+		c := script[i]
+		if c == '$' {
+			return script[:i+1]
+		}
+		isLetter := c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z')
+		if !isLetter && !(i > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package sqlhelpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScriptTestSuite struct {
+	suite.Suite
+}
+
+func TestScriptTestSuite(t *testing.T) {
+	suite.Run(t, new(ScriptTestSuite))
+}
+
+func (suite *ScriptTestSuite) TestItCanSplitScriptsIntoStatements() {
+	scenarios := map[string]struct {
+		script   string
+		expected []string
+	}{
+		"simple": {
+			"CREATE TABLE a (id INT);\nINSERT INTO a VALUES (1);\n",
+			[]string{"CREATE TABLE a (id INT)", "INSERT INTO a VALUES (1)"},
+		},
+		"no trailing semicolon": {
+			"SELECT 1;SELECT 2", []string{"SELECT 1", "SELECT 2"},
+		},
+		"empty statements": {" ; ;\n", nil},
+		"quoted semicolons": {
+			`INSERT INTO a VALUES ('a;b', "c;d", 'it''s;', 'x\';y');` + "SELECT `e;f` FROM a",
+			[]string{
+				`INSERT INTO a VALUES ('a;b', "c;d", 'it''s;', 'x\';y')`,
+				"SELECT `e;f` FROM a",
+			},
+		},
+		"comments": {
+			"-- drop; it\nDROP TABLE a; /* not; this */ SELECT 1;",
+			[]string{"-- drop; it\nDROP TABLE a", "/* not; this */ SELECT 1"},
+		},
+		"dollar quoted bodies": {
+			"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;" +
+				"SELECT $$a;b$$; UPDATE a SET id = $1;",
+			[]string{
+				"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql",
+				"SELECT $$a;b$$",
+				"UPDATE a SET id = $1",
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Run(name, func() {
+			suite.Assert().Equal(scenario.expected, SplitStatements(scenario.script))
+		})
+	}
+}
+
+func (suite *ScriptTestSuite) TestItExecutesScriptsStatementByStatement() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	err := ExecScript(context.Background(), db, "CREATE TABLE a (id INT);\nDROP TABLE b;")
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]string{"CREATE TABLE a (id INT)", "DROP TABLE b"}, queries(recorder),
+	)
+}
+
+func (suite *ScriptTestSuite) TestItCanRunScriptsAsRunHooks() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	hook := RunHook(context.Background(), db, "SET a = 1; SET b = 2")
+
+	suite.Assert().NoError(hook(handler.StageUp))
+	suite.Assert().NoError(hook(handler.StageDown))
+	suite.Assert().Equal(
+		[]string{"SET a = 1", "SET b = 2", "SET a = 1", "SET b = 2"}, queries(recorder),
+	)
+}
@@ -0,0 +1,142 @@
+package sqlhelpers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rsgcata/go-migrations/online"
+)
+
+// SessionSettings Session level settings applied on the migrations connection before the
+// migrations run, protecting production from migrations which hold table locks for too long.
+// Zero values are not applied.
+type SessionSettings struct {
+	// StatementTimeout The max duration of a statement: statement_timeout for Postgres,
+	// max_execution_time for Mysql (which only limits read only SELECT statements). For MariaDB,
+	// use Extra{"max_statement_time": "<seconds>"} instead.
+	StatementTimeout time.Duration
+
+	// LockTimeout The max time a statement waits for a lock: lock_timeout for Postgres,
+	// lock_wait_timeout (metadata locks, taken by DDL statements) and innodb_lock_wait_timeout
+	// (row locks) for Mysql, rounded up to seconds
+	LockTimeout time.Duration
+
+	// SQLMode The Mysql sql_mode (for example, "STRICT_ALL_TABLES"). Not supported for Postgres.
+	SQLMode string
+
+	// Extra Other settings, by name, applied as they are, so the values must be SQL literals
+	// (for example, {"time_zone": "'+00:00'"})
+	Extra map[string]string
+}
+
+// IsZero Checks if no setting is configured
+func (settings SessionSettings) IsZero() bool {
+	return settings.StatementTimeout == 0 && settings.LockTimeout == 0 &&
+		settings.SQLMode == "" && len(settings.Extra) == 0
+}
+
+// Statements Returns the statements which apply the settings, for the dialect
+func (settings SessionSettings) Statements(dialect online.Dialect) ([]string, error) {
+	setPrefix := ""
+	switch dialect {
+	case online.Mysql:
+		setPrefix = "SET SESSION "
+	case online.Postgres:
+		setPrefix = "SET "
+	default:
+		return nil, unsupported(dialect)
+	}
+
+	var statements []string
+	set := func(name string, value string) {
+		statements = append(statements, setPrefix+name+" = "+value)
+	}
+
+	if dialect == online.Postgres && settings.SQLMode != "" {
+		return nil, fmt.Errorf("sql mode is not supported for dialect %q", dialect)
+	}
+
+	if settings.StatementTimeout > 0 {
+		name := "max_execution_time"
+		if dialect == online.Postgres {
+			name = "statement_timeout"
+		}
+		set(name, strconv.FormatInt(settings.StatementTimeout.Milliseconds(), 10))
+	}
+
+	if settings.LockTimeout > 0 {
+		if dialect == online.Postgres {
+			set("lock_timeout", strconv.FormatInt(settings.LockTimeout.Milliseconds(), 10))
+		} else {
+			seconds := strconv.FormatInt(
+				int64((settings.LockTimeout+time.Second-1)/time.Second), 10,
+			)
+			set("lock_wait_timeout", seconds)
+			set("innodb_lock_wait_timeout", seconds)
+		}
+	}
+
+	if settings.SQLMode != "" {
+		set("sql_mode", "'"+strings.ReplaceAll(settings.SQLMode, "'", "''")+"'")
+	}
+
+	names := make([]string, 0, len(settings.Extra))
+	for name := range settings.Extra {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		set(name, settings.Extra[name])
+	}
+
+	return statements, nil
+}
+
+// ApplySession Applies the session settings on the connection. Since a *sql.DB is a pool of
+// connections, the settings applied through it only affect one of its connections, so the
+// migrations should use a dedicated connection (see OpenSession).
+func ApplySession(
+	ctx context.Context,
+	db DB,
+	dialect online.Dialect,
+	settings SessionSettings,
+) error {
+	statements, err := settings.Statements(dialect)
+	if err != nil {
+		return fmt.Errorf("failed to apply session settings with error: %w", err)
+	}
+
+	for _, statement := range statements {
+		if _, err = db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf(
+				"failed to apply session setting %q with error: %w", statement, err,
+			)
+		}
+	}
+	return nil
+}
+
+// OpenSession Returns a dedicated connection from the pool, with the session settings applied,
+// to be used by the migrations. The connection must be closed when the migrations are done.
+func OpenSession(
+	ctx context.Context,
+	db *sql.DB,
+	dialect online.Dialect,
+	settings SessionSettings,
+) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session connection with error: %w", err)
+	}
+
+	if err = ApplySession(ctx, conn, dialect, settings); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
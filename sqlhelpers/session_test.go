@@ -0,0 +1,81 @@
+package sqlhelpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/online"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionTestSuite struct {
+	suite.Suite
+}
+
+func TestSessionTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionTestSuite))
+}
+
+func (suite *SessionTestSuite) TestItBuildsTheSessionStatementsForTheDialect() {
+	settings := SessionSettings{
+		StatementTimeout: 30 * time.Second,
+		LockTimeout:      1500 * time.Millisecond,
+		Extra:            map[string]string{"time_zone": "'+00:00'", "b": "1"},
+	}
+	scenarios := map[online.Dialect][]string{
+		online.Mysql: {
+			"SET SESSION max_execution_time = 30000",
+			"SET SESSION lock_wait_timeout = 2",
+			"SET SESSION innodb_lock_wait_timeout = 2",
+			"SET SESSION b = 1",
+			"SET SESSION time_zone = '+00:00'",
+		},
+		online.Postgres: {
+			"SET statement_timeout = 30000",
+			"SET lock_timeout = 1500",
+			"SET b = 1",
+			"SET time_zone = '+00:00'",
+		},
+	}
+
+	for dialect, expected := range scenarios {
+		statements, err := settings.Statements(dialect)
+		suite.Assert().NoError(err, dialect)
+		suite.Assert().Equal(expected, statements, dialect)
+	}
+
+	statements, err := SessionSettings{SQLMode: "STRICT_ALL_TABLES,NO_'X"}.Statements(online.Mysql)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]string{"SET SESSION sql_mode = 'STRICT_ALL_TABLES,NO_''X'"}, statements)
+
+	_, err = SessionSettings{SQLMode: "STRICT_ALL_TABLES"}.Statements(online.Postgres)
+	suite.Assert().ErrorContains(err, "sql mode is not supported")
+	_, err = SessionSettings{}.Statements("oracle")
+	suite.Assert().ErrorContains(err, "not supported")
+
+	statements, err = SessionSettings{}.Statements(online.Mysql)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(statements)
+	suite.Assert().True(SessionSettings{}.IsZero())
+	suite.Assert().False(settings.IsZero())
+}
+
+func (suite *SessionTestSuite) TestItAppliesTheSettingsOnADedicatedConnection() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	conn, err := OpenSession(
+		context.Background(), db, online.Postgres, SessionSettings{LockTimeout: time.Second},
+	)
+	suite.Require().NoError(err)
+	suite.Assert().NoError(ExecScript(context.Background(), conn, "ALTER TABLE a ADD b INT"))
+	suite.Assert().NoError(conn.Close())
+	suite.Assert().Equal(
+		[]string{"SET lock_timeout = 1000", "ALTER TABLE a ADD b INT"}, queries(recorder),
+	)
+
+	_, err = OpenSession(context.Background(), db, online.Postgres, SessionSettings{SQLMode: "x"})
+	suite.Assert().ErrorContains(err, "failed to apply session settings")
+}
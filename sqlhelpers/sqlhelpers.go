@@ -0,0 +1,283 @@
+// Package sqlhelpers includes idempotent helpers for common DDL operations, which can be used
+// from database/sql based Migration implementations, so migrations are shorter and can be
+// retried after a failure. The helpers are dialect aware (see online.Dialect): Mysql (and
+// MariaDB) and Postgres. Migrations built from changes (see ReversibleMigration) get their
+// Down() generated from the helpers.
+package sqlhelpers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rsgcata/go-migrations/online"
+)
+
+// DB The database handle used by the helpers. Both *sql.DB and *sql.Tx can be used (note that
+// Mysql commits DDL statements implicitly).
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Column A column definition, for example Column{"email", "VARCHAR(255) NOT NULL"}
+type Column struct {
+	Name       string
+	Definition string
+}
+
+func (c Column) String() string {
+	return c.Name + " " + c.Definition
+}
+
+// CreateTableIfNotExists Creates the table with the provided columns and constraints (for
+// example, "PRIMARY KEY (id)"), if it does not exist
+func CreateTableIfNotExists(
+	ctx context.Context,
+	db DB,
+	table string,
+	columns []Column,
+	constraints ...string,
+) error {
+	definitions := make([]string, 0, len(columns)+len(constraints))
+	for _, column := range columns {
+		definitions = append(definitions, column.String())
+	}
+	definitions = append(definitions, constraints...)
+
+	_, err := db.ExecContext(
+		ctx,
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, strings.Join(definitions, ", ")),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create table %s with error: %w", table, err)
+	}
+	return nil
+}
+
+// AddColumnIfMissing Adds the column to the table, if the table does not have it
+func AddColumnIfMissing(
+	ctx context.Context,
+	db DB,
+	dialect online.Dialect,
+	table string,
+	column Column,
+) error {
+	errMsg := fmt.Sprintf("failed to add column %s to %s", column.Name, table)
+
+	exists, err := ColumnExists(ctx, db, dialect, table, column.Name)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	} else if exists {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, column))
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// RenameColumn Renames the column of the table. It is a no-op if the column was already renamed
+// (from does not exist, but to does). Requires Mysql 8+ or MariaDB 10.5+.
+func RenameColumn(
+	ctx context.Context,
+	db DB,
+	dialect online.Dialect,
+	table, from, to string,
+) error {
+	errMsg := fmt.Sprintf("failed to rename column %s to %s on %s", from, to, table)
+
+	fromExists, err := ColumnExists(ctx, db, dialect, table, from)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	} else if !fromExists {
+		toExists, err := ColumnExists(ctx, db, dialect, table, to)
+		if err != nil {
+			return fmt.Errorf("%s, %w", errMsg, err)
+		} else if toExists {
+			return nil
+		}
+		return fmt.Errorf("%s, column %s does not exist", errMsg, from)
+	}
+
+	_, err = db.ExecContext(
+		ctx, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, from, to),
+	)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// EnsureIndex Creates the index, if the table does not have an index with the same name. Unlike
+// online.CreateIndex, the statement may block writes to the table while the index is built.
+func EnsureIndex(ctx context.Context, db DB, dialect online.Dialect, index online.Index) error {
+	errMsg := fmt.Sprintf("failed to ensure index %s on %s", index.Name, index.Table)
+
+	exists, err := IndexExists(ctx, db, dialect, index.Table, index.Name)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	} else if exists {
+		return nil
+	}
+
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			"CREATE %sINDEX %s ON %s (%s)",
+			unique, index.Name, index.Table, strings.Join(index.Columns, ", "),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// DropTableIfExists Drops the table, if it exists
+func DropTableIfExists(ctx context.Context, db DB, table string) error {
+	if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS "+table); err != nil {
+		return fmt.Errorf("failed to drop table %s with error: %w", table, err)
+	}
+	return nil
+}
+
+// DropColumnIfExists Drops the column from the table, if the table has it
+func DropColumnIfExists(
+	ctx context.Context,
+	db DB,
+	dialect online.Dialect,
+	table, column string,
+) error {
+	errMsg := fmt.Sprintf("failed to drop column %s from %s", column, table)
+
+	exists, err := ColumnExists(ctx, db, dialect, table, column)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	} else if !exists {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column))
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// DropIndexIfExists Drops the index, if the table has an index with the same name. Unlike
+// online.DropIndex, the statement may block writes to the table.
+func DropIndexIfExists(
+	ctx context.Context,
+	db DB,
+	dialect online.Dialect,
+	index online.Index,
+) error {
+	errMsg := fmt.Sprintf("failed to drop index %s from %s", index.Name, index.Table)
+
+	exists, err := IndexExists(ctx, db, dialect, index.Table, index.Name)
+	if err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	} else if !exists {
+		return nil
+	}
+
+	query := fmt.Sprintf("DROP INDEX %s ON %s", index.Name, index.Table)
+	if dialect == online.Postgres {
+		query = "DROP INDEX " + index.Name
+	}
+
+	if _, err = db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("%s, %w", errMsg, err)
+	}
+	return nil
+}
+
+// ColumnExists Checks if the table, from the current database (Mysql) or schema (Postgres), has
+// the column
+func ColumnExists(
+	ctx context.Context,
+	db DB,
+	dialect online.Dialect,
+	table, column string,
+) (bool, error) {
+	schema, err := currentSchema(dialect)
+	if err != nil {
+		return false, err
+	}
+
+	return exists(
+		ctx, db,
+		"SELECT 1 FROM information_schema.columns WHERE table_schema = "+schema+
+			" AND table_name = ? AND column_name = ?",
+		dialect, table, column,
+	)
+}
+
+// IndexExists Checks if the table, from the current database (Mysql) or schema (Postgres), has
+// an index with the provided name
+func IndexExists(
+	ctx context.Context,
+	db DB,
+	dialect online.Dialect,
+	table, index string,
+) (bool, error) {
+	query := "SELECT 1 FROM information_schema.statistics WHERE table_schema = DATABASE()" +
+		" AND table_name = ? AND index_name = ? LIMIT 1"
+	if dialect == online.Postgres {
+		query = "SELECT 1 FROM pg_indexes WHERE schemaname = current_schema()" +
+			" AND tablename = ? AND indexname = ?"
+	} else if dialect != online.Mysql {
+		return false, unsupported(dialect)
+	}
+
+	return exists(ctx, db, query, dialect, table, index)
+}
+
+func currentSchema(dialect online.Dialect) (string, error) {
+	switch dialect {
+	case online.Mysql:
+		return "DATABASE()", nil
+	case online.Postgres:
+		return "current_schema()", nil
+	}
+	return "", unsupported(dialect)
+}
+
+// exists Runs the query, which must select a row if the checked object exists. The "?"
+// placeholders are converted to the dialect's placeholders.
+func exists(
+	ctx context.Context,
+	db DB,
+	query string,
+	dialect online.Dialect,
+	args ...interface{},
+) (bool, error) {
+	if dialect == online.Postgres {
+		for i := range args {
+			query = strings.Replace(query, "?", fmt.Sprintf("$%d", i+1), 1)
+		}
+	}
+
+	var found int
+	err := db.QueryRowContext(ctx, query, args...).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check existence with error: %w", err)
+	}
+	return true, nil
+}
+
+func unsupported(dialect online.Dialect) error {
+	return fmt.Errorf("dialect %q is not supported", dialect)
+}
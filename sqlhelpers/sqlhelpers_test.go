@@ -0,0 +1,134 @@
+package sqlhelpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/online"
+	"github.com/rsgcata/go-migrations/sqlcapture"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLHelpersTestSuite struct {
+	suite.Suite
+}
+
+func TestSQLHelpersTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLHelpersTestSuite))
+}
+
+func queries(recorder *sqlcapture.Recorder) []string {
+	var queries []string
+	for _, statement := range recorder.Statements() {
+		queries = append(queries, statement.Query)
+	}
+	return queries
+}
+
+func (suite *SQLHelpersTestSuite) TestItCanCreateTablesIfTheyDoNotExist() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	err := CreateTableIfNotExists(
+		context.Background(), db, "users",
+		[]Column{{"id", "BIGINT NOT NULL"}, {"email", "VARCHAR(255)"}},
+		"PRIMARY KEY (id)",
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(
+		[]string{
+			"CREATE TABLE IF NOT EXISTS users " +
+				"(id BIGINT NOT NULL, email VARCHAR(255), PRIMARY KEY (id))",
+		},
+		queries(recorder),
+	)
+}
+
+func (suite *SQLHelpersTestSuite) TestItChecksColumnsBeforeAddingThem() {
+	scenarios := map[online.Dialect][]string{
+		online.Mysql: {
+			"SELECT 1 FROM information_schema.columns WHERE table_schema = DATABASE()" +
+				" AND table_name = ? AND column_name = ?",
+			"ALTER TABLE users ADD COLUMN age INT",
+		},
+		online.Postgres: {
+			"SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema()" +
+				" AND table_name = $1 AND column_name = $2",
+			"ALTER TABLE users ADD COLUMN age INT",
+		},
+	}
+
+	for dialect, expected := range scenarios {
+		suite.Run(string(dialect), func() {
+			db, recorder := sqlcapture.NewDB()
+			defer func() { _ = db.Close() }()
+
+			err := AddColumnIfMissing(
+				context.Background(), db, dialect, "users", Column{"age", "INT"},
+			)
+
+			suite.Assert().NoError(err)
+			suite.Assert().Equal(expected, queries(recorder))
+			suite.Assert().Equal(
+				[]any{"users", "age"}, recorder.Statements()[0].Args,
+			)
+		})
+	}
+}
+
+func (suite *SQLHelpersTestSuite) TestItFailsRenamingMissingColumns() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+
+	// The recording handle returns no rows, so neither of the columns exists
+	err := RenameColumn(context.Background(), db, online.Mysql, "users", "phone", "phone_number")
+
+	suite.Assert().ErrorContains(err, "column phone does not exist")
+	suite.Assert().Len(recorder.Statements(), 2)
+	suite.Assert().Equal([]any{"users", "phone_number"}, recorder.Statements()[1].Args)
+}
+
+func (suite *SQLHelpersTestSuite) TestItChecksIndexesBeforeCreatingThem() {
+	index := online.Index{
+		Name: "idx_email", Table: "users", Columns: []string{"email", "id"}, Unique: true,
+	}
+	scenarios := map[online.Dialect][]string{
+		online.Mysql: {
+			"SELECT 1 FROM information_schema.statistics WHERE table_schema = DATABASE()" +
+				" AND table_name = ? AND index_name = ? LIMIT 1",
+			"CREATE UNIQUE INDEX idx_email ON users (email, id)",
+		},
+		online.Postgres: {
+			"SELECT 1 FROM pg_indexes WHERE schemaname = current_schema()" +
+				" AND tablename = $1 AND indexname = $2",
+			"CREATE UNIQUE INDEX idx_email ON users (email, id)",
+		},
+	}
+
+	for dialect, expected := range scenarios {
+		suite.Run(string(dialect), func() {
+			db, recorder := sqlcapture.NewDB()
+			defer func() { _ = db.Close() }()
+
+			suite.Assert().NoError(EnsureIndex(context.Background(), db, dialect, index))
+			suite.Assert().Equal(expected, queries(recorder))
+		})
+	}
+}
+
+func (suite *SQLHelpersTestSuite) TestItFailsForUnsupportedDialects() {
+	db, recorder := sqlcapture.NewDB()
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+
+	suite.Assert().ErrorContains(
+		AddColumnIfMissing(ctx, db, "sqlite", "users", Column{"age", "INT"}),
+		`dialect "sqlite" is not supported`,
+	)
+	suite.Assert().ErrorContains(
+		EnsureIndex(ctx, db, "sqlite", online.Index{Name: "idx", Table: "users"}),
+		`dialect "sqlite" is not supported`,
+	)
+	suite.Assert().Empty(recorder.Statements())
+}
@@ -0,0 +1,320 @@
+// Package status includes an http.Handler which exposes the migrations status (the executions
+// history, the pending migrations and the last failure), as JSON and as a minimal HTML
+// dashboard, for quick internal visibility without building a UI.
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+)
+
+// DefaultHistoryLimit The number of executions shown by default (see WithHistoryLimit)
+const DefaultHistoryLimit = 50
+
+// Report The migrations status, served as JSON or rendered by the dashboard
+type Report struct {
+	GeneratedAt string           `json:"generatedAt"`
+	Summary     *handler.Summary `json:"summary"`
+	// History The latest executions, newest first (see WithHistoryLimit)
+	History     []Entry      `json:"history"`
+	Pending     []Entry      `json:"pending"`
+	LastFailure *LastFailure `json:"lastFailure"`
+	// Error Set if (part of) the status could not be loaded, for example, if the executions are
+	// inconsistent with the registered migrations
+	Error string `json:"error,omitempty"`
+}
+
+// Entry A migration, with its execution, if executed, and the notes attached to the execution
+type Entry struct {
+	Version   uint64                        `json:"version"`
+	Name      string                        `json:"name"`
+	Execution *execution.MigrationExecution `json:"execution,omitempty"`
+	Notes     []execution.Note              `json:"notes,omitempty"`
+}
+
+// The sources of the last failure
+const (
+	// FailureSourceRun A failure of a run of the current process (see FailureRecorder)
+	FailureSourceRun = "run"
+	// FailureSourceUnfinished An execution which was started but not finished, because the
+	// migration failed (or is still running)
+	FailureSourceUnfinished = "unfinished"
+)
+
+// LastFailure The last migration failure
+type LastFailure struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	// Stage The stage which failed (see handler.MigrationStage), for run failures
+	Stage string `json:"stage,omitempty"`
+	// Error The failure error, for run failures
+	Error string `json:"error,omitempty"`
+	At    string `json:"at"`
+}
+
+// FailureRecorder handler.ErrorReporter which keeps the last migration failure of the current
+// process, so the dashboard can show its error (see WithFailureRecorder and
+// handler.WithErrorReporter)
+type FailureRecorder struct {
+	mu   sync.Mutex
+	last *LastFailure
+}
+
+func (recorder *FailureRecorder) ReportFailure(failure handler.Failure) error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.last = &LastFailure{
+		Version: failure.Version,
+		Source:  FailureSourceRun,
+		Stage:   string(failure.Stage),
+		Error:   failure.Err.Error(),
+		At:      time.Now().UTC().Format(execution.TimestampFormat),
+	}
+	return nil
+}
+
+// LastFailure Returns the last recorded failure, or nil if there was none
+func (recorder *FailureRecorder) LastFailure() *LastFailure {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.last == nil {
+		return nil
+	}
+	last := *recorder.last
+	return &last
+}
+
+// Dashboard http.Handler which serves the status Report as JSON, if requested with the
+// ?format=json query parameter or an Accept header including application/json, otherwise as
+// an HTML page (without external assets). Responds with 503 Service Unavailable if the status
+// could not be loaded.
+type Dashboard struct {
+	migrations   *handler.MigrationsHandler
+	historyLimit int
+	recorder     *FailureRecorder
+	now          func() time.Time
+}
+
+// Option Optional behaviour for the Dashboard
+type Option func(*Dashboard)
+
+// WithHistoryLimit Limits the number of shown executions (DefaultHistoryLimit by default)
+func WithHistoryLimit(limit int) Option {
+	return func(dashboard *Dashboard) {
+		dashboard.historyLimit = limit
+	}
+}
+
+// WithFailureRecorder Shows the failures recorded by the recorder, which must also be passed to
+// the migrations handler (see handler.WithErrorReporter). Without it, only the unfinished
+// executions are shown as failures.
+func WithFailureRecorder(recorder *FailureRecorder) Option {
+	return func(dashboard *Dashboard) {
+		dashboard.recorder = recorder
+	}
+}
+
+// NewDashboard Builds a new Dashboard for the migrations handler
+func NewDashboard(migrations *handler.MigrationsHandler, options ...Option) *Dashboard {
+	dashboard := &Dashboard{
+		migrations:   migrations,
+		historyLimit: DefaultHistoryLimit,
+		now:          time.Now,
+	}
+
+	for _, option := range options {
+		option(dashboard)
+	}
+
+	return dashboard
+}
+
+// Report Loads the current migrations status. Parts which can not be loaded are left empty and
+// the errors are joined in Report.Error.
+func (dashboard *Dashboard) Report() Report {
+	report := Report{GeneratedAt: dashboard.now().UTC().Format(execution.TimestampFormat)}
+	var errs []error
+
+	history, err := dashboard.migrations.History()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	notes, err := dashboard.migrations.Notes()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	slices.Reverse(history)
+	for _, exec := range history {
+		if len(report.History) >= dashboard.historyLimit {
+			break
+		}
+		entry := dashboard.entry(exec.Version, &exec)
+		entry.Notes = notes[exec.Version]
+		report.History = append(report.History, entry)
+	}
+
+	plan, err := dashboard.migrations.Plan()
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		report.Summary = &handler.Summary{
+			RegisteredCount: plan.RegisteredMigrationsCount(),
+			FinishedCount:   plan.FinishedExecutionsCount(),
+			LastExecuted:    plan.LastExecuted(),
+			NextToExecute:   plan.NextToExecute(),
+		}
+		for _, mig := range plan.AllToBeExecuted() {
+			report.Pending = append(report.Pending, dashboard.entry(mig.Version(), nil))
+		}
+	}
+
+	report.LastFailure = dashboard.lastFailure(history)
+	if len(errs) > 0 {
+		report.Error = errors.Join(errs...).Error()
+	}
+	return report
+}
+
+func (dashboard *Dashboard) entry(version uint64, exec *execution.MigrationExecution) Entry {
+	return Entry{Version: version, Name: dashboard.migrations.DisplayName(version), Execution: exec}
+}
+
+// lastFailure The failure recorded by the recorder, if any, otherwise the newest unfinished
+// execution from the history (newest first)
+func (dashboard *Dashboard) lastFailure(history []execution.MigrationExecution) *LastFailure {
+	if dashboard.recorder != nil {
+		if last := dashboard.recorder.LastFailure(); last != nil {
+			last.Name = dashboard.migrations.DisplayName(last.Version)
+			return last
+		}
+	}
+
+	for _, exec := range history {
+		if !exec.Finished() {
+			return &LastFailure{
+				Version: exec.Version,
+				Name:    dashboard.migrations.DisplayName(exec.Version),
+				Source:  FailureSourceUnfinished,
+				At:      execution.FormatTimestampMs(exec.ExecutedAtMs),
+			}
+		}
+	}
+	return nil
+}
+
+func (dashboard *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := dashboard.Report()
+	status := http.StatusOK
+	if report.Error != "" {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Query().Get("format") == "json" ||
+		strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = dashboardTemplate.Execute(w, report)
+}
+
+// dashboardTemplate The HTML dashboard, with inline styles
+var dashboardTemplate = template.Must(
+	template.New("dashboard").Funcs(template.FuncMap{
+		"timestamp": execution.FormatTimestampMs,
+		"duration": func(exec *execution.MigrationExecution) string {
+			if !exec.Finished() {
+				return ""
+			}
+			return exec.Duration().String()
+		},
+		"state": func(exec *execution.MigrationExecution) string {
+			switch {
+			case exec.Skipped():
+				return "skipped"
+			case exec.Finished():
+				return "finished"
+			}
+			return "unfinished"
+		},
+		"plural": func(count int, noun string) string {
+			if count == 1 {
+				return fmt.Sprintf("%d %s", count, noun)
+			}
+			return fmt.Sprintf("%d %ss", count, noun)
+		},
+	}).Parse(dashboardHTML),
+)
+
+const dashboardHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Migrations status</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+h1 { font-size: 1.4rem; } h2 { font-size: 1.1rem; margin-top: 2rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .3rem .6rem; border-bottom: 1px solid #ddd; }
+.muted { color: #777; }
+.error, .failure { background: #fdecea; border: 1px solid #f5c2c0; padding: .6rem; }
+.unfinished { color: #b00020; } .skipped { color: #777; }
+</style>
+</head>
+<body>
+<h1>Migrations status</h1>
+<p class="muted">Generated at {{.GeneratedAt}}</p>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{with .Summary}}<p>{{plural .RegisteredCount "registered migration"}},
+{{plural .FinishedCount "finished execution"}}, {{plural .PendingCount "pending migration"}}</p>{{end}}
+<h2>Last failure</h2>
+{{with .LastFailure}}<div class="failure">
+<strong>{{.Name}}</strong> (version {{.Version}}){{if .At}} at {{.At}}{{end}}:
+{{if eq .Source "run"}}failed at stage {{.Stage}} with error: {{.Error}}
+{{else}}started but not finished (failed or still running){{end}}
+</div>{{else}}<p class="muted">None</p>{{end}}
+<h2>Pending</h2>
+{{if .Pending}}<table>
+<tr><th>Version</th><th>Name</th></tr>
+{{range .Pending}}<tr><td>{{.Version}}</td><td>{{.Name}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">None</p>{{end}}
+<h2>History</h2>
+{{if .History}}<table>
+<tr><th>Version</th><th>Name</th><th>Executed at</th><th>Duration</th><th>State</th><th>Run</th>
+<th>Notes</th></tr>
+{{range .History}}{{$state := state .Execution}}<tr>
+<td>{{.Version}}</td><td>{{.Name}}</td><td>{{timestamp .Execution.ExecutedAtMs}}</td>
+<td>{{duration .Execution}}</td><td class="{{$state}}">{{$state}}</td>
+<td>{{with .Execution.Run}}{{.DeployID}} {{.GitSHA}} {{.Operator}}{{end}}</td>
+<td>{{range .Notes}}<div class="note">{{.Text}}
+{{- with .Author}} <span class="muted">({{.}})</span>{{end}}</div>{{end}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">None</p>{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,157 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusTestSuite struct {
+	suite.Suite
+}
+
+func TestStatusTestSuite(t *testing.T) {
+	suite.Run(t, new(StatusTestSuite))
+}
+
+type failingMigration struct {
+	migration.DummyMigration
+}
+
+func (m *failingMigration) Up() error {
+	return errors.New("duplicate column")
+}
+
+func (suite *StatusTestSuite) newDashboard(
+	repo execution.Repository,
+	options ...Option,
+) (*Dashboard, *handler.MigrationsHandler) {
+	registry := migration.NewGenericRegistry()
+	_ = registry.Register(migration.NewDummyMigration(1))
+	_ = registry.Register(migration.NewDummyMigration(2))
+	_ = registry.Register(&failingMigration{*migration.NewDummyMigration(3)})
+
+	recorder := &FailureRecorder{}
+	migrations, err := handler.NewHandler(
+		registry, repo, nil, handler.WithErrorReporter(recorder),
+	)
+	suite.Require().NoError(err)
+
+	dashboard := NewDashboard(migrations, append(options, WithFailureRecorder(recorder))...)
+	dashboard.now = func() time.Time { return time.UnixMilli(1717236000000) }
+	return dashboard, migrations
+}
+
+func (suite *StatusTestSuite) serve(
+	dashboard *Dashboard,
+	target string,
+	accept string,
+) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	request.Header.Set("Accept", accept)
+	response := httptest.NewRecorder()
+	dashboard.ServeHTTP(response, request)
+	return response
+}
+
+func (suite *StatusTestSuite) TestItServesTheStatusAsJSON() {
+	repo := &execution.InMemoryRepository{}
+	dashboard, migrations := suite.newDashboard(repo, WithHistoryLimit(1))
+
+	_, err := migrations.MigrateUp(handler.NumOfRuns(3))
+	suite.Assert().ErrorContains(err, "duplicate column")
+
+	for _, accept := range []string{"application/json", ""} {
+		response := suite.serve(dashboard, "/status?format=json", accept)
+		suite.Assert().Equal(http.StatusOK, response.Code)
+		suite.Assert().Equal("application/json", response.Header().Get("Content-Type"))
+
+		var report map[string]any
+		suite.Require().NoError(json.Unmarshal(response.Body.Bytes(), &report))
+		suite.Assert().Equal("2024-06-01T10:00:00.000Z", report["generatedAt"])
+		suite.Assert().Equal(float64(1), report["summary"].(map[string]any)["pendingCount"])
+		suite.Assert().Len(report["history"], 1)
+		latest := report["history"].([]any)[0].(map[string]any)
+		suite.Assert().Equal(float64(3), latest["version"])
+		suite.Assert().Equal(false, latest["execution"].(map[string]any)["finished"])
+		suite.Assert().Equal(
+			[]any{map[string]any{"version": float64(3), "name": "migration 3"}}, report["pending"],
+		)
+
+		failure := report["lastFailure"].(map[string]any)
+		suite.Assert().Equal(float64(3), failure["version"])
+		suite.Assert().Equal(FailureSourceRun, failure["source"])
+		suite.Assert().Equal("up", failure["stage"])
+		suite.Assert().Equal("duplicate column", failure["error"])
+		suite.Assert().NotContains(report, "error")
+	}
+}
+
+type notesRepository struct {
+	execution.InMemoryRepository
+	execution.InMemoryNoteStore
+}
+
+func (suite *StatusTestSuite) TestItRendersTheHTMLDashboard() {
+	repo := &notesRepository{}
+	repo.PersistedExecutions = []execution.MigrationExecution{
+		{Version: 1, ExecutedAtMs: 1717236000000, FinishedAtMs: 1717236001500},
+		{Version: 2, ExecutedAtMs: 1717236002000, Run: execution.RunMetadata{DeployID: "d-7"}},
+	}
+	repo.Notes = []execution.Note{
+		{Version: 1, ExecutedAtMs: 1717236000000, Text: "backfill verified", Author: "jane"},
+	}
+	dashboard, _ := suite.newDashboard(repo)
+
+	response := suite.serve(dashboard, "/status", "text/html")
+	body := response.Body.String()
+
+	suite.Assert().Equal(http.StatusOK, response.Code)
+	suite.Assert().Equal("text/html; charset=utf-8", response.Header().Get("Content-Type"))
+	suite.Assert().Contains(body, "3 registered migrations,\n1 finished execution, 2 pending")
+	suite.Assert().Contains(body, "<td>1.5s</td><td class=\"finished\">finished</td>")
+	suite.Assert().Contains(body, "<td class=\"unfinished\">unfinished</td>\n<td>d-7")
+	suite.Assert().Contains(body, "<strong>migration 2</strong> (version 2) at 2024-06-01T10:00:02")
+	suite.Assert().Contains(body, "started but not finished")
+	suite.Assert().Contains(body, "<tr><td>3</td><td>migration 3</td></tr>")
+	suite.Assert().Contains(
+		body, "<div class=\"note\">backfill verified <span class=\"muted\">(jane)</span></div>",
+	)
+	suite.Assert().NotContains(body, "class=\"error\"")
+}
+
+func (suite *StatusTestSuite) TestItReportsStatusLoadingErrors() {
+	repo := &execution.InMemoryRepository{
+		PersistedExecutions: []execution.MigrationExecution{{Version: 9, FinishedAtMs: 1}},
+	}
+	dashboard, _ := suite.newDashboard(repo)
+
+	response := suite.serve(dashboard, "/status", "")
+	suite.Assert().Equal(http.StatusServiceUnavailable, response.Code)
+	suite.Assert().Contains(response.Body.String(), "<p class=\"error\">")
+
+	report := dashboard.Report()
+	suite.Assert().Contains(report.Error, handler.ErrPlanInconsistent.Error())
+	suite.Assert().Nil(report.Summary)
+	suite.Assert().Len(report.History, 1)
+	suite.Assert().Nil(report.LastFailure)
+}
+
+func (suite *StatusTestSuite) TestItOnlyAllowsReads() {
+	dashboard, _ := suite.newDashboard(&execution.InMemoryRepository{})
+
+	request := httptest.NewRequest(http.MethodPost, "/status", nil)
+	response := httptest.NewRecorder()
+	dashboard.ServeHTTP(response, request)
+
+	suite.Assert().Equal(http.StatusMethodNotAllowed, response.Code)
+	suite.Assert().Equal("GET, HEAD", response.Header().Get("Allow"))
+}
@@ -0,0 +1,70 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// ErrCanaryFailed is returned (wrapped) when the canary tenant fails to migrate or fails the
+// verification, in which case the remaining tenants are not touched
+var ErrCanaryFailed = errors.New("canary tenant failed")
+
+// Verifier Must check that the tenant works as expected after its migrations were applied (for
+// example, by running smoke test queries against its database). A returned error stops the run
+// before the remaining tenants are migrated.
+type Verifier func(
+	tenant Tenant,
+	registry migration.MigrationsRegistry,
+	repository execution.Repository,
+) error
+
+// RunWithCanary Same as Run, but calls fn for the canary tenant first and then runs the
+// verifiers, in order, for it. Only if the canary passes, fn is called for the remaining
+// tenants. The canary must be one of the provided tenants, otherwise errors with
+// ErrUnknownTenant. If the canary fails, errors with ErrCanaryFailed.
+func (r *Runner) RunWithCanary(
+	canaryID string,
+	tenants []Tenant,
+	fn func(
+		tenant Tenant,
+		registry migration.MigrationsRegistry,
+		repository execution.Repository,
+	) error,
+	verifiers ...Verifier,
+) error {
+	canaryIndex := slices.IndexFunc(tenants, func(t Tenant) bool { return t.ID == canaryID })
+	if canaryIndex < 0 {
+		return fmt.Errorf(
+			"%w: canary %s is not one of the selected tenants", ErrUnknownTenant, canaryID,
+		)
+	}
+
+	err := r.Run(
+		tenants[canaryIndex:canaryIndex+1],
+		func(
+			t Tenant,
+			registry migration.MigrationsRegistry,
+			repository execution.Repository,
+		) error {
+			if err := fn(t, registry, repository); err != nil {
+				return err
+			}
+
+			for i, verify := range verifiers {
+				if err := verify(t, registry, repository); err != nil {
+					return fmt.Errorf("verification %d failed with error: %w", i, err)
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("%w, %w", ErrCanaryFailed, err)
+	}
+
+	return r.Run(slices.Delete(slices.Clone(tenants), canaryIndex, canaryIndex+1), fn)
+}
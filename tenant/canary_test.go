@@ -0,0 +1,115 @@
+package tenant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type CanaryTestSuite struct {
+	suite.Suite
+}
+
+func TestCanaryTestSuite(t *testing.T) {
+	suite.Run(t, new(CanaryTestSuite))
+}
+
+func (suite *CanaryTestSuite) newRunner() *Runner {
+	return NewRunner(
+		StaticProvider{{ID: "acme"}, {ID: "globex"}, {ID: "initech"}},
+		func(tenant Tenant) (migration.MigrationsRegistry, execution.Repository, error) {
+			return migration.NewGenericRegistry(), &execution.InMemoryRepository{}, nil
+		},
+	)
+}
+
+func (suite *CanaryTestSuite) TestItRunsTheCanaryFirstAndThenTheRemainingTenants() {
+	runner := suite.newRunner()
+	all, _ := runner.Select()
+
+	var visited []string
+	err := runner.RunWithCanary(
+		"globex",
+		all,
+		func(
+			tenant Tenant,
+			registry migration.MigrationsRegistry,
+			repository execution.Repository,
+		) error {
+			visited = append(visited, tenant.ID)
+			return nil
+		},
+		func(
+			tenant Tenant,
+			registry migration.MigrationsRegistry,
+			repository execution.Repository,
+		) error {
+			visited = append(visited, "verify "+tenant.ID)
+			return nil
+		},
+	)
+
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]string{"globex", "verify globex", "acme", "initech"}, visited)
+	suite.Assert().Len(all, 3)
+}
+
+func (suite *CanaryTestSuite) TestItStopsWhenTheCanaryFails() {
+	scenarios := map[string]struct {
+		runErr      error
+		verifyErr   error
+		expectedErr string
+	}{
+		"run fails": {errors.New("up err"), nil, "tenant acme failed with error: up err"},
+		"verify fails": {
+			nil, errors.New("smoke err"), "verification 0 failed with error: smoke err",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		suite.Run(name, func() {
+			runner := suite.newRunner()
+			all, _ := runner.Select()
+
+			var visited []string
+			err := runner.RunWithCanary(
+				"acme",
+				all,
+				func(
+					tenant Tenant,
+					registry migration.MigrationsRegistry,
+					repository execution.Repository,
+				) error {
+					visited = append(visited, tenant.ID)
+					return scenario.runErr
+				},
+				func(Tenant, migration.MigrationsRegistry, execution.Repository) error {
+					return scenario.verifyErr
+				},
+			)
+
+			suite.Assert().ErrorIs(err, ErrCanaryFailed)
+			suite.Assert().ErrorContains(err, scenario.expectedErr)
+			suite.Assert().Equal([]string{"acme"}, visited)
+		})
+	}
+}
+
+func (suite *CanaryTestSuite) TestItFailsWhenTheCanaryIsNotSelected() {
+	runner := suite.newRunner()
+	selected, _ := runner.Select("acme")
+
+	err := runner.RunWithCanary(
+		"globex",
+		selected,
+		func(Tenant, migration.MigrationsRegistry, execution.Repository) error {
+			suite.Fail("no tenant must be migrated")
+			return nil
+		},
+	)
+
+	suite.Assert().ErrorIs(err, ErrUnknownTenant)
+}
@@ -0,0 +1,156 @@
+// Package tenant includes multi-tenant migrations related logic. It can be used by applications
+// where each tenant has its own database or schema (for example, schema-per-tenant SaaS apps)
+// which must be migrated and tracked independently.
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// Tenant Holds the information needed to connect to a tenant's database
+type Tenant struct {
+	ID           string
+	DSN          string
+	DatabaseName string
+}
+
+// Provider Must be implemented by the client code and must list all tenants that should be
+// migrated, together with their database connection details
+type Provider interface {
+	// Tenants must return all tenants, preferably in a stable order
+	Tenants() ([]Tenant, error)
+}
+
+// StaticProvider Provider implementation for a fixed list of tenants
+type StaticProvider []Tenant
+
+func (p StaticProvider) Tenants() ([]Tenant, error) {
+	return p, nil
+}
+
+// TargetBuilder Must build the migrations registry (with migrations connected to the tenant's
+// database) and the executions repository for the provided tenant. Executions are tracked per
+// tenant, so the repository should persist them in the tenant's database or in a tenant
+// specific table/collection.
+type TargetBuilder func(
+	tenant Tenant,
+) (migration.MigrationsRegistry, execution.Repository, error)
+
+// ClosingTargetBuilder Same as TargetBuilder, but also returns the function which releases the
+// resources opened for the tenant (for example, its database connections). It is called once
+// the tenant's run is done, so migrating a large fleet does not keep a connection pool open per
+// tenant. The returned function can be nil if there is nothing to release.
+type ClosingTargetBuilder func(
+	tenant Tenant,
+) (migration.MigrationsRegistry, execution.Repository, func() error, error)
+
+// ErrUnknownTenant is returned when a requested tenant is not listed by the Provider
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Runner Runs migration related logic for one, multiple or all tenants
+type Runner struct {
+	provider    Provider
+	buildTarget ClosingTargetBuilder
+}
+
+func NewRunner(provider Provider, buildTarget TargetBuilder) *Runner {
+	return NewClosingRunner(provider, func(tenant Tenant) (
+		migration.MigrationsRegistry, execution.Repository, func() error, error,
+	) {
+		registry, repository, err := buildTarget(tenant)
+		return registry, repository, nil, err
+	})
+}
+
+// NewClosingRunner Same as NewRunner, but releases the resources of each tenant once its run is
+// done (see ClosingTargetBuilder)
+func NewClosingRunner(provider Provider, buildTarget ClosingTargetBuilder) *Runner {
+	return &Runner{provider: provider, buildTarget: buildTarget}
+}
+
+// Select Returns the tenants with the provided ids, in the order given by the Provider.
+// If no ids are provided, all tenants are returned. Errors with ErrUnknownTenant if any of
+// the ids is not listed by the Provider.
+func (r *Runner) Select(ids ...string) ([]Tenant, error) {
+	tenants, err := r.provider.Tenants()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants with error: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return tenants, nil
+	}
+
+	var selected []Tenant
+	for _, t := range tenants {
+		if slices.Contains(ids, t.ID) {
+			selected = append(selected, t)
+		}
+	}
+
+	for _, id := range ids {
+		if !slices.ContainsFunc(selected, func(t Tenant) bool { return t.ID == id }) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+		}
+	}
+
+	return selected, nil
+}
+
+// Run Builds the registry and repository for each tenant and calls fn with them, sequentially,
+// in the order of the provided tenants. The resources of each tenant are released once fn
+// returns (see ClosingTargetBuilder), failing to release them fails the tenant. Stops at the
+// first tenant that fails, so the remaining tenants are not touched.
+func (r *Runner) Run(
+	tenants []Tenant,
+	fn func(
+		tenant Tenant,
+		registry migration.MigrationsRegistry,
+		repository execution.Repository,
+	) error,
+) error {
+	for _, t := range tenants {
+		if err := r.runTenant(t, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runTenant Builds the registry and repository for the tenant, calls fn with them and releases
+// the tenant's resources
+func (r *Runner) runTenant(
+	t Tenant,
+	fn func(
+		tenant Tenant,
+		registry migration.MigrationsRegistry,
+		repository execution.Repository,
+	) error,
+) error {
+	registry, repository, closeTarget, err := r.buildTarget(t)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to build migrations target for tenant %s with error: %w", t.ID, err,
+		)
+	}
+
+	if err = fn(t, registry, repository); err != nil {
+		err = fmt.Errorf("tenant %s failed with error: %w", t.ID, err)
+	}
+
+	if closeTarget != nil {
+		if closeErr := closeTarget(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf(
+				"failed to release the resources of tenant %s with error: %w", t.ID, closeErr,
+			))
+		}
+	}
+
+	return err
+}
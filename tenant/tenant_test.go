@@ -0,0 +1,121 @@
+package tenant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type TenantTestSuite struct {
+	suite.Suite
+}
+
+func TestTenantTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantTestSuite))
+}
+
+func (suite *TenantTestSuite) newRunner() *Runner {
+	return NewRunner(
+		StaticProvider{{ID: "acme"}, {ID: "globex"}, {ID: "initech"}},
+		func(tenant Tenant) (migration.MigrationsRegistry, execution.Repository, error) {
+			if tenant.ID == "initech" {
+				return nil, nil, errors.New("build err")
+			}
+			return migration.NewGenericRegistry(), &execution.InMemoryRepository{}, nil
+		},
+	)
+}
+
+func (suite *TenantTestSuite) TestItCanSelectTenants() {
+	runner := suite.newRunner()
+
+	all, err := runner.Select()
+	suite.Assert().NoError(err)
+	suite.Assert().Len(all, 3)
+
+	selected, err := runner.Select("globex", "acme")
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]Tenant{{ID: "acme"}, {ID: "globex"}}, selected)
+
+	_, err = runner.Select("acme", "unknown")
+	suite.Assert().ErrorIs(err, ErrUnknownTenant)
+}
+
+func (suite *TenantTestSuite) TestItRunsForEachTenantAndStopsOnFailure() {
+	runner := suite.newRunner()
+	all, _ := runner.Select()
+
+	var visited []string
+	err := runner.Run(
+		all,
+		func(
+			tenant Tenant,
+			registry migration.MigrationsRegistry,
+			repository execution.Repository,
+		) error {
+			visited = append(visited, tenant.ID)
+			return nil
+		},
+	)
+
+	suite.Assert().ErrorContains(err, "initech")
+	suite.Assert().Equal([]string{"acme", "globex"}, visited)
+
+	visited = nil
+	err = runner.Run(
+		all,
+		func(
+			tenant Tenant,
+			registry migration.MigrationsRegistry,
+			repository execution.Repository,
+		) error {
+			visited = append(visited, tenant.ID)
+			return errors.New("fn err")
+		},
+	)
+
+	suite.Assert().ErrorContains(err, "tenant acme failed with error: fn err")
+	suite.Assert().Equal([]string{"acme"}, visited)
+}
+
+func (suite *TenantTestSuite) TestItReleasesTheTenantResourcesAfterEachRun() {
+	var closed []string
+	runner := NewClosingRunner(
+		StaticProvider{{ID: "acme"}, {ID: "globex"}, {ID: "initech"}},
+		func(tenant Tenant) (
+			migration.MigrationsRegistry, execution.Repository, func() error, error,
+		) {
+			closeTarget := func() error {
+				closed = append(closed, tenant.ID)
+				if tenant.ID == "globex" {
+					return errors.New("close err")
+				}
+				return nil
+			}
+			return migration.NewGenericRegistry(), &execution.InMemoryRepository{}, closeTarget, nil
+		},
+	)
+	tenants, _ := runner.Select()
+
+	var ran []string
+	err := runner.Run(
+		tenants, func(
+			tenant Tenant,
+			registry migration.MigrationsRegistry,
+			repository execution.Repository,
+		) error {
+			ran = append(ran, tenant.ID)
+			suite.Assert().NotContains(closed, tenant.ID)
+			return nil
+		},
+	)
+
+	suite.Assert().ErrorContains(
+		err, "failed to release the resources of tenant globex with error: close err",
+	)
+	suite.Assert().Equal([]string{"acme", "globex"}, ran)
+	suite.Assert().Equal([]string{"acme", "globex"}, closed)
+}
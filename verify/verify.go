@@ -0,0 +1,163 @@
+// Package verify includes helpers for verifying the migrations chain in CI. The chain is split
+// in shards, each verified in parallel, in its own disposable database or schema, so projects
+// with many migrations can verify the full chain (and its reversibility) faster.
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+)
+
+// Environment A disposable database or schema in which a shard is verified. The registry
+// migrations must run against the environment's database and the repository must store the
+// executions in it (or in a shard specific table/collection).
+type Environment struct {
+	Registry   migration.MigrationsRegistry
+	Repository execution.Repository
+	// Cleanup is called after the shard is verified, to drop the database or schema. Optional.
+	Cleanup func() error
+}
+
+// EnvironmentFactory Must be implemented by the client code and must create a new, empty,
+// Environment for the shard (numbered from 0). It's called concurrently, once for each shard.
+type EnvironmentFactory func(shard int) (Environment, error)
+
+// Settings Configures how the migrations chain is verified
+type Settings struct {
+	// Shards is the number of environments in which the chain is verified in parallel. Capped to
+	// the number of migrations. Defaults to 1.
+	Shards int
+	// Reversibility enables running Up(), Down() and Up() again for each migration, instead of
+	// only Up() (see handler.MigrationsHandler.VerifyReversible)
+	Reversibility bool
+	// HandlerOptions are passed to the handler of each shard
+	HandlerOptions []handler.Option
+}
+
+// ShardResult The outcome of a shard verification
+type ShardResult struct {
+	Shard int
+	// Versions are the versions of the migrations verified by the shard
+	Versions []uint64
+	// Results are the round-trip results of the verified migrations, if Reversibility is enabled
+	Results []handler.ReversibilityResult
+	Err     error
+}
+
+// Run Splits the registry migrations in contiguous ranges, one for each shard, and verifies
+// them in parallel. Each shard creates its environment, migrates it up to the first migration
+// of its range, then verifies the range. Since the migrations before the range only run Up(),
+// with N shards and reversibility enabled, the chain takes about 1 + 2/N times the duration of
+// a full MigrateUp, instead of 3 times. All shards run to completion, the returned error joins
+// the failed shards errors.
+func Run(newEnvironment EnvironmentFactory, settings Settings) ([]ShardResult, error) {
+	environment, err := newEnvironment(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the environment of shard 0 with error: %w", err)
+	}
+
+	ranges := split(environment.Registry.OrderedVersions(), settings.Shards)
+	results := make([]ShardResult, len(ranges))
+	environments := map[int]Environment{0: environment}
+
+	var wg sync.WaitGroup
+	for shard, versions := range ranges {
+		results[shard] = ShardResult{Shard: shard, Versions: versions}
+		wg.Add(1)
+
+		go func(result *ShardResult, environment Environment, created bool) {
+			defer wg.Done()
+			result.Results, result.Err = verifyShard(
+				newEnvironment, result.Shard, environment, created, ranges, settings,
+			)
+		}(&results[shard], environments[shard], shard == 0)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("shard %d failed, %w", result.Shard, result.Err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// verifyShard Migrates the shard environment up to the first migration of the shard range,
+// then verifies the range. The environment is created if it was not created already.
+func verifyShard(
+	newEnvironment EnvironmentFactory,
+	shard int,
+	environment Environment,
+	created bool,
+	ranges [][]uint64,
+	settings Settings,
+) (results []handler.ReversibilityResult, err error) {
+	if !created {
+		if environment, err = newEnvironment(shard); err != nil {
+			return nil, fmt.Errorf("failed to create the environment with error: %w", err)
+		}
+	}
+
+	if environment.Cleanup != nil {
+		defer func() {
+			if cleanupErr := environment.Cleanup(); cleanupErr != nil {
+				cleanupErr = fmt.Errorf(
+					"failed to clean up the environment with error: %w", cleanupErr,
+				)
+				err = errors.Join(err, cleanupErr)
+			}
+		}()
+	}
+
+	if len(ranges[shard]) == 0 {
+		return nil, nil
+	}
+
+	migrationsHandler, err := handler.NewHandler(
+		environment.Registry, environment.Repository, nil, settings.HandlerOptions...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := 0
+	for _, versions := range ranges[:shard] {
+		previous += len(versions)
+	}
+
+	if previous > 0 {
+		if _, err = migrationsHandler.MigrateUp(handler.NumOfRuns(previous)); err != nil {
+			return nil, fmt.Errorf("failed to migrate up to the shard range with error: %w", err)
+		}
+	}
+
+	count := handler.NumOfRuns(len(ranges[shard]))
+	if settings.Reversibility {
+		return migrationsHandler.VerifyReversible(count)
+	}
+
+	_, err = migrationsHandler.MigrateUp(count)
+	return nil, err
+}
+
+// split Splits the versions in (at most) shards contiguous ranges, of (almost) equal lengths
+func split(versions []uint64, shards int) [][]uint64 {
+	shards = max(min(shards, len(versions)), 1)
+	ranges := make([][]uint64, 0, shards)
+
+	start := 0
+	for shard := 0; shard < shards; shard++ {
+		end := start + (len(versions)-start)/(shards-shard)
+		ranges = append(ranges, versions[start:end])
+		start = end
+	}
+
+	return ranges
+}
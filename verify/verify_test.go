@@ -0,0 +1,183 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/rsgcata/go-migrations/execution"
+	"github.com/rsgcata/go-migrations/handler"
+	"github.com/rsgcata/go-migrations/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+type VerifyTestSuite struct {
+	suite.Suite
+}
+
+func TestVerifyTestSuite(t *testing.T) {
+	suite.Run(t, new(VerifyTestSuite))
+}
+
+// fakeDatabase The tables of a disposable environment and the migration calls made in it
+type fakeDatabase struct {
+	tables  map[string]bool
+	calls   []string
+	cleaned bool
+}
+
+// tableMigration Creates a table in the fake database. Its Down() forgets to drop the table if
+// brokenDown is set
+type tableMigration struct {
+	migration.DummyMigration
+	db         *fakeDatabase
+	brokenDown bool
+}
+
+func (m *tableMigration) table() string {
+	return fmt.Sprintf("table_%d", m.Version())
+}
+
+func (m *tableMigration) Up() error {
+	m.db.calls = append(m.db.calls, fmt.Sprintf("up %d", m.Version()))
+	if m.db.tables[m.table()] {
+		return errors.New("table " + m.table() + " already exists")
+	}
+	m.db.tables[m.table()] = true
+	return nil
+}
+
+func (m *tableMigration) Down() error {
+	m.db.calls = append(m.db.calls, fmt.Sprintf("down %d", m.Version()))
+	if !m.brokenDown {
+		delete(m.db.tables, m.table())
+	}
+	return nil
+}
+
+// newFactory Builds an EnvironmentFactory for the versions, recording the created databases
+func newFactory(
+	versions []uint64,
+	brokenDown uint64,
+) (EnvironmentFactory, map[int]*fakeDatabase) {
+	var mu sync.Mutex
+	databases := map[int]*fakeDatabase{}
+
+	return func(shard int) (Environment, error) {
+		db := &fakeDatabase{tables: map[string]bool{}}
+		mu.Lock()
+		databases[shard] = db
+		mu.Unlock()
+
+		registry := migration.NewGenericRegistry()
+		for _, version := range versions {
+			_ = registry.Register(
+				&tableMigration{
+					DummyMigration: *migration.NewDummyMigration(version),
+					db:             db,
+					brokenDown:     version == brokenDown,
+				},
+			)
+		}
+
+		return Environment{
+			Registry:   registry,
+			Repository: &execution.InMemoryRepository{},
+			Cleanup: func() error {
+				db.cleaned = true
+				return nil
+			},
+		}, nil
+	}, databases
+}
+
+func (suite *VerifyTestSuite) TestItCanSplitVersionsInShards() {
+	versions := []uint64{1, 2, 3, 4, 5}
+
+	suite.Assert().Equal([][]uint64{{1, 2, 3, 4, 5}}, split(versions, 0))
+	suite.Assert().Equal([][]uint64{{1, 2}, {3, 4, 5}}, split(versions, 2))
+	suite.Assert().Equal([][]uint64{{1}, {2, 3}, {4, 5}}, split(versions, 3))
+	suite.Assert().Equal([][]uint64{{1}, {2}, {3}, {4}, {5}}, split(versions, 10))
+	suite.Assert().Equal([][]uint64{nil}, split(nil, 3))
+}
+
+func (suite *VerifyTestSuite) TestItVerifiesTheReversibilityOfEachShardRange() {
+	newEnvironment, databases := newFactory([]uint64{1, 2, 3, 4, 5}, 0)
+
+	results, err := Run(newEnvironment, Settings{Shards: 2, Reversibility: true})
+
+	suite.Require().NoError(err)
+	suite.Assert().Len(results, 2)
+	suite.Assert().Equal([]uint64{1, 2}, results[0].Versions)
+	suite.Assert().Equal([]uint64{3, 4, 5}, results[1].Versions)
+	suite.Assert().Len(results[0].Results, 2)
+	suite.Assert().Len(results[1].Results, 3)
+
+	suite.Assert().Equal(
+		[]string{"up 1", "down 1", "up 1", "up 2", "down 2", "up 2"}, databases[0].calls,
+	)
+	suite.Assert().Equal(
+		[]string{
+			"up 1", "up 2", "up 3", "down 3", "up 3", "up 4", "down 4", "up 4",
+			"up 5", "down 5", "up 5",
+		},
+		databases[1].calls,
+	)
+	suite.Assert().Len(databases[1].tables, 5)
+	suite.Assert().True(databases[0].cleaned)
+	suite.Assert().True(databases[1].cleaned)
+}
+
+func (suite *VerifyTestSuite) TestItCanOnlyMigrateUpEachShardRange() {
+	newEnvironment, databases := newFactory([]uint64{1, 2, 3}, 0)
+
+	results, err := Run(newEnvironment, Settings{Shards: 3})
+
+	suite.Require().NoError(err)
+	suite.Assert().Len(results, 3)
+	suite.Assert().Equal([]string{"up 1"}, databases[0].calls)
+	suite.Assert().Equal([]string{"up 1", "up 2"}, databases[1].calls)
+	suite.Assert().Equal([]string{"up 1", "up 2", "up 3"}, databases[2].calls)
+	for _, result := range results {
+		suite.Assert().Nil(result.Results)
+	}
+}
+
+func (suite *VerifyTestSuite) TestItRunsAllShardsAndReportsTheFailedOnes() {
+	newEnvironment, databases := newFactory([]uint64{1, 2, 3, 4}, 2)
+
+	results, err := Run(newEnvironment, Settings{Shards: 2, Reversibility: true})
+
+	suite.Assert().ErrorIs(err, handler.ErrNotReversible)
+	suite.Assert().ErrorContains(err, "shard 0 failed")
+	suite.Assert().NotContains(err.Error(), "shard 1")
+	suite.Assert().Error(results[0].Err)
+	suite.Assert().False(results[0].Results[1].Reversible())
+	suite.Assert().NoError(results[1].Err)
+	suite.Assert().True(databases[0].cleaned)
+	suite.Assert().True(databases[1].cleaned)
+}
+
+func (suite *VerifyTestSuite) TestItFailsWhenEnvironmentsCanNotBeCreated() {
+	newEnvironment, _ := newFactory([]uint64{1, 2}, 0)
+	factoryErr := errors.New("factory err")
+
+	_, err := Run(
+		func(shard int) (Environment, error) {
+			if shard == 1 {
+				return Environment{}, factoryErr
+			}
+			return newEnvironment(shard)
+		},
+		Settings{Shards: 2},
+	)
+	suite.Assert().ErrorIs(err, factoryErr)
+	suite.Assert().ErrorContains(err, "shard 1 failed")
+
+	_, err = Run(
+		func(shard int) (Environment, error) { return Environment{}, factoryErr },
+		Settings{Shards: 2},
+	)
+	suite.Assert().ErrorIs(err, factoryErr)
+}